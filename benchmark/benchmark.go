@@ -148,6 +148,12 @@ func FileCollectionBenchmark(sourceDir string, numFiles int) (*Result, error) {
 
 // FileProcessingBenchmark benchmarks full file processing pipeline.
 func FileProcessingBenchmark(sourceDir string, format string, concurrency int) (*Result, error) {
+	return fileProcessingBenchmark(sourceDir, format, concurrency, false)
+}
+
+// fileProcessingBenchmark benchmarks the full file processing pipeline, reading upcoming files
+// ahead of the workers when prefetch is set.
+func fileProcessingBenchmark(sourceDir string, format string, concurrency int, prefetch bool) (*Result, error) {
 	// Load configuration to ensure proper file filtering
 	config.LoadConfig()
 
@@ -208,7 +214,7 @@ func FileProcessingBenchmark(sourceDir string, format string, concurrency int) (
 	}
 
 	// Process files with concurrency
-	err = runProcessingPipeline(context.Background(), files, outputFile, format, concurrency, sourceDir)
+	err = runProcessingPipeline(context.Background(), files, outputFile, format, concurrency, sourceDir, prefetch)
 	if err != nil {
 		return nil, shared.WrapError(
 			err,
@@ -233,6 +239,9 @@ func FileProcessingBenchmark(sourceDir string, format string, concurrency int) (
 	}
 
 	benchmarkName := fmt.Sprintf("FileProcessing_%s_c%d", format, concurrency)
+	if prefetch {
+		benchmarkName += "_prefetch"
+	}
 	result := buildBenchmarkResult(benchmarkName, files, totalBytes, duration, memBefore, memAfter)
 	return result, nil
 }
@@ -261,6 +270,34 @@ func ConcurrencyBenchmark(sourceDir string, format string, concurrencyLevels []i
 	return suite, nil
 }
 
+// ConcurrencyPrefetchBenchmark benchmarks different concurrency levels with and without files
+// being read ahead of the workers, measuring how much filesystem latency prefetching hides.
+func ConcurrencyPrefetchBenchmark(sourceDir string, format string, concurrencyLevels []int) (*Suite, error) {
+	suite := &Suite{
+		Name:    "ConcurrencyPrefetchBenchmark",
+		Results: make([]Result, 0, 2*len(concurrencyLevels)),
+	}
+
+	for _, concurrency := range concurrencyLevels {
+		for _, prefetch := range []bool{false, true} {
+			result, err := fileProcessingBenchmark(sourceDir, format, concurrency, prefetch)
+			if err != nil {
+				return nil, shared.WrapErrorf(
+					err,
+					shared.ErrorTypeProcessing,
+					shared.CodeProcessingCollection,
+					"concurrency benchmark failed for level %d (prefetch: %v)",
+					concurrency,
+					prefetch,
+				)
+			}
+			suite.Results = append(suite.Results, *result)
+		}
+	}
+
+	return suite, nil
+}
+
 // FormatBenchmark benchmarks different output formats.
 func FormatBenchmark(sourceDir string, formats []string) (*Suite, error) {
 	suite := &Suite{
@@ -377,6 +414,7 @@ func runProcessingPipeline(
 	format string,
 	concurrency int,
 	sourceDir string,
+	prefetch bool,
 ) error {
 	// Guard against invalid concurrency to prevent deadlocks
 	if concurrency < 1 {
@@ -401,6 +439,12 @@ func runProcessingPipeline(
 		)
 	}
 
+	var prefetcher *fileproc.Prefetcher
+	if prefetch {
+		prefetcher = fileproc.NewPrefetcher(fileproc.PrefetchBudget(config.Global()), config.PerformancePrefetchReaders())
+		defer prefetcher.Close()
+	}
+
 	// Start workers with proper synchronization
 	var workersDone sync.WaitGroup
 	for i := 0; i < concurrency; i++ {
@@ -408,13 +452,18 @@ func runProcessingPipeline(
 		go func() {
 			defer workersDone.Done()
 			for filePath := range fileCh {
-				fileproc.ProcessFile(filePath, writeCh, absRoot)
+				processor := fileproc.NewFileProcessor(absRoot)
+				processor.SetPrefetcher(prefetcher)
+				if err := processor.ProcessWithContext(ctx, filePath, writeCh); err != nil {
+					shared.LogErrorf(err, shared.FileProcessingMsgFailedToProcess, filePath)
+				}
 			}
 		}()
 	}
 
 	// Send files to workers
 	for _, file := range files {
+		prefetcher.Add(file)
 		select {
 		case <-ctx.Done():
 			close(fileCh)
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -113,6 +114,26 @@ func TestConcurrencyBenchmark(t *testing.T) {
 	}
 }
 
+// TestConcurrencyPrefetchBenchmark tests that the prefetch benchmark runs every level with and
+// without prefetching.
+func TestConcurrencyPrefetchBenchmark(t *testing.T) {
+	suite, err := ConcurrencyPrefetchBenchmark("", "json", []int{2})
+	if err != nil {
+		t.Fatalf("ConcurrencyPrefetchBenchmark failed: %v", err)
+	}
+
+	names := make([]string, 0, len(suite.Results))
+	for _, result := range suite.Results {
+		names = append(names, result.Name)
+		if result.FilesProcessed <= 0 {
+			t.Errorf("Result %s: "+shared.TestFmtExpectedFilesProcessed, result.Name, result.FilesProcessed)
+		}
+	}
+	if want := []string{"FileProcessing_json_c2", "FileProcessing_json_c2_prefetch"}; !slices.Equal(names, want) {
+		t.Errorf("result names = %v, want %v", names, want)
+	}
+}
+
 // TestFormatBenchmark tests the format benchmark.
 func TestFormatBenchmark(t *testing.T) {
 	formats := []string{"json", "yaml"}
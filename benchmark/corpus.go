@@ -0,0 +1,205 @@
+// Package benchmark provides benchmarking infrastructure for gibidify.
+package benchmark
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// corpusDownloadTimeout bounds the download of one corpus, the Linux tree being the largest.
+const corpusDownloadTimeout = 30 * time.Minute
+
+// Corpus is a well-known open-source tree pinned to a release, so benchmarks run against it
+// are comparable across machines and gibidify versions.
+type Corpus struct {
+	Name    string
+	Version string
+	// URL is the gzipped tarball of the release, holding the tree under one top-level directory.
+	URL string
+}
+
+// corpora lists the standard corpora --corpus selects by name.
+var corpora = map[string]Corpus{
+	"linux": {
+		Name: "linux", Version: "v6.6",
+		URL: "https://codeload.github.com/torvalds/linux/tar.gz/refs/tags/v6.6",
+	},
+	"kubernetes": {
+		Name: "kubernetes", Version: "v1.29.0",
+		URL: "https://codeload.github.com/kubernetes/kubernetes/tar.gz/refs/tags/v1.29.0",
+	},
+	"rails": {
+		Name: "rails", Version: "v7.1.2",
+		URL: "https://codeload.github.com/rails/rails/tar.gz/refs/tags/v7.1.2",
+	},
+}
+
+// CorpusNames returns the names of the standard corpora, sorted.
+func CorpusNames() []string {
+	names := make([]string, 0, len(corpora))
+	for name := range corpora {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	return names
+}
+
+// LookupCorpus returns the standard corpus called name.
+func LookupCorpus(name string) (Corpus, error) {
+	corpus, ok := corpora[name]
+	if !ok {
+		return Corpus{}, shared.NewValidationError(
+			shared.CodeValidationFormat,
+			"unknown corpus "+name+" (available: "+strings.Join(CorpusNames(), ", ")+")",
+		)
+	}
+
+	return corpus, nil
+}
+
+// DefaultCorpusDir returns the directory corpora are cached in, under the user cache dir.
+func DefaultCorpusDir() (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", shared.WrapError(err, shared.ErrorTypeFileSystem, shared.CodeFSPathResolution, "locating cache dir")
+	}
+
+	return filepath.Join(base, shared.AppName, "corpus"), nil
+}
+
+// FetchCorpus returns the directory holding corpus under cacheDir, downloading and extracting
+// it first unless an earlier run cached it. The tree is extracted next to its final place and
+// renamed into it once complete, so an interrupted download is never mistaken for a cached one.
+func FetchCorpus(ctx context.Context, corpus Corpus, cacheDir string) (string, error) {
+	dir := filepath.Join(cacheDir, corpus.Name+"-"+corpus.Version)
+	if info, err := os.Stat(dir); err == nil && info.IsDir() {
+		return dir, nil
+	}
+
+	if err := os.MkdirAll(cacheDir, 0o750); err != nil {
+		return "", shared.WrapError(err, shared.ErrorTypeFileSystem, shared.CodeFSAccess, "creating corpus cache dir").
+			WithFilePath(cacheDir)
+	}
+	partial, err := os.MkdirTemp(cacheDir, corpus.Name+"-*.partial")
+	if err != nil {
+		return "", shared.WrapError(err, shared.ErrorTypeFileSystem, shared.CodeFSAccess, "creating corpus dir").
+			WithFilePath(cacheDir)
+	}
+	defer func() { _ = os.RemoveAll(partial) }()
+
+	if err := downloadCorpus(ctx, corpus, partial); err != nil {
+		return "", err
+	}
+	if err := os.Rename(partial, dir); err != nil {
+		return "", shared.WrapError(err, shared.ErrorTypeFileSystem, shared.CodeFSAccess, "caching corpus").
+			WithFilePath(dir)
+	}
+
+	return dir, nil
+}
+
+// downloadCorpus downloads the tarball of corpus and extracts it into dir.
+func downloadCorpus(ctx context.Context, corpus Corpus, dir string) error {
+	ctx, cancel := context.WithTimeout(ctx, corpusDownloadTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, corpus.URL, http.NoBody)
+	if err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIORead, "building corpus download").
+			WithContext("url", corpus.URL)
+	}
+	resp, err := http.DefaultClient.Do(req) // #nosec G107 -- the URL is one of the pinned corpora
+	if err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIORead, "corpus download failed").
+			WithContext("url", corpus.URL)
+	}
+	defer shared.SafeCloseReader(resp.Body, corpus.URL)
+
+	if resp.StatusCode != http.StatusOK {
+		return shared.NewStructuredError(
+			shared.ErrorTypeIO, shared.CodeIORead, "corpus download returned "+resp.Status, "",
+			map[string]any{"url": corpus.URL, "status": resp.StatusCode},
+		)
+	}
+
+	return extractTarball(resp.Body, dir)
+}
+
+// extractTarball extracts the regular files and directories of the gzipped tarball r into dir,
+// leaving out its top-level directory. Links and other entries are skipped; an entry whose path
+// leaves dir fails the extraction.
+func extractTarball(r io.Reader, dir string) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIORead, "reading corpus tarball")
+	}
+	defer shared.SafeCloseReader(gz, "corpus tarball")
+
+	archive := tar.NewReader(gz)
+	for {
+		header, err := archive.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIORead, "reading corpus tarball")
+		}
+		if err := extractEntry(archive, header, dir); err != nil {
+			return err
+		}
+	}
+}
+
+// extractEntry extracts one tarball entry into dir.
+func extractEntry(archive *tar.Reader, header *tar.Header, dir string) error {
+	_, name, ok := strings.Cut(header.Name, "/")
+	if !ok || name == "" || (header.Typeflag != tar.TypeReg && header.Typeflag != tar.TypeDir) {
+		return nil
+	}
+	if !filepath.IsLocal(name) {
+		return shared.NewStructuredError(
+			shared.ErrorTypeValidation, shared.CodeValidationPath, "corpus tarball entry leaves its directory",
+			header.Name, nil,
+		)
+	}
+
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	if header.Typeflag == tar.TypeDir {
+		return wrapCorpusWrite(os.MkdirAll(path, 0o750), path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return wrapCorpusWrite(err, path)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600) // #nosec G304 -- checked IsLocal
+	if err != nil {
+		return wrapCorpusWrite(err, path)
+	}
+	_, err = io.Copy(file, archive) // #nosec G110 -- the corpora are pinned releases
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	return wrapCorpusWrite(err, path)
+}
+
+// wrapCorpusWrite wraps an error writing the extracted corpus file at path, or returns nil.
+func wrapCorpusWrite(err error, path string) error {
+	if err == nil {
+		return nil
+	}
+
+	return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOFileWrite, "extracting corpus").WithFilePath(path)
+}
@@ -0,0 +1,128 @@
+package benchmark
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// corpusTarball builds a gzipped tarball holding the named entries: files with their contents,
+// and directories for the names without contents.
+func corpusTarball(t *testing.T, entries []string, contents map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	archive := tar.NewWriter(gz)
+	for _, name := range entries {
+		content, isFile := contents[name]
+		header := &tar.Header{Name: name, Mode: 0o644, Typeflag: tar.TypeDir}
+		if isFile {
+			header.Typeflag, header.Size = tar.TypeReg, int64(len(content))
+		}
+		if err := archive.WriteHeader(header); err != nil {
+			t.Fatalf("writing header: %v", err)
+		}
+		if _, err := archive.Write([]byte(content)); err != nil {
+			t.Fatalf("writing entry: %v", err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatalf("closing tar: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("closing gzip: %v", err)
+	}
+
+	return buf.Bytes()
+}
+
+// TestFetchCorpus tests that a corpus is extracted without its top-level directory, and served
+// from the cache on the next fetch.
+func TestFetchCorpus(t *testing.T) {
+	tarball := corpusTarball(t,
+		[]string{"demo-1.0/", "demo-1.0/src/", "demo-1.0/src/main.go", "demo-1.0/README.md"},
+		map[string]string{"demo-1.0/src/main.go": shared.LiteralPackageMain, "demo-1.0/README.md": "# demo\n"},
+	)
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+		_, _ = w.Write(tarball)
+	}))
+	defer server.Close()
+
+	corpus := Corpus{Name: "demo", Version: "1.0", URL: server.URL}
+	cacheDir := t.TempDir()
+	for range 2 {
+		dir, err := FetchCorpus(t.Context(), corpus, cacheDir)
+		if err != nil {
+			t.Fatalf("FetchCorpus: %v", err)
+		}
+		content, err := os.ReadFile(filepath.Join(dir, "src", "main.go"))
+		if err != nil || string(content) != shared.LiteralPackageMain {
+			t.Errorf("src/main.go = %q, %v", content, err)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("downloaded %d times, want 1", requests)
+	}
+}
+
+// TestFetchCorpusFailures tests that failed downloads and unsafe tarballs leave nothing cached.
+func TestFetchCorpusFailures(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     []byte
+		wantCode string
+	}{
+		{name: "http error", status: http.StatusNotFound, wantCode: shared.CodeIORead},
+		{name: "not gzip", status: http.StatusOK, body: []byte("not a tarball"), wantCode: shared.CodeIORead},
+		{
+			name:   "entry leaving the directory",
+			status: http.StatusOK,
+			body: corpusTarball(t, []string{"demo-1.0/../../escape.txt"},
+				map[string]string{"demo-1.0/../../escape.txt": "x"}),
+			wantCode: shared.CodeValidationPath,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write(tt.body)
+			}))
+			defer server.Close()
+
+			cacheDir := t.TempDir()
+			_, err := FetchCorpus(t.Context(), Corpus{Name: "demo", Version: "1.0", URL: server.URL}, cacheDir)
+			var structErr *shared.StructuredError
+			if !errors.As(err, &structErr) || structErr.Code != tt.wantCode {
+				t.Errorf("FetchCorpus error = %v, want code %s", err, tt.wantCode)
+			}
+			if entries, _ := os.ReadDir(cacheDir); len(entries) != 0 {
+				t.Errorf("cache dir holds %v after a failed fetch", entries)
+			}
+		})
+	}
+}
+
+// TestLookupCorpus tests that the standard corpora are found by name.
+func TestLookupCorpus(t *testing.T) {
+	for _, name := range CorpusNames() {
+		if corpus, err := LookupCorpus(name); err != nil || corpus.Name != name || corpus.URL == "" {
+			t.Errorf("LookupCorpus(%q) = %+v, %v", name, corpus, err)
+		}
+	}
+	if _, err := LookupCorpus("plan9"); err == nil {
+		t.Error("LookupCorpus(plan9) succeeded, want an error")
+	}
+}
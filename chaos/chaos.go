@@ -0,0 +1,79 @@
+// Package chaos injects failures into file reads and bundle writes, so integration tests can
+// check that error paths and partial-failure handling work end to end. Injection is compiled
+// in only with the chaos build tag; in other builds the hooks do nothing and Available is false.
+package chaos
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// DefaultDelay is how long a slow read is delayed unless Config.Delay is set.
+const DefaultDelay = 50 * time.Millisecond
+
+// ErrInjected is the error of every injected read or write failure.
+var ErrInjected = errors.New("chaos: injected failure")
+
+// Config holds the probabilities, between 0 and 1, of the failures to inject.
+type Config struct {
+	// ReadError is the probability that opening or reading a source file fails.
+	ReadError float64
+	// SlowRead is the probability that a read of a source file is delayed by Delay.
+	SlowRead float64
+	// WriteError is the probability that a write to the bundle output fails.
+	WriteError float64
+	// Delay is how long slow reads take; DefaultDelay when zero.
+	Delay time.Duration
+	// Seed makes the injected failures repeatable; 0 picks a random seed.
+	Seed uint64
+}
+
+// ParseConfig parses a comma-separated list of key=value settings, for example
+// "read=0.1,slow=0.2,write=0.05,delay=20ms,seed=7".
+func ParseConfig(spec string) (Config, error) {
+	cfg := Config{Delay: DefaultDelay}
+	for setting := range strings.SplitSeq(spec, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(setting), "=")
+		var err error
+		switch key {
+		case "read":
+			cfg.ReadError, err = parseProbability(value)
+		case "slow":
+			cfg.SlowRead, err = parseProbability(value)
+		case "write":
+			cfg.WriteError, err = parseProbability(value)
+		case "delay":
+			cfg.Delay, err = time.ParseDuration(value)
+		case "seed":
+			cfg.Seed, err = strconv.ParseUint(value, 10, 64)
+		default:
+			err = errors.New("unknown setting; use read, slow, write, delay or seed")
+		}
+		if err != nil {
+			return Config{}, shared.NewStructuredError(
+				shared.ErrorTypeValidation, shared.CodeCLIInvalidArgs,
+				fmt.Sprintf("invalid chaos setting %q: %v", setting, err), "", nil,
+			)
+		}
+	}
+
+	return cfg, nil
+}
+
+// parseProbability parses a probability between 0 and 1.
+func parseProbability(value string) (float64, error) {
+	p, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, err //nolint:wrapcheck // ParseConfig names the setting
+	}
+	if p < 0 || p > 1 {
+		return 0, errors.New("probability must be between 0 and 1")
+	}
+
+	return p, nil
+}
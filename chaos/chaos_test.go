@@ -0,0 +1,48 @@
+package chaos
+
+import (
+	"testing"
+	"time"
+)
+
+// TestParseConfig tests parsing --chaos settings and rejecting unknown keys and
+// probabilities outside 0 to 1.
+func TestParseConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    Config
+		wantErr bool
+	}{
+		{
+			name: "all settings",
+			spec: "read=0.1, slow=0.2,write=0.05,delay=20ms,seed=7",
+			want: Config{ReadError: 0.1, SlowRead: 0.2, WriteError: 0.05, Delay: 20 * time.Millisecond, Seed: 7},
+		},
+		{name: "default delay", spec: "write=1", want: Config{WriteError: 1, Delay: DefaultDelay}},
+		{name: "unknown setting", spec: "read=0.1,crash=1", wantErr: true},
+		{name: "probability above one", spec: "read=1.5", wantErr: true},
+		{name: "negative probability", spec: "slow=-0.1", wantErr: true},
+		{name: "invalid delay", spec: "delay=soon", wantErr: true},
+		{name: "missing value", spec: "seed", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseConfig(tt.spec)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseConfig(%q) = %+v, want an error", tt.spec, got)
+				}
+
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseConfig(%q) failed: %v", tt.spec, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseConfig(%q) = %+v, want %+v", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
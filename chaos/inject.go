@@ -0,0 +1,97 @@
+//go:build chaos
+
+package chaos
+
+import (
+	"io"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Available reports whether failure injection is compiled in.
+const Available = true
+
+// injector draws the failures of one Config.
+type injector struct {
+	cfg Config
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+// active is the injector of the enabled Config, or nil when injection is disabled.
+var active atomic.Pointer[injector]
+
+// Enable starts injecting the failures of cfg, replacing any Config enabled before.
+func Enable(cfg Config) {
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = rand.Uint64()
+	}
+	if cfg.Delay == 0 {
+		cfg.Delay = DefaultDelay
+	}
+	active.Store(&injector{cfg: cfg, rng: rand.New(rand.NewPCG(seed, seed))}) // #nosec G404 -- not security relevant
+}
+
+// Disable stops injecting failures.
+func Disable() {
+	active.Store(nil)
+}
+
+// hit reports whether a failure of probability p is injected this time.
+func (i *injector) hit(p float64) bool {
+	if p <= 0 {
+		return false
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	return i.rng.Float64() < p
+}
+
+// BeforeRead delays a read of a source file when a slow read is injected, and returns
+// ErrInjected when a read error is.
+func BeforeRead() error {
+	i := active.Load()
+	if i == nil {
+		return nil
+	}
+	if i.hit(i.cfg.SlowRead) {
+		time.Sleep(i.cfg.Delay)
+	}
+	if i.hit(i.cfg.ReadError) {
+		return ErrInjected
+	}
+
+	return nil
+}
+
+// BeforeWrite returns ErrInjected when a write failure is injected into a bundle write.
+func BeforeWrite() error {
+	if i := active.Load(); i != nil && i.hit(i.cfg.WriteError) {
+		return ErrInjected
+	}
+
+	return nil
+}
+
+// Reader injects slow reads and read errors into every read from r.
+func Reader(r io.Reader) io.Reader {
+	return chaosReader{r: r}
+}
+
+// chaosReader is the io.Reader Reader returns.
+type chaosReader struct {
+	r io.Reader
+}
+
+// Read implements io.Reader.
+func (c chaosReader) Read(p []byte) (int, error) {
+	if err := BeforeRead(); err != nil {
+		return 0, err
+	}
+
+	return c.r.Read(p) //nolint:wrapcheck // passes the reader's errors through
+}
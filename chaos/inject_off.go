@@ -0,0 +1,29 @@
+//go:build !chaos
+
+package chaos
+
+import "io"
+
+// Available reports whether failure injection is compiled in.
+const Available = false
+
+// Enable does nothing: failure injection is compiled in only with the chaos build tag.
+func Enable(Config) {}
+
+// Disable does nothing without the chaos build tag.
+func Disable() {}
+
+// BeforeRead never fails without the chaos build tag.
+func BeforeRead() error {
+	return nil
+}
+
+// BeforeWrite never fails without the chaos build tag.
+func BeforeWrite() error {
+	return nil
+}
+
+// Reader returns r unchanged without the chaos build tag.
+func Reader(r io.Reader) io.Reader {
+	return r
+}
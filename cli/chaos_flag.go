@@ -0,0 +1,12 @@
+//go:build chaos
+
+// Package cli provides command-line interface functionality for gibidify.
+package cli
+
+import "flag"
+
+// registerChaosFlag registers --chaos, which test builds use to inject failures.
+func registerChaosFlag(fs *flag.FlagSet, flags *Flags) {
+	fs.StringVar(&flags.Chaos, "chaos", "",
+		"Inject failures for testing: read=P,slow=P,write=P probabilities, delay=D for slow reads, seed=N")
+}
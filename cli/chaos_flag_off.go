@@ -0,0 +1,9 @@
+//go:build !chaos
+
+// Package cli provides command-line interface functionality for gibidify.
+package cli
+
+import "flag"
+
+// registerChaosFlag registers nothing: --chaos exists only in chaos builds.
+func registerChaosFlag(*flag.FlagSet, *Flags) {}
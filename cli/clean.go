@@ -0,0 +1,172 @@
+// Package cli provides command-line interface functionality for gibidify.
+package cli
+
+import (
+	"cmp"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ivuorinen/gibidify/fileproc"
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+func init() {
+	registerSubcommand(shared.CLISubcommandClean, "cleaning bundles", func(_ context.Context, args []string) error {
+		return RunClean(os.Stdout, args, time.Now())
+	})
+}
+
+// bundleFormats maps the file extensions of bundles to their output format.
+var bundleFormats = map[string]string{
+	".md":       shared.FormatMarkdown,
+	".markdown": shared.FormatMarkdown,
+	".json":     shared.FormatJSON,
+	".yaml":     shared.FormatYAML,
+	".yml":      shared.FormatYAML,
+	".txt":      shared.FormatPlain,
+}
+
+// CleanFlags holds flags for the clean subcommand.
+type CleanFlags struct {
+	Dir       string
+	Keep      int
+	OlderThan time.Duration
+	DryRun    bool
+}
+
+// ParseCleanFlags parses the arguments following the clean subcommand. At least one of
+// -keep and -older-than is required, so a bare clean never removes every bundle.
+func ParseCleanFlags(args []string) (*CleanFlags, error) {
+	flags := &CleanFlags{}
+
+	fs := flag.NewFlagSet(shared.AppName+" "+shared.CLISubcommandClean, flag.ContinueOnError)
+	fs.StringVar(&flags.Dir, "dir", "", "Directory holding the generated bundles")
+	fs.IntVar(&flags.Keep, "keep", 0, "Always keep the newest N bundles")
+	fs.Func("older-than", "Remove bundles modified longer ago than this age (e.g. 30d or 12h)", func(value string) error {
+		age, err := parseAge(value)
+		flags.OlderThan = age
+
+		return err
+	})
+	fs.BoolVar(&flags.DryRun, "dry-run", false, "List the bundles that would be removed without removing them")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	var message string
+	switch {
+	case flags.Dir == "" || fs.NArg() > 0:
+		message = "usage: gibidify clean -dir <directory> [-keep N] [-older-than AGE] [-dry-run]"
+	case flags.Keep < 0:
+		message = fmt.Sprintf("-keep must not be negative, got %d", flags.Keep)
+	case flags.Keep == 0 && flags.OlderThan == 0:
+		message = "clean requires -keep or -older-than"
+	default:
+		return flags, nil
+	}
+
+	return nil, shared.NewStructuredError(shared.ErrorTypeValidation, shared.CodeCLIInvalidArgs, message, "", nil)
+}
+
+// parseAge parses a duration as accepted by time.ParseDuration, or a whole number of days
+// such as "30d".
+func parseAge(value string) (time.Duration, error) {
+	age, err := time.ParseDuration(value)
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		var n int
+		n, err = strconv.Atoi(days)
+		age = time.Duration(n) * 24 * time.Hour
+	}
+	if err != nil || age <= 0 {
+		return 0, fmt.Errorf("invalid age %q, want a positive duration such as 30d or 12h", value)
+	}
+
+	return age, nil
+}
+
+// cleanCandidate is a bundle found by the clean subcommand.
+type cleanCandidate struct {
+	path    string
+	modTime time.Time
+}
+
+// RunClean removes the bundles in the -dir directory that are not among the newest -keep and,
+// with -older-than, were last modified before that age relative to now. Only files whose
+// content reads as a gibidify bundle manifest are considered; other files are left alone.
+// Removed paths are written to w.
+func RunClean(w io.Writer, args []string, now time.Time) error {
+	flags, err := ParseCleanFlags(args)
+	if err != nil {
+		return err
+	}
+
+	bundles, err := findBundles(flags.Dir)
+	if err != nil {
+		return err
+	}
+
+	removed := 0
+	for i, bundle := range bundles {
+		if i < flags.Keep || (flags.OlderThan > 0 && now.Sub(bundle.modTime) < flags.OlderThan) {
+			continue
+		}
+		if !flags.DryRun {
+			if err := os.Remove(bundle.path); err != nil {
+				return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOFileWrite, "failed to remove bundle").
+					WithFilePath(bundle.path)
+			}
+		}
+		removed++
+		if _, err := fmt.Fprintln(w, bundle.path); err != nil {
+			return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOWrite, "failed to write removed bundles")
+		}
+	}
+
+	verb := "Removed"
+	if flags.DryRun {
+		verb = "Would remove"
+	}
+	NewUIManager().PrintInfo("%s %d of %d bundles in %s", verb, removed, len(bundles), flags.Dir)
+
+	return nil
+}
+
+// findBundles returns the bundles directly in dir, newest first.
+func findBundles(dir string) ([]cleanCandidate, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIORead, "failed to list bundles").
+			WithFilePath(dir)
+	}
+
+	var bundles []cleanCandidate
+	for _, entry := range entries {
+		format, ok := bundleFormats[strings.ToLower(filepath.Ext(entry.Name()))]
+		if !ok || !entry.Type().IsRegular() {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		if manifest, err := fileproc.ReadBundleManifest(path, format); err != nil || len(manifest) == 0 {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		bundles = append(bundles, cleanCandidate{path: path, modTime: info.ModTime()})
+	}
+	slices.SortFunc(bundles, func(a, b cleanCandidate) int {
+		return cmp.Or(b.modTime.Compare(a.modTime), cmp.Compare(a.path, b.path))
+	})
+
+	return bundles, nil
+}
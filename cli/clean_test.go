@@ -0,0 +1,143 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// createCleanFixture creates bundles and other files in a temporary directory, each modified
+// the given number of days before now, and returns the directory.
+func createCleanFixture(t *testing.T, now time.Time) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	files := []struct {
+		name    string
+		content string
+		days    int
+	}{
+		{name: "day1.md", content: "# Bundle\n\n## File: `main.go`\n\n```go\npackage main\n```\n", days: 1},
+		{name: "day10.json", content: `{"files":[{"path":"main.go","content":"package main"}]}`, days: 10},
+		{name: "day40.yaml", content: "files:\n  - path: main.go\n    content: package main\n", days: 40},
+		{name: "day50.md", content: "## File: `util.go`\n\npackage main\n", days: 50},
+		{name: "notes.md", content: "# Notes\n\nNot a bundle.\n", days: 60},
+		{name: "package.json", content: `{"name":"app"}`, days: 60},
+		{name: "day70.txt", content: "## File: `main.go`\n", days: 70},
+	}
+	for _, file := range files {
+		path := testutil.CreateTestFile(t, dir, file.name, []byte(file.content))
+		modTime := now.Add(-time.Duration(file.days) * 24 * time.Hour)
+		testutil.MustSucceed(t, os.Chtimes(path, modTime, modTime), "setting modification time")
+	}
+
+	return dir
+}
+
+func TestRunClean(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name        string
+		args        []string
+		wantRemoved []string
+	}{
+		{name: "keep newest", args: []string{"-keep", "2"}, wantRemoved: []string{"day40.yaml", "day50.md"}},
+		{name: "older than", args: []string{"-older-than", "30d"}, wantRemoved: []string{"day40.yaml", "day50.md"}},
+		{
+			name:        "keep protects old bundles",
+			args:        []string{"-keep", "3", "-older-than", "5d"},
+			wantRemoved: []string{"day50.md"},
+		},
+		{name: "nothing to remove", args: []string{"-keep", "10", "-older-than", "1h"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := createCleanFixture(t, now)
+			defer testutil.SuppressAllOutput(t)()
+
+			var out bytes.Buffer
+			testutil.MustSucceed(t, RunClean(&out, append([]string{"-dir", dir}, tt.args...), now), "RunClean")
+
+			var removed []string
+			for _, line := range strings.Fields(out.String()) {
+				removed = append(removed, filepath.Base(line))
+				if _, err := os.Stat(line); !os.IsNotExist(err) {
+					t.Errorf("%s is listed as removed but still exists", line)
+				}
+			}
+			slices.Sort(removed)
+			if !slices.Equal(removed, tt.wantRemoved) {
+				t.Errorf("removed %v, want %v", removed, tt.wantRemoved)
+			}
+		})
+	}
+}
+
+func TestRunCleanDryRun(t *testing.T) {
+	now := time.Now()
+	dir := createCleanFixture(t, now)
+	defer testutil.SuppressAllOutput(t)()
+
+	var out bytes.Buffer
+	testutil.MustSucceed(t, RunClean(&out, []string{"-dir", dir, "-keep", "1", "-dry-run"}, now), "RunClean")
+
+	if listed := strings.Fields(out.String()); len(listed) != 3 {
+		t.Errorf("dry run listed %v, want the 3 bundles after the newest", listed)
+	}
+	entries, err := os.ReadDir(dir)
+	testutil.MustSucceed(t, err, "reading directory")
+	if len(entries) != 7 {
+		t.Errorf("dry run left %d files, want all 7", len(entries))
+	}
+}
+
+func TestParseCleanFlags(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		args    []string
+		want    *CleanFlags
+		wantErr bool
+	}{
+		{
+			name: "keep and older than",
+			args: []string{"-dir", dir, "-keep", "10", "-older-than", "30d"},
+			want: &CleanFlags{Dir: dir, Keep: 10, OlderThan: 30 * 24 * time.Hour},
+		},
+		{
+			name: "go duration",
+			args: []string{"-dir", dir, "-older-than", "12h"},
+			want: &CleanFlags{Dir: dir, OlderThan: 12 * time.Hour},
+		},
+		{name: "missing dir", args: []string{"-keep", "1"}, wantErr: true},
+		{name: "no retention", args: []string{"-dir", dir}, wantErr: true},
+		{name: "negative keep", args: []string{"-dir", dir, "-keep", "-1"}, wantErr: true},
+		{name: "invalid age", args: []string{"-dir", dir, "-older-than", "soon"}, wantErr: true},
+		{name: "zero days", args: []string{"-dir", dir, "-older-than", "0d"}, wantErr: true},
+		{name: "extra argument", args: []string{"-dir", dir, "-keep", "1", "more"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCleanFlags(tt.args)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseCleanFlags(%v) = %+v, want an error", tt.args, got)
+				}
+
+				return
+			}
+			testutil.MustSucceed(t, err, "ParseCleanFlags")
+			if *got != *tt.want {
+				t.Errorf("ParseCleanFlags(%v) = %+v, want %+v", tt.args, got, tt.want)
+			}
+		})
+	}
+}
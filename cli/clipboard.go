@@ -0,0 +1,61 @@
+//go:build !minimal
+
+// Package cli provides command-line interface functionality for gibidify.
+package cli
+
+import (
+	"bytes"
+	"os/exec"
+	"runtime"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// clipboardCommands lists the clipboard writers tried in order on the current platform.
+// clip.exe is also tried on Linux, where it is available under WSL.
+func clipboardCommands() [][]string {
+	switch runtime.GOOS {
+	case "darwin":
+		return [][]string{{"pbcopy"}}
+	case "windows":
+		return [][]string{{"clip.exe"}}
+	default:
+		return [][]string{
+			{"wl-copy"},
+			{"xclip", "-selection", "clipboard"},
+			{"xsel", "--clipboard", "--input"},
+			{"clip.exe"},
+		}
+	}
+}
+
+// findClipboardCommand returns the first clipboard writer found in PATH, or nil when there is none.
+func findClipboardCommand() []string {
+	for _, command := range clipboardCommands() {
+		if _, err := exec.LookPath(command[0]); err == nil {
+			return command
+		}
+	}
+
+	return nil
+}
+
+// copyToClipboard writes content to the clipboard and returns the name of the command used.
+func copyToClipboard(content []byte) (string, error) {
+	command := findClipboardCommand()
+	if command == nil {
+		return "", shared.NewStructuredError(
+			shared.ErrorTypeIO, shared.CodeIOWrite, "no clipboard command found", "", nil,
+		)
+	}
+
+	cmd := exec.Command(command[0], command[1:]...) // #nosec G204 - command is from the fixed clipboard list
+	cmd.Stdin = bytes.NewReader(content)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return "", shared.WrapErrorf(
+			err, shared.ErrorTypeIO, shared.CodeIOWrite, "%s failed: %s", command[0], bytes.TrimSpace(out),
+		)
+	}
+
+	return command[0], nil
+}
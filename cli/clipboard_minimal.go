@@ -0,0 +1,25 @@
+//go:build minimal
+
+// Package cli provides command-line interface functionality for gibidify.
+package cli
+
+import (
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// clipboardCommands lists no clipboard writers: the minimal build leaves clipboard support out.
+func clipboardCommands() [][]string {
+	return nil
+}
+
+// findClipboardCommand returns nil, as the minimal build has no clipboard support.
+func findClipboardCommand() []string {
+	return nil
+}
+
+// copyToClipboard always fails in the minimal build, so quick writes the bundle to stdout.
+func copyToClipboard(_ []byte) (string, error) {
+	return "", shared.NewStructuredError(
+		shared.ErrorTypeIO, shared.CodeIOWrite, "built without clipboard support", "", nil,
+	)
+}
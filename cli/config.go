@@ -0,0 +1,37 @@
+// Package cli provides command-line interface functionality for gibidify.
+package cli
+
+import (
+	"context"
+	"io"
+	"os"
+
+	"github.com/ivuorinen/gibidify/config"
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+func init() {
+	registerSubcommand(shared.CLISubcommandConfig, "describing config", func(_ context.Context, args []string) error {
+		return RunConfig(os.Stdout, args)
+	})
+}
+
+// RunConfig runs the action following the config subcommand: schema writes a JSON Schema of
+// the config file to w, for editors to complete and check config files with.
+func RunConfig(w io.Writer, args []string) error {
+	if len(args) != 1 || args[0] != shared.CLIConfigActionSchema {
+		return shared.NewStructuredError(
+			shared.ErrorTypeValidation, shared.CodeCLIInvalidArgs, "usage: gibidify config schema", "", nil,
+		)
+	}
+
+	schema, err := config.JSONSchema()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(schema); err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOWrite, "failed to write schema")
+	}
+
+	return nil
+}
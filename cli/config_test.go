@@ -0,0 +1,23 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+func TestRunConfig(t *testing.T) {
+	var out bytes.Buffer
+	testutil.MustSucceed(t, RunConfig(&out, []string{"schema"}), "RunConfig schema")
+	if !json.Valid(out.Bytes()) {
+		t.Errorf("RunConfig schema wrote %q, want a JSON document", out.String())
+	}
+
+	for _, args := range [][]string{nil, {"dump"}, {"schema", "extra"}} {
+		if err := RunConfig(&out, args); err == nil {
+			t.Errorf("RunConfig(%v) succeeded, want a usage error", args)
+		}
+	}
+}
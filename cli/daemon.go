@@ -0,0 +1,383 @@
+//go:build !minimal
+
+// Package cli provides command-line interface functionality for gibidify.
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"runtime/pprof"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ivuorinen/gibidify/config"
+	"github.com/ivuorinen/gibidify/daemon"
+	"github.com/ivuorinen/gibidify/fileproc"
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+func init() {
+	registerSubcommand(shared.CLISubcommandDaemon, "running daemon", RunDaemon)
+}
+
+// Daemon method names.
+const (
+	daemonMethodBundle       = "bundle"
+	daemonMethodStats        = "stats"
+	daemonMethodReloadConfig = "reload-config"
+
+	// daemonLabelFormat is the profiler label holding the format of a bundle request.
+	daemonLabelFormat = "format"
+)
+
+// daemonProfilePushInterval is how often --pyroscope pushes the CPU and heap profiles.
+const daemonProfilePushInterval = 10 * time.Second
+
+// pyroscopeTagUnsafe matches the characters a Pyroscope tag value cannot hold.
+var pyroscopeTagUnsafe = regexp.MustCompile(`[^A-Za-z0-9_.-]`)
+
+// DaemonFlags holds flags for the daemon subcommand.
+type DaemonFlags struct {
+	SourceDir    string
+	Socket       string
+	Pprof        string
+	Pyroscope    string
+	PyroscopeApp string
+}
+
+// ParseDaemonFlags parses the arguments following the daemon subcommand.
+func ParseDaemonFlags(args []string) (*DaemonFlags, error) {
+	flags := &DaemonFlags{}
+
+	fs := flag.NewFlagSet(shared.AppName+" "+shared.CLISubcommandDaemon, flag.ContinueOnError)
+	fs.StringVar(&flags.SourceDir, shared.CLIArgSource, "", "Source directory to index and bundle")
+	fs.StringVar(&flags.Socket, "socket", "", "Control socket path (default: <tmp>/gibidify-<hash>.sock)")
+	fs.StringVar(&flags.Pprof, "pprof", "",
+		"Serve net/http/pprof profiles, labeled per request, on this address (e.g. localhost:6060)")
+	fs.StringVar(&flags.Pyroscope, "pyroscope", "",
+		"Push CPU and heap profiles, labeled per request, to the Pyroscope server at this URL")
+	fs.StringVar(&flags.PyroscopeApp, "pyroscope-app", shared.AppName+".daemon",
+		"Application name of the profiles pushed with --pyroscope")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if flags.SourceDir == "" {
+		return nil, NewCLIMissingSourceError()
+	}
+	if parsed, err := url.Parse(flags.Pyroscope); flags.Pyroscope != "" &&
+		(err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "") {
+		return nil, shared.NewStructuredError(
+			shared.ErrorTypeCLI, shared.CodeCLIInvalidArgs, "--pyroscope must be an http or https URL", "", nil,
+		)
+	}
+	if err := shared.ValidateSourcePath(flags.SourceDir); err != nil {
+		return nil, fmt.Errorf("validating source path: %w", err)
+	}
+
+	absSource, err := shared.AbsolutePath(flags.SourceDir)
+	if err != nil {
+		return nil, fmt.Errorf("getting absolute path: %w", err)
+	}
+	flags.SourceDir = absSource
+	if flags.Socket == "" {
+		name := shared.AppName + "-" + shared.ShortChecksum(config.PerformanceHashAlgorithm(), []byte(absSource), 4)
+		flags.Socket = filepath.Join(os.TempDir(), name+".sock")
+	}
+
+	return flags, nil
+}
+
+// RunDaemon indexes the source tree and serves bundle, stats, and reload-config
+// requests on the control socket until interrupted.
+func RunDaemon(ctx context.Context, args []string) error {
+	flags, err := ParseDaemonFlags(args)
+	if err != nil {
+		return err
+	}
+
+	state := &daemonState{source: flags.SourceDir, settings: config.Global(), started: time.Now()}
+	if err := state.reindex(); err != nil {
+		return err
+	}
+
+	listener, err := daemon.Listen(flags.Socket)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := os.Remove(flags.Socket); err != nil && !os.IsNotExist(err) {
+			shared.LogError("Error removing daemon socket", err)
+		}
+	}()
+
+	ui := NewUIManager()
+	ui.PrintHeader(ui.theme.start + "gibidify daemon")
+	ui.PrintInfo("Source: %s (%d files indexed)", flags.SourceDir, len(state.files))
+	ui.PrintInfo("Socket: %s", flags.Socket)
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var profiling sync.WaitGroup
+	if err := startProfiling(ctx, flags, ui, &profiling); err != nil {
+		return err
+	}
+	err = state.server().Serve(ctx, listener)
+	stop()
+	profiling.Wait()
+
+	return err
+}
+
+// startProfiling serves the --pprof endpoints and starts pushing profiles to --pyroscope, tagged
+// with the source directory name, until ctx is canceled. Pushing is tracked by wg.
+func startProfiling(ctx context.Context, flags *DaemonFlags, ui *UIManager, wg *sync.WaitGroup) error {
+	if flags.Pprof != "" {
+		addr, err := daemon.ServeProfiles(ctx, flags.Pprof)
+		if err != nil {
+			return err
+		}
+		ui.PrintInfo("Profiles: http://%s/debug/pprof/", addr)
+	}
+	if flags.Pyroscope != "" {
+		source := pyroscopeTagUnsafe.ReplaceAllString(filepath.Base(flags.SourceDir), "_")
+		pusher := daemon.NewProfilePusher(
+			flags.Pyroscope, flags.PyroscopeApp+"{source="+source+"}", daemonProfilePushInterval,
+		)
+		wg.Go(func() { pusher.Run(ctx) })
+		ui.PrintInfo("Profiles pushed to: %s", flags.Pyroscope)
+	}
+
+	return nil
+}
+
+// daemonState holds the warmed file index and the configuration shared by all daemon requests.
+// Requests are serialized because reload-config replaces the configuration bundles read.
+type daemonState struct {
+	mu        sync.Mutex
+	source    string
+	settings  *config.Settings
+	files     []string
+	indexedAt time.Time
+	started   time.Time
+	bundles   int
+}
+
+// server creates a daemon server with the state's handlers registered.
+func (d *daemonState) server() *daemon.Server {
+	server := daemon.NewServer()
+	server.Handle(daemonMethodBundle, d.handleBundle)
+	server.Handle(daemonMethodStats, d.handleStats)
+	server.Handle(daemonMethodReloadConfig, d.handleReloadConfig)
+
+	return server
+}
+
+// reindex rescans the source tree with the current file type configuration.
+func (d *daemonState) reindex() error {
+	files, err := fileproc.NewProdWalkerWithSettings(newFileTypeRegistry(d.settings), d.settings).Walk(d.source)
+	if err != nil {
+		return shared.WrapError(err, shared.ErrorTypeProcessing, shared.CodeProcessingCollection, "indexing source").
+			WithFilePath(d.source)
+	}
+	d.files = files
+	d.indexedAt = time.Now()
+
+	return nil
+}
+
+// bundleParams are the parameters of the bundle method.
+type bundleParams struct {
+	Destination string `json:"destination"`
+	Format      string `json:"format"`
+	Prefix      string `json:"prefix"`
+	Suffix      string `json:"suffix"`
+	Refresh     bool   `json:"refresh"`
+	bundleLimits
+}
+
+// bundleLimits are resource limits of a bundle request. A request may lower the configured
+// resourceLimits, which bound it, so one large request cannot hold the daemon for long; a zero
+// limit keeps the configured one. While resourceLimits are disabled only the limits a request
+// sets are enforced. The stats method reports the configured limits.
+type bundleLimits struct {
+	MaxFiles       int   `json:"max_files"`
+	MaxTotalSize   int64 `json:"max_total_size"`
+	TimeoutSec     int   `json:"timeout_sec"`
+	FileTimeoutSec int   `json:"file_timeout_sec"`
+}
+
+// configuredBundleLimits returns the resourceLimits configured in settings.
+func configuredBundleLimits(settings *config.Settings) bundleLimits {
+	return bundleLimits{
+		MaxFiles:       settings.MaxFiles(),
+		MaxTotalSize:   settings.MaxTotalSize(),
+		TimeoutSec:     settings.OverallTimeoutSec(),
+		FileTimeoutSec: settings.FileProcessingTimeoutSec(),
+	}
+}
+
+// resourceLimits returns the limits as processor resource limits.
+func (l bundleLimits) resourceLimits() fileproc.ResourceLimits {
+	return fileproc.ResourceLimits{
+		MaxFiles:              l.MaxFiles,
+		MaxTotalSize:          l.MaxTotalSize,
+		FileProcessingTimeout: time.Duration(l.FileTimeoutSec) * time.Second,
+		OverallTimeout:        time.Duration(l.TimeoutSec) * time.Second,
+	}
+}
+
+// bundleResult is returned by the bundle method. Content is set when no destination was given.
+type bundleResult struct {
+	Destination string `json:"destination,omitempty"`
+	Content     string `json:"content,omitempty"`
+	Files       int    `json:"files"`
+	DurationMS  int64  `json:"duration_ms"`
+	// Request is the daemon.LabelRequest profiler label the bundle was built under.
+	Request string `json:"request,omitempty"`
+	// RunID identifies the run that built the bundle in the daemon's log lines and the bundle.
+	RunID string `json:"run_id"`
+}
+
+// handleBundle writes a bundle from the warmed index, rescanning first when refresh is set.
+func (d *daemonState) handleBundle(ctx context.Context, raw json.RawMessage) (any, error) {
+	params := bundleParams{Format: shared.FormatMarkdown}
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &params); err != nil {
+			return nil, fmt.Errorf("invalid bundle params: %w", err)
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := d.settings.ValidateOutputFormat(params.Format); err != nil {
+		return nil, err
+	}
+	if params.Refresh {
+		if err := d.reindex(); err != nil {
+			return nil, err
+		}
+	}
+
+	destination, inline, err := bundleDestination(params.Destination, params.Format)
+	if err != nil {
+		return nil, err
+	}
+	if inline {
+		defer func() { shared.LogError("Error removing temporary bundle", os.Remove(destination)) }()
+	}
+
+	start := time.Now()
+	p := NewProcessor(WithFlags(&Flags{
+		SourceDir:   d.source,
+		Destination: destination,
+		Format:      params.Format,
+		Prefix:      params.Prefix,
+		Suffix:      params.Suffix,
+		Concurrency: config.DefaultConcurrency(),
+		NoUI:        true,
+	}), WithSettings(d.settings), WithResourceLimits(params.resourceLimits()))
+	p.indexedFiles = d.files
+	pprof.Do(ctx, pprof.Labels(daemonLabelFormat, params.Format), func(ctx context.Context) {
+		err = p.Process(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	d.bundles++
+
+	result := bundleResult{Files: len(d.files), DurationMS: time.Since(start).Milliseconds(), RunID: p.RunID()}
+	result.Request, _ = pprof.Label(ctx, daemon.LabelRequest)
+	if !inline {
+		result.Destination = destination
+
+		return result, nil
+	}
+
+	content, err := os.ReadFile(destination) // #nosec G304 -- temporary file created by bundleDestination
+	if err != nil {
+		return nil, shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIORead, "reading bundle")
+	}
+	result.Content = string(content)
+
+	return result, nil
+}
+
+// bundleDestination validates the requested destination, or creates a temporary file
+// when none was given so the bundle can be returned inline.
+func bundleDestination(requested, format string) (path string, inline bool, err error) {
+	if requested != "" {
+		if err := shared.ValidateDestinationPath(requested); err != nil {
+			return "", false, err
+		}
+
+		return requested, false, nil
+	}
+
+	tmp, err := os.CreateTemp("", shared.AppName+"-daemon-*."+formatExtension(format))
+	if err != nil {
+		return "", false, shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOFileCreate, "creating temporary bundle")
+	}
+	shared.LogError("Error closing temporary bundle", tmp.Close())
+
+	return tmp.Name(), true, nil
+}
+
+// daemonStats is returned by the stats method.
+type daemonStats struct {
+	Source        string    `json:"source"`
+	Files         int       `json:"files"`
+	IndexedAt     time.Time `json:"indexed_at"`
+	Bundles       int       `json:"bundles"`
+	UptimeSeconds int64     `json:"uptime_seconds"`
+	// Limits are the configured resourceLimits bounding the limits of bundle requests.
+	Limits bundleLimits `json:"limits"`
+}
+
+// handleStats reports the index size and request counters.
+func (d *daemonState) handleStats(_ context.Context, _ json.RawMessage) (any, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return daemonStats{
+		Source:        d.source,
+		Files:         len(d.files),
+		IndexedAt:     d.indexedAt,
+		Bundles:       d.bundles,
+		UptimeSeconds: int64(time.Since(d.started).Seconds()),
+		Limits:        configuredBundleLimits(d.settings),
+	}, nil
+}
+
+// handleReloadConfig reloads the configuration file and rebuilds the index. A config file that
+// has become invalid, or an index that cannot be rebuilt, fails the request and keeps the
+// configuration in use.
+func (d *daemonState) handleReloadConfig(ctx context.Context, raw json.RawMessage) (any, error) {
+	settings, err := config.LoadSettingsFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	d.mu.Lock()
+	previous := d.settings
+	d.settings = settings
+	if err = d.reindex(); err != nil {
+		d.settings = previous
+	}
+	d.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	return d.handleStats(ctx, raw)
+}
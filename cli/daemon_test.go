@@ -0,0 +1,224 @@
+//go:build !minimal
+
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ivuorinen/gibidify/config"
+	"github.com/ivuorinen/gibidify/daemon"
+	"github.com/ivuorinen/gibidify/fileproc"
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// TestDaemonHandlers tests bundle, stats, and reload-config over the control socket.
+func TestDaemonHandlers(t *testing.T) {
+	srcDir := t.TempDir()
+	testutil.CreateTestFile(t, srcDir, "main.go", []byte(shared.LiteralPackageMain))
+	testutil.SetViperKeys(t, map[string]any{shared.ConfigKeyCodeOwnersEnabled: false})
+	defer testutil.SuppressLogs(t)()
+
+	state := &daemonState{source: srcDir, settings: config.Global(), started: time.Now()}
+	testutil.MustSucceed(t, state.reindex(), "indexing")
+
+	socket := filepath.Join(t.TempDir(), "d.sock")
+	listener, err := daemon.Listen(socket)
+	testutil.MustSucceed(t, err, "listening")
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error, 1)
+	go func() { done <- state.server().Serve(ctx, listener) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	// Files added after indexing are only bundled after a refresh.
+	testutil.CreateTestFile(t, srcDir, "extra.go", []byte(shared.LiteralPackageMain))
+
+	var result bundleResult
+	testutil.MustSucceed(t, daemon.Call(t.Context(), socket, daemonMethodBundle, nil, &result), "bundle")
+	if result.Files != 1 || !strings.Contains(result.Content, "## File: `main.go`") ||
+		strings.Contains(result.Content, "extra.go") {
+		t.Errorf("unexpected bundle from warmed index: %+v", result)
+	}
+
+	dest := filepath.Join(t.TempDir(), "out.json")
+	params := bundleParams{Destination: dest, Format: shared.FormatJSON, Refresh: true}
+	var refreshed bundleResult
+	testutil.MustSucceed(t, daemon.Call(t.Context(), socket, daemonMethodBundle, params, &refreshed), "refresh bundle")
+	if refreshed.Files != 2 || refreshed.Destination != dest || refreshed.Content != "" || refreshed.Request != "2" {
+		t.Errorf("unexpected refreshed bundle result: %+v", refreshed)
+	}
+
+	var stats daemonStats
+	testutil.MustSucceed(t, daemon.Call(t.Context(), socket, daemonMethodStats, nil, &stats), "stats")
+	if stats.Bundles != 2 || stats.Files != 2 || stats.Source != srcDir ||
+		stats.Limits.MaxFiles != shared.ConfigMaxFilesDefault {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+
+	err = daemon.Call(t.Context(), socket, daemonMethodBundle, bundleParams{Format: "xml"}, nil)
+	if err == nil {
+		t.Error("expected error for unsupported format")
+	}
+
+	over := bundleParams{Format: shared.FormatJSON, bundleLimits: bundleLimits{MaxFiles: stats.Limits.MaxFiles + 1}}
+	err = daemon.Call(t.Context(), socket, daemonMethodBundle, over, nil)
+	if err == nil || !strings.Contains(err.Error(), "max_files") {
+		t.Errorf("expected error for a file limit above the configured one, got %v", err)
+	}
+}
+
+// TestDaemonConcurrentReload sends bundle requests from several clients while the config
+// file is rewritten and reloaded, checking every bundle sees one configuration or the other.
+func TestDaemonConcurrentReload(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(max(runtime.GOMAXPROCS(0), stressProcs)))
+	const clients, requests = 4, 5
+
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	writeReloadConfig(t, configHome, "alpha")
+	testutil.ResetViperConfig(t, "")
+	t.Cleanup(func() { testutil.ResetViperConfig(t, "") })
+	defer testutil.SuppressLogs(t)()
+
+	srcDir := t.TempDir()
+	testutil.CreateTestFile(t, srcDir, "main.stress", []byte("stress\n"))
+	testutil.CreateTestFile(t, srcDir, "main.go", []byte(shared.LiteralPackageMain))
+	state := &daemonState{source: srcDir, settings: config.Global(), started: time.Now()}
+	testutil.MustSucceed(t, state.reindex(), "indexing")
+
+	socket := filepath.Join(t.TempDir(), "d.sock")
+	listener, err := daemon.Listen(socket)
+	testutil.MustSucceed(t, err, "listening")
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error, 1)
+	go func() { done <- state.server().Serve(ctx, listener) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	var wg sync.WaitGroup
+	for range clients {
+		wg.Go(func() {
+			for range requests {
+				checkReloadBundle(t, socket)
+			}
+		})
+	}
+	wg.Go(func() {
+		for i := range requests {
+			writeReloadConfig(t, configHome, []string{"beta", "alpha"}[i%2])
+			if err := daemon.Call(ctx, socket, daemonMethodReloadConfig, nil, nil); err != nil {
+				t.Errorf("reload-config: %v", err)
+			}
+		}
+	})
+	wg.Wait()
+
+	var stats daemonStats
+	testutil.MustSucceed(t, daemon.Call(t.Context(), socket, daemonMethodStats, nil, &stats), "stats")
+	if stats.Bundles != clients*requests {
+		t.Errorf("daemon served %d bundles, want %d", stats.Bundles, clients*requests)
+	}
+}
+
+// TestDaemonReloadInvalidConfig tests that reloading a config file that has become invalid fails
+// and keeps the previous configuration, ignore lists included, for later bundles.
+func TestDaemonReloadInvalidConfig(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	writeReloadConfig(t, configHome, "alpha")
+	configFile := filepath.Join(configHome, shared.AppName, "config.yaml")
+	appendFile(t, configFile, "ignoreDirectories: [skipped]\n")
+	defer testutil.SuppressLogs(t)()
+
+	srcDir := t.TempDir()
+	testutil.CreateTestFile(t, srcDir, "main.stress", []byte("stress\n"))
+	testutil.CreateTestFile(t, testutil.CreateTestDirectory(t, srcDir, "skipped"), "main.go", []byte("package main\n"))
+	settings, err := config.LoadSettingsFromEnv()
+	testutil.MustSucceed(t, err, "loading settings")
+	state := &daemonState{source: srcDir, settings: settings, started: time.Now()}
+	testutil.MustSucceed(t, state.reindex(), "indexing")
+
+	appendFile(t, configFile, "fileSizeLimit: 100\n")
+	if _, err := state.handleReloadConfig(t.Context(), nil); err == nil {
+		t.Fatal("reload-config accepted an invalid config file")
+	}
+
+	raw, err := json.Marshal(bundleParams{Format: shared.FormatJSON, Refresh: true})
+	testutil.MustSucceed(t, err, "encoding params")
+	result, err := state.handleBundle(t.Context(), raw)
+	testutil.MustSucceed(t, err, "bundle")
+	var bundle fileproc.OutputData
+	testutil.MustSucceed(t, json.Unmarshal([]byte(result.(bundleResult).Content), &bundle), "decoding bundle")
+	if len(bundle.Files) != 1 || bundle.Files[0].Path != "main.stress" || bundle.Files[0].Language != "alpha" {
+		t.Errorf("bundle files = %+v, want main.stress as alpha with skipped/ ignored", bundle.Files)
+	}
+}
+
+// appendFile appends content to the file at path.
+func appendFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	testutil.MustSucceed(t, err, "reading "+path)
+	testutil.MustSucceed(t, os.WriteFile(path, append(data, content...), shared.TestFilePermission), "writing "+path)
+}
+
+// writeReloadConfig atomically replaces the config file under configHome with one mapping
+// .stress files to language.
+func writeReloadConfig(t *testing.T, configHome, language string) {
+	t.Helper()
+
+	dir := filepath.Join(configHome, shared.AppName)
+	content := "fileTypes:\n  customLanguages:\n    .stress: " + language + "\n"
+	tmp := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.MkdirAll(dir, shared.TestDirPermission); err != nil {
+		t.Errorf("creating config directory: %v", err)
+	}
+	if err := os.WriteFile(tmp, []byte(content), shared.TestFilePermission); err != nil {
+		t.Errorf("writing config: %v", err)
+	}
+	if err := os.Rename(tmp, filepath.Join(dir, "config.yaml")); err != nil {
+		t.Errorf("replacing config: %v", err)
+	}
+}
+
+// checkReloadBundle requests a JSON bundle and checks the .stress file has one of the
+// configured languages.
+func checkReloadBundle(t *testing.T, socket string) {
+	t.Helper()
+
+	var result bundleResult
+	params := bundleParams{Format: shared.FormatJSON}
+	if err := daemon.Call(t.Context(), socket, daemonMethodBundle, params, &result); err != nil {
+		t.Errorf("bundle: %v", err)
+
+		return
+	}
+	var bundle fileproc.OutputData
+	if err := json.Unmarshal([]byte(result.Content), &bundle); err != nil {
+		t.Errorf("decoding bundle: %v", err)
+
+		return
+	}
+	for _, file := range bundle.Files {
+		if file.Path == "main.stress" && file.Language != "alpha" && file.Language != "beta" {
+			t.Errorf("main.stress language = %q, want alpha or beta", file.Language)
+		}
+	}
+	if len(bundle.Files) != 2 {
+		t.Errorf("bundled %d files, want 2", len(bundle.Files))
+	}
+}
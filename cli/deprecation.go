@@ -0,0 +1,74 @@
+// Package cli provides command-line interface functionality for gibidify.
+package cli
+
+import (
+	"flag"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/ivuorinen/gibidify/config"
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// flagDeprecations lists the bundling flags that still work but will be removed, by name.
+// Renaming a flag takes only an entry here: a deprecated flag that is no longer defined
+// becomes an alias sharing the value of its replacement.
+var flagDeprecations = map[string]config.Deprecation{}
+
+// deprecateFlags defines the aliases of renamed flags in fs and marks every deprecated flag
+// in its --help text. Call it once all flags are defined.
+func deprecateFlags(fs *flag.FlagSet) {
+	for _, name := range slices.Sorted(maps.Keys(flagDeprecations)) {
+		deprecation := flagDeprecations[name]
+		if fs.Lookup(name) == nil {
+			replacement := fs.Lookup(deprecation.Replacement)
+			if replacement == nil {
+				continue
+			}
+			fs.Var(replacement.Value, name, replacement.Usage)
+		}
+		f := fs.Lookup(name)
+		f.Usage = "DEPRECATED: " + flagDeprecationMessage(name) + ". " + f.Usage
+	}
+}
+
+// usedDeprecatedFlags returns a message for every deprecated flag set on the command line of
+// the parsed fs, sorted by name.
+func usedDeprecatedFlags(fs *flag.FlagSet) []string {
+	var messages []string
+	fs.Visit(func(f *flag.Flag) {
+		if _, ok := flagDeprecations[f.Name]; ok {
+			messages = append(messages, flagDeprecationMessage(f.Name))
+		}
+	})
+
+	return messages
+}
+
+// flagDeprecationMessage describes the deprecation of the flag name.
+func flagDeprecationMessage(name string) string {
+	deprecation := flagDeprecations[name]
+
+	return deprecation.Message("--"+name, "--"+deprecation.Replacement)
+}
+
+// WarnDeprecations prints one warning listing every deprecated flag and config key the run
+// uses, so users can move off them before they are removed. Call it once the config is loaded.
+func WarnDeprecations(flags *Flags, settings *config.Settings) {
+	messages := append(slices.Clone(flags.deprecated), settings.DeprecatedKeysInUse()...)
+	if len(messages) == 0 {
+		return
+	}
+
+	warning := "Deprecated settings in use; a future release will remove them:\n  - " +
+		strings.Join(messages, "\n  - ")
+	if flags.NoUI {
+		shared.GetLogger().Warn(warning)
+
+		return
+	}
+	ui := NewUIManager()
+	ui.SetColorOutput(!flags.NoColors)
+	ui.PrintWarning("%s", warning)
+}
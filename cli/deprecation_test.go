@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"bytes"
+	"flag"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/config"
+)
+
+// TestDeprecateFlags tests that renamed flags alias their replacement, that deprecated flags
+// are marked in --help, and that only the deprecated flags set are reported.
+func TestDeprecateFlags(t *testing.T) {
+	saved := flagDeprecations
+	flagDeprecations = map[string]config.Deprecation{
+		"show-timings": {Replacement: "timings"},
+		"no-progress":  {Hint: "set ui.progressStyle: none"},
+		"unused":       {Replacement: "missing"},
+		"fsync":        {},
+	}
+	t.Cleanup(func() { flagDeprecations = saved })
+
+	var timings, noProgress, fsync bool
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.BoolVar(&timings, "timings", false, "Print a table of timings")
+	fs.BoolVar(&noProgress, "no-progress", false, "Disable progress bars")
+	fs.BoolVar(&fsync, "fsync", false, "Flush the bundle")
+	deprecateFlags(fs)
+
+	var help bytes.Buffer
+	fs.SetOutput(&help)
+	fs.PrintDefaults()
+	for _, want := range []string{
+		"DEPRECATED: --show-timings is deprecated; use --timings instead. Print a table of timings",
+		"DEPRECATED: --no-progress is deprecated; set ui.progressStyle: none. Disable progress bars",
+		"DEPRECATED: --fsync is deprecated and will be removed. Flush the bundle",
+	} {
+		if !strings.Contains(help.String(), want) {
+			t.Errorf("help does not mark %q:\n%s", want, help.String())
+		}
+	}
+	if strings.Contains(help.String(), "-unused") || strings.Contains(help.String(), "DEPRECATED: --timings") {
+		t.Errorf("help marks flags that are not deprecated or defined:\n%s", help.String())
+	}
+
+	if err := fs.Parse([]string{"-show-timings", "-no-progress"}); err != nil {
+		t.Fatalf("parsing: %v", err)
+	}
+	if !timings || !noProgress {
+		t.Errorf("timings = %v, no-progress = %v, want both set", timings, noProgress)
+	}
+	want := []string{
+		"--no-progress is deprecated; set ui.progressStyle: none",
+		"--show-timings is deprecated; use --timings instead",
+	}
+	if got := usedDeprecatedFlags(fs); !slices.Equal(got, want) {
+		t.Errorf("usedDeprecatedFlags() = %q, want %q", got, want)
+	}
+}
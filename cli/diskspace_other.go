@@ -0,0 +1,14 @@
+//go:build !(linux || darwin || freebsd)
+
+// Package cli provides command-line interface functionality for gibidify.
+package cli
+
+// freeSpace reports that the free space is unknown on this platform.
+func freeSpace(string) (free uint64, ok bool) {
+	return 0, false
+}
+
+// dirWritable assumes dir is writable on this platform; creating the file reports otherwise.
+func dirWritable(string) error {
+	return nil
+}
@@ -0,0 +1,25 @@
+//go:build linux || darwin || freebsd
+
+// Package cli provides command-line interface functionality for gibidify.
+package cli
+
+import "syscall"
+
+// accessWriteOK is the W_OK mode of access(2), the same on every platform this file builds for.
+const accessWriteOK = 0x2
+
+// freeSpace returns the bytes available to unprivileged users on the file system holding dir.
+// ok is false when the free space cannot be determined.
+func freeSpace(dir string) (free uint64, ok bool) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(dir, &stat); err != nil {
+		return 0, false
+	}
+
+	return uint64(stat.Bavail) * uint64(stat.Bsize), true //nolint:unconvert,gosec // field types vary by platform
+}
+
+// dirWritable returns an error when the current user may not create files in dir.
+func dirWritable(dir string) error {
+	return syscall.Access(dir, accessWriteOK) //nolint:wrapcheck // wrapped by checkDestination
+}
@@ -0,0 +1,242 @@
+// Package cli provides command-line interface functionality for gibidify.
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ivuorinen/gibidify/config"
+	"github.com/ivuorinen/gibidify/fileproc"
+	"github.com/ivuorinen/gibidify/gitutil"
+	"github.com/ivuorinen/gibidify/policy"
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+func init() {
+	registerSubcommand(shared.CLISubcommandDoctor, "running doctor", func(_ context.Context, args []string) error {
+		return RunDoctor(os.Stdout, args)
+	})
+}
+
+// Doctor check statuses. Only failed checks make the doctor subcommand exit with an error;
+// unavailable capabilities just disable the features that need them.
+const (
+	doctorOK          = "ok"
+	doctorUnavailable = "unavailable"
+	doctorFailed      = "FAILED"
+)
+
+// DoctorCheck is the result of one self-diagnostic check.
+type DoctorCheck struct {
+	Name   string
+	Status string
+	Detail string
+}
+
+// RunDoctor runs the self-diagnostic and writes the capability matrix to w.
+// It returns an error when a check required for bundling failed.
+func RunDoctor(w io.Writer, args []string) error {
+	fs := flag.NewFlagSet(shared.AppName+" "+shared.CLISubcommandDoctor, flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	checks := runDoctorChecks()
+	if err := writeDoctorMatrix(w, checks); err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOWrite, "failed to write capability matrix")
+	}
+
+	var failed []string
+	for _, check := range checks {
+		if check.Status == doctorFailed {
+			failed = append(failed, check.Name)
+		}
+	}
+	if len(failed) > 0 {
+		return shared.NewStructuredError(
+			shared.ErrorTypeValidation, shared.CodeConfigValidation,
+			"doctor checks failed: "+strings.Join(failed, ", "), "", nil,
+		)
+	}
+
+	return nil
+}
+
+// runDoctorChecks runs every self-diagnostic check.
+func runDoctorChecks() []DoctorCheck {
+	return []DoctorCheck{
+		{
+			Name:   "platform",
+			Status: doctorOK,
+			Detail: fmt.Sprintf("%s/%s, %s", runtime.GOOS, runtime.GOARCH, runtime.Version()),
+		},
+		checkBuild(),
+		checkConfig(),
+		checkPolicy(),
+		checkTempDir(),
+		checkTerminal(),
+		checkGit(),
+		checkClipboard(),
+		checkContainer(),
+	}
+}
+
+// checkBuild reports the optional features compiled into the binary, which the minimal build tag leaves out.
+func checkBuild() DoctorCheck {
+	wasm := "built in"
+	if !fileproc.WasmAvailable() {
+		wasm = "not built in"
+	}
+
+	return DoctorCheck{
+		Name:   "build",
+		Status: doctorOK,
+		Detail: fmt.Sprintf("subcommands: %s; hashes: %s; WASM transforms: %s",
+			strings.Join(Subcommands(), ", "), strings.Join(shared.HashAlgorithms(), ", "), wasm),
+	}
+}
+
+// checkConfig reports which config file is in use and whether it is valid.
+func checkConfig() DoctorCheck {
+	check := DoctorCheck{Name: "config", Status: doctorOK, Detail: "no config file, using defaults"}
+	if err := config.LoadError(); err != nil {
+		check.Status = doctorFailed
+		check.Detail = fmt.Sprintf("invalid config file, falling back to defaults: %v", err)
+
+		return check
+	}
+	if file := config.FileUsed(); file != "" {
+		check.Detail = file
+	}
+
+	return check
+}
+
+// checkPolicy reports the organization policy file, if one is installed.
+func checkPolicy() DoctorCheck {
+	check := DoctorCheck{Name: "policy", Status: doctorOK, Detail: "none"}
+	path := policy.Find()
+	if path == "" {
+		return check
+	}
+	if _, err := policy.Load(path); err != nil {
+		check.Status = doctorFailed
+		check.Detail = err.Error()
+
+		return check
+	}
+	check.Detail = path
+
+	return check
+}
+
+// checkTempDir verifies that temporary files can be created, as prompt templates and the daemon need them.
+func checkTempDir() DoctorCheck {
+	check := DoctorCheck{Name: "temp dir", Status: doctorOK, Detail: os.TempDir() + " is writable"}
+	file, err := os.CreateTemp("", shared.AppName+"-doctor-*")
+	if err != nil {
+		check.Status = doctorFailed
+		check.Detail = err.Error()
+
+		return check
+	}
+	shared.LogError("Error closing doctor temp file", file.Close())
+	shared.LogError("Error removing doctor temp file", os.Remove(file.Name()))
+
+	return check
+}
+
+// checkTerminal reports whether progress bars and colors are available.
+func checkTerminal() DoctorCheck {
+	capabilities := []string{"progress bars", "colors"}
+	available := []bool{isInteractiveTerminal(), isColorTerminal()}
+
+	var enabled, disabled []string
+	for i, capability := range capabilities {
+		if available[i] {
+			enabled = append(enabled, capability)
+		} else {
+			disabled = append(disabled, capability)
+		}
+	}
+	if len(enabled) == 0 {
+		return DoctorCheck{Name: "terminal", Status: doctorUnavailable, Detail: "not a terminal: no progress bars or colors"}
+	}
+
+	detail := strings.Join(enabled, ", ")
+	if len(disabled) > 0 {
+		detail += " (no " + strings.Join(disabled, ", ") + ")"
+	}
+
+	return DoctorCheck{Name: "terminal", Status: doctorOK, Detail: detail}
+}
+
+// checkGit reports the git version used for --author, blame and CODEOWNERS features.
+func checkGit() DoctorCheck {
+	if !gitutil.Available() {
+		return DoctorCheck{Name: "git", Status: doctorUnavailable, Detail: "git not found in PATH; git features are disabled"}
+	}
+	out, err := gitutil.Git{}.Run(".", "--version")
+	if err != nil {
+		return DoctorCheck{Name: "git", Status: doctorFailed, Detail: err.Error()}
+	}
+
+	return DoctorCheck{Name: "git", Status: doctorOK, Detail: strings.TrimSpace(string(out))}
+}
+
+// checkClipboard reports the clipboard writer found on this platform.
+func checkClipboard() DoctorCheck {
+	if len(clipboardCommands()) == 0 {
+		return DoctorCheck{Name: "clipboard", Status: doctorUnavailable, Detail: "not built in (minimal build)"}
+	}
+	command := findClipboardCommand()
+	if command == nil {
+		names := make([]string, 0, len(clipboardCommands()))
+		for _, c := range clipboardCommands() {
+			names = append(names, c[0])
+		}
+
+		return DoctorCheck{
+			Name: "clipboard", Status: doctorUnavailable, Detail: "none of " + strings.Join(names, ", ") + " found",
+		}
+	}
+
+	return DoctorCheck{Name: "clipboard", Status: doctorOK, Detail: strings.Join(command, " ")}
+}
+
+// checkContainer reports the detected container limits and the defaults derived from them.
+func checkContainer() DoctorCheck {
+	limits := config.DetectContainerLimits()
+	detail := "no cgroup limits"
+	if limits != (config.ContainerLimits{}) {
+		detail = fmt.Sprintf("cpus=%.2f, memory=%dMB", limits.CPUs, limits.MemoryBytes/int64(shared.BytesPerMB))
+	}
+
+	return DoctorCheck{
+		Name:   "resources",
+		Status: doctorOK,
+		Detail: fmt.Sprintf("%s; default concurrency=%d, hardMemoryLimitMB=%d",
+			detail, config.DefaultConcurrency(), config.DefaultHardMemoryLimitMB()),
+	}
+}
+
+// writeDoctorMatrix writes checks as an aligned table.
+func writeDoctorMatrix(w io.Writer, checks []DoctorCheck) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if _, err := fmt.Fprintln(tw, "CHECK\tSTATUS\tDETAIL"); err != nil {
+		return err
+	}
+	for _, check := range checks {
+		if _, err := fmt.Fprintf(tw, "%s\t%s\t%s\n", check.Name, check.Status, check.Detail); err != nil {
+			return err
+		}
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,38 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// TestRunDoctor tests that the capability matrix lists every check and that failed
+// checks make the command fail.
+func TestRunDoctor(t *testing.T) {
+	t.Setenv(shared.PolicyEnvVar, "")
+	testutil.ResetViperConfig(t, "")
+
+	var out bytes.Buffer
+	testutil.MustSucceed(t, RunDoctor(&out, nil), "RunDoctor")
+	for _, name := range []string{"CHECK", "platform", "build", "config", "temp dir", "terminal", "git", "clipboard"} {
+		if !strings.Contains(out.String(), name) {
+			t.Errorf("capability matrix missing %q:\n%s", name, out.String())
+		}
+	}
+
+	policyFile := testutil.CreateTestFile(t, t.TempDir(), shared.PolicyFileName, []byte("maxInputSize: -1\n"))
+	t.Setenv(shared.PolicyEnvVar, policyFile)
+	out.Reset()
+	err := RunDoctor(&out, nil)
+	var structErr *shared.StructuredError
+	if !errors.As(err, &structErr) || !strings.Contains(err.Error(), "policy") {
+		t.Errorf("expected the policy check to fail, got %v", err)
+	}
+	if !strings.Contains(out.String(), "FAILED") {
+		t.Errorf("failed check not shown:\n%s", out.String())
+	}
+}
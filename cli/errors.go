@@ -112,7 +112,9 @@ func (ef *ErrorFormatter) provideValidationSuggestions(err *shared.StructuredErr
 
 	switch err.Code {
 	case shared.CodeValidationFormat:
-		ef.ui.printf("  • Use a supported format: markdown, json, yaml\n")
+		ef.ui.printf(
+			"  • Use a supported format: markdown, json, yaml, plain, pdf, zip, or one registered in output.plugins\n",
+		)
 		ef.ui.printf("  • Example: -format markdown\n")
 	case shared.CodeValidationSize:
 		ef.ui.printf("  • Increase file size limit in config.yaml\n")
@@ -271,6 +273,8 @@ func IsUserError(err error) bool {
 	structErr := &shared.StructuredError{}
 	if errors.As(err, &structErr) {
 		return structErr.Type == shared.ErrorTypeValidation ||
+			structErr.Code == shared.CodeCLIOverwriteDeclined ||
+			structErr.Code == shared.CodeCLIShareDeclined ||
 			structErr.Code == shared.CodeValidationFormat ||
 			structErr.Code == shared.CodeValidationSize
 	}
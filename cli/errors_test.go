@@ -475,6 +475,7 @@ func createTestUI() (*UIManager, *bytes.Buffer) {
 	ui := &UIManager{
 		enableColors:   false, // Disable colors for consistent testing
 		enableProgress: false, // Disable progress for testing
+		theme:          unicodeTheme,
 		output:         output,
 	}
 
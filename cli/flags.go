@@ -5,26 +5,93 @@ import (
 	"flag"
 	"fmt"
 	"os"
-	"runtime"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/ivuorinen/gibidify/config"
+	"github.com/ivuorinen/gibidify/fileproc"
 	"github.com/ivuorinen/gibidify/shared"
 )
 
 // Flags holds CLI flags values.
 type Flags struct {
-	SourceDir   string
-	Destination string
-	Prefix      string
-	Suffix      string
-	Concurrency int
-	Format      string
-	NoColors    bool
-	NoProgress  bool
-	NoUI        bool
-	Verbose     bool
-	ShowVersion bool
-	LogLevel    string
+	SourceDir        string
+	Destination      string
+	Stdout           bool
+	Prefix           string
+	Suffix           string
+	Concurrency      int
+	CPUs             int
+	Format           string
+	NoColors         bool
+	NoProgress       bool
+	NoUI             bool
+	Verbose          bool
+	ShowVersion      bool
+	LogLevel         string
+	Author           string
+	Owner            string
+	FromPatch        string
+	AppendPatch      bool
+	Prelude          string
+	PromptTemplate   string
+	Append           bool
+	Index            string
+	Depfile          string
+	Sign             string
+	Signature        string
+	Config           string
+	Hermetic         bool
+	RestrictTo       string
+	PolicyOverride   bool
+	Timings          bool
+	Hotspots         int
+	WarningsAsErrors bool
+	DocLanguage      string
+	RespectGitignore bool
+	Tests            string
+	Coverage         string
+	MaxCoverage      float64
+	Findings         string
+	Order            string
+	Include          []string
+	Exclude          []string
+	Fsync            bool
+	PreviewDiff      bool
+	Every            time.Duration
+	Keep             int
+	NoCache          bool
+	NoProjectConfig  bool
+	Profile          string
+	Share            bool
+	StripComments    bool
+	Compact          bool
+	// AutoExcludeOutliers excludes the files over collection.outlierPercent without asking.
+	AutoExcludeOutliers bool
+	// SplitSize is the --split-size limit of every part, in bytes or, with SplitTokens, tokens.
+	SplitSize   int64
+	SplitTokens bool
+	// Chaos lists the failures to inject with --chaos, which only chaos builds register.
+	Chaos string
+	// Report is the format of the --report run report, ReportFile where it is written (stderr
+	// when empty or -).
+	Report     string
+	ReportFile string
+
+	// deprecated holds a message for every deprecated flag set on the command line.
+	deprecated []string
+	// respectGitignoreSet reports whether --respect-gitignore was given, overriding the config.
+	respectGitignoreSet bool
+	// maxCoverageSet reports whether --max-coverage was given; 0 keeps only uncovered files.
+	maxCoverageSet bool
+	// formatSet reports whether -format was given, overriding the format of --profile.
+	formatSet bool
+	// destinationSet reports whether -destination was given rather than derived from the format.
+	destinationSet bool
 }
 
 var (
@@ -51,12 +118,16 @@ func ParseFlags() (*Flags, error) {
 
 	fs := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
 	fs.StringVar(&flags.SourceDir, shared.CLIArgSource, "", "Source directory to scan recursively")
-	fs.StringVar(&flags.Destination, "destination", "", "Output file to write aggregated code")
+	fs.StringVar(&flags.Destination, "destination", "", "Output file to write aggregated code; - writes to stdout")
+	fs.BoolVar(&flags.Stdout, "stdout", false, "Write the bundle to stdout instead of a file, for shell pipelines")
 	fs.StringVar(&flags.Prefix, "prefix", "", "Text to add at the beginning of the output file")
 	fs.StringVar(&flags.Suffix, "suffix", "", "Text to add at the end of the output file")
-	fs.StringVar(&flags.Format, shared.CLIArgFormat, shared.FormatJSON, "Output format (json, markdown, yaml)")
-	fs.IntVar(&flags.Concurrency, shared.CLIArgConcurrency, runtime.NumCPU(),
-		"Number of concurrent workers (default: number of CPU cores)")
+	fs.StringVar(&flags.Format, shared.CLIArgFormat, shared.FormatJSON,
+		"Output format (json, markdown, yaml, plain, pdf, zip, or an output.plugins format)")
+	fs.IntVar(&flags.Concurrency, shared.CLIArgConcurrency, config.DefaultConcurrency(),
+		"Number of concurrent workers (default: number of CPU cores, or the container CPU limit)")
+	fs.IntVar(&flags.CPUs, "cpus", 0,
+		"Limit to N CPUs: sets GOMAXPROCS and the default --concurrency (default: all CPUs or the container limit)")
 	fs.BoolVar(&flags.NoColors, "no-colors", false, "Disable colored output")
 	fs.BoolVar(&flags.NoProgress, "no-progress", false, "Disable progress bars")
 	fs.BoolVar(&flags.NoUI, "no-ui", false, "Disable all UI output (implies no-colors and no-progress)")
@@ -66,9 +137,135 @@ func ParseFlags() (*Flags, error) {
 		&flags.LogLevel, "log-level", string(shared.LogLevelWarn), "Set log level (debug, info, warn, error)",
 	)
 
+	fs.StringVar(&flags.Author, "author", "",
+		"Include only files where authors matching this regex own at least git.authorThreshold percent of lines")
+
+	fs.StringVar(&flags.Owner, "owner", "", "Include only files owned by this CODEOWNERS owner (e.g. @org/team)")
+	fs.StringVar(&flags.Tests, "tests", shared.TestsInclude,
+		"Test files (foo_test.go, foo.spec.ts, test_foo.py, ...): include, exclude, or only")
+	fs.StringVar(&flags.Coverage, "coverage", "",
+		"Annotate files with their test coverage from a Go cover profile (cover.out) or LCOV report (lcov.info)")
+	fs.Float64Var(&flags.MaxCoverage, "max-coverage", 0,
+		"With --coverage, include only files in the report covered at most this percent")
+	fs.StringVar(&flags.Findings, "findings", "",
+		"Attach static analysis findings from a SARIF or golangci-lint JSON report to the files they name")
+	fs.StringVar(&flags.Order, "order", "",
+		"Order files by: alphabetical, size (smallest first), recency (last committed first), depth "+
+			"(shallowest first), priority (order.priority globs) or complexity (most complex first)")
+	fs.Func("include", "Include only files matching this glob relative to the source (e.g. '**/*.go'); repeatable",
+		func(pattern string) error {
+			flags.Include = append(flags.Include, pattern)
+			return nil
+		})
+	fs.Func("exclude", "Leave out files matching this glob relative to the source (e.g. '**/*_test.go'); repeatable",
+		func(pattern string) error {
+			flags.Exclude = append(flags.Exclude, pattern)
+			return nil
+		})
+
+	fs.StringVar(&flags.FromPatch, "from-patch", "",
+		"Bundle only the files referenced by this unified diff (.patch/.diff) instead of scanning the source")
+	fs.BoolVar(&flags.AppendPatch, "append-patch", false, "Append the --from-patch diff itself as a final entry")
+
+	fs.StringVar(&flags.Prelude, "prelude", "",
+		"Comma-separated context documents placed before the file sections (overrides output.prelude)")
+
+	fs.StringVar(&flags.PromptTemplate, "prompt-template", "",
+		"Go text/template file that wraps the whole bundle (available as {{.Bundle}}) in an LLM prompt")
+
+	fs.BoolVar(&flags.Append, "append", false,
+		"Append the bundle to an existing destination after a separator header instead of overwriting it")
+	fs.BoolVar(&flags.Fsync, "fsync", false,
+		"Flush the bundle to stable storage before the destination is closed")
+	fs.BoolVar(&flags.PreviewDiff, "preview-diff", false,
+		"When the destination exists, show the files added, removed and changed and ask before overwriting it")
+	fs.DurationVar(&flags.Every, "every", 0,
+		"Keep running and regenerate the bundle at this interval (e.g. 1h) until interrupted")
+	fs.IntVar(&flags.Keep, "keep", 0,
+		"Keep only the newest N bundles written to a destination containing "+shared.DestinationTimestampPlaceholder)
+
+	fs.StringVar(&flags.Index, "index", "",
+		"Write a JSON index of file sections (path, language, size, byte offset) in the bundle to this file")
+	fs.StringVar(&flags.Config, "config", "",
+		"Read configuration from this file instead of searching the config directories (default $"+
+			shared.ConfigEnvVar+")")
+	fs.StringVar(&flags.Profile, "profile", "",
+		"Apply the settings of this profile from the profiles config section over the configuration")
+	fs.BoolVar(&flags.NoProjectConfig, "no-project-config", false,
+		"Do not merge the "+shared.ProjectConfigFileName+" found in or above the source directory over the configuration")
+	fs.BoolVar(&flags.Hermetic, "hermetic", false,
+		"Run for hermetic build systems: no config or policy from the home directory, an isolated git "+
+			"and writes only to declared outputs")
+	fs.StringVar(&flags.RestrictTo, "restrict-to", "",
+		"Comma-separated directories every file read must resolve into, following symlinks")
+	fs.StringVar(&flags.Depfile, "depfile", "",
+		"Write a make-compatible dependency file listing the bundled files as prerequisites of the destination")
+	fs.BoolVar(&flags.Share, "share", false,
+		"Upload the finished bundle to the share.backend paste service after confirming, and print its URL")
+	fs.Func("split-size",
+		"Write the bundle as parts (output.part1.md, ...) of at most this size: bytes with an optional KB or MB "+
+			"suffix, or estimated tokens (e.g. 100000tokens, 100ktokens)",
+		func(value string) error {
+			size, tokens, err := parseSplitSize(value)
+			flags.SplitSize, flags.SplitTokens = size, tokens
+
+			return err
+		})
+	fs.StringVar(&flags.Sign, "sign", "",
+		"Sign the bundle and its --index with this Ed25519 private key (PKCS #8 PEM) in a detached signature")
+	fs.StringVar(&flags.Signature, "signature", "",
+		"With --sign, write the signature to this file (default: <destination>.sig)")
+
+	fs.BoolVar(&flags.PolicyOverride, "policy-override", false,
+		"Run even though the configuration violates the organization policy; the override is audit-logged")
+
+	fs.StringVar(&flags.Report, "report", "",
+		"Also write a machine-readable run report in this format (json): files included and skipped with "+
+			"reasons, sizes, phase durations, limit hits and redactions")
+	fs.StringVar(&flags.ReportFile, "report-file", "",
+		"With --report, write the run report to this file (default: stderr)")
+	fs.BoolVar(&flags.Timings, "timings", false,
+		"Print a table of the time spent per phase (read, transform, format, write) and per worker")
+	fs.IntVar(&flags.Hotspots, "hotspots", 0,
+		"Print the N slowest files with their size and the phase they spent most time in")
+	fs.BoolVar(&flags.NoCache, "no-cache", false,
+		"Read and transform every file instead of bundling unchanged files from the content cache")
+	fs.BoolVar(&flags.WarningsAsErrors, "warnings-as-errors", false,
+		"Fail the run when it finished with warnings (invalid UTF-8, Markdown fence collisions, slow files)")
+
+	fs.StringVar(&flags.DocLanguage, "doc-language", "",
+		"Comma-separated natural languages (e.g. en,fi) Markdown and text documentation must be written in; "+
+			"documentation detected in other languages is left out")
+
+	fs.BoolVar(&flags.StripComments, "strip-comments", false,
+		"Remove comments from Go, JavaScript/TypeScript, Python and C-family source files to save tokens; "+
+			"stripComments.languages selects the languages")
+
+	fs.BoolVar(&flags.Compact, "compact", false,
+		"Collapse runs of blank lines, trim trailing whitespace and, with output.compact.maxLineLength, "+
+			"truncate long lines to keep the bundle small")
+
+	fs.BoolVar(&flags.AutoExcludeOutliers, "auto-exclude-outliers", false,
+		"Exclude files taking more than collection.outlierPercent of the collected size without asking")
+
+	fs.BoolVar(&flags.RespectGitignore, "respect-gitignore", true,
+		"Leave out files git ignores (.gitignore files, .git/info/exclude); overrides collection.respectGitignore")
+	registerChaosFlag(fs, flags)
+
+	deprecateFlags(fs)
 	if err := fs.Parse(os.Args[1:]); err != nil {
 		return nil, err
 	}
+	flags.deprecated = usedDeprecatedFlags(fs)
+	fs.Visit(func(set *flag.Flag) {
+		flags.respectGitignoreSet = flags.respectGitignoreSet || set.Name == "respect-gitignore"
+		flags.maxCoverageSet = flags.maxCoverageSet || set.Name == "max-coverage"
+		flags.formatSet = flags.formatSet || set.Name == shared.CLIArgFormat
+		flags.destinationSet = flags.destinationSet || set.Name == "destination"
+	})
+	if flags.Config == "" {
+		flags.Config = os.Getenv(shared.ConfigEnvVar)
+	}
 
 	// --version is a terminal action that does not require source/destination validation.
 	if flags.ShowVersion {
@@ -77,6 +274,10 @@ func ParseFlags() (*Flags, error) {
 		return flags, nil
 	}
 
+	if err := flags.applyCPUs(fs); err != nil {
+		return nil, err
+	}
+
 	if err := flags.validate(); err != nil {
 		return nil, err
 	}
@@ -91,6 +292,31 @@ func ParseFlags() (*Flags, error) {
 	return flags, nil
 }
 
+// applyCPUs applies --cpus: it sets GOMAXPROCS and derives the worker count from it,
+// unless --concurrency was given explicitly.
+func (f *Flags) applyCPUs(fs *flag.FlagSet) error {
+	if f.CPUs < 0 {
+		return shared.NewStructuredError(
+			shared.ErrorTypeCLI, shared.CodeCLIInvalidArgs,
+			fmt.Sprintf("--cpus must not be negative, got %d", f.CPUs), "", nil,
+		)
+	}
+	if f.CPUs == 0 {
+		return nil
+	}
+
+	config.SetCPUs(f.CPUs)
+	concurrencySet := false
+	fs.Visit(func(set *flag.Flag) {
+		concurrencySet = concurrencySet || set.Name == shared.CLIArgConcurrency
+	})
+	if !concurrencySet {
+		f.Concurrency = config.DefaultConcurrency()
+	}
+
+	return nil
+}
+
 // validate validates the CLI flags.
 func (f *Flags) validate() error {
 	if f.SourceDir == "" {
@@ -102,8 +328,8 @@ func (f *Flags) validate() error {
 		return fmt.Errorf("validating source path: %w", err)
 	}
 
-	// Validate output format
-	if err := config.ValidateOutputFormat(f.Format); err != nil {
+	// Validate output format; plugin formats are checked once the configuration is loaded
+	if err := config.ValidateOutputFormatName(f.Format); err != nil {
 		return fmt.Errorf("validating output format: %w", err)
 	}
 
@@ -117,23 +343,439 @@ func (f *Flags) validate() error {
 		return fmt.Errorf("invalid log level: %s (must be: debug, info, warn, error)", f.LogLevel)
 	}
 
+	// Validate patch options
+	if err := f.validatePatch(); err != nil {
+		return err
+	}
+	if f.PreviewDiff && f.Append {
+		return shared.NewStructuredError(
+			shared.ErrorTypeCLI, shared.CodeCLIInvalidArgs, "--preview-diff cannot be combined with --append", "", nil,
+		)
+	}
+	if err := f.validateSchedule(); err != nil {
+		return err
+	}
+	if err := f.validateHermetic(); err != nil {
+		return err
+	}
+	if err := f.validateOutputs(); err != nil {
+		return err
+	}
+	if err := f.validateCollection(); err != nil {
+		return err
+	}
+
+	if err := f.validateInputs(); err != nil {
+		return err
+	}
+
+	// Validate author pattern
+	if f.Author != "" {
+		if _, err := regexp.Compile(f.Author); err != nil {
+			return fmt.Errorf("invalid author pattern: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// validatePatch validates the --from-patch and --append-patch flags.
+func (f *Flags) validatePatch() error {
+	if f.FromPatch == "" {
+		if f.AppendPatch {
+			return shared.NewStructuredError(
+				shared.ErrorTypeCLI, shared.CodeCLIInvalidArgs, "--append-patch requires --from-patch", "", nil,
+			)
+		}
+
+		return nil
+	}
+
+	return validateInputFile("patch", f.FromPatch)
+}
+
+// validateSchedule validates the --every and --keep flags.
+func (f *Flags) validateSchedule() error {
+	var message string
+	switch {
+	case f.Every != 0 && f.Every < minEvery:
+		message = fmt.Sprintf("--every must be at least %s, got %s", minEvery, f.Every)
+	case f.Every != 0 && f.PreviewDiff:
+		message = "--every cannot be combined with --preview-diff"
+	case f.Every != 0 && f.Share:
+		message = "--every cannot be combined with --share"
+	case f.Keep < 0:
+		message = fmt.Sprintf("--keep must not be negative, got %d", f.Keep)
+	case f.Keep > 0 && !strings.Contains(filepath.Base(f.Destination), shared.DestinationTimestampPlaceholder):
+		message = "--keep requires " + shared.DestinationTimestampPlaceholder + " in the destination file name"
+	default:
+		return nil
+	}
+
+	return shared.NewStructuredError(shared.ErrorTypeCLI, shared.CodeCLIInvalidArgs, message, "", nil)
+}
+
+// validateCollection validates the flags selecting which files are bundled.
+func (f *Flags) validateCollection() error {
+	switch f.Tests {
+	case "", shared.TestsInclude, shared.TestsExclude, shared.TestsOnly:
+	default:
+		return shared.NewStructuredError(
+			shared.ErrorTypeCLI, shared.CodeCLIInvalidArgs,
+			fmt.Sprintf("invalid --tests: %s (must be: include, exclude, only)", f.Tests), "", nil,
+		)
+	}
+	if err := config.ValidateDocLanguages(f.DocLanguages()); err != nil {
+		return fmt.Errorf("validating --doc-language: %w", err)
+	}
+
+	if f.Order != "" && !slices.Contains(shared.OrderStrategies, f.Order) {
+		return shared.NewStructuredError(
+			shared.ErrorTypeCLI, shared.CodeCLIInvalidArgs,
+			fmt.Sprintf("invalid --order: %s (must be: %s)", f.Order, strings.Join(shared.OrderStrategies, ", ")), "", nil,
+		)
+	}
+
+	if _, err := fileproc.NewGlobFilter(f.SourceDir, f.Include, f.Exclude); err != nil {
+		return fmt.Errorf("validating --include and --exclude: %w", err)
+	}
+
+	if err := f.validateCoverage(); err != nil {
+		return err
+	}
+	if f.Findings != "" {
+		return validateInputFile("findings report", f.Findings)
+	}
+
+	return nil
+}
+
+// validateCoverage validates the --coverage and --max-coverage flags.
+func (f *Flags) validateCoverage() error {
+	var message string
+	switch {
+	case f.maxCoverageSet && f.Coverage == "":
+		message = "--max-coverage requires --coverage"
+	case f.MaxCoverage < 0 || f.MaxCoverage > 100:
+		message = fmt.Sprintf("--max-coverage must be between 0 and 100, got %g", f.MaxCoverage)
+	case f.Coverage != "":
+		return validateInputFile("coverage report", f.Coverage)
+	default:
+		return nil
+	}
+
+	return shared.NewStructuredError(shared.ErrorTypeCLI, shared.CodeCLIInvalidArgs, message, "", nil)
+}
+
+// validateInputs checks that the config file, prelude documents, prompt template and signing
+// key exist.
+func (f *Flags) validateInputs() error {
+	if f.Config != "" {
+		if err := validateInputFile("config", f.Config); err != nil {
+			return err
+		}
+	}
+	for _, path := range f.PreludeFiles() {
+		if err := validateInputFile("prelude", path); err != nil {
+			return err
+		}
+	}
+	if f.PromptTemplate != "" {
+		if err := validateInputFile("prompt template", f.PromptTemplate); err != nil {
+			return err
+		}
+	}
+
+	return f.validateSigning()
+}
+
+// validateSigning validates the --sign and --signature flags.
+func (f *Flags) validateSigning() error {
+	if f.Sign == "" {
+		if f.Signature != "" {
+			return shared.NewStructuredError(
+				shared.ErrorTypeCLI, shared.CodeCLIInvalidArgs, "--signature requires --sign", "", nil,
+			)
+		}
+
+		return nil
+	}
+
+	return validateInputFile("signing key", f.Sign)
+}
+
+// ToStdout reports whether the bundle is written to stdout, with --stdout or -destination -.
+func (f *Flags) ToStdout() bool {
+	return f.Stdout || f.Destination == "-"
+}
+
+// validateStdout rejects the flags that need a destination file when writing to stdout.
+func (f *Flags) validateStdout() error {
+	if !f.ToStdout() {
+		return nil
+	}
+
+	var message string
+	switch {
+	case f.Stdout && f.Destination != "" && f.Destination != "-":
+		message = "--stdout cannot be combined with -destination " + f.Destination
+	case f.Append:
+		message = "--append cannot write to stdout"
+	case f.PreviewDiff:
+		message = "--preview-diff cannot write to stdout"
+	case f.Every != 0 || f.Keep != 0:
+		message = "--every and --keep cannot write to stdout"
+	case f.Depfile != "":
+		message = "--depfile needs a destination file, not stdout"
+	case f.Sign != "":
+		message = "--sign needs a destination file, not stdout"
+	case f.Share:
+		message = "--share needs a destination file, not stdout"
+	default:
+		return nil
+	}
+
+	return shared.NewStructuredError(shared.ErrorTypeCLI, shared.CodeCLIInvalidArgs, message, "", nil)
+}
+
+// validateOutputs validates the flags choosing where and how the bundle file is written.
+func (f *Flags) validateOutputs() error {
+	if err := f.validateStdout(); err != nil {
+		return err
+	}
+	if err := f.validateBinaryFormat(); err != nil {
+		return err
+	}
+	if err := f.validateReport(); err != nil {
+		return err
+	}
+
+	return f.validateSplit()
+}
+
+// validateBinaryFormat rejects the flags that read, extend or cut the bundle as text, which a
+// PDF or zip bundle is not.
+func (f *Flags) validateBinaryFormat() error {
+	if f.Format != shared.FormatPDF && f.Format != shared.FormatZip {
+		return nil
+	}
+
+	var conflict string
+	switch {
+	case f.Append:
+		conflict = "--append"
+	case f.PreviewDiff:
+		conflict = "--preview-diff"
+	case f.SplitSize != 0:
+		conflict = "--split-size"
+	case f.PromptTemplate != "":
+		conflict = "--prompt-template"
+	case f.Index != "":
+		conflict = "--index"
+	default:
+		return nil
+	}
+
+	return shared.NewStructuredError(
+		shared.ErrorTypeCLI, shared.CodeCLIInvalidArgs, "-format "+f.Format+" cannot be combined with "+conflict, "", nil,
+	)
+}
+
+// validateReport validates the --report and --report-file flags.
+func (f *Flags) validateReport() error {
+	var message string
+	switch {
+	case f.Report != "" && f.Report != shared.FormatJSON:
+		message = fmt.Sprintf("invalid --report: %s (must be: json)", f.Report)
+	case f.Report == "" && f.ReportFile != "":
+		message = "--report-file requires --report"
+	default:
+		return nil
+	}
+
+	return shared.NewStructuredError(shared.ErrorTypeCLI, shared.CodeCLIInvalidArgs, message, "", nil)
+}
+
+// validateSplit rejects the flags that need the bundle in a single file when --split-size
+// writes it as parts.
+func (f *Flags) validateSplit() error {
+	if f.SplitSize == 0 {
+		return nil
+	}
+
+	var conflict string
+	switch {
+	case f.ToStdout():
+		return shared.NewStructuredError(
+			shared.ErrorTypeCLI, shared.CodeCLIInvalidArgs, "--split-size needs a destination file, not stdout", "", nil,
+		)
+	case f.Append:
+		conflict = "--append"
+	case f.PreviewDiff:
+		conflict = "--preview-diff"
+	case f.Keep != 0:
+		conflict = "--keep"
+	case f.PromptTemplate != "":
+		conflict = "--prompt-template"
+	case f.Index != "":
+		conflict = "--index"
+	case f.Depfile != "":
+		conflict = "--depfile"
+	case f.Sign != "":
+		conflict = "--sign"
+	case f.Share:
+		conflict = "--share"
+	default:
+		return nil
+	}
+
+	return shared.NewStructuredError(
+		shared.ErrorTypeCLI, shared.CodeCLIInvalidArgs, "--split-size cannot be combined with "+conflict, "", nil,
+	)
+}
+
+// splitSizeUnits are the suffixes --split-size accepts, longest first among those sharing an ending.
+var splitSizeUnits = []struct {
+	suffix     string
+	multiplier int64
+	tokens     bool
+}{
+	{suffix: "ktokens", multiplier: 1000, tokens: true},
+	{suffix: "tokens", multiplier: 1, tokens: true},
+	{suffix: "kb", multiplier: shared.BytesPerKB},
+	{suffix: "mb", multiplier: shared.BytesPerMB},
+	{suffix: "b", multiplier: 1},
+}
+
+// parseSplitSize parses a --split-size value into a positive limit and whether it counts tokens.
+func parseSplitSize(value string) (int64, bool, error) {
+	number, multiplier, tokens := strings.ToLower(strings.TrimSpace(value)), int64(1), false
+	for _, unit := range splitSizeUnits {
+		if n, ok := strings.CutSuffix(number, unit.suffix); ok {
+			number, multiplier, tokens = strings.TrimSpace(n), unit.multiplier, unit.tokens
+
+			break
+		}
+	}
+
+	n, err := strconv.ParseInt(number, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, false, fmt.Errorf("invalid size %q, want positive bytes (e.g. 500KB) or tokens (e.g. 100ktokens)", value)
+	}
+
+	return n * multiplier, tokens, nil
+}
+
+// validateHermetic rejects the flags a hermetic run cannot honour: runs that never finish
+// or wait for input, writes outside the declared outputs, and an implied destination.
+func (f *Flags) validateHermetic() error {
+	if !f.Hermetic {
+		return nil
+	}
+
+	var message string
+	switch {
+	case f.Destination == "" && !f.ToStdout():
+		message = "--hermetic requires an explicit -destination or --stdout"
+	case f.Every != 0:
+		message = "--hermetic cannot be combined with --every"
+	case f.PreviewDiff:
+		message = "--hermetic cannot be combined with --preview-diff"
+	case f.PolicyOverride:
+		message = "--hermetic cannot be combined with --policy-override, which writes the audit log"
+	case f.Share:
+		message = "--hermetic cannot be combined with --share, which uploads the bundle"
+	default:
+		return nil
+	}
+
+	return shared.NewStructuredError(shared.ErrorTypeCLI, shared.CodeCLIInvalidArgs, message, "", nil)
+}
+
+// validateInputFile checks that a user-supplied input file exists and is not a directory.
+func validateInputFile(kind, path string) error {
+	if info, err := os.Stat(path); err != nil || info.IsDir() {
+		return shared.NewStructuredError(
+			shared.ErrorTypeFileSystem, shared.CodeFSNotFound, kind+" file not found: "+path, path, nil,
+		)
+	}
+
 	return nil
 }
 
+// PreludeFiles returns the --prelude documents in order, skipping empty entries.
+func (f *Flags) PreludeFiles() []string {
+	return splitPathList(f.Prelude)
+}
+
+// RestrictRoots returns the --restrict-to directories, skipping empty entries.
+func (f *Flags) RestrictRoots() []string {
+	return splitPathList(f.RestrictTo)
+}
+
+// DocLanguages returns the lowercase natural language codes given with --doc-language.
+func (f *Flags) DocLanguages() []string {
+	languages := splitPathList(f.DocLanguage)
+	for i, language := range languages {
+		languages[i] = strings.ToLower(language)
+	}
+
+	return languages
+}
+
+// splitPathList splits a comma-separated flag value into trimmed, non-empty paths.
+func splitPathList(value string) []string {
+	var paths []string
+	for _, path := range strings.Split(value, ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			paths = append(paths, path)
+		}
+	}
+
+	return paths
+}
+
+// formatExtension returns the file extension, without the dot, of bundles written in format.
+func formatExtension(format string) string {
+	if format == shared.FormatPlain {
+		return shared.FormatPlainExtension
+	}
+
+	return format
+}
+
 // setDefaultDestination sets the default destination if not provided.
 func (f *Flags) setDefaultDestination() error {
-	if f.Destination == "" {
+	if f.ToStdout() {
+		f.Stdout = true
+		f.Destination = ""
+	}
+	if f.Destination == "" && !f.Stdout {
 		absRoot, err := shared.AbsolutePath(f.SourceDir)
 		if err != nil {
 			return fmt.Errorf("getting absolute path: %w", err)
 		}
 		baseName := shared.BaseName(absRoot)
-		f.Destination = baseName + "." + f.Format
+		f.Destination = baseName + "." + formatExtension(f.Format)
 	}
 
 	// Validate destination path for security
-	if err := shared.ValidateDestinationPath(f.Destination); err != nil {
-		return fmt.Errorf("validating destination path: %w", err)
+	if f.Destination != "" {
+		if err := shared.ValidateDestinationPath(f.Destination); err != nil {
+			return fmt.Errorf("validating destination path: %w", err)
+		}
+	}
+
+	if f.Index != "" {
+		if err := shared.ValidateDestinationPath(f.Index); err != nil {
+			return fmt.Errorf("validating index path: %w", err)
+		}
+	}
+
+	if f.Depfile != "" {
+		if err := shared.ValidateDestinationPath(f.Depfile); err != nil {
+			return fmt.Errorf("validating depfile path: %w", err)
+		}
 	}
 
 	return nil
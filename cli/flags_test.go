@@ -3,10 +3,13 @@ package cli
 import (
 	"flag"
 	"os"
+	"path/filepath"
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/ivuorinen/gibidify/config"
 	"github.com/ivuorinen/gibidify/shared"
 	"github.com/ivuorinen/gibidify/testutil"
 )
@@ -156,7 +159,7 @@ func TestParseFlags(t *testing.T) {
 		},
 		{
 			name:        "invalid format",
-			args:        []string{shared.TestCLIFlagSource, "testdir", shared.TestCLIFlagFormat, "invalid"},
+			args:        []string{shared.TestCLIFlagSource, "testdir", shared.TestCLIFlagFormat, "invalid!"},
 			wantErr:     true,
 			errContains: "validating output format",
 		},
@@ -183,6 +186,137 @@ func TestParseFlags(t *testing.T) {
 	}
 }
 
+// TestParseSplitSize tests the byte and token limits --split-size accepts.
+func TestParseSplitSize(t *testing.T) {
+	tests := []struct {
+		value      string
+		want       int64
+		wantTokens bool
+		wantErr    bool
+	}{
+		{value: "4096", want: 4096},
+		{value: "500KB", want: 500 * shared.BytesPerKB},
+		{value: "2 mb", want: 2 * shared.BytesPerMB},
+		{value: "100000tokens", want: 100000, wantTokens: true},
+		{value: "100ktokens", want: 100000, wantTokens: true},
+		{value: "0", wantErr: true},
+		{value: "-5KB", wantErr: true},
+		{value: "1.5MB", wantErr: true},
+		{value: "tokens", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		size, tokens, err := parseSplitSize(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseSplitSize(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+
+			continue
+		}
+		if size != tt.want || tokens != tt.wantTokens {
+			t.Errorf("parseSplitSize(%q) = %d, %v, want %d, %v", tt.value, size, tokens, tt.want, tt.wantTokens)
+		}
+	}
+}
+
+// TestParseFlagsCPUs tests that --cpus sets GOMAXPROCS and the default worker count.
+func TestParseFlagsCPUs(t *testing.T) {
+	procs := runtime.GOMAXPROCS(0)
+	t.Cleanup(func() {
+		runtime.GOMAXPROCS(procs)
+		config.SetCPUs(0)
+	})
+
+	tests := []struct {
+		name        string
+		args        []string
+		want        *Flags
+		wantErr     bool
+		errContains string
+	}{
+		{
+			name: "cpus derives concurrency",
+			args: []string{shared.TestCLIFlagSource, "testdir", "-cpus", "2"},
+			want: &Flags{
+				SourceDir:   "testdir",
+				Format:      shared.FormatJSON,
+				Concurrency: 2,
+				Destination: "testdir.json",
+				LogLevel:    string(shared.LogLevelWarn),
+			},
+		},
+		{
+			name: "explicit concurrency wins",
+			args: []string{shared.TestCLIFlagSource, "testdir", "-cpus", "2", shared.TestCLIFlagConcurrency, "3"},
+			want: &Flags{
+				SourceDir:   "testdir",
+				Format:      shared.FormatJSON,
+				Concurrency: 3,
+				Destination: "testdir.json",
+				LogLevel:    string(shared.LogLevelWarn),
+			},
+		},
+		{
+			name:        "negative cpus",
+			args:        []string{shared.TestCLIFlagSource, "testdir", "-cpus", "-1"},
+			wantErr:     true,
+			errContains: "--cpus must not be negative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runParseFlagsTest(t, tt.args, tt.want, tt.wantErr, tt.errContains)
+			if !tt.wantErr && runtime.GOMAXPROCS(0) != 2 {
+				t.Errorf("GOMAXPROCS = %d, want 2", runtime.GOMAXPROCS(0))
+			}
+		})
+	}
+}
+
+// TestParseFlagsConfigEnv tests that $GIBIDIFY_CONFIG names the config file unless --config is
+// given, and that a missing one is an error.
+func TestParseFlagsConfigEnv(t *testing.T) {
+	dir := t.TempDir()
+	fromEnv := testutil.CreateTestFile(t, dir, "env.yaml", []byte("fileSizeLimit: 123456\n"))
+	fromFlag := testutil.CreateTestFile(t, dir, "flag.yaml", []byte("fileSizeLimit: 123456\n"))
+	origArgs := os.Args
+	t.Cleanup(func() { os.Args = origArgs })
+
+	tests := []struct {
+		name    string
+		env     string
+		args    []string
+		want    string
+		wantErr bool
+	}{
+		{name: "environment", env: fromEnv, want: fromEnv},
+		{name: "flag wins", env: fromEnv, args: []string{"-config", fromFlag}, want: fromFlag},
+		{name: "neither", want: ""},
+		{name: "missing file", env: filepath.Join(dir, "missing.yaml"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(shared.ConfigEnvVar, tt.env)
+			resetFlagsState()
+			setupCommandLineArgs(append([]string{shared.TestCLIFlagSource, dir, "-stdout"}, tt.args...))
+
+			flags, err := ParseFlags()
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "config file not found") {
+					t.Errorf("ParseFlags() error = %v, want config file not found", err)
+				}
+
+				return
+			}
+			testutil.MustSucceed(t, err, "ParseFlags")
+			if flags.Config != tt.want {
+				t.Errorf("Config = %q, want %q", flags.Config, tt.want)
+			}
+		})
+	}
+}
+
 // validateFlagsValidationResult validates flag validation test results.
 func validateFlagsValidationResult(t *testing.T, err error, wantErr bool, errContains string) {
 	t.Helper()
@@ -238,7 +372,7 @@ func TestFlagsvalidate(t *testing.T) {
 			name: "invalid format",
 			flags: &Flags{
 				SourceDir:   tempDir,
-				Format:      "invalid",
+				Format:      "invalid!",
 				Concurrency: 4,
 				LogLevel:    "warn",
 			},
@@ -278,6 +412,381 @@ func TestFlagsvalidate(t *testing.T) {
 			wantErr:     true,
 			errContains: "invalid log level",
 		},
+		{
+			name: "invalid author pattern",
+			flags: &Flags{
+				SourceDir:   tempDir,
+				Format:      "json",
+				Concurrency: 4,
+				LogLevel:    "warn",
+				Author:      "(unclosed",
+			},
+			wantErr:     true,
+			errContains: "invalid author pattern",
+		},
+		{
+			name: "unsupported documentation language",
+			flags: &Flags{
+				SourceDir:   tempDir,
+				Format:      "json",
+				Concurrency: 4,
+				LogLevel:    "warn",
+				DocLanguage: "en, xx",
+			},
+			wantErr:     true,
+			errContains: "validating --doc-language",
+		},
+		{
+			name: "invalid tests mode",
+			flags: &Flags{
+				SourceDir:   tempDir,
+				Format:      "json",
+				Concurrency: 4,
+				LogLevel:    "warn",
+				Tests:       "skip",
+			},
+			wantErr:     true,
+			errContains: "invalid --tests",
+		},
+		{
+			name: "max coverage without report",
+			flags: &Flags{
+				SourceDir:      tempDir,
+				Format:         "json",
+				Concurrency:    4,
+				LogLevel:       "warn",
+				MaxCoverage:    50,
+				maxCoverageSet: true,
+			},
+			wantErr:     true,
+			errContains: "--max-coverage requires --coverage",
+		},
+		{
+			name: "signature without sign",
+			flags: &Flags{
+				SourceDir:   tempDir,
+				Format:      "json",
+				Concurrency: 4,
+				LogLevel:    "warn",
+				Signature:   "bundle.sig",
+			},
+			wantErr:     true,
+			errContains: "--signature requires --sign",
+		},
+		{
+			name: "invalid order",
+			flags: &Flags{
+				SourceDir:   tempDir,
+				Format:      "json",
+				Concurrency: 4,
+				LogLevel:    "warn",
+				Order:       "random",
+			},
+			wantErr:     true,
+			errContains: "invalid --order: random",
+		},
+		{
+			name: "invalid exclude glob",
+			flags: &Flags{
+				SourceDir:   tempDir,
+				Format:      "json",
+				Concurrency: 4,
+				LogLevel:    "warn",
+				Include:     []string{"**/*.go"},
+				Exclude:     []string{"vendor/[a-"},
+			},
+			wantErr:     true,
+			errContains: `invalid glob "vendor/[a-"`,
+		},
+		{
+			name: "missing findings report",
+			flags: &Flags{
+				SourceDir:   tempDir,
+				Format:      "json",
+				Concurrency: 4,
+				LogLevel:    "warn",
+				Findings:    tempDir + "/report.sarif",
+			},
+			wantErr:     true,
+			errContains: "findings report file not found",
+		},
+		{
+			name: "stdout with destination file",
+			flags: &Flags{
+				SourceDir:   tempDir,
+				Format:      "json",
+				Concurrency: 4,
+				LogLevel:    "warn",
+				Stdout:      true,
+				Destination: "out.json",
+			},
+			wantErr:     true,
+			errContains: "--stdout cannot be combined",
+		},
+		{
+			name: "append to stdout",
+			flags: &Flags{
+				SourceDir:   tempDir,
+				Format:      "json",
+				Concurrency: 4,
+				LogLevel:    "warn",
+				Destination: "-",
+				Append:      true,
+			},
+			wantErr:     true,
+			errContains: "--append cannot write to stdout",
+		},
+		{
+			name: "share to stdout",
+			flags: &Flags{
+				SourceDir:   tempDir,
+				Format:      "json",
+				Concurrency: 4,
+				LogLevel:    "warn",
+				Destination: "-",
+				Share:       true,
+			},
+			wantErr:     true,
+			errContains: "--share needs a destination file",
+		},
+		{
+			name: "split to stdout",
+			flags: &Flags{
+				SourceDir:   tempDir,
+				Format:      "json",
+				Concurrency: 4,
+				LogLevel:    "warn",
+				Destination: "-",
+				SplitSize:   shared.BytesPerMB,
+			},
+			wantErr:     true,
+			errContains: "--split-size needs a destination file",
+		},
+		{
+			name: "split with index",
+			flags: &Flags{
+				SourceDir:   tempDir,
+				Format:      "json",
+				Concurrency: 4,
+				LogLevel:    "warn",
+				SplitSize:   shared.BytesPerMB,
+				Index:       tempDir + "/index.json",
+			},
+			wantErr:     true,
+			errContains: "--split-size cannot be combined with --index",
+		},
+		{
+			name: "pdf with append",
+			flags: &Flags{
+				SourceDir:   tempDir,
+				Format:      "pdf",
+				Concurrency: 4,
+				LogLevel:    "warn",
+				Append:      true,
+			},
+			wantErr:     true,
+			errContains: "-format pdf cannot be combined with --append",
+		},
+		{
+			name: "zip with prompt template",
+			flags: &Flags{
+				SourceDir:      tempDir,
+				Format:         "zip",
+				Concurrency:    4,
+				LogLevel:       "warn",
+				PromptTemplate: "review",
+			},
+			wantErr:     true,
+			errContains: "-format zip cannot be combined with --prompt-template",
+		},
+		{
+			name: "unknown report format",
+			flags: &Flags{
+				SourceDir:   tempDir,
+				Format:      "json",
+				Concurrency: 4,
+				LogLevel:    "warn",
+				Report:      "xml",
+			},
+			wantErr:     true,
+			errContains: "invalid --report: xml",
+		},
+		{
+			name: "report file without report",
+			flags: &Flags{
+				SourceDir:   tempDir,
+				Format:      "json",
+				Concurrency: 4,
+				LogLevel:    "warn",
+				ReportFile:  tempDir + "/report.json",
+			},
+			wantErr:     true,
+			errContains: "--report-file requires --report",
+		},
+		{
+			name: "missing patch file",
+			flags: &Flags{
+				SourceDir:   tempDir,
+				Format:      "json",
+				Concurrency: 4,
+				LogLevel:    "warn",
+				FromPatch:   tempDir + "/missing.patch",
+			},
+			wantErr:     true,
+			errContains: "patch file not found",
+		},
+		{
+			name: "append patch without patch",
+			flags: &Flags{
+				SourceDir:   tempDir,
+				Format:      "json",
+				Concurrency: 4,
+				LogLevel:    "warn",
+				AppendPatch: true,
+			},
+			wantErr:     true,
+			errContains: "--append-patch requires --from-patch",
+		},
+		{
+			name: "preview diff with append",
+			flags: &Flags{
+				SourceDir:   tempDir,
+				Format:      "json",
+				Concurrency: 4,
+				LogLevel:    "warn",
+				Append:      true,
+				PreviewDiff: true,
+			},
+			wantErr:     true,
+			errContains: "--preview-diff cannot be combined with --append",
+		},
+		{
+			name: "every below minimum",
+			flags: &Flags{
+				SourceDir:   tempDir,
+				Format:      "json",
+				Concurrency: 4,
+				LogLevel:    "warn",
+				Every:       500 * time.Millisecond,
+			},
+			wantErr:     true,
+			errContains: "--every must be at least 1s",
+		},
+		{
+			name: "every with preview diff",
+			flags: &Flags{
+				SourceDir:   tempDir,
+				Format:      "json",
+				Concurrency: 4,
+				LogLevel:    "warn",
+				Every:       time.Hour,
+				PreviewDiff: true,
+			},
+			wantErr:     true,
+			errContains: "--every cannot be combined with --preview-diff",
+		},
+		{
+			name: "keep without timestamp",
+			flags: &Flags{
+				SourceDir:   tempDir,
+				Destination: tempDir + "/bundle.md",
+				Format:      "markdown",
+				Concurrency: 4,
+				LogLevel:    "warn",
+				Keep:        3,
+			},
+			wantErr:     true,
+			errContains: "--keep requires {{timestamp}}",
+		},
+		{
+			name: "keep with timestamp",
+			flags: &Flags{
+				SourceDir:   tempDir,
+				Destination: tempDir + "/bundle-{{timestamp}}.md",
+				Format:      "markdown",
+				Concurrency: 4,
+				LogLevel:    "warn",
+				Every:       time.Hour,
+				Keep:        3,
+			},
+			wantErr: false,
+		},
+		{
+			name: "hermetic without destination",
+			flags: &Flags{
+				SourceDir:   tempDir,
+				Format:      "json",
+				Concurrency: 4,
+				LogLevel:    "warn",
+				Hermetic:    true,
+			},
+			wantErr:     true,
+			errContains: "--hermetic requires an explicit -destination",
+		},
+		{
+			name: "hermetic with policy override",
+			flags: &Flags{
+				SourceDir:      tempDir,
+				Destination:    tempDir + "/out.json",
+				Format:         "json",
+				Concurrency:    4,
+				LogLevel:       "warn",
+				Hermetic:       true,
+				PolicyOverride: true,
+			},
+			wantErr:     true,
+			errContains: "--hermetic cannot be combined with --policy-override",
+		},
+		{
+			name: "hermetic with share",
+			flags: &Flags{
+				SourceDir:   tempDir,
+				Destination: tempDir + "/out.json",
+				Format:      "json",
+				Concurrency: 4,
+				LogLevel:    "warn",
+				Hermetic:    true,
+				Share:       true,
+			},
+			wantErr:     true,
+			errContains: "--hermetic cannot be combined with --share",
+		},
+		{
+			name: "hermetic with destination",
+			flags: &Flags{
+				SourceDir:   tempDir,
+				Destination: tempDir + "/out.json",
+				Format:      "json",
+				Concurrency: 4,
+				LogLevel:    "warn",
+				Hermetic:    true,
+			},
+			wantErr: false,
+		},
+		{
+			name: "missing config file",
+			flags: &Flags{
+				SourceDir:   tempDir,
+				Format:      "json",
+				Concurrency: 4,
+				LogLevel:    "warn",
+				Config:      tempDir + "/missing.yaml",
+			},
+			wantErr:     true,
+			errContains: "config file not found",
+		},
+		{
+			name: "missing prelude file",
+			flags: &Flags{
+				SourceDir:   tempDir,
+				Format:      "json",
+				Concurrency: 4,
+				LogLevel:    "warn",
+				Prelude:     tempDir + "/missing.md",
+			},
+			wantErr:     true,
+			errContains: "prelude file not found",
+		},
 	}
 
 	for _, tt := range tests {
@@ -366,6 +875,36 @@ func TestFlagssetDefaultDestination(t *testing.T) {
 			wantDestination: baseName + ".yaml",
 			wantErr:         false,
 		},
+		{
+			name: "plain format uses txt extension",
+			flags: &Flags{
+				SourceDir: tempDir,
+				Format:    "plain",
+				LogLevel:  "warn",
+			},
+			wantDestination: baseName + ".txt",
+			wantErr:         false,
+		},
+		{
+			name: "pdf format uses pdf extension",
+			flags: &Flags{
+				SourceDir: tempDir,
+				Format:    "pdf",
+				LogLevel:  "warn",
+			},
+			wantDestination: baseName + ".pdf",
+			wantErr:         false,
+		},
+		{
+			name: "zip format uses zip extension",
+			flags: &Flags{
+				SourceDir: tempDir,
+				Format:    "zip",
+				LogLevel:  "warn",
+			},
+			wantDestination: baseName + ".zip",
+			wantErr:         false,
+		},
 		{
 			name: "preserve existing destination",
 			flags: &Flags{
@@ -387,6 +926,17 @@ func TestFlagssetDefaultDestination(t *testing.T) {
 			wantDestination: "exist.markdown", // Based on filepath.Base of the path
 			wantErr:         false,            // AbsolutePath doesn't validate existence, only converts to absolute
 		},
+		{
+			name: "stdout has no destination",
+			flags: &Flags{
+				SourceDir:   tempDir,
+				Format:      "json",
+				Destination: "-",
+				LogLevel:    "warn",
+			},
+			wantDestination: "",
+			wantErr:         false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,109 @@
+// Package cli provides command-line interface functionality for gibidify.
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/ivuorinen/gibidify/config"
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+func init() {
+	registerSubcommand(shared.CLISubcommandInit, "writing config file", func(_ context.Context, args []string) error {
+		return RunInit(os.Stdout, args)
+	})
+}
+
+// InitFlags holds flags for the init subcommand.
+type InitFlags struct {
+	Path   string
+	Force  bool
+	Stdout bool
+}
+
+// ParseInitFlags parses the arguments following the init subcommand.
+func ParseInitFlags(args []string) (*InitFlags, error) {
+	flags := &InitFlags{}
+
+	fs := flag.NewFlagSet(shared.AppName+" "+shared.CLISubcommandInit, flag.ContinueOnError)
+	fs.StringVar(&flags.Path, "path", "", "Config file to write (default $XDG_CONFIG_HOME/gibidify/config.yaml)")
+	fs.BoolVar(&flags.Force, "force", false, "Overwrite an existing config file")
+	fs.BoolVar(&flags.Stdout, "stdout", false, "Write the config to stdout instead of a file")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, shared.NewStructuredError(
+			shared.ErrorTypeValidation, shared.CodeCLIInvalidArgs,
+			"usage: gibidify init [-path <file>] [-force] [-stdout]", "", nil,
+		)
+	}
+
+	return flags, nil
+}
+
+// RunInit writes a config file listing every setting at its default value, commented out,
+// to the file LoadConfig reads first, or to w with -stdout. An existing file is only
+// replaced with -force.
+func RunInit(w io.Writer, args []string) error {
+	flags, err := ParseInitFlags(args)
+	if err != nil {
+		return err
+	}
+
+	document, err := config.DefaultConfigYAML()
+	if err != nil {
+		return err
+	}
+	if flags.Stdout {
+		if _, err := w.Write(document); err != nil {
+			return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOWrite, "failed to write config")
+		}
+
+		return nil
+	}
+
+	path := flags.Path
+	if path == "" {
+		if path, err = config.UserConfigFile(); err != nil {
+			return err
+		}
+	}
+	if err := writeConfigFile(path, document, flags.Force); err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(w, "Wrote %s\n", path); err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOWrite, "failed to write config")
+	}
+
+	return nil
+}
+
+// writeConfigFile writes document to path, creating its directory; an existing file is
+// only replaced when force is set.
+func writeConfigFile(path string, document []byte, force bool) error {
+	if _, err := os.Stat(path); err == nil && !force {
+		return shared.NewStructuredError(
+			shared.ErrorTypeValidation, shared.CodeCLIInvalidArgs,
+			"config file already exists; use -force to overwrite it", path, nil,
+		)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOFileCreate, "failed to create config directory").
+			WithFilePath(path)
+	}
+	if err := os.WriteFile(path, document, shared.OutputFilePermission); err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOFileWrite, "failed to write config file").
+			WithFilePath(path)
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+func TestRunInit(t *testing.T) {
+	xdg := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+	path := filepath.Join(xdg, "gibidify", "config.yaml")
+
+	var out bytes.Buffer
+	testutil.MustSucceed(t, RunInit(&out, nil), "RunInit")
+	if !strings.Contains(out.String(), path) {
+		t.Errorf("RunInit() output = %q, want it to name %s", out.String(), path)
+	}
+	written, err := os.ReadFile(path)
+	testutil.MustSucceed(t, err, "reading the written config")
+	if !strings.Contains(string(written), "# fileSizeLimit: ") {
+		t.Errorf("RunInit() wrote %q, want the commented default settings", written)
+	}
+
+	if err := RunInit(&out, nil); err == nil {
+		t.Error("RunInit() over an existing config succeeded, want an error")
+	}
+	testutil.MustSucceed(t, RunInit(&out, []string{"-force"}), "RunInit -force")
+
+	out.Reset()
+	testutil.MustSucceed(t, RunInit(&out, []string{"-stdout"}), "RunInit -stdout")
+	if out.String() != string(written) {
+		t.Errorf("RunInit -stdout = %q, want %q", out.String(), written)
+	}
+}
+
+func TestParseInitFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    InitFlags
+		wantErr bool
+	}{
+		{name: "defaults", args: nil},
+		{
+			name: "explicit path",
+			args: []string{"-path", "gibidify.yaml", "-force"},
+			want: InitFlags{Path: "gibidify.yaml", Force: true},
+		},
+		{name: "stdout", args: []string{"-stdout"}, want: InitFlags{Stdout: true}},
+		{name: "positional argument", args: []string{"config.yaml"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseInitFlags(tt.args)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseInitFlags(%q) succeeded, want an error", tt.args)
+				}
+
+				return
+			}
+			testutil.MustSucceed(t, err, "ParseInitFlags")
+			if *got != tt.want {
+				t.Errorf("ParseInitFlags(%q) = %+v, want %+v", tt.args, *got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,232 @@
+//go:build !minimal
+
+// Package cli provides command-line interface functionality for gibidify.
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/ivuorinen/gibidify/config"
+	"github.com/ivuorinen/gibidify/fileproc"
+	"github.com/ivuorinen/gibidify/github"
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+func init() {
+	registerSubcommand(shared.CLISubcommandPR, "bundling pull request", RunPR)
+}
+
+// PRFlags holds flags for the pr subcommand.
+type PRFlags struct {
+	Ref         github.PRRef
+	Destination string
+	Format      string
+	APIURL      string
+	NoIssues    bool
+	NoUI        bool
+}
+
+// ParsePRFlags parses the arguments following the pr subcommand.
+func ParsePRFlags(args []string) (*PRFlags, error) {
+	flags := &PRFlags{}
+
+	fs := flag.NewFlagSet(shared.AppName+" "+shared.CLISubcommandPR, flag.ContinueOnError)
+	fs.StringVar(&flags.Destination, "destination", "", "Output file (default: <repo>-pr-<number>.<format>)")
+	fs.StringVar(&flags.Format, shared.CLIArgFormat, shared.FormatMarkdown,
+		"Output format (json, markdown, yaml, plain, pdf, zip, or an output.plugins format)")
+	fs.StringVar(&flags.APIURL, "api-url", envOr("GITHUB_API_URL", github.DefaultAPIURL), "GitHub API base URL")
+	fs.BoolVar(&flags.NoIssues, "no-issues", false, "Do not fetch issues linked from the description")
+	fs.BoolVar(&flags.NoUI, "no-ui", false, "Disable all UI output")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() != 1 {
+		return nil, shared.NewStructuredError(
+			shared.ErrorTypeValidation,
+			shared.CodeCLIInvalidArgs,
+			"usage: gibidify pr [flags] https://github.com/<owner>/<repo>/pull/<number>",
+			"",
+			nil,
+		)
+	}
+
+	ref, err := github.ParsePRURL(fs.Arg(0))
+	if err != nil {
+		return nil, err
+	}
+	flags.Ref = ref
+
+	if err := config.ValidateOutputFormat(flags.Format); err != nil {
+		return nil, fmt.Errorf("validating output format: %w", err)
+	}
+	if flags.Destination == "" {
+		flags.Destination = fmt.Sprintf("%s-pr-%d.%s", ref.Repo, ref.Number, formatExtension(flags.Format))
+	}
+	if err := shared.ValidateDestinationPath(flags.Destination); err != nil {
+		return nil, fmt.Errorf("validating destination path: %w", err)
+	}
+
+	return flags, nil
+}
+
+// RunPR fetches a GitHub pull request and writes its review bundle.
+func RunPR(ctx context.Context, args []string) error {
+	flags, err := ParsePRFlags(args)
+	if err != nil {
+		return err
+	}
+
+	ui := NewUIManager()
+	ui.SetSilentMode(flags.NoUI)
+	ui.PrintHeader(ui.theme.start+"Bundling pull request %s", flags.Ref)
+
+	client := github.NewClient(flags.APIURL, github.TokenFromEnv())
+	entries, err := buildPRBundle(ctx, client, flags.Ref, !flags.NoIssues)
+	if err != nil {
+		return err
+	}
+
+	if err := writeEntries(flags.Destination, flags.Format, entries); err != nil {
+		return err
+	}
+	ui.PrintSuccess("Pull request bundle saved to %s", flags.Destination)
+
+	return nil
+}
+
+// buildPRBundle fetches the pull request, its changed files, and optionally its
+// linked issues, and returns them as output entries in that order.
+func buildPRBundle(
+	ctx context.Context,
+	client *github.Client,
+	ref github.PRRef,
+	withIssues bool,
+) ([]fileproc.WriteRequest, error) {
+	pr, err := client.PullRequest(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	files, err := client.PullRequestFiles(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]fileproc.WriteRequest, 0, len(files)+1)
+	entries = append(entries, textEntry(
+		fmt.Sprintf("pull/%d", pr.Number),
+		describePullRequest(pr),
+		shared.MetadataRoleDescription,
+		pr.Title,
+		pr.HTMLURL,
+	))
+
+	logger := shared.GetLogger()
+	for _, file := range files {
+		if file.Patch == "" {
+			logger.Debugf("Skipping %s: no textual diff available", file.Filename)
+
+			continue
+		}
+		entries = append(entries, fileEntry(file))
+	}
+
+	if !withIssues {
+		return entries, nil
+	}
+	for _, issueRef := range github.LinkedIssues(pr.Body, ref) {
+		issue, err := client.Issue(ctx, issueRef)
+		if err != nil {
+			logger.Warnf("Skipping linked issue %s: %v", issueRef, err)
+
+			continue
+		}
+		entries = append(entries, textEntry(
+			"issues/"+strconv.Itoa(issue.Number), describeIssue(issue), shared.MetadataRoleIssue, issue.Title, issue.HTMLURL,
+		))
+	}
+
+	return entries, nil
+}
+
+// fileEntry converts a changed file into a diff entry.
+func fileEntry(file github.ChangedFile) fileproc.WriteRequest {
+	meta := map[string]string{
+		shared.MetadataKeyRole:    shared.MetadataRolePatch,
+		shared.MetadataKeyStatus:  file.Status,
+		shared.MetadataKeyChanges: fmt.Sprintf("+%d -%d", file.Additions, file.Deletions),
+	}
+	if file.PreviousFilename != "" {
+		meta[shared.MetadataKeyPreviousPath] = file.PreviousFilename
+	}
+
+	return fileproc.WriteRequest{
+		Path:     file.Filename,
+		Content:  file.Patch,
+		Size:     int64(len(file.Patch)),
+		Metadata: meta,
+	}
+}
+
+// textEntry builds a markdown entry for pull request or issue text.
+func textEntry(path, content, role, title, url string) fileproc.WriteRequest {
+	return fileproc.WriteRequest{
+		Path:    path,
+		Content: content,
+		Size:    int64(len(content)),
+		Metadata: map[string]string{
+			shared.MetadataKeyRole:  role,
+			shared.MetadataKeyTitle: title,
+			shared.MetadataKeyURL:   url,
+		},
+	}
+}
+
+// describePullRequest renders the pull request header and description as markdown.
+func describePullRequest(pr *github.PullRequest) string {
+	return fmt.Sprintf(
+		"# %s\n\nAuthor: @%s\nState: %s\nBranches: %s <- %s\n\n%s",
+		pr.Title, pr.User.Login, pr.State, pr.Base.Ref, pr.Head.Ref, pr.Body,
+	)
+}
+
+// describeIssue renders an issue header and body as markdown.
+func describeIssue(issue *github.Issue) string {
+	return fmt.Sprintf("# %s\n\nAuthor: @%s\nState: %s\n\n%s", issue.Title, issue.User.Login, issue.State, issue.Body)
+}
+
+// writeEntries writes prepared entries to destination in the given format.
+func writeEntries(destination, format string, entries []fileproc.WriteRequest) error {
+	outFile, err := os.Create(destination) // #nosec G304 - destination is validated in ParsePRFlags
+	if err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOFileCreate, "failed to create output file").
+			WithFilePath(destination)
+	}
+	defer func() {
+		shared.LogError("Error closing output file", outFile.Close())
+	}()
+
+	writeCh := make(chan fileproc.WriteRequest, len(entries))
+	done := make(chan struct{})
+	go fileproc.StartWriter(outFile, writeCh, done, format, "", "")
+	for _, entry := range entries {
+		writeCh <- entry
+	}
+	close(writeCh)
+	<-done
+
+	return nil
+}
+
+// envOr returns the environment variable name, or fallback when it is unset or empty.
+func envOr(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+
+	return fallback
+}
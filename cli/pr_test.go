@@ -0,0 +1,92 @@
+//go:build !minimal
+
+package cli
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/github"
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// newFakeGitHub serves a pull request with one text and one binary file and a linked issue.
+func newFakeGitHub(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	responses := map[string]any{
+		"/repos/org/repo/pulls/7": github.PullRequest{
+			Number: 7, Title: "Add widget", Body: "Fixes #3", State: "open",
+			HTMLURL: "https://github.com/org/repo/pull/7", User: github.User{Login: "alice"},
+		},
+		"/repos/org/repo/pulls/7/files": []github.ChangedFile{
+			{Filename: "widget.go", Status: "added", Additions: 1, Patch: "@@ -0,0 +1 @@\n+package widget"},
+			{Filename: "logo.png", Status: "added"},
+		},
+		"/repos/org/repo/issues/3": github.Issue{Number: 3, Title: "Need a widget", Body: "Please."},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp, ok := responses[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// TestRunPR tests that the pr subcommand bundles description, diffs, and linked issues.
+func TestRunPR(t *testing.T) {
+	server := newFakeGitHub(t)
+	dest := filepath.Join(t.TempDir(), "bundle.md")
+	defer testutil.SuppressLogs(t)()
+
+	err := RunPR(t.Context(), []string{
+		"-api-url", server.URL, "-destination", dest, "-no-ui", "https://github.com/org/repo/pull/7",
+	})
+	testutil.MustSucceed(t, err, "RunPR")
+
+	content, err := os.ReadFile(dest)
+	testutil.MustSucceed(t, err, "reading bundle")
+	out := string(content)
+
+	for _, want := range []string{
+		"## File: `pull/7`", "# Add widget", "## File: `widget.go`", "```diff\n@@ -0,0 +1 @@", "## File: `issues/3`",
+		"Need a widget",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("bundle missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "logo.png") {
+		t.Error("binary file without a patch should be skipped")
+	}
+	if strings.Index(out, "pull/7") > strings.Index(out, "widget.go") {
+		t.Error("description should precede the file diffs")
+	}
+}
+
+// TestParsePRFlags tests pr subcommand argument validation and defaults.
+func TestParsePRFlags(t *testing.T) {
+	flags, err := ParsePRFlags([]string{"-format", shared.FormatJSON, "org/repo#12"})
+	testutil.MustSucceed(t, err, "parsing pr flags")
+	if flags.Destination != "repo-pr-12.json" {
+		t.Errorf("default destination = %s, want repo-pr-12.json", flags.Destination)
+	}
+
+	for _, args := range [][]string{{}, {"not-a-pr"}, {"-format", "xml", "org/repo#1"}} {
+		if _, err := ParsePRFlags(args); err == nil {
+			t.Errorf("ParsePRFlags(%v) expected error", args)
+		}
+	}
+}
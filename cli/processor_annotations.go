@@ -0,0 +1,82 @@
+// Package cli provides command-line interface functionality for gibidify.
+package cli
+
+import (
+	"errors"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// applyAnnotations registers an annotator that attaches user-authored notes to the files
+// they name. Notes come from the .gibidify-notes.yaml sidecar in the source directory and
+// the annotations config map, which overrides the sidecar for the same path.
+func (p *Processor) applyAnnotations() error {
+	path := filepath.Join(p.flags.SourceDir, shared.AnnotationsFileName)
+	if err := p.restriction.check("notes file", path); err != nil {
+		return err
+	}
+	notes, err := loadAnnotationsFile(path)
+	if err != nil {
+		return err
+	}
+	maps.Copy(notes, normalizeAnnotations(p.settings.Annotations()))
+	if len(notes) == 0 {
+		return nil
+	}
+
+	p.annotators = append(p.annotators, func(_, relPath string) map[string]string {
+		if note := notes[annotationKey(relPath)]; note != "" {
+			return map[string]string{shared.MetadataKeyNote: note}
+		}
+
+		return nil
+	})
+
+	return nil
+}
+
+// loadAnnotationsFile reads a sidecar map of path to note. A missing file holds no notes.
+func loadAnnotationsFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- sidecar file at a fixed name in the source directory
+	if errors.Is(err, fs.ErrNotExist) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIORead, "failed to read annotations").
+			WithFilePath(path)
+	}
+
+	var notes map[string]string
+	if err := yaml.Unmarshal(data, &notes); err != nil {
+		return nil, shared.WrapError(
+			err, shared.ErrorTypeConfiguration, shared.CodeConfigValidation, "invalid annotations file",
+		).WithFilePath(path)
+	}
+
+	return normalizeAnnotations(notes), nil
+}
+
+// normalizeAnnotations keys notes by annotationKey and drops empty notes.
+func normalizeAnnotations(notes map[string]string) map[string]string {
+	normalized := make(map[string]string, len(notes))
+	for path, note := range notes {
+		if note = strings.TrimSpace(note); note != "" {
+			normalized[annotationKey(path)] = note
+		}
+	}
+
+	return normalized
+}
+
+// annotationKey returns the lookup key of a path: clean, slash-separated and lowercased,
+// because the annotations config map loses the case of its keys.
+func annotationKey(path string) string {
+	return strings.ToLower(filepath.ToSlash(filepath.Clean(path)))
+}
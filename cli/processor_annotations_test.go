@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// TestApplyAnnotations tests that notes from the sidecar file and the config map are attached
+// to the files they name, with the config map taking precedence.
+func TestApplyAnnotations(t *testing.T) {
+	dir := t.TempDir()
+	testutil.CreateTestFile(t, dir, shared.AnnotationsFileName, []byte(
+		"cmd/Main.go: Entry point, start here\nutil.go: Old note\nempty.go: \"\"\n",
+	))
+	testutil.SetViperKeys(t, map[string]any{
+		shared.ConfigKeyAnnotations: map[string]string{"util.go": "Helpers shared by every command"},
+	})
+
+	p := NewProcessor(WithFlags(&Flags{SourceDir: dir}))
+	testutil.MustSucceed(t, p.applyAnnotations(), "applyAnnotations")
+	if len(p.annotators) != 1 {
+		t.Fatalf("annotators = %d, want 1", len(p.annotators))
+	}
+
+	tests := []struct {
+		relPath string
+		want    string
+	}{
+		{relPath: "cmd/Main.go", want: "Entry point, start here"},
+		{relPath: "cmd/main.go", want: "Entry point, start here"},
+		{relPath: "util.go", want: "Helpers shared by every command"},
+		{relPath: "empty.go", want: ""},
+		{relPath: "other.go", want: ""},
+	}
+	for _, tt := range tests {
+		if got := p.annotators[0]("", tt.relPath)[shared.MetadataKeyNote]; got != tt.want {
+			t.Errorf("note for %s = %q, want %q", tt.relPath, got, tt.want)
+		}
+	}
+}
+
+// TestApplyAnnotationsInvalidFile tests that a malformed sidecar file is reported.
+func TestApplyAnnotationsInvalidFile(t *testing.T) {
+	dir := t.TempDir()
+	testutil.CreateTestFile(t, dir, shared.AnnotationsFileName, []byte("- not\n- a map\n"))
+	testutil.SetViperKeys(t, map[string]any{})
+
+	p := NewProcessor(WithFlags(&Flags{SourceDir: dir}))
+	testutil.VerifyStructuredError(t, p.applyAnnotations(), shared.ErrorTypeConfiguration, shared.CodeConfigValidation)
+}
+
+// TestProcessorAnnotationsMarkdown tests that a multi-line note is rendered above the file content.
+func TestProcessorAnnotationsMarkdown(t *testing.T) {
+	defer testutil.SuppressAllOutput(t)()
+	testutil.SetViperKeys(t, map[string]any{
+		shared.ConfigKeyCodeOwnersEnabled: false,
+		shared.ConfigKeyAnnotations:       map[string]string{"main.go": "Start here.\nThe flags live in cli/."},
+	})
+	srcDir := t.TempDir()
+	testutil.CreateTestFile(t, srcDir, "main.go", []byte(shared.LiteralPackageMain))
+
+	var bundle bytes.Buffer
+	p := NewProcessor(WithSource(srcDir), WithFormat(shared.FormatMarkdown), WithWriter(&bundle))
+	testutil.MustSucceed(t, p.Process(t.Context()), "Process")
+
+	want := "## File: `main.go`\n> note: Start here.\n> The flags live in cli/.\n\n```go\n"
+	if !strings.Contains(bundle.String(), want) {
+		t.Errorf("bundle = %q, want the note above the content: %q", bundle.String(), want)
+	}
+}
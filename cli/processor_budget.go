@@ -0,0 +1,38 @@
+// Package cli provides command-line interface functionality for gibidify.
+package cli
+
+import (
+	"os"
+
+	"github.com/ivuorinen/gibidify/fileproc"
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// applyBudgets counts files toward the budgets globs and applies the fallback of every budget
+// they exceed: rolled up files are taken out of files and listed after the file sections, and
+// truncated or outlined files are reduced as they are processed.
+func (p *Processor) applyBudgets(files []string) ([]string, error) {
+	budgets := p.settings.Budgets()
+	if len(budgets) == 0 {
+		return files, nil
+	}
+
+	absRoot, err := shared.AbsolutePath(p.flags.SourceDir)
+	if err != nil {
+		return nil, shared.WrapError(err, shared.ErrorTypeFileSystem, shared.CodeFSPathResolution, "resolving source").
+			WithFilePath(p.flags.SourceDir)
+	}
+
+	sizes := make(map[string]int64, len(files))
+	for _, file := range files {
+		if info, err := os.Stat(file); err == nil {
+			sizes[file] = info.Size()
+		}
+	}
+
+	p.budgets = fileproc.NewBudgetsWithSettings(absRoot, budgets, p.settings)
+	kept, entries := p.budgets.Apply(files, sizes, p.registry)
+	p.trailingEntries = append(p.trailingEntries, entries...)
+
+	return kept, nil
+}
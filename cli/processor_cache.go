@@ -0,0 +1,85 @@
+// Package cli provides command-line interface functionality for gibidify.
+package cli
+
+import (
+	"encoding/json"
+	"path/filepath"
+
+	"github.com/ivuorinen/gibidify/fileproc"
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// contentCacheSettings is everything besides a file itself that shapes its transformed content.
+type contentCacheSettings struct {
+	Config        map[string]any `json:"config"`
+	DocLanguages  []string       `json:"docLanguages,omitempty"`
+	StripComments bool           `json:"stripComments,omitempty"`
+	Compact       bool           `json:"compact,omitempty"`
+	Redactions    []string       `json:"redactions,omitempty"`
+}
+
+// loadContentCache loads the content cache of the source directory unless --no-cache is set,
+// performance.contentCache is off or the run is hermetic, which writes only declared outputs.
+func (p *Processor) loadContentCache() {
+	p.contentCache = nil
+	if p.flags.NoCache || p.flags.Hermetic || !p.settings.PerformanceContentCache() {
+		return
+	}
+
+	root, err := filepath.Abs(p.flags.SourceDir)
+	if err != nil {
+		p.logger.Debugf("Content cache disabled: %v", err)
+
+		return
+	}
+	algorithm := p.settings.PerformanceHashAlgorithm()
+	path, err := fileproc.DefaultContentCachePath(root, algorithm)
+	if err != nil {
+		p.logger.Debugf("Content cache disabled: %v", err)
+
+		return
+	}
+	settings, err := p.contentCacheFingerprint(algorithm)
+	if err != nil {
+		p.logger.Debugf("Content cache disabled: %v", err)
+
+		return
+	}
+	p.contentCache = fileproc.LoadContentCache(path, root, settings)
+}
+
+// contentCacheFingerprint returns the digest of the settings that shape transformed content,
+// so a cache saved with other settings is not used.
+func (p *Processor) contentCacheFingerprint(algorithm string) (string, error) {
+	settings := contentCacheSettings{
+		Config: p.settings.NonDefaultSettings(), DocLanguages: p.flags.DocLanguages(),
+		StripComments: p.flags.StripComments, Compact: p.flags.Compact,
+	}
+	if p.policy != nil {
+		for _, redaction := range p.policy.FileRedactions() {
+			settings.Redactions = append(settings.Redactions,
+				redaction.Name+"\x00"+redaction.Pattern.String()+"\x00"+redaction.Replacement)
+		}
+	}
+
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return "", shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOEncoding, "encoding content cache settings")
+	}
+
+	return shared.ShortChecksum(algorithm, data, 16), nil
+}
+
+// saveContentCache records the content cache statistics and saves the cache. Failing to save
+// it only costs the next run its hits.
+func (p *Processor) saveContentCache() {
+	if p.contentCache == nil {
+		return
+	}
+
+	hits, misses := p.contentCache.Stats()
+	p.metricsCollector.RecordContentCache(hits, misses)
+	if err := p.contentCache.Save(); err != nil {
+		p.logger.Warnf("Failed to save content cache: %v", err)
+	}
+}
@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// TestProcessContentCache tests that a second run bundles unchanged files from the content
+// cache, and that --no-cache and a changed setting bypass it.
+func TestProcessContentCache(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	srcDir := t.TempDir()
+	testutil.CreateTestFile(t, srcDir, "main.go", []byte(shared.LiteralPackageMain))
+	testutil.CreateTestFile(t, srcDir, "README.md", []byte("# Readme\n"))
+	defer testutil.SuppressLogs(t)()
+
+	tests := []struct {
+		name       string
+		noCache    bool
+		keys       map[string]any
+		wantHits   int64
+		wantMisses int64
+	}{
+		{name: "first run", wantMisses: 2},
+		{name: "unchanged", wantHits: 2},
+		{name: "no cache", noCache: true},
+		{name: "other settings", keys: map[string]any{shared.ConfigKeyTokensEstimate: true}, wantMisses: 2},
+	}
+
+	for _, tt := range tests {
+		testutil.SetViperKeys(t, tt.keys)
+		p := NewProcessor(WithFlags(&Flags{
+			SourceDir: srcDir, Destination: filepath.Join(t.TempDir(), "bundle.md"), Format: shared.FormatMarkdown,
+			Concurrency: 1, NoUI: true, NoCache: tt.noCache,
+		}))
+		testutil.MustSucceed(t, p.Process(t.Context()), tt.name)
+
+		metrics := p.metricsCollector.FinalMetrics()
+		if metrics.CacheHits != tt.wantHits || metrics.CacheMisses != tt.wantMisses {
+			t.Errorf("%s: %d hits, %d misses, want %d, %d",
+				tt.name, metrics.CacheHits, metrics.CacheMisses, tt.wantHits, tt.wantMisses)
+		}
+	}
+}
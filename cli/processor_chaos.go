@@ -0,0 +1,28 @@
+// Package cli provides command-line interface functionality for gibidify.
+package cli
+
+import (
+	"github.com/ivuorinen/gibidify/chaos"
+)
+
+// startChaos injects the failures --chaos lists into file reads and bundle writes until the
+// returned function is called. Builds without the chaos build tag inject nothing.
+func (p *Processor) startChaos() (stop func(), err error) {
+	if p.flags.Chaos == "" {
+		return func() {}, nil
+	}
+	cfg, err := chaos.ParseConfig(p.flags.Chaos)
+	if err != nil {
+		return nil, err
+	}
+	if !chaos.Available {
+		p.logger.Warnf("Ignoring --chaos %s: built without the chaos build tag", p.flags.Chaos)
+
+		return func() {}, nil
+	}
+
+	p.logger.Warnf("Injecting failures: %s", p.flags.Chaos)
+	chaos.Enable(cfg)
+
+	return chaos.Disable, nil
+}
@@ -0,0 +1,92 @@
+//go:build chaos
+
+package cli
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/chaos"
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// runChaos bundles a directory of ten files into a Markdown file with the failures of spec
+// injected, returning the processor, the bundle and the error of the run.
+func runChaos(t *testing.T, spec string) (*Processor, string, error) {
+	t.Helper()
+	testutil.SetViperKeys(t, map[string]any{shared.ConfigKeyCodeOwnersEnabled: false})
+	srcDir := t.TempDir()
+	for i := range 10 {
+		testutil.CreateTestFile(t, srcDir, "file"+string(rune('a'+i))+".go", []byte(shared.LiteralPackageMain))
+	}
+	destination := filepath.Join(t.TempDir(), "bundle.md")
+
+	p := NewProcessor(WithSource(srcDir), WithDestination(destination), WithFormat(shared.FormatMarkdown))
+	p.flags.NoUI = true
+	p.flags.Chaos = spec
+	err := p.Process(t.Context())
+
+	bundle, readErr := os.ReadFile(destination) // #nosec G304 -- test bundle
+	if readErr != nil && !errors.Is(readErr, os.ErrNotExist) {
+		t.Fatalf("reading bundle: %v", readErr)
+	}
+
+	return p, string(bundle), err
+}
+
+// TestProcessorChaos tests that injected read failures leave files out of a bundle that is
+// still written, slow reads only slow the run down, and write failures fail it.
+func TestProcessorChaos(t *testing.T) {
+	tests := []struct {
+		name      string
+		spec      string
+		wantErr   bool
+		wantFiles int
+	}{
+		{name: "read failures", spec: "read=1", wantFiles: 0},
+		{name: "slow reads", spec: "slow=1,delay=1ms", wantFiles: 10},
+		{name: "write failures", spec: "write=1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, bundle, err := runChaos(t, tt.spec)
+			if tt.wantErr {
+				if !errors.Is(err, chaos.ErrInjected) {
+					t.Errorf("Process() error = %v, want the injected failure", err)
+				}
+
+				return
+			}
+			if err != nil {
+				t.Fatalf("Process() failed: %v", err)
+			}
+			if got := strings.Count(bundle, "## File: `"); got != tt.wantFiles {
+				t.Errorf("bundle has %d files, want %d", got, tt.wantFiles)
+			}
+			if got := p.metricsCollector.CurrentMetrics().ErrorFiles; got != int64(10-tt.wantFiles) {
+				t.Errorf("ErrorFiles = %d, want %d", got, 10-tt.wantFiles)
+			}
+		})
+	}
+}
+
+// TestProcessorChaosSeed tests that a seed makes the injected failures of a run repeatable.
+func TestProcessorChaosSeed(t *testing.T) {
+	first, _, err := runChaos(t, "read=0.5,seed=3")
+	testutil.MustSucceed(t, err, "first run")
+	second, _, err := runChaos(t, "read=0.5,seed=3")
+	testutil.MustSucceed(t, err, "second run")
+
+	firstErrors := first.metricsCollector.CurrentMetrics().ErrorFiles
+	if secondErrors := second.metricsCollector.CurrentMetrics().ErrorFiles; firstErrors != secondErrors {
+		t.Errorf("runs with the same seed failed %d and %d reads", firstErrors, secondErrors)
+	}
+	if firstErrors == 0 || firstErrors == 10 {
+		t.Errorf("read=0.5 failed %d of 10 reads, want some but not all", firstErrors)
+	}
+}
@@ -0,0 +1,86 @@
+// Package cli provides command-line interface functionality for gibidify.
+package cli
+
+import (
+	"cmp"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ivuorinen/gibidify/fileproc"
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// churnFile is a bundled file and the number of commits that touched it.
+type churnFile struct {
+	path    string
+	commits int
+}
+
+// applyChurn counts the commits of the last git.churnDays days per file when git.churn is on,
+// registers an annotator recording the count of every file changed in that window and, with
+// git.churnTop, queues an entry listing the most changed of files ahead of the file sections.
+// Outside a git repository churn is skipped with a warning.
+func (p *Processor) applyChurn(files []string) {
+	if !p.settings.GitChurn() || !p.settings.GitEnabled() {
+		return
+	}
+
+	days := p.settings.GitChurnDays()
+	churn, err := p.git().LoadChurn(p.flags.SourceDir, time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		p.logger.Warnf("Skipping churn: %v", err)
+
+		return
+	}
+
+	p.annotators = append(p.annotators, func(filePath, _ string) map[string]string {
+		if commits := churn.Commits(filePath); commits > 0 {
+			return map[string]string{shared.MetadataKeyChurn: strconv.Itoa(commits)}
+		}
+
+		return nil
+	})
+
+	top := p.settings.GitChurnTop()
+	if top == 0 {
+		return
+	}
+	ranked := make([]churnFile, 0, len(files))
+	for _, file := range files {
+		if commits := churn.Commits(file); commits > 0 {
+			ranked = append(ranked, churnFile{path: file, commits: commits})
+		}
+	}
+	if len(ranked) == 0 {
+		return
+	}
+	slices.SortFunc(ranked, func(a, b churnFile) int {
+		return cmp.Or(cmp.Compare(b.commits, a.commits), strings.Compare(a.path, b.path))
+	})
+	p.leadingEntries = append(p.leadingEntries, p.churnEntry(ranked[:min(top, len(ranked))], days))
+}
+
+// churnEntry renders the top-churn summary: one "commits path" line per file, paths relative
+// to the source directory.
+func (p *Processor) churnEntry(ranked []churnFile, days int) fileproc.WriteRequest {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Most changed files by commits in the last %d days:\n\n", days)
+	for _, file := range ranked {
+		path := file.path
+		if rel, err := filepath.Rel(p.flags.SourceDir, file.path); err == nil {
+			path = filepath.ToSlash(rel)
+		}
+		fmt.Fprintf(&b, "%6d  %s\n", file.commits, path)
+	}
+
+	return fileproc.WriteRequest{
+		Path:     fmt.Sprintf("churn (last %d days)", days),
+		Content:  b.String(),
+		Size:     int64(b.Len()),
+		Metadata: map[string]string{shared.MetadataKeyRole: shared.MetadataRoleChurn},
+	}
+}
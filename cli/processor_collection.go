@@ -4,15 +4,19 @@ package cli
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 
-	"github.com/ivuorinen/gibidify/config"
 	"github.com/ivuorinen/gibidify/fileproc"
 	"github.com/ivuorinen/gibidify/shared"
 )
 
 // collectFiles collects all files to be processed.
 func (p *Processor) collectFiles() ([]string, error) {
-	files, err := fileproc.CollectFiles(p.flags.SourceDir)
+	if err := p.setupRollups(); err != nil {
+		return nil, err
+	}
+
+	files, err := p.collectSourceFiles()
 	if err != nil {
 		return nil, shared.WrapError(
 			err,
@@ -21,22 +25,142 @@ func (p *Processor) collectFiles() ([]string, error) {
 			"error collecting files",
 		)
 	}
+	if err := p.restriction.checkFiles(files); err != nil {
+		return nil, err
+	}
+	files, err = p.applyGlobs(files)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err = p.filterByAuthor(files)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err = p.applyCodeOwners(files)
+	if err != nil {
+		return nil, err
+	}
+	files, err = p.applyTestFiles(files)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err = p.applyCoverage(files)
+	if err != nil {
+		return nil, err
+	}
+	files = p.applyRollups(files)
+	files, err = p.applyBudgets(files)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err = p.applyOrder(files)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.applyFindings(); err != nil {
+		return nil, err
+	}
+
+	if err := p.applyAnnotations(); err != nil {
+		return nil, err
+	}
+
+	if err := p.loadPrelude(); err != nil {
+		return nil, err
+	}
+	p.applyChurn(files)
 
-	logger := shared.GetLogger()
+	logger := p.logger
 	logger.Infof(shared.CLIMsgFoundFilesToProcess, len(files))
 
 	return files, nil
 }
 
-// validateFileCollection validates the collected files against resource limits.
+// collectSourceFiles walks the source directory, or reads the file list from --from-patch,
+// which the walk's ignore rules and filters apply to as well. A file list preset by the
+// daemon's warmed index skips the walk.
+func (p *Processor) collectSourceFiles() ([]string, error) {
+	if p.indexedFiles != nil {
+		return p.indexedFiles, nil
+	}
+	walker := p.newWalker()
+	walker.SetSkipHook(func(path, reason string, size int64) {
+		p.resourceMonitor.RecordFileSkipped(reason, size)
+		if p.metricsCollector != nil {
+			p.metricsCollector.RecordFileSkipped(path, reason, size)
+		}
+		// Binary assets are listed in their rollup even though their content is left out
+		if reason == shared.SkipReasonBinary && p.rollups != nil {
+			p.rollups.Claim(path, size)
+		}
+	})
+	if p.flags.FromPatch == "" {
+		return walker.Walk(p.flags.SourceDir)
+	}
+
+	files, err := fileproc.CollectPatchFilesWithWalker(p.flags.SourceDir, p.flags.FromPatch, walker)
+	if err != nil {
+		return nil, err
+	}
+	if p.flags.AppendPatch {
+		if err := p.appendPatchEntry(); err != nil {
+			return nil, err
+		}
+	}
+
+	return files, nil
+}
+
+// newWalker creates the source directory walker, honoring --respect-gitignore over the config.
+// The user's global git excludes file is left out of hermetic runs, which read no user configuration.
+func (p *Processor) newWalker() *fileproc.ProdWalker {
+	walker := fileproc.NewProdWalkerWithSettings(p.registry, p.settings)
+	respect := p.settings.CollectionRespectGitignore()
+	if p.flags.respectGitignoreSet {
+		respect = p.flags.RespectGitignore
+	}
+	walker.SetRespectGitignore(respect)
+	if respect && p.settings.CollectionGlobalGitignore() && !p.flags.Hermetic {
+		walker.SetGlobalExcludesFile(p.git().GlobalExcludesFile(p.flags.SourceDir))
+	}
+
+	return walker
+}
+
+// appendPatchEntry queues the --from-patch diff as a trailing output entry.
+func (p *Processor) appendPatchEntry() error {
+	content, err := os.ReadFile(p.flags.FromPatch)
+	if err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIORead, "failed to read patch").
+			WithFilePath(p.flags.FromPatch)
+	}
+
+	p.trailingEntries = append(p.trailingEntries, fileproc.WriteRequest{
+		Path:     filepath.Base(p.flags.FromPatch),
+		Content:  string(content),
+		Size:     int64(len(content)),
+		Metadata: map[string]string{shared.MetadataKeyRole: shared.MetadataRolePatch},
+	})
+
+	return nil
+}
+
+// validateFileCollection validates the collected files against the limits the resource
+// monitor enforces, which include the limits of the run.
 func (p *Processor) validateFileCollection(files []string) error {
-	if !config.ResourceLimitsEnabled() {
+	limits := p.resourceMonitor.Limits()
+	if limits.MaxFiles == 0 && limits.MaxTotalSize == 0 {
 		return nil
 	}
 
 	// Check file count limit
-	maxFiles := config.MaxFiles()
-	if len(files) > maxFiles {
+	maxFiles := limits.MaxFiles
+	if maxFiles > 0 && len(files) > maxFiles {
 		return shared.NewStructuredError(
 			shared.ErrorTypeValidation,
 			shared.CodeResourceLimitFiles,
@@ -50,14 +174,14 @@ func (p *Processor) validateFileCollection(files []string) error {
 	}
 
 	// Check total size limit (estimate)
-	maxTotalSize := config.MaxTotalSize()
+	maxTotalSize := limits.MaxTotalSize
 	totalSize := int64(0)
 	oversizedFiles := 0
 
 	for _, filePath := range files {
 		if fileInfo, err := os.Stat(filePath); err == nil {
 			totalSize += fileInfo.Size()
-			if totalSize > maxTotalSize {
+			if maxTotalSize > 0 && totalSize > maxTotalSize {
 				return shared.NewStructuredError(
 					shared.ErrorTypeValidation,
 					shared.CodeResourceLimitTotalSize,
@@ -77,7 +201,7 @@ func (p *Processor) validateFileCollection(files []string) error {
 		}
 	}
 
-	logger := shared.GetLogger()
+	logger := p.logger
 	if oversizedFiles > 0 {
 		logger.Warnf("Could not stat %d files during pre-validation", oversizedFiles)
 	}
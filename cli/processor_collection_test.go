@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// TestCollectFilesFromPatch tests that --from-patch limits collection to patched files.
+func TestCollectFilesFromPatch(t *testing.T) {
+	dir := t.TempDir()
+	changed := testutil.CreateTestFile(t, dir, "changed.go", []byte(shared.LiteralPackageMain))
+	testutil.CreateTestFile(t, dir, "untouched.go", []byte(shared.LiteralPackageMain))
+
+	patch := "--- a/changed.go\n+++ b/changed.go\n@@ -1 +1 @@\n-package old\n+package main\n"
+	patchPath := filepath.Join(t.TempDir(), "changes.diff")
+	testutil.MustSucceed(t, os.WriteFile(patchPath, []byte(patch), 0o600), "writing patch")
+	testutil.SetViperKeys(t, map[string]any{shared.ConfigKeyCodeOwnersEnabled: false})
+	defer testutil.SuppressLogs(t)()
+
+	tests := []struct {
+		name        string
+		appendPatch bool
+		wantTrailer int
+	}{
+		{name: "files only", appendPatch: false, wantTrailer: 0},
+		{name: "with appended patch", appendPatch: true, wantTrailer: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewProcessor(WithFlags(&Flags{SourceDir: dir, FromPatch: patchPath, AppendPatch: tt.appendPatch}))
+			files, err := p.collectFiles()
+			testutil.MustSucceed(t, err, "collectFiles")
+
+			if len(files) != 1 || files[0] != changed {
+				t.Errorf("collectFiles() = %v, want [%s]", files, changed)
+			}
+			if len(p.trailingEntries) != tt.wantTrailer {
+				t.Fatalf("trailing entries = %d, want %d", len(p.trailingEntries), tt.wantTrailer)
+			}
+			if tt.wantTrailer > 0 {
+				entry := p.trailingEntries[0]
+				if entry.Path != "changes.diff" || entry.Content != patch ||
+					entry.Metadata[shared.MetadataKeyRole] != shared.MetadataRolePatch {
+					t.Errorf("unexpected patch entry: %+v", entry)
+				}
+			}
+		})
+	}
+}
+
+// TestCollectFilesRollups tests that files under collection.rollups globs, binary assets
+// included, are left out of the walk and listed in one trailing entry per glob.
+func TestCollectFilesRollups(t *testing.T) {
+	dir := t.TempDir()
+	main := testutil.CreateTestFile(t, dir, "main.go", []byte(shared.LiteralPackageMain))
+	assets := filepath.Join(dir, "assets")
+	testutil.MustSucceed(t, os.MkdirAll(assets, 0o750), "creating assets")
+	testutil.CreateTestFile(t, assets, "site.css", []byte("body {}\n"))
+	testutil.CreateTestFile(t, assets, "logo.png", []byte{0x89, 'P', 'N', 'G'})
+	testutil.SetViperKeys(t, map[string]any{
+		shared.ConfigKeyCodeOwnersEnabled: false,
+		shared.ConfigKeyCollectionRollups: []string{"assets/**", "public/**"},
+	})
+	defer testutil.SuppressLogs(t)()
+
+	p := NewProcessor(WithFlags(&Flags{SourceDir: dir}))
+	files, err := p.collectFiles()
+	testutil.MustSucceed(t, err, "collectFiles")
+
+	if len(files) != 1 || files[0] != main {
+		t.Errorf("collectFiles() = %v, want [%s]", files, main)
+	}
+	if len(p.trailingEntries) != 1 {
+		t.Fatalf("trailing entries = %d, want 1 for assets/**", len(p.trailingEntries))
+	}
+	entry := p.trailingEntries[0]
+	want := "[2 files, 12 bytes rolled up]\nTypes: .css 1 .png 1\n\nassets/logo.png (4 bytes)\nassets/site.css (8 bytes)\n"
+	if entry.Path != "assets/**" || entry.Content != want || entry.Size != 12 ||
+		entry.Metadata[shared.MetadataKeyRole] != shared.MetadataRoleRollup {
+		t.Errorf("rollup entry = %+v, want path assets/** with content %q", entry, want)
+	}
+}
+
+// TestCollectFilesGlobs tests that --include and --exclude globs select the collected files.
+func TestCollectFilesGlobs(t *testing.T) {
+	dir := t.TempDir()
+	main := testutil.CreateTestFile(t, dir, "main.go", []byte(shared.LiteralPackageMain))
+	testutil.CreateTestFile(t, dir, "main_test.go", []byte(shared.LiteralPackageMain))
+	testutil.CreateTestFile(t, dir, "README.md", []byte("# Readme\n"))
+	cmd := filepath.Join(dir, "cmd")
+	testutil.MustSucceed(t, os.MkdirAll(cmd, 0o750), "creating cmd")
+	run := testutil.CreateTestFile(t, cmd, "run.go", []byte(shared.LiteralPackageMain))
+	testutil.SetViperKeys(t, map[string]any{shared.ConfigKeyCodeOwnersEnabled: false})
+	defer testutil.SuppressLogs(t)()
+
+	p := NewProcessor(WithFlags(&Flags{
+		SourceDir: dir,
+		Include:   []string{"**/*.go"},
+		Exclude:   []string{"**/*_test.go"},
+	}))
+	files, err := p.collectFiles()
+	testutil.MustSucceed(t, err, "collectFiles")
+
+	if !slices.Equal(files, []string{run, main}) {
+		t.Errorf("collectFiles() = %v, want [%s %s]", files, run, main)
+	}
+}
@@ -0,0 +1,58 @@
+// Package cli provides command-line interface functionality for gibidify.
+package cli
+
+import (
+	"path/filepath"
+	"strconv"
+
+	"github.com/ivuorinen/gibidify/fileproc"
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// applyCoverage reads the --coverage report, registers an annotator recording the coverage of
+// every file the report covers, and with --max-coverage keeps only the files in the report
+// covered at most that much.
+func (p *Processor) applyCoverage(files []string) ([]string, error) {
+	if p.flags.Coverage == "" {
+		return files, nil
+	}
+
+	report, err := fileproc.LoadCoverageReport(p.flags.Coverage)
+	if err != nil {
+		return nil, err
+	}
+	absRoot, err := shared.AbsolutePath(p.flags.SourceDir)
+	if err != nil {
+		return nil, shared.WrapError(err, shared.ErrorTypeFileSystem, shared.CodeFSPathResolution, "resolving source")
+	}
+	coverage := func(filePath string) (float64, bool) {
+		relPath, err := filepath.Rel(absRoot, filePath)
+		if err != nil {
+			relPath = filePath
+		}
+
+		return report.Percent(filePath, relPath)
+	}
+
+	p.annotators = append(p.annotators, func(filePath, _ string) map[string]string {
+		if percent, ok := coverage(filePath); ok {
+			return map[string]string{shared.MetadataKeyCoverage: strconv.FormatFloat(percent, 'f', 1, 64) + "%"}
+		}
+
+		return nil
+	})
+
+	if !p.flags.maxCoverageSet {
+		return files, nil
+	}
+
+	kept := make([]string, 0, len(files))
+	for _, file := range files {
+		if percent, ok := coverage(file); ok && percent <= p.flags.MaxCoverage {
+			kept = append(kept, file)
+		}
+	}
+	p.logger.Infof("Coverage filter (at most %g%%) kept %d of %d files", p.flags.MaxCoverage, len(kept), len(files))
+
+	return kept, nil
+}
@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// TestApplyCoverage tests coverage annotation and the --max-coverage filter.
+func TestApplyCoverage(t *testing.T) {
+	dir := t.TempDir()
+	covered := filepath.Join(dir, "covered.go")
+	partial := filepath.Join(dir, "partial.go")
+	unlisted := filepath.Join(dir, "README.md")
+	files := []string{covered, partial, unlisted}
+
+	profile := "mode: set\nexample.com/mod/covered.go:1.1,2.2 2 1\n" +
+		"example.com/mod/partial.go:1.1,2.2 1 1\nexample.com/mod/partial.go:3.1,4.2 3 0\n"
+	report := filepath.Join(t.TempDir(), "cover.out")
+	testutil.MustSucceed(t, os.WriteFile(report, []byte(profile), 0o600), "writing profile")
+	defer testutil.SuppressLogs(t)()
+
+	tests := []struct {
+		name        string
+		maxCoverage float64
+		maxSet      bool
+		want        []string
+	}{
+		{name: "annotate only", want: files},
+		{name: "under-covered", maxCoverage: 50, maxSet: true, want: []string{partial}},
+		{name: "all listed", maxCoverage: 100, maxSet: true, want: []string{covered, partial}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flags := &Flags{SourceDir: dir, Coverage: report, MaxCoverage: tt.maxCoverage, maxCoverageSet: tt.maxSet}
+			p := NewProcessor(WithFlags(flags))
+			got, err := p.applyCoverage(files)
+			testutil.MustSucceed(t, err, "applyCoverage")
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("applyCoverage() = %v, want %v", got, tt.want)
+			}
+			if meta := p.annotators[0](partial, "partial.go"); meta[shared.MetadataKeyCoverage] != "25.0%" {
+				t.Errorf("partial.go annotation = %v, want coverage 25.0%%", meta)
+			}
+			if meta := p.annotators[0](unlisted, "README.md"); len(meta) != 0 {
+				t.Errorf("README.md annotation = %v, want none", meta)
+			}
+		})
+	}
+}
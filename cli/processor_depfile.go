@@ -0,0 +1,52 @@
+// Package cli provides command-line interface functionality for gibidify.
+package cli
+
+import (
+	"os"
+	"strings"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// makeEscaper escapes the characters make treats specially in a rule.
+var makeEscaper = strings.NewReplacer(" ", `\ `, "#", `\#`, "$", "$$")
+
+// saveDepfile writes the --depfile dependency file: a make rule with the destination as target
+// and files plus the other inputs of the run as prerequisites, followed by an empty rule for
+// every prerequisite so make does not fail once one is deleted.
+func (p *Processor) saveDepfile(files []string) error {
+	if p.flags.Depfile == "" {
+		return nil
+	}
+
+	prerequisites := make([]string, 0, len(files)+7)
+	prerequisites = append(prerequisites, files...)
+	inputs := []string{
+		p.settings.FileUsed(), p.settings.ProjectFileUsed(), p.flags.PromptTemplate, p.flags.FromPatch, p.flags.Coverage,
+		p.flags.Findings,
+	}
+	for _, input := range inputs {
+		if input != "" {
+			prerequisites = append(prerequisites, input)
+		}
+	}
+	prerequisites = append(prerequisites, p.flags.PreludeFiles()...)
+
+	var depfile strings.Builder
+	depfile.WriteString(makeEscaper.Replace(p.flags.Destination) + ":")
+	for _, prerequisite := range prerequisites {
+		depfile.WriteString(" \\\n  " + makeEscaper.Replace(prerequisite))
+	}
+	depfile.WriteString("\n")
+	for _, prerequisite := range prerequisites {
+		depfile.WriteString("\n" + makeEscaper.Replace(prerequisite) + ":\n")
+	}
+
+	if err := os.WriteFile(p.flags.Depfile, []byte(depfile.String()), shared.OutputFilePermission); err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOFileWrite, "failed to write depfile").
+			WithFilePath(p.flags.Depfile)
+	}
+	p.ui.PrintInfo("Depfile saved to %s", p.flags.Depfile)
+
+	return nil
+}
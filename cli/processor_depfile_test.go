@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// TestProcessWritesDepfile tests that --depfile lists the bundled files and inputs as escaped
+// make prerequisites of the destination.
+func TestProcessWritesDepfile(t *testing.T) {
+	srcDir := t.TempDir()
+	outDir := t.TempDir()
+	testutil.CreateTestFile(t, srcDir, "main.go", []byte(shared.LiteralPackageMain))
+	testutil.CreateTestFile(t, srcDir, "my notes#1.txt", []byte("notes\n"))
+	tmpl := testutil.CreateTestFile(t, outDir, "p.tmpl", []byte("Review:\n{{.Bundle}}"))
+	testutil.SetViperKeys(t, map[string]any{shared.ConfigKeyCodeOwnersEnabled: false})
+	defer testutil.SuppressLogs(t)()
+
+	dest := filepath.Join(outDir, "bundle$.md")
+	depfile := filepath.Join(outDir, "bundle.d")
+	p := NewProcessor(WithFlags(&Flags{
+		SourceDir: srcDir, Destination: dest, Format: shared.FormatMarkdown, Concurrency: 1, NoUI: true,
+		Depfile: depfile, PromptTemplate: tmpl,
+	}))
+	testutil.MustSucceed(t, p.Process(t.Context()), "Process")
+
+	got, err := os.ReadFile(depfile) // #nosec G304 -- depfile is in t.TempDir()
+	testutil.MustSucceed(t, err, "reading depfile")
+	main := filepath.Join(srcDir, "main.go")
+	notes := makeEscaper.Replace(filepath.Join(srcDir, "my notes#1.txt"))
+	want := filepath.Join(outDir, "bundle$$.md") + ": \\\n  " + main + " \\\n  " + notes + " \\\n  " + tmpl + "\n\n" +
+		main + ":\n\n" + notes + ":\n\n" + tmpl + ":\n"
+	if string(got) != want {
+		t.Errorf("depfile = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,175 @@
+// Package cli provides command-line interface functionality for gibidify.
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// openDestination opens path for writing the bundle. Named pipes are opened write-only
+// without truncation; with appendMode regular files are appended to instead of truncated.
+// appended reports whether the bundle follows existing content and needs a separator.
+func openDestination(path string, appendMode bool) (file *os.File, appended bool, err error) {
+	info, statErr := os.Stat(path)
+	if statErr == nil && info.Mode()&os.ModeNamedPipe != 0 {
+		// Opening a FIFO blocks until a reader connects.
+		file, err = os.OpenFile(path, os.O_WRONLY, 0) // #nosec G304 -- destination is validated in flags.validate()
+
+		return file, false, err
+	}
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if appendMode {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+		appended = statErr == nil && info.Size() > 0
+	}
+	file, err = os.OpenFile(path, flags, shared.OutputFilePermission) // #nosec G304 -- validated in flags.validate()
+
+	return file, appended, err
+}
+
+// checkDestination verifies before any file is processed that the bundle can be written,
+// without creating or changing the destination: its directory exists, the destination or the
+// directory is writable, and the file system has room for size bytes. Failing here saves a
+// run that would only fail once the bundle is written. Named pipes and writers are not checked.
+func (p *Processor) checkDestination(size int64) error {
+	path := p.flags.Destination
+	if p.writer != nil || path == "" {
+		return nil
+	}
+
+	info, err := os.Stat(path)
+	exists := err == nil
+	switch {
+	case exists && info.Mode()&os.ModeNamedPipe != 0:
+		return nil
+	case exists && info.IsDir():
+		return destinationCheckError(nil, path, "destination is a directory")
+	case !exists && !errors.Is(err, fs.ErrNotExist):
+		return destinationCheckError(err, path, "failed to check destination")
+	}
+
+	// New destinations, prompt-wrapped and previewed bundles are created in the directory
+	dir := filepath.Dir(path)
+	if err := checkDestinationDir(dir, !exists || p.promptTemplate != "" || p.flags.PreviewDiff); err != nil {
+		return destinationCheckError(nil, path, err.Error())
+	}
+	if exists {
+		// Opening without O_CREATE or O_TRUNC leaves the existing bundle untouched
+		file, err := os.OpenFile(path, os.O_WRONLY, 0) // #nosec G304 -- validated in flags.validate()
+		if err != nil {
+			return destinationCheckError(err, path, "destination is not writable")
+		}
+		shared.LogError("Error closing destination", file.Close())
+		if !p.flags.Append {
+			size -= info.Size()
+		}
+	}
+
+	if free, ok := freeSpace(dir); ok && size > 0 && uint64(size) > free {
+		return destinationCheckError(nil, path, fmt.Sprintf(
+			"not enough free space for the bundle: about %d bytes needed, %d available", size, free,
+		))
+	}
+
+	return nil
+}
+
+// checkDestinationDir returns an error when dir is not an existing directory or, with
+// create, when files cannot be created in it.
+func checkDestinationDir(dir string, create bool) error {
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return fmt.Errorf("destination directory does not exist: %s", dir)
+	}
+	if create && dirWritable(dir) != nil {
+		return fmt.Errorf("destination directory is not writable: %s", dir)
+	}
+
+	return nil
+}
+
+// collectedSize returns the total size of files, an estimate of the size of their bundle.
+func collectedSize(files []string) int64 {
+	var size int64
+	for _, file := range files {
+		if info, err := os.Stat(file); err == nil {
+			size += info.Size()
+		}
+	}
+
+	return size
+}
+
+// destinationCheckError reports a destination that failed checkDestination.
+func destinationCheckError(err error, path, message string) error {
+	return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOFileCreate, message).WithFilePath(path)
+}
+
+// syncOutput flushes the written bundle to stable storage when --fsync is set.
+// Pipes and devices have nothing to sync.
+func (p *Processor) syncOutput(file *os.File) error {
+	if !p.flags.Fsync {
+		return nil
+	}
+	if info, err := file.Stat(); err == nil && !info.Mode().IsRegular() {
+		return nil
+	}
+	if err := file.Sync(); err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOFileWrite, "failed to fsync output file").
+			WithFilePath(p.flags.Destination)
+	}
+
+	return nil
+}
+
+// destinationName names where the bundle is written for messages: the destination file,
+// stdout, or the writer set with WithWriter.
+func (p *Processor) destinationName() string {
+	switch {
+	case p.flags.Destination != "":
+		return p.flags.Destination
+	case p.flags.Stdout:
+		return "stdout"
+	default:
+		return "writer"
+	}
+}
+
+// copyToWriter copies the finished bundle in file to the writer set with WithWriter.
+func (p *Processor) copyToWriter(file *os.File) error {
+	if p.writer == nil {
+		return nil
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIORead, "failed to rewind bundle").
+			WithFilePath(file.Name())
+	}
+	if _, err := io.Copy(p.writer, file); err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOWrite, "failed to write bundle")
+	}
+
+	return nil
+}
+
+// bundleSeparator returns the header written between bundles appended to one file.
+// JSON bundles are separated by a newline only, so the file remains a readable stream of documents.
+func bundleSeparator(format, source string, now time.Time) string {
+	stamp := now.UTC().Format(time.RFC3339)
+	switch format {
+	case shared.FormatMarkdown:
+		return fmt.Sprintf("\n\n---\n\n<!-- %s bundle appended %s from %s -->\n\n", shared.AppName, stamp, source)
+	case shared.FormatYAML:
+		return fmt.Sprintf("\n---\n# %s bundle appended %s from %s\n", shared.AppName, stamp, source)
+	case shared.FormatPlain:
+		return fmt.Sprintf("\n%s bundle appended %s from %s\n\n", shared.AppName, stamp, source)
+	default:
+		return "\n"
+	}
+}
@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// TestProcessAppendMode tests that --append adds a delimited bundle after existing content.
+func TestProcessAppendMode(t *testing.T) {
+	srcDir := t.TempDir()
+	testutil.CreateTestFile(t, srcDir, "main.go", []byte(shared.LiteralPackageMain))
+	dest := filepath.Join(t.TempDir(), "log.md")
+	testutil.SetViperKeys(t, map[string]any{shared.ConfigKeyCodeOwnersEnabled: false})
+	defer testutil.SuppressLogs(t)()
+
+	for range 2 {
+		p := NewProcessor(WithFlags(&Flags{
+			SourceDir: srcDir, Destination: dest, Format: shared.FormatMarkdown, Concurrency: 1,
+			NoUI: true, Append: true,
+		}))
+		testutil.MustSucceed(t, p.Process(t.Context()), "Process")
+	}
+
+	content, err := os.ReadFile(dest)
+	testutil.MustSucceed(t, err, "reading output")
+	out := string(content)
+	if n := strings.Count(out, "## File: `main.go`"); n != 2 {
+		t.Errorf("expected 2 bundles, found %d:\n%s", n, out)
+	}
+	if n := strings.Count(out, "bundle appended"); n != 1 {
+		t.Errorf("expected exactly 1 separator header, found %d:\n%s", n, out)
+	}
+	if strings.HasPrefix(out, "\n") {
+		t.Error("first bundle in a new file should not start with a separator")
+	}
+}
+
+// TestBundleSeparator tests the per-format separator headers.
+func TestBundleSeparator(t *testing.T) {
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{format: shared.FormatMarkdown, want: "<!-- gibidify bundle appended"},
+		{format: shared.FormatYAML, want: "\n---\n# gibidify bundle appended"},
+		{format: shared.FormatJSON, want: "\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			if got := bundleSeparator(tt.format, "src", time.Unix(0, 0)); !strings.Contains(got, tt.want) {
+				t.Errorf("bundleSeparator(%s) = %q, want it to contain %q", tt.format, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestSyncOutput tests that --fsync syncs regular output files and reports sync failures.
+func TestSyncOutput(t *testing.T) {
+	tests := []struct {
+		name    string
+		fsync   bool
+		closed  bool
+		wantErr bool
+	}{
+		{name: "disabled", fsync: false, closed: true},
+		{name: "open file", fsync: true},
+		{name: "closed file", fsync: true, closed: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := os.Create(filepath.Join(t.TempDir(), "out.md"))
+			testutil.MustSucceed(t, err, "creating output")
+			if tt.closed {
+				testutil.CloseFile(t, file)
+			} else {
+				defer testutil.CloseFile(t, file)
+			}
+
+			p := NewProcessor(WithFlags(&Flags{Destination: file.Name(), Fsync: tt.fsync, NoUI: true}))
+			if err := p.syncOutput(file); (err != nil) != tt.wantErr {
+				t.Errorf("syncOutput() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestCheckDestination tests the pre-flight destination check that runs before processing.
+func TestCheckDestination(t *testing.T) {
+	dir := t.TempDir()
+	existing := testutil.CreateTestFile(t, dir, "existing.md", []byte("old bundle"))
+	_, canStat := freeSpace(dir)
+
+	tests := []struct {
+		name    string
+		path    string
+		size    int64
+		wantErr string
+		skip    bool
+	}{
+		{name: "new file", path: filepath.Join(dir, "new.md"), size: 1},
+		{name: "existing file", path: existing, size: 1},
+		{name: "missing directory", path: filepath.Join(dir, "missing", "out.md"), wantErr: "does not exist"},
+		{name: "directory", path: dir, wantErr: "destination is a directory"},
+		{name: "no free space", path: filepath.Join(dir, "huge.md"), size: 1 << 62, wantErr: "free space", skip: !canStat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.skip {
+				t.Skip("free space is unknown on this platform")
+			}
+			p := &Processor{flags: &Flags{Destination: tt.path}}
+			err := p.checkDestination(tt.size)
+			if tt.wantErr == "" {
+				testutil.MustSucceed(t, err, "checkDestination")
+			} else {
+				testutil.VerifyStructuredError(t, err, shared.ErrorTypeIO, shared.CodeIOFileCreate)
+				if !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("checkDestination() = %v, want an error containing %q", err, tt.wantErr)
+				}
+			}
+			if _, statErr := os.Stat(tt.path); tt.path != existing && tt.path != dir && statErr == nil {
+				t.Errorf("checkDestination created %s", tt.path)
+			}
+		})
+	}
+
+	if content, _ := os.ReadFile(existing); string(content) != "old bundle" { // #nosec G304 -- in t.TempDir()
+		t.Errorf("checkDestination changed the existing destination to %q", content)
+	}
+}
@@ -0,0 +1,47 @@
+//go:build unix
+
+package cli
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// TestProcessNamedPipe tests that a bundle can be streamed into a FIFO.
+func TestProcessNamedPipe(t *testing.T) {
+	srcDir := t.TempDir()
+	testutil.CreateTestFile(t, srcDir, "main.go", []byte(shared.LiteralPackageMain))
+	fifo := filepath.Join(t.TempDir(), "bundle.fifo")
+	testutil.MustSucceed(t, syscall.Mkfifo(fifo, 0o600), "creating fifo")
+	testutil.SetViperKeys(t, map[string]any{shared.ConfigKeyCodeOwnersEnabled: false})
+	defer testutil.SuppressLogs(t)()
+
+	received := make(chan string, 1)
+	go func() {
+		reader, err := os.Open(fifo)
+		if err != nil {
+			received <- err.Error()
+
+			return
+		}
+		defer reader.Close()
+		data, _ := io.ReadAll(reader)
+		received <- string(data)
+	}()
+
+	p := NewProcessor(WithFlags(&Flags{
+		SourceDir: srcDir, Destination: fifo, Format: shared.FormatMarkdown, Concurrency: 1, NoUI: true,
+	}))
+	testutil.MustSucceed(t, p.Process(t.Context()), "Process")
+
+	if out := <-received; !strings.Contains(out, "## File: `main.go`") {
+		t.Errorf("unexpected FIFO output: %q", out)
+	}
+}
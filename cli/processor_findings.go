@@ -0,0 +1,40 @@
+// Package cli provides command-line interface functionality for gibidify.
+package cli
+
+import (
+	"path/filepath"
+
+	"github.com/ivuorinen/gibidify/fileproc"
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// applyFindings reads the --findings report and registers an annotator listing the static
+// analysis findings of every file the report names, so they appear beside the code.
+func (p *Processor) applyFindings() error {
+	if p.flags.Findings == "" {
+		return nil
+	}
+
+	report, err := fileproc.LoadFindingsReport(p.flags.Findings)
+	if err != nil {
+		return err
+	}
+	absRoot, err := shared.AbsolutePath(p.flags.SourceDir)
+	if err != nil {
+		return shared.WrapError(err, shared.ErrorTypeFileSystem, shared.CodeFSPathResolution, "resolving source")
+	}
+
+	p.annotators = append(p.annotators, func(filePath, _ string) map[string]string {
+		relPath, err := filepath.Rel(absRoot, filePath)
+		if err != nil {
+			relPath = filePath
+		}
+		if findings := report.Findings(filePath, relPath); len(findings) > 0 {
+			return map[string]string{shared.MetadataKeyFindings: fileproc.FormatFindings(findings)}
+		}
+
+		return nil
+	})
+
+	return nil
+}
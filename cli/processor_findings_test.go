@@ -0,0 +1,30 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// TestApplyFindings tests that findings from a golangci-lint report annotate the files they name.
+func TestApplyFindings(t *testing.T) {
+	dir := t.TempDir()
+	report := filepath.Join(t.TempDir(), "lint.json")
+	issues := `{"Issues":[{"FromLinter":"errcheck","Text":"Error return value is not checked",` +
+		`"Pos":{"Filename":"pkg/io.go","Line":20,"Column":2}}]}`
+	testutil.MustSucceed(t, os.WriteFile(report, []byte(issues), 0o600), "writing report")
+
+	p := NewProcessor(WithFlags(&Flags{SourceDir: dir, Findings: report}))
+	testutil.MustSucceed(t, p.applyFindings(), "applyFindings")
+
+	want := "20:2 warning [errcheck] Error return value is not checked"
+	if meta := p.annotators[0](filepath.Join(dir, "pkg", "io.go"), "pkg/io.go"); meta[shared.MetadataKeyFindings] != want {
+		t.Errorf("pkg/io.go annotation = %v, want findings %q", meta, want)
+	}
+	if meta := p.annotators[0](filepath.Join(dir, "main.go"), "main.go"); len(meta) != 0 {
+		t.Errorf("main.go annotation = %v, want none", meta)
+	}
+}
@@ -0,0 +1,91 @@
+// Package cli provides command-line interface functionality for gibidify.
+package cli
+
+import (
+	"regexp"
+
+	"github.com/ivuorinen/gibidify/gitutil"
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// filterByAuthor keeps only files where authors matching --author own at least
+// git.authorThreshold percent of the lines according to git blame.
+func (p *Processor) filterByAuthor(files []string) ([]string, error) {
+	if p.flags.Author == "" {
+		return files, nil
+	}
+	if !p.settings.GitEnabled() {
+		return nil, shared.NewStructuredError(
+			shared.ErrorTypeConfiguration,
+			shared.CodeConfigValidation,
+			"--author requires git integration (git.enabled)",
+			"",
+			nil,
+		)
+	}
+
+	pattern, err := regexp.Compile("(?i)" + p.flags.Author)
+	if err != nil {
+		return nil, shared.WrapError(err, shared.ErrorTypeCLI, shared.CodeCLIInvalidArgs, "invalid author pattern")
+	}
+
+	blamer, cache, err := p.newBlamer(p.flags.SourceDir)
+	if err != nil {
+		return nil, err
+	}
+
+	threshold := p.settings.GitAuthorThreshold()
+	logger := p.logger
+	kept := make([]string, 0, len(files))
+	for _, file := range files {
+		summary, err := blamer.Summary(file)
+		if err != nil {
+			logger.Debugf("Skipping %s: blame failed: %v", file, err)
+
+			continue
+		}
+		if share := summary.Share(pattern.MatchString); share > 0 && share >= threshold {
+			kept = append(kept, file)
+		}
+	}
+
+	if cache != nil {
+		if err := cache.Save(); err != nil {
+			logger.Warnf("Failed to save blame cache: %v", err)
+		}
+	}
+	logger.Infof("Author filter %q kept %d of %d files", p.flags.Author, len(kept), len(files))
+
+	return kept, nil
+}
+
+// git returns the git runner of the run, isolated from the user and system git configuration
+// when the run is hermetic.
+func (p *Processor) git() gitutil.Git {
+	return gitutil.Git{Isolated: p.flags.Hermetic}
+}
+
+// newBlamer creates a blamer for the repository containing dir, with the on-disk cache when
+// enabled and the run is not hermetic, which writes only declared outputs.
+func (p *Processor) newBlamer(dir string) (*gitutil.Blamer, *gitutil.BlameCache, error) {
+	root, err := p.git().RepoRoot(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var cache *gitutil.BlameCache
+	if p.settings.GitBlameCache() && !p.flags.Hermetic {
+		if path, err := gitutil.DefaultBlameCachePath(root, p.settings.PerformanceHashAlgorithm()); err == nil {
+			cache = gitutil.LoadBlameCache(path)
+		} else {
+			p.logger.Debugf("Blame cache disabled: %v", err)
+		}
+	}
+
+	blamer, err := p.git().NewBlamer(root, cache)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return blamer, cache, nil
+}
@@ -0,0 +1,137 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// setupAuthorRepo creates a repository where alice.go is owned by Alice and bob.go by Bob.
+func setupAuthorRepo(t *testing.T) (dir string, files []string) {
+	t.Helper()
+	dir = t.TempDir()
+	testutil.InitGitRepo(t, dir)
+	alice := testutil.CreateTestFile(t, dir, "alice.go", []byte("package a\n\nfunc A() {}\n"))
+	testutil.GitCommitAs(t, dir, "Alice", "alice@example.com", "alice")
+	bob := testutil.CreateTestFile(t, dir, "bob.go", []byte("package b\n\nfunc B() {}\n"))
+	testutil.GitCommitAs(t, dir, "Bob", "bob@example.com", "bob")
+
+	return dir, []string{alice, bob}
+}
+
+// TestFilterByAuthor tests that --author keeps only files owned by the matching author.
+func TestFilterByAuthor(t *testing.T) {
+	dir, files := setupAuthorRepo(t)
+	testutil.SetViperKeys(t, map[string]any{shared.ConfigKeyGitBlameCache: false})
+	defer testutil.SuppressLogs(t)()
+
+	tests := []struct {
+		name   string
+		author string
+		want   []string
+	}{
+		{name: "no filter", author: "", want: files},
+		{name: "match by name", author: "alice", want: files[:1]},
+		{name: "match by email", author: "bob@example", want: files[1:]},
+		{name: "no match", author: "carol", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewProcessor(WithFlags(&Flags{SourceDir: dir, Author: tt.author}))
+			got, err := p.filterByAuthor(files)
+			testutil.MustSucceed(t, err, "filterByAuthor")
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if filepath.Base(got[i]) != filepath.Base(tt.want[i]) {
+					t.Errorf("got[%d] = %s, want %s", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+// TestFilterByAuthorBlameCache tests that the blame cache is saved to the user cache directory,
+// except in hermetic runs.
+func TestFilterByAuthorBlameCache(t *testing.T) {
+	dir, files := setupAuthorRepo(t)
+	testutil.SetViperKeys(t, map[string]any{shared.ConfigKeyGitBlameCache: true})
+	defer testutil.SuppressLogs(t)()
+
+	for _, hermetic := range []bool{false, true} {
+		cacheHome := t.TempDir()
+		t.Setenv("XDG_CACHE_HOME", cacheHome)
+
+		p := NewProcessor(WithFlags(&Flags{SourceDir: dir, Author: "alice", Hermetic: hermetic}))
+		_, err := p.filterByAuthor(files)
+		testutil.MustSucceed(t, err, "filterByAuthor")
+
+		_, err = os.Stat(filepath.Join(cacheHome, shared.AppName))
+		if created := err == nil; created == hermetic {
+			t.Errorf("hermetic = %v: cache directory created = %v", hermetic, created)
+		}
+	}
+}
+
+// TestFilterByAuthorGitDisabled tests that --author fails when git integration is disabled.
+func TestFilterByAuthorGitDisabled(t *testing.T) {
+	testutil.SetViperKeys(t, map[string]any{shared.ConfigKeyGitEnabled: false})
+
+	p := NewProcessor(WithFlags(&Flags{SourceDir: t.TempDir(), Author: "alice"}))
+	_, err := p.filterByAuthor([]string{"a.go"})
+	testutil.VerifyStructuredError(t, err, shared.ErrorTypeConfiguration, shared.CodeConfigValidation)
+}
+
+// TestApplyChurn tests churn annotation and the top-churn entry.
+func TestApplyChurn(t *testing.T) {
+	dir, files := setupAuthorRepo(t)
+	testutil.CreateTestFile(t, dir, "bob.go", []byte("package b\n\nfunc B() { _ = 1 }\n"))
+	testutil.GitCommitAs(t, dir, "Bob", "bob@example.com", "bob again")
+	testutil.SetViperKeys(t, map[string]any{
+		shared.ConfigKeyGitChurn: true, shared.ConfigKeyGitChurnDays: 30, shared.ConfigKeyGitChurnTop: 1,
+	})
+
+	p := NewProcessor(WithFlags(&Flags{SourceDir: dir}))
+	p.applyChurn(files)
+
+	if meta := p.annotators[0](files[1], "bob.go"); meta[shared.MetadataKeyChurn] != "2" {
+		t.Errorf("bob.go annotation = %v, want churn 2", meta)
+	}
+	if len(p.leadingEntries) != 1 {
+		t.Fatalf("leadingEntries = %v, want the top-churn entry", p.leadingEntries)
+	}
+	entry := p.leadingEntries[0]
+	if entry.Metadata[shared.MetadataKeyRole] != shared.MetadataRoleChurn ||
+		!strings.Contains(entry.Content, "2  bob.go") {
+		t.Errorf("top-churn entry = %+v, want bob.go with 2 commits", entry)
+	}
+	if strings.Contains(entry.Content, "alice.go") {
+		t.Errorf("top-churn entry lists alice.go beyond git.churnTop: %q", entry.Content)
+	}
+}
+
+// TestApplyOrderRecency tests that --order recency puts files never committed first, and
+// requires git integration.
+func TestApplyOrderRecency(t *testing.T) {
+	dir, files := setupAuthorRepo(t)
+	draft := testutil.CreateTestFile(t, dir, "draft.go", []byte("package d\n"))
+	testutil.SetViperKeys(t, nil)
+
+	p := NewProcessor(WithFlags(&Flags{SourceDir: dir, Order: shared.OrderRecency}))
+	ordered, err := p.applyOrder(append(files, draft))
+	testutil.MustSucceed(t, err, "ordering by recency")
+	if len(ordered) != 3 || ordered[0] != draft {
+		t.Errorf("applyOrder() = %v, want draft.go first", ordered)
+	}
+
+	testutil.SetViperKeys(t, map[string]any{shared.ConfigKeyGitEnabled: false})
+	if _, err := p.applyOrder(files); err == nil || !strings.Contains(err.Error(), "git.enabled") {
+		t.Errorf("applyOrder() without git = %v, want a git.enabled error", err)
+	}
+}
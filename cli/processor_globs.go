@@ -0,0 +1,30 @@
+// Package cli provides command-line interface functionality for gibidify.
+package cli
+
+import (
+	"github.com/ivuorinen/gibidify/fileproc"
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// applyGlobs keeps the files matching an --include glob, or every file without one, that
+// match no --exclude glob. Globs match paths relative to the source directory.
+func (p *Processor) applyGlobs(files []string) ([]string, error) {
+	if len(p.flags.Include) == 0 && len(p.flags.Exclude) == 0 {
+		return files, nil
+	}
+
+	absRoot, err := shared.AbsolutePath(p.flags.SourceDir)
+	if err != nil {
+		return nil, shared.WrapError(err, shared.ErrorTypeFileSystem, shared.CodeFSPathResolution, "resolving source").
+			WithFilePath(p.flags.SourceDir)
+	}
+	filter, err := fileproc.NewGlobFilter(absRoot, p.flags.Include, p.flags.Exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	kept := filter.Filter(files)
+	p.logger.Infof("Include and exclude globs kept %d of %d files", len(kept), len(files))
+
+	return kept, nil
+}
@@ -0,0 +1,43 @@
+// Package cli provides command-line interface functionality for gibidify.
+package cli
+
+import (
+	"github.com/ivuorinen/gibidify/fileproc"
+)
+
+// newBundleIndex returns the index filled while writing, or nil without --index.
+func (p *Processor) newBundleIndex() *fileproc.BundleIndex {
+	if p.flags.Index == "" {
+		return nil
+	}
+
+	return fileproc.NewBundleIndex(p.flags.Destination, p.flags.Format)
+}
+
+// saveBundleIndex writes the --index file, shifting offsets by the position of the
+// bundle within the destination (non-zero for prompt-wrapped bundles). It reports whether
+// the index was written.
+func (p *Processor) saveBundleIndex(index *fileproc.BundleIndex, bundleOffset int64) (bool, error) {
+	if index == nil {
+		return false, nil
+	}
+	logger := p.logger
+	if !index.Available() {
+		logger.Warnf("Skipping index %s: destination offsets are unavailable", p.flags.Index)
+
+		return false, nil
+	}
+	if bundleOffset < 0 {
+		logger.Warnf("Skipping index %s: the prompt template does not embed the bundle verbatim", p.flags.Index)
+
+		return false, nil
+	}
+
+	index.Shift(bundleOffset)
+	if err := index.Save(p.flags.Index); err != nil {
+		return false, err
+	}
+	p.ui.PrintInfo("Index saved to %s", p.flags.Index)
+
+	return true, nil
+}
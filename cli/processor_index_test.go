@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/fileproc"
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// TestProcessWritesIndex tests that --index offsets point into the destination, including prompt-wrapped bundles.
+func TestProcessWritesIndex(t *testing.T) {
+	srcDir := t.TempDir()
+	testutil.CreateTestFile(t, srcDir, "main.go", []byte(shared.LiteralPackageMain))
+	tmpl := testutil.CreateTestFile(t, t.TempDir(), "p.tmpl", []byte("Review:\n{{.Bundle}}\nThanks"))
+	testutil.SetViperKeys(t, map[string]any{shared.ConfigKeyCodeOwnersEnabled: false})
+	defer testutil.SuppressLogs(t)()
+
+	for _, promptTemplate := range []string{"", tmpl} {
+		outDir := t.TempDir()
+		dest := filepath.Join(outDir, "bundle.md")
+		indexPath := filepath.Join(outDir, "bundle.index.json")
+		p := NewProcessor(WithFlags(&Flags{
+			SourceDir: srcDir, Destination: dest, Format: shared.FormatMarkdown, Concurrency: 1, NoUI: true,
+			Index: indexPath, PromptTemplate: promptTemplate,
+		}))
+		testutil.MustSucceed(t, p.Process(t.Context()), "Process")
+
+		raw, err := os.ReadFile(indexPath)
+		testutil.MustSucceed(t, err, "reading index")
+		var index fileproc.BundleIndex
+		testutil.MustSucceed(t, json.Unmarshal(raw, &index), "decoding index")
+		bundle, err := os.ReadFile(dest)
+		testutil.MustSucceed(t, err, "reading bundle")
+
+		if index.Bundle != dest || len(index.Entries) != 1 {
+			t.Fatalf("unexpected index: %+v", index)
+		}
+		entry := index.Entries[0]
+		section := string(bundle[entry.Offset : entry.Offset+entry.Length])
+		if !strings.HasPrefix(section, "## File: `main.go`") {
+			t.Errorf("index offset does not point at the section (template %q): %q", promptTemplate, section)
+		}
+	}
+}
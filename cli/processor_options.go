@@ -0,0 +1,135 @@
+// Package cli provides command-line interface functionality for gibidify.
+package cli
+
+import (
+	"io"
+	"os/exec"
+
+	"github.com/ivuorinen/gibidify/config"
+	"github.com/ivuorinen/gibidify/fileproc"
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// ProcessorOption configures a Processor created by NewProcessor.
+type ProcessorOption func(*Processor)
+
+// WithFlags configures the processor from a full set of command-line flags, as the CLI
+// does. It replaces every setting made by earlier options, so pass it first; the processor
+// uses flags directly, and options passed after it modify them.
+func WithFlags(flags *Flags) ProcessorOption {
+	return func(p *Processor) {
+		p.flags = flags
+	}
+}
+
+// WithSource sets the directory to bundle.
+func WithSource(dir string) ProcessorOption {
+	return func(p *Processor) {
+		p.flags.SourceDir = dir
+	}
+}
+
+// WithDestination sets the path the bundle is written to.
+func WithDestination(path string) ProcessorOption {
+	return func(p *Processor) {
+		p.flags.Destination = path
+	}
+}
+
+// WithFormat sets the output format: shared.FormatJSON (the default), shared.FormatMarkdown,
+// shared.FormatYAML, shared.FormatPlain, shared.FormatPDF or shared.FormatZip.
+func WithFormat(format string) ProcessorOption {
+	return func(p *Processor) {
+		p.flags.Format = format
+	}
+}
+
+// WithConcurrency sets the number of file processing workers.
+func WithConcurrency(workers int) ProcessorOption {
+	return func(p *Processor) {
+		p.flags.Concurrency = workers
+	}
+}
+
+// WithWriter writes the finished bundle to w instead of a destination file. The bundle is
+// assembled in a temporary file and copied to w once complete, so w never sees a partial bundle.
+func WithWriter(w io.Writer) ProcessorOption {
+	return func(p *Processor) {
+		p.writer = w
+	}
+}
+
+// WithRegistry sets the file type registry used to skip binary and image files and detect
+// languages. By default the processor creates one from the fileTypes configuration.
+func WithRegistry(registry *fileproc.FileTypeRegistry) ProcessorOption {
+	return func(p *Processor) {
+		p.registry = registry
+	}
+}
+
+// WithSettings sets the configuration the processor reads, such as Settings loaded with
+// config.LoadSettings, so processors with different configurations can run side by side.
+// It defaults to the global configuration.
+func WithSettings(settings *config.Settings) ProcessorOption {
+	return func(p *Processor) {
+		p.settings = settings
+	}
+}
+
+// WithLogger sets the logger the processor reports to. It defaults to shared.GetLogger().
+func WithLogger(logger shared.Logger) ProcessorOption {
+	return func(p *Processor) {
+		p.logger = logger
+	}
+}
+
+// WithRunID sets the ID identifying the run in log lines, the final report and the bundle's run
+// summary. It defaults to a new shared.NewRunID().
+func WithRunID(id string) ProcessorOption {
+	return func(p *Processor) {
+		p.runID = id
+	}
+}
+
+// WithResourceLimits lowers the configured resourceLimits for this processor, as servers do for
+// every request. Process fails when a limit is negative or above the configured one.
+func WithResourceLimits(limits fileproc.ResourceLimits) ProcessorOption {
+	return func(p *Processor) {
+		p.resourceLimits = limits
+	}
+}
+
+// defaultFlags returns the settings of a processor created without WithFlags: the CLI
+// defaults, with terminal output disabled.
+func defaultFlags() *Flags {
+	return &Flags{
+		Format:      shared.FormatJSON,
+		Concurrency: config.DefaultConcurrency(),
+		NoUI:        true,
+	}
+}
+
+// validate checks the settings the options may have left invalid, so library users get the
+// same errors as the command line.
+func (p *Processor) validate() error {
+	if p.flags.SourceDir == "" {
+		return NewCLIMissingSourceError()
+	}
+	if p.flags.Destination == "" && p.writer == nil {
+		return shared.NewStructuredError(
+			shared.ErrorTypeValidation, shared.CodeValidationRequired,
+			"a destination or writer is required", "", nil,
+		)
+	}
+	if err := p.settings.ValidateOutputFormat(p.flags.Format); err != nil {
+		return err
+	}
+	if plugin, ok := p.settings.OutputPlugin(p.flags.Format); ok {
+		if _, err := exec.LookPath(plugin.Command[0]); err != nil {
+			return shared.WrapError(err, shared.ErrorTypeConfiguration, shared.CodeConfigValidation,
+				"format plugin "+p.flags.Format+" cannot be run")
+		}
+	}
+
+	return p.settings.ValidateConcurrency(p.flags.Concurrency)
+}
@@ -0,0 +1,310 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/config"
+	"github.com/ivuorinen/gibidify/fileproc"
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// recordingLogger is a shared.Logger that keeps every info message.
+type recordingLogger struct {
+	mu    sync.Mutex
+	infos []string
+}
+
+func (l *recordingLogger) Debug(...any)          {}
+func (l *recordingLogger) Debugf(string, ...any) {}
+func (l *recordingLogger) Info(args ...any)      { l.Infof("%s", fmt.Sprint(args...)) }
+func (l *recordingLogger) Infof(format string, args ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.infos = append(l.infos, fmt.Sprintf(format, args...))
+}
+func (l *recordingLogger) Warn(...any)                             {}
+func (l *recordingLogger) Warnf(string, ...any)                    {}
+func (l *recordingLogger) Error(...any)                            {}
+func (l *recordingLogger) Errorf(string, ...any)                   {}
+func (l *recordingLogger) WithFields(map[string]any) shared.Logger { return l }
+func (l *recordingLogger) SetLevel(shared.LogLevel)                {}
+func (l *recordingLogger) SetOutput(io.Writer)                     {}
+
+// TestProcessorOptions tests that a processor composed from options bundles into a writer
+// with the given registry and logger, without a destination file.
+func TestProcessorOptions(t *testing.T) {
+	testutil.SetViperKeys(t, map[string]any{shared.ConfigKeyCodeOwnersEnabled: false})
+	srcDir := t.TempDir()
+	testutil.CreateTestFile(t, srcDir, "main.go", []byte(shared.LiteralPackageMain))
+	testutil.CreateTestFile(t, srcDir, "notes.custom", []byte("notes\n"))
+
+	registry := fileproc.NewFileTypeRegistry()
+	registry.Configure(nil, nil, map[string]string{".custom": "custom"}, nil, nil, nil)
+	logger := &recordingLogger{}
+	var bundle bytes.Buffer
+
+	p := NewProcessor(
+		WithSource(srcDir),
+		WithFormat(shared.FormatJSON),
+		WithConcurrency(2),
+		WithWriter(&bundle),
+		WithRegistry(registry),
+		WithLogger(logger),
+	)
+	testutil.MustSucceed(t, p.Process(t.Context()), "Process")
+
+	var output fileproc.OutputData
+	testutil.MustSucceed(t, json.Unmarshal(bundle.Bytes(), &output), "decoding bundle")
+	languages := make(map[string]string, len(output.Files))
+	for _, file := range output.Files {
+		languages[file.Path] = file.Language
+	}
+	if len(languages) != 2 || languages["main.go"] != "go" || languages["notes.custom"] != "custom" {
+		t.Errorf("bundled files = %v, want main.go (go) and notes.custom (custom)", languages)
+	}
+
+	want := fmt.Sprintf(shared.CLIMsgFoundFilesToProcess, 2)
+	if !strings.Contains(strings.Join(logger.infos, "\n"), want) {
+		t.Errorf("logger got %q, want a message %q", logger.infos, want)
+	}
+}
+
+// TestProcessorConcurrentSettings tests that processors with different formats and Settings
+// of their own run side by side, each bundling by its own configuration rather than the
+// global one.
+func TestProcessorConcurrentSettings(t *testing.T) {
+	testutil.SetViperKeys(t, map[string]any{shared.ConfigKeyOutputFileIDs: true})
+	srcDir := t.TempDir()
+	testutil.CreateTestFile(t, srcDir, "main.go", []byte(shared.LiteralPackageMain))
+	generated := testutil.CreateTestDirectory(t, srcDir, "generated")
+	testutil.CreateTestFile(t, generated, "types.go", []byte(shared.LiteralPackageMain))
+
+	markdown := config.NewSettings()
+	markdown.Set(shared.ConfigKeyCodeOwnersEnabled, false)
+	markdown.Set(shared.ConfigKeyIgnoreDirectories, []string{"generated"})
+	jsonSettings := config.NewSettings()
+	jsonSettings.Set(shared.ConfigKeyCodeOwnersEnabled, false)
+
+	runs := []struct {
+		format   string
+		settings *config.Settings
+		bundle   bytes.Buffer
+		err      error
+	}{
+		{format: shared.FormatMarkdown, settings: markdown},
+		{format: shared.FormatJSON, settings: jsonSettings},
+	}
+	var wg sync.WaitGroup
+	for i := range runs {
+		run := &runs[i]
+		wg.Go(func() {
+			run.err = NewProcessor(
+				WithSource(srcDir),
+				WithFormat(run.format),
+				WithWriter(&run.bundle),
+				WithSettings(run.settings),
+				WithLogger(shared.NewLogger()),
+			).Process(t.Context())
+		})
+	}
+	wg.Wait()
+
+	testutil.MustSucceed(t, runs[0].err, "markdown run")
+	testutil.MustSucceed(t, runs[1].err, "JSON run")
+	if got := runs[0].bundle.String(); !strings.Contains(got, "main.go") || strings.Contains(got, "types.go") {
+		t.Errorf("markdown bundle = %q, want main.go without the ignored generated directory", got)
+	}
+	var output fileproc.OutputData
+	testutil.MustSucceed(t, json.Unmarshal(runs[1].bundle.Bytes(), &output), "decoding JSON bundle")
+	if len(output.Files) != 2 {
+		t.Fatalf("JSON bundle holds %d files, want 2", len(output.Files))
+	}
+	for _, file := range output.Files {
+		if _, ok := file.Metadata[shared.MetadataKeyID]; ok {
+			t.Errorf("%s has a file ID from the global output.fileIds", file.Path)
+		}
+	}
+}
+
+// TestProcessorRunID tests that the run ID given to a processor reaches its log lines and the
+// bundle's run summary, and that processors get distinct run IDs by default.
+func TestProcessorRunID(t *testing.T) {
+	const runID = "20261015T093012-9f3a61c2"
+	srcDir := t.TempDir()
+	testutil.CreateTestFile(t, srcDir, "main.go", []byte(shared.LiteralPackageMain))
+	settings := config.NewSettings()
+	settings.Set(shared.ConfigKeyCodeOwnersEnabled, false)
+	settings.Set(shared.ConfigKeyOutputAppendRunSummary, true)
+	var logs, bundle bytes.Buffer
+	logger := shared.NewLogger()
+	logger.SetOutput(&logs)
+	logger.SetLevel(shared.LogLevelInfo)
+
+	p := NewProcessor(
+		WithSource(srcDir),
+		WithFormat(shared.FormatJSON),
+		WithWriter(&bundle),
+		WithSettings(settings),
+		WithLogger(logger),
+		WithRunID(runID),
+	)
+	testutil.MustSucceed(t, p.Process(t.Context()), "Process")
+
+	var output fileproc.OutputData
+	testutil.MustSucceed(t, json.Unmarshal(bundle.Bytes(), &output), "decoding bundle")
+	if output.Summary == nil || output.Summary.RunID != runID {
+		t.Errorf("run summary = %+v, want run ID %s", output.Summary, runID)
+	}
+	for line := range strings.Lines(logs.String()) {
+		if !strings.Contains(line, shared.LogFieldRunID+"="+runID) {
+			t.Errorf("log line %q has no run ID %s", line, runID)
+		}
+	}
+	if logs.Len() == 0 {
+		t.Error("processor logged nothing")
+	}
+
+	if first, second := NewProcessor().RunID(), NewProcessor().RunID(); first == "" || first == second {
+		t.Errorf("default run IDs = %q and %q, want distinct IDs", first, second)
+	}
+}
+
+// TestProcessorRunIDConcurrent tests that processors running at once on the shared logger tag
+// their log lines with their own run IDs, and that no run ID stays on the logger after a run.
+func TestProcessorRunIDConcurrent(t *testing.T) {
+	var logs bytes.Buffer
+	logger := shared.GetLogger()
+	logger.SetOutput(&logs)
+	logger.SetLevel(shared.LogLevelInfo)
+	t.Cleanup(func() {
+		logger.SetOutput(os.Stderr)
+		logger.SetLevel(shared.LogLevelWarn)
+	})
+
+	runs := map[string]int{"run-a": 1, "run-b": 2}
+	var wg sync.WaitGroup
+	for runID, files := range runs {
+		srcDir := t.TempDir()
+		for i := range files {
+			testutil.CreateTestFile(t, srcDir, fmt.Sprintf("main%d.go", i), []byte(shared.LiteralPackageMain))
+		}
+		settings := config.NewSettings()
+		settings.Set(shared.ConfigKeyCodeOwnersEnabled, false)
+		p := NewProcessor(
+			WithSource(srcDir), WithFormat(shared.FormatJSON), WithWriter(io.Discard),
+			WithSettings(settings), WithRunID(runID),
+		)
+		wg.Go(func() {
+			if err := p.Process(t.Context()); err != nil {
+				t.Errorf("Process %s: %v", runID, err)
+			}
+		})
+	}
+	wg.Wait()
+	logger.Warn("after the runs")
+
+	output := logs.String()
+	for runID, files := range runs {
+		found := fmt.Sprintf(shared.CLIMsgFoundFilesToProcess, files)
+		if !strings.Contains(output, found) {
+			t.Fatalf("no %q line logged:\n%s", found, output)
+		}
+		for line := range strings.Lines(output) {
+			if strings.Contains(line, found) && !strings.Contains(line, shared.LogFieldRunID+"="+runID) {
+				t.Errorf("log line %q, want run ID %s", line, runID)
+			}
+		}
+	}
+	for line := range strings.Lines(output) {
+		if strings.Contains(line, "after the runs") && strings.Contains(line, shared.LogFieldRunID) {
+			t.Errorf("log line %q after the runs carries a run ID", line)
+		}
+	}
+}
+
+// TestProcessorOptionsWithPromptTemplate tests that a prompt-wrapped bundle goes to the writer.
+func TestProcessorOptionsWithPromptTemplate(t *testing.T) {
+	testutil.SetViperKeys(t, map[string]any{shared.ConfigKeyCodeOwnersEnabled: false})
+	srcDir := t.TempDir()
+	testutil.CreateTestFile(t, srcDir, "main.go", []byte(shared.LiteralPackageMain))
+	tmpl := testutil.CreateTestFile(t, t.TempDir(), "p.tmpl", []byte("Review:\n{{.Bundle}}"))
+
+	flags := &Flags{SourceDir: srcDir, Format: shared.FormatMarkdown, Concurrency: 1, PromptTemplate: tmpl, NoUI: true}
+	var bundle bytes.Buffer
+	testutil.MustSucceed(t, NewProcessor(WithFlags(flags), WithWriter(&bundle)).Process(t.Context()), "Process")
+
+	if got := bundle.String(); !strings.HasPrefix(got, "Review:\n") || !strings.Contains(got, shared.LiteralPackageMain) {
+		t.Errorf("bundle = %q, want the prompt around the bundle", got)
+	}
+}
+
+// TestProcessorStdout tests that --stdout writes the bundle to stdout without a destination file.
+func TestProcessorStdout(t *testing.T) {
+	testutil.SetViperKeys(t, map[string]any{shared.ConfigKeyCodeOwnersEnabled: false})
+	srcDir := t.TempDir()
+	testutil.CreateTestFile(t, srcDir, "main.go", []byte(shared.LiteralPackageMain))
+	stdout, _ := testutil.CreateTempOutputFile(t, "stdout")
+	saved := os.Stdout
+	os.Stdout = stdout
+	t.Cleanup(func() { os.Stdout = saved })
+
+	flags := &Flags{SourceDir: srcDir, Format: shared.FormatMarkdown, Concurrency: 1, Stdout: true, NoUI: true}
+	p := NewProcessor(WithFlags(flags))
+	os.Stdout = saved
+	testutil.MustSucceed(t, p.Process(t.Context()), "Process")
+
+	if name := p.destinationName(); name != "stdout" {
+		t.Errorf("destinationName() = %q, want stdout", name)
+	}
+	written, err := os.ReadFile(stdout.Name())
+	testutil.MustSucceed(t, err, "reading stdout")
+	if !strings.Contains(string(written), shared.LiteralPackageMain) {
+		t.Errorf("stdout = %q, want the bundle", written)
+	}
+	if entries, _ := os.ReadDir(srcDir); len(entries) != 1 {
+		t.Errorf("source directory holds %d entries, want no bundle file next to main.go", len(entries))
+	}
+}
+
+// TestProcessorOptionsValidation tests that Process rejects settings the options left invalid.
+func TestProcessorOptionsValidation(t *testing.T) {
+	srcDir := t.TempDir()
+	testutil.SetViperKeys(t, map[string]any{
+		shared.ConfigKeyOutputPlugins: map[string]any{"missing": map[string]any{"command": []string{"gibidify-no-plugin"}}},
+	})
+	tests := []struct {
+		name string
+		opts []ProcessorOption
+	}{
+		{name: "missing source", opts: []ProcessorOption{WithWriter(io.Discard)}},
+		{name: "missing destination and writer", opts: []ProcessorOption{WithSource(srcDir)}},
+		{
+			name: "unsupported format",
+			opts: []ProcessorOption{WithSource(srcDir), WithWriter(io.Discard), WithFormat("xml")},
+		},
+		{
+			name: "format plugin that cannot be run",
+			opts: []ProcessorOption{WithSource(srcDir), WithWriter(io.Discard), WithFormat("missing")},
+		},
+		{
+			name: "invalid concurrency",
+			opts: []ProcessorOption{WithSource(srcDir), WithWriter(io.Discard), WithConcurrency(0)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := NewProcessor(tt.opts...).Process(t.Context()); err == nil {
+				t.Error("Process succeeded, want a validation error")
+			}
+		})
+	}
+}
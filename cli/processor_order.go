@@ -0,0 +1,66 @@
+// Package cli provides command-line interface functionality for gibidify.
+package cli
+
+import (
+	"os"
+
+	"github.com/ivuorinen/gibidify/fileproc"
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// applyOrder puts files in the order of the --order strategy, so the most important files are
+// handed to the workers first. Ordering by recency needs the git history of the source, and
+// ordering by complexity reads every file.
+func (p *Processor) applyOrder(files []string) ([]string, error) {
+	if p.flags.Order == "" {
+		return files, nil
+	}
+
+	absRoot, err := shared.AbsolutePath(p.flags.SourceDir)
+	if err != nil {
+		return nil, shared.WrapError(err, shared.ErrorTypeFileSystem, shared.CodeFSPathResolution, "resolving source")
+	}
+	opts := fileproc.OrderOptions{Root: absRoot, Priority: p.settings.OrderPriority()}
+
+	switch p.flags.Order {
+	case shared.OrderSize:
+		opts.Sizes = make(map[string]int64, len(files))
+		for _, file := range files {
+			if info, err := os.Stat(file); err == nil {
+				opts.Sizes[file] = info.Size()
+			}
+		}
+	case shared.OrderRecency:
+		if !p.settings.GitEnabled() {
+			return nil, shared.NewStructuredError(
+				shared.ErrorTypeConfiguration, shared.CodeConfigValidation,
+				"--order recency requires git integration (git.enabled)", "", nil,
+			)
+		}
+		recency, err := p.git().LoadRecency(p.flags.SourceDir)
+		if err != nil {
+			return nil, err
+		}
+		opts.LastCommit = recency.LastCommit
+	case shared.OrderComplexity:
+		opts.Complexity = p.fileComplexities(files)
+	}
+
+	p.logger.Infof("Ordering %d files by %s", len(files), p.flags.Order)
+
+	return fileproc.OrderFiles(files, p.flags.Order, opts), nil
+}
+
+// fileComplexities returns the approximate cyclomatic complexity of every file that can be read.
+func (p *Processor) fileComplexities(files []string) map[string]int64 {
+	complexities := make(map[string]int64, len(files))
+	for _, file := range files {
+		content, err := os.ReadFile(file) // #nosec G304 -- files were collected from the validated source
+		if err != nil {
+			continue
+		}
+		complexities[file] = int64(fileproc.MeasureCode(string(content), p.registry.Language(file)).Complexity)
+	}
+
+	return complexities
+}
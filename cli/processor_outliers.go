@@ -0,0 +1,113 @@
+// Package cli provides command-line interface functionality for gibidify.
+package cli
+
+import (
+	"bufio"
+	"cmp"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// outlier is a collected file taking more than collection.outlierPercent of the collected size.
+type outlier struct {
+	path string
+	size int64
+}
+
+// excludeOutliers looks for single files taking more than collection.outlierPercent of the
+// collected size, such as a vendored JSON file, and lists them. They are excluded with
+// --auto-exclude-outliers or when the user confirms it on a terminal, and kept otherwise.
+func (p *Processor) excludeOutliers(files []string) []string {
+	percent := p.settings.CollectionOutlierPercent()
+	if percent == 0 || len(files) < shared.CollectionOutlierMinFiles {
+		return files
+	}
+	outliers, total := findOutliers(files, percent)
+	if len(outliers) == 0 {
+		return files
+	}
+
+	p.printPreview("%d file(s) take more than %d%% of the %d bytes collected:\n", len(outliers), percent, total)
+	for _, o := range outliers {
+		path := o.path
+		if rel, err := filepath.Rel(p.flags.SourceDir, o.path); err == nil {
+			path = filepath.ToSlash(rel)
+		}
+		p.printPreview("  %s (%d bytes, %d%%)\n", path, o.size, o.size*100/total)
+	}
+	if !p.flags.AutoExcludeOutliers && (!p.promptOutliers() || !p.confirmOutliers()) {
+		p.printPreview("Keeping them; --auto-exclude-outliers excludes them\n")
+
+		return files
+	}
+
+	excluded := make(map[string]bool, len(outliers))
+	for _, o := range outliers {
+		excluded[o.path] = true
+		p.resourceMonitor.RecordFileSkipped(shared.SkipReasonOutlier, o.size)
+		if p.metricsCollector != nil {
+			p.metricsCollector.RecordFileSkipped(o.path, shared.SkipReasonOutlier, o.size)
+		}
+	}
+	p.printPreview("Excluded %d file(s)\n", len(outliers))
+
+	// files may be the daemon's warmed index, so the kept files go to a new slice
+	kept := make([]string, 0, len(files)-len(outliers))
+	for _, file := range files {
+		if !excluded[file] {
+			kept = append(kept, file)
+		}
+	}
+
+	return kept
+}
+
+// findOutliers returns the files taking more than percent of the size of all files, largest
+// first, and that total size.
+func findOutliers(files []string, percent int) ([]outlier, int64) {
+	sizes := make([]outlier, 0, len(files))
+	var total int64
+	for _, file := range files {
+		if info, err := os.Stat(file); err == nil {
+			sizes = append(sizes, outlier{path: file, size: info.Size()})
+			total += info.Size()
+		}
+	}
+
+	var outliers []outlier
+	for _, o := range sizes {
+		if o.size*100 > total*int64(percent) {
+			outliers = append(outliers, o)
+		}
+	}
+	slices.SortFunc(outliers, func(a, b outlier) int {
+		return cmp.Or(cmp.Compare(b.size, a.size), cmp.Compare(a.path, b.path))
+	})
+
+	return outliers, total
+}
+
+// promptOutliers reports whether the user can be asked about outliers: the answer is read from
+// a terminal and the question shown on one.
+func (p *Processor) promptOutliers() bool {
+	in, ok := p.confirmIn.(*os.File)
+
+	return ok && isTerminal(in) && isTerminal(p.confirmOut)
+}
+
+// confirmOutliers asks whether to exclude the listed outliers. Anything but yes keeps them.
+func (p *Processor) confirmOutliers() bool {
+	p.printPreview("Exclude them? [y/N] ")
+
+	answer, err := bufio.NewReader(p.confirmIn).ReadString('\n')
+	if err != nil && answer == "" {
+		p.printPreview("\n")
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+
+	return answer == "y" || answer == "yes"
+}
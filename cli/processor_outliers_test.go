@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// TestProcessorExcludeOutliers tests that files over collection.outlierPercent are listed, and
+// excluded only with --auto-exclude-outliers since the prompt needs a terminal.
+func TestProcessorExcludeOutliers(t *testing.T) {
+	tests := []struct {
+		name         string
+		percent      int
+		autoExclude  bool
+		wantListed   bool
+		wantExcluded bool
+	}{
+		{name: "auto excluded", percent: 50, autoExclude: true, wantListed: true, wantExcluded: true},
+		{name: "kept without a terminal", percent: 50, wantListed: true},
+		{name: "below the threshold", percent: 90, autoExclude: true},
+		{name: "disabled", percent: 0, autoExclude: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer testutil.SuppressAllOutput(t)()
+			testutil.ResetViperConfig(t, "")
+			testutil.SetViperKeys(t, map[string]any{shared.ConfigKeyCollectionOutlierPercent: tt.percent})
+			srcDir := t.TempDir()
+			for _, name := range []string{"a.go", "b.go", "c.go", "d.go"} {
+				testutil.CreateTestFile(t, srcDir, name, []byte(shared.LiteralPackageMain))
+			}
+			testutil.CreateTestFile(t, srcDir, "data.json", []byte(`{"rows": "`+strings.Repeat("x", 200)+`"}`))
+
+			destination := filepath.Join(t.TempDir(), "bundle.md")
+			flags := &Flags{
+				SourceDir: srcDir, Destination: destination, Format: shared.FormatMarkdown,
+				Concurrency: 1, NoUI: true, AutoExcludeOutliers: tt.autoExclude,
+			}
+			var prompt bytes.Buffer
+			p := NewProcessor(WithFlags(flags))
+			p.confirmOut = &prompt
+			testutil.MustSucceed(t, p.Process(t.Context()), "Process")
+
+			if listed := strings.Contains(prompt.String(), "  data.json ("); listed != tt.wantListed {
+				t.Errorf("prompt = %q, want data.json listed = %v", prompt.String(), tt.wantListed)
+			}
+			bundle, err := os.ReadFile(destination)
+			testutil.MustSucceed(t, err, "reading bundle")
+			if excluded := !strings.Contains(string(bundle), "data.json"); excluded != tt.wantExcluded {
+				t.Errorf("data.json excluded = %v, want %v", excluded, tt.wantExcluded)
+			}
+		})
+	}
+}
+
+// TestProcessorConfirmOutliers tests that only a yes answer excludes the outliers.
+func TestProcessorConfirmOutliers(t *testing.T) {
+	tests := []struct {
+		answer string
+		want   bool
+	}{
+		{answer: "y\n", want: true},
+		{answer: "YES\n", want: true},
+		{answer: "n\n"},
+		{answer: ""},
+	}
+
+	for _, tt := range tests {
+		p := &Processor{confirmIn: strings.NewReader(tt.answer), confirmOut: &bytes.Buffer{}}
+		if got := p.confirmOutliers(); got != tt.want {
+			t.Errorf("confirmOutliers(%q) = %v, want %v", tt.answer, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+// Package cli provides command-line interface functionality for gibidify.
+package cli
+
+import (
+	"strings"
+
+	"github.com/ivuorinen/gibidify/gitutil"
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// applyCodeOwners loads CODEOWNERS, filters files by --owner, and registers
+// an annotator that records each file's owners in its metadata.
+func (p *Processor) applyCodeOwners(files []string) ([]string, error) {
+	if !p.settings.CodeOwnersEnabled() {
+		if p.flags.Owner != "" {
+			return nil, shared.NewStructuredError(
+				shared.ErrorTypeConfiguration,
+				shared.CodeConfigValidation,
+				"--owner requires CODEOWNERS support (codeowners.enabled)",
+				"",
+				nil,
+			)
+		}
+
+		return files, nil
+	}
+
+	owners, err := p.loadCodeOwners()
+	if err != nil {
+		return nil, err
+	}
+	if owners == nil {
+		if p.flags.Owner != "" {
+			return nil, shared.NewStructuredError(
+				shared.ErrorTypeValidation,
+				shared.CodeFSNotFound,
+				"--owner given but no CODEOWNERS file was found",
+				p.flags.SourceDir,
+				map[string]any{"locations": gitutil.CodeOwnersLocations},
+			)
+		}
+
+		return files, nil
+	}
+
+	p.annotators = append(p.annotators, func(filePath, _ string) map[string]string {
+		if fileOwners := owners.Owners(filePath); len(fileOwners) > 0 {
+			return map[string]string{shared.MetadataKeyOwners: strings.Join(fileOwners, " ")}
+		}
+
+		return nil
+	})
+
+	if p.flags.Owner == "" {
+		return files, nil
+	}
+
+	kept := make([]string, 0, len(files))
+	for _, file := range files {
+		if owners.IsOwnedBy(file, p.flags.Owner) {
+			kept = append(kept, file)
+		}
+	}
+	p.logger.Infof("Owner filter %q kept %d of %d files", p.flags.Owner, len(kept), len(files))
+
+	return kept, nil
+}
+
+// loadCodeOwners locates and parses the CODEOWNERS file for the source directory.
+// Returns nil without error when no CODEOWNERS file exists.
+func (p *Processor) loadCodeOwners() (*gitutil.CodeOwners, error) {
+	root, err := shared.AbsolutePath(p.flags.SourceDir)
+	if err != nil {
+		return nil, shared.WrapError(err, shared.ErrorTypeFileSystem, shared.CodeFSPathResolution, "resolving source")
+	}
+	if p.settings.GitEnabled() && gitutil.Available() {
+		if repoRoot, err := p.git().RepoRoot(root); err == nil {
+			root = repoRoot
+		}
+	}
+
+	path := p.settings.CodeOwnersPath()
+	if path == "" {
+		path = gitutil.FindCodeOwners(root)
+	}
+	if path == "" {
+		return nil, nil
+	}
+	if err := p.restriction.check("CODEOWNERS file", path); err != nil {
+		return nil, err
+	}
+	p.logger.Debugf("Using CODEOWNERS file: %s", path)
+
+	return gitutil.LoadCodeOwners(path, root)
+}
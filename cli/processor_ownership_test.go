@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// TestApplyCodeOwners tests --owner filtering and owner annotation.
+func TestApplyCodeOwners(t *testing.T) {
+	dir := t.TempDir()
+	testutil.CreateTestFile(t, dir, "CODEOWNERS", []byte("*.go @org/backend\n*.js @org/frontend\n"))
+	goFile := testutil.CreateTestFile(t, dir, "main.go", []byte(shared.LiteralPackageMain))
+	jsFile := testutil.CreateTestFile(t, dir, "app.js", []byte("console.log(1)"))
+	testutil.SetViperKeys(t, map[string]any{shared.ConfigKeyGitEnabled: false})
+	defer testutil.SuppressLogs(t)()
+
+	p := NewProcessor(WithFlags(&Flags{SourceDir: dir, Owner: "@org/backend"}))
+	got, err := p.applyCodeOwners([]string{goFile, jsFile})
+	testutil.MustSucceed(t, err, "applyCodeOwners")
+
+	if len(got) != 1 || filepath.Base(got[0]) != "main.go" {
+		t.Fatalf("expected only main.go, got %v", got)
+	}
+	if len(p.annotators) != 1 {
+		t.Fatalf("expected owners annotator to be registered, got %d", len(p.annotators))
+	}
+	if meta := p.annotators[0](jsFile, "app.js"); meta[shared.MetadataKeyOwners] != "@org/frontend" {
+		t.Errorf("unexpected annotation: %v", meta)
+	}
+}
+
+// TestApplyCodeOwnersMissingFile tests that --owner fails without a CODEOWNERS file.
+func TestApplyCodeOwnersMissingFile(t *testing.T) {
+	testutil.SetViperKeys(t, map[string]any{shared.ConfigKeyGitEnabled: false})
+
+	p := NewProcessor(WithFlags(&Flags{SourceDir: t.TempDir(), Owner: "@org/backend"}))
+	_, err := p.applyCodeOwners([]string{"a.go"})
+	testutil.VerifyStructuredError(t, err, shared.ErrorTypeValidation, shared.CodeFSNotFound)
+
+	p.flags.Owner = ""
+	files, err := p.applyCodeOwners([]string{"a.go"})
+	testutil.MustSucceed(t, err, "applyCodeOwners without owner")
+	if len(files) != 1 || len(p.annotators) != 0 {
+		t.Errorf("expected passthrough without annotators, got %v / %d", files, len(p.annotators))
+	}
+}
@@ -0,0 +1,35 @@
+//go:build unix
+
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// TestProcessorFormatPlugin tests that a run in an output.plugins format writes the bundle the
+// plugin makes of the file events, here the events themselves echoed by cat.
+func TestProcessorFormatPlugin(t *testing.T) {
+	testutil.SetViperKeys(t, map[string]any{
+		shared.ConfigKeyOutputPlugins: map[string]any{"ndjson": map[string]any{"command": []string{"cat"}}},
+	})
+	srcDir := t.TempDir()
+	testutil.CreateTestFile(t, srcDir, "main.go", []byte("package main\n"))
+	destination := filepath.Join(t.TempDir(), "bundle.ndjson")
+
+	p := NewProcessor(WithSource(srcDir), WithDestination(destination), WithFormat("ndjson"))
+	testutil.MustSucceed(t, p.Process(t.Context()), "processing with a format plugin")
+
+	data, err := os.ReadFile(destination)
+	testutil.MustSucceed(t, err, "reading bundle")
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[0], `"event":"start"`) ||
+		!strings.Contains(lines[1], `"path":"main.go"`) || !strings.Contains(lines[2], `"event":"end"`) {
+		t.Errorf("bundle = %s, want the start, main.go and end events", data)
+	}
+}
@@ -0,0 +1,77 @@
+// Package cli provides command-line interface functionality for gibidify.
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ivuorinen/gibidify/policy"
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// loadPolicy loads the organization policy file, if one is installed.
+func (p *Processor) loadPolicy() error {
+	path := policy.Find()
+	if p.flags.Hermetic {
+		path = policy.FindAdministered()
+	}
+	if path == "" {
+		return nil
+	}
+
+	loaded, err := policy.Load(path)
+	if err != nil {
+		return err
+	}
+	p.policy = loaded
+	p.logger.Infof("Using policy file: %s", path)
+
+	return nil
+}
+
+// enforcePolicy checks the destination and the collected files against the policy. Bundles
+// written to stdout or a writer have no destination to check.
+// Violations fail the run unless --policy-override is set, in which case they are audit-logged.
+func (p *Processor) enforcePolicy(files []string) error {
+	if p.policy == nil {
+		return nil
+	}
+
+	var violations []policy.Violation
+	if p.flags.Destination != "" {
+		violations = p.policy.CheckDestination(p.flags.Destination)
+	}
+	violations = append(violations, p.policy.CheckFiles(p.flags.SourceDir, files)...)
+
+	return p.handleViolations(violations, p.flags.Destination)
+}
+
+// handleViolations fails the run with violations of the policy unless --policy-override is
+// set, in which case they are audit-logged with the destination the bundle goes to.
+func (p *Processor) handleViolations(violations []policy.Violation, destination string) error {
+	if len(violations) == 0 {
+		return nil
+	}
+
+	if !p.flags.PolicyOverride {
+		details := make([]string, len(violations))
+		for i, v := range violations {
+			details[i] = v.String()
+		}
+
+		return shared.NewStructuredError(
+			shared.ErrorTypeValidation,
+			shared.CodeValidationPolicy,
+			fmt.Sprintf("configuration violates policy %s: %s", p.policy.Path(), strings.Join(details, "; ")),
+			"",
+			map[string]any{"violations": len(violations)},
+		)
+	}
+
+	p.ui.PrintWarning("Overriding %d policy violation(s); this run is audit-logged", len(violations))
+
+	record := p.policy.NewAuditRecord(p.flags.SourceDir, destination, violations)
+	record.RunID = p.runID
+
+	return p.policy.Audit(record)
+}
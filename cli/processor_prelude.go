@@ -0,0 +1,47 @@
+// Package cli provides command-line interface functionality for gibidify.
+package cli
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/ivuorinen/gibidify/fileproc"
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// preludeFiles returns the prelude documents of the run: --prelude, or output.prelude when the
+// flag is unset.
+func (p *Processor) preludeFiles() []string {
+	if files := p.flags.PreludeFiles(); len(files) > 0 {
+		return files
+	}
+
+	return p.settings.OutputPrelude()
+}
+
+// loadPrelude reads the prelude documents and queues them as leading output entries.
+func (p *Processor) loadPrelude() error {
+	for _, path := range p.preludeFiles() {
+		content, err := os.ReadFile(path) // #nosec G304 -- prelude paths are supplied explicitly by the user
+		if err != nil {
+			return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIORead, "failed to read prelude").
+				WithFilePath(path)
+		}
+
+		p.leadingEntries = append(p.leadingEntries, fileproc.WriteRequest{
+			Path:     filepath.ToSlash(filepath.Clean(path)),
+			Content:  string(content),
+			Size:     int64(len(content)),
+			Metadata: map[string]string{shared.MetadataKeyRole: shared.MetadataRolePrelude},
+		})
+	}
+
+	return nil
+}
+
+// sendLeadingEntries queues the prelude entries ahead of any file output.
+func (p *Processor) sendLeadingEntries(writeCh chan fileproc.WriteRequest) {
+	for _, entry := range p.leadingEntries {
+		writeCh <- p.redactEntry(entry)
+	}
+}
@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// TestLoadPrelude tests that --prelude documents are queued in order and override output.prelude.
+func TestLoadPrelude(t *testing.T) {
+	dir := t.TempDir()
+	task := testutil.CreateTestFile(t, dir, "task.md", []byte("# Task"))
+	notes := testutil.CreateTestFile(t, dir, "notes.md", []byte("# Notes"))
+	configured := testutil.CreateTestFile(t, dir, "configured.md", []byte("# Configured"))
+	testutil.SetViperKeys(t, map[string]any{shared.ConfigKeyOutputPrelude: []string{configured}})
+
+	tests := []struct {
+		name    string
+		prelude string
+		want    []string
+	}{
+		{name: "flag overrides config", prelude: task + ", " + notes, want: []string{"# Task", "# Notes"}},
+		{name: "config fallback", prelude: "", want: []string{"# Configured"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewProcessor(WithFlags(&Flags{Prelude: tt.prelude}))
+			testutil.MustSucceed(t, p.loadPrelude(), "loadPrelude")
+
+			if len(p.leadingEntries) != len(tt.want) {
+				t.Fatalf("leading entries = %d, want %d", len(p.leadingEntries), len(tt.want))
+			}
+			for i, entry := range p.leadingEntries {
+				if entry.Content != tt.want[i] || entry.Metadata[shared.MetadataKeyRole] != shared.MetadataRolePrelude {
+					t.Errorf("entry %d = %+v, want content %q with prelude role", i, entry, tt.want[i])
+				}
+			}
+		})
+	}
+}
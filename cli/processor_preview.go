@@ -0,0 +1,115 @@
+// Package cli provides command-line interface functionality for gibidify.
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ivuorinen/gibidify/fileproc"
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// previewDestination reports whether the run previews its changes before overwriting the
+// destination: --preview-diff is set and the destination is an existing, non-empty file.
+func (p *Processor) previewDestination() bool {
+	if !p.flags.PreviewDiff || p.writer != nil {
+		return false
+	}
+	info, err := os.Stat(p.flags.Destination)
+
+	return err == nil && info.Mode().IsRegular() && info.Size() > 0
+}
+
+// confirmOverwrite shows the files the bundle at bundlePath adds, removes and changes relative
+// to the destination, and asks whether to overwrite it. Anything but yes keeps the destination.
+func (p *Processor) confirmOverwrite(bundlePath string) error {
+	destination := p.flags.Destination
+	p.writePreview(bundlePath)
+	p.printPreview("Overwrite %s? [y/N] ", destination)
+
+	answer, err := bufio.NewReader(p.confirmIn).ReadString('\n')
+	if err != nil && answer == "" {
+		p.printPreview("\n")
+	}
+	if answer = strings.ToLower(strings.TrimSpace(answer)); answer == "y" || answer == "yes" {
+		return nil
+	}
+
+	return shared.NewStructuredError(
+		shared.ErrorTypeCLI, shared.CodeCLIOverwriteDeclined,
+		"overwrite declined, kept the existing destination", destination, nil,
+	)
+}
+
+// writePreview writes the summary diff of the bundle at bundlePath against the destination.
+func (p *Processor) writePreview(bundlePath string) {
+	destination := p.flags.Destination
+	previous, err := fileproc.ReadBundleManifestWithSettings(destination, p.flags.Format, p.settings)
+	if err != nil {
+		p.printPreview("%s is not a readable %s bundle, it will be replaced entirely: %v\n",
+			destination, p.flags.Format, err)
+
+		return
+	}
+	current, err := fileproc.ReadBundleManifestWithSettings(bundlePath, p.flags.Format, p.settings)
+	if err != nil {
+		p.printPreview("The new bundle cannot be compared: %v\n", err)
+
+		return
+	}
+
+	diff := current.Diff(previous)
+	if diff.Empty() {
+		p.printPreview("No file changes against %s\n", destination)
+
+		return
+	}
+	p.printPreview("Changes to %s:\n", destination)
+	p.printPreviewPaths("+", diff.Added)
+	p.printPreviewPaths("-", diff.Removed)
+	p.printPreviewPaths("~", diff.Changed)
+	p.printPreview("%d added, %d removed, %d changed\n", len(diff.Added), len(diff.Removed), len(diff.Changed))
+}
+
+// printPreviewPaths lists up to shared.PreviewDiffMaxPaths paths marked with marker.
+func (p *Processor) printPreviewPaths(marker string, paths []string) {
+	for _, path := range paths[:min(len(paths), shared.PreviewDiffMaxPaths)] {
+		p.printPreview("  %s %s\n", marker, path)
+	}
+	if more := len(paths) - shared.PreviewDiffMaxPaths; more > 0 {
+		p.printPreview("  %s and %d more\n", marker, more)
+	}
+}
+
+// printPreview writes to the preview output, which stays visible with --no-ui.
+func (p *Processor) printPreview(format string, args ...any) {
+	_, _ = fmt.Fprintf(p.confirmOut, format, args...)
+}
+
+// installBundle copies the previewed bundle in file over the destination.
+func (p *Processor) installBundle(file *os.File) error {
+	if !p.previewing {
+		return nil
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIORead, "failed to rewind bundle").
+			WithFilePath(file.Name())
+	}
+
+	outFile, err := p.createOutputFile()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		shared.LogError("Error closing output file", outFile.Close())
+	}()
+	if _, err := io.Copy(outFile, file); err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOFileWrite, "failed to write bundle").
+			WithFilePath(p.flags.Destination)
+	}
+
+	return p.syncOutput(outFile)
+}
@@ -3,36 +3,74 @@ package cli
 
 import (
 	"context"
+	"fmt"
 	"os"
+	"runtime"
 	"sync"
 	"time"
 
+	"github.com/ivuorinen/gibidify/config"
 	"github.com/ivuorinen/gibidify/fileproc"
 	"github.com/ivuorinen/gibidify/shared"
 )
 
-// Process executes the main file processing workflow.
+// Process executes the main file processing workflow. The context passed on carries the run
+// ID, which the lines of the processor's logger carry as well.
 func (p *Processor) Process(ctx context.Context) error {
+	ctx = shared.ContextWithRunID(ctx, p.runID)
+	if err := p.validate(); err != nil {
+		return err
+	}
+	p.destinationTemplate = p.flags.Destination
+	p.flags.Destination = expandDestination(p.flags.Destination, time.Now())
+	if err := p.loadRestriction(); err != nil {
+		return err
+	}
+	if err := p.resourceMonitor.Restrict(p.resourceLimits); err != nil {
+		return err
+	}
+
 	// Create overall processing context with timeout
 	overallCtx, overallCancel := p.resourceMonitor.CreateOverallProcessingContext(ctx)
 	defer overallCancel()
 
-	// Configure file type registry
-	p.configureFileTypes()
+	// Parse the prompt template and the organization policy before doing any work
+	if err := p.loadPromptTemplate(); err != nil {
+		return err
+	}
+	if err := p.loadPolicy(); err != nil {
+		return err
+	}
+	if err := p.loadSigningKey(); err != nil {
+		return err
+	}
+	if err := p.loadWasmTransforms(overallCtx); err != nil {
+		return err
+	}
+	defer p.closeWasmTransforms(overallCtx)
+	p.secrets = fileproc.SecretRedactions(p.settings)
+	p.loadContentCache()
 
 	// Print startup info with colors
-	p.ui.PrintHeader("🚀 Starting gibidify")
+	p.ui.PrintHeader(p.ui.theme.start + "Starting gibidify")
+	p.ui.PrintInfo("Run ID: %s", p.runID)
 	p.ui.PrintInfo("Format: %s", p.flags.Format)
 	p.ui.PrintInfo("Source: %s", p.flags.SourceDir)
-	p.ui.PrintInfo("Destination: %s", p.flags.Destination)
-	p.ui.PrintInfo("Workers: %d", p.flags.Concurrency)
+	p.ui.PrintInfo("Destination: %s", p.destinationName())
+	p.ui.PrintInfo("Workers: %d (GOMAXPROCS %d)", p.flags.Concurrency, runtime.GOMAXPROCS(0))
+	p.metricsCollector.RecordWorkers(p.flags.Concurrency)
+	p.setupWarnings()
 
 	// Log resource monitoring configuration
 	p.resourceMonitor.LogResourceInfo()
+	config.LogContainerLimits()
+	openFiles := fileproc.ConfigureOpenFiles(p.settings)
+	p.logger.Debugf("Open file limit: %d (raised: %v), source files open at once: %d (0 is unbounded)",
+		openFiles.Limit, openFiles.Raised, openFiles.MaxOpen)
 	p.backpressure.LogBackpressureInfo()
 
 	// Collect files with progress indication and timing
-	p.ui.PrintInfo("📁 Collecting files...")
+	p.ui.PrintInfo(p.ui.theme.collect + "Collecting files...")
 	collectionStart := time.Now()
 	files, err := p.collectFiles()
 	collectionTime := time.Since(collectionStart)
@@ -45,48 +83,109 @@ func (p *Processor) Process(ctx context.Context) error {
 	// Show collection results
 	p.ui.PrintSuccess(shared.CLIMsgFoundFilesToProcess, len(files))
 
+	// Offer to leave out single files dominating the bundle
+	files = p.excludeOutliers(files)
+
+	// Refuse runs that violate the organization policy unless overridden
+	if err := p.enforcePolicy(files); err != nil {
+		return err
+	}
+
 	// Pre-validate file collection against resource limits
 	if err := p.validateFileCollection(files); err != nil {
 		return err
 	}
 
+	// Check that the destination can take the bundle before spending time on processing
+	if err := p.checkDestination(collectedSize(files)); err != nil {
+		return err
+	}
+
 	// Process files with overall timeout and timing
 	processingStart := time.Now()
 	err = p.processFiles(overallCtx, files)
 	processingTime := time.Since(processingStart)
 	p.metricsCollector.RecordPhaseTime(shared.MetricsPhaseProcessing, processingTime)
+	if err != nil {
+		return err
+	}
+
+	if err := p.shareBundle(overallCtx); err != nil {
+		return err
+	}
+	if err := p.pruneSnapshots(); err != nil {
+		return err
+	}
 
-	return err
+	return p.checkWarnings()
 }
 
 // processFiles processes the collected files.
 func (p *Processor) processFiles(ctx context.Context, files []string) error {
-	outFile, err := p.createOutputFile()
+	stopChaos, err := p.startChaos()
+	if err != nil {
+		return err
+	}
+	defer stopChaos()
+
+	// With --preview-diff the bundle is built aside and only copied over the destination once confirmed
+	p.previewing = p.previewDestination()
+	outFile, cleanup, err := p.createBundleFile()
 	if err != nil {
 		return err
 	}
+	defer cleanup()
 	defer func() {
 		shared.LogError("Error closing output file", outFile.Close())
 	}()
 
 	// Initialize back-pressure and channels
-	p.ui.PrintInfo("⚙️  Initializing processing...")
+	p.ui.PrintInfo(p.ui.theme.setup + "Initializing processing...")
 	p.backpressure.LogBackpressureInfo()
 	fileCh, writeCh := p.backpressure.CreateChannels()
 	writerDone := make(chan struct{})
 
-	// Start writer
-	go fileproc.StartWriter(outFile, writeCh, writerDone, p.flags.Format, p.flags.Prefix, p.flags.Suffix)
+	// With performance.prefetch upcoming small files are read while the workers format earlier ones
+	p.prefetch = fileproc.NewPrefetcherWithSettings(p.settings)
+	defer p.prefetch.Close()
+
+	// Start writer, recording section offsets when --index is set
+	index := p.newBundleIndex()
+	var outputStats fileproc.OutputStats
+	writerOpts := fileproc.WriterOptions{
+		Index: index, Stats: &outputStats, Registry: p.registry, Split: p.splitOptions(), Settings: p.settings,
+		RunID: p.runID,
+	}
+	if p.metricsCollector != nil {
+		writerOpts.Timing = p.metricsCollector.RecordFileTiming
+		writerOpts.Warning = p.metricsCollector.RecordWarning
+	}
+	if p.settings.OutputAppendRunSummary() {
+		writerOpts.Summary = p.completeRunSummary
+	}
+	if p.settings.OutputConfigProvenance() {
+		writerOpts.Config = p.settings.NonDefaultSettings()
+	}
+	go fileproc.StartWriterWithOptions(
+		outFile, writeCh, writerDone, p.flags.Format, p.flags.Prefix, p.flags.Suffix, writerOpts,
+	)
+
+	// Prelude documents precede every file section
+	p.sendLeadingEntries(writeCh)
 
 	// Start workers
 	var wg sync.WaitGroup
 	p.startWorkers(ctx, &wg, fileCh, writeCh)
 
 	// Start progress bar
-	p.ui.StartProgress(len(files), "📝 Processing files")
+	p.ui.StartProgress(len(files), p.ui.theme.process+"Processing files")
 
 	// Send files to workers
 	if err := p.sendFiles(ctx, files, fileCh); err != nil {
+		// Let the canceled workers and the writer finish before the output file is closed
+		wg.Wait()
+		close(writeCh)
+		<-writerDone
 		p.ui.FinishProgress()
 
 		return err
@@ -97,16 +196,68 @@ func (p *Processor) processFiles(ctx context.Context, files []string) error {
 	p.waitForCompletion(&wg, writeCh, writerDone)
 	writingTime := time.Since(writingStart)
 	p.metricsCollector.RecordPhaseTime(shared.MetricsPhaseWriting, writingTime)
+	p.metricsCollector.RecordOutputWrites(outputStats.Writes, outputStats.Bytes)
+	p.saveContentCache()
 
 	p.ui.FinishProgress()
 
+	// A bundle the writer failed to write out is incomplete
+	if outputStats.Err != nil {
+		return shared.WrapError(outputStats.Err, shared.ErrorTypeIO, shared.CodeIOWrite, "failed to write bundle").
+			WithFilePath(outFile.Name())
+	}
+
+	// Workers stop taking files once the run is canceled, leaving the bundle incomplete
+	if err := shared.CheckContextCancellation(ctx, shared.CLIMsgFileProcessingWorker); err != nil {
+		return fmt.Errorf("context check failed: %w", err)
+	}
+
+	if p.previewing {
+		if err := p.confirmOverwrite(outFile.Name()); err != nil {
+			return err
+		}
+	}
+
+	// With a prompt template the destination is written and synced by wrapInPrompt
+	if p.promptTemplate == "" {
+		if err := p.syncOutput(outFile); err != nil {
+			return err
+		}
+		if err := p.copyToWriter(outFile); err != nil {
+			return err
+		}
+		if err := p.installBundle(outFile); err != nil {
+			return err
+		}
+	}
+
+	bundleOffset, err := p.wrapInPrompt(outFile.Name())
+	if err != nil {
+		return err
+	}
+	indexSaved, err := p.saveBundleIndex(index, bundleOffset)
+	if err != nil {
+		return err
+	}
+	if err := p.saveDepfile(files); err != nil {
+		return err
+	}
+	if err := p.signBundle(indexSaved); err != nil {
+		return err
+	}
+
 	// Final cleanup with timing
 	finalizeStart := time.Now()
 	p.logFinalStats()
 	finalizeTime := time.Since(finalizeStart)
 	p.metricsCollector.RecordPhaseTime(shared.MetricsPhaseFinalize, finalizeTime)
+	if err := p.writeRunReport(); err != nil {
+		return err
+	}
+	p.reportWarnings()
+	p.finishSplit()
 
-	p.ui.PrintSuccess("Processing completed. Output saved to %s", p.flags.Destination)
+	p.ui.PrintSuccess("Processing completed. Output saved to %s", p.destinationName())
 
 	return nil
 }
@@ -114,14 +265,24 @@ func (p *Processor) processFiles(ctx context.Context, files []string) error {
 // createOutputFile creates the output file.
 func (p *Processor) createOutputFile() (*os.File, error) {
 	// Destination path has been validated in CLI flags validation for path traversal attempts
-	outFile, err := os.Create(p.flags.Destination) // #nosec G304 - destination is validated in flags.validate()
+	outFile, appended, err := openDestination(p.outputPath(), p.flags.Append)
 	if err != nil {
 		return nil, shared.WrapError(
 			err,
 			shared.ErrorTypeIO,
 			shared.CodeIOFileCreate,
 			"failed to create output file",
-		).WithFilePath(p.flags.Destination)
+		).WithFilePath(p.outputPath())
+	}
+
+	if appended {
+		separator := bundleSeparator(p.flags.Format, p.flags.SourceDir, time.Now())
+		if _, err := outFile.WriteString(separator); err != nil {
+			shared.LogError("Error closing output file", outFile.Close())
+
+			return nil, shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOFileWrite, "failed to write separator").
+				WithFilePath(p.flags.Destination)
+		}
 	}
 
 	return outFile, nil
@@ -0,0 +1,141 @@
+// Package cli provides command-line interface functionality for gibidify.
+package cli
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/templates"
+)
+
+// newPromptEngine creates the template engine used to render --prompt-template scaffolds.
+func (p *Processor) newPromptEngine() *templates.Engine {
+	ctx := templates.TemplateContext{
+		Timestamp:  time.Now(),
+		SourcePath: p.flags.SourceDir,
+		Format:     p.flags.Format,
+		Variables:  p.settings.TemplateVariables(),
+	}
+	if p.metricsCollector != nil {
+		m := p.metricsCollector.CurrentMetrics()
+		ctx.TotalFiles = int(m.TotalFiles)
+		ctx.ProcessedFiles = int(m.ProcessedFiles)
+		ctx.SkippedFiles = int(m.SkippedFiles)
+		ctx.ErrorFiles = int(m.ErrorFiles)
+		ctx.TotalSize = m.TotalSize
+	}
+
+	return templates.NewEngineWithCustomTemplate(templates.OutputTemplate{}, ctx)
+}
+
+// loadPromptTemplate reads and parses the --prompt-template file so that template
+// errors surface before any files are processed.
+func (p *Processor) loadPromptTemplate() error {
+	if p.flags.PromptTemplate == "" {
+		return nil
+	}
+
+	content, err := os.ReadFile(p.flags.PromptTemplate)
+	if err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIORead, "failed to read prompt template").
+			WithFilePath(p.flags.PromptTemplate)
+	}
+	if err := p.newPromptEngine().ParsePrompt(string(content)); err != nil {
+		return shared.WrapError(err, shared.ErrorTypeValidation, shared.CodeValidationFormat, "invalid prompt template").
+			WithFilePath(p.flags.PromptTemplate)
+	}
+	p.promptTemplate = string(content)
+
+	return nil
+}
+
+// createBundleFile creates the file the writer streams the bundle into. With a prompt
+// template or --preview-diff the bundle goes to a temporary file next to the destination, and with WithWriter
+// to one in the system temporary directory, which the returned cleanup function removes.
+func (p *Processor) createBundleFile() (*os.File, func(), error) {
+	if p.promptTemplate == "" && p.writer == nil && !p.previewing {
+		outFile, err := p.createOutputFile()
+
+		return outFile, func() {}, err
+	}
+
+	dir := filepath.Dir(p.flags.Destination)
+	if p.writer != nil {
+		dir = ""
+	}
+	tmpFile, err := os.CreateTemp(dir, ".gibidify-bundle-*")
+	if err != nil {
+		return nil, nil, shared.WrapError(
+			err, shared.ErrorTypeIO, shared.CodeIOFileCreate, "failed to create temporary bundle file",
+		).WithFilePath(p.flags.Destination)
+	}
+
+	return tmpFile, func() { shared.LogError("Error removing temporary bundle", os.Remove(tmpFile.Name())) }, nil
+}
+
+// wrapInPrompt renders the finished bundle through the prompt template into the destination.
+// It returns the byte offset of the bundle within the destination, or -1 when the
+// template transformed the bundle so that it no longer appears verbatim.
+func (p *Processor) wrapInPrompt(bundlePath string) (int64, error) {
+	if p.promptTemplate == "" {
+		return 0, nil
+	}
+
+	bundle, err := os.ReadFile(bundlePath) // #nosec G304 -- temporary file created by createBundleFile
+	if err != nil {
+		return 0, shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIORead, "failed to read bundle").
+			WithFilePath(bundlePath)
+	}
+
+	prompt, err := p.newPromptEngine().RenderPrompt(p.promptTemplate, string(bundle))
+	if err != nil {
+		return 0, shared.WrapError(err, shared.ErrorTypeProcessing, shared.CodeProcessingEncode, "rendering prompt").
+			WithFilePath(p.flags.PromptTemplate)
+	}
+
+	if p.writer != nil {
+		if _, err := io.WriteString(p.writer, prompt); err != nil {
+			return 0, shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOWrite, "failed to write prompt")
+		}
+
+		return promptBundleOffset(prompt, string(bundle), 0), nil
+	}
+
+	outFile, err := p.createOutputFile()
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		shared.LogError("Error closing output file", outFile.Close())
+	}()
+
+	// Appended prompts start after the existing content and separator.
+	base, err := outFile.Seek(0, io.SeekCurrent)
+	if err != nil {
+		base = 0
+	}
+	if _, err := outFile.WriteString(prompt); err != nil {
+		return 0, shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOFileWrite, "failed to write prompt").
+			WithFilePath(p.flags.Destination)
+	}
+	if err := p.syncOutput(outFile); err != nil {
+		return 0, err
+	}
+
+	return promptBundleOffset(prompt, string(bundle), base), nil
+}
+
+// promptBundleOffset returns the byte offset of bundle within a prompt written at base,
+// or -1 when the prompt does not contain the bundle verbatim.
+func promptBundleOffset(prompt, bundle string, base int64) int64 {
+	pos := strings.Index(prompt, bundle)
+	if pos < 0 {
+		return -1
+	}
+
+	return base + int64(pos)
+}
@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// TestProcessWithPromptTemplate tests that --prompt-template wraps the whole bundle.
+func TestProcessWithPromptTemplate(t *testing.T) {
+	srcDir := t.TempDir()
+	testutil.CreateTestFile(t, srcDir, "main.go", []byte(shared.LiteralPackageMain))
+	tmpl := testutil.CreateTestFile(t, t.TempDir(), "review.tmpl",
+		[]byte("SYSTEM: review this {{.Format}} bundle\n<bundle>\n{{.Bundle}}</bundle>\nTASK: {{.Variables.task}}\n"))
+	outDir := t.TempDir()
+	dest := filepath.Join(outDir, "prompt.md")
+	testutil.SetViperKeys(t, map[string]any{
+		shared.ConfigKeyCodeOwnersEnabled: false,
+		shared.ConfigKeyOutputVariables:   map[string]string{"task": "find bugs"},
+	})
+	defer testutil.SuppressLogs(t)()
+
+	p := NewProcessor(WithFlags(&Flags{
+		SourceDir: srcDir, Destination: dest, Format: shared.FormatMarkdown, Concurrency: 1,
+		NoUI: true, PromptTemplate: tmpl,
+	}))
+	testutil.MustSucceed(t, p.Process(t.Context()), "Process")
+
+	content, err := os.ReadFile(dest)
+	testutil.MustSucceed(t, err, "reading prompt")
+	out := string(content)
+	if !strings.HasPrefix(out, "SYSTEM: review this markdown bundle\n<bundle>\n") ||
+		!strings.Contains(out, "## File: `main.go`") || !strings.HasSuffix(out, "</bundle>\nTASK: find bugs\n") {
+		t.Errorf("unexpected prompt output:\n%s", out)
+	}
+
+	entries, err := os.ReadDir(outDir)
+	testutil.MustSucceed(t, err, "listing destination dir")
+	if len(entries) != 1 {
+		t.Errorf("temporary bundle file was not removed: %v", entries)
+	}
+}
+
+// TestLoadPromptTemplateInvalid tests that template syntax errors fail before processing.
+func TestLoadPromptTemplateInvalid(t *testing.T) {
+	tmpl := testutil.CreateTestFile(t, t.TempDir(), "bad.tmpl", []byte("{{.Bundle"))
+
+	p := NewProcessor(WithFlags(&Flags{PromptTemplate: tmpl, NoUI: true}))
+	if err := p.loadPromptTemplate(); err == nil || !strings.Contains(err.Error(), "invalid prompt template") {
+		t.Errorf("expected invalid prompt template error, got %v", err)
+	}
+}
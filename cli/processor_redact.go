@@ -0,0 +1,23 @@
+// Package cli provides command-line interface functionality for gibidify.
+package cli
+
+import (
+	"github.com/ivuorinen/gibidify/fileproc"
+)
+
+// redactEntry applies the secret redactions of redaction.enabled and the mandatory policy
+// redactions to an entry the workers did not process, such as a prelude document, the
+// --from-patch diff or a rollup, so none reaches the bundle unredacted, and counts the
+// replacements with those of the files.
+func (p *Processor) redactEntry(entry fileproc.WriteRequest) fileproc.WriteRequest {
+	var redactions []fileproc.Redaction
+	if p.policy != nil {
+		redactions = p.policy.FileRedactions()
+	}
+	entry, counts := fileproc.RedactEntry(entry, p.secrets, redactions)
+	if len(counts) > 0 && p.metricsCollector != nil {
+		p.metricsCollector.RecordRedactions(counts)
+	}
+
+	return entry
+}
@@ -0,0 +1,41 @@
+// Package cli provides command-line interface functionality for gibidify.
+package cli
+
+import (
+	"bytes"
+	"os"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// writeRunReport writes the --report run report to the --report-file, or to stderr without one.
+// File paths in the report are relative to the source directory.
+func (p *Processor) writeRunReport() error {
+	if p.flags.Report == "" {
+		return nil
+	}
+
+	root, err := shared.AbsolutePath(p.flags.SourceDir)
+	if err != nil {
+		root = ""
+	}
+	var report bytes.Buffer
+	if err := p.metricsCollector.RunReport(root).WriteJSON(&report); err != nil {
+		return err
+	}
+
+	if p.flags.ReportFile == "" || p.flags.ReportFile == "-" {
+		if _, err := p.reportOut.Write(report.Bytes()); err != nil {
+			return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOWrite, "failed to write run report")
+		}
+
+		return nil
+	}
+	if err := os.WriteFile(p.flags.ReportFile, report.Bytes(), shared.OutputFilePermission); err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOFileWrite, "failed to write run report").
+			WithFilePath(p.flags.ReportFile)
+	}
+	p.ui.PrintInfo("Run report saved to %s", p.flags.ReportFile)
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/metrics"
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// TestProcessWritesRunReport tests that --report json writes the run report to the
+// --report-file, or to stderr without one, alongside the bundle.
+func TestProcessWritesRunReport(t *testing.T) {
+	srcDir := t.TempDir()
+	outDir := t.TempDir()
+	testutil.CreateTestFile(t, srcDir, "main.go", []byte(shared.LiteralPackageMain))
+	testutil.CreateTestFile(t, srcDir, "logo.bin", []byte{0x89, 'P', 'N', 'G', 0, 0, 0, 0})
+	testutil.SetViperKeys(t, map[string]any{shared.ConfigKeyCodeOwnersEnabled: false})
+	defer testutil.SuppressLogs(t)()
+
+	reportFile := filepath.Join(outDir, "report.json")
+	for _, target := range []string{reportFile, ""} {
+		var stderr bytes.Buffer
+		p := NewProcessor(WithFlags(&Flags{
+			SourceDir: srcDir, Destination: filepath.Join(outDir, "bundle.json"), Format: shared.FormatJSON,
+			Concurrency: 1, NoUI: true, Report: shared.FormatJSON, ReportFile: target,
+		}))
+		p.reportOut = &stderr
+		testutil.MustSucceed(t, p.Process(t.Context()), "Process")
+
+		data := stderr.Bytes()
+		if target != "" {
+			var err error
+			data, err = os.ReadFile(target) // #nosec G304 -- target is in t.TempDir()
+			testutil.MustSucceed(t, err, "reading run report")
+		}
+		var report metrics.RunReport
+		testutil.MustSucceed(t, json.Unmarshal(data, &report), "decoding run report")
+		if report.RunID != p.RunID() || report.Totals.Included != 1 {
+			t.Errorf("report for %q = %+v, want run %s with one file included", target, report.Totals, p.RunID())
+		}
+		if len(report.Files) != 2 || report.Files[0].Path != "logo.bin" || report.Files[0].Reason != shared.SkipReasonBinary {
+			t.Errorf("report files = %+v, want logo.bin skipped as binary and main.go", report.Files)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+// Package cli provides command-line interface functionality for gibidify.
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// pathRestriction confines every file a run reads to the --restrict-to roots. Paths are
+// compared after resolving symlinks, so a link inside a root cannot reach a file outside it.
+// A nil restriction allows every path.
+type pathRestriction struct {
+	roots []string
+}
+
+// newPathRestriction resolves roots to absolute paths without symlinks. It returns nil
+// when no roots are given.
+func newPathRestriction(roots []string) (*pathRestriction, error) {
+	if len(roots) == 0 {
+		return nil, nil
+	}
+
+	r := &pathRestriction{roots: make([]string, 0, len(roots))}
+	for _, root := range roots {
+		resolved, err := resolvePath(root)
+		if err != nil {
+			return nil, shared.WrapError(
+				err, shared.ErrorTypeFileSystem, shared.CodeFSPathResolution, "failed to resolve --restrict-to root",
+			).WithFilePath(root)
+		}
+		r.roots = append(r.roots, resolved)
+	}
+
+	return r, nil
+}
+
+// resolvePath returns path as an absolute path with every symlink resolved.
+func resolvePath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("resolving absolute path: %w", err)
+	}
+	resolved, err := filepath.EvalSymlinks(abs)
+	if err != nil {
+		return "", fmt.Errorf("resolving symlinks: %w", err)
+	}
+
+	return resolved, nil
+}
+
+// check returns a security error when the kind file at path resolves outside every root.
+// A missing file is allowed, as there is nothing to read; any other path that cannot be
+// resolved is refused, as its target cannot be verified.
+func (r *pathRestriction) check(kind, path string) error {
+	if r == nil {
+		return nil
+	}
+
+	resolved, err := resolvePath(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err == nil {
+		for _, root := range r.roots {
+			if rel, relErr := filepath.Rel(root, resolved); relErr == nil && rel != ".." &&
+				!strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				return nil
+			}
+		}
+	}
+
+	return shared.NewStructuredError(
+		shared.ErrorTypeValidation, shared.CodeValidationRestrict,
+		fmt.Sprintf("%s %s resolves outside the --restrict-to directories", kind, path), path,
+		map[string]any{"roots": r.roots},
+	)
+}
+
+// loadRestriction sets up --restrict-to and checks the inputs named before collection: the
+// source directory, the config files, the prelude documents, the transforms.wasm modules, the
+// prompt template, the patch, the coverage and findings reports and the --sign key.
+func (p *Processor) loadRestriction() error {
+	restriction, err := newPathRestriction(p.flags.RestrictRoots())
+	if err != nil || restriction == nil {
+		return err
+	}
+	p.restriction = restriction
+
+	type input struct{ kind, path string }
+	inputs := []input{
+		{"source directory", p.flags.SourceDir},
+		{"config file", p.settings.FileUsed()},
+		{"project config file", p.settings.ProjectFileUsed()},
+		{"prompt template", p.flags.PromptTemplate},
+		{"patch", p.flags.FromPatch},
+		{"coverage report", p.flags.Coverage},
+		{"findings report", p.flags.Findings},
+		{"signing key", p.flags.Sign},
+	}
+	for _, path := range p.preludeFiles() {
+		inputs = append(inputs, input{"prelude", path})
+	}
+	for _, transform := range p.settings.TransformsWasm() {
+		inputs = append(inputs, input{"WASM module", transform.Module})
+	}
+	for _, in := range inputs {
+		if in.path == "" {
+			continue
+		}
+		if err := restriction.check(in.kind, in.path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkFiles returns a security error for the first collected file outside the roots.
+func (r *pathRestriction) checkFiles(files []string) error {
+	if r == nil {
+		return nil
+	}
+	for _, file := range files {
+		if err := r.check("file", file); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
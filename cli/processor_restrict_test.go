@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// TestProcessorRestrictTo tests that --restrict-to refuses inputs and symlinks resolving outside
+// its roots and allows links that stay inside them.
+func TestProcessorRestrictTo(t *testing.T) {
+	outside := t.TempDir()
+	secret := testutil.CreateTestFile(t, outside, "secret.txt", []byte("secret\n"))
+	template := testutil.CreateTestFile(t, outside, "p.tmpl", []byte("{{.Bundle}}"))
+
+	tests := []struct {
+		name    string
+		setup   func(t *testing.T, srcDir string) *Flags
+		config  map[string]any
+		wantErr string
+	}{
+		{
+			name:  "files inside the root",
+			setup: func(*testing.T, string) *Flags { return &Flags{} },
+		},
+		{
+			name: "symlink inside the root",
+			setup: func(t *testing.T, srcDir string) *Flags {
+				t.Helper()
+				testutil.MustSucceed(t, os.Symlink("main.go", filepath.Join(srcDir, "link.go")), "creating symlink")
+
+				return &Flags{}
+			},
+		},
+		{
+			name: "symlink out of the root",
+			setup: func(t *testing.T, srcDir string) *Flags {
+				t.Helper()
+				testutil.MustSucceed(t, os.Symlink(secret, filepath.Join(srcDir, "leak.txt")), "creating symlink")
+
+				return &Flags{}
+			},
+			wantErr: "leak.txt resolves outside",
+		},
+		{
+			name:    "prompt template out of the root",
+			setup:   func(*testing.T, string) *Flags { return &Flags{PromptTemplate: template} },
+			wantErr: "prompt template " + template,
+		},
+		{
+			name:    "signing key out of the root",
+			setup:   func(*testing.T, string) *Flags { return &Flags{Sign: secret} },
+			wantErr: "signing key " + secret,
+		},
+		{
+			name:    "codeowners path from config",
+			setup:   func(*testing.T, string) *Flags { return &Flags{} },
+			config:  map[string]any{shared.ConfigKeyCodeOwnersEnabled: true, shared.ConfigKeyCodeOwnersPath: secret},
+			wantErr: "CODEOWNERS file " + secret,
+		},
+		{
+			name:    "prelude from config",
+			setup:   func(*testing.T, string) *Flags { return &Flags{} },
+			config:  map[string]any{shared.ConfigKeyOutputPrelude: []string{secret}},
+			wantErr: "prelude " + secret,
+		},
+		{
+			name:  "WASM module from config",
+			setup: func(*testing.T, string) *Flags { return &Flags{} },
+			config: map[string]any{
+				shared.ConfigKeyTransformsWasm: []map[string]any{{"pattern": "*.go", "module": secret}},
+			},
+			wantErr: "WASM module " + secret,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys := map[string]any{shared.ConfigKeyCodeOwnersEnabled: false}
+			for key, value := range tt.config {
+				keys[key] = value
+			}
+			testutil.SetViperKeys(t, keys)
+			srcDir := t.TempDir()
+			testutil.CreateTestFile(t, srcDir, "main.go", []byte(shared.LiteralPackageMain))
+
+			flags := tt.setup(t, srcDir)
+			flags.SourceDir = srcDir
+			flags.Format = shared.FormatMarkdown
+			flags.Concurrency = 1
+			flags.NoUI = true
+			flags.RestrictTo = srcDir
+			var bundle bytes.Buffer
+			err := NewProcessor(WithFlags(flags), WithWriter(&bundle)).Process(t.Context())
+
+			if tt.wantErr == "" {
+				testutil.MustSucceed(t, err, "Process")
+
+				return
+			}
+			testutil.VerifyStructuredError(t, err, shared.ErrorTypeValidation, shared.CodeValidationRestrict)
+			if !strings.Contains(err.Error(), tt.wantErr) || strings.Contains(bundle.String(), "secret") {
+				t.Errorf("Process() = %v with bundle %q, want an error containing %q", err, bundle.String(), tt.wantErr)
+			}
+		})
+	}
+}
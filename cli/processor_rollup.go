@@ -0,0 +1,44 @@
+// Package cli provides command-line interface functionality for gibidify.
+package cli
+
+import (
+	"os"
+
+	"github.com/ivuorinen/gibidify/fileproc"
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// setupRollups creates the rollups of the collection.rollups globs, or leaves them nil when
+// none are configured.
+func (p *Processor) setupRollups() error {
+	absRoot, err := shared.AbsolutePath(p.flags.SourceDir)
+	if err != nil {
+		return shared.WrapError(err, shared.ErrorTypeFileSystem, shared.CodeFSPathResolution, "failed to resolve source").
+			WithFilePath(p.flags.SourceDir)
+	}
+	p.rollups = fileproc.NewRollups(absRoot, p.settings.CollectionRollups())
+
+	return nil
+}
+
+// applyRollups takes the files matching a rollup glob out of files and queues one entry per
+// rollup, listing its files, after the file sections.
+func (p *Processor) applyRollups(files []string) []string {
+	if p.rollups == nil {
+		return files
+	}
+
+	kept := make([]string, 0, len(files))
+	for _, file := range files {
+		var size int64
+		if info, err := os.Stat(file); err == nil {
+			size = info.Size()
+		}
+		if !p.rollups.Claim(file, size) {
+			kept = append(kept, file)
+		}
+	}
+	p.trailingEntries = append(p.trailingEntries, p.rollups.Entries()...)
+
+	return kept
+}
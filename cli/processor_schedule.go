@@ -0,0 +1,111 @@
+// Package cli provides command-line interface functionality for gibidify.
+package cli
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/signing"
+)
+
+// minEvery is the shortest --every interval; snapshot names are only unique to the second.
+const minEvery = time.Second
+
+// RunEvery bundles with flags now and then every --every interval until ctx is done or the
+// process is interrupted. A failing first run is returned, as it points at the setup; later
+// failures are reported and the schedule carries on.
+func RunEvery(ctx context.Context, flags *Flags) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	ui := NewUIManager()
+	ui.SetColorOutput(!flags.NoColors && !flags.NoUI)
+	ui.SetSilentMode(flags.NoUI)
+
+	ticker := time.NewTicker(flags.Every)
+	defer ticker.Stop()
+	for run := 1; ; run++ {
+		// Every run gets its own processor and flags, as processing fills in both
+		runFlags := *flags
+		p := NewProcessor(WithFlags(&runFlags))
+		err := p.Process(ctx)
+		switch {
+		case ctx.Err() != nil:
+			return nil
+		case err != nil && run == 1:
+			return err
+		case err != nil:
+			ui.PrintWarning("Scheduled run %d (%s) failed: %v", run, p.RunID(), err)
+		}
+		ui.PrintInfo("Next run at %s", time.Now().Add(flags.Every).Format(time.TimeOnly))
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// expandDestination replaces the timestamp placeholder in path with now in UTC.
+func expandDestination(path string, now time.Time) string {
+	return strings.ReplaceAll(
+		path, shared.DestinationTimestampPlaceholder, now.UTC().Format(shared.DestinationTimestampLayout),
+	)
+}
+
+// pruneSnapshots removes all but the newest --keep bundles written to the templated destination
+// file name. Only files whose name matches the template with a valid timestamp are considered.
+func (p *Processor) pruneSnapshots() error {
+	if p.flags.Keep <= 0 {
+		return nil
+	}
+
+	dir, name := filepath.Split(p.destinationTemplate)
+	prefix, suffix, _ := strings.Cut(name, shared.DestinationTimestampPlaceholder)
+	entries, err := os.ReadDir(filepath.Clean(dir))
+	if err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIORead, "failed to list bundle snapshots").
+			WithFilePath(dir)
+	}
+
+	var snapshots []string
+	for _, entry := range entries {
+		rest, hasPrefix := strings.CutPrefix(entry.Name(), prefix)
+		stamp, hasSuffix := strings.CutSuffix(rest, suffix)
+		if !hasPrefix || !hasSuffix || !entry.Type().IsRegular() {
+			continue
+		}
+		if _, err := time.Parse(shared.DestinationTimestampLayout, stamp); err == nil {
+			snapshots = append(snapshots, entry.Name())
+		}
+	}
+	if len(snapshots) <= p.flags.Keep {
+		return nil
+	}
+
+	// The timestamps sort by time, so the oldest snapshots come first
+	slices.Sort(snapshots)
+	for _, snapshot := range snapshots[:len(snapshots)-p.flags.Keep] {
+		path := filepath.Join(dir, snapshot)
+		if err := os.Remove(path); err != nil {
+			return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOFileWrite, "failed to remove bundle snapshot").
+				WithFilePath(path)
+		}
+		p.logger.Infof("Removed old bundle snapshot %s", path)
+		if err := os.Remove(path + signing.Extension); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOFileWrite, "failed to remove bundle signature").
+				WithFilePath(path + signing.Extension)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+func TestExpandDestination(t *testing.T) {
+	now := time.Date(2026, 3, 4, 5, 6, 7, 0, time.FixedZone("EET", 2*60*60))
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "out/app-{{timestamp}}.md", want: "out/app-20260304T030607Z.md"},
+		{path: "{{timestamp}}/app.md", want: "20260304T030607Z/app.md"},
+		{path: "out/app.md", want: "out/app.md"},
+	}
+
+	for _, tt := range tests {
+		if got := expandDestination(tt.path, now); got != tt.want {
+			t.Errorf("expandDestination(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+// TestProcessorPruneSnapshots tests that a run keeps the newest --keep snapshots and leaves files
+// that do not match the destination template alone.
+func TestProcessorPruneSnapshots(t *testing.T) {
+	testutil.SetViperKeys(t, map[string]any{shared.ConfigKeyCodeOwnersEnabled: false})
+	srcDir := t.TempDir()
+	testutil.CreateTestFile(t, srcDir, "main.go", []byte(shared.LiteralPackageMain))
+	outDir := t.TempDir()
+	for _, name := range []string{
+		"app-20240101T000000Z.md",
+		"app-20250101T000000Z.md",
+		"app-20250601T000000Z.md",
+		"app-latest.md",
+		"app-20200101T000000Z.json",
+		"notes.md",
+	} {
+		testutil.CreateTestFile(t, outDir, name, []byte("old"))
+	}
+
+	flags := &Flags{
+		SourceDir:   srcDir,
+		Destination: filepath.Join(outDir, "app-"+shared.DestinationTimestampPlaceholder+".md"),
+		Format:      shared.FormatMarkdown,
+		Concurrency: 1,
+		NoUI:        true,
+		Keep:        2,
+	}
+	defer testutil.SuppressAllOutput(t)()
+	testutil.MustSucceed(t, NewProcessor(WithFlags(flags)).Process(t.Context()), "Process")
+
+	entries, err := os.ReadDir(outDir)
+	testutil.MustSucceed(t, err, "reading output directory")
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	bundle := filepath.Base(flags.Destination)
+	want := []string{"app-20200101T000000Z.json", "app-20250601T000000Z.md", bundle, "app-latest.md", "notes.md"}
+	slices.Sort(want)
+	if !slices.Equal(names, want) {
+		t.Errorf("output directory = %v, want %v", names, want)
+	}
+}
+
+// TestRunEvery tests that a schedule runs at once, stops when its context is done, and returns
+// the error of a failing first run.
+func TestRunEvery(t *testing.T) {
+	testutil.SetViperKeys(t, map[string]any{shared.ConfigKeyCodeOwnersEnabled: false})
+	srcDir := t.TempDir()
+	testutil.CreateTestFile(t, srcDir, "main.go", []byte(shared.LiteralPackageMain))
+	outDir := t.TempDir()
+	defer testutil.SuppressAllOutput(t)()
+
+	flags := &Flags{
+		SourceDir:   srcDir,
+		Destination: filepath.Join(outDir, "app-"+shared.DestinationTimestampPlaceholder+".md"),
+		Format:      shared.FormatMarkdown,
+		Concurrency: 1,
+		NoUI:        true,
+		Every:       time.Hour,
+	}
+	ctx, cancel := context.WithTimeout(t.Context(), 2*time.Second)
+	defer cancel()
+	testutil.MustSucceed(t, RunEvery(ctx, flags), "RunEvery")
+
+	entries, err := os.ReadDir(outDir)
+	testutil.MustSucceed(t, err, "reading output directory")
+	if len(entries) != 1 {
+		t.Errorf("schedule wrote %d bundles before its context was done, want 1", len(entries))
+	}
+	if flags.Destination != filepath.Join(outDir, "app-"+shared.DestinationTimestampPlaceholder+".md") {
+		t.Errorf("RunEvery changed the destination flag to %q", flags.Destination)
+	}
+
+	flags.SourceDir = filepath.Join(srcDir, "missing")
+	if err := RunEvery(t.Context(), flags); err == nil {
+		t.Error("RunEvery with a failing first run succeeded, want an error")
+	}
+}
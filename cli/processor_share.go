@@ -0,0 +1,111 @@
+//go:build !minimal
+
+// Package cli provides command-line interface functionality for gibidify.
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/ivuorinen/gibidify/config"
+	"github.com/ivuorinen/gibidify/fileproc"
+	"github.com/ivuorinen/gibidify/github"
+	"github.com/ivuorinen/gibidify/share"
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// shareBundle uploads the finished bundle with --share once the user confirms it, and prints
+// the URL it was shared at.
+func (p *Processor) shareBundle(ctx context.Context) error {
+	if !p.flags.Share {
+		return nil
+	}
+	path := p.flags.Destination
+	if p.writer != nil || path == "" {
+		return shared.NewStructuredError(
+			shared.ErrorTypeCLI, shared.CodeCLIInvalidArgs, "--share needs a destination file", "", nil,
+		)
+	}
+
+	content, err := os.ReadFile(path) // #nosec G304 -- validated in flags.validate()
+	if err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIORead, "failed to read bundle").WithFilePath(path)
+	}
+	if maxSize := p.settings.ShareMaxSize(); int64(len(content)) > maxSize {
+		return shared.NewStructuredError(
+			shared.ErrorTypeValidation, shared.CodeValidationSize,
+			fmt.Sprintf("bundle is %d bytes, larger than share.maxSize (%d bytes)", len(content), maxSize),
+			path, nil,
+		)
+	}
+
+	uploader := newUploader(p.settings)
+	if p.policy != nil {
+		if err := p.handleViolations(p.policy.CheckShare(uploader.Host()), uploader.Host()); err != nil {
+			return err
+		}
+	}
+	if err := p.confirmShare(uploader, path, content); err != nil {
+		return err
+	}
+	url, err := uploader.Upload(ctx, filepath.Base(path), content)
+	if err != nil {
+		return err
+	}
+	p.printPreview("Shared %s at %s\n", path, url)
+
+	return nil
+}
+
+// newUploader creates the uploader of the share.backend of settings, with the token from
+// share.tokenEnv. Without one, gists get the GitHub token of the environment; share.url comes
+// from the user's own config, as project config files cannot set it.
+func newUploader(settings *config.Settings) share.Uploader {
+	token := ""
+	if name := settings.ShareTokenEnv(); name != "" {
+		token = strings.TrimSpace(os.Getenv(name))
+	}
+
+	if settings.ShareBackend() == shared.ShareBackendPaste {
+		return share.NewPaste(settings.ShareURL(), token)
+	}
+	if settings.ShareTokenEnv() == "" {
+		token = github.TokenFromEnv()
+	}
+
+	return share.NewGist(settings.ShareURL(), token, settings.SharePublic())
+}
+
+// confirmShare shows where the bundle at path goes and what the security scan finds in it, and
+// asks whether to upload it. Anything but yes cancels the upload.
+func (p *Processor) confirmShare(uploader share.Uploader, path string, content []byte) error {
+	p.printPreview("Sharing %s (%d bytes) uploads it to %s.\n", path, len(content), uploader.Describe())
+	findings := fileproc.ScanSecurity(content)
+	if len(findings) == 0 {
+		p.printPreview("Security scan: no potential secrets, suspicious URLs or private keys found\n")
+	} else {
+		p.printPreview("Security scan findings (review before sharing):\n")
+		for _, kind := range slices.Sorted(maps.Keys(findings)) {
+			p.printPreview("  %s: %d\n", kind, findings[kind])
+		}
+	}
+	p.printPreview("Upload %s? [y/N] ", path)
+
+	answer, err := bufio.NewReader(p.confirmIn).ReadString('\n')
+	if err != nil && answer == "" {
+		p.printPreview("\n")
+	}
+	if answer = strings.ToLower(strings.TrimSpace(answer)); answer == "y" || answer == "yes" {
+		return nil
+	}
+
+	return shared.NewStructuredError(
+		shared.ErrorTypeCLI, shared.CodeCLIShareDeclined, "upload declined, the bundle was not shared", path, nil,
+	)
+}
@@ -0,0 +1,21 @@
+//go:build minimal
+
+// Package cli provides command-line interface functionality for gibidify.
+package cli
+
+import (
+	"context"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// shareBundle fails with --share in the minimal build, which leaves network access out.
+func (p *Processor) shareBundle(_ context.Context) error {
+	if !p.flags.Share {
+		return nil
+	}
+
+	return shared.NewStructuredError(
+		shared.ErrorTypeCLI, shared.CodeCLIInvalidArgs, "built without --share support", "", nil,
+	)
+}
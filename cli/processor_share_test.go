@@ -0,0 +1,175 @@
+//go:build !minimal
+
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/config"
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// TestProcessorShare tests that --share uploads the bundle only once confirmed, after showing
+// its security scan, and refuses bundles larger than share.maxSize.
+func TestProcessorShare(t *testing.T) {
+	tests := []struct {
+		name       string
+		answer     string
+		maxSize    int64
+		wantCode   string
+		wantUpload bool
+	}{
+		{name: "confirmed", answer: "y\n", maxSize: shared.ConfigShareMaxSizeDefault, wantUpload: true},
+		{name: "declined", answer: "n\n", maxSize: shared.ConfigShareMaxSizeDefault, wantCode: shared.CodeCLIShareDeclined},
+		{name: "too large", answer: "y\n", maxSize: 8, wantCode: shared.CodeValidationSize},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer testutil.SuppressAllOutput(t)()
+			uploaded := ""
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				data, _ := io.ReadAll(r.Body)
+				uploaded = string(data)
+				w.Header().Set("Location", "/p/abc")
+				w.WriteHeader(http.StatusCreated)
+			}))
+			defer server.Close()
+			testutil.SetViperKeys(t, map[string]any{
+				shared.ConfigKeyCodeOwnersEnabled: false,
+				shared.ConfigKeyShareBackend:      shared.ShareBackendPaste,
+				shared.ConfigKeyShareURL:          server.URL,
+				shared.ConfigKeyShareMaxSize:      tt.maxSize,
+			})
+			srcDir := t.TempDir()
+			testutil.CreateTestFile(t, srcDir, "main.go", []byte(shared.LiteralPackageMain))
+
+			flags := &Flags{
+				SourceDir: srcDir, Destination: filepath.Join(t.TempDir(), "bundle.md"),
+				Format: shared.FormatMarkdown, Concurrency: 1, NoUI: true, Share: true,
+			}
+			var prompt bytes.Buffer
+			p := NewProcessor(WithFlags(flags))
+			p.confirmIn = strings.NewReader(tt.answer)
+			p.confirmOut = &prompt
+
+			err := p.Process(t.Context())
+			var structErr *shared.StructuredError
+			if tt.wantCode != "" && (!errors.As(err, &structErr) || structErr.Code != tt.wantCode) {
+				t.Errorf("Process error = %v, want code %s", err, tt.wantCode)
+			}
+			if tt.wantCode == "" {
+				testutil.MustSucceed(t, err, "Process")
+			}
+			if (uploaded != "") != tt.wantUpload || (tt.wantUpload && !strings.Contains(uploaded, "main.go")) {
+				t.Errorf("uploaded %q, want upload = %v", uploaded, tt.wantUpload)
+			}
+			if tt.wantUpload && !strings.Contains(prompt.String(), "at "+server.URL+"/p/abc") {
+				t.Errorf("prompt = %q, want the paste URL", prompt.String())
+			}
+			if tt.maxSize > 8 && !strings.Contains(prompt.String(), "Security scan: no potential secrets") {
+				t.Errorf("prompt = %q, want the security scan result", prompt.String())
+			}
+		})
+	}
+}
+
+// TestProcessorSharePolicy tests that --share refuses hosts the policy's allowedDestinations
+// leave out, unless the host is allowed or the override is audit-logged.
+func TestProcessorSharePolicy(t *testing.T) {
+	tests := []struct {
+		name       string
+		allowHost  bool
+		override   bool
+		wantUpload bool
+	}{
+		{name: "host not allowed"},
+		{name: "host allowed", allowHost: true, wantUpload: true},
+		{name: "override", override: true, wantUpload: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer testutil.SuppressAllOutput(t)()
+			uploaded := false
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				uploaded = true
+				w.Header().Set("Location", "/p/abc")
+				w.WriteHeader(http.StatusCreated)
+			}))
+			defer server.Close()
+			testutil.SetViperKeys(t, map[string]any{
+				shared.ConfigKeyCodeOwnersEnabled: false,
+				shared.ConfigKeyShareBackend:      shared.ShareBackendPaste,
+				shared.ConfigKeyShareURL:          server.URL,
+			})
+			destDir := t.TempDir()
+			allowed := "allowedDestinations:\n  - " + destDir + "\n"
+			if tt.allowHost {
+				allowed += "  - " + server.URL + "\n"
+			}
+			auditLog := filepath.Join(t.TempDir(), "audit.log")
+			policyFile := testutil.CreateTestFile(t, t.TempDir(), shared.PolicyFileName,
+				[]byte(allowed+"auditLog: "+auditLog+"\n"))
+			t.Setenv(shared.PolicyEnvVar, policyFile)
+			srcDir := t.TempDir()
+			testutil.CreateTestFile(t, srcDir, "main.go", []byte(shared.LiteralPackageMain))
+
+			flags := &Flags{
+				SourceDir: srcDir, Destination: filepath.Join(destDir, "bundle.md"), Format: shared.FormatMarkdown,
+				Concurrency: 1, NoUI: true, Share: true, PolicyOverride: tt.override,
+			}
+			p := NewProcessor(WithFlags(flags))
+			p.confirmIn = strings.NewReader("y\n")
+			p.confirmOut = io.Discard
+
+			err := p.Process(t.Context())
+			if tt.wantUpload {
+				testutil.MustSucceed(t, err, "Process")
+			} else {
+				testutil.VerifyStructuredError(t, err, shared.ErrorTypeValidation, shared.CodeValidationPolicy)
+			}
+			if uploaded != tt.wantUpload {
+				t.Errorf("uploaded = %v, want %v", uploaded, tt.wantUpload)
+			}
+			if _, err := os.Stat(auditLog); (err == nil) != tt.override {
+				t.Errorf("audit log written = %v, want %v", err == nil, tt.override)
+			}
+		})
+	}
+}
+
+// TestNewUploaderGistToken tests that a gist names the API host it goes to and gets the GitHub
+// token of the environment when the user's config names that API.
+func TestNewUploaderGistToken(t *testing.T) {
+	auth := ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = io.WriteString(w, `{"html_url": "https://gist.example/abc"}`)
+	}))
+	defer server.Close()
+	t.Setenv("GITHUB_TOKEN", "secret")
+	userConfig := testutil.CreateTestFile(t, t.TempDir(), "config.yaml", []byte("share:\n  url: "+server.URL+"\n"))
+	settings, err := config.LoadSettings(userConfig)
+	testutil.MustSucceed(t, err, "LoadSettings")
+
+	uploader := newUploader(settings)
+	if got := uploader.Describe(); !strings.HasSuffix(got, " at "+strings.TrimPrefix(server.URL, "http://")) {
+		t.Errorf("Describe() = %q, want the API host", got)
+	}
+	_, err = uploader.Upload(t.Context(), "bundle.md", []byte("# Bundle\n"))
+	testutil.MustSucceed(t, err, "Upload")
+	if auth != "Bearer secret" {
+		t.Errorf("Authorization = %q, want the GitHub token for the user's configured API", auth)
+	}
+}
@@ -0,0 +1,52 @@
+// Package cli provides command-line interface functionality for gibidify.
+package cli
+
+import (
+	"github.com/ivuorinen/gibidify/signing"
+)
+
+// loadSigningKey reads the --sign private key before any work is done, so an unusable key
+// fails the run early.
+func (p *Processor) loadSigningKey() error {
+	if p.flags.Sign == "" {
+		return nil
+	}
+
+	key, err := signing.LoadPrivateKey(p.flags.Sign)
+	if err != nil {
+		return err
+	}
+	p.signingKey = key
+
+	return nil
+}
+
+// signaturePath returns the file the signature of the bundle is written to: --signature, or
+// the destination with .sig appended.
+func (p *Processor) signaturePath() string {
+	if p.flags.Signature != "" {
+		return p.flags.Signature
+	}
+
+	return p.flags.Destination + signing.Extension
+}
+
+// signBundle writes the detached signature of the destination and, when it was written, the
+// --index file.
+func (p *Processor) signBundle(indexSaved bool) error {
+	if p.signingKey == nil {
+		return nil
+	}
+
+	files := []string{p.flags.Destination}
+	if indexSaved {
+		files = append(files, p.flags.Index)
+	}
+	sigPath := p.signaturePath()
+	if err := signing.Sign(p.signingKey, sigPath, files); err != nil {
+		return err
+	}
+	p.ui.PrintInfo("Signature saved to %s", sigPath)
+
+	return nil
+}
@@ -0,0 +1,59 @@
+// Package cli provides command-line interface functionality for gibidify.
+package cli
+
+import (
+	"os"
+	"strings"
+
+	"github.com/ivuorinen/gibidify/fileproc"
+)
+
+// splitting reports whether --split-size writes the bundle as parts. Bundles written to the
+// writer set with WithWriter are never split.
+func (p *Processor) splitting() bool {
+	return p.flags.SplitSize > 0 && p.writer == nil
+}
+
+// outputPath returns the file the bundle is written to: the destination, or its first part
+// with --split-size.
+func (p *Processor) outputPath() string {
+	if p.splitting() {
+		return fileproc.SplitPartPath(p.flags.Destination, 1)
+	}
+
+	return p.flags.Destination
+}
+
+// splitOptions returns the writer options splitting the bundle with --split-size, or nil, and
+// resets the parts recorded by a previous run.
+func (p *Processor) splitOptions() *fileproc.SplitOptions {
+	p.splitParts = nil
+	if !p.splitting() {
+		return nil
+	}
+
+	return &fileproc.SplitOptions{
+		Destination: p.flags.Destination,
+		Limit:       p.flags.SplitSize,
+		Tokens:      p.flags.SplitTokens,
+		Sync:        p.flags.Fsync,
+		Parts:       &p.splitParts,
+	}
+}
+
+// finishSplit removes the parts a previous, longer split of the destination left behind and
+// lists the parts written.
+func (p *Processor) finishSplit() {
+	if len(p.splitParts) == 0 {
+		return
+	}
+
+	for n := len(p.splitParts) + 1; ; n++ {
+		stale := fileproc.SplitPartPath(p.flags.Destination, n)
+		if err := os.Remove(stale); err != nil {
+			break
+		}
+		p.logger.Debugf("Removed stale bundle part %s", stale)
+	}
+	p.ui.PrintInfo("Bundle split into %d parts: %s", len(p.splitParts), strings.Join(p.splitParts, ", "))
+}
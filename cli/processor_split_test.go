@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/fileproc"
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// TestProcessSplitsBundle tests that --split-size writes the bundle as parts next to the
+// destination and removes the parts a previous, longer split left behind.
+func TestProcessSplitsBundle(t *testing.T) {
+	srcDir := t.TempDir()
+	outDir := t.TempDir()
+	for _, name := range []string{"a.go", "b.go", "c.go"} {
+		testutil.CreateTestFile(t, srcDir, name, []byte(shared.LiteralPackageMain+strings.Repeat("// x\n", 200)))
+	}
+	testutil.SetViperKeys(t, map[string]any{shared.ConfigKeyCodeOwnersEnabled: false})
+	defer testutil.SuppressLogs(t)()
+
+	dest := filepath.Join(outDir, "bundle.md")
+	stale := testutil.CreateTestFile(t, outDir, "bundle.part4.md", []byte("stale"))
+	p := NewProcessor(WithFlags(&Flags{
+		SourceDir: srcDir, Destination: dest, Format: shared.FormatMarkdown, Concurrency: 1, NoUI: true,
+		SplitSize: 1500,
+	}))
+	testutil.MustSucceed(t, p.Process(t.Context()), "Process")
+
+	if len(p.splitParts) != 3 {
+		t.Fatalf("split into %v, want 3 parts", p.splitParts)
+	}
+	for n, name := range []string{"a.go", "b.go", "c.go"} {
+		part := fileproc.SplitPartPath(dest, n+1)
+		data, err := os.ReadFile(part) // #nosec G304 -- part is in t.TempDir()
+		testutil.MustSucceed(t, err, "reading part")
+		if !strings.Contains(string(data), name) {
+			t.Errorf("part %d does not hold %s:\n%s", n+1, name, data)
+		}
+	}
+	if _, err := os.Stat(dest); !os.IsNotExist(err) {
+		t.Errorf("destination %s was written, want only its parts", dest)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("stale part %s was kept", stale)
+	}
+}
@@ -2,9 +2,11 @@
 package cli
 
 import (
+	"maps"
+	"slices"
 	"strings"
 
-	"github.com/ivuorinen/gibidify/config"
+	"github.com/ivuorinen/gibidify/fileproc"
 	"github.com/ivuorinen/gibidify/shared"
 )
 
@@ -14,6 +16,8 @@ func (p *Processor) logFinalStats() {
 	p.logResourceStats()
 	p.finalizeAndReportMetrics()
 	p.logVerboseStats()
+	p.logBufferPoolStats()
+	p.logPrefetchStats()
 	if p.resourceMonitor != nil {
 		p.resourceMonitor.Close()
 	}
@@ -26,7 +30,7 @@ func (p *Processor) logBackpressureStats() {
 		return
 	}
 
-	logger := shared.GetLogger()
+	logger := p.logger
 	backpressureStats := p.backpressure.Stats()
 	if backpressureStats.Enabled {
 		logger.Infof(
@@ -38,10 +42,25 @@ func (p *Processor) logBackpressureStats() {
 	}
 }
 
-// logResourceStats logs resource monitoring statistics.
+// completeRunSummary adds the files left out of the bundle, by reason, to the run summary.
+func (p *Processor) completeRunSummary(summary *fileproc.RunSummary) {
+	if p.resourceMonitor == nil {
+		return
+	}
+
+	for reason, skipped := range p.resourceMonitor.Metrics().Skipped {
+		if summary.Skipped == nil {
+			summary.Skipped = make(map[string]int64)
+		}
+		summary.Skipped[reason] = skipped.Files
+	}
+}
+
+// logResourceStats logs resource monitoring statistics and feeds the files left out of the
+// bundle, by reason, to the run report.
 func (p *Processor) logResourceStats() {
 	// Check resource monitoring is enabled and monitor is non-nil before dereferencing
-	if !config.ResourceLimitsEnabled() {
+	if !p.settings.ResourceLimitsEnabled() {
 		return
 	}
 
@@ -49,7 +68,7 @@ func (p *Processor) logResourceStats() {
 		return
 	}
 
-	logger := shared.GetLogger()
+	logger := p.logger
 	resourceStats := p.resourceMonitor.Metrics()
 
 	logger.Infof(
@@ -58,8 +77,21 @@ func (p *Processor) logResourceStats() {
 		resourceStats.AverageFileSize/float64(shared.BytesPerKB), resourceStats.ProcessingRate,
 	)
 
+	for _, reason := range slices.Sorted(maps.Keys(resourceStats.Skipped)) {
+		skipped := resourceStats.Skipped[reason]
+		logger.Infof("Resource stats: skipped %s=%d files (%dKB)", reason, skipped.Files, skipped.Bytes/shared.BytesPerKB)
+		if p.metricsCollector != nil {
+			p.metricsCollector.RecordSkipped(reason, skipped.Files, skipped.Bytes)
+		}
+	}
+
 	if len(resourceStats.ViolationsDetected) > 0 {
 		logger.Warnf("Resource violations detected: %v", resourceStats.ViolationsDetected)
+		for _, violation := range resourceStats.ViolationsDetected {
+			if p.metricsCollector != nil {
+				p.metricsCollector.RecordLimitHit(violation)
+			}
+		}
 	}
 
 	if resourceStats.DegradationActive {
@@ -71,6 +103,29 @@ func (p *Processor) logResourceStats() {
 	}
 }
 
+// logBufferPoolStats logs at debug level how well the shared buffer pools reused their buffers.
+func (p *Processor) logBufferPoolStats() {
+	logger := p.logger
+	for _, stats := range shared.PoolStats() {
+		logger.Debugf(
+			"Buffer pool stats: pool=%s, gets=%d, allocs=%d, reuse=%.1f%%, puts=%d, dropped=%d",
+			stats.Name, stats.Gets, stats.Allocs, stats.ReuseRate(), stats.Puts, stats.Dropped,
+		)
+	}
+}
+
+// logPrefetchStats logs at debug level how many files were read ahead of the workers.
+func (p *Processor) logPrefetchStats() {
+	if p.prefetch == nil {
+		return
+	}
+	stats := p.prefetch.Stats()
+	p.logger.Debugf(
+		"Prefetch stats: files=%d, bytes=%d, hits=%d, misses=%d",
+		stats.Files, stats.Bytes, stats.Hits, stats.Misses,
+	)
+}
+
 // finalizeAndReportMetrics finalizes metrics collection and displays the final report.
 func (p *Processor) finalizeAndReportMetrics() {
 	if p.metricsCollector != nil {
@@ -84,6 +139,16 @@ func (p *Processor) finalizeAndReportMetrics() {
 			p.ui.PrintInfo("%s", strings.TrimSuffix(finalReport, "\n"))
 		}
 	}
+
+	// The verbose report already includes the timing table
+	if p.flags.Timings && !p.flags.Verbose && p.metricsReporter != nil && p.ui != nil {
+		p.ui.PrintInfo("%s", strings.TrimSuffix(p.metricsReporter.ReportTimings(), "\n"))
+	}
+	if p.flags.Hotspots > 0 && p.metricsReporter != nil && p.ui != nil {
+		if hotspots := p.metricsReporter.ReportHotspots(p.flags.Hotspots); hotspots != "" {
+			p.ui.PrintInfo("%s", strings.TrimSuffix(hotspots, "\n"))
+		}
+	}
 }
 
 // logVerboseStats logs detailed structured statistics when verbose mode is enabled.
@@ -92,7 +157,7 @@ func (p *Processor) logVerboseStats() {
 		return
 	}
 
-	logger := shared.GetLogger()
+	logger := p.logger
 	report := p.metricsCollector.GenerateReport()
 	fields := map[string]any{
 		"total_files":      report.Summary.TotalFiles,
@@ -103,6 +168,12 @@ func (p *Processor) logVerboseStats() {
 		"files_per_second": report.Summary.FilesPerSecond,
 		"bytes_per_second": report.Summary.BytesPerSecond,
 		"memory_usage_mb":  report.Summary.CurrentMemoryMB,
+		"output_writes":    report.Summary.OutputWrites,
+		"cache_hits":       report.Summary.CacheHits,
+		"cache_misses":     report.Summary.CacheMisses,
+		"gomaxprocs":       report.Summary.GOMAXPROCS,
+		"host_cpus":        report.Summary.HostCPUs,
+		"workers":          report.Summary.Workers,
 	}
 	logger.WithFields(fields).Info("Processing completed with comprehensive metrics")
 }
@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ivuorinen/gibidify/fileproc"
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// Processor stress test sizes, kept small enough to finish quickly under -race.
+const (
+	stressFiles   = 200
+	stressRounds  = 12
+	stressWorkers = 8
+	// stressProcs is the minimum GOMAXPROCS the stress tests run with, so workers run in
+	// parallel threads and interleave at arbitrary points even on a single CPU.
+	stressProcs = 4
+)
+
+// createStressTree creates stressFiles small files, and one file large enough to be streamed,
+// spread over nested directories.
+func createStressTree(t *testing.T) string {
+	t.Helper()
+
+	root := t.TempDir()
+	for i := range stressFiles {
+		dir := filepath.Join(root, fmt.Sprintf("pkg%d", i%7), fmt.Sprintf("sub%d", i%3))
+		testutil.MustSucceed(t, os.MkdirAll(dir, shared.TestDirPermission), "creating directory")
+		testutil.CreateTestFile(t, dir, fmt.Sprintf("file%03d.go", i), fmt.Appendf(nil, "package p\n\n// %d\n", i))
+	}
+	large := strings.Repeat("streamed line of text\n", shared.FileProcessingStreamThreshold/20)
+	testutil.CreateTestFile(t, root, "large.txt", []byte(large))
+
+	return root
+}
+
+// TestProcessorStress bundles a tree of many files through channels of two slots with many
+// workers, cancelling the run at a random point in every round but the last, which must
+// bundle every file.
+func TestProcessorStress(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(max(runtime.GOMAXPROCS(0), stressProcs)))
+	defer testutil.SuppressAllOutput(t)()
+	testutil.SetViperKeys(t, map[string]any{
+		shared.ConfigKeyBackpressureEnabled:              true,
+		shared.ConfigKeyBackpressureMaxPendingFiles:      2,
+		shared.ConfigKeyBackpressureMaxPendingWrites:     2,
+		shared.ConfigKeyResourceLimitsMaxConcurrentReads: 3,
+		shared.ConfigKeyPerformanceFormatWorkers:         4,
+		shared.ConfigKeyCodeOwnersEnabled:                false,
+	})
+	source := createStressTree(t)
+	goroutines := runtime.NumGoroutine()
+
+	for round := range stressRounds {
+		destination := filepath.Join(t.TempDir(), "stress.json")
+		ctx, cancel := context.WithCancel(t.Context())
+		if round < stressRounds-1 {
+			delay := time.Duration(rand.IntN(30)) * time.Millisecond // #nosec G404 -- test randomness
+			time.AfterFunc(delay, cancel)
+		}
+
+		p := NewProcessor(WithFlags(&Flags{
+			SourceDir:   source,
+			Destination: destination,
+			Format:      shared.FormatJSON,
+			Concurrency: stressWorkers,
+			NoUI:        true,
+		}))
+		err := p.Process(ctx)
+		canceled := ctx.Err() != nil
+		cancel()
+
+		if !canceled {
+			testutil.MustSucceed(t, err, "uncanceled stress round")
+			verifyStressBundle(t, destination)
+		}
+	}
+
+	// Canceled runs must not leave workers, writers or rate limiters behind.
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > goroutines && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if leaked := runtime.NumGoroutine() - goroutines; leaked > 0 {
+		t.Errorf("%d goroutines still running after the stress rounds", leaked)
+	}
+}
+
+// verifyStressBundle checks that the bundle at destination holds every file of the stress tree once.
+func verifyStressBundle(t *testing.T, destination string) {
+	t.Helper()
+
+	data, err := os.ReadFile(destination) // #nosec G304 -- destination is in t.TempDir()
+	testutil.MustSucceed(t, err, "reading bundle")
+	var bundle fileproc.OutputData
+	testutil.MustSucceed(t, json.Unmarshal(data, &bundle), "decoding bundle")
+
+	seen := make(map[string]bool, len(bundle.Files))
+	for _, file := range bundle.Files {
+		if seen[file.Path] {
+			t.Errorf("%s bundled twice", file.Path)
+		}
+		seen[file.Path] = true
+	}
+	if len(seen) != stressFiles+1 {
+		t.Errorf("bundled %d distinct files, want %d", len(seen), stressFiles+1)
+	}
+}
@@ -1,7 +1,9 @@
 package cli
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -66,7 +68,7 @@ func TestNewProcessor(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(
 			tt.name, func(t *testing.T) {
-				processor := NewProcessor(tt.flags)
+				processor := NewProcessor(WithFlags(tt.flags))
 
 				validateProcessor(t, processor, tt.want)
 				validateProcessorFlags(t, processor, tt.flags)
@@ -108,15 +110,18 @@ func verifyDefaultExtensions(t *testing.T, registry *fileproc.FileTypeRegistry)
 	}
 }
 
-// verifyCustomExtensions checks that custom extensions are recognized when expected.
+// verifyCustomExtensions checks that custom extensions are recognized exactly when expected.
 func verifyCustomExtensions(t *testing.T, registry *fileproc.FileTypeRegistry, tt configureFileTypesTestCase) {
 	t.Helper()
-	if !tt.wantCustom || len(tt.customExtensions) == 0 {
+	if len(tt.customExtensions) == 0 {
 		return
 	}
 	testFile := "test" + tt.customExtensions[0]
-	if !registry.IsImage(testFile) {
-		t.Errorf("expected %s to be recognized as image (custom extension)", testFile)
+	if got := registry.IsImage(testFile); got != tt.wantCustom {
+		t.Errorf("IsImage(%s) = %v, want %v", testFile, got, tt.wantCustom)
+	}
+	if fileproc.DefaultRegistry().IsImage(testFile) { //nolint:staticcheck // checking the default is untouched
+		t.Errorf("configuring a processor changed the default registry: %s is an image", testFile)
 	}
 }
 
@@ -156,7 +161,7 @@ func TestProcessorConfigureFileTypes(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			setupFileTypesConfig(t, tt)
 
-			if got := config.FileTypesEnabled(); got != tt.fileTypesEnabled {
+			if got := config.Global().FileTypesEnabled(); got != tt.fileTypesEnabled {
 				t.Errorf("FileTypesEnabled() = %v, want %v", got, tt.fileTypesEnabled)
 			}
 
@@ -166,10 +171,7 @@ func TestProcessorConfigureFileTypes(t *testing.T) {
 				Concurrency: 1,
 				Destination: shared.TestOutputMarkdown,
 			}
-			processor := NewProcessor(flags)
-			processor.configureFileTypes()
-
-			registry := fileproc.DefaultRegistry()
+			registry := NewProcessor(WithFlags(flags)).registry
 			verifyDefaultExtensions(t, registry)
 			verifyCustomExtensions(t, registry, tt)
 			verifyRegistryState(t, registry)
@@ -281,7 +283,7 @@ func TestProcessorCollectFiles(t *testing.T) {
 					Destination: filepath.Join(t.TempDir(), shared.TestOutputMD),
 				}
 
-				processor := NewProcessor(flags)
+				processor := NewProcessor(WithFlags(flags))
 				files, err := processor.collectFiles()
 				validateCollectFiles(t, files, err, tt.wantCount, tt.wantErr, tt.errContains)
 			},
@@ -388,7 +390,7 @@ func TestProcessorvalidateFileCollection(t *testing.T) {
 					Destination: filepath.Join(t.TempDir(), shared.TestOutputMD),
 				}
 
-				processor := NewProcessor(flags)
+				processor := NewProcessor(WithFlags(flags))
 				err := processor.validateFileCollection(testFiles)
 				validateFileCollectionResult(t, err, tt.wantErr, tt.errContains)
 			},
@@ -463,7 +465,7 @@ func TestProcessorcreateOutputFile(t *testing.T) {
 					Destination: tt.setupDest(),
 				}
 
-				processor := NewProcessor(flags)
+				processor := NewProcessor(WithFlags(flags))
 				outFile, err := processor.createOutputFile()
 				validateOutputFile(t, outFile, err, tt.wantErr, tt.errContains)
 			},
@@ -490,7 +492,7 @@ func runProcessorIntegrationTest(
 		NoUI:        true, // Disable all UI output for testing
 	}
 
-	processor := NewProcessor(flags)
+	processor := NewProcessor(WithFlags(flags))
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
@@ -639,7 +641,7 @@ func TestProcessorProcessContextCancellation(t *testing.T) {
 		NoUI:        true, // Disable all UI output for testing
 	}
 
-	processor := NewProcessor(flags)
+	processor := NewProcessor(WithFlags(flags))
 
 	// Create context that will be canceled immediately
 	ctx, cancel := context.WithCancel(context.Background())
@@ -668,6 +670,7 @@ func TestProcessorProcessResourceLimits(t *testing.T) {
 		name        string
 		setupConfig func()
 		setupFiles  func(dir string) []string
+		limits      fileproc.ResourceLimits
 		wantErr     bool
 		errContains string
 	}{
@@ -685,6 +688,23 @@ func TestProcessorProcessResourceLimits(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "run limits while resource limits are disabled",
+			setupConfig: func() {
+				viper.Set(shared.ConfigKeyResourceLimitsEnabled, false)
+			},
+			setupFiles: func(dir string) []string {
+				files := []testutil.FileSpec{
+					{Name: "a.txt", Content: "a\n"},
+					{Name: "b.txt", Content: "b\n"},
+				}
+
+				return testutil.CreateTestFiles(t, dir, files)
+			},
+			limits:      fileproc.ResourceLimits{MaxFiles: 1},
+			wantErr:     true,
+			errContains: "file count",
+		},
 	}
 
 	for _, tt := range tests {
@@ -708,7 +728,7 @@ func TestProcessorProcessResourceLimits(t *testing.T) {
 					NoUI:        true, // Disable all UI output for testing
 				}
 
-				processor := NewProcessor(flags)
+				processor := NewProcessor(WithFlags(flags), WithResourceLimits(tt.limits))
 				ctx := context.Background()
 
 				err := processor.Process(ctx)
@@ -762,7 +782,7 @@ func createLogStatsProcessor(t *testing.T) *Processor {
 		NoColors:    true,
 		NoProgress:  true,
 	}
-	return NewProcessor(flags)
+	return NewProcessor(WithFlags(flags))
 }
 
 // simulateProcessing records file processing activity for stats generation.
@@ -772,6 +792,7 @@ func simulateProcessing(processor *Processor, simulate bool) {
 	}
 	processor.resourceMonitor.RecordFileProcessed(1024)
 	processor.resourceMonitor.RecordFileProcessed(2048)
+	processor.resourceMonitor.RecordFileSkipped(shared.SkipReasonBinary, 4096)
 }
 
 // verifyLogKeywords checks expected and unexpected keywords in output.
@@ -813,7 +834,7 @@ func TestProcessorLogFinalStats(t *testing.T) {
 			enableBackpressure:   false,
 			enableResourceLimits: true,
 			simulateProcessing:   true,
-			expectedKeywords:     []string{"Resource stats", "processed", "files"},
+			expectedKeywords:     []string{"Resource stats", "processed", "files", "skipped binary=1 files (4KB)"},
 			unexpectedKeywords:   []string{},
 		},
 		{
@@ -845,6 +866,94 @@ func TestProcessorLogFinalStats(t *testing.T) {
 	}
 }
 
+// TestProcessorAppendRunSummary tests that output.appendRunSummary ends the bundle with the files
+// written and the files left out.
+func TestProcessorAppendRunSummary(t *testing.T) {
+	defer testutil.SuppressAllOutput(t)()
+	testutil.SetViperKeys(t, map[string]any{
+		shared.ConfigKeyOutputAppendRunSummary: true,
+		shared.ConfigKeyResourceLimitsEnabled:  true,
+		shared.ConfigKeyCodeOwnersEnabled:      false,
+	})
+	srcDir := t.TempDir()
+	testutil.CreateTestFile(t, srcDir, "main.go", []byte(shared.LiteralPackageMain))
+	testutil.CreateTestFile(t, srcDir, "tool.exe", []byte("binary"))
+
+	var bundle bytes.Buffer
+	p := NewProcessor(WithSource(srcDir), WithWriter(&bundle))
+	testutil.MustSucceed(t, p.Process(t.Context()), "Process")
+
+	var output fileproc.OutputData
+	testutil.MustSucceed(t, json.Unmarshal(bundle.Bytes(), &output), "decoding bundle")
+	summary := output.Summary
+	if summary == nil || summary.Files != 1 || summary.Languages["go"] != 1 ||
+		summary.Skipped[shared.SkipReasonBinary] != 1 {
+		t.Errorf("summary = %+v, want main.go bundled and tool.exe left out as binary", summary)
+	}
+}
+
+// TestProcessorConfigProvenance tests that output.configProvenance records the non-default
+// settings at the start of the bundle.
+func TestProcessorConfigProvenance(t *testing.T) {
+	defer testutil.SuppressAllOutput(t)()
+	testutil.SetViperKeys(t, map[string]any{
+		shared.ConfigKeyOutputConfigProvenance: true,
+		shared.ConfigKeyOutputFileIDs:          true,
+		shared.ConfigKeyCodeOwnersEnabled:      false,
+	})
+	srcDir := t.TempDir()
+	testutil.CreateTestFile(t, srcDir, "main.go", []byte(shared.LiteralPackageMain))
+
+	var bundle bytes.Buffer
+	p := NewProcessor(WithSource(srcDir), WithWriter(&bundle))
+	testutil.MustSucceed(t, p.Process(t.Context()), "Process")
+
+	var output fileproc.OutputData
+	testutil.MustSucceed(t, json.Unmarshal(bundle.Bytes(), &output), "decoding bundle")
+	if output.Config["output.fileids"] != true || output.Config["output.configprovenance"] != true ||
+		output.Config["codeowners.enabled"] != false {
+		t.Errorf("config = %v, want the three non-default settings", output.Config)
+	}
+	if _, ok := output.Config["filesizelimit"]; ok {
+		t.Errorf("config = %v, recording the default fileSizeLimit", output.Config)
+	}
+}
+
+func TestMonitorSkipReason(t *testing.T) {
+	structured := func(code string) error {
+		return shared.NewStructuredError(shared.ErrorTypeValidation, code, "skipped", "file.go", nil)
+	}
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "processed", err: nil, want: ""},
+		{name: "generated", err: structured(shared.CodeValidationGenerated), want: shared.SkipReasonGenerated},
+		{
+			name: "documentation language",
+			err:  structured(shared.CodeValidationDocLanguage),
+			want: shared.SkipReasonDocLanguage,
+		},
+		{name: "file too large", err: structured(shared.CodeValidationSize), want: shared.SkipReasonSizeLimit},
+		{
+			name: "total size limit",
+			err:  fmt.Errorf("wrapped: %w", structured(shared.CodeResourceLimitTotalSize)),
+			want: shared.SkipReasonSizeLimit,
+		},
+		{name: "other structured error", err: structured(shared.CodeIOFileWrite), want: shared.SkipReasonError},
+		{name: "plain error", err: os.ErrPermission, want: shared.SkipReasonError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := monitorSkipReason(tt.err); got != tt.want {
+				t.Errorf("monitorSkipReason() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 // Helper types and functions
 
 type processorValidation struct {
@@ -939,7 +1048,7 @@ func BenchmarkProcessorNewProcessor(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		processor := NewProcessor(flags)
+		processor := NewProcessor(WithFlags(flags))
 		_ = processor
 	}
 }
@@ -975,7 +1084,7 @@ func BenchmarkProcessorCollectFiles(b *testing.B) {
 			Destination: filepath.Join(outDir, shared.TestOutputMD),
 		}
 
-		processor := NewProcessor(flags)
+		processor := NewProcessor(WithFlags(flags))
 		files, err := processor.collectFiles()
 		if err != nil {
 			b.Fatalf("collectFiles failed: %v", err)
@@ -1019,7 +1128,61 @@ func BenchmarkProcessorProcess(b *testing.B) {
 			LogLevel:    "warn",
 		}
 
-		processor := NewProcessor(flags)
+		processor := NewProcessor(WithFlags(flags))
 		_ = processor.Process(context.Background())
 	}
 }
+
+// TestProcessorPreviewDiff tests that --preview-diff lists the changes against the existing
+// destination and only overwrites it when confirmed.
+func TestProcessorPreviewDiff(t *testing.T) {
+	tests := []struct {
+		name          string
+		answer        string
+		wantErr       bool
+		wantOverwrite bool
+	}{
+		{name: "confirmed", answer: "y\n", wantOverwrite: true},
+		{name: "declined", answer: "n\n", wantErr: true},
+		{name: "no answer", answer: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer testutil.SuppressAllOutput(t)()
+			testutil.SetViperKeys(t, map[string]any{shared.ConfigKeyCodeOwnersEnabled: false})
+			srcDir := t.TempDir()
+			testutil.CreateTestFile(t, srcDir, "main.go", []byte(shared.LiteralPackageMain))
+			previous := `{"prefix":"","files":[{"path":"old.go","content":"package old"}],"suffix":""}`
+			destination := testutil.CreateTestFile(t, t.TempDir(), "bundle.json", []byte(previous))
+
+			flags := &Flags{
+				SourceDir: srcDir, Destination: destination, Format: shared.FormatJSON,
+				Concurrency: 1, NoUI: true, PreviewDiff: true,
+			}
+			var preview bytes.Buffer
+			p := NewProcessor(WithFlags(flags))
+			p.confirmIn = strings.NewReader(tt.answer)
+			p.confirmOut = &preview
+
+			err := p.Process(t.Context())
+			if tt.wantErr && !IsUserError(err) {
+				t.Errorf("Process error = %v, want the overwrite declined", err)
+			}
+			if !tt.wantErr {
+				testutil.MustSucceed(t, err, "Process")
+			}
+			for _, want := range []string{"+ main.go", "- old.go", "1 added, 1 removed, 0 changed", "Overwrite "} {
+				if !strings.Contains(preview.String(), want) {
+					t.Errorf("preview = %q, want it to contain %q", preview.String(), want)
+				}
+			}
+
+			data, readErr := os.ReadFile(destination) // #nosec G304 -- destination is in t.TempDir()
+			testutil.MustSucceed(t, readErr, "reading destination")
+			if overwritten := string(data) != previous; overwritten != tt.wantOverwrite {
+				t.Errorf("destination overwritten = %v, want %v:\n%s", overwritten, tt.wantOverwrite, data)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+// Package cli provides command-line interface functionality for gibidify.
+package cli
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/ivuorinen/gibidify/fileproc"
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// applyTestFiles registers an annotator linking test files and the files they test in both
+// directions, and keeps, drops or keeps only the test files as --tests asks. Links are made
+// before filtering, so they also name paired files that are left out.
+func (p *Processor) applyTestFiles(files []string) ([]string, error) {
+	absRoot, err := shared.AbsolutePath(p.flags.SourceDir)
+	if err != nil {
+		return nil, shared.WrapError(err, shared.ErrorTypeFileSystem, shared.CodeFSPathResolution, "resolving source")
+	}
+
+	pairs := fileproc.PairTestFiles(files)
+	testsOf := make(map[string][]string)
+	for test, subject := range pairs {
+		testsOf[subject] = append(testsOf[subject], test)
+	}
+	if len(pairs) > 0 {
+		p.annotators = append(p.annotators, testPairAnnotator(absRoot, pairs, testsOf))
+	}
+
+	if p.flags.Tests == "" || p.flags.Tests == shared.TestsInclude {
+		return files, nil
+	}
+
+	only := p.flags.Tests == shared.TestsOnly
+	kept := make([]string, 0, len(files))
+	for _, file := range files {
+		if fileproc.IsTestFile(file) == only {
+			kept = append(kept, file)
+		}
+	}
+	p.logger.Infof("Test file filter %q kept %d of %d files", p.flags.Tests, len(kept), len(files))
+
+	return kept, nil
+}
+
+// testPairAnnotator returns an annotator adding the subject of every test file in pairs, and
+// the tests of every file in testsOf, as paths relative to root.
+func testPairAnnotator(root string, pairs map[string]string, testsOf map[string][]string) fileproc.Annotator {
+	relative := func(path string) string {
+		if rel, err := filepath.Rel(root, path); err == nil {
+			return rel
+		}
+
+		return path
+	}
+
+	return func(filePath, _ string) map[string]string {
+		meta := make(map[string]string)
+		if subject, ok := pairs[filePath]; ok {
+			meta[shared.MetadataKeyTestOf] = relative(subject)
+		}
+		if tests := testsOf[filePath]; len(tests) > 0 {
+			rels := make([]string, 0, len(tests))
+			for _, test := range tests {
+				rels = append(rels, relative(test))
+			}
+			slices.Sort(rels)
+			meta[shared.MetadataKeyTestedBy] = strings.Join(rels, " ")
+		}
+
+		return meta
+	}
+}
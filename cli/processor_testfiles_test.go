@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// TestApplyTestFiles tests --tests filtering and the links between test files and subjects.
+func TestApplyTestFiles(t *testing.T) {
+	dir := t.TempDir()
+	subject := filepath.Join(dir, "walker.go")
+	test := filepath.Join(dir, "walker_test.go")
+	other := filepath.Join(dir, "main.go")
+	files := []string{other, subject, test}
+	defer testutil.SuppressLogs(t)()
+
+	tests := []struct {
+		mode string
+		want []string
+	}{
+		{mode: "", want: files},
+		{mode: shared.TestsInclude, want: files},
+		{mode: shared.TestsExclude, want: []string{other, subject}},
+		{mode: shared.TestsOnly, want: []string{test}},
+	}
+
+	for _, tt := range tests {
+		t.Run("tests="+tt.mode, func(t *testing.T) {
+			p := NewProcessor(WithFlags(&Flags{SourceDir: dir, Tests: tt.mode}))
+			got, err := p.applyTestFiles(files)
+			testutil.MustSucceed(t, err, "applyTestFiles")
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("applyTestFiles() = %v, want %v", got, tt.want)
+			}
+			if len(p.annotators) != 1 {
+				t.Fatalf("expected test pair annotator to be registered, got %d", len(p.annotators))
+			}
+			if meta := p.annotators[0](test, "walker_test.go"); meta[shared.MetadataKeyTestOf] != "walker.go" {
+				t.Errorf("test file annotation = %v, want test_of walker.go", meta)
+			}
+			if meta := p.annotators[0](subject, "walker.go"); meta[shared.MetadataKeyTestedBy] != "walker_test.go" {
+				t.Errorf("subject annotation = %v, want tested_by walker_test.go", meta)
+			}
+			if meta := p.annotators[0](other, "main.go"); len(meta) != 0 {
+				t.Errorf("unpaired file annotation = %v, want none", meta)
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+// Package cli provides command-line interface functionality for gibidify.
+package cli
+
+import (
+	"context"
+
+	"github.com/ivuorinen/gibidify/fileproc"
+)
+
+// loadWasmTransforms compiles the transforms.wasm modules once for the run, so the workers
+// only instantiate them.
+func (p *Processor) loadWasmTransforms(ctx context.Context) error {
+	transforms, err := fileproc.LoadWasmTransforms(ctx, p.settings.TransformsWasm())
+	if err != nil {
+		return err
+	}
+	p.wasm = transforms
+
+	return nil
+}
+
+// closeWasmTransforms releases the compiled transforms.wasm modules.
+func (p *Processor) closeWasmTransforms(ctx context.Context) {
+	if err := p.wasm.Close(ctx); err != nil {
+		p.logger.Warnf("Error closing WASM transforms: %v", err)
+	}
+	p.wasm = nil
+}
@@ -2,9 +2,15 @@
 package cli
 
 import (
+	"crypto/ed25519"
+	"io"
+	"os"
+
 	"github.com/ivuorinen/gibidify/config"
 	"github.com/ivuorinen/gibidify/fileproc"
 	"github.com/ivuorinen/gibidify/metrics"
+	"github.com/ivuorinen/gibidify/policy"
+	"github.com/ivuorinen/gibidify/shared"
 )
 
 // Processor handles the main file processing logic.
@@ -12,14 +18,60 @@ type Processor struct {
 	flags            *Flags
 	backpressure     *fileproc.BackpressureManager
 	resourceMonitor  *fileproc.ResourceMonitor
+	resourceLimits   fileproc.ResourceLimits
 	ui               *UIManager
 	metricsCollector *metrics.Collector
 	metricsReporter  *metrics.Reporter
+	annotators       []fileproc.Annotator
+	leadingEntries   []fileproc.WriteRequest
+	trailingEntries  []fileproc.WriteRequest
+	rollups          *fileproc.Rollups
+	budgets          *fileproc.Budgets
+	wasm             *fileproc.WasmTransforms
+	secrets          []fileproc.Redaction
+	contentCache     *fileproc.ContentCache
+	prefetch         *fileproc.Prefetcher
+	promptTemplate   string
+	signingKey       ed25519.PrivateKey
+	indexedFiles     []string
+	splitParts       []string
+	policy           *policy.Policy
+	restriction      *pathRestriction
+	registry         *fileproc.FileTypeRegistry
+	settings         *config.Settings
+	writer           io.Writer
+	logger           shared.Logger
+	runID            string
+	previewing       bool
+	// destinationTemplate is the destination before its timestamp placeholder was expanded.
+	destinationTemplate string
+	confirmIn           io.Reader
+	confirmOut          io.Writer
+	// reportOut receives the --report run report written without --report-file.
+	reportOut io.Writer
 }
 
-// NewProcessor creates a new processor with the given flags.
-func NewProcessor(flags *Flags) *Processor {
-	ui := NewUIManager()
+// NewProcessor creates a processor configured by opts, applied in order. The CLI passes
+// WithFlags; library users compose WithSource, WithDestination or WithWriter, and the other
+// options, over the command-line defaults. Unless WithRegistry is given, the processor owns
+// a file type registry created from its fileTypes configuration, so concurrent processors
+// never share file type settings. The processor reads the global configuration unless
+// WithSettings gives it its own. Every processor is one run, identified by a new run ID
+// unless WithRunID sets it.
+func NewProcessor(opts ...ProcessorOption) *Processor {
+	p := &Processor{flags: defaultFlags(), confirmIn: os.Stdin, confirmOut: os.Stderr, reportOut: os.Stderr}
+	for _, opt := range opts {
+		opt(p)
+	}
+	flags := p.flags
+	if p.writer == nil && flags.Stdout {
+		p.writer = os.Stdout
+	}
+	if p.settings == nil {
+		p.settings = config.Global()
+	}
+
+	ui := newUIManager(p.settings)
 
 	// Configure UI based on flags
 	ui.SetColorOutput(!flags.NoColors && !flags.NoUI)
@@ -34,26 +86,45 @@ func NewProcessor(flags *Flags) *Processor {
 		!flags.NoColors && !flags.NoUI,
 	)
 
-	return &Processor{
-		flags:            flags,
-		backpressure:     fileproc.NewBackpressureManager(),
-		resourceMonitor:  fileproc.NewResourceMonitor(),
-		ui:               ui,
-		metricsCollector: metricsCollector,
-		metricsReporter:  metricsReporter,
+	p.backpressure = fileproc.NewBackpressureManagerWithSettings(p.settings)
+	p.resourceMonitor = fileproc.NewResourceMonitorWithSettings(p.settings)
+	p.ui = ui
+	p.metricsCollector = metricsCollector
+	p.metricsReporter = metricsReporter
+	if p.registry == nil {
+		p.registry = newFileTypeRegistry(p.settings)
+	}
+	if p.logger == nil {
+		p.logger = shared.GetLogger()
 	}
+	if p.runID == "" {
+		p.runID = shared.NewRunID()
+	}
+	p.logger = p.logger.WithFields(map[string]any{shared.LogFieldRunID: p.runID})
+	metricsCollector.RecordRunID(p.runID)
+
+	return p
 }
 
-// configureFileTypes configures the file type registry.
-func (p *Processor) configureFileTypes() {
-	if config.FileTypesEnabled() {
-		fileproc.ConfigureFromSettings(
-			config.CustomImageExtensions(),
-			config.CustomBinaryExtensions(),
-			config.CustomLanguages(),
-			config.DisabledImageExtensions(),
-			config.DisabledBinaryExtensions(),
-			config.DisabledLanguageExtensions(),
+// RunID returns the ID identifying the run in log lines, reports and the bundle.
+func (p *Processor) RunID() string {
+	return p.runID
+}
+
+// newFileTypeRegistry creates a file type registry with the fileTypes configuration of
+// settings applied.
+func newFileTypeRegistry(settings *config.Settings) *fileproc.FileTypeRegistry {
+	registry := fileproc.NewFileTypeRegistry()
+	if settings.FileTypesEnabled() {
+		registry.Configure(
+			settings.CustomImageExtensions(),
+			settings.CustomBinaryExtensions(),
+			settings.CustomLanguages(),
+			settings.DisabledImageExtensions(),
+			settings.DisabledBinaryExtensions(),
+			settings.DisabledLanguageExtensions(),
 		)
 	}
+
+	return registry
 }
@@ -0,0 +1,75 @@
+// Package cli provides command-line interface functionality for gibidify.
+package cli
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ivuorinen/gibidify/metrics"
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// setupWarnings sets the warnings.slowFileSec threshold of the run.
+func (p *Processor) setupWarnings() {
+	p.metricsCollector.SetSlowFileThreshold(time.Duration(p.settings.WarningsSlowFileSec()) * time.Second)
+}
+
+// reportWarnings prints the non-fatal warnings of the run apart from its errors: the count by
+// kind, then the first few files of each kind.
+func (p *Processor) reportWarnings() {
+	current := p.metricsCollector.CurrentMetrics()
+	total := warningTotal(current)
+	if total == 0 {
+		return
+	}
+
+	kinds := slices.Sorted(maps.Keys(current.WarningCounts))
+	counts := make([]string, 0, len(kinds))
+	for _, kind := range kinds {
+		counts = append(counts, fmt.Sprintf("%s %d", kind, current.WarningCounts[kind]))
+	}
+	p.ui.PrintWarning("%d warnings (%s)", total, strings.Join(counts, ", "))
+
+	for _, kind := range kinds {
+		listed := 0
+		for _, warning := range current.Warnings {
+			if warning.Kind == kind && listed < shared.MetricsWarningExamples {
+				p.ui.PrintWarning("  %s: %s", warning.Path, warning.Message)
+				listed++
+			}
+		}
+		if more := current.WarningCounts[kind] - int64(listed); more > 0 {
+			p.ui.PrintWarning("  ... and %d more %s warnings", more, kind)
+		}
+	}
+}
+
+// checkWarnings fails a run that finished with warnings when --warnings-as-errors is set.
+// The bundle has been written by then, so it can still be inspected.
+func (p *Processor) checkWarnings() error {
+	if !p.flags.WarningsAsErrors {
+		return nil
+	}
+	total := warningTotal(p.metricsCollector.CurrentMetrics())
+	if total == 0 {
+		return nil
+	}
+
+	return shared.NewStructuredError(
+		shared.ErrorTypeValidation, shared.CodeValidationWarnings,
+		fmt.Sprintf("run finished with %d warnings (--warnings-as-errors)", total), p.flags.Destination, nil,
+	)
+}
+
+// warningTotal returns the number of warnings of every kind in current.
+func warningTotal(current metrics.ProcessingMetrics) int64 {
+	var total int64
+	for _, count := range current.WarningCounts {
+		total += count
+	}
+
+	return total
+}
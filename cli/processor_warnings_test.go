@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// TestProcessWarningsAsErrors tests that a run with warnings succeeds unless --warnings-as-errors
+// is set, and that the bundle is written either way.
+func TestProcessWarningsAsErrors(t *testing.T) {
+	srcDir := t.TempDir()
+	testutil.CreateTestFile(t, srcDir, "notes.txt", []byte("caf\xe9\n"))
+	testutil.SetViperKeys(t, map[string]any{shared.ConfigKeyCodeOwnersEnabled: false})
+	defer testutil.SuppressLogs(t)()
+
+	for _, strict := range []bool{false, true} {
+		dest := filepath.Join(t.TempDir(), "bundle.md")
+		p := NewProcessor(WithFlags(&Flags{
+			SourceDir: srcDir, Destination: dest, Format: shared.FormatMarkdown, Concurrency: 1, NoUI: true,
+			WarningsAsErrors: strict,
+		}))
+		err := p.Process(t.Context())
+
+		var structErr *shared.StructuredError
+		failed := errors.As(err, &structErr) && structErr.Code == shared.CodeValidationWarnings
+		if failed != strict || (!strict && err != nil) {
+			t.Errorf("warnings-as-errors %v: Process error = %v", strict, err)
+		}
+		if _, statErr := os.Stat(dest); statErr != nil {
+			t.Errorf("warnings-as-errors %v: bundle not written: %v", strict, statErr)
+		}
+		if got := p.metricsCollector.CurrentMetrics().WarningCounts[shared.WarningEncoding]; got != 1 {
+			t.Errorf("warnings-as-errors %v: %d encoding warnings, want 1", strict, got)
+		}
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/ivuorinen/gibidify/fileproc"
 	"github.com/ivuorinen/gibidify/metrics"
@@ -21,16 +22,17 @@ func (p *Processor) startWorkers(
 	fileCh chan string,
 	writeCh chan fileproc.WriteRequest,
 ) {
-	for range p.flags.Concurrency {
+	for id := range p.flags.Concurrency {
 		wg.Add(1)
-		go p.worker(ctx, wg, fileCh, writeCh)
+		go p.worker(ctx, wg, id, fileCh, writeCh)
 	}
 }
 
-// worker is the worker goroutine function.
+// worker is the worker goroutine function. id identifies the worker in the timing report.
 func (p *Processor) worker(
 	ctx context.Context,
 	wg *sync.WaitGroup,
+	id int,
 	fileCh chan string,
 	writeCh chan fileproc.WriteRequest,
 ) {
@@ -43,7 +45,11 @@ func (p *Processor) worker(
 			if !ok {
 				return
 			}
+			start := time.Now()
 			p.processFile(ctx, filePath, writeCh)
+			if p.metricsCollector != nil {
+				p.metricsCollector.RecordWorkerTime(id, time.Since(start))
+			}
 		}
 	}
 }
@@ -65,7 +71,7 @@ func (p *Processor) processFile(ctx context.Context, filePath string, writeCh ch
 
 	// Check for emergency stop
 	if p.resourceMonitor != nil && p.resourceMonitor.IsEmergencyStopActive() {
-		logger := shared.GetLogger()
+		logger := p.logger
 		logger.Warnf("Emergency stop active, skipping file: %s", filePath)
 
 		// Record skipped file
@@ -95,8 +101,16 @@ func (p *Processor) processFile(ctx context.Context, filePath string, writeCh ch
 	// Use the resource monitor-aware processing with metrics tracking
 	fileSize, format, success, processErr := p.processFileWithMetrics(fileCtx, filePath, writeCh, absRoot)
 
-	// Record the processing result (skipped=false, skipReason="" since processFileWithMetrics never skips)
-	p.recordFileResult(filePath, fileSize, format, success, false, "", processErr)
+	// Git LFS pointers, binary content, generated-looking text, excluded documentation and files
+	// left out by a WASM transform are skipped by policy rather than failing
+	if reason := skipReason(processErr); reason != "" {
+		p.recordFileResult(filePath, fileSize, format, false, true, reason, nil)
+	} else {
+		p.recordFileResult(filePath, fileSize, format, success, false, monitorSkipReason(processErr), processErr)
+	}
+	if reason := monitorSkipReason(processErr); reason != "" && p.resourceMonitor != nil {
+		p.resourceMonitor.RecordFileSkipped(reason, fileSize)
+	}
 
 	// Update progress bar with metrics
 	if p.ui != nil {
@@ -107,7 +121,7 @@ func (p *Processor) processFile(ctx context.Context, filePath string, writeCh ch
 	if p.flags.Verbose && p.metricsCollector != nil {
 		currentMetrics := p.metricsCollector.CurrentMetrics()
 		if currentMetrics.ProcessedFiles%10 == 0 && p.metricsReporter != nil {
-			logger := shared.GetLogger()
+			logger := p.logger
 			logger.Info(p.metricsReporter.ReportProgress())
 		}
 	}
@@ -130,6 +144,7 @@ func (p *Processor) sendFiles(ctx context.Context, files []string, fileCh chan s
 			return fmt.Errorf("context check failed: %w", err)
 		}
 
+		p.prefetch.Add(fp)
 		select {
 		case fileCh <- fp:
 		case <-ctx.Done():
@@ -165,8 +180,34 @@ func (p *Processor) processFileWithMetrics(
 		format = format[1:] // Remove the dot
 	}
 
-	// Use the existing resource monitor-aware processing
-	err = fileproc.ProcessFileWithMonitor(ctx, filePath, writeCh, absRoot, p.resourceMonitor)
+	// Use the resource monitor-aware processing with the configured annotators
+	monitor := p.resourceMonitor
+	if monitor == nil {
+		monitor = fileproc.NewResourceMonitorWithSettings(p.settings)
+	}
+	processor := fileproc.NewFileProcessorWithSettings(absRoot, monitor, p.settings)
+	processor.SetRegistry(p.registry)
+	processor.SetAnnotators(p.annotators...)
+	processor.SetDocLanguages(p.flags.DocLanguages()...)
+	processor.SetStripComments(p.flags.StripComments)
+	processor.SetCompact(p.flags.Compact)
+	processor.SetOffline(p.flags.Hermetic)
+	processor.SetBudgets(p.budgets)
+	processor.SetWasmTransforms(p.wasm)
+	processor.SetContentCache(p.contentCache)
+	processor.SetPrefetcher(p.prefetch)
+	processor.SetSecretRedactions(p.secrets)
+	if p.policy != nil {
+		processor.SetRedactions(p.policy.FileRedactions()...)
+	}
+	if p.metricsCollector != nil {
+		processor.SetSanitizeHook(p.metricsCollector.RecordSanitizedFile)
+		processor.SetSecurityHook(p.metricsCollector.RecordSecurityFindings)
+		processor.SetRedactionHook(p.metricsCollector.RecordRedactions)
+		processor.SetTimingHook(p.metricsCollector.RecordFileTiming)
+		processor.SetWarningHook(p.metricsCollector.RecordWarning)
+	}
+	err = processor.ProcessWithContext(ctx, filePath, writeCh)
 
 	// Check if processing was successful
 	select {
@@ -181,6 +222,55 @@ func (p *Processor) processFileWithMetrics(
 	}
 }
 
+// skipReason returns the skip reason carried by a Git LFS pointer, binary content, generated-text,
+// documentation language or WASM transform error, or "" for any other error.
+func skipReason(err error) string {
+	var structErr *shared.StructuredError
+	if !errors.As(err, &structErr) {
+		return ""
+	}
+	switch structErr.Code {
+	case shared.CodeValidationLFS, shared.CodeValidationBinary, shared.CodeValidationGenerated,
+		shared.CodeValidationDocLanguage, shared.CodeValidationTransform:
+	default:
+		return ""
+	}
+	if reason, ok := structErr.Context["reason"].(string); ok {
+		return reason
+	}
+
+	return structErr.Message
+}
+
+// monitorSkipReason returns the shared.SkipReason* a processing error leaves a file out of
+// the bundle for, or "" when the file was processed.
+func monitorSkipReason(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	var structErr *shared.StructuredError
+	if !errors.As(err, &structErr) {
+		return shared.SkipReasonError
+	}
+	switch structErr.Code {
+	case shared.CodeValidationLFS:
+		return shared.SkipReasonLFSPointer
+	case shared.CodeValidationBinary:
+		return shared.SkipReasonBinary
+	case shared.CodeValidationGenerated:
+		return shared.SkipReasonGenerated
+	case shared.CodeValidationDocLanguage:
+		return shared.SkipReasonDocLanguage
+	case shared.CodeValidationTransform:
+		return shared.SkipReasonTransform
+	case shared.CodeValidationSize, shared.CodeResourceLimitTotalSize:
+		return shared.SkipReasonSizeLimit
+	default:
+		return shared.SkipReasonError
+	}
+}
+
 // recordFileResult records the result of file processing in metrics.
 func (p *Processor) recordFileResult(
 	filePath string,
@@ -208,13 +298,16 @@ func (p *Processor) recordFileResult(
 	p.metricsCollector.RecordFileProcessed(result)
 }
 
-// waitForCompletion waits for all workers to complete.
+// waitForCompletion waits for all workers to complete, queues any trailing entries, and waits for the writer.
 func (p *Processor) waitForCompletion(
 	wg *sync.WaitGroup,
 	writeCh chan fileproc.WriteRequest,
 	writerDone chan struct{},
 ) {
 	wg.Wait()
+	for _, entry := range p.trailingEntries {
+		writeCh <- p.redactEntry(entry)
+	}
 	close(writeCh)
 	<-writerDone
 }
@@ -0,0 +1,27 @@
+// Package cli provides command-line interface functionality for gibidify.
+package cli
+
+import (
+	"github.com/ivuorinen/gibidify/config"
+)
+
+// ApplyProfile merges the --profile settings over the loaded settings. The output format the
+// profile selects replaces the default -format, and the default destination named after it,
+// unless those flags were given. Call it once the config is loaded.
+func ApplyProfile(flags *Flags, settings *config.Settings) error {
+	if flags.Profile == "" {
+		return nil
+	}
+	format, err := settings.ApplyProfile(flags.Profile)
+	if err != nil || format == "" || flags.formatSet {
+		return err //nolint:wrapcheck // wrapped by run
+	}
+
+	flags.Format = format
+	if flags.destinationSet || flags.Stdout {
+		return nil
+	}
+	flags.Destination = ""
+
+	return flags.setDefaultDestination()
+}
@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/config"
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// TestApplyProfile tests that the format of --profile replaces the default format and the
+// destination named after it, but not -format or -destination given on the command line.
+func TestApplyProfile(t *testing.T) {
+	srcDir := t.TempDir()
+	configFile := testutil.CreateTestFile(t, t.TempDir(), "config.yaml", []byte(
+		"profiles:\n  review:\n    format: markdown\n  limits:\n    fileSizeLimit: 4096\n",
+	))
+	t.Cleanup(func() { testutil.ResetViperConfig(t, "") })
+	base := filepath.Base(srcDir)
+
+	tests := []struct {
+		name            string
+		args            []string
+		wantFormat      string
+		wantDestination string
+	}{
+		{name: "profile format", args: []string{"-profile", "review"}, wantFormat: shared.FormatMarkdown,
+			wantDestination: base + ".markdown"},
+		{name: "format flag wins", args: []string{"-profile", "review", "-format", "yaml"},
+			wantFormat: shared.FormatYAML, wantDestination: base + ".yaml"},
+		{name: "destination flag kept", args: []string{"-profile", "review", "-destination", "out.txt"},
+			wantFormat: shared.FormatMarkdown, wantDestination: "out.txt"},
+		{name: "profile without format", args: []string{"-profile", "limits"}, wantFormat: shared.FormatJSON,
+			wantDestination: base + ".json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetFlagsState()
+			setupCommandLineArgs(append([]string{shared.TestCLIFlagSource, srcDir, "-config", configFile}, tt.args...))
+			flags, err := ParseFlags()
+			testutil.MustSucceed(t, err, "ParseFlags")
+			testutil.MustSucceed(t, config.LoadConfigFrom(flags.Config), "LoadConfigFrom")
+
+			testutil.MustSucceed(t, ApplyProfile(flags, config.Global()), "ApplyProfile")
+			if flags.Format != tt.wantFormat || flags.Destination != tt.wantDestination {
+				t.Errorf("format %q and destination %q, want %q and %q",
+					flags.Format, flags.Destination, tt.wantFormat, tt.wantDestination)
+			}
+		})
+	}
+
+	resetFlagsState()
+	setupCommandLineArgs([]string{shared.TestCLIFlagSource, srcDir, "-config", configFile, "-profile", "missing"})
+	flags, err := ParseFlags()
+	testutil.MustSucceed(t, err, "ParseFlags")
+	testutil.MustSucceed(t, config.LoadConfigFrom(flags.Config), "LoadConfigFrom")
+	if err := ApplyProfile(flags, config.Global()); err == nil {
+		t.Error("ApplyProfile() accepted an unknown profile")
+	}
+}
@@ -0,0 +1,169 @@
+// Package cli provides command-line interface functionality for gibidify.
+package cli
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/ivuorinen/gibidify/config"
+	"github.com/ivuorinen/gibidify/fileproc"
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+func init() {
+	registerSubcommand(shared.CLISubcommandQuick, "bundling quickly", func(ctx context.Context, args []string) error {
+		return RunQuick(ctx, os.Stdout, args)
+	})
+}
+
+// quickLockfiles are dependency lockfiles left out of quick bundles: they are large,
+// generated, and rarely useful as LLM context.
+var quickLockfiles = map[string]bool{
+	"package-lock.json":   true,
+	"npm-shrinkwrap.json": true,
+	"yarn.lock":           true,
+	"pnpm-lock.yaml":      true,
+	"bun.lockb":           true,
+	"bun.lock":            true,
+	"composer.lock":       true,
+	"Gemfile.lock":        true,
+	"Cargo.lock":          true,
+	"go.sum":              true,
+	"poetry.lock":         true,
+	"Pipfile.lock":        true,
+	"uv.lock":             true,
+	"Podfile.lock":        true,
+	"pubspec.lock":        true,
+	"mix.lock":            true,
+	"flake.lock":          true,
+}
+
+// QuickFlags holds flags for the quick subcommand.
+type QuickFlags struct {
+	SourceDir string
+	Stdout    bool
+}
+
+// ParseQuickFlags parses the arguments following the quick subcommand.
+// The source directory is an optional positional argument defaulting to the current directory.
+func ParseQuickFlags(args []string) (*QuickFlags, error) {
+	flags := &QuickFlags{SourceDir: "."}
+
+	fs := flag.NewFlagSet(shared.AppName+" "+shared.CLISubcommandQuick, flag.ContinueOnError)
+	fs.BoolVar(&flags.Stdout, "stdout", false, "Write the bundle to stdout instead of the clipboard")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 1 {
+		return nil, shared.NewStructuredError(
+			shared.ErrorTypeValidation,
+			shared.CodeCLIInvalidArgs,
+			"usage: gibidify quick [flags] [directory]",
+			"",
+			nil,
+		)
+	}
+	if fs.NArg() == 1 {
+		flags.SourceDir = fs.Arg(0)
+	}
+	if err := shared.ValidateSourcePath(flags.SourceDir); err != nil {
+		return nil, fmt.Errorf("validating source path: %w", err)
+	}
+
+	return flags, nil
+}
+
+// RunQuick bundles a repository as markdown with zero-flag defaults: .gitignore rules and
+// generated-text detection apply as usual, and lockfiles are left out. The bundle goes to
+// the clipboard when w is a terminal and a clipboard command is available, otherwise to w.
+func RunQuick(ctx context.Context, w io.Writer, args []string) error {
+	flags, err := ParseQuickFlags(args)
+	if err != nil {
+		return err
+	}
+
+	ui := NewUIManager()
+	content, processed, err := quickBundle(ctx, flags.SourceDir)
+	if err != nil {
+		return err
+	}
+
+	tokens, _ := fileproc.EstimateTokens(int64(len(content)), "")
+	if tokens > shared.QuickTokenWarning {
+		ui.PrintWarning(
+			"Bundle is ~%d tokens, more than a %dk context window; narrow it down with gibidify -source <subdir>",
+			tokens, shared.QuickTokenWarning/1000,
+		)
+	}
+
+	if !flags.Stdout && isTerminal(w) {
+		command, err := copyToClipboard(content)
+		if err == nil {
+			ui.PrintSuccess("Copied %d files (~%d tokens) to the clipboard with %s", processed, tokens, command)
+
+			return nil
+		}
+		ui.PrintWarning("Clipboard unavailable, writing to stdout: %v", err)
+	}
+
+	if _, err := w.Write(content); err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOWrite, "failed to write bundle")
+	}
+
+	return nil
+}
+
+// quickBundle renders the markdown bundle of source and returns its content and the number
+// of files bundled.
+func quickBundle(ctx context.Context, source string) ([]byte, int64, error) {
+	registry := newFileTypeRegistry(config.Global())
+	files, err := fileproc.CollectFilesWithRegistry(source, registry)
+	if err != nil {
+		return nil, 0, shared.WrapError(
+			err, shared.ErrorTypeProcessing, shared.CodeProcessingCollection, "error collecting files",
+		).WithFilePath(source)
+	}
+
+	var content bytes.Buffer
+	p := NewProcessor(
+		WithSource(source),
+		WithFormat(shared.FormatMarkdown),
+		WithWriter(&content),
+		WithRegistry(registry),
+	)
+	p.indexedFiles = withoutLockfiles(files)
+	if err := p.Process(ctx); err != nil {
+		return nil, 0, err
+	}
+
+	return content.Bytes(), p.metricsCollector.CurrentMetrics().ProcessedFiles, nil
+}
+
+// withoutLockfiles returns files without dependency lockfiles.
+func withoutLockfiles(files []string) []string {
+	kept := make([]string, 0, len(files))
+	for _, file := range files {
+		if !quickLockfiles[filepath.Base(file)] {
+			kept = append(kept, file)
+		}
+	}
+
+	return kept
+}
+
+// isTerminal reports whether w is a terminal rather than a pipe, file or buffer.
+func isTerminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
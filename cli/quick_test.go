@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+func TestParseQuickFlags(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantSource string
+		wantStdout bool
+		wantErr    bool
+	}{
+		{name: "defaults", args: nil, wantSource: "."},
+		{name: "directory", args: []string{dir}, wantSource: dir},
+		{name: "stdout", args: []string{"-stdout", dir}, wantSource: dir, wantStdout: true},
+		{name: "too many arguments", args: []string{dir, dir}, wantErr: true},
+		{name: "missing directory", args: []string{dir + "/missing"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flags, err := ParseQuickFlags(tt.args)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", flags)
+				}
+
+				return
+			}
+			testutil.MustSucceed(t, err, "ParseQuickFlags")
+			if flags.SourceDir != tt.wantSource || flags.Stdout != tt.wantStdout {
+				t.Errorf("got %+v, want source=%s stdout=%v", flags, tt.wantSource, tt.wantStdout)
+			}
+		})
+	}
+}
+
+// TestRunQuick tests that a bundle without lockfiles is written to a non-terminal writer.
+func TestRunQuick(t *testing.T) {
+	testutil.ResetViperConfig(t, "")
+	defer testutil.SuppressLogs(t)()
+
+	dir := t.TempDir()
+	testutil.CreateTestFile(t, dir, "main.go", []byte(shared.LiteralPackageMain))
+	testutil.CreateTestFile(t, dir, "go.sum", []byte("example.com/mod v1.0.0 h1:abc=\n"))
+	testutil.CreateTestFile(t, dir, "package-lock.json", []byte("{}\n"))
+
+	var out bytes.Buffer
+	testutil.MustSucceed(t, RunQuick(context.Background(), &out, []string{dir}), "RunQuick")
+
+	bundle := out.String()
+	if !strings.Contains(bundle, "main.go") {
+		t.Errorf("bundle is missing main.go:\n%s", bundle)
+	}
+	for _, lockfile := range []string{"go.sum", "package-lock.json"} {
+		if strings.Contains(bundle, lockfile) {
+			t.Errorf("bundle contains lockfile %s:\n%s", lockfile, bundle)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+// Package cli provides command-line interface functionality for gibidify.
+package cli
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/ivuorinen/gibidify/config"
+)
+
+// subcommand is a command dispatched on the first command-line argument instead of the bundling flags.
+type subcommand struct {
+	// action describes what the subcommand does, prefixing its errors.
+	action string
+	run    func(ctx context.Context, args []string) error
+}
+
+// subcommands holds the registered subcommands. Optional ones register themselves from files
+// the minimal build tag leaves out, so their dependencies are not linked into that binary.
+var subcommands = map[string]subcommand{}
+
+// registerSubcommand makes run available as the subcommand name.
+func registerSubcommand(name, action string, run func(ctx context.Context, args []string) error) {
+	subcommands[name] = subcommand{action: action, run: run}
+}
+
+// Subcommands returns the names of the subcommands built into this binary in sorted order.
+func Subcommands() []string {
+	return slices.Sorted(maps.Keys(subcommands))
+}
+
+// RunSubcommand loads the configuration and runs the subcommand name with args.
+// handled is false when no subcommand is registered under name.
+func RunSubcommand(ctx context.Context, name string, args []string) (handled bool, err error) {
+	sub, ok := subcommands[name]
+	if !ok {
+		return false, nil
+	}
+
+	if err := config.LoadConfigFromEnv(); err != nil {
+		return true, fmt.Errorf("loading config: %w", err)
+	}
+	if err := sub.run(ctx, args); err != nil {
+		return true, fmt.Errorf("%s: %w", sub.action, err)
+	}
+
+	return true, nil
+}
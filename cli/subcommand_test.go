@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// TestRunSubcommand tests dispatching registered subcommands and falling through to the bundling flags.
+func TestRunSubcommand(t *testing.T) {
+	testutil.ResetViperConfig(t, "")
+	for _, name := range []string{shared.CLISubcommandDoctor, shared.CLISubcommandQuick, shared.CLISubcommandClean} {
+		if !slices.Contains(Subcommands(), name) {
+			t.Errorf("Subcommands() = %v, missing core subcommand %q", Subcommands(), name)
+		}
+	}
+
+	var gotArgs []string
+	registerSubcommand("test-sub", "testing", func(_ context.Context, args []string) error {
+		gotArgs = args
+		if len(args) > 0 && args[0] == "fail" {
+			return errors.New("boom")
+		}
+
+		return nil
+	})
+	t.Cleanup(func() { delete(subcommands, "test-sub") })
+
+	handled, err := RunSubcommand(context.Background(), "test-sub", []string{"-x"})
+	if !handled || err != nil || !slices.Equal(gotArgs, []string{"-x"}) {
+		t.Errorf("RunSubcommand() = %v, %v with args %v, want handled with args [-x]", handled, err, gotArgs)
+	}
+
+	handled, err = RunSubcommand(context.Background(), "test-sub", []string{"fail"})
+	if !handled || err == nil || !strings.HasPrefix(err.Error(), "testing: ") {
+		t.Errorf("RunSubcommand() = %v, %v, want a handled error prefixed with the action", handled, err)
+	}
+
+	if handled, err := RunSubcommand(context.Background(), "-source", nil); handled || err != nil {
+		t.Errorf("RunSubcommand(-source) = %v, %v, want unhandled", handled, err)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/fatih/color"
 	"github.com/schollz/progressbar/v3"
 
+	"github.com/ivuorinen/gibidify/config"
 	"github.com/ivuorinen/gibidify/shared"
 )
 
@@ -18,23 +19,45 @@ type UIManager struct {
 	enableColors   bool
 	enableProgress bool
 	silentMode     bool
+	progressStyle  string
+	theme          uiTheme
 	progressBar    *progressbar.ProgressBar
+	dots           *dotsProgress
 	output         io.Writer
 }
 
-// NewUIManager creates a new UI manager.
+// NewUIManager creates a new UI manager using the ui.progressStyle and ui.theme settings.
 func NewUIManager() *UIManager {
+	return newUIManager(config.Global())
+}
+
+// newUIManager creates a UI manager using the ui settings of settings.
+func newUIManager(settings *config.Settings) *UIManager {
 	return &UIManager{
 		enableColors:   isColorTerminal(),
 		enableProgress: isInteractiveTerminal(),
+		progressStyle:  settings.UIProgressStyle(),
+		theme:          themeFor(settings.UITheme()),
 		output:         os.Stderr, // Progress and colors go to stderr
 	}
 }
 
-// SetColorOutput enables or disables colored output.
+// SetColorOutput enables or disables colored output. It leaves the color.NoColor default
+// alone, so UI managers of concurrent processors do not race on it.
 func (ui *UIManager) SetColorOutput(enabled bool) {
 	ui.enableColors = enabled
-	color.NoColor = !enabled
+}
+
+// colored returns a color of attributes that is on exactly when the UI manager uses colors.
+func (ui *UIManager) colored(attributes ...color.Attribute) *color.Color {
+	c := color.New(attributes...)
+	if ui.enableColors {
+		c.EnableColor()
+	} else {
+		c.DisableColor()
+	}
+
+	return c
 }
 
 // SetProgressOutput enables or disables progress bars.
@@ -52,29 +75,54 @@ func (ui *UIManager) SetSilentMode(silent bool) {
 	}
 }
 
-// StartProgress initializes a progress bar for file processing.
+// StartProgress initializes progress display for file processing in the configured style.
 func (ui *UIManager) StartProgress(total int, description string) {
-	if !ui.enableProgress || total <= 0 {
+	if !ui.enableProgress || total <= 0 || ui.progressStyle == shared.UIProgressStyleNone {
 		return
 	}
 
-	ui.progressBar = progressbar.NewOptions(
-		total,
+	switch ui.progressStyle {
+	case shared.UIProgressStyleDots:
+		ui.dots = &dotsProgress{total: total}
+		ui.printf("%s ", description)
+	case shared.UIProgressStyleSpinner:
+		ui.progressBar = progressbar.NewOptions(
+			-1,
+			append(
+				ui.progressOptions(description),
+				progressbar.OptionSpinnerCustom(ui.theme.spinner),
+				// Advance the spinner as files finish rather than from a ticker goroutine.
+				progressbar.OptionSetSpinnerChangeInterval(0),
+			)...,
+		)
+	default:
+		ui.progressBar = progressbar.NewOptions(
+			total,
+			append(
+				ui.progressOptions(description),
+				progressbar.OptionSetTheme(
+					progressbar.Theme{
+						Saucer:        ui.colored(color.FgGreen).Sprint(ui.theme.progressChar),
+						SaucerHead:    ui.colored(color.FgGreen).Sprint(ui.theme.progressChar),
+						SaucerPadding: " ",
+						BarStart:      "[",
+						BarEnd:        "]",
+					},
+				),
+				progressbar.OptionSetWidth(40),
+			)...,
+		)
+	}
+}
+
+// progressOptions returns the options shared by the bar and spinner progress styles.
+func (ui *UIManager) progressOptions(description string) []progressbar.Option {
+	return []progressbar.Option{
 		progressbar.OptionSetWriter(ui.output),
 		progressbar.OptionSetDescription(description),
-		progressbar.OptionSetTheme(
-			progressbar.Theme{
-				Saucer:        color.GreenString(shared.UIProgressBarChar),
-				SaucerHead:    color.GreenString(shared.UIProgressBarChar),
-				SaucerPadding: " ",
-				BarStart:      "[",
-				BarEnd:        "]",
-			},
-		),
 		progressbar.OptionShowCount(),
 		progressbar.OptionShowIts(),
-		progressbar.OptionSetWidth(40),
-		progressbar.OptionThrottle(100*time.Millisecond),
+		progressbar.OptionThrottle(100 * time.Millisecond),
 		progressbar.OptionOnCompletion(
 			func() {
 				//nolint:errcheck // UI output, errors don't affect processing
@@ -82,22 +130,29 @@ func (ui *UIManager) StartProgress(total int, description string) {
 			},
 		),
 		progressbar.OptionSetRenderBlankState(true),
-	)
+	}
 }
 
-// UpdateProgress increments the progress bar.
+// UpdateProgress advances the progress display.
 func (ui *UIManager) UpdateProgress(increment int) {
 	if ui.progressBar != nil {
 		_ = ui.progressBar.Add(increment)
 	}
+	if ui.dots != nil {
+		ui.printf("%s", ui.dots.add(increment))
+	}
 }
 
-// FinishProgress completes the progress bar.
+// FinishProgress completes the progress display.
 func (ui *UIManager) FinishProgress() {
 	if ui.progressBar != nil {
 		_ = ui.progressBar.Finish()
 		ui.progressBar = nil
 	}
+	if ui.dots != nil {
+		ui.printf("%s\n", ui.dots.finish())
+		ui.dots = nil
+	}
 }
 
 // PrintSuccess prints a success message in green.
@@ -106,9 +161,9 @@ func (ui *UIManager) PrintSuccess(format string, args ...any) {
 		return
 	}
 	if ui.enableColors {
-		color.Green("✓ "+format, args...)
+		_, _ = ui.colored(color.FgGreen).Fprintf(color.Output, ui.theme.success+" "+format+"\n", args...)
 	} else {
-		ui.printf("✓ "+format+"\n", args...)
+		ui.printf(ui.theme.success+" "+format+"\n", args...)
 	}
 }
 
@@ -118,9 +173,9 @@ func (ui *UIManager) PrintError(format string, args ...any) {
 		return
 	}
 	if ui.enableColors {
-		color.Red("✗ "+format, args...)
+		_, _ = ui.colored(color.FgRed).Fprintf(color.Output, ui.theme.failure+" "+format+"\n", args...)
 	} else {
-		ui.printf("✗ "+format+"\n", args...)
+		ui.printf(ui.theme.failure+" "+format+"\n", args...)
 	}
 }
 
@@ -130,9 +185,9 @@ func (ui *UIManager) PrintWarning(format string, args ...any) {
 		return
 	}
 	if ui.enableColors {
-		color.Yellow("⚠ "+format, args...)
+		_, _ = ui.colored(color.FgYellow).Fprintf(color.Output, ui.theme.warning+" "+format+"\n", args...)
 	} else {
-		ui.printf("⚠ "+format+"\n", args...)
+		ui.printf(ui.theme.warning+" "+format+"\n", args...)
 	}
 }
 
@@ -142,10 +197,9 @@ func (ui *UIManager) PrintInfo(format string, args ...any) {
 		return
 	}
 	if ui.enableColors {
-		//nolint:errcheck // UI output, errors don't affect processing
-		color.Blue("ℹ "+format, args...)
+		_, _ = ui.colored(color.FgBlue).Fprintf(color.Output, ui.theme.info+" "+format+"\n", args...)
 	} else {
-		ui.printf("ℹ "+format+"\n", args...)
+		ui.printf(ui.theme.info+" "+format+"\n", args...)
 	}
 }
 
@@ -155,8 +209,7 @@ func (ui *UIManager) PrintHeader(format string, args ...any) {
 		return
 	}
 	if ui.enableColors {
-		//nolint:errcheck // UI output, errors don't affect processing
-		_, _ = color.New(color.Bold).Fprintf(ui.output, format+"\n", args...)
+		_, _ = ui.colored(color.Bold).Fprintf(ui.output, format+"\n", args...)
 	} else {
 		ui.printf(format+"\n", args...)
 	}
@@ -0,0 +1,109 @@
+// Package cli provides command-line interface functionality for gibidify.
+package cli
+
+import (
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// uiTheme holds the markers the UI prints around messages and progress.
+type uiTheme struct {
+	success string
+	failure string
+	warning string
+	info    string
+	// start, collect, setup and process prefix the run headers; they are empty in the ASCII theme.
+	start   string
+	collect string
+	setup   string
+	process string
+	// progressChar fills the progress bar and spinner animates the spinner progress style.
+	progressChar string
+	spinner      []string
+}
+
+// unicodeTheme marks messages with symbols and emoji.
+var unicodeTheme = uiTheme{
+	success:      "✓",
+	failure:      "✗",
+	warning:      "⚠",
+	info:         "ℹ",
+	start:        "🚀 ",
+	collect:      "📁 ",
+	setup:        "\u2699\ufe0f  ",
+	process:      "📝 ",
+	progressChar: shared.UIProgressBarChar,
+	spinner:      []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"},
+}
+
+// asciiTheme marks messages with plain text, for consoles that cannot show Unicode.
+var asciiTheme = uiTheme{
+	success:      "[ok]",
+	failure:      "[error]",
+	warning:      "[warn]",
+	info:         "[info]",
+	progressChar: shared.UIProgressBarCharASCII,
+	spinner:      []string{"|", "/", "-", "\\"},
+}
+
+// themeFor returns the theme named by ui.theme. Auto and unknown names pick the ASCII theme
+// when the terminal or locale cannot show Unicode.
+func themeFor(name string) uiTheme {
+	switch name {
+	case shared.UIThemeUnicode:
+		return unicodeTheme
+	case shared.UIThemeASCII:
+		return asciiTheme
+	default:
+		if unicodeSupported() {
+			return unicodeTheme
+		}
+
+		return asciiTheme
+	}
+}
+
+// unicodeSupported reports whether the terminal can show the Unicode theme: it is not a dumb
+// terminal and the locale, when one is set, uses UTF-8. Consoles that set no locale, such as
+// Windows Terminal, are assumed to cope.
+func unicodeSupported() bool {
+	if os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if locale := strings.ToLower(os.Getenv(name)); locale != "" {
+			return strings.Contains(locale, "utf-8") || strings.Contains(locale, "utf8")
+		}
+	}
+
+	return true
+}
+
+// dotsProgress prints the dots progress style: shared.UIProgressDots dots over a complete run,
+// appended without redrawing so the output reads well in logs.
+type dotsProgress struct {
+	mu      sync.Mutex
+	total   int
+	done    int
+	printed int
+}
+
+// add records increment more finished files and returns the dots now due.
+func (d *dotsProgress) add(increment int) string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.done = min(d.done+increment, d.total)
+	due := d.done*shared.UIProgressDots/d.total - d.printed
+	d.printed += due
+
+	return strings.Repeat(".", due)
+}
+
+// finish returns the remaining dots of a complete run.
+func (d *dotsProgress) finish() string {
+	return d.add(d.total)
+}
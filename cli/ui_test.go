@@ -4,6 +4,7 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"unicode"
 
 	"github.com/ivuorinen/gibidify/shared"
 )
@@ -529,3 +530,126 @@ func TestUIManagerIntegration(t *testing.T) {
 		}
 	}
 }
+
+func TestUIManagerProgressStyles(t *testing.T) {
+	tests := []struct {
+		name     string
+		style    string
+		wantBar  bool
+		wantDots string
+		unwanted string
+	}{
+		{name: "bar", style: shared.UIProgressStyleBar, wantBar: true, unwanted: "⠋"},
+		{name: "spinner", style: shared.UIProgressStyleSpinner, wantBar: true, unwanted: shared.UIProgressBarChar},
+		{name: "dots", style: shared.UIProgressStyleDots, wantDots: strings.Repeat(".", shared.UIProgressDots)},
+		{name: "none", style: shared.UIProgressStyleNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ui, output := createTestUI()
+			ui.SetProgressOutput(true)
+			ui.progressStyle = tt.style
+
+			ui.StartProgress(4, shared.TestProgressMessage)
+			if got := ui.progressBar != nil; got != tt.wantBar {
+				t.Errorf("progress bar created = %v, want %v", got, tt.wantBar)
+			}
+			for range 4 {
+				ui.UpdateProgress(1)
+			}
+			ui.FinishProgress()
+
+			got := output.String()
+			if tt.style == shared.UIProgressStyleNone && got != "" {
+				t.Errorf("none style printed %q", got)
+			}
+			if tt.wantDots != "" && got != shared.TestProgressMessage+" "+tt.wantDots+"\n" {
+				t.Errorf("dots style printed %q", got)
+			}
+			if tt.unwanted != "" && strings.Contains(got, tt.unwanted) {
+				t.Errorf("%s style printed %q, containing %q", tt.style, got, tt.unwanted)
+			}
+		})
+	}
+}
+
+func TestDotsProgress(t *testing.T) {
+	dots := &dotsProgress{total: 200}
+
+	var printed strings.Builder
+	for range 199 {
+		printed.WriteString(dots.add(1))
+	}
+	if got := printed.Len(); got != shared.UIProgressDots-1 {
+		t.Errorf("printed %d dots for 199 of 200 files, want %d", got, shared.UIProgressDots-1)
+	}
+	if got := dots.finish(); got != "." {
+		t.Errorf("finish() = %q, want the last dot", got)
+	}
+	if got := dots.add(5); got != "" {
+		t.Errorf("add() after finish = %q, want no more dots", got)
+	}
+}
+
+func TestUIManagerASCIITheme(t *testing.T) {
+	ui, output := createTestUI()
+	ui.theme = asciiTheme
+	ui.progressStyle = shared.UIProgressStyleBar
+	ui.SetProgressOutput(true)
+
+	ui.PrintHeader(ui.theme.start + "Starting")
+	ui.PrintSuccess("done")
+	ui.PrintError("failed")
+	ui.PrintWarning("careful")
+	ui.PrintInfo(ui.theme.collect + "note")
+	ui.StartProgress(2, shared.TestProgressMessage)
+	ui.UpdateProgress(2)
+	ui.FinishProgress()
+
+	got := output.String()
+	for _, want := range []string{"Starting\n", "[ok] done", "[error] failed", "[warn] careful", "[info] note", "##"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output %q does not contain %q", got, want)
+		}
+	}
+	for _, r := range got {
+		if r > unicode.MaxASCII {
+			t.Fatalf("output %q contains non-ASCII %q", got, r)
+		}
+	}
+}
+
+func TestThemeFor(t *testing.T) {
+	tests := []struct {
+		name   string
+		theme  string
+		term   string
+		lcAll  string
+		lang   string
+		wantOK string
+	}{
+		{name: "unicode forced", theme: shared.UIThemeUnicode, term: "dumb", wantOK: "✓"},
+		{name: "ascii forced", theme: shared.UIThemeASCII, lang: "en_US.UTF-8", wantOK: "[ok]"},
+		{name: "auto with UTF-8 locale", theme: shared.UIThemeAuto, lang: "en_US.UTF-8", wantOK: "✓"},
+		{name: "auto with utf8 locale", theme: shared.UIThemeAuto, lang: "C.utf8", wantOK: "✓"},
+		{name: "auto with C locale", theme: shared.UIThemeAuto, lang: "C", wantOK: "[ok]"},
+		{name: "auto with LC_ALL overriding", theme: shared.UIThemeAuto, lcAll: "POSIX", lang: "en_US.UTF-8", wantOK: "[ok]"},
+		{name: "auto with dumb terminal", theme: shared.UIThemeAuto, term: "dumb", lang: "en_US.UTF-8", wantOK: "[ok]"},
+		{name: "auto without locale", theme: shared.UIThemeAuto, wantOK: "✓"},
+		{name: "unset", theme: "", lang: "C", wantOK: "[ok]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TERM", tt.term)
+			t.Setenv("LC_ALL", tt.lcAll)
+			t.Setenv("LC_CTYPE", "")
+			t.Setenv("LANG", tt.lang)
+
+			if got := themeFor(tt.theme).success; got != tt.wantOK {
+				t.Errorf("themeFor(%q).success = %q, want %q", tt.theme, got, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+// Package cli provides command-line interface functionality for gibidify.
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/signing"
+)
+
+func init() {
+	registerSubcommand(shared.CLISubcommandVerify, "verifying bundle", func(_ context.Context, args []string) error {
+		return RunVerify(os.Stdout, args)
+	})
+}
+
+// VerifyFlags holds flags for the verify subcommand.
+type VerifyFlags struct {
+	Key       string
+	Bundle    string
+	Signature string
+}
+
+// ParseVerifyFlags parses the arguments following the verify subcommand: the -key flag, the
+// bundle and optionally its signature, which defaults to the bundle with .sig appended.
+func ParseVerifyFlags(args []string) (*VerifyFlags, error) {
+	flags := &VerifyFlags{}
+
+	fs := flag.NewFlagSet(shared.AppName+" "+shared.CLISubcommandVerify, flag.ContinueOnError)
+	fs.StringVar(&flags.Key, "key", "", "Ed25519 public key (PKIX PEM) of the signer")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if flags.Key == "" || fs.NArg() < 1 || fs.NArg() > 2 {
+		return nil, shared.NewStructuredError(
+			shared.ErrorTypeValidation, shared.CodeCLIInvalidArgs,
+			"usage: gibidify verify -key <public key> <bundle> [signature]", "", nil,
+		)
+	}
+	flags.Bundle = fs.Arg(0)
+	flags.Signature = flags.Bundle + signing.Extension
+	if fs.NArg() == 2 {
+		flags.Signature = fs.Arg(1)
+	}
+
+	return flags, nil
+}
+
+// RunVerify checks a bundle against its --sign signature and writes the status of every
+// signed file to w. It fails when the signature was not made with the key or a signed file
+// that is present does not match it.
+func RunVerify(w io.Writer, args []string) error {
+	flags, err := ParseVerifyFlags(args)
+	if err != nil {
+		return err
+	}
+
+	key, err := signing.LoadPublicKey(flags.Key)
+	if err != nil {
+		return err
+	}
+	checks, err := signing.Verify(key, flags.Signature, flags.Bundle)
+	if err != nil {
+		return err
+	}
+
+	for _, check := range checks {
+		if _, err := fmt.Fprintf(w, "%s\t%s\n", check.Status, check.Path); err != nil {
+			return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOWrite, "failed to write verification")
+		}
+	}
+	_, err = fmt.Fprintf(w, "Signature by key %s verified\n", signing.KeyID(key))
+	if err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOWrite, "failed to write verification")
+	}
+
+	return nil
+}
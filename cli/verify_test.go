@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"strings"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/signing"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+func TestRunVerify(t *testing.T) {
+	dir := t.TempDir()
+	public, private, err := ed25519.GenerateKey(nil)
+	testutil.MustSucceed(t, err, "generating key")
+	publicDER, err := x509.MarshalPKIXPublicKey(public)
+	testutil.MustSucceed(t, err, "encoding public key")
+	keyPath := testutil.CreateTestFile(t, dir, "key.pub",
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}))
+	bundle := testutil.CreateTestFile(t, dir, "bundle.md", []byte("# Bundle\n"))
+	testutil.MustSucceed(t, signing.Sign(private, bundle+signing.Extension, []string{bundle}), "Sign")
+
+	var out bytes.Buffer
+	testutil.MustSucceed(t, RunVerify(&out, []string{"-key", keyPath, bundle}), "RunVerify")
+	if !strings.Contains(out.String(), "ok\t"+bundle) || !strings.Contains(out.String(), signing.KeyID(public)) {
+		t.Errorf("RunVerify() output = %q, want the bundle verified by %s", out.String(), signing.KeyID(public))
+	}
+
+	testutil.CreateTestFile(t, dir, "bundle.md", []byte("# Changed\n"))
+	if err := RunVerify(&out, []string{"-key", keyPath, bundle}); err == nil {
+		t.Error("RunVerify() of a changed bundle succeeded, want an error")
+	}
+}
+
+func TestParseVerifyFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    *VerifyFlags
+		wantErr bool
+	}{
+		{
+			name: "default signature",
+			args: []string{"-key", "key.pub", "bundle.md"},
+			want: &VerifyFlags{Key: "key.pub", Bundle: "bundle.md", Signature: "bundle.md.sig"},
+		},
+		{
+			name: "explicit signature",
+			args: []string{"-key", "key.pub", "bundle.md", "release.sig"},
+			want: &VerifyFlags{Key: "key.pub", Bundle: "bundle.md", Signature: "release.sig"},
+		},
+		{name: "missing key", args: []string{"bundle.md"}, wantErr: true},
+		{name: "missing bundle", args: []string{"-key", "key.pub"}, wantErr: true},
+		{name: "extra argument", args: []string{"-key", "key.pub", "a", "b", "c"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseVerifyFlags(tt.args)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseVerifyFlags(%v) = %+v, want an error", tt.args, got)
+				}
+
+				return
+			}
+			testutil.MustSucceed(t, err, "ParseVerifyFlags")
+			if *got != *tt.want {
+				t.Errorf("ParseVerifyFlags(%v) = %+v, want %+v", tt.args, got, tt.want)
+			}
+		})
+	}
+}
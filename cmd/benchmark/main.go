@@ -2,6 +2,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
@@ -20,6 +21,8 @@ var (
 	concurrencyList *string
 	formatList      *string
 	numFiles        *int
+	corpusName      *string
+	prefetch        *bool
 )
 
 func main() {
@@ -43,6 +46,11 @@ func main() {
 		"format-list", shared.TestFormatList, "Comma-separated list of formats",
 	)
 	numFiles = fs.Int("files", shared.BenchmarkDefaultFileCount, "Number of files to create for benchmarks")
+	prefetch = fs.Bool("prefetch", false,
+		"Run each concurrency level with and without files read ahead of the workers")
+	corpusName = fs.String("corpus", "",
+		"Benchmark a standard open-source tree ("+strings.Join(benchmark.CorpusNames(), ", ")+
+			"), downloaded and cached on first use, instead of temp files")
 
 	if err := fs.Parse(os.Args[1:]); err != nil {
 		//goland:noinspection GoUnhandledErrorResult
@@ -50,6 +58,12 @@ func main() {
 		os.Exit(1)
 	}
 
+	if err := resolveCorpus(context.Background()); err != nil {
+		//goland:noinspection GoUnhandledErrorResult
+		_, _ = fmt.Fprintf(os.Stderr, "Corpus download failed: %v\n", err)
+		os.Exit(1)
+	}
+
 	if err := runBenchmarks(); err != nil {
 		//goland:noinspection GoUnhandledErrorResult
 		_, _ = fmt.Fprintf(os.Stderr, "Benchmark failed: %v\n", err)
@@ -57,6 +71,35 @@ func main() {
 	}
 }
 
+// resolveCorpus points the source directory at the --corpus tree, downloading it into the user
+// cache dir unless an earlier run cached it.
+func resolveCorpus(ctx context.Context) error {
+	if *corpusName == "" {
+		return nil
+	}
+	if *sourceDir != "" {
+		return shared.NewValidationError(shared.CodeValidationFormat, "--corpus and --source are mutually exclusive")
+	}
+	corpus, err := benchmark.LookupCorpus(*corpusName)
+	if err != nil {
+		return err
+	}
+	cacheDir, err := benchmark.DefaultCorpusDir()
+	if err != nil {
+		return err
+	}
+
+	//nolint:errcheck // Benchmark status message, errors don't affect benchmark results
+	_, _ = fmt.Printf("Fetching %s %s corpus...\n", corpus.Name, corpus.Version)
+	dir, err := benchmark.FetchCorpus(ctx, corpus, cacheDir)
+	if err != nil {
+		return err
+	}
+	*sourceDir = dir
+
+	return nil
+}
+
 func runBenchmarks() error {
 	//nolint:errcheck // Benchmark informational output, errors don't affect benchmark results
 	_, _ = fmt.Println("Running gibidify benchmarks...")
@@ -131,8 +174,13 @@ func runConcurrencyBenchmark() error {
 	}
 
 	//nolint:errcheck // Benchmark status message, errors don't affect benchmark results
-	_, _ = fmt.Printf("Running concurrency benchmark (format: %s, levels: %v)...\n", *format, concurrencyLevels)
-	suite, err := benchmark.ConcurrencyBenchmark(*sourceDir, *format, concurrencyLevels)
+	_, _ = fmt.Printf("Running concurrency benchmark (format: %s, levels: %v, prefetch: %v)...\n",
+		*format, concurrencyLevels, *prefetch)
+	run := benchmark.ConcurrencyBenchmark
+	if *prefetch {
+		run = benchmark.ConcurrencyPrefetchBenchmark
+	}
+	suite, err := run(*sourceDir, *format, concurrencyLevels)
 	if err != nil {
 		return shared.WrapError(
 			err,
@@ -165,6 +213,9 @@ func getSourceDescription() string {
 	if *sourceDir == "" {
 		return fmt.Sprintf("temporary files (%d files)", *numFiles)
 	}
+	if corpus, err := benchmark.LookupCorpus(*corpusName); err == nil {
+		return fmt.Sprintf("%s %s corpus (%s)", corpus.Name, corpus.Version, *sourceDir)
+	}
 
 	return *sourceDir
 }
@@ -343,6 +343,22 @@ func TestRunConcurrencyBenchmark(t *testing.T) {
 		testutil.AssertNoError(t, err, "runConcurrencyBenchmark")
 	})
 
+	t.Run("success with prefetch", func(t *testing.T) {
+		tempDir := t.TempDir()
+		testutil.CreateTestFiles(t, tempDir, []testutil.FileSpec{
+			{Name: testFile1, Content: testContent1},
+		})
+
+		*sourceDir = tempDir
+		*format = testJSON
+		*concurrencyList = testConcurrency
+		*prefetch = true
+		defer func() { *prefetch = false }()
+
+		err := runConcurrencyBenchmark()
+		testutil.AssertNoError(t, err, "runConcurrencyBenchmark with prefetch")
+	})
+
 	t.Run("error with invalid concurrency list", func(t *testing.T) {
 		tempDir := t.TempDir()
 		*sourceDir = tempDir
@@ -753,4 +769,6 @@ func resetFlags() {
 	)
 	formatList = flag.String("format-list", shared.TestFormatList, "Comma-separated list of formats")
 	numFiles = flag.Int("files", 100, "Number of files to create for benchmarks")
+	corpusName = flag.String("corpus", "", "Benchmark a standard open-source tree")
+	prefetch = flag.Bool("prefetch", false, "Run each concurrency level with and without files read ahead")
 }
@@ -57,16 +57,16 @@ func TestFileTypeRegistryValidationFailure(t *testing.T) {
 func verifyDefaultValues(t *testing.T) {
 	t.Helper()
 
-	if !FileTypesEnabled() {
+	if !Global().FileTypesEnabled() {
 		t.Error("Expected file types to be enabled by default")
 	}
 
-	verifyEmptySlice(t, CustomImageExtensions(), "custom image extensions")
-	verifyEmptySlice(t, CustomBinaryExtensions(), "custom binary extensions")
-	verifyEmptyMap(t, CustomLanguages(), "custom languages")
-	verifyEmptySlice(t, DisabledImageExtensions(), "disabled image extensions")
-	verifyEmptySlice(t, DisabledBinaryExtensions(), "disabled binary extensions")
-	verifyEmptySlice(t, DisabledLanguageExtensions(), "disabled language extensions")
+	verifyEmptySlice(t, Global().CustomImageExtensions(), "custom image extensions")
+	verifyEmptySlice(t, Global().CustomBinaryExtensions(), "custom binary extensions")
+	verifyEmptyMap(t, Global().CustomLanguages(), "custom languages")
+	verifyEmptySlice(t, Global().DisabledImageExtensions(), "disabled image extensions")
+	verifyEmptySlice(t, Global().DisabledBinaryExtensions(), "disabled binary extensions")
+	verifyEmptySlice(t, Global().DisabledLanguageExtensions(), "disabled language extensions")
 }
 
 // setTestConfiguration sets test configuration values.
@@ -89,22 +89,24 @@ func setTestConfiguration() {
 func verifyTestConfiguration(t *testing.T) {
 	t.Helper()
 
-	if FileTypesEnabled() {
+	if Global().FileTypesEnabled() {
 		t.Error("Expected file types to be disabled")
 	}
 
-	verifyStringSlice(t, CustomImageExtensions(), []string{".webp", ".avif"}, "custom image extensions")
-	verifyStringSlice(t, CustomBinaryExtensions(), []string{".custom", ".mybin"}, "custom binary extensions")
+	verifyStringSlice(t, Global().CustomImageExtensions(), []string{".webp", ".avif"}, "custom image extensions")
+	verifyStringSlice(t, Global().CustomBinaryExtensions(), []string{".custom", ".mybin"}, "custom binary extensions")
 
 	expectedLangs := map[string]string{
 		".zig": "zig",
 		".v":   "vlang",
 	}
-	verifyStringMap(t, CustomLanguages(), expectedLangs, "custom languages")
+	verifyStringMap(t, Global().CustomLanguages(), expectedLangs, "custom languages")
 
-	verifyStringSliceLength(t, DisabledImageExtensions(), []string{".gif", ".bmp"}, "disabled image extensions")
-	verifyStringSliceLength(t, DisabledBinaryExtensions(), []string{".exe", ".dll"}, "disabled binary extensions")
-	verifyStringSliceLength(t, DisabledLanguageExtensions(), []string{".rb", ".pl"}, "disabled language extensions")
+	verifyStringSliceLength(t, Global().DisabledImageExtensions(), []string{".gif", ".bmp"}, "disabled image extensions")
+	verifyStringSliceLength(t, Global().DisabledBinaryExtensions(), []string{".exe", ".dll"}, "disabled binary extensions")
+	verifyStringSliceLength(
+		t, Global().DisabledLanguageExtensions(), []string{".rb", ".pl"}, "disabled language extensions",
+	)
 }
 
 // setValidConfiguration sets valid configuration for validation tests.
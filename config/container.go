@@ -0,0 +1,160 @@
+package config
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// ContainerLimits holds the CPU and memory limits of the cgroup gibidify runs in.
+// Zero values mean no limit was found.
+type ContainerLimits struct {
+	CPUs        float64
+	MemoryBytes int64
+}
+
+var (
+	containerLimitsOnce sync.Once
+	containerLimits     ContainerLimits
+
+	// cpuOverride is the CPU count set with SetCPUs; 0 uses the detected limits.
+	cpuOverride atomic.Int32
+)
+
+// DetectContainerLimits returns the cgroup limits of the current process. They are read once.
+func DetectContainerLimits() ContainerLimits {
+	containerLimitsOnce.Do(func() {
+		containerLimits = readCgroupLimits(shared.CgroupRoot)
+	})
+
+	return containerLimits
+}
+
+// SetCPUs limits the process to n CPUs, as the --cpus flag does: it sets GOMAXPROCS and makes
+// DefaultConcurrency return n. n <= 0 restores the detected concurrency default and leaves
+// GOMAXPROCS as it is.
+func SetCPUs(n int) {
+	if n <= 0 {
+		cpuOverride.Store(0)
+
+		return
+	}
+
+	runtime.GOMAXPROCS(n)
+	cpuOverride.Store(shared.SafeIntToInt32WithDefault(n, 1))
+}
+
+// DefaultConcurrency returns the default worker count: the CPU count set with SetCPUs, else the
+// container CPU limit rounded up when one is set, otherwise the number of host CPUs.
+func DefaultConcurrency() int {
+	if n := cpuOverride.Load(); n > 0 {
+		return int(n)
+	}
+
+	return concurrencyFor(DetectContainerLimits(), runtime.NumCPU())
+}
+
+// DefaultHardMemoryLimitMB returns the default hard memory limit: ConfigHardMemoryLimitMBDefault,
+// lowered to a share of the container memory limit when that would not fit.
+func DefaultHardMemoryLimitMB() int {
+	return hardMemoryLimitFor(DetectContainerLimits())
+}
+
+// LogContainerLimits logs the detected container limits at debug level.
+func LogContainerLimits() {
+	logger := shared.GetLogger()
+	limits := DetectContainerLimits()
+	if limits == (ContainerLimits{}) {
+		logger.Debug("No container CPU or memory limits detected")
+
+		return
+	}
+
+	logger.Debugf(
+		"Container limits: cpus=%.2f, memory=%dMB (default concurrency=%d, hardMemoryLimitMB=%d)",
+		limits.CPUs, limits.MemoryBytes/int64(shared.BytesPerMB), DefaultConcurrency(), DefaultHardMemoryLimitMB(),
+	)
+}
+
+// concurrencyFor derives the worker count from limits, never exceeding hostCPUs.
+func concurrencyFor(limits ContainerLimits, hostCPUs int) int {
+	if limits.CPUs <= 0 {
+		return hostCPUs
+	}
+
+	return max(1, min(hostCPUs, int(math.Ceil(limits.CPUs))))
+}
+
+// hardMemoryLimitFor derives the hard memory limit in MB from limits.
+func hardMemoryLimitFor(limits ContainerLimits) int {
+	if limits.MemoryBytes <= 0 {
+		return shared.ConfigHardMemoryLimitMBDefault
+	}
+
+	share := limits.MemoryBytes / int64(shared.BytesPerMB) * shared.ContainerMemoryLimitPercent / 100
+
+	return int(max(int64(shared.ConfigHardMemoryLimitMBMin), min(int64(shared.ConfigHardMemoryLimitMBDefault), share)))
+}
+
+// readCgroupLimits reads the cgroup v2 limits under root, falling back to cgroup v1.
+func readCgroupLimits(root string) ContainerLimits {
+	var limits ContainerLimits
+
+	// cgroup v2: "max 100000" or "<quota> <period>"
+	if fields := readCgroupFields(filepath.Join(root, "cpu.max")); len(fields) == 2 {
+		limits.CPUs = cpuQuota(fields[0], fields[1])
+	} else {
+		quota := readCgroupFields(filepath.Join(root, "cpu", "cpu.cfs_quota_us"))
+		period := readCgroupFields(filepath.Join(root, "cpu", "cpu.cfs_period_us"))
+		if len(quota) == 1 && len(period) == 1 {
+			limits.CPUs = cpuQuota(quota[0], period[0])
+		}
+	}
+
+	if fields := readCgroupFields(filepath.Join(root, "memory.max")); len(fields) == 1 {
+		limits.MemoryBytes = memoryLimit(fields[0])
+	} else if fields := readCgroupFields(filepath.Join(root, "memory", "memory.limit_in_bytes")); len(fields) == 1 {
+		limits.MemoryBytes = memoryLimit(fields[0])
+	}
+
+	return limits
+}
+
+// readCgroupFields returns the whitespace-separated fields of a cgroup file, or nil when it cannot be read.
+func readCgroupFields(path string) []string {
+	data, err := os.ReadFile(path) // #nosec G304 - path is a fixed cgroup file
+	if err != nil {
+		return nil
+	}
+
+	return strings.Fields(string(data))
+}
+
+// cpuQuota returns quota/period as a CPU count, or 0 when the quota is unlimited ("max" or -1).
+func cpuQuota(quota, period string) float64 {
+	q, qErr := strconv.ParseFloat(quota, 64)
+	p, pErr := strconv.ParseFloat(period, 64)
+	if qErr != nil || pErr != nil || q <= 0 || p <= 0 {
+		return 0
+	}
+
+	return q / p
+}
+
+// memoryLimit parses a cgroup memory limit, returning 0 for "max" and the near-MaxInt64
+// value cgroup v1 reports when no limit is set.
+func memoryLimit(value string) int64 {
+	limit, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || limit <= 0 || limit >= shared.CgroupUnlimitedMemory {
+		return 0
+	}
+
+	return limit
+}
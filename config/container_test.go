@@ -0,0 +1,133 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// TestReadCgroupLimits tests cgroup v2 and v1 limit parsing.
+func TestReadCgroupLimits(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  ContainerLimits
+	}{
+		{name: "no cgroup files", want: ContainerLimits{}},
+		{
+			name:  "v2 limits",
+			files: map[string]string{"cpu.max": "150000 100000\n", "memory.max": "268435456\n"},
+			want:  ContainerLimits{CPUs: 1.5, MemoryBytes: 256 * shared.BytesPerMB},
+		},
+		{
+			name:  "v2 unlimited",
+			files: map[string]string{"cpu.max": "max 100000\n", "memory.max": "max\n"},
+			want:  ContainerLimits{},
+		},
+		{
+			name: "v1 limits",
+			files: map[string]string{
+				"cpu/cpu.cfs_quota_us":         "200000\n",
+				"cpu/cpu.cfs_period_us":        "100000\n",
+				"memory/memory.limit_in_bytes": "1073741824\n",
+			},
+			want: ContainerLimits{CPUs: 2, MemoryBytes: 1024 * shared.BytesPerMB},
+		},
+		{
+			name: "v1 unlimited",
+			files: map[string]string{
+				"cpu/cpu.cfs_quota_us":         "-1\n",
+				"cpu/cpu.cfs_period_us":        "100000\n",
+				"memory/memory.limit_in_bytes": "9223372036854771712\n",
+			},
+			want: ContainerLimits{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			for name, content := range tt.files {
+				path := filepath.Join(root, name)
+				if err := os.MkdirAll(filepath.Dir(path), shared.TestDirPermission); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(content), shared.TestFilePermission); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if got := readCgroupLimits(root); got != tt.want {
+				t.Errorf("readCgroupLimits() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestContainerDefaults tests the concurrency and memory limit derived from container limits.
+func TestContainerDefaults(t *testing.T) {
+	tests := []struct {
+		name            string
+		limits          ContainerLimits
+		wantConcurrency int
+		wantMemoryMB    int
+	}{
+		{name: "no limits", wantConcurrency: 8, wantMemoryMB: shared.ConfigHardMemoryLimitMBDefault},
+		{
+			name:            "fractional CPUs round up",
+			limits:          ContainerLimits{CPUs: 1.5, MemoryBytes: 256 * shared.BytesPerMB},
+			wantConcurrency: 2,
+			wantMemoryMB:    192,
+		},
+		{
+			name:            "limits above the host and the default",
+			limits:          ContainerLimits{CPUs: 16, MemoryBytes: 4096 * shared.BytesPerMB},
+			wantConcurrency: 8,
+			wantMemoryMB:    shared.ConfigHardMemoryLimitMBDefault,
+		},
+		{
+			name:            "tiny memory limit",
+			limits:          ContainerLimits{CPUs: 0.25, MemoryBytes: 32 * shared.BytesPerMB},
+			wantConcurrency: 1,
+			wantMemoryMB:    shared.ConfigHardMemoryLimitMBMin,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := concurrencyFor(tt.limits, 8); got != tt.wantConcurrency {
+				t.Errorf("concurrencyFor() = %d, want %d", got, tt.wantConcurrency)
+			}
+			if got := hardMemoryLimitFor(tt.limits); got != tt.wantMemoryMB {
+				t.Errorf("hardMemoryLimitFor() = %d, want %d", got, tt.wantMemoryMB)
+			}
+		})
+	}
+}
+
+func TestSetCPUs(t *testing.T) {
+	procs := runtime.GOMAXPROCS(0)
+	t.Cleanup(func() {
+		runtime.GOMAXPROCS(procs)
+		SetCPUs(0)
+	})
+
+	SetCPUs(3)
+	if got := runtime.GOMAXPROCS(0); got != 3 {
+		t.Errorf("GOMAXPROCS = %d after SetCPUs(3), want 3", got)
+	}
+	if got := DefaultConcurrency(); got != 3 {
+		t.Errorf("DefaultConcurrency() = %d after SetCPUs(3), want 3", got)
+	}
+
+	SetCPUs(0)
+	if got, want := DefaultConcurrency(), concurrencyFor(DetectContainerLimits(), runtime.NumCPU()); got != want {
+		t.Errorf("DefaultConcurrency() = %d after SetCPUs(0), want the detected %d", got, want)
+	}
+	if got := runtime.GOMAXPROCS(0); got != 3 {
+		t.Errorf("SetCPUs(0) changed GOMAXPROCS to %d", got)
+	}
+}
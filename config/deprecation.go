@@ -0,0 +1,57 @@
+// Package config handles application configuration management.
+package config
+
+import (
+	"maps"
+	"slices"
+)
+
+// Deprecation describes a flag or config key that still works but will be removed in a future
+// release, and what to use instead.
+type Deprecation struct {
+	// Replacement is the flag or key taking over; a renamed config key is read into it, and a
+	// renamed flag becomes an alias of it.
+	Replacement string
+	// Hint tells users what to do when there is no direct replacement.
+	Hint string
+}
+
+// Message describes the deprecation of the flag or key shown as name, for example
+// "--old is deprecated; use --new instead".
+func (d Deprecation) Message(name, replacement string) string {
+	switch {
+	case d.Replacement != "":
+		return name + " is deprecated; use " + replacement + " instead"
+	case d.Hint != "":
+		return name + " is deprecated; " + d.Hint
+	default:
+		return name + " is deprecated and will be removed"
+	}
+}
+
+// deprecatedKeys lists the config keys that still work but will be removed, by key. Add an
+// entry with a Replacement when renaming a key, so existing config files keep working.
+var deprecatedKeys = map[string]Deprecation{}
+
+// applyDeprecatedKeys reads the deprecated keys the config file of s sets into their
+// replacements, unless the file sets the replacement too, and records a message for each.
+func (s *Settings) applyDeprecatedKeys() {
+	var inUse []string
+	for _, key := range slices.Sorted(maps.Keys(deprecatedKeys)) {
+		if !s.values().InConfig(key) {
+			continue
+		}
+		deprecation := deprecatedKeys[key]
+		if deprecation.Replacement != "" && !s.values().InConfig(deprecation.Replacement) {
+			s.values().Set(deprecation.Replacement, s.values().Get(key))
+		}
+		inUse = append(inUse, deprecation.Message(key, deprecation.Replacement))
+	}
+	s.deprecated = inUse
+}
+
+// DeprecatedKeysInUse returns a message for every deprecated key the config file of s sets,
+// sorted by key.
+func (s *Settings) DeprecatedKeysInUse() []string {
+	return slices.Clone(s.deprecated)
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/spf13/viper"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// TestDeprecatedKeys tests that deprecated keys set in the config file are read into their
+// replacements unless the file sets those too, and are reported once each.
+func TestDeprecatedKeys(t *testing.T) {
+	saved := deprecatedKeys
+	deprecatedKeys = map[string]Deprecation{
+		"maxFileSize":    {Replacement: shared.ConfigKeyFileSizeLimit},
+		"ui.legacyTheme": {Replacement: shared.ConfigKeyUITheme},
+		"ui.spinner":     {Hint: "set ui.progressStyle: spinner"},
+		"ui.unused":      {},
+	}
+	t.Cleanup(func() {
+		deprecatedKeys = saved
+		viper.Reset()
+		SetDefaultConfig()
+	})
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := "maxFileSize: 123456\nui:\n  legacyTheme: unicode\n  theme: ascii\n  spinner: true\n"
+	if err := os.WriteFile(path, []byte(content), shared.TestFilePermission); err != nil {
+		t.Fatalf("writing config: %v", err)
+	}
+
+	viper.Reset()
+	if err := LoadConfigFrom(path); err != nil {
+		t.Fatalf("LoadConfigFrom: %v", err)
+	}
+
+	if got := Global().FileSizeLimit(); got != 123456 {
+		t.Errorf("file size limit = %d, want 123456 from maxFileSize", got)
+	}
+	if got := Global().UITheme(); got != shared.UIThemeASCII {
+		t.Errorf("ui.theme = %q, want the file's ascii over ui.legacyTheme", got)
+	}
+	want := []string{
+		"maxFileSize is deprecated; use fileSizeLimit instead",
+		"ui.legacyTheme is deprecated; use ui.theme instead",
+		"ui.spinner is deprecated; set ui.progressStyle: spinner",
+	}
+	if got := Global().DeprecatedKeysInUse(); !slices.Equal(got, want) {
+		t.Errorf("DeprecatedKeysInUse() = %q, want %q", got, want)
+	}
+	settings, err := LoadSettings(path)
+	if err != nil {
+		t.Fatalf("LoadSettings: %v", err)
+	}
+	if got := settings.DeprecatedKeysInUse(); !slices.Equal(got, want) {
+		t.Errorf("DeprecatedKeysInUse() of loaded settings = %q, want %q", got, want)
+	}
+
+	viper.Reset()
+	if err := LoadConfigFrom(""); err != nil || len(Global().DeprecatedKeysInUse()) != 0 {
+		t.Errorf("defaults report deprecated keys %q (error %v), want none", Global().DeprecatedKeysInUse(), err)
+	}
+}
@@ -0,0 +1,119 @@
+// Package config handles application configuration management.
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// defaultConfigHeader opens the document DefaultConfigYAML returns.
+const defaultConfigHeader = `# gibidify configuration file.
+#
+# Every setting is listed at its default value and commented out; uncomment and edit the
+# ones to change. See config.example.yaml and the README for what each setting does.
+`
+
+// defaultRecorder records the default configuration values in the order they are set.
+type defaultRecorder struct {
+	keys   []string
+	values map[string]any
+}
+
+// SetDefault records value as the default of key.
+func (r *defaultRecorder) SetDefault(key string, value any) {
+	if _, ok := r.values[key]; !ok {
+		r.keys = append(r.keys, key)
+	}
+	r.values[key] = value
+}
+
+// DefaultConfigYAML returns a config file listing every setting at its default value,
+// commented out and grouped by section in the order the defaults are declared.
+func DefaultConfigYAML() ([]byte, error) {
+	recorder := &defaultRecorder{values: make(map[string]any)}
+	setDefaults(recorder)
+
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	for _, key := range recorder.keys {
+		valueNode := &yaml.Node{}
+		if err := valueNode.Encode(recorder.values[key]); err != nil {
+			return nil, shared.WrapError(
+				err, shared.ErrorTypeProcessing, shared.CodeProcessingEncode, "failed to encode default of "+key,
+			)
+		}
+		setNode(root, strings.Split(key, "."), valueNode)
+	}
+
+	var document bytes.Buffer
+	encoder := yaml.NewEncoder(&document)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(root); err != nil {
+		return nil, shared.WrapError(err, shared.ErrorTypeProcessing, shared.CodeProcessingEncode, "failed to encode config")
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, shared.WrapError(err, shared.ErrorTypeProcessing, shared.CodeProcessingEncode, "failed to encode config")
+	}
+
+	var out strings.Builder
+	out.WriteString(defaultConfigHeader)
+	for line := range strings.Lines(document.String()) {
+		if !strings.HasPrefix(line, " ") {
+			out.WriteString("\n")
+		}
+		out.WriteString("# " + line)
+	}
+
+	return []byte(out.String()), nil
+}
+
+// setNode sets the value at the dotted path parts in the mapping node, adding the
+// intermediate mappings in first-use order.
+func setNode(mapping *yaml.Node, parts []string, value *yaml.Node) {
+	for i := 0; i < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value != parts[0] {
+			continue
+		}
+		if len(parts) == 1 {
+			mapping.Content[i+1] = value
+		} else {
+			setNode(mapping.Content[i+1], parts[1:], value)
+		}
+
+		return
+	}
+
+	child := value
+	if len(parts) > 1 {
+		child = &yaml.Node{Kind: yaml.MappingNode}
+		setNode(child, parts[1:], value)
+	}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: parts[0]}, child)
+}
+
+// UserConfigFile returns the config file LoadConfig looks for first:
+// $XDG_CONFIG_HOME/gibidify/config.yaml, or $HOME/.config/gibidify/config.yaml when
+// XDG_CONFIG_HOME is not set.
+func UserConfigFile() (string, error) {
+	if xdgConfig := os.Getenv("XDG_CONFIG_HOME"); xdgConfig != "" {
+		if err := shared.ValidateConfigPath(xdgConfig); err != nil {
+			return "", err
+		}
+
+		return filepath.Join(xdgConfig, shared.AppName, "config.yaml"), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", shared.WrapError(
+			err, shared.ErrorTypeFileSystem, shared.CodeFSPathResolution, "failed to find home directory",
+		)
+	}
+
+	return filepath.Join(home, ".config", shared.AppName, "config.yaml"), nil
+}
@@ -0,0 +1,88 @@
+package config_test
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/ivuorinen/gibidify/config"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// TestDefaultConfigYAML tests that the generated config file is commented out, loads as a
+// valid configuration once uncommented and lists every ConfigKey in shared/constants.go.
+func TestDefaultConfigYAML(t *testing.T) {
+	testutil.ResetViperConfig(t, "")
+	document, err := config.DefaultConfigYAML()
+	testutil.MustSucceed(t, err, "DefaultConfigYAML")
+
+	// The header ends at the first blank line; every setting after it is commented out.
+	_, body, found := strings.Cut(string(document), "\n\n")
+	if !found {
+		t.Fatalf("DefaultConfigYAML() = %q, want a header followed by settings", document)
+	}
+	var uncommented strings.Builder
+	for line := range strings.Lines(body) {
+		if line != "\n" && !strings.HasPrefix(line, "# ") {
+			t.Fatalf("DefaultConfigYAML() line %q is not commented out", line)
+		}
+		uncommented.WriteString(strings.TrimPrefix(line, "# "))
+	}
+
+	path := testutil.CreateTestFile(t, t.TempDir(), "config.yaml", []byte(uncommented.String()))
+	_, err = config.LoadSettings(path)
+	testutil.MustSucceed(t, err, "loading the uncommented config")
+
+	var values map[string]any
+	testutil.MustSucceed(t, yaml.Unmarshal([]byte(uncommented.String()), &values), "parsing the uncommented config")
+	for _, key := range configKeys(t) {
+		if !hasKey(values, strings.Split(key, ".")) {
+			t.Errorf("DefaultConfigYAML() is missing %s", key)
+		}
+	}
+}
+
+// configKeys returns the values of the ConfigKey constants declared in shared/constants.go.
+func configKeys(t *testing.T) []string {
+	t.Helper()
+
+	file, err := parser.ParseFile(token.NewFileSet(), filepath.Join("..", "shared", "constants.go"), nil, 0)
+	testutil.MustSucceed(t, err, "parsing shared/constants.go")
+
+	var keys []string
+	ast.Inspect(file, func(node ast.Node) bool {
+		spec, ok := node.(*ast.ValueSpec)
+		if !ok || len(spec.Names) != 1 || len(spec.Values) != 1 || !strings.HasPrefix(spec.Names[0].Name, "ConfigKey") {
+			return true
+		}
+		if literal, ok := spec.Values[0].(*ast.BasicLit); ok && literal.Kind == token.STRING {
+			key, err := strconv.Unquote(literal.Value)
+			testutil.MustSucceed(t, err, "unquoting "+spec.Names[0].Name)
+			keys = append(keys, key)
+		}
+
+		return true
+	})
+	if len(keys) == 0 {
+		t.Fatal("found no ConfigKey constants in shared/constants.go")
+	}
+
+	return keys
+}
+
+// hasKey reports whether the nested YAML mapping values holds the dotted path parts.
+func hasKey(values map[string]any, parts []string) bool {
+	value, ok := values[parts[0]]
+	if !ok || len(parts) == 1 {
+		return ok
+	}
+	nested, ok := value.(map[string]any)
+
+	return ok && hasKey(nested, parts[1:])
+}
@@ -2,330 +2,905 @@
 package config
 
 import (
+	"slices"
 	"strings"
 
-	"github.com/spf13/viper"
-
 	"github.com/ivuorinen/gibidify/shared"
 )
 
 // FileSizeLimit returns the file size limit from configuration.
 // Default: ConfigFileSizeLimitDefault (5MB).
-func FileSizeLimit() int64 {
-	return viper.GetInt64(shared.ConfigKeyFileSizeLimit)
+func (s *Settings) FileSizeLimit() int64 {
+	return s.values().GetInt64(shared.ConfigKeyFileSizeLimit)
 }
 
 // IgnoredDirectories returns the list of directories to ignore.
 // Default: ConfigIgnoredDirectoriesDefault.
-func IgnoredDirectories() []string {
-	return viper.GetStringSlice(shared.ConfigKeyIgnoreDirectories)
+func (s *Settings) IgnoredDirectories() []string {
+	return s.values().GetStringSlice(shared.ConfigKeyIgnoreDirectories)
 }
 
 // MaxConcurrency returns the maximum concurrency level.
 // Returns 0 if not set (caller should determine appropriate default).
-func MaxConcurrency() int {
-	return viper.GetInt(shared.ConfigKeyMaxConcurrency)
+func (s *Settings) MaxConcurrency() int {
+	return s.values().GetInt(shared.ConfigKeyMaxConcurrency)
 }
 
 // SupportedFormats returns the list of supported output formats.
 // Returns empty slice if not set.
-func SupportedFormats() []string {
-	return viper.GetStringSlice(shared.ConfigKeySupportedFormats)
+func (s *Settings) SupportedFormats() []string {
+	return s.values().GetStringSlice(shared.ConfigKeySupportedFormats)
 }
 
 // FilePatterns returns the list of file patterns.
 // Returns empty slice if not set.
-func FilePatterns() []string {
-	return viper.GetStringSlice(shared.ConfigKeyFilePatterns)
+func (s *Settings) FilePatterns() []string {
+	return s.values().GetStringSlice(shared.ConfigKeyFilePatterns)
 }
 
-// IsValidFormat checks if the given format is valid.
-func IsValidFormat(format string) bool {
+// IsValidFormat checks if the given format is valid: a built-in format or an output.plugins format.
+func (s *Settings) IsValidFormat(format string) bool {
 	format = strings.ToLower(strings.TrimSpace(format))
-	supportedFormats := map[string]bool{
-		shared.FormatJSON:     true,
-		shared.FormatYAML:     true,
-		shared.FormatMarkdown: true,
+	if IsBuiltinFormat(format) {
+		return true
 	}
+	_, ok := s.OutputPlugin(format)
+
+	return ok
+}
 
-	return supportedFormats[format]
+// IsBuiltinFormat reports whether format is one of the formats gibidify writes itself.
+func IsBuiltinFormat(format string) bool {
+	switch format {
+	case shared.FormatJSON, shared.FormatYAML, shared.FormatMarkdown, shared.FormatPlain, shared.FormatPDF,
+		shared.FormatZip:
+		return true
+	default:
+		return false
+	}
 }
 
 // FileTypesEnabled returns whether file types are enabled.
 // Default: ConfigFileTypesEnabledDefault (true).
-func FileTypesEnabled() bool {
-	return viper.GetBool(shared.ConfigKeyFileTypesEnabled)
+func (s *Settings) FileTypesEnabled() bool {
+	return s.values().GetBool(shared.ConfigKeyFileTypesEnabled)
 }
 
 // CustomImageExtensions returns custom image extensions.
 // Default: ConfigCustomImageExtensionsDefault (empty).
-func CustomImageExtensions() []string {
-	return viper.GetStringSlice(shared.ConfigKeyFileTypesCustomImageExtensions)
+func (s *Settings) CustomImageExtensions() []string {
+	return s.values().GetStringSlice(shared.ConfigKeyFileTypesCustomImageExtensions)
 }
 
 // CustomBinaryExtensions returns custom binary extensions.
 // Default: ConfigCustomBinaryExtensionsDefault (empty).
-func CustomBinaryExtensions() []string {
-	return viper.GetStringSlice(shared.ConfigKeyFileTypesCustomBinaryExtensions)
+func (s *Settings) CustomBinaryExtensions() []string {
+	return s.values().GetStringSlice(shared.ConfigKeyFileTypesCustomBinaryExtensions)
 }
 
 // CustomLanguages returns custom language mappings.
 // Default: ConfigCustomLanguagesDefault (empty).
-func CustomLanguages() map[string]string {
-	return viper.GetStringMapString(shared.ConfigKeyFileTypesCustomLanguages)
+func (s *Settings) CustomLanguages() map[string]string {
+	return s.values().GetStringMapString(shared.ConfigKeyFileTypesCustomLanguages)
 }
 
 // DisabledImageExtensions returns disabled image extensions.
 // Default: ConfigDisabledImageExtensionsDefault (empty).
-func DisabledImageExtensions() []string {
-	return viper.GetStringSlice(shared.ConfigKeyFileTypesDisabledImageExtensions)
+func (s *Settings) DisabledImageExtensions() []string {
+	return s.values().GetStringSlice(shared.ConfigKeyFileTypesDisabledImageExtensions)
 }
 
 // DisabledBinaryExtensions returns disabled binary extensions.
 // Default: ConfigDisabledBinaryExtensionsDefault (empty).
-func DisabledBinaryExtensions() []string {
-	return viper.GetStringSlice(shared.ConfigKeyFileTypesDisabledBinaryExtensions)
+func (s *Settings) DisabledBinaryExtensions() []string {
+	return s.values().GetStringSlice(shared.ConfigKeyFileTypesDisabledBinaryExtensions)
 }
 
 // DisabledLanguageExtensions returns disabled language extensions.
 // Default: ConfigDisabledLanguageExtensionsDefault (empty).
-func DisabledLanguageExtensions() []string {
-	return viper.GetStringSlice(shared.ConfigKeyFileTypesDisabledLanguageExts)
+func (s *Settings) DisabledLanguageExtensions() []string {
+	return s.values().GetStringSlice(shared.ConfigKeyFileTypesDisabledLanguageExts)
+}
+
+// ContentSniffingEnabled returns whether files with text extensions are checked for binary content.
+// Default: ConfigContentSniffingEnabledDefault (true).
+func (s *Settings) ContentSniffingEnabled() bool {
+	return s.values().GetBool(shared.ConfigKeyContentSniffingEnabled)
 }
 
 // Backpressure getters
 
 // BackpressureEnabled returns whether backpressure is enabled.
 // Default: ConfigBackpressureEnabledDefault (true).
-func BackpressureEnabled() bool {
-	return viper.GetBool(shared.ConfigKeyBackpressureEnabled)
+func (s *Settings) BackpressureEnabled() bool {
+	return s.values().GetBool(shared.ConfigKeyBackpressureEnabled)
 }
 
 // MaxPendingFiles returns the maximum pending files.
 // Default: ConfigMaxPendingFilesDefault (1000).
-func MaxPendingFiles() int {
-	return viper.GetInt(shared.ConfigKeyBackpressureMaxPendingFiles)
+func (s *Settings) MaxPendingFiles() int {
+	return s.values().GetInt(shared.ConfigKeyBackpressureMaxPendingFiles)
 }
 
 // MaxPendingWrites returns the maximum pending writes.
 // Default: ConfigMaxPendingWritesDefault (100).
-func MaxPendingWrites() int {
-	return viper.GetInt(shared.ConfigKeyBackpressureMaxPendingWrites)
+func (s *Settings) MaxPendingWrites() int {
+	return s.values().GetInt(shared.ConfigKeyBackpressureMaxPendingWrites)
 }
 
 // MaxMemoryUsage returns the maximum memory usage.
 // Default: ConfigMaxMemoryUsageDefault (100MB).
-func MaxMemoryUsage() int64 {
-	return viper.GetInt64(shared.ConfigKeyBackpressureMaxMemoryUsage)
+func (s *Settings) MaxMemoryUsage() int64 {
+	return s.values().GetInt64(shared.ConfigKeyBackpressureMaxMemoryUsage)
 }
 
 // MemoryCheckInterval returns the memory check interval.
 // Default: ConfigMemoryCheckIntervalDefault (1000 files).
-func MemoryCheckInterval() int {
-	return viper.GetInt(shared.ConfigKeyBackpressureMemoryCheckInt)
+func (s *Settings) MemoryCheckInterval() int {
+	return s.values().GetInt(shared.ConfigKeyBackpressureMemoryCheckInt)
 }
 
 // Resource limits getters
 
 // ResourceLimitsEnabled returns whether resource limits are enabled.
 // Default: ConfigResourceLimitsEnabledDefault (true).
-func ResourceLimitsEnabled() bool {
-	return viper.GetBool(shared.ConfigKeyResourceLimitsEnabled)
+func (s *Settings) ResourceLimitsEnabled() bool {
+	return s.values().GetBool(shared.ConfigKeyResourceLimitsEnabled)
 }
 
 // MaxFiles returns the maximum number of files.
 // Default: ConfigMaxFilesDefault (10000).
-func MaxFiles() int {
-	return viper.GetInt(shared.ConfigKeyResourceLimitsMaxFiles)
+func (s *Settings) MaxFiles() int {
+	return s.values().GetInt(shared.ConfigKeyResourceLimitsMaxFiles)
 }
 
 // MaxTotalSize returns the maximum total size.
 // Default: ConfigMaxTotalSizeDefault (1GB).
-func MaxTotalSize() int64 {
-	return viper.GetInt64(shared.ConfigKeyResourceLimitsMaxTotalSize)
+func (s *Settings) MaxTotalSize() int64 {
+	return s.values().GetInt64(shared.ConfigKeyResourceLimitsMaxTotalSize)
 }
 
 // FileProcessingTimeoutSec returns the file processing timeout in seconds.
 // Default: ConfigFileProcessingTimeoutSecDefault (30 seconds).
-func FileProcessingTimeoutSec() int {
-	return viper.GetInt(shared.ConfigKeyResourceLimitsFileProcessingTO)
+func (s *Settings) FileProcessingTimeoutSec() int {
+	return s.values().GetInt(shared.ConfigKeyResourceLimitsFileProcessingTO)
 }
 
 // OverallTimeoutSec returns the overall timeout in seconds.
 // Default: ConfigOverallTimeoutSecDefault (3600 seconds).
-func OverallTimeoutSec() int {
-	return viper.GetInt(shared.ConfigKeyResourceLimitsOverallTO)
+func (s *Settings) OverallTimeoutSec() int {
+	return s.values().GetInt(shared.ConfigKeyResourceLimitsOverallTO)
 }
 
 // MaxConcurrentReads returns the maximum concurrent reads.
 // Default: ConfigMaxConcurrentReadsDefault (10).
-func MaxConcurrentReads() int {
-	return viper.GetInt(shared.ConfigKeyResourceLimitsMaxConcurrentReads)
+func (s *Settings) MaxConcurrentReads() int {
+	return s.values().GetInt(shared.ConfigKeyResourceLimitsMaxConcurrentReads)
 }
 
 // RateLimitFilesPerSec returns the rate limit files per second.
 // Default: ConfigRateLimitFilesPerSecDefault (0 = disabled).
-func RateLimitFilesPerSec() int {
-	return viper.GetInt(shared.ConfigKeyResourceLimitsRateLimitFilesPerSec)
+func (s *Settings) RateLimitFilesPerSec() int {
+	return s.values().GetInt(shared.ConfigKeyResourceLimitsRateLimitFilesPerSec)
 }
 
 // HardMemoryLimitMB returns the hard memory limit in MB.
-// Default: ConfigHardMemoryLimitMBDefault (512MB).
-func HardMemoryLimitMB() int {
-	return viper.GetInt(shared.ConfigKeyResourceLimitsHardMemoryLimitMB)
+// Default: ConfigHardMemoryLimitMBDefault (512MB), or 75% of the container memory limit when lower.
+func (s *Settings) HardMemoryLimitMB() int {
+	return s.values().GetInt(shared.ConfigKeyResourceLimitsHardMemoryLimitMB)
 }
 
 // EnableGracefulDegradation returns whether graceful degradation is enabled.
 // Default: ConfigEnableGracefulDegradationDefault (true).
-func EnableGracefulDegradation() bool {
-	return viper.GetBool(shared.ConfigKeyResourceLimitsEnableGracefulDeg)
+func (s *Settings) EnableGracefulDegradation() bool {
+	return s.values().GetBool(shared.ConfigKeyResourceLimitsEnableGracefulDeg)
 }
 
 // EnableResourceMonitoring returns whether resource monitoring is enabled.
 // Default: ConfigEnableResourceMonitoringDefault (true).
-func EnableResourceMonitoring() bool {
-	return viper.GetBool(shared.ConfigKeyResourceLimitsEnableMonitoring)
+func (s *Settings) EnableResourceMonitoring() bool {
+	return s.values().GetBool(shared.ConfigKeyResourceLimitsEnableMonitoring)
 }
 
 // Template system getters
 
 // OutputTemplate returns the selected output template name.
 // Default: ConfigOutputTemplateDefault (empty string).
-func OutputTemplate() string {
-	return viper.GetString(shared.ConfigKeyOutputTemplate)
+func (s *Settings) OutputTemplate() string {
+	return s.values().GetString(shared.ConfigKeyOutputTemplate)
 }
 
 // metadataBool is a helper for metadata boolean configuration values.
 // All metadata flags default to false.
-func metadataBool(key string) bool {
-	return viper.GetBool("output.metadata." + key)
+func (s *Settings) metadataBool(key string) bool {
+	return s.values().GetBool("output.metadata." + key)
 }
 
 // TemplateMetadataIncludeStats returns whether to include stats in metadata.
-func TemplateMetadataIncludeStats() bool {
-	return metadataBool("includeStats")
+func (s *Settings) TemplateMetadataIncludeStats() bool {
+	return s.metadataBool("includeStats")
 }
 
 // TemplateMetadataIncludeTimestamp returns whether to include timestamp in metadata.
-func TemplateMetadataIncludeTimestamp() bool {
-	return metadataBool("includeTimestamp")
+func (s *Settings) TemplateMetadataIncludeTimestamp() bool {
+	return s.metadataBool("includeTimestamp")
 }
 
 // TemplateMetadataIncludeFileCount returns whether to include file count in metadata.
-func TemplateMetadataIncludeFileCount() bool {
-	return metadataBool("includeFileCount")
+func (s *Settings) TemplateMetadataIncludeFileCount() bool {
+	return s.metadataBool("includeFileCount")
 }
 
 // TemplateMetadataIncludeSourcePath returns whether to include source path in metadata.
-func TemplateMetadataIncludeSourcePath() bool {
-	return metadataBool("includeSourcePath")
+func (s *Settings) TemplateMetadataIncludeSourcePath() bool {
+	return s.metadataBool("includeSourcePath")
 }
 
 // TemplateMetadataIncludeFileTypes returns whether to include file types in metadata.
-func TemplateMetadataIncludeFileTypes() bool {
-	return metadataBool("includeFileTypes")
+func (s *Settings) TemplateMetadataIncludeFileTypes() bool {
+	return s.metadataBool("includeFileTypes")
 }
 
 // TemplateMetadataIncludeProcessingTime returns whether to include processing time in metadata.
-func TemplateMetadataIncludeProcessingTime() bool {
-	return metadataBool("includeProcessingTime")
+func (s *Settings) TemplateMetadataIncludeProcessingTime() bool {
+	return s.metadataBool("includeProcessingTime")
 }
 
 // TemplateMetadataIncludeTotalSize returns whether to include total size in metadata.
-func TemplateMetadataIncludeTotalSize() bool {
-	return metadataBool("includeTotalSize")
+func (s *Settings) TemplateMetadataIncludeTotalSize() bool {
+	return s.metadataBool("includeTotalSize")
 }
 
 // TemplateMetadataIncludeMetrics returns whether to include metrics in metadata.
-func TemplateMetadataIncludeMetrics() bool {
-	return metadataBool("includeMetrics")
+func (s *Settings) TemplateMetadataIncludeMetrics() bool {
+	return s.metadataBool("includeMetrics")
 }
 
 // markdownBool is a helper for markdown boolean configuration values.
 // All markdown flags default to false.
-func markdownBool(key string) bool {
-	return viper.GetBool("output.markdown." + key)
+func (s *Settings) markdownBool(key string) bool {
+	return s.values().GetBool("output.markdown." + key)
 }
 
 // TemplateMarkdownUseCodeBlocks returns whether to use code blocks in markdown.
-func TemplateMarkdownUseCodeBlocks() bool {
-	return markdownBool("useCodeBlocks")
+func (s *Settings) TemplateMarkdownUseCodeBlocks() bool {
+	return s.markdownBool("useCodeBlocks")
 }
 
 // TemplateMarkdownIncludeLanguage returns whether to include language in code blocks.
-func TemplateMarkdownIncludeLanguage() bool {
-	return markdownBool("includeLanguage")
+func (s *Settings) TemplateMarkdownIncludeLanguage() bool {
+	return s.markdownBool("includeLanguage")
 }
 
 // TemplateMarkdownHeaderLevel returns the header level for file sections.
 // Default: ConfigMarkdownHeaderLevelDefault (0).
-func TemplateMarkdownHeaderLevel() int {
-	return viper.GetInt(shared.ConfigKeyOutputMarkdownHeaderLevel)
+func (s *Settings) TemplateMarkdownHeaderLevel() int {
+	return s.values().GetInt(shared.ConfigKeyOutputMarkdownHeaderLevel)
 }
 
 // TemplateMarkdownTableOfContents returns whether to include table of contents.
-func TemplateMarkdownTableOfContents() bool {
-	return markdownBool("tableOfContents")
+func (s *Settings) TemplateMarkdownTableOfContents() bool {
+	return s.markdownBool("tableOfContents")
 }
 
 // TemplateMarkdownUseCollapsible returns whether to use collapsible sections.
-func TemplateMarkdownUseCollapsible() bool {
-	return markdownBool("useCollapsible")
+func (s *Settings) TemplateMarkdownUseCollapsible() bool {
+	return s.markdownBool("useCollapsible")
 }
 
 // TemplateMarkdownSyntaxHighlighting returns whether to enable syntax highlighting.
-func TemplateMarkdownSyntaxHighlighting() bool {
-	return markdownBool("syntaxHighlighting")
+func (s *Settings) TemplateMarkdownSyntaxHighlighting() bool {
+	return s.markdownBool("syntaxHighlighting")
 }
 
 // TemplateMarkdownLineNumbers returns whether to include line numbers.
-func TemplateMarkdownLineNumbers() bool {
-	return markdownBool("lineNumbers")
+func (s *Settings) TemplateMarkdownLineNumbers() bool {
+	return s.markdownBool("lineNumbers")
 }
 
 // TemplateMarkdownFoldLongFiles returns whether to fold long files.
-func TemplateMarkdownFoldLongFiles() bool {
-	return markdownBool("foldLongFiles")
+func (s *Settings) TemplateMarkdownFoldLongFiles() bool {
+	return s.markdownBool("foldLongFiles")
 }
 
-// TemplateMarkdownMaxLineLength returns the maximum line length.
+// TemplateMarkdownMaxLineLength returns the length, in characters, past which markdown file
+// sections are wrapped: prose always, code with TemplateMarkdownWrapCode.
 // Default: ConfigMarkdownMaxLineLengthDefault (0 = unlimited).
-func TemplateMarkdownMaxLineLength() int {
-	return viper.GetInt(shared.ConfigKeyOutputMarkdownMaxLineLen)
+func (s *Settings) TemplateMarkdownMaxLineLength() int {
+	return s.values().GetInt(shared.ConfigKeyOutputMarkdownMaxLineLen)
+}
+
+// TemplateMarkdownWrapCode returns whether code lines longer than the maximum line length are hard-wrapped.
+// Default: ConfigMarkdownWrapCodeDefault (false).
+func (s *Settings) TemplateMarkdownWrapCode() bool {
+	return s.values().GetBool(shared.ConfigKeyOutputMarkdownWrapCode)
+}
+
+// TemplateMarkdownWrapMarker returns the marker ending every hard-wrapped code line but the last.
+// Default: ConfigMarkdownWrapMarkerDefault (↩).
+func (s *Settings) TemplateMarkdownWrapMarker() string {
+	return s.values().GetString(shared.ConfigKeyOutputMarkdownWrapMarker)
 }
 
 // TemplateCustomCSS returns custom CSS for markdown output.
 // Default: ConfigMarkdownCustomCSSDefault (empty string).
-func TemplateCustomCSS() string {
-	return viper.GetString(shared.ConfigKeyOutputMarkdownCustomCSS)
+func (s *Settings) TemplateCustomCSS() string {
+	return s.values().GetString(shared.ConfigKeyOutputMarkdownCustomCSS)
+}
+
+// TemplateMarkdownSectionPlaceholder returns the block written after every markdown file section.
+// Default: ConfigMarkdownSectionPlaceholderDefault (empty = none).
+func (s *Settings) TemplateMarkdownSectionPlaceholder() string {
+	return s.values().GetString(shared.ConfigKeyOutputMarkdownSectionPlaceholder)
+}
+
+// OutputPlainDelimiter returns the line starting every file of a plain bundle, with {{path}}
+// standing for the file path.
+// Default: ConfigPlainDelimiterDefault (===== {{path}} =====).
+func (s *Settings) OutputPlainDelimiter() string {
+	return s.values().GetString(shared.ConfigKeyOutputPlainDelimiter)
+}
+
+// OutputPDFPageSize returns the page size of PDF bundles: a4 or letter.
+// Default: ConfigPDFPageSizeDefault (a4).
+func (s *Settings) OutputPDFPageSize() string {
+	return strings.ToLower(s.values().GetString(shared.ConfigKeyOutputPDFPageSize))
+}
+
+// OutputPDFFontSize returns the font size of PDF bundles in points.
+// Default: ConfigPDFFontSizeDefault (8).
+func (s *Settings) OutputPDFFontSize() int {
+	return s.values().GetInt(shared.ConfigKeyOutputPDFFontSize)
+}
+
+// FormatPlugin is an external format registered in output.plugins: the command, program first,
+// that reads the files of the bundle as NDJSON events on stdin and writes the bundle to stdout.
+type FormatPlugin struct {
+	Command []string `mapstructure:"command"`
+}
+
+// OutputPlugins returns the external format plugins by format name. Plugins that cannot be
+// decoded are left out; validation reports them.
+// Default: ConfigOutputPluginsDefault (empty).
+func (s *Settings) OutputPlugins() map[string]FormatPlugin {
+	plugins := make(map[string]FormatPlugin)
+	if err := s.values().UnmarshalKey(shared.ConfigKeyOutputPlugins, &plugins); err != nil {
+		return nil
+	}
+
+	return plugins
+}
+
+// OutputPlugin returns the external format plugin registered for format, if there is one.
+func (s *Settings) OutputPlugin(format string) (FormatPlugin, bool) {
+	plugin, ok := s.OutputPlugins()[format]
+
+	return plugin, ok && len(plugin.Command) > 0
 }
 
 // TemplateCustomHeader returns custom header template.
 // Default: ConfigCustomHeaderDefault (empty string).
-func TemplateCustomHeader() string {
-	return viper.GetString(shared.ConfigKeyOutputCustomHeader)
+func (s *Settings) TemplateCustomHeader() string {
+	return s.values().GetString(shared.ConfigKeyOutputCustomHeader)
 }
 
 // TemplateCustomFooter returns custom footer template.
 // Default: ConfigCustomFooterDefault (empty string).
-func TemplateCustomFooter() string {
-	return viper.GetString(shared.ConfigKeyOutputCustomFooter)
+func (s *Settings) TemplateCustomFooter() string {
+	return s.values().GetString(shared.ConfigKeyOutputCustomFooter)
 }
 
 // TemplateCustomFileHeader returns custom file header template.
 // Default: ConfigCustomFileHeaderDefault (empty string).
-func TemplateCustomFileHeader() string {
-	return viper.GetString(shared.ConfigKeyOutputCustomFileHeader)
+func (s *Settings) TemplateCustomFileHeader() string {
+	return s.values().GetString(shared.ConfigKeyOutputCustomFileHeader)
 }
 
 // TemplateCustomFileFooter returns custom file footer template.
 // Default: ConfigCustomFileFooterDefault (empty string).
-func TemplateCustomFileFooter() string {
-	return viper.GetString(shared.ConfigKeyOutputCustomFileFooter)
+func (s *Settings) TemplateCustomFileFooter() string {
+	return s.values().GetString(shared.ConfigKeyOutputCustomFileFooter)
 }
 
 // TemplateVariables returns custom template variables.
 // Default: ConfigTemplateVariablesDefault (empty map).
-func TemplateVariables() map[string]string {
-	return viper.GetStringMapString(shared.ConfigKeyOutputVariables)
+func (s *Settings) TemplateVariables() map[string]string {
+	return s.values().GetStringMapString(shared.ConfigKeyOutputVariables)
+}
+
+// OutputPrelude returns the context documents placed before the file sections.
+// Default: ConfigOutputPreludeDefault (empty).
+func (s *Settings) OutputPrelude() []string {
+	return s.values().GetStringSlice(shared.ConfigKeyOutputPrelude)
+}
+
+// OutputSourceSpans returns whether JSON file entries record the offsets of their content.
+// Default: ConfigOutputSourceSpansDefault (false).
+func (s *Settings) OutputSourceSpans() bool {
+	return s.values().GetBool(shared.ConfigKeyOutputSourceSpans)
+}
+
+// OutputFileIDs returns whether every entry carries its stable short ID in its metadata.
+// Default: ConfigOutputFileIDsDefault (false).
+func (s *Settings) OutputFileIDs() bool {
+	return s.values().GetBool(shared.ConfigKeyOutputFileIDs)
+}
+
+// OutputAppendRunSummary returns whether the bundle ends with a summary of its files, languages,
+// summarized files and files left out.
+// Default: ConfigOutputAppendRunSummaryDefault (false).
+func (s *Settings) OutputAppendRunSummary() bool {
+	return s.values().GetBool(shared.ConfigKeyOutputAppendRunSummary)
+}
+
+// OutputConfigProvenance returns whether the bundle starts with the settings that differ from
+// the defaults, so recipients can see which filters and transforms shaped it.
+// Default: ConfigOutputConfigProvenanceDefault (false).
+func (s *Settings) OutputConfigProvenance() bool {
+	return s.values().GetBool(shared.ConfigKeyOutputConfigProvenance)
+}
+
+// OutputNormalizeLineEndings returns the line-ending style file content is converted to: lf, crlf or preserve.
+// Default: ConfigOutputNormalizeLineEndingsDefault ("preserve").
+func (s *Settings) OutputNormalizeLineEndings() string {
+	return s.values().GetString(shared.ConfigKeyOutputNormalizeLineEndings)
+}
+
+// OutputWhitespaceIndent returns the indentation conversion for language: preserve, spaces or tabs.
+// Default: ConfigOutputWhitespaceIndentDefault ("preserve").
+func (s *Settings) OutputWhitespaceIndent(language string) string {
+	return s.values().GetString(s.whitespaceKey(shared.ConfigKeyOutputWhitespaceIndent, language))
+}
+
+// OutputWhitespaceTabWidth returns the tab stop width used when converting indentation for language.
+// Default: ConfigOutputWhitespaceTabWidthDefault (4).
+func (s *Settings) OutputWhitespaceTabWidth(language string) int {
+	return s.values().GetInt(s.whitespaceKey(shared.ConfigKeyOutputWhitespaceTabWidth, language))
+}
+
+// OutputWhitespaceTrimTrailing returns whether trailing whitespace is trimmed for language.
+// Default: ConfigOutputWhitespaceTrimTrailingDefault (false).
+func (s *Settings) OutputWhitespaceTrimTrailing(language string) bool {
+	return s.values().GetBool(s.whitespaceKey(shared.ConfigKeyOutputWhitespaceTrimTrailing, language))
+}
+
+// OutputCompactEnabled returns whether file content is compacted by the output.compact rules.
+// Default: ConfigOutputCompactEnabledDefault (false).
+func (s *Settings) OutputCompactEnabled() bool {
+	return s.values().GetBool(shared.ConfigKeyOutputCompactEnabled)
+}
+
+// OutputCompactCollapseBlankLines returns whether compacting collapses runs of blank lines into one.
+// Default: ConfigOutputCompactCollapseBlankLinesDefault (true).
+func (s *Settings) OutputCompactCollapseBlankLines() bool {
+	return s.values().GetBool(shared.ConfigKeyOutputCompactCollapseBlankLines)
+}
+
+// OutputCompactTrimTrailing returns whether compacting trims the whitespace at the end of every line.
+// Default: ConfigOutputCompactTrimTrailingDefault (true).
+func (s *Settings) OutputCompactTrimTrailing() bool {
+	return s.values().GetBool(shared.ConfigKeyOutputCompactTrimTrailing)
+}
+
+// OutputCompactMaxLineLength returns the length in bytes past which compacting truncates a line,
+// marking the cut with an ellipsis. 0 keeps long lines whole.
+// Default: ConfigOutputCompactMaxLineLengthDefault (0).
+func (s *Settings) OutputCompactMaxLineLength() int {
+	return s.values().GetInt(shared.ConfigKeyOutputCompactMaxLineLength)
+}
+
+// OutputSanitizeStripBOM returns whether UTF-8 byte order marks are stripped from file content.
+// Default: ConfigOutputSanitizeStripBOMDefault (true).
+func (s *Settings) OutputSanitizeStripBOM() bool {
+	return s.values().GetBool(shared.ConfigKeyOutputSanitizeStripBOM)
+}
+
+// OutputSanitizeInvisibleChars returns whether zero-width and bidi control characters are removed.
+// Default: ConfigOutputSanitizeInvisibleDefault (false).
+func (s *Settings) OutputSanitizeInvisibleChars() bool {
+	return s.values().GetBool(shared.ConfigKeyOutputSanitizeInvisible)
+}
+
+// OutputBufferSize returns the size in bytes of the buffer between the format writers and the
+// output file; 0 writes every piece of the bundle straight to the file.
+// Default: ConfigOutputBufferSizeDefault (65536).
+func (s *Settings) OutputBufferSize() int {
+	return s.values().GetInt(shared.ConfigKeyOutputBufferSize)
+}
+
+// TokensEstimate returns whether every file entry gets its estimated LLM token count and the
+// estimation method as metadata.
+// Default: ConfigTokensEstimateDefault (false).
+func (s *Settings) TokensEstimate() bool {
+	return s.values().GetBool(shared.ConfigKeyTokensEstimate)
+}
+
+// CodeMetricsEnabled returns whether every file entry gets its code and comment line counts and
+// approximate cyclomatic complexity as metadata.
+// Default: ConfigCodeMetricsEnabledDefault (false).
+func (s *Settings) CodeMetricsEnabled() bool {
+	return s.values().GetBool(shared.ConfigKeyCodeMetricsEnabled)
+}
+
+// TokensBytesPerToken returns the average number of bytes per LLM token in content of language:
+// its tokens.languages calibration when one is configured or built in, and tokens.bytesPerToken
+// otherwise.
+// Default: ConfigTokensLanguagesDefault, then ConfigTokensBytesPerTokenDefault (4.0).
+func (s *Settings) TokensBytesPerToken(language string) float64 {
+	if s.TokensCalibrated(language) {
+		return s.values().GetFloat64(shared.ConfigKeyTokensLanguages + "." + language)
+	}
+
+	return s.values().GetFloat64(shared.ConfigKeyTokensBytesPerToken)
+}
+
+// TokensCalibrated reports whether tokens.languages has a calibration for language.
+func (s *Settings) TokensCalibrated(language string) bool {
+	return language != "" && s.values().IsSet(shared.ConfigKeyTokensLanguages+"."+language)
+}
+
+// CollectionRollups returns the gitignore-style globs, such as assets/**, whose files are each
+// summarized as a single entry listing them instead of being embedded.
+// Default: ConfigCollectionRollupsDefault (empty).
+func (s *Settings) CollectionRollups() []string {
+	return s.values().GetStringSlice(shared.ConfigKeyCollectionRollups)
+}
+
+// CollectionRespectGitignore returns whether collection leaves out the files git ignores:
+// those matched by .gitignore files, including the ones above the source directory in its
+// repository, and by .git/info/exclude.
+// Default: ConfigCollectionRespectGitignoreDefault (true).
+func (s *Settings) CollectionRespectGitignore() bool {
+	return s.values().GetBool(shared.ConfigKeyCollectionRespectGitignore)
+}
+
+// CollectionGlobalGitignore returns whether collection also leaves out the files matched by the
+// user's global git excludes file (core.excludesFile, or $XDG_CONFIG_HOME/git/ignore). It only
+// applies while the files git ignores are left out.
+// Default: ConfigCollectionGlobalGitignoreDefault (true).
+func (s *Settings) CollectionGlobalGitignore() bool {
+	return s.values().GetBool(shared.ConfigKeyCollectionGlobalGitignore)
+}
+
+// CollectionOutlierPercent returns the share of the collected size, in percent, above which a
+// single file is an outlier the run offers to exclude. 0 disables the check.
+// Default: ConfigCollectionOutlierPercentDefault (50).
+func (s *Settings) CollectionOutlierPercent() int {
+	return s.values().GetInt(shared.ConfigKeyCollectionOutlierPercent)
+}
+
+// OrderPriority returns the gitignore-style globs --order priority puts files in order of:
+// files matching the first glob come first, and files matching none come last.
+// Default: ConfigOrderPriorityDefault (empty).
+func (s *Settings) OrderPriority() []string {
+	return s.values().GetStringSlice(shared.ConfigKeyOrderPriority)
+}
+
+// Budget is the size budget of a directory glob: the bytes and estimated tokens its files may
+// take together, 0 for no limit, and the fallback applied to them when they take more.
+type Budget struct {
+	MaxBytes  int64  `mapstructure:"maxBytes"`
+	MaxTokens int64  `mapstructure:"maxTokens"`
+	Fallback  string `mapstructure:"fallback"`
+}
+
+// Budgets returns the size budgets by gitignore-style directory glob, such as docs/**. Budgets
+// that cannot be decoded are left out; validation reports them.
+// Default: ConfigBudgetsDefault (empty).
+func (s *Settings) Budgets() map[string]Budget {
+	budgets := make(map[string]Budget)
+	if err := s.values().UnmarshalKey(shared.ConfigKeyBudgets, &budgets); err != nil {
+		return nil
+	}
+
+	return budgets
+}
+
+// WasmTransform is a user transform compiled to a WASI module, run on the files whose
+// path relative to the source matches its doublestar glob.
+type WasmTransform struct {
+	Pattern string `mapstructure:"pattern"`
+	Module  string `mapstructure:"module"`
+}
+
+// TransformsWasm returns the WASM transforms in the order they run. Transforms that cannot be
+// decoded are left out; validation reports them.
+// Default: ConfigTransformsWasmDefault (empty).
+func (s *Settings) TransformsWasm() []WasmTransform {
+	var transforms []WasmTransform
+	if err := s.values().UnmarshalKey(shared.ConfigKeyTransformsWasm, &transforms); err != nil {
+		return nil
+	}
+
+	return transforms
+}
+
+// DocLanguageDetect returns whether the natural language of Markdown, reStructuredText and plain
+// text files is detected and added to their metadata.
+// Default: ConfigDocLanguageDetectDefault (false).
+func (s *Settings) DocLanguageDetect() bool {
+	return s.values().GetBool(shared.ConfigKeyDocLanguageDetect)
+}
+
+// DocLanguageInclude returns the lowercase codes of the natural languages prose files must be
+// written in; prose detected in any other language is left out. Setting any enables detection.
+// Default: ConfigDocLanguageIncludeDefault (empty = all).
+func (s *Settings) DocLanguageInclude() []string {
+	// Lowered in a copy, as viper returns the default slice itself
+	languages := slices.Clone(s.values().GetStringSlice(shared.ConfigKeyDocLanguageInclude))
+	for i, language := range languages {
+		languages[i] = strings.ToLower(strings.TrimSpace(language))
+	}
+
+	return languages
+}
+
+// whitespaceKey returns the output.whitespace.languages.<language> override of globalKey
+// when one is configured, and globalKey otherwise.
+func (s *Settings) whitespaceKey(globalKey, language string) string {
+	if language == "" {
+		return globalKey
+	}
+
+	setting := strings.TrimPrefix(globalKey, shared.ConfigKeyOutputWhitespace+".")
+	key := shared.ConfigKeyOutputWhitespaceLanguages + "." + language + "." + setting
+	if s.values().IsSet(key) {
+		return key
+	}
+
+	return globalKey
+}
+
+// GitEnabled returns whether git integration is enabled.
+// Default: ConfigGitEnabledDefault (true).
+func (s *Settings) GitEnabled() bool {
+	return s.values().GetBool(shared.ConfigKeyGitEnabled)
+}
+
+// GitAuthorThreshold returns the minimum authorship share (percent) required by --author.
+// Default: ConfigGitAuthorThresholdDefault (20).
+func (s *Settings) GitAuthorThreshold() float64 {
+	return s.values().GetFloat64(shared.ConfigKeyGitAuthorThreshold)
+}
+
+// GitBlameCache returns whether blame summaries are cached on disk.
+// Default: ConfigGitBlameCacheDefault (true).
+func (s *Settings) GitBlameCache() bool {
+	return s.values().GetBool(shared.ConfigKeyGitBlameCache)
+}
+
+// GitChurn returns whether files are annotated with their commit count over GitChurnDays.
+// Default: ConfigGitChurnDefault (false).
+func (s *Settings) GitChurn() bool {
+	return s.values().GetBool(shared.ConfigKeyGitChurn)
+}
+
+// GitChurnDays returns the window, in days, over which commits are counted as churn.
+// Default: ConfigGitChurnDaysDefault (90).
+func (s *Settings) GitChurnDays() int {
+	return s.values().GetInt(shared.ConfigKeyGitChurnDays)
+}
+
+// GitChurnTop returns how many files the top-churn section lists, 0 for no section.
+// Default: ConfigGitChurnTopDefault (0).
+func (s *Settings) GitChurnTop() int {
+	return s.values().GetInt(shared.ConfigKeyGitChurnTop)
+}
+
+// GitLFSAction returns how Git LFS pointer files are handled: skip, include or fetch.
+// Default: ConfigGitLFSActionDefault ("skip").
+func (s *Settings) GitLFSAction() string {
+	return s.values().GetString(shared.ConfigKeyGitLFSAction)
+}
+
+// GitLFSMaxFetchSize returns the size in bytes of the largest Git LFS object fetched.
+// Default: ConfigGitLFSMaxFetchSizeDefault (10MB).
+func (s *Settings) GitLFSMaxFetchSize() int64 {
+	return s.values().GetInt64(shared.ConfigKeyGitLFSMaxFetchSize)
+}
+
+// CodeOwnersEnabled returns whether files are annotated with their CODEOWNERS owners.
+// Default: ConfigCodeOwnersEnabledDefault (true).
+func (s *Settings) CodeOwnersEnabled() bool {
+	return s.values().GetBool(shared.ConfigKeyCodeOwnersEnabled)
+}
+
+// CodeOwnersPath returns an explicit CODEOWNERS file path.
+// Default: ConfigCodeOwnersPathDefault (empty = auto-discover in the repository).
+func (s *Settings) CodeOwnersPath() string {
+	return s.values().GetString(shared.ConfigKeyCodeOwnersPath)
+}
+
+// Annotations returns the notes placed above files, keyed by the file's path in the output.
+// Configuration keys are case-insensitive, so the paths come back lowercased.
+// Default: ConfigAnnotationsDefault (empty map).
+func (s *Settings) Annotations() map[string]string {
+	return s.values().GetStringMapString(shared.ConfigKeyAnnotations)
+}
+
+// GeneratedTextEnabled returns whether minified and high-entropy text files are detected.
+// Default: ConfigGeneratedTextEnabledDefault (true).
+func (s *Settings) GeneratedTextEnabled() bool {
+	return s.values().GetBool(shared.ConfigKeyGeneratedTextEnabled)
+}
+
+// GeneratedTextAction returns how generated-looking files are handled: skip or summarize.
+// Default: ConfigGeneratedTextActionDefault ("skip").
+func (s *Settings) GeneratedTextAction() string {
+	return s.values().GetString(shared.ConfigKeyGeneratedTextAction)
+}
+
+// GeneratedTextMinSize returns the size in bytes below which files are not inspected.
+// Default: ConfigGeneratedTextMinSizeDefault (16KB).
+func (s *Settings) GeneratedTextMinSize() int64 {
+	return s.values().GetInt64(shared.ConfigKeyGeneratedTextMinSize)
+}
+
+// GeneratedTextMaxLineLength returns the longest line a file may have before it counts as minified.
+// Default: ConfigGeneratedTextMaxLineLengthDefault (5000).
+func (s *Settings) GeneratedTextMaxLineLength() int {
+	return s.values().GetInt(shared.ConfigKeyGeneratedTextMaxLineLength)
+}
+
+// GeneratedTextEntropyThreshold returns the byte entropy (bits per byte) above which ASCII text
+// counts as an encoded blob.
+// Default: ConfigGeneratedTextEntropyDefault (5.6).
+func (s *Settings) GeneratedTextEntropyThreshold() float64 {
+	return s.values().GetFloat64(shared.ConfigKeyGeneratedTextEntropy)
+}
+
+// SecurityScanEnabled returns whether included content is scanned for potential secrets,
+// suspicious URLs and private keys.
+// Default: ConfigSecurityScanEnabledDefault (false).
+func (s *Settings) SecurityScanEnabled() bool {
+	return s.values().GetBool(shared.ConfigKeySecurityScanEnabled)
+}
+
+// RedactionEnabled returns whether secrets in included content are replaced with
+// [REDACTED:<type>] markers before writing.
+// Default: ConfigRedactionEnabledDefault (false).
+func (s *Settings) RedactionEnabled() bool {
+	return s.values().GetBool(shared.ConfigKeyRedactionEnabled)
+}
+
+// StripCommentsEnabled returns whether comments are removed from the source files of the
+// stripComments.languages.
+// Default: ConfigStripCommentsEnabledDefault (false).
+func (s *Settings) StripCommentsEnabled() bool {
+	return s.values().GetBool(shared.ConfigKeyStripCommentsEnabled)
+}
+
+// StripCommentsLanguages returns the lowercase names of the languages comments are stripped from.
+// Default: ConfigStripCommentsLanguagesDefault (every language in shared.CommentStripLanguages).
+func (s *Settings) StripCommentsLanguages() []string {
+	languages := slices.Clone(s.values().GetStringSlice(shared.ConfigKeyStripCommentsLanguages))
+	for i, language := range languages {
+		languages[i] = strings.ToLower(strings.TrimSpace(language))
+	}
+
+	return languages
+}
+
+// RedactionPattern is a custom redaction rule: matches of the regular expression Pattern are
+// replaced with [REDACTED:<Name>].
+type RedactionPattern struct {
+	Name    string `mapstructure:"name"`
+	Pattern string `mapstructure:"pattern"`
+}
+
+// RedactionPatterns returns the custom redaction rules, applied after the built-in ones. Rules
+// that cannot be decoded are left out; validation reports them.
+// Default: ConfigRedactionPatternsDefault (empty).
+func (s *Settings) RedactionPatterns() []RedactionPattern {
+	var patterns []RedactionPattern
+	if err := s.values().UnmarshalKey(shared.ConfigKeyRedactionPatterns, &patterns); err != nil {
+		return nil
+	}
+
+	return patterns
+}
+
+// PerformanceFormatWorkers returns the number of goroutines rendering in-memory file entries
+// into the output format; 1 renders them on the writer goroutine.
+// Default: ConfigPerformanceFormatWorkersDefault (1).
+func (s *Settings) PerformanceFormatWorkers() int {
+	return s.values().GetInt(shared.ConfigKeyPerformanceFormatWorkers)
+}
+
+// PerformanceHashAlgorithm returns the hash algorithm used for file IDs and cache keys,
+// one of shared.HashAlgorithms.
+// Default: ConfigPerformanceHashAlgorithmDefault (sha256).
+func (s *Settings) PerformanceHashAlgorithm() string {
+	return s.values().GetString(shared.ConfigKeyPerformanceHashAlgorithm)
+}
+
+// ShareBackend returns the service --share uploads bundles to, gist or paste.
+// Default: ConfigShareBackendDefault (gist).
+func (s *Settings) ShareBackend() string {
+	return s.values().GetString(shared.ConfigKeyShareBackend)
+}
+
+// ShareURL returns the paste endpoint of the paste backend, or the GitHub API base URL of the
+// gist backend, where empty selects the public API.
+// Default: "".
+func (s *Settings) ShareURL() string {
+	return s.values().GetString(shared.ConfigKeyShareURL)
+}
+
+// ShareTokenEnv returns the environment variable holding the token --share authenticates with.
+// Empty selects GITHUB_TOKEN or GH_TOKEN for gists and no token for the paste endpoint.
+// Default: "".
+func (s *Settings) ShareTokenEnv() string {
+	return s.values().GetString(shared.ConfigKeyShareTokenEnv)
+}
+
+// ShareMaxSize returns the largest bundle (bytes) --share uploads.
+// Default: ConfigShareMaxSizeDefault (10MB).
+func (s *Settings) ShareMaxSize() int64 {
+	return s.values().GetInt64(shared.ConfigKeyShareMaxSize)
+}
+
+// SharePublic returns whether gists created by --share are public rather than secret.
+// Default: ConfigSharePublicDefault (false).
+func (s *Settings) SharePublic() bool {
+	return s.values().GetBool(shared.ConfigKeySharePublic)
+}
+
+// PerformanceContentCache returns whether the transformed content of unchanged files is cached
+// on disk between runs.
+// Default: ConfigPerformanceContentCacheDefault (true).
+func (s *Settings) PerformanceContentCache() bool {
+	return s.values().GetBool(shared.ConfigKeyPerformanceContentCache)
+}
+
+// PerformanceMaxOpenFiles returns the most source files read at once; 0 derives the bound
+// from the open file limit (RLIMIT_NOFILE) of the process.
+// Default: ConfigPerformanceMaxOpenFilesDefault (0).
+func (s *Settings) PerformanceMaxOpenFiles() int {
+	return s.values().GetInt(shared.ConfigKeyPerformanceMaxOpenFiles)
+}
+
+// PerformanceRaiseOpenFileLimit returns whether the soft open file limit is raised to the
+// hard limit before a run.
+// Default: ConfigPerformanceRaiseOpenFileLimitDefault (false).
+func (s *Settings) PerformanceRaiseOpenFileLimit() bool {
+	return s.values().GetBool(shared.ConfigKeyPerformanceRaiseOpenFileLimit)
+}
+
+// PerformancePrefetch returns whether upcoming small files are read ahead of the workers.
+// Default: ConfigPerformancePrefetchDefault (false).
+func (s *Settings) PerformancePrefetch() bool {
+	return s.values().GetBool(shared.ConfigKeyPerformancePrefetch)
+}
+
+// PerformancePrefetchBytes returns how many bytes of prefetched content may be held at once;
+// 0 derives the budget from backpressure.maxMemoryUsage.
+// Default: ConfigPerformancePrefetchBytesDefault (0).
+func (s *Settings) PerformancePrefetchBytes() int64 {
+	return s.values().GetInt64(shared.ConfigKeyPerformancePrefetchBytes)
+}
+
+// PerformancePrefetchReaders returns how many goroutines read upcoming files ahead of the workers.
+// Default: ConfigPerformancePrefetchReadersDefault (4).
+func (s *Settings) PerformancePrefetchReaders() int {
+	return s.values().GetInt(shared.ConfigKeyPerformancePrefetchReaders)
+}
+
+// UIProgressStyle returns how processing progress is shown: bar, spinner, dots or none.
+// Default: ConfigUIProgressStyleDefault (bar).
+func (s *Settings) UIProgressStyle() string {
+	return s.values().GetString(shared.ConfigKeyUIProgressStyle)
+}
+
+// UITheme returns the set of markers the UI prints: auto, unicode or ascii.
+// Default: ConfigUIThemeDefault (auto).
+func (s *Settings) UITheme() string {
+	return s.values().GetString(shared.ConfigKeyUITheme)
+}
+
+// WarningsSlowFileSec returns the time in seconds a file may take to process before the run
+// warns about it; 0 disables the warning.
+// Default: ConfigWarningsSlowFileSecDefault (5).
+func (s *Settings) WarningsSlowFileSec() int {
+	return s.values().GetInt(shared.ConfigKeyWarningsSlowFileSec)
 }
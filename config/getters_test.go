@@ -23,35 +23,35 @@ func TestConfigGetters(t *testing.T) {
 			name:           "GetFileSizeLimit",
 			configKey:      "fileSizeLimit",
 			configValue:    int64(1048576),
-			getterFunc:     func() any { return config.FileSizeLimit() },
+			getterFunc:     func() any { return config.Global().FileSizeLimit() },
 			expectedResult: int64(1048576),
 		},
 		{
 			name:           "GetIgnoredDirectories",
 			configKey:      "ignoreDirectories",
 			configValue:    []string{"node_modules", ".git", "dist"},
-			getterFunc:     func() any { return config.IgnoredDirectories() },
+			getterFunc:     func() any { return config.Global().IgnoredDirectories() },
 			expectedResult: []string{"node_modules", ".git", "dist"},
 		},
 		{
 			name:           "GetMaxConcurrency",
 			configKey:      "maxConcurrency",
 			configValue:    8,
-			getterFunc:     func() any { return config.MaxConcurrency() },
+			getterFunc:     func() any { return config.Global().MaxConcurrency() },
 			expectedResult: 8,
 		},
 		{
 			name:           "GetSupportedFormats",
 			configKey:      "supportedFormats",
 			configValue:    []string{"json", "yaml", "markdown"},
-			getterFunc:     func() any { return config.SupportedFormats() },
+			getterFunc:     func() any { return config.Global().SupportedFormats() },
 			expectedResult: []string{"json", "yaml", "markdown"},
 		},
 		{
 			name:           "GetFilePatterns",
 			configKey:      "filePatterns",
 			configValue:    []string{"*.go", "*.js", "*.py"},
-			getterFunc:     func() any { return config.FilePatterns() },
+			getterFunc:     func() any { return config.Global().FilePatterns() },
 			expectedResult: []string{"*.go", "*.js", "*.py"},
 		},
 
@@ -60,42 +60,42 @@ func TestConfigGetters(t *testing.T) {
 			name:           "GetFileTypesEnabled",
 			configKey:      "fileTypes.enabled",
 			configValue:    true,
-			getterFunc:     func() any { return config.FileTypesEnabled() },
+			getterFunc:     func() any { return config.Global().FileTypesEnabled() },
 			expectedResult: true,
 		},
 		{
 			name:           "GetCustomImageExtensions",
 			configKey:      "fileTypes.customImageExtensions",
 			configValue:    []string{".webp", ".avif"},
-			getterFunc:     func() any { return config.CustomImageExtensions() },
+			getterFunc:     func() any { return config.Global().CustomImageExtensions() },
 			expectedResult: []string{".webp", ".avif"},
 		},
 		{
 			name:           "GetCustomBinaryExtensions",
 			configKey:      "fileTypes.customBinaryExtensions",
 			configValue:    []string{".custom", ".bin"},
-			getterFunc:     func() any { return config.CustomBinaryExtensions() },
+			getterFunc:     func() any { return config.Global().CustomBinaryExtensions() },
 			expectedResult: []string{".custom", ".bin"},
 		},
 		{
 			name:           "GetDisabledImageExtensions",
 			configKey:      "fileTypes.disabledImageExtensions",
 			configValue:    []string{".gif", ".bmp"},
-			getterFunc:     func() any { return config.DisabledImageExtensions() },
+			getterFunc:     func() any { return config.Global().DisabledImageExtensions() },
 			expectedResult: []string{".gif", ".bmp"},
 		},
 		{
 			name:           "GetDisabledBinaryExtensions",
 			configKey:      "fileTypes.disabledBinaryExtensions",
 			configValue:    []string{".exe", ".dll"},
-			getterFunc:     func() any { return config.DisabledBinaryExtensions() },
+			getterFunc:     func() any { return config.Global().DisabledBinaryExtensions() },
 			expectedResult: []string{".exe", ".dll"},
 		},
 		{
 			name:           "GetDisabledLanguageExtensions",
 			configKey:      "fileTypes.disabledLanguageExtensions",
 			configValue:    []string{".sh", ".bat"},
-			getterFunc:     func() any { return config.DisabledLanguageExtensions() },
+			getterFunc:     func() any { return config.Global().DisabledLanguageExtensions() },
 			expectedResult: []string{".sh", ".bat"},
 		},
 
@@ -104,35 +104,35 @@ func TestConfigGetters(t *testing.T) {
 			name:           "GetBackpressureEnabled",
 			configKey:      "backpressure.enabled",
 			configValue:    true,
-			getterFunc:     func() any { return config.BackpressureEnabled() },
+			getterFunc:     func() any { return config.Global().BackpressureEnabled() },
 			expectedResult: true,
 		},
 		{
 			name:           "GetMaxPendingFiles",
 			configKey:      "backpressure.maxPendingFiles",
 			configValue:    1000,
-			getterFunc:     func() any { return config.MaxPendingFiles() },
+			getterFunc:     func() any { return config.Global().MaxPendingFiles() },
 			expectedResult: 1000,
 		},
 		{
 			name:           "GetMaxPendingWrites",
 			configKey:      "backpressure.maxPendingWrites",
 			configValue:    100,
-			getterFunc:     func() any { return config.MaxPendingWrites() },
+			getterFunc:     func() any { return config.Global().MaxPendingWrites() },
 			expectedResult: 100,
 		},
 		{
 			name:           "GetMaxMemoryUsage",
 			configKey:      "backpressure.maxMemoryUsage",
 			configValue:    int64(104857600),
-			getterFunc:     func() any { return config.MaxMemoryUsage() },
+			getterFunc:     func() any { return config.Global().MaxMemoryUsage() },
 			expectedResult: int64(104857600),
 		},
 		{
 			name:           "GetMemoryCheckInterval",
 			configKey:      "backpressure.memoryCheckInterval",
 			configValue:    500,
-			getterFunc:     func() any { return config.MemoryCheckInterval() },
+			getterFunc:     func() any { return config.Global().MemoryCheckInterval() },
 			expectedResult: 500,
 		},
 
@@ -141,70 +141,70 @@ func TestConfigGetters(t *testing.T) {
 			name:           "GetResourceLimitsEnabled",
 			configKey:      "resourceLimits.enabled",
 			configValue:    true,
-			getterFunc:     func() any { return config.ResourceLimitsEnabled() },
+			getterFunc:     func() any { return config.Global().ResourceLimitsEnabled() },
 			expectedResult: true,
 		},
 		{
 			name:           "GetMaxFiles",
 			configKey:      "resourceLimits.maxFiles",
 			configValue:    5000,
-			getterFunc:     func() any { return config.MaxFiles() },
+			getterFunc:     func() any { return config.Global().MaxFiles() },
 			expectedResult: 5000,
 		},
 		{
 			name:           "GetMaxTotalSize",
 			configKey:      "resourceLimits.maxTotalSize",
 			configValue:    int64(1073741824),
-			getterFunc:     func() any { return config.MaxTotalSize() },
+			getterFunc:     func() any { return config.Global().MaxTotalSize() },
 			expectedResult: int64(1073741824),
 		},
 		{
 			name:           "GetFileProcessingTimeoutSec",
 			configKey:      "resourceLimits.fileProcessingTimeoutSec",
 			configValue:    30,
-			getterFunc:     func() any { return config.FileProcessingTimeoutSec() },
+			getterFunc:     func() any { return config.Global().FileProcessingTimeoutSec() },
 			expectedResult: 30,
 		},
 		{
 			name:           "GetOverallTimeoutSec",
 			configKey:      "resourceLimits.overallTimeoutSec",
 			configValue:    1800,
-			getterFunc:     func() any { return config.OverallTimeoutSec() },
+			getterFunc:     func() any { return config.Global().OverallTimeoutSec() },
 			expectedResult: 1800,
 		},
 		{
 			name:           "GetMaxConcurrentReads",
 			configKey:      "resourceLimits.maxConcurrentReads",
 			configValue:    10,
-			getterFunc:     func() any { return config.MaxConcurrentReads() },
+			getterFunc:     func() any { return config.Global().MaxConcurrentReads() },
 			expectedResult: 10,
 		},
 		{
 			name:           "GetRateLimitFilesPerSec",
 			configKey:      "resourceLimits.rateLimitFilesPerSec",
 			configValue:    100,
-			getterFunc:     func() any { return config.RateLimitFilesPerSec() },
+			getterFunc:     func() any { return config.Global().RateLimitFilesPerSec() },
 			expectedResult: 100,
 		},
 		{
 			name:           "GetHardMemoryLimitMB",
 			configKey:      "resourceLimits.hardMemoryLimitMB",
 			configValue:    512,
-			getterFunc:     func() any { return config.HardMemoryLimitMB() },
+			getterFunc:     func() any { return config.Global().HardMemoryLimitMB() },
 			expectedResult: 512,
 		},
 		{
 			name:           "GetEnableGracefulDegradation",
 			configKey:      "resourceLimits.enableGracefulDegradation",
 			configValue:    true,
-			getterFunc:     func() any { return config.EnableGracefulDegradation() },
+			getterFunc:     func() any { return config.Global().EnableGracefulDegradation() },
 			expectedResult: true,
 		},
 		{
 			name:           "GetEnableResourceMonitoring",
 			configKey:      "resourceLimits.enableResourceMonitoring",
 			configValue:    true,
-			getterFunc:     func() any { return config.EnableResourceMonitoring() },
+			getterFunc:     func() any { return config.Global().EnableResourceMonitoring() },
 			expectedResult: true,
 		},
 
@@ -213,63 +213,63 @@ func TestConfigGetters(t *testing.T) {
 			name:           "GetOutputTemplate",
 			configKey:      "output.template",
 			configValue:    "detailed",
-			getterFunc:     func() any { return config.OutputTemplate() },
+			getterFunc:     func() any { return config.Global().OutputTemplate() },
 			expectedResult: "detailed",
 		},
 		{
 			name:           "GetTemplateMetadataIncludeStats",
 			configKey:      "output.metadata.includeStats",
 			configValue:    true,
-			getterFunc:     func() any { return config.TemplateMetadataIncludeStats() },
+			getterFunc:     func() any { return config.Global().TemplateMetadataIncludeStats() },
 			expectedResult: true,
 		},
 		{
 			name:           "GetTemplateMetadataIncludeTimestamp",
 			configKey:      "output.metadata.includeTimestamp",
 			configValue:    false,
-			getterFunc:     func() any { return config.TemplateMetadataIncludeTimestamp() },
+			getterFunc:     func() any { return config.Global().TemplateMetadataIncludeTimestamp() },
 			expectedResult: false,
 		},
 		{
 			name:           "GetTemplateMetadataIncludeFileCount",
 			configKey:      "output.metadata.includeFileCount",
 			configValue:    true,
-			getterFunc:     func() any { return config.TemplateMetadataIncludeFileCount() },
+			getterFunc:     func() any { return config.Global().TemplateMetadataIncludeFileCount() },
 			expectedResult: true,
 		},
 		{
 			name:           "GetTemplateMetadataIncludeSourcePath",
 			configKey:      "output.metadata.includeSourcePath",
 			configValue:    false,
-			getterFunc:     func() any { return config.TemplateMetadataIncludeSourcePath() },
+			getterFunc:     func() any { return config.Global().TemplateMetadataIncludeSourcePath() },
 			expectedResult: false,
 		},
 		{
 			name:           "GetTemplateMetadataIncludeFileTypes",
 			configKey:      "output.metadata.includeFileTypes",
 			configValue:    true,
-			getterFunc:     func() any { return config.TemplateMetadataIncludeFileTypes() },
+			getterFunc:     func() any { return config.Global().TemplateMetadataIncludeFileTypes() },
 			expectedResult: true,
 		},
 		{
 			name:           "GetTemplateMetadataIncludeProcessingTime",
 			configKey:      "output.metadata.includeProcessingTime",
 			configValue:    false,
-			getterFunc:     func() any { return config.TemplateMetadataIncludeProcessingTime() },
+			getterFunc:     func() any { return config.Global().TemplateMetadataIncludeProcessingTime() },
 			expectedResult: false,
 		},
 		{
 			name:           "GetTemplateMetadataIncludeTotalSize",
 			configKey:      "output.metadata.includeTotalSize",
 			configValue:    true,
-			getterFunc:     func() any { return config.TemplateMetadataIncludeTotalSize() },
+			getterFunc:     func() any { return config.Global().TemplateMetadataIncludeTotalSize() },
 			expectedResult: true,
 		},
 		{
 			name:           "GetTemplateMetadataIncludeMetrics",
 			configKey:      "output.metadata.includeMetrics",
 			configValue:    false,
-			getterFunc:     func() any { return config.TemplateMetadataIncludeMetrics() },
+			getterFunc:     func() any { return config.Global().TemplateMetadataIncludeMetrics() },
 			expectedResult: false,
 		},
 
@@ -278,70 +278,70 @@ func TestConfigGetters(t *testing.T) {
 			name:           "GetTemplateMarkdownUseCodeBlocks",
 			configKey:      "output.markdown.useCodeBlocks",
 			configValue:    true,
-			getterFunc:     func() any { return config.TemplateMarkdownUseCodeBlocks() },
+			getterFunc:     func() any { return config.Global().TemplateMarkdownUseCodeBlocks() },
 			expectedResult: true,
 		},
 		{
 			name:           "GetTemplateMarkdownIncludeLanguage",
 			configKey:      "output.markdown.includeLanguage",
 			configValue:    false,
-			getterFunc:     func() any { return config.TemplateMarkdownIncludeLanguage() },
+			getterFunc:     func() any { return config.Global().TemplateMarkdownIncludeLanguage() },
 			expectedResult: false,
 		},
 		{
 			name:           "GetTemplateMarkdownHeaderLevel",
 			configKey:      "output.markdown.headerLevel",
 			configValue:    3,
-			getterFunc:     func() any { return config.TemplateMarkdownHeaderLevel() },
+			getterFunc:     func() any { return config.Global().TemplateMarkdownHeaderLevel() },
 			expectedResult: 3,
 		},
 		{
 			name:           "GetTemplateMarkdownTableOfContents",
 			configKey:      "output.markdown.tableOfContents",
 			configValue:    true,
-			getterFunc:     func() any { return config.TemplateMarkdownTableOfContents() },
+			getterFunc:     func() any { return config.Global().TemplateMarkdownTableOfContents() },
 			expectedResult: true,
 		},
 		{
 			name:           "GetTemplateMarkdownUseCollapsible",
 			configKey:      "output.markdown.useCollapsible",
 			configValue:    false,
-			getterFunc:     func() any { return config.TemplateMarkdownUseCollapsible() },
+			getterFunc:     func() any { return config.Global().TemplateMarkdownUseCollapsible() },
 			expectedResult: false,
 		},
 		{
 			name:           "GetTemplateMarkdownSyntaxHighlighting",
 			configKey:      "output.markdown.syntaxHighlighting",
 			configValue:    true,
-			getterFunc:     func() any { return config.TemplateMarkdownSyntaxHighlighting() },
+			getterFunc:     func() any { return config.Global().TemplateMarkdownSyntaxHighlighting() },
 			expectedResult: true,
 		},
 		{
 			name:           "GetTemplateMarkdownLineNumbers",
 			configKey:      "output.markdown.lineNumbers",
 			configValue:    false,
-			getterFunc:     func() any { return config.TemplateMarkdownLineNumbers() },
+			getterFunc:     func() any { return config.Global().TemplateMarkdownLineNumbers() },
 			expectedResult: false,
 		},
 		{
 			name:           "GetTemplateMarkdownFoldLongFiles",
 			configKey:      "output.markdown.foldLongFiles",
 			configValue:    true,
-			getterFunc:     func() any { return config.TemplateMarkdownFoldLongFiles() },
+			getterFunc:     func() any { return config.Global().TemplateMarkdownFoldLongFiles() },
 			expectedResult: true,
 		},
 		{
 			name:           "GetTemplateMarkdownMaxLineLength",
 			configKey:      "output.markdown.maxLineLength",
 			configValue:    120,
-			getterFunc:     func() any { return config.TemplateMarkdownMaxLineLength() },
+			getterFunc:     func() any { return config.Global().TemplateMarkdownMaxLineLength() },
 			expectedResult: 120,
 		},
 		{
 			name:           "GetTemplateCustomCSS",
 			configKey:      "output.markdown.customCSS",
 			configValue:    "body { color: blue; }",
-			getterFunc:     func() any { return config.TemplateCustomCSS() },
+			getterFunc:     func() any { return config.Global().TemplateCustomCSS() },
 			expectedResult: "body { color: blue; }",
 		},
 
@@ -350,28 +350,28 @@ func TestConfigGetters(t *testing.T) {
 			name:           "GetTemplateCustomHeader",
 			configKey:      "output.custom.header",
 			configValue:    "# Custom Header\n",
-			getterFunc:     func() any { return config.TemplateCustomHeader() },
+			getterFunc:     func() any { return config.Global().TemplateCustomHeader() },
 			expectedResult: "# Custom Header\n",
 		},
 		{
 			name:           "GetTemplateCustomFooter",
 			configKey:      "output.custom.footer",
 			configValue:    "---\nFooter content",
-			getterFunc:     func() any { return config.TemplateCustomFooter() },
+			getterFunc:     func() any { return config.Global().TemplateCustomFooter() },
 			expectedResult: "---\nFooter content",
 		},
 		{
 			name:           "GetTemplateCustomFileHeader",
 			configKey:      "output.custom.fileHeader",
 			configValue:    "## File: {{ .Path }}",
-			getterFunc:     func() any { return config.TemplateCustomFileHeader() },
+			getterFunc:     func() any { return config.Global().TemplateCustomFileHeader() },
 			expectedResult: "## File: {{ .Path }}",
 		},
 		{
 			name:           "GetTemplateCustomFileFooter",
 			configKey:      "output.custom.fileFooter",
 			configValue:    "---",
-			getterFunc:     func() any { return config.TemplateCustomFileFooter() },
+			getterFunc:     func() any { return config.Global().TemplateCustomFileFooter() },
 			expectedResult: "---",
 		},
 
@@ -380,7 +380,7 @@ func TestConfigGetters(t *testing.T) {
 			name:           "GetCustomLanguages",
 			configKey:      "fileTypes.customLanguages",
 			configValue:    map[string]string{".vue": "vue", ".svelte": "svelte"},
-			getterFunc:     func() any { return config.CustomLanguages() },
+			getterFunc:     func() any { return config.Global().CustomLanguages() },
 			expectedResult: map[string]string{".vue": "vue", ".svelte": "svelte"},
 		},
 
@@ -389,9 +389,20 @@ func TestConfigGetters(t *testing.T) {
 			name:           "GetTemplateVariables",
 			configKey:      "output.variables",
 			configValue:    map[string]string{"project": "gibidify", "version": "1.0"},
-			getterFunc:     func() any { return config.TemplateVariables() },
+			getterFunc:     func() any { return config.Global().TemplateVariables() },
 			expectedResult: map[string]string{"project": "gibidify", "version": "1.0"},
 		},
+
+		// Budgets map getter
+		{
+			name:        "GetBudgets",
+			configKey:   "budgets",
+			configValue: map[string]any{"docs/**": map[string]any{"maxTokens": 5000, "fallback": "outline"}},
+			getterFunc:  func() any { return config.Global().Budgets() },
+			expectedResult: map[string]config.Budget{
+				"docs/**": {MaxTokens: 5000, Fallback: "outline"},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -419,39 +430,40 @@ func TestConfigGettersWithDefaults(t *testing.T) {
 
 	// Test numeric getters with concrete default assertions
 	t.Run("numeric_getters", func(t *testing.T) {
-		assertInt64Getter(t, "FileSizeLimit", config.FileSizeLimit, shared.ConfigFileSizeLimitDefault)
-		assertIntGetter(t, "MaxConcurrency", config.MaxConcurrency, shared.ConfigMaxConcurrencyDefault)
-		assertIntGetter(t, "TemplateMarkdownHeaderLevel", config.TemplateMarkdownHeaderLevel,
+		assertInt64Getter(t, "FileSizeLimit", config.Global().FileSizeLimit, shared.ConfigFileSizeLimitDefault)
+		assertIntGetter(t, "MaxConcurrency", config.Global().MaxConcurrency, shared.ConfigMaxConcurrencyDefault)
+		assertIntGetter(t, "TemplateMarkdownHeaderLevel", config.Global().TemplateMarkdownHeaderLevel,
 			shared.ConfigMarkdownHeaderLevelDefault)
-		assertIntGetter(t, "MaxFiles", config.MaxFiles, shared.ConfigMaxFilesDefault)
-		assertInt64Getter(t, "MaxTotalSize", config.MaxTotalSize, shared.ConfigMaxTotalSizeDefault)
-		assertIntGetter(t, "FileProcessingTimeoutSec", config.FileProcessingTimeoutSec,
+		assertIntGetter(t, "MaxFiles", config.Global().MaxFiles, shared.ConfigMaxFilesDefault)
+		assertInt64Getter(t, "MaxTotalSize", config.Global().MaxTotalSize, shared.ConfigMaxTotalSizeDefault)
+		assertIntGetter(t, "FileProcessingTimeoutSec", config.Global().FileProcessingTimeoutSec,
 			shared.ConfigFileProcessingTimeoutSecDefault)
-		assertIntGetter(t, "OverallTimeoutSec", config.OverallTimeoutSec, shared.ConfigOverallTimeoutSecDefault)
-		assertIntGetter(t, "MaxConcurrentReads", config.MaxConcurrentReads, shared.ConfigMaxConcurrentReadsDefault)
-		assertIntGetter(t, "HardMemoryLimitMB", config.HardMemoryLimitMB, shared.ConfigHardMemoryLimitMBDefault)
+		assertIntGetter(t, "OverallTimeoutSec", config.Global().OverallTimeoutSec, shared.ConfigOverallTimeoutSecDefault)
+		assertIntGetter(t, "MaxConcurrentReads", config.Global().MaxConcurrentReads, shared.ConfigMaxConcurrentReadsDefault)
+		assertIntGetter(t, "HardMemoryLimitMB", config.Global().HardMemoryLimitMB, shared.ConfigHardMemoryLimitMBDefault)
 	})
 
 	// Test boolean getters with concrete default assertions
 	t.Run("boolean_getters", func(t *testing.T) {
-		assertBoolGetter(t, "FileTypesEnabled", config.FileTypesEnabled, shared.ConfigFileTypesEnabledDefault)
-		assertBoolGetter(t, "BackpressureEnabled", config.BackpressureEnabled, shared.ConfigBackpressureEnabledDefault)
-		assertBoolGetter(t, "ResourceLimitsEnabled", config.ResourceLimitsEnabled,
+		assertBoolGetter(t, "FileTypesEnabled", config.Global().FileTypesEnabled, shared.ConfigFileTypesEnabledDefault)
+		assertBoolGetter(t, "BackpressureEnabled", config.Global().BackpressureEnabled,
+			shared.ConfigBackpressureEnabledDefault)
+		assertBoolGetter(t, "ResourceLimitsEnabled", config.Global().ResourceLimitsEnabled,
 			shared.ConfigResourceLimitsEnabledDefault)
-		assertBoolGetter(t, "EnableGracefulDegradation", config.EnableGracefulDegradation,
+		assertBoolGetter(t, "EnableGracefulDegradation", config.Global().EnableGracefulDegradation,
 			shared.ConfigEnableGracefulDegradationDefault)
-		assertBoolGetter(t, "TemplateMarkdownUseCodeBlocks", config.TemplateMarkdownUseCodeBlocks,
+		assertBoolGetter(t, "TemplateMarkdownUseCodeBlocks", config.Global().TemplateMarkdownUseCodeBlocks,
 			shared.ConfigMarkdownUseCodeBlocksDefault)
-		assertBoolGetter(t, "TemplateMarkdownTableOfContents", config.TemplateMarkdownTableOfContents,
+		assertBoolGetter(t, "TemplateMarkdownTableOfContents", config.Global().TemplateMarkdownTableOfContents,
 			shared.ConfigMarkdownTableOfContentsDefault)
 	})
 
 	// Test string getters with concrete default assertions
 	t.Run("string_getters", func(t *testing.T) {
-		assertStringGetter(t, "OutputTemplate", config.OutputTemplate)
-		assertStringGetter(t, "TemplateCustomCSS", config.TemplateCustomCSS)
-		assertStringGetter(t, "TemplateCustomHeader", config.TemplateCustomHeader)
-		assertStringGetter(t, "TemplateCustomFooter", config.TemplateCustomFooter)
+		assertStringGetter(t, "OutputTemplate", config.Global().OutputTemplate)
+		assertStringGetter(t, "TemplateCustomCSS", config.Global().TemplateCustomCSS)
+		assertStringGetter(t, "TemplateCustomHeader", config.Global().TemplateCustomHeader)
+		assertStringGetter(t, "TemplateCustomFooter", config.Global().TemplateCustomFooter)
 	})
 }
 
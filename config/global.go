@@ -0,0 +1,30 @@
+// Package config handles application configuration management.
+package config
+
+// The functions below read the global configuration that LoadConfig fills; a processor
+// given Settings of its own reads those instead.
+
+// PerformancePrefetchReaders calls Settings.PerformancePrefetchReaders on the global configuration.
+func PerformancePrefetchReaders() int {
+	return Global().PerformancePrefetchReaders()
+}
+
+// PerformanceHashAlgorithm calls Settings.PerformanceHashAlgorithm on the global configuration.
+func PerformanceHashAlgorithm() string {
+	return Global().PerformanceHashAlgorithm()
+}
+
+// ValidateConcurrency calls Settings.ValidateConcurrency on the global configuration.
+func ValidateConcurrency(concurrency int) error {
+	return Global().ValidateConcurrency(concurrency)
+}
+
+// ValidateConfig calls Settings.ValidateConfig on the global configuration.
+func ValidateConfig() error {
+	return Global().ValidateConfig()
+}
+
+// ValidateOutputFormat calls Settings.ValidateOutputFormat on the global configuration.
+func ValidateOutputFormat(format string) error {
+	return Global().ValidateOutputFormat(format)
+}
@@ -2,6 +2,7 @@
 package config
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 
@@ -10,6 +11,9 @@ import (
 	"github.com/ivuorinen/gibidify/shared"
 )
 
+// loadErr is the validation error that made the last LoadConfig call fall back to defaults.
+var loadErr error
+
 // LoadConfig reads configuration from a YAML file.
 // It looks for config in the following order:
 // 1. $XDG_CONFIG_HOME/gibidify/config.yaml
@@ -20,31 +24,22 @@ func LoadConfig() {
 	viper.SetConfigType(shared.FormatYAML)
 
 	logger := shared.GetLogger()
-
-	if xdgConfig := os.Getenv("XDG_CONFIG_HOME"); xdgConfig != "" {
-		// Validate XDG_CONFIG_HOME for path traversal attempts
-		if err := shared.ValidateConfigPath(xdgConfig); err != nil {
-			logger.Warnf("Invalid XDG_CONFIG_HOME path, using default config: %v", err)
-		} else {
-			configPath := filepath.Join(xdgConfig, shared.AppName)
-			viper.AddConfigPath(configPath)
-		}
-	} else if home, err := os.UserHomeDir(); err == nil {
-		viper.AddConfigPath(filepath.Join(home, ".config", shared.AppName))
-	}
-	// Only add current directory if no config file named gibidify.yaml exists
-	// to avoid conflicts with the project's output file
-	if _, err := os.Stat(shared.AppName + ".yaml"); os.IsNotExist(err) {
-		viper.AddConfigPath(".")
+	loadErr = nil
+	global.projectFile, global.projectKeys, global.deprecated = "", nil, nil
+	for _, dir := range configDirs() {
+		viper.AddConfigPath(dir)
 	}
 
+	// Defaults fill in every key a partial config file leaves out
+	SetDefaultConfig()
 	if err := viper.ReadInConfig(); err != nil {
 		logger.Infof("Config file not found, using default values: %v", err)
-		SetDefaultConfig()
 	} else {
 		logger.Infof("Using config file: %s", viper.ConfigFileUsed())
+		global.applyDeprecatedKeys()
 		// Validate configuration after loading
 		if err := ValidateConfig(); err != nil {
+			loadErr = err
 			logger.Warnf("Configuration validation failed: %v", err)
 			logger.Info("Falling back to default configuration")
 			// Reset viper and set defaults when validation fails
@@ -54,66 +49,284 @@ func LoadConfig() {
 	}
 }
 
+// LoadConfigFrom reads configuration from path only, skipping the directories LoadConfig
+// searches; an empty path leaves every setting at its default. As the file was named
+// explicitly, an unreadable or invalid file is returned as an error, rather than silently
+// replaced by the defaults, which are left in place.
+func LoadConfigFrom(path string) error {
+	loadErr = nil
+	global.projectFile, global.projectKeys, global.deprecated = "", nil, nil
+	SetDefaultConfig()
+	if path == "" {
+		shared.GetLogger().Info("No config file given, using default values")
+
+		return nil
+	}
+
+	viper.SetConfigFile(path)
+	viper.SetConfigType(shared.FormatYAML)
+	if err := viper.ReadInConfig(); err != nil {
+		return shared.WrapError(err, shared.ErrorTypeConfiguration, shared.CodeConfigMissing, "failed to read config file").
+			WithFilePath(path)
+	}
+	global.applyDeprecatedKeys()
+	if err := ValidateConfig(); err != nil {
+		viper.Reset()
+		SetDefaultConfig()
+
+		return err
+	}
+	shared.GetLogger().Infof("Using config file: %s", path)
+
+	return nil
+}
+
+// LoadSettingsFromEnv reads the config file LoadConfigFromEnv would into new Settings, leaving
+// the global configuration untouched. Unlike LoadConfig, an invalid file found in the config
+// directories is returned as an error rather than replaced by the defaults.
+func LoadSettingsFromEnv() (*Settings, error) {
+	if path := os.Getenv(shared.ConfigEnvVar); path != "" {
+		return LoadSettings(path)
+	}
+
+	s := NewSettings()
+	s.v.SetConfigName("config")
+	s.v.SetConfigType(shared.FormatYAML)
+	for _, dir := range configDirs() {
+		s.v.AddConfigPath(dir)
+	}
+	if err := s.v.ReadInConfig(); err != nil {
+		if errors.As(err, &viper.ConfigFileNotFoundError{}) {
+			return s, nil
+		}
+
+		return nil, shared.WrapError(
+			err, shared.ErrorTypeConfiguration, shared.CodeConfigMissing, "failed to read config file",
+		).WithFilePath(s.v.ConfigFileUsed())
+	}
+	s.applyDeprecatedKeys()
+	if err := s.ValidateConfig(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// configDirs returns the directories LoadConfig searches for the config file, in order.
+func configDirs() []string {
+	var dirs []string
+	if xdgConfig := os.Getenv("XDG_CONFIG_HOME"); xdgConfig != "" {
+		// Validate XDG_CONFIG_HOME for path traversal attempts
+		if err := shared.ValidateConfigPath(xdgConfig); err != nil {
+			shared.GetLogger().Warnf("Invalid XDG_CONFIG_HOME path, using default config: %v", err)
+		} else {
+			dirs = append(dirs, filepath.Join(xdgConfig, shared.AppName))
+		}
+	} else if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, ".config", shared.AppName))
+	}
+	// Only add current directory if no config file named gibidify.yaml exists
+	// to avoid conflicts with the project's output file
+	if _, err := os.Stat(shared.AppName + ".yaml"); os.IsNotExist(err) {
+		dirs = append(dirs, ".")
+	}
+
+	return dirs
+}
+
+// LoadConfigFromEnv reads the config file named by $GIBIDIFY_CONFIG like LoadConfigFrom, so a
+// missing or invalid file is an error, or searches the config directories like LoadConfig when
+// the variable is not set.
+func LoadConfigFromEnv() error {
+	if path := os.Getenv(shared.ConfigEnvVar); path != "" {
+		return LoadConfigFrom(path)
+	}
+	LoadConfig()
+
+	return nil
+}
+
+// LoadError returns the validation error that made the last LoadConfig call fall back to
+// the default configuration, or nil when the config file was valid or none was found.
+func LoadError() error {
+	return loadErr
+}
+
+// FileUsed returns the config file loaded by LoadConfig, or "" when defaults are in use.
+func FileUsed() string {
+	return Global().FileUsed()
+}
+
 // SetDefaultConfig sets default configuration values.
 func SetDefaultConfig() {
+	setDefaults(viper.GetViper())
+}
+
+// defaultSetter receives the default configuration values, such as a *viper.Viper.
+type defaultSetter interface {
+	SetDefault(key string, value any)
+}
+
+// setDefaults sets the default configuration values on v.
+func setDefaults(v defaultSetter) {
 	// File size limits
-	viper.SetDefault(shared.ConfigKeyFileSizeLimit, shared.ConfigFileSizeLimitDefault)
-	viper.SetDefault(shared.ConfigKeyIgnoreDirectories, shared.ConfigIgnoredDirectoriesDefault)
-	viper.SetDefault(shared.ConfigKeyMaxConcurrency, shared.ConfigMaxConcurrencyDefault)
-	viper.SetDefault(shared.ConfigKeySupportedFormats, shared.ConfigSupportedFormatsDefault)
-	viper.SetDefault(shared.ConfigKeyFilePatterns, shared.ConfigFilePatternsDefault)
+	v.SetDefault(shared.ConfigKeyFileSizeLimit, shared.ConfigFileSizeLimitDefault)
+	v.SetDefault(shared.ConfigKeyIgnoreDirectories, shared.ConfigIgnoredDirectoriesDefault)
+	v.SetDefault(shared.ConfigKeyMaxConcurrency, shared.ConfigMaxConcurrencyDefault)
+	v.SetDefault(shared.ConfigKeySupportedFormats, shared.ConfigSupportedFormatsDefault)
+	v.SetDefault(shared.ConfigKeyFilePatterns, shared.ConfigFilePatternsDefault)
 
 	// FileTypeRegistry defaults
-	viper.SetDefault(shared.ConfigKeyFileTypesEnabled, shared.ConfigFileTypesEnabledDefault)
-	viper.SetDefault(shared.ConfigKeyFileTypesCustomImageExtensions, shared.ConfigCustomImageExtensionsDefault)
-	viper.SetDefault(shared.ConfigKeyFileTypesCustomBinaryExtensions, shared.ConfigCustomBinaryExtensionsDefault)
-	viper.SetDefault(shared.ConfigKeyFileTypesCustomLanguages, shared.ConfigCustomLanguagesDefault)
-	viper.SetDefault(shared.ConfigKeyFileTypesDisabledImageExtensions, shared.ConfigDisabledImageExtensionsDefault)
-	viper.SetDefault(shared.ConfigKeyFileTypesDisabledBinaryExtensions, shared.ConfigDisabledBinaryExtensionsDefault)
-	viper.SetDefault(shared.ConfigKeyFileTypesDisabledLanguageExts, shared.ConfigDisabledLanguageExtensionsDefault)
+	v.SetDefault(shared.ConfigKeyFileTypesEnabled, shared.ConfigFileTypesEnabledDefault)
+	v.SetDefault(shared.ConfigKeyFileTypesCustomImageExtensions, shared.ConfigCustomImageExtensionsDefault)
+	v.SetDefault(shared.ConfigKeyFileTypesCustomBinaryExtensions, shared.ConfigCustomBinaryExtensionsDefault)
+	v.SetDefault(shared.ConfigKeyFileTypesCustomLanguages, shared.ConfigCustomLanguagesDefault)
+	v.SetDefault(shared.ConfigKeyFileTypesDisabledImageExtensions, shared.ConfigDisabledImageExtensionsDefault)
+	v.SetDefault(shared.ConfigKeyFileTypesDisabledBinaryExtensions, shared.ConfigDisabledBinaryExtensionsDefault)
+	v.SetDefault(shared.ConfigKeyFileTypesDisabledLanguageExts, shared.ConfigDisabledLanguageExtensionsDefault)
+	v.SetDefault(shared.ConfigKeyContentSniffingEnabled, shared.ConfigContentSniffingEnabledDefault)
 
 	// Backpressure and memory management defaults
-	viper.SetDefault(shared.ConfigKeyBackpressureEnabled, shared.ConfigBackpressureEnabledDefault)
-	viper.SetDefault(shared.ConfigKeyBackpressureMaxPendingFiles, shared.ConfigMaxPendingFilesDefault)
-	viper.SetDefault(shared.ConfigKeyBackpressureMaxPendingWrites, shared.ConfigMaxPendingWritesDefault)
-	viper.SetDefault(shared.ConfigKeyBackpressureMaxMemoryUsage, shared.ConfigMaxMemoryUsageDefault)
-	viper.SetDefault(shared.ConfigKeyBackpressureMemoryCheckInt, shared.ConfigMemoryCheckIntervalDefault)
+	v.SetDefault(shared.ConfigKeyBackpressureEnabled, shared.ConfigBackpressureEnabledDefault)
+	v.SetDefault(shared.ConfigKeyBackpressureMaxPendingFiles, shared.ConfigMaxPendingFilesDefault)
+	v.SetDefault(shared.ConfigKeyBackpressureMaxPendingWrites, shared.ConfigMaxPendingWritesDefault)
+	v.SetDefault(shared.ConfigKeyBackpressureMaxMemoryUsage, shared.ConfigMaxMemoryUsageDefault)
+	v.SetDefault(shared.ConfigKeyBackpressureMemoryCheckInt, shared.ConfigMemoryCheckIntervalDefault)
 
 	// Resource limit defaults
-	viper.SetDefault(shared.ConfigKeyResourceLimitsEnabled, shared.ConfigResourceLimitsEnabledDefault)
-	viper.SetDefault(shared.ConfigKeyResourceLimitsMaxFiles, shared.ConfigMaxFilesDefault)
-	viper.SetDefault(shared.ConfigKeyResourceLimitsMaxTotalSize, shared.ConfigMaxTotalSizeDefault)
-	viper.SetDefault(shared.ConfigKeyResourceLimitsFileProcessingTO, shared.ConfigFileProcessingTimeoutSecDefault)
-	viper.SetDefault(shared.ConfigKeyResourceLimitsOverallTO, shared.ConfigOverallTimeoutSecDefault)
-	viper.SetDefault(shared.ConfigKeyResourceLimitsMaxConcurrentReads, shared.ConfigMaxConcurrentReadsDefault)
-	viper.SetDefault(shared.ConfigKeyResourceLimitsRateLimitFilesPerSec, shared.ConfigRateLimitFilesPerSecDefault)
-	viper.SetDefault(shared.ConfigKeyResourceLimitsHardMemoryLimitMB, shared.ConfigHardMemoryLimitMBDefault)
-	viper.SetDefault(shared.ConfigKeyResourceLimitsEnableGracefulDeg, shared.ConfigEnableGracefulDegradationDefault)
-	viper.SetDefault(shared.ConfigKeyResourceLimitsEnableMonitoring, shared.ConfigEnableResourceMonitoringDefault)
+	v.SetDefault(shared.ConfigKeyResourceLimitsEnabled, shared.ConfigResourceLimitsEnabledDefault)
+	v.SetDefault(shared.ConfigKeyResourceLimitsMaxFiles, shared.ConfigMaxFilesDefault)
+	v.SetDefault(shared.ConfigKeyResourceLimitsMaxTotalSize, shared.ConfigMaxTotalSizeDefault)
+	v.SetDefault(shared.ConfigKeyResourceLimitsFileProcessingTO, shared.ConfigFileProcessingTimeoutSecDefault)
+	v.SetDefault(shared.ConfigKeyResourceLimitsOverallTO, shared.ConfigOverallTimeoutSecDefault)
+	v.SetDefault(shared.ConfigKeyResourceLimitsMaxConcurrentReads, shared.ConfigMaxConcurrentReadsDefault)
+	v.SetDefault(shared.ConfigKeyResourceLimitsRateLimitFilesPerSec, shared.ConfigRateLimitFilesPerSecDefault)
+	v.SetDefault(shared.ConfigKeyResourceLimitsHardMemoryLimitMB, DefaultHardMemoryLimitMB())
+	v.SetDefault(shared.ConfigKeyResourceLimitsEnableGracefulDeg, shared.ConfigEnableGracefulDegradationDefault)
+	v.SetDefault(shared.ConfigKeyResourceLimitsEnableMonitoring, shared.ConfigEnableResourceMonitoringDefault)
 
 	// Output configuration defaults
-	viper.SetDefault(shared.ConfigKeyOutputTemplate, shared.ConfigOutputTemplateDefault)
-	viper.SetDefault("output.metadata.includeStats", shared.ConfigMetadataIncludeStatsDefault)
-	viper.SetDefault("output.metadata.includeTimestamp", shared.ConfigMetadataIncludeTimestampDefault)
-	viper.SetDefault("output.metadata.includeFileCount", shared.ConfigMetadataIncludeFileCountDefault)
-	viper.SetDefault("output.metadata.includeSourcePath", shared.ConfigMetadataIncludeSourcePathDefault)
-	viper.SetDefault("output.metadata.includeFileTypes", shared.ConfigMetadataIncludeFileTypesDefault)
-	viper.SetDefault("output.metadata.includeProcessingTime", shared.ConfigMetadataIncludeProcessingTimeDefault)
-	viper.SetDefault("output.metadata.includeTotalSize", shared.ConfigMetadataIncludeTotalSizeDefault)
-	viper.SetDefault("output.metadata.includeMetrics", shared.ConfigMetadataIncludeMetricsDefault)
-	viper.SetDefault("output.markdown.useCodeBlocks", shared.ConfigMarkdownUseCodeBlocksDefault)
-	viper.SetDefault("output.markdown.includeLanguage", shared.ConfigMarkdownIncludeLanguageDefault)
-	viper.SetDefault(shared.ConfigKeyOutputMarkdownHeaderLevel, shared.ConfigMarkdownHeaderLevelDefault)
-	viper.SetDefault("output.markdown.tableOfContents", shared.ConfigMarkdownTableOfContentsDefault)
-	viper.SetDefault("output.markdown.useCollapsible", shared.ConfigMarkdownUseCollapsibleDefault)
-	viper.SetDefault("output.markdown.syntaxHighlighting", shared.ConfigMarkdownSyntaxHighlightingDefault)
-	viper.SetDefault("output.markdown.lineNumbers", shared.ConfigMarkdownLineNumbersDefault)
-	viper.SetDefault("output.markdown.foldLongFiles", shared.ConfigMarkdownFoldLongFilesDefault)
-	viper.SetDefault(shared.ConfigKeyOutputMarkdownMaxLineLen, shared.ConfigMarkdownMaxLineLengthDefault)
-	viper.SetDefault(shared.ConfigKeyOutputMarkdownCustomCSS, shared.ConfigMarkdownCustomCSSDefault)
-	viper.SetDefault(shared.ConfigKeyOutputCustomHeader, shared.ConfigCustomHeaderDefault)
-	viper.SetDefault(shared.ConfigKeyOutputCustomFooter, shared.ConfigCustomFooterDefault)
-	viper.SetDefault(shared.ConfigKeyOutputCustomFileHeader, shared.ConfigCustomFileHeaderDefault)
-	viper.SetDefault(shared.ConfigKeyOutputCustomFileFooter, shared.ConfigCustomFileFooterDefault)
-	viper.SetDefault(shared.ConfigKeyOutputVariables, shared.ConfigTemplateVariablesDefault)
+	v.SetDefault(shared.ConfigKeyOutputTemplate, shared.ConfigOutputTemplateDefault)
+	v.SetDefault("output.metadata.includeStats", shared.ConfigMetadataIncludeStatsDefault)
+	v.SetDefault("output.metadata.includeTimestamp", shared.ConfigMetadataIncludeTimestampDefault)
+	v.SetDefault("output.metadata.includeFileCount", shared.ConfigMetadataIncludeFileCountDefault)
+	v.SetDefault("output.metadata.includeSourcePath", shared.ConfigMetadataIncludeSourcePathDefault)
+	v.SetDefault("output.metadata.includeFileTypes", shared.ConfigMetadataIncludeFileTypesDefault)
+	v.SetDefault("output.metadata.includeProcessingTime", shared.ConfigMetadataIncludeProcessingTimeDefault)
+	v.SetDefault("output.metadata.includeTotalSize", shared.ConfigMetadataIncludeTotalSizeDefault)
+	v.SetDefault("output.metadata.includeMetrics", shared.ConfigMetadataIncludeMetricsDefault)
+	v.SetDefault("output.markdown.useCodeBlocks", shared.ConfigMarkdownUseCodeBlocksDefault)
+	v.SetDefault("output.markdown.includeLanguage", shared.ConfigMarkdownIncludeLanguageDefault)
+	v.SetDefault(shared.ConfigKeyOutputMarkdownHeaderLevel, shared.ConfigMarkdownHeaderLevelDefault)
+	v.SetDefault("output.markdown.tableOfContents", shared.ConfigMarkdownTableOfContentsDefault)
+	v.SetDefault("output.markdown.useCollapsible", shared.ConfigMarkdownUseCollapsibleDefault)
+	v.SetDefault("output.markdown.syntaxHighlighting", shared.ConfigMarkdownSyntaxHighlightingDefault)
+	v.SetDefault("output.markdown.lineNumbers", shared.ConfigMarkdownLineNumbersDefault)
+	v.SetDefault("output.markdown.foldLongFiles", shared.ConfigMarkdownFoldLongFilesDefault)
+	v.SetDefault(shared.ConfigKeyOutputMarkdownMaxLineLen, shared.ConfigMarkdownMaxLineLengthDefault)
+	v.SetDefault(shared.ConfigKeyOutputMarkdownWrapCode, shared.ConfigMarkdownWrapCodeDefault)
+	v.SetDefault(shared.ConfigKeyOutputMarkdownWrapMarker, shared.ConfigMarkdownWrapMarkerDefault)
+	v.SetDefault(shared.ConfigKeyOutputMarkdownCustomCSS, shared.ConfigMarkdownCustomCSSDefault)
+	v.SetDefault(shared.ConfigKeyOutputMarkdownSectionPlaceholder, shared.ConfigMarkdownSectionPlaceholderDefault)
+	v.SetDefault(shared.ConfigKeyOutputPlainDelimiter, shared.ConfigPlainDelimiterDefault)
+	v.SetDefault(shared.ConfigKeyOutputPDFPageSize, shared.ConfigPDFPageSizeDefault)
+	v.SetDefault(shared.ConfigKeyOutputPDFFontSize, shared.ConfigPDFFontSizeDefault)
+	v.SetDefault(shared.ConfigKeyOutputPlugins, shared.ConfigOutputPluginsDefault)
+	v.SetDefault(shared.ConfigKeyOutputCustomHeader, shared.ConfigCustomHeaderDefault)
+	v.SetDefault(shared.ConfigKeyOutputCustomFooter, shared.ConfigCustomFooterDefault)
+	v.SetDefault(shared.ConfigKeyOutputCustomFileHeader, shared.ConfigCustomFileHeaderDefault)
+	v.SetDefault(shared.ConfigKeyOutputCustomFileFooter, shared.ConfigCustomFileFooterDefault)
+	v.SetDefault(shared.ConfigKeyOutputVariables, shared.ConfigTemplateVariablesDefault)
+	v.SetDefault(shared.ConfigKeyOutputPrelude, shared.ConfigOutputPreludeDefault)
+	v.SetDefault(shared.ConfigKeyOutputSourceSpans, shared.ConfigOutputSourceSpansDefault)
+	v.SetDefault(shared.ConfigKeyOutputFileIDs, shared.ConfigOutputFileIDsDefault)
+	v.SetDefault(shared.ConfigKeyOutputAppendRunSummary, shared.ConfigOutputAppendRunSummaryDefault)
+	v.SetDefault(shared.ConfigKeyOutputConfigProvenance, shared.ConfigOutputConfigProvenanceDefault)
+	v.SetDefault(shared.ConfigKeyOutputNormalizeLineEndings, shared.ConfigOutputNormalizeLineEndingsDefault)
+	v.SetDefault(shared.ConfigKeyOutputWhitespaceIndent, shared.ConfigOutputWhitespaceIndentDefault)
+	v.SetDefault(shared.ConfigKeyOutputWhitespaceTabWidth, shared.ConfigOutputWhitespaceTabWidthDefault)
+	v.SetDefault(shared.ConfigKeyOutputWhitespaceTrimTrailing, shared.ConfigOutputWhitespaceTrimTrailingDefault)
+	v.SetDefault(shared.ConfigKeyOutputWhitespaceLanguages, shared.ConfigOutputWhitespaceLanguagesDefault)
+	v.SetDefault(shared.ConfigKeyOutputCompactEnabled, shared.ConfigOutputCompactEnabledDefault)
+	v.SetDefault(shared.ConfigKeyOutputCompactCollapseBlankLines, shared.ConfigOutputCompactCollapseBlankLinesDefault)
+	v.SetDefault(shared.ConfigKeyOutputCompactTrimTrailing, shared.ConfigOutputCompactTrimTrailingDefault)
+	v.SetDefault(shared.ConfigKeyOutputCompactMaxLineLength, shared.ConfigOutputCompactMaxLineLengthDefault)
+	v.SetDefault(shared.ConfigKeyOutputSanitizeStripBOM, shared.ConfigOutputSanitizeStripBOMDefault)
+	v.SetDefault(shared.ConfigKeyOutputSanitizeInvisible, shared.ConfigOutputSanitizeInvisibleDefault)
+	v.SetDefault(shared.ConfigKeyOutputBufferSize, shared.ConfigOutputBufferSizeDefault)
+
+	// Git integration defaults
+	v.SetDefault(shared.ConfigKeyGitEnabled, shared.ConfigGitEnabledDefault)
+	v.SetDefault(shared.ConfigKeyGitAuthorThreshold, shared.ConfigGitAuthorThresholdDefault)
+	v.SetDefault(shared.ConfigKeyGitBlameCache, shared.ConfigGitBlameCacheDefault)
+	v.SetDefault(shared.ConfigKeyGitChurn, shared.ConfigGitChurnDefault)
+	v.SetDefault(shared.ConfigKeyGitChurnDays, shared.ConfigGitChurnDaysDefault)
+	v.SetDefault(shared.ConfigKeyGitChurnTop, shared.ConfigGitChurnTopDefault)
+	v.SetDefault(shared.ConfigKeyGitLFSAction, shared.ConfigGitLFSActionDefault)
+	v.SetDefault(shared.ConfigKeyGitLFSMaxFetchSize, shared.ConfigGitLFSMaxFetchSizeDefault)
+
+	// Generated text detection defaults
+	v.SetDefault(shared.ConfigKeyGeneratedTextEnabled, shared.ConfigGeneratedTextEnabledDefault)
+	v.SetDefault(shared.ConfigKeyGeneratedTextAction, shared.ConfigGeneratedTextActionDefault)
+	v.SetDefault(shared.ConfigKeyGeneratedTextMinSize, shared.ConfigGeneratedTextMinSizeDefault)
+	v.SetDefault(shared.ConfigKeyGeneratedTextMaxLineLength, shared.ConfigGeneratedTextMaxLineLengthDefault)
+	v.SetDefault(shared.ConfigKeyGeneratedTextEntropy, shared.ConfigGeneratedTextEntropyDefault)
+	v.SetDefault(shared.ConfigKeySecurityScanEnabled, shared.ConfigSecurityScanEnabledDefault)
+	v.SetDefault(shared.ConfigKeyRedactionEnabled, shared.ConfigRedactionEnabledDefault)
+	v.SetDefault(shared.ConfigKeyRedactionPatterns, shared.ConfigRedactionPatternsDefault)
+	v.SetDefault(shared.ConfigKeyStripCommentsEnabled, shared.ConfigStripCommentsEnabledDefault)
+	v.SetDefault(shared.ConfigKeyStripCommentsLanguages, shared.ConfigStripCommentsLanguagesDefault)
+
+	// Performance defaults
+	v.SetDefault(shared.ConfigKeyPerformanceFormatWorkers, shared.ConfigPerformanceFormatWorkersDefault)
+	v.SetDefault(shared.ConfigKeyPerformanceHashAlgorithm, shared.ConfigPerformanceHashAlgorithmDefault)
+	v.SetDefault(shared.ConfigKeyPerformanceContentCache, shared.ConfigPerformanceContentCacheDefault)
+	v.SetDefault(shared.ConfigKeyPerformanceMaxOpenFiles, shared.ConfigPerformanceMaxOpenFilesDefault)
+	v.SetDefault(shared.ConfigKeyPerformanceRaiseOpenFileLimit, shared.ConfigPerformanceRaiseOpenFileLimitDefault)
+	v.SetDefault(shared.ConfigKeyPerformancePrefetch, shared.ConfigPerformancePrefetchDefault)
+	v.SetDefault(shared.ConfigKeyPerformancePrefetchBytes, shared.ConfigPerformancePrefetchBytesDefault)
+	v.SetDefault(shared.ConfigKeyPerformancePrefetchReaders, shared.ConfigPerformancePrefetchReadersDefault)
+
+	// Share defaults
+	v.SetDefault(shared.ConfigKeyShareBackend, shared.ConfigShareBackendDefault)
+	v.SetDefault(shared.ConfigKeyShareURL, "")
+	v.SetDefault(shared.ConfigKeyShareTokenEnv, "")
+	v.SetDefault(shared.ConfigKeyShareMaxSize, shared.ConfigShareMaxSizeDefault)
+	v.SetDefault(shared.ConfigKeySharePublic, shared.ConfigSharePublicDefault)
+
+	// UI defaults
+	v.SetDefault(shared.ConfigKeyUIProgressStyle, shared.ConfigUIProgressStyleDefault)
+	v.SetDefault(shared.ConfigKeyUITheme, shared.ConfigUIThemeDefault)
+
+	// Warning defaults
+	v.SetDefault(shared.ConfigKeyWarningsSlowFileSec, shared.ConfigWarningsSlowFileSecDefault)
+
+	// Token estimation defaults
+	v.SetDefault(shared.ConfigKeyTokensEstimate, shared.ConfigTokensEstimateDefault)
+	v.SetDefault(shared.ConfigKeyTokensBytesPerToken, shared.ConfigTokensBytesPerTokenDefault)
+	v.SetDefault(shared.ConfigKeyTokensLanguages, shared.ConfigTokensLanguagesDefault)
+	v.SetDefault(shared.ConfigKeyCodeMetricsEnabled, shared.ConfigCodeMetricsEnabledDefault)
+
+	// Collection defaults
+	v.SetDefault(shared.ConfigKeyCollectionRollups, shared.ConfigCollectionRollupsDefault)
+	v.SetDefault(shared.ConfigKeyCollectionRespectGitignore, shared.ConfigCollectionRespectGitignoreDefault)
+	v.SetDefault(shared.ConfigKeyCollectionGlobalGitignore, shared.ConfigCollectionGlobalGitignoreDefault)
+	v.SetDefault(shared.ConfigKeyCollectionOutlierPercent, shared.ConfigCollectionOutlierPercentDefault)
+	v.SetDefault(shared.ConfigKeyBudgets, shared.ConfigBudgetsDefault)
+	v.SetDefault(shared.ConfigKeyOrderPriority, shared.ConfigOrderPriorityDefault)
+	v.SetDefault(shared.ConfigKeyTransformsWasm, shared.ConfigTransformsWasmDefault)
+
+	// Documentation language defaults
+	v.SetDefault(shared.ConfigKeyDocLanguageDetect, shared.ConfigDocLanguageDetectDefault)
+	v.SetDefault(shared.ConfigKeyDocLanguageInclude, shared.ConfigDocLanguageIncludeDefault)
+
+	// CODEOWNERS defaults
+	v.SetDefault(shared.ConfigKeyCodeOwnersEnabled, shared.ConfigCodeOwnersEnabledDefault)
+	v.SetDefault(shared.ConfigKeyCodeOwnersPath, shared.ConfigCodeOwnersPathDefault)
+
+	// Annotation defaults
+	v.SetDefault(shared.ConfigKeyAnnotations, shared.ConfigAnnotationsDefault)
+
+	// Profile defaults
+	v.SetDefault(shared.ConfigKeyProfiles, shared.ConfigProfilesDefault)
 }
@@ -2,6 +2,7 @@ package config_test
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/spf13/viper"
@@ -27,12 +28,12 @@ func TestDefaultConfig(t *testing.T) {
 	testutil.ResetViperConfig(t, tmpDir)
 
 	// Check defaults
-	defaultSizeLimit := config.FileSizeLimit()
+	defaultSizeLimit := config.Global().FileSizeLimit()
 	if defaultSizeLimit != defaultFileSizeLimit {
 		t.Errorf("Expected default file size limit of 5242880, got %d", defaultSizeLimit)
 	}
 
-	ignoredDirs := config.IgnoredDirectories()
+	ignoredDirs := config.Global().IgnoredDirectories()
 	if len(ignoredDirs) == 0 {
 		t.Error("Expected some default ignored directories, got none")
 	}
@@ -99,17 +100,127 @@ func TestLoadConfigWithValidation(t *testing.T) {
 	config.LoadConfig()
 
 	// Should have fallen back to defaults due to validation failure
-	if config.FileSizeLimit() != int64(shared.ConfigFileSizeLimitDefault) {
-		t.Errorf("Expected default file size limit after validation failure, got %d", config.FileSizeLimit())
+	if config.Global().FileSizeLimit() != int64(shared.ConfigFileSizeLimitDefault) {
+		t.Errorf("Expected default file size limit after validation failure, got %d", config.Global().FileSizeLimit())
 	}
-	if containsString(config.IgnoredDirectories(), "") {
+	if containsString(config.Global().IgnoredDirectories(), "") {
 		t.Errorf(
 			"Expected ignored directories not to contain empty string after validation failure, got %v",
-			config.IgnoredDirectories(),
+			config.Global().IgnoredDirectories(),
 		)
 	}
 }
 
+// TestLoadConfigPartialFile tests that keys a config file leaves out keep their defaults.
+func TestLoadConfigPartialFile(t *testing.T) {
+	tempDir := t.TempDir()
+	testutil.CreateTestFile(t, tempDir, "config.yaml", []byte("fileTypes:\n  customLanguages:\n    .stress: stress\n"))
+	t.Cleanup(func() { testutil.ResetViperConfig(t, "") })
+
+	viper.Reset()
+	viper.AddConfigPath(tempDir)
+	config.LoadConfig()
+
+	if err := config.LoadError(); err != nil {
+		t.Fatalf("partial config file was rejected: %v", err)
+	}
+	if got := config.Global().CustomLanguages()[".stress"]; got != "stress" {
+		t.Errorf("custom language for .stress = %q, want stress", got)
+	}
+	if config.Global().FileSizeLimit() != int64(shared.ConfigFileSizeLimitDefault) {
+		t.Errorf("file size limit = %d, want the default", config.Global().FileSizeLimit())
+	}
+}
+
+// TestLoadConfigFrom tests that an explicit config file is read without searching the config
+// directories and that a missing or invalid one is an error instead of a fallback.
+func TestLoadConfigFrom(t *testing.T) {
+	configHome := t.TempDir()
+	userDir := filepath.Join(configHome, shared.AppName)
+	testutil.MustSucceed(t, os.MkdirAll(userDir, shared.TestDirPermission), "creating config dir")
+	testutil.CreateTestFile(t, userDir, "config.yaml", []byte("fileSizeLimit: 2048\n"))
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	t.Cleanup(func() { testutil.ResetViperConfig(t, "") })
+	dir := t.TempDir()
+	valid := testutil.CreateTestFile(t, dir, "valid.yaml", []byte("fileSizeLimit: 123456\n"))
+	invalid := testutil.CreateTestFile(t, dir, "invalid.yaml", []byte("fileSizeLimit: 100\n"))
+
+	tests := []struct {
+		name      string
+		path      string
+		wantLimit int64
+		wantErr   bool
+	}{
+		{name: "defaults without a file", path: "", wantLimit: int64(shared.ConfigFileSizeLimitDefault)},
+		{name: "explicit file", path: valid, wantLimit: testFileSizeLimit},
+		{name: "missing file", path: filepath.Join(dir, "missing.yaml"), wantErr: true},
+		{name: "invalid file", path: invalid, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Reset()
+			err := config.LoadConfigFrom(tt.path)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("LoadConfigFrom(%q) succeeded, want an error", tt.path)
+				}
+
+				return
+			}
+			testutil.MustSucceed(t, err, "LoadConfigFrom")
+			if got := config.Global().FileSizeLimit(); got != tt.wantLimit {
+				t.Errorf("file size limit = %d, want %d", got, tt.wantLimit)
+			}
+		})
+	}
+}
+
+// TestLoadConfigFromEnv tests that $GIBIDIFY_CONFIG is read instead of the config directories,
+// that a missing or invalid file is an error leaving the defaults, and that the config
+// directories are searched without it.
+func TestLoadConfigFromEnv(t *testing.T) {
+	configHome := t.TempDir()
+	userDir := testutil.CreateTestDirectory(t, configHome, shared.AppName)
+	testutil.CreateTestFile(t, userDir, "config.yaml", []byte("fileSizeLimit: 2048\n"))
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	t.Cleanup(func() { testutil.ResetViperConfig(t, "") })
+	dir := t.TempDir()
+	valid := testutil.CreateTestFile(t, dir, "valid.yaml", []byte("fileSizeLimit: 123456\n"))
+	invalid := testutil.CreateTestFile(t, dir, "invalid.yaml", []byte("fileSizeLimit: 100\n"))
+
+	tests := []struct {
+		name      string
+		env       string
+		wantLimit int64
+		wantErr   bool
+	}{
+		{name: "config directories without the variable", env: "", wantLimit: 2048},
+		{name: "file from the variable", env: valid, wantLimit: testFileSizeLimit},
+		{
+			name:      "missing file",
+			env:       filepath.Join(dir, "missing.yaml"),
+			wantLimit: int64(shared.ConfigFileSizeLimitDefault),
+			wantErr:   true,
+		},
+		{name: "invalid file", env: invalid, wantLimit: int64(shared.ConfigFileSizeLimitDefault), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Reset()
+			t.Setenv(shared.ConfigEnvVar, tt.env)
+			err := config.LoadConfigFromEnv()
+			if tt.wantErr != (err != nil) {
+				t.Errorf("LoadConfigFromEnv() error = %v, want error %v", err, tt.wantErr)
+			}
+			if got := config.Global().FileSizeLimit(); got != tt.wantLimit {
+				t.Errorf("file size limit = %d, want %d", got, tt.wantLimit)
+			}
+		})
+	}
+}
+
 // Helper functions
 
 func containsString(slice []string, item string) bool {
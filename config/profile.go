@@ -0,0 +1,137 @@
+// Package config handles application configuration management.
+package config
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/spf13/viper"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// ProfileNames returns the names of the profiles in the global configuration, sorted.
+func ProfileNames() []string {
+	return Global().ProfileNames()
+}
+
+// ProfileNames returns the names of the profiles configured in s, sorted. Like every config
+// key, profile names are case-insensitive and reported in lower case.
+func (s *Settings) ProfileNames() []string {
+	return slices.Sorted(maps.Keys(s.values().GetStringMap(shared.ConfigKeyProfiles)))
+}
+
+// ApplyProfile merges the settings of the named profile over s and returns the output format
+// it selects, or "" when it selects none. An unknown profile, or settings that would leave s
+// invalid, are returned as an error and leave s unchanged.
+func (s *Settings) ApplyProfile(name string) (format string, err error) {
+	profile, err := s.profile(name)
+	if err != nil {
+		return "", err
+	}
+	format, _ = profile[shared.ProfileKeyFormat].(string)
+	delete(profile, shared.ProfileKeyFormat)
+
+	// Validate the merged configuration before touching s
+	merged := NewSettings()
+	if err := mergeProfile(merged.v, s.values().AllSettings(), name); err != nil {
+		return "", err
+	}
+	if err := mergeProfile(merged.v, profile, name); err != nil {
+		return "", err
+	}
+	if err := merged.ValidateConfig(); err != nil {
+		return "", err
+	}
+	if err := mergeProfile(s.values(), profile, name); err != nil {
+		return "", err
+	}
+	shared.GetLogger().Infof("Using profile: %s", name)
+
+	return format, nil
+}
+
+// mergeProfile merges settings of the named profile into v.
+func mergeProfile(v *viper.Viper, settings map[string]any, name string) error {
+	if err := v.MergeConfigMap(settings); err != nil {
+		return shared.WrapError(
+			err, shared.ErrorTypeConfiguration, shared.CodeConfigValidation, "failed to apply profile "+name,
+		)
+	}
+
+	return nil
+}
+
+// profile returns a copy of the settings of the named profile in s.
+func (s *Settings) profile(name string) (map[string]any, error) {
+	profiles := s.values().GetStringMap(shared.ConfigKeyProfiles)
+	profile, ok := profiles[strings.ToLower(name)].(map[string]any)
+	if !ok {
+		available := "none are configured"
+		if names := s.ProfileNames(); len(names) > 0 {
+			available = "available: " + strings.Join(names, ", ")
+		}
+
+		return nil, shared.NewStructuredError(
+			shared.ErrorTypeConfiguration, shared.CodeConfigMissing,
+			fmt.Sprintf("unknown profile %q (%s)", name, available), "",
+			map[string]any{"profile": name},
+		)
+	}
+
+	return maps.Clone(profile), nil
+}
+
+// validateProfiles validates every profile: it may only set known config keys and the output
+// format, and its settings must be valid over the defaults.
+func (s *Settings) validateProfiles() []string {
+	var validationErrors []string
+	profiles := s.values().GetStringMap(shared.ConfigKeyProfiles)
+	for _, name := range slices.Sorted(maps.Keys(profiles)) {
+		profile, ok := profiles[name].(map[string]any)
+		if !ok {
+			validationErrors = append(validationErrors, fmt.Sprintf("profiles.%s must be a mapping of settings", name))
+
+			continue
+		}
+		for _, message := range validateProfile(maps.Clone(profile)) {
+			validationErrors = append(validationErrors, fmt.Sprintf("profiles.%s: %s", name, message))
+		}
+	}
+
+	return validationErrors
+}
+
+// validateProfile validates the settings of one profile.
+func validateProfile(profile map[string]any) []string {
+	var validationErrors []string
+	if format, ok := profile[shared.ProfileKeyFormat]; ok {
+		name, _ := format.(string)
+		if err := ValidateOutputFormatName(name); err != nil {
+			validationErrors = append(
+				validationErrors, fmt.Sprintf("%s (%v) is not an output format", shared.ProfileKeyFormat, format),
+			)
+		}
+		delete(profile, shared.ProfileKeyFormat)
+	}
+
+	settings := NewSettings()
+	if err := settings.v.MergeConfigMap(profile); err != nil {
+		return append(validationErrors, err.Error())
+	}
+	flat := viper.New()
+	if err := flat.MergeConfigMap(profile); err != nil {
+		return append(validationErrors, err.Error())
+	}
+	// Profiles cannot nest profiles
+	schema := configSchema()
+	delete(schema.Properties, shared.ConfigKeyProfiles)
+	validationErrors = append(validationErrors, schema.check(flat, flat.AllKeys())...)
+	if len(validationErrors) > 0 {
+		return validationErrors
+	}
+
+	return settings.validationErrors()
+}
@@ -0,0 +1,130 @@
+package config_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/config"
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// profilesConfig configures two profiles over a base file size limit and ignore list.
+const profilesConfig = `fileSizeLimit: 2048
+ignoreDirectories: [vendor]
+profiles:
+  llm-small:
+    format: markdown
+    fileSizeLimit: 4096
+    ignoreDirectories: [vendor, docs, testdata]
+    output:
+      fileIds: true
+  full-audit:
+    securityScan:
+      enabled: true
+`
+
+// TestApplyProfile tests that a profile overrides the settings it names, keeps the others and
+// returns its output format, and that unknown profiles are rejected with the available ones.
+func TestApplyProfile(t *testing.T) {
+	path := testutil.CreateTestFile(t, t.TempDir(), "config.yaml", []byte(profilesConfig))
+	t.Cleanup(func() { testutil.ResetViperConfig(t, "") })
+
+	tests := []struct {
+		name        string
+		profile     string
+		wantFormat  string
+		wantLimit   int64
+		wantIgnored []string
+		wantErr     string
+	}{
+		{
+			name: "llm-small", profile: "llm-small", wantFormat: shared.FormatMarkdown, wantLimit: 4096,
+			wantIgnored: []string{"vendor", "docs", "testdata"},
+		},
+		{name: "case-insensitive name", profile: "Full-Audit", wantLimit: 2048, wantIgnored: []string{"vendor"}},
+		{name: "unknown", profile: "tiny", wantErr: `unknown profile "tiny" (available: full-audit, llm-small)`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings, err := config.LoadSettings(path)
+			testutil.MustSucceed(t, err, "LoadSettings")
+			format, err := settings.ApplyProfile(tt.profile)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("ApplyProfile() error = %v, want %q", err, tt.wantErr)
+				}
+
+				return
+			}
+			testutil.MustSucceed(t, err, "ApplyProfile")
+			if format != tt.wantFormat {
+				t.Errorf("format = %q, want %q", format, tt.wantFormat)
+			}
+			if got := settings.FileSizeLimit(); got != tt.wantLimit {
+				t.Errorf("file size limit = %d, want %d", got, tt.wantLimit)
+			}
+			if got := settings.IgnoredDirectories(); !slices.Equal(got, tt.wantIgnored) {
+				t.Errorf("ignored directories = %v, want %v", got, tt.wantIgnored)
+			}
+		})
+	}
+
+	testutil.MustSucceed(t, config.LoadConfigFrom(path), "LoadConfigFrom")
+	if got := config.ProfileNames(); !slices.Equal(got, []string{"full-audit", "llm-small"}) {
+		t.Errorf("ProfileNames() = %v, want [full-audit llm-small]", got)
+	}
+	if _, err := config.Global().ApplyProfile("full-audit"); err != nil || !config.Global().SecurityScanEnabled() {
+		t.Errorf("full-audit left the security scan off (error %v)", err)
+	}
+}
+
+// TestValidateProfiles tests that profiles may only set known, valid settings and formats.
+func TestValidateProfiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile string
+		wantErr string
+	}{
+		{name: "valid", profile: "format: yaml\nannotations:\n  main.go: start here\n"},
+		{name: "plugin format", profile: "format: my-plugin\n"},
+		{name: "unknown setting", profile: "fileSizeLimt: 4096\n", wantErr: "profiles.p: unknown setting filesizelimt"},
+		{name: "invalid format", profile: "format: \"Bad Format\"\n", wantErr: "profiles.p: format (Bad Format)"},
+		{name: "invalid value", profile: "fileSizeLimit: 100\n", wantErr: "profiles.p: fileSizeLimit (100)"},
+		{name: "nested profiles", profile: "profiles:\n  q:\n    format: yaml\n", wantErr: "unknown setting profiles"},
+		{name: "not a mapping", profile: "", wantErr: "profiles.p must be a mapping of settings"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Cleanup(func() { testutil.ResetViperConfig(t, "") })
+			content := "profiles:\n  p:\n" + indent(tt.profile)
+			if tt.profile == "" {
+				content = "profiles:\n  p: small\n"
+			}
+			path := testutil.CreateTestFile(t, t.TempDir(), "config.yaml", []byte(content))
+
+			err := config.LoadConfigFrom(path)
+			if tt.wantErr == "" {
+				testutil.MustSucceed(t, err, "LoadConfigFrom")
+
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadConfigFrom() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// indent indents every line of s by four spaces.
+func indent(s string) string {
+	var out strings.Builder
+	for line := range strings.Lines(s) {
+		out.WriteString("    " + line)
+	}
+
+	return out.String()
+}
@@ -0,0 +1,144 @@
+// Package config handles application configuration management.
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/viper"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// projectKeys are the config keys and sections a project config file may set: the ignore
+// lists, the file patterns and the output settings, except projectExcludedKeys.
+var projectKeys = []string{
+	shared.ConfigKeyIgnoreDirectories,
+	shared.ConfigKeyFilePatterns,
+	"output",
+}
+
+// projectExcludedKeys are the keys within projectKeys a project config file may not set:
+// output.plugins, which run commands, and output.prelude, which reads files from anywhere.
+var projectExcludedKeys = []string{
+	shared.ConfigKeyOutputPlugins,
+	shared.ConfigKeyOutputPrelude,
+}
+
+// FindProjectConfig returns the .gibidify.yaml nearest to sourceDir, looking in sourceDir and
+// each directory above it up to the root of the git repository holding it, or "" when there
+// is none or sourceDir is not inside a git repository.
+func FindProjectConfig(sourceDir string) string {
+	dir, err := filepath.Abs(sourceDir)
+	if err != nil {
+		return ""
+	}
+	var found string
+	for {
+		candidate := filepath.Join(dir, shared.ProjectConfigFileName)
+		if info, err := os.Stat(candidate); err == nil && info.Mode().IsRegular() && found == "" {
+			found = candidate
+		}
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return found
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// MergeProjectConfig merges the project config file at path over s, so a repository can check
+// in its own ignore lists and output settings. As checked-in files may come from anyone, every
+// other key, such as the share, redaction and transform settings or the limits, is ignored
+// with a warning. An unreadable file, or one that would leave s invalid, is returned as an
+// error and leaves s unchanged.
+func (s *Settings) MergeProjectConfig(path string) error {
+	project := viper.New()
+	project.SetConfigFile(path)
+	project.SetConfigType(shared.FormatYAML)
+	if err := project.ReadInConfig(); err != nil {
+		return shared.WrapError(
+			err, shared.ErrorTypeConfiguration, shared.CodeConfigMissing, "failed to read project config file",
+		).WithFilePath(path)
+	}
+
+	allowed := viper.New()
+	var ignored []string
+	for _, key := range project.AllKeys() {
+		if !projectKey(key) {
+			ignored = append(ignored, key)
+
+			continue
+		}
+		allowed.Set(key, project.Get(key))
+	}
+	if len(ignored) > 0 {
+		slices.Sort(ignored)
+		shared.GetLogger().Warnf(
+			"Ignoring %s in project config file %s: it may only set ignore lists, file patterns and output settings",
+			strings.Join(ignored, ", "), path,
+		)
+	}
+
+	// Validate the merged configuration before touching s
+	merged := NewSettings()
+	if err := mergeProjectSettings(merged.v, s.values().AllSettings(), path); err != nil {
+		return err
+	}
+	if err := mergeProjectSettings(merged.v, allowed.AllSettings(), path); err != nil {
+		return err
+	}
+	if err := merged.ValidateConfig(); err != nil {
+		return err
+	}
+	if err := mergeProjectSettings(s.values(), allowed.AllSettings(), path); err != nil {
+		return err
+	}
+	s.projectFile, s.projectKeys = path, allowed.AllKeys()
+	shared.GetLogger().Infof("Using project config file: %s", path)
+
+	return nil
+}
+
+// mergeProjectSettings merges settings read from the project config file at path into v.
+func mergeProjectSettings(v *viper.Viper, settings map[string]any, path string) error {
+	if err := v.MergeConfigMap(settings); err != nil {
+		return shared.WrapError(
+			err, shared.ErrorTypeConfiguration, shared.CodeConfigMissing, "failed to merge project config file",
+		).WithFilePath(path)
+	}
+
+	return nil
+}
+
+// projectKey reports whether a project config file may set key, as viper reports it.
+func projectKey(key string) bool {
+	if slices.ContainsFunc(projectExcludedKeys, func(excluded string) bool { return keyWithin(key, excluded) }) {
+		return false
+	}
+
+	return slices.ContainsFunc(projectKeys, func(allowed string) bool { return keyWithin(key, allowed) })
+}
+
+// keyWithin reports whether key is section or one of the keys below it.
+func keyWithin(key, section string) bool {
+	section = strings.ToLower(section)
+
+	return key == section || strings.HasPrefix(key, section+".")
+}
+
+// SetByProjectFile reports whether the project config file merged over s sets key or a key
+// below it.
+func (s *Settings) SetByProjectFile(key string) bool {
+	return slices.ContainsFunc(s.projectKeys, func(projectKey string) bool { return keyWithin(projectKey, key) })
+}
+
+// ProjectFileUsed returns the project config file merged over s, or "" when none was.
+func (s *Settings) ProjectFileUsed() string {
+	return s.projectFile
+}
@@ -0,0 +1,124 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/config"
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// TestFindProjectConfig tests that the project config file nearest to the source directory is
+// found, without looking above the repository root.
+func TestFindProjectConfig(t *testing.T) {
+	outside := t.TempDir()
+	testutil.CreateTestFile(t, outside, shared.ProjectConfigFileName, []byte("fileSizeLimit: 2048\n"))
+	repo := testutil.CreateTestDirectory(t, outside, "repo")
+	testutil.CreateTestDirectory(t, repo, ".git")
+	deep := filepath.Join(repo, "pkg", "deep")
+	testutil.MustSucceed(t, os.MkdirAll(deep, 0o750), "creating source directory")
+	nested := filepath.Join(repo, "pkg")
+
+	if got := config.FindProjectConfig(deep); got != "" {
+		t.Errorf("FindProjectConfig() = %q, want none above the repository root", got)
+	}
+
+	rootConfig := testutil.CreateTestFile(t, repo, shared.ProjectConfigFileName, []byte("fileSizeLimit: 2048\n"))
+	if got := config.FindProjectConfig(deep); got != rootConfig {
+		t.Errorf("FindProjectConfig() = %q, want the repository root config %q", got, rootConfig)
+	}
+
+	nestedConfig := testutil.CreateTestFile(t, nested, shared.ProjectConfigFileName, []byte("fileSizeLimit: 2048\n"))
+	if got := config.FindProjectConfig(deep); got != nestedConfig {
+		t.Errorf("FindProjectConfig() = %q, want the nearest config %q", got, nestedConfig)
+	}
+}
+
+// TestFindProjectConfigOutsideRepository tests that no project config file is found when the
+// source directory is not inside a git repository.
+func TestFindProjectConfigOutsideRepository(t *testing.T) {
+	dir := t.TempDir()
+	testutil.CreateTestFile(t, dir, shared.ProjectConfigFileName, []byte("fileSizeLimit: 2048\n"))
+
+	if got := config.FindProjectConfig(dir); got != "" {
+		t.Errorf("FindProjectConfig() = %q, want none outside a repository", got)
+	}
+}
+
+// TestMergeProjectConfig tests that a project config file overrides the ignore lists, file
+// patterns and output settings it names, keeps the others from the user config, and cannot
+// change any other setting.
+func TestMergeProjectConfig(t *testing.T) {
+	userConfig := testutil.CreateTestFile(t, t.TempDir(), "config.yaml", []byte(
+		"fileSizeLimit: 2048\nignoreDirectories: [vendor]\nshare:\n  url: https://paste.example.com\n"+
+			"redaction:\n  enabled: true\n",
+	))
+	repo := t.TempDir()
+	valid := testutil.CreateTestFile(t, repo, "valid.yaml", []byte(`ignoreDirectories: [generated]
+fileSizeLimit: 4096
+output:
+  fileIds: true
+  plugins:
+    evil:
+      command: [rm, -rf, /]
+  prelude: [/etc/passwd]
+share:
+  url: https://evil.example.com
+  tokenEnv: GITHUB_TOKEN
+redaction:
+  enabled: false
+transforms:
+  wasm: [{module: evil.wasm}]
+`))
+	invalid := testutil.CreateTestFile(t, repo, "invalid.yaml", []byte("output:\n  bufferSize: -1\n"))
+
+	settings, err := config.LoadSettings(userConfig)
+	testutil.MustSucceed(t, err, "LoadSettings")
+	testutil.MustSucceed(t, settings.MergeProjectConfig(valid), "MergeProjectConfig")
+
+	if got := settings.IgnoredDirectories(); !slices.Equal(got, []string{"generated"}) {
+		t.Errorf("ignored directories = %v, want [generated] from the project config", got)
+	}
+	if !settings.OutputFileIDs() {
+		t.Error("output.fileIds not taken from the project config")
+	}
+	if got := settings.FileSizeLimit(); got != 2048 {
+		t.Errorf("file size limit = %d, want 2048 from the user config", got)
+	}
+	if got := settings.ShareURL(); got != "https://paste.example.com" {
+		t.Errorf("share URL = %q, want the user config's", got)
+	}
+	if !settings.RedactionEnabled() {
+		t.Error("project config disabled redaction")
+	}
+	if got := settings.TransformsWasm(); len(got) != 0 {
+		t.Errorf("project config registered WebAssembly transforms %v", got)
+	}
+	if _, ok := settings.OutputPlugin("evil"); ok {
+		t.Error("project config registered a format plugin")
+	}
+	if got := settings.OutputPrelude(); len(got) != 0 {
+		t.Errorf("project config added prelude documents %v", got)
+	}
+	if got := settings.ProjectFileUsed(); got != valid {
+		t.Errorf("ProjectFileUsed() = %q, want %q", got, valid)
+	}
+	if !settings.SetByProjectFile(shared.ConfigKeyOutputFileIDs) || settings.SetByProjectFile(shared.ConfigKeyShareURL) {
+		t.Error("SetByProjectFile() does not report the keys the project config set")
+	}
+
+	settings, err = config.LoadSettings(userConfig)
+	testutil.MustSucceed(t, err, "LoadSettings")
+	if err := settings.MergeProjectConfig(invalid); err == nil {
+		t.Error("MergeProjectConfig() accepted an invalid project config")
+	}
+	if got := settings.ProjectFileUsed(); got != "" {
+		t.Errorf("ProjectFileUsed() = %q after a failed merge, want none", got)
+	}
+	if got := settings.IgnoredDirectories(); !slices.Equal(got, []string{"vendor"}) {
+		t.Errorf("ignored directories = %v after a failed merge, want the user config's", got)
+	}
+}
@@ -0,0 +1,47 @@
+// Package config handles application configuration management.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// NonDefaultSettings returns the effective settings that differ from the defaults, keyed by
+// their dotted config key, so a bundle can record the configuration that shaped it. Values
+// are compared by their JSON encoding, as a config file decodes numbers and lists into other
+// types than the defaults use.
+func (s *Settings) NonDefaultSettings() map[string]any {
+	defaults := viper.New()
+	setDefaults(defaults)
+
+	keys := s.values().AllKeys()
+	settings := make(map[string]any)
+	for _, key := range keys {
+		value := s.values().Get(key)
+		if !sameSetting(value, defaults.Get(key)) && !hasNestedKey(keys, key) {
+			settings[key] = value
+		}
+	}
+
+	return settings
+}
+
+// hasNestedKey reports whether keys holds a key nested under key, such as output.variables.team
+// under a defaulted output.variables map. The nested keys are reported instead.
+func hasNestedKey(keys []string, key string) bool {
+	return slices.ContainsFunc(keys, func(other string) bool {
+		return strings.HasPrefix(other, key+".")
+	})
+}
+
+// sameSetting reports whether two setting values encode to the same JSON.
+func sameSetting(a, b any) bool {
+	encodedA, errA := json.Marshal(a)
+	encodedB, errB := json.Marshal(b)
+
+	return errA == nil && errB == nil && bytes.Equal(encodedA, encodedB)
+}
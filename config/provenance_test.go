@@ -0,0 +1,50 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+
+	"github.com/ivuorinen/gibidify/config"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// TestNonDefaultSettings tests that only settings differing from the defaults are reported,
+// even when a config file restates a default with a different type.
+func TestNonDefaultSettings(t *testing.T) {
+	tempDir := t.TempDir()
+	testutil.CreateTestFile(t, tempDir, "config.yaml", []byte(`fileSizeLimit: 5242880
+ignoreDirectories: [vendor, node_modules, .git, dist, build, target, bower_components, cache, tmp]
+maxConcurrency: 3
+output:
+  fileIds: true
+  variables:
+    team: core
+`))
+	t.Cleanup(func() { testutil.ResetViperConfig(t, "") })
+
+	viper.Reset()
+	viper.AddConfigPath(tempDir)
+	config.LoadConfig()
+	testutil.MustSucceed(t, config.LoadError(), "loading config")
+
+	settings := config.Global().NonDefaultSettings()
+	want := map[string]any{"maxconcurrency": 3, "output.fileids": true, "output.variables.team": "core"}
+	if len(settings) != len(want) {
+		t.Errorf("NonDefaultSettings() = %v, want %v", settings, want)
+	}
+	for key, value := range want {
+		if settings[key] != value {
+			t.Errorf("NonDefaultSettings()[%q] = %v, want %v", key, settings[key], value)
+		}
+	}
+}
+
+// TestNonDefaultSettingsDefaults tests that the default configuration reports nothing.
+func TestNonDefaultSettingsDefaults(t *testing.T) {
+	testutil.ResetViperConfig(t, t.TempDir())
+
+	if settings := config.Global().NonDefaultSettings(); len(settings) != 0 {
+		t.Errorf("NonDefaultSettings() = %v, want none", settings)
+	}
+}
@@ -0,0 +1,345 @@
+// Package config handles application configuration management.
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/spf13/viper"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// schemaDialect is the JSON Schema draft JSONSchema describes the configuration in.
+const schemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// JSON Schema types of the settings.
+const (
+	schemaTypeBoolean = "boolean"
+	schemaTypeInteger = "integer"
+	schemaTypeNumber  = "number"
+	schemaTypeString  = "string"
+	schemaTypeArray   = "array"
+	schemaTypeObject  = "object"
+)
+
+// schemaTypeNames describes the JSON Schema types in validation errors.
+var schemaTypeNames = map[string]string{
+	schemaTypeBoolean: "a boolean",
+	schemaTypeInteger: "an integer",
+	schemaTypeNumber:  "a number",
+	schemaTypeString:  "a string",
+	schemaTypeArray:   "a list",
+	schemaTypeObject:  "a mapping",
+}
+
+// schemaEnums lists the values of the string settings that take one of a fixed set.
+var schemaEnums = map[string][]string{
+	shared.ConfigKeyPerformanceHashAlgorithm: shared.HashAlgorithms(),
+	shared.ConfigKeyShareBackend:             {shared.ShareBackendGist, shared.ShareBackendPaste},
+	shared.ConfigKeyUIProgressStyle: {
+		shared.UIProgressStyleBar, shared.UIProgressStyleSpinner, shared.UIProgressStyleDots, shared.UIProgressStyleNone,
+	},
+	shared.ConfigKeyUITheme: {shared.UIThemeAuto, shared.UIThemeUnicode, shared.UIThemeASCII},
+}
+
+// schemaSuggestDistance is the largest edit distance between an unknown setting and a known
+// one still suggested in its place.
+const schemaSuggestDistance = 2
+
+// schemaNode is the JSON Schema of a setting, or of a section of settings.
+type schemaNode struct {
+	Schema      string   `json:"$schema,omitempty"`
+	Title       string   `json:"title,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Type        string   `json:"type,omitempty"`
+	Enum        []string `json:"enum,omitempty"`
+	Default     any      `json:"default,omitempty"`
+	// Items is the schema of the elements of a list; nil allows any element.
+	Items      *schemaNode            `json:"items,omitempty"`
+	Properties map[string]*schemaNode `json:"properties,omitempty"`
+	// AdditionalProperties is false for a section, or the *schemaNode of the values of a
+	// mapping with free keys; nil allows any value.
+	AdditionalProperties any `json:"additionalProperties,omitempty"`
+}
+
+// JSONSchema returns a JSON Schema describing every setting of the configuration file, with
+// its type and default value. Config files are validated against the same schema.
+func JSONSchema() ([]byte, error) {
+	schema := configSchema()
+	schema.Schema = schemaDialect
+	schema.Title = shared.AppName + " configuration"
+
+	document, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, shared.WrapError(err, shared.ErrorTypeProcessing, shared.CodeProcessingEncode, "failed to encode schema")
+	}
+
+	return append(document, '\n'), nil
+}
+
+// configSchema returns the schema of the configuration, built from the defaults: every
+// setting takes the type of its default value, and sections allow no other settings.
+func configSchema() *schemaNode {
+	recorder := &defaultRecorder{values: make(map[string]any)}
+	setDefaults(recorder)
+
+	root := newSectionSchema()
+	for _, key := range recorder.keys {
+		root.add(strings.Split(key, "."), settingSchema(key, recorder.values[key]))
+	}
+
+	return root
+}
+
+// newSectionSchema returns the schema of a section of settings.
+func newSectionSchema() *schemaNode {
+	return &schemaNode{Type: schemaTypeObject, Properties: make(map[string]*schemaNode), AdditionalProperties: false}
+}
+
+// add sets the schema of the setting at the dotted path parts, adding the sections on the way.
+func (n *schemaNode) add(parts []string, setting *schemaNode) {
+	if len(parts) == 1 {
+		n.Properties[parts[0]] = setting
+
+		return
+	}
+	section, ok := n.Properties[parts[0]]
+	if !ok {
+		section = newSectionSchema()
+		n.Properties[parts[0]] = section
+	}
+	section.add(parts[1:], setting)
+}
+
+// settingSchema returns the schema of the setting key with the default value.
+func settingSchema(key string, value any) *schemaNode {
+	node := valueSchema(reflect.TypeOf(value))
+	node.Enum = schemaEnums[key]
+	node.Default = value
+	if rv := reflect.ValueOf(value); rv.Kind() == reflect.Slice && rv.IsNil() {
+		node.Default = []any{}
+	}
+	if key == shared.ConfigKeyProfiles {
+		node.AdditionalProperties = &schemaNode{
+			Type:        schemaTypeObject,
+			Description: "Settings merged over the configuration by --profile, and the output format it selects",
+		}
+	}
+
+	return node
+}
+
+// valueSchema returns the schema of values of type t.
+func valueSchema(t reflect.Type) *schemaNode {
+	switch t.Kind() {
+	case reflect.Bool:
+		return &schemaNode{Type: schemaTypeBoolean}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &schemaNode{Type: schemaTypeInteger}
+	case reflect.Float32, reflect.Float64:
+		return &schemaNode{Type: schemaTypeNumber}
+	case reflect.String:
+		return &schemaNode{Type: schemaTypeString}
+	case reflect.Slice, reflect.Array:
+		node := &schemaNode{Type: schemaTypeArray}
+		if t.Elem().Kind() != reflect.Interface {
+			node.Items = valueSchema(t.Elem())
+		}
+
+		return node
+	case reflect.Map:
+		node := &schemaNode{Type: schemaTypeObject}
+		if t.Elem().Kind() != reflect.Interface {
+			node.AdditionalProperties = valueSchema(t.Elem())
+		}
+
+		return node
+	default:
+		return &schemaNode{}
+	}
+}
+
+// validateSchema checks the settings the config file of s sets against the configuration
+// schema, reporting unknown settings, such as a misspelled section, and values of the wrong type.
+func (s *Settings) validateSchema() []string {
+	var keys []string
+	for _, key := range s.values().AllKeys() {
+		if s.values().InConfig(key) {
+			keys = append(keys, key)
+		}
+	}
+
+	return configSchema().check(s.values(), keys)
+}
+
+// check validates the values v holds for the lower-case dotted keys against the schema n.
+func (n *schemaNode) check(v *viper.Viper, keys []string) []string {
+	var validationErrors []string
+	unknown := make(map[string]bool)
+	for _, key := range slices.Sorted(slices.Values(keys)) {
+		if isDeprecatedKey(key) {
+			continue
+		}
+		node, path, unknownSetting := n.lookup(strings.Split(key, "."))
+		value := v.Get(key)
+		switch {
+		case unknownSetting != "":
+			if !unknown[unknownSetting] {
+				unknown[unknownSetting] = true
+				validationErrors = append(validationErrors, unknownSetting)
+			}
+		case node != nil && node.Properties != nil:
+			// An empty section leaves its settings at their defaults
+			if value != nil {
+				validationErrors = append(validationErrors, path+" must be a mapping of settings")
+			}
+		default:
+			validationErrors = append(validationErrors, node.checkValue(path, value)...)
+		}
+	}
+
+	return validationErrors
+}
+
+// lookup returns the schema of the setting, or section, at the lower-case dotted path parts and
+// the path as the schema spells it, or a message when the path names no setting. A nil schema
+// allows any value.
+func (n *schemaNode) lookup(parts []string) (node *schemaNode, path string, unknownSetting string) {
+	node = n
+	var spelled []string
+	for i, part := range parts {
+		if node.Properties == nil {
+			if node.Type != schemaTypeObject {
+				return nil, "", "unknown setting " + strings.Join(append(spelled, parts[i:]...), ".")
+			}
+			// Keys of a mapping, such as file names or extensions, may contain dots themselves
+			values, _ := node.AdditionalProperties.(*schemaNode)
+			if values != nil && values.Type == schemaTypeObject {
+				// The mappings inside a mapping, such as the profiles, are validated on their own
+				values = nil
+			}
+			spelled = append(spelled, strings.Join(parts[i:], "."))
+
+			return values, strings.Join(spelled, "."), ""
+		}
+		name, ok := node.property(part)
+		if !ok {
+			return nil, "", unknownSettingMessage(spelled, part, node)
+		}
+		node = node.Properties[name]
+		spelled = append(spelled, name)
+	}
+
+	return node, strings.Join(spelled, "."), ""
+}
+
+// isDeprecatedKey reports whether the lower-case key is a deprecated config key, which has no
+// default but still works.
+func isDeprecatedKey(key string) bool {
+	for deprecated := range deprecatedKeys {
+		if strings.EqualFold(deprecated, key) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// property returns the name of the property of n that part, in lower case, refers to.
+func (n *schemaNode) property(part string) (string, bool) {
+	for name := range n.Properties {
+		if strings.EqualFold(name, part) {
+			return name, true
+		}
+	}
+
+	return "", false
+}
+
+// unknownSettingMessage reports that section, at the path spelled, has no setting named part,
+// suggesting the closest one when part looks like a misspelling of it.
+func unknownSettingMessage(spelled []string, part string, section *schemaNode) string {
+	message := "unknown setting " + strings.Join(append(slices.Clone(spelled), part), ".")
+	best, bestDistance := "", schemaSuggestDistance+1
+	for _, name := range slices.Sorted(maps.Keys(section.Properties)) {
+		if distance := editDistance(part, strings.ToLower(name)); distance < bestDistance {
+			best, bestDistance = name, distance
+		}
+	}
+	if best != "" {
+		message += fmt.Sprintf(" (did you mean %s?)", strings.Join(append(slices.Clone(spelled), best), "."))
+	}
+
+	return message
+}
+
+// checkValue validates the value of the setting at path against the schema n.
+func (n *schemaNode) checkValue(path string, value any) []string {
+	if n == nil || value == nil {
+		return nil
+	}
+	if !n.accepts(value) {
+		return []string{fmt.Sprintf("%s (%v) must be %s", path, value, schemaTypeNames[n.Type])}
+	}
+	if n.Type != schemaTypeArray || n.Items == nil {
+		return nil
+	}
+
+	var validationErrors []string
+	elements := reflect.ValueOf(value)
+	for i := range elements.Len() {
+		element := fmt.Sprintf("%s[%d]", path, i)
+		validationErrors = append(validationErrors, n.Items.checkValue(element, elements.Index(i).Interface())...)
+	}
+
+	return validationErrors
+}
+
+// accepts reports whether value has the type of the schema n. Whole numbers are integers.
+func (n *schemaNode) accepts(value any) bool {
+	rv := reflect.ValueOf(value)
+	switch n.Type {
+	case schemaTypeBoolean:
+		return rv.Kind() == reflect.Bool
+	case schemaTypeInteger:
+		return rv.CanInt() || rv.CanUint() || rv.CanFloat() && rv.Float() == float64(int64(rv.Float()))
+	case schemaTypeNumber:
+		return rv.CanInt() || rv.CanUint() || rv.CanFloat()
+	case schemaTypeString:
+		return rv.Kind() == reflect.String
+	case schemaTypeArray:
+		return rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array
+	case schemaTypeObject:
+		return rv.Kind() == reflect.Map
+	default:
+		return true
+	}
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	current := make([]int, len(b)+1)
+	for i := range len(a) {
+		current[0] = i + 1
+		for j := range len(b) {
+			substitution := previous[j]
+			if a[i] != b[j] {
+				substitution++
+			}
+			current[j+1] = min(previous[j+1]+1, current[j]+1, substitution)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(b)]
+}
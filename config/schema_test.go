@@ -0,0 +1,91 @@
+package config_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/config"
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// TestJSONSchema tests that the schema describes every section with the types and defaults of
+// its settings.
+func TestJSONSchema(t *testing.T) {
+	document, err := config.JSONSchema()
+	testutil.MustSucceed(t, err, "JSONSchema")
+
+	var schema struct {
+		Schema     string `json:"$schema"`
+		Properties map[string]struct {
+			AdditionalProperties any `json:"additionalProperties"`
+			Properties           map[string]struct {
+				Type    string   `json:"type"`
+				Default any      `json:"default"`
+				Enum    []string `json:"enum"`
+			} `json:"properties"`
+		} `json:"properties"`
+	}
+	testutil.MustSucceed(t, json.Unmarshal(document, &schema), "decoding the schema")
+
+	if !strings.Contains(schema.Schema, "json-schema.org") {
+		t.Errorf("$schema = %q, want a JSON Schema dialect", schema.Schema)
+	}
+	backpressure := schema.Properties["backpressure"]
+	if backpressure.AdditionalProperties != false {
+		t.Error("backpressure allows unknown settings, want additionalProperties false")
+	}
+	if enabled := backpressure.Properties["enabled"]; enabled.Type != "boolean" || enabled.Default != true {
+		t.Errorf("backpressure.enabled = %+v, want a boolean defaulting to true", enabled)
+	}
+	hash := schema.Properties["performance"].Properties["hashAlgorithm"]
+	if len(hash.Enum) != len(shared.HashAlgorithms()) {
+		t.Errorf("performance.hashAlgorithm enum = %v, want %v", hash.Enum, shared.HashAlgorithms())
+	}
+}
+
+// TestValidateSchema tests that config files setting unknown settings or values of the wrong
+// type are rejected.
+func TestValidateSchema(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "valid", content: "backpressure:\n  enabled: false\nfileSizeLimit: 2048\n"},
+		{name: "whole number as integer", content: "fileSizeLimit: 2048.0\n"},
+		{name: "dotted mapping keys", content: "fileTypes:\n  customLanguages:\n    .stress: stress\n"},
+		{name: "empty section", content: "backpressure:\n"},
+		{
+			name:    "misspelled section",
+			content: "backpresure:\n  enabled: false\n  maxPendingFiles: 10\n",
+			wantErr: "unknown setting backpresure (did you mean backpressure?)",
+		},
+		{
+			name:    "misspelled setting",
+			content: "backpressure:\n  enabeld: false\n",
+			wantErr: "unknown setting backpressure.enabeld (did you mean backpressure.enabled?)",
+		},
+		{name: "unrelated setting", content: "colour: red\n", wantErr: "unknown setting colour"},
+		{name: "wrong type", content: "backpressure:\n  enabled: yes please\n", wantErr: "must be a boolean"},
+		{name: "fraction as integer", content: "fileSizeLimit: 2048.5\n", wantErr: "must be an integer"},
+		{name: "list element", content: "ignoreDirectories:\n  - [vendor]\n", wantErr: "ignoreDirectories[0]"},
+		{name: "scalar section", content: "backpressure: true\n", wantErr: "backpressure must be a mapping"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := testutil.CreateTestFile(t, t.TempDir(), "config.yaml", []byte(tt.content))
+			_, err := config.LoadSettings(path)
+			if tt.wantErr == "" {
+				testutil.MustSucceed(t, err, "LoadSettings")
+
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadSettings() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+// Package config handles application configuration management.
+package config
+
+import (
+	"github.com/spf13/viper"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// Settings is one configuration: the defaults with a config file on top. Global returns the
+// Settings that LoadConfig fills; a processor given Settings of its own reads those instead,
+// so runs with different configurations can share a process.
+type Settings struct {
+	// v holds the configuration; nil for the global configuration, which viper.Reset replaces.
+	v *viper.Viper
+	// projectFile is the project config file merged over the configuration, projectKeys the
+	// keys it set.
+	projectFile string
+	projectKeys []string
+	// deprecated holds the messages for the deprecated keys the config file sets.
+	deprecated []string
+}
+
+// global is the Settings of the global configuration.
+var global = &Settings{}
+
+// Global returns the global configuration, which LoadConfig fills. It follows
+// whatever LoadConfig or viper.Reset leaves in place, rather than a snapshot.
+func Global() *Settings {
+	return global
+}
+
+// NewSettings returns Settings holding the default configuration.
+func NewSettings() *Settings {
+	v := viper.New()
+	setDefaults(v)
+
+	return &Settings{v: v}
+}
+
+// LoadSettings reads the config file at path into new Settings, leaving the global
+// configuration untouched; an empty path returns the defaults. Like LoadConfigFrom, an
+// unreadable or invalid file is returned as an error.
+func LoadSettings(path string) (*Settings, error) {
+	s := NewSettings()
+	if path == "" {
+		return s, nil
+	}
+
+	s.v.SetConfigFile(path)
+	s.v.SetConfigType(shared.FormatYAML)
+	if err := s.v.ReadInConfig(); err != nil {
+		return nil, shared.WrapError(
+			err, shared.ErrorTypeConfiguration, shared.CodeConfigMissing, "failed to read config file",
+		).WithFilePath(path)
+	}
+	s.applyDeprecatedKeys()
+	if err := s.ValidateConfig(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// values returns the viper instance holding the configuration of s.
+func (s *Settings) values() *viper.Viper {
+	if s.v == nil {
+		return viper.GetViper()
+	}
+
+	return s.v
+}
+
+// FileUsed returns the config file s was read from, or "" when it holds the defaults.
+func (s *Settings) FileUsed() string {
+	return s.values().ConfigFileUsed()
+}
+
+// Set overrides the value of key, as a command-line flag does.
+func (s *Settings) Set(key string, value any) {
+	s.values().Set(key, value)
+}
@@ -0,0 +1,61 @@
+package config_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/config"
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// TestLoadSettings tests that Settings loaded from a file, or holding the defaults, are
+// independent of the global configuration and of each other.
+func TestLoadSettings(t *testing.T) {
+	testutil.ResetViperConfig(t, "")
+	testutil.SetViperKeys(t, map[string]any{shared.ConfigKeyFileSizeLimit: defaultFileSizeLimit + 1})
+	dir := t.TempDir()
+	valid := testutil.CreateTestFile(t, dir, "valid.yaml", []byte("fileSizeLimit: 123456\n"))
+	invalid := testutil.CreateTestFile(t, dir, "invalid.yaml", []byte("fileSizeLimit: 100\n"))
+
+	tests := []struct {
+		name      string
+		path      string
+		wantLimit int64
+		wantErr   bool
+	}{
+		{name: "defaults without a file", path: "", wantLimit: defaultFileSizeLimit},
+		{name: "explicit file", path: valid, wantLimit: testFileSizeLimit},
+		{name: "missing file", path: filepath.Join(dir, "missing.yaml"), wantErr: true},
+		{name: "invalid file", path: invalid, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings, err := config.LoadSettings(tt.path)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("LoadSettings(%q) succeeded, want an error", tt.path)
+				}
+
+				return
+			}
+			testutil.MustSucceed(t, err, "LoadSettings")
+			if got := settings.FileSizeLimit(); got != tt.wantLimit {
+				t.Errorf("file size limit = %d, want %d", got, tt.wantLimit)
+			}
+			if got := settings.FileUsed(); got != tt.path {
+				t.Errorf("FileUsed() = %q, want %q", got, tt.path)
+			}
+		})
+	}
+
+	settings := config.NewSettings()
+	settings.Set(shared.ConfigKeyFileSizeLimit, testFileSizeLimit)
+	if got := config.Global().FileSizeLimit(); got != defaultFileSizeLimit+1 {
+		t.Errorf("global file size limit = %d after Settings.Set, want it unchanged", got)
+	}
+	if got := config.NewSettings().FileSizeLimit(); got != defaultFileSizeLimit {
+		t.Errorf("new Settings file size limit = %d, want the default", got)
+	}
+}
@@ -3,22 +3,26 @@ package config
 
 import (
 	"fmt"
+	"maps"
+	"net/url"
+	"regexp"
+	"slices"
 	"strings"
 
-	"github.com/spf13/viper"
-
 	"github.com/ivuorinen/gibidify/shared"
 )
 
-// ValidateConfig validates the loaded configuration.
-func ValidateConfig() error {
-	var validationErrors []string
+// formatPluginName matches the names output.plugins formats can be registered under.
+var formatPluginName = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
 
-	// Validate basic settings
-	validationErrors = append(validationErrors, validateBasicSettings()...)
-	validationErrors = append(validationErrors, validateFileTypeSettings()...)
-	validationErrors = append(validationErrors, validateBackpressureSettings()...)
-	validationErrors = append(validationErrors, validateResourceLimitSettings()...)
+// ValidateConfig validates the loaded configuration.
+func (s *Settings) ValidateConfig() error {
+	// Values of the wrong type would be reported again by the checks of the settings, as the
+	// zero values they convert to
+	validationErrors := s.validateSchema()
+	if len(validationErrors) == 0 {
+		validationErrors = append(s.validationErrors(), s.validateProfiles()...)
+	}
 
 	if len(validationErrors) > 0 {
 		return shared.NewStructuredError(
@@ -33,24 +37,56 @@ func ValidateConfig() error {
 	return nil
 }
 
+// validationErrors returns a message for every invalid setting of s, apart from the profiles.
+func (s *Settings) validationErrors() []string {
+	var validationErrors []string
+
+	// Validate basic settings
+	validationErrors = append(validationErrors, s.validateBasicSettings()...)
+	validationErrors = append(validationErrors, s.validateFileTypeSettings()...)
+	validationErrors = append(validationErrors, s.validateBackpressureSettings()...)
+	validationErrors = append(validationErrors, s.validateResourceLimitSettings()...)
+	validationErrors = append(validationErrors, s.validateGitSettings()...)
+	validationErrors = append(validationErrors, s.validateGeneratedTextSettings()...)
+	validationErrors = append(validationErrors, s.validateRedactionSettings()...)
+	validationErrors = append(validationErrors, s.validateOutputSettings()...)
+
+	return validationErrors
+}
+
 // validateBasicSettings validates basic configuration settings.
-func validateBasicSettings() []string {
+func (s *Settings) validateBasicSettings() []string {
 	var validationErrors []string
 
-	validationErrors = append(validationErrors, validateFileSizeLimit()...)
-	validationErrors = append(validationErrors, validateIgnoreDirectories()...)
-	validationErrors = append(validationErrors, validateSupportedFormats()...)
-	validationErrors = append(validationErrors, validateConcurrencySettings()...)
-	validationErrors = append(validationErrors, validateFilePatterns()...)
+	validationErrors = append(validationErrors, s.validateFileSizeLimit()...)
+	validationErrors = append(validationErrors, s.validateIgnoreDirectories()...)
+	validationErrors = append(validationErrors, s.validateSupportedFormats()...)
+	validationErrors = append(validationErrors, s.validateConcurrencySettings()...)
+	validationErrors = append(validationErrors, s.validateFormatWorkers()...)
+	validationErrors = append(validationErrors, s.validateMaxOpenFiles()...)
+	validationErrors = append(validationErrors, s.validatePrefetch()...)
+	validationErrors = append(validationErrors, s.validateHashAlgorithm()...)
+	validationErrors = append(validationErrors, s.validateUISettings()...)
+	validationErrors = append(validationErrors, s.validateWarningSettings()...)
+	validationErrors = append(validationErrors, s.validateTokenSettings()...)
+	validationErrors = append(validationErrors, s.validateDocLanguageSettings()...)
+	validationErrors = append(validationErrors, s.validateStripCommentsLanguages()...)
+	validationErrors = append(validationErrors, s.validateCollectionRollups()...)
+	validationErrors = append(validationErrors, s.validateCollectionOutlierPercent()...)
+	validationErrors = append(validationErrors, s.validateBudgets()...)
+	validationErrors = append(validationErrors, s.validateOrderPriority()...)
+	validationErrors = append(validationErrors, s.validateTransformsWasm()...)
+	validationErrors = append(validationErrors, s.validateShareSettings()...)
+	validationErrors = append(validationErrors, s.validateFilePatterns()...)
 
 	return validationErrors
 }
 
 // validateFileSizeLimit validates the file size limit setting.
-func validateFileSizeLimit() []string {
+func (s *Settings) validateFileSizeLimit() []string {
 	var validationErrors []string
 
-	fileSizeLimit := viper.GetInt64(shared.ConfigKeyFileSizeLimit)
+	fileSizeLimit := s.values().GetInt64(shared.ConfigKeyFileSizeLimit)
 	if fileSizeLimit < shared.ConfigFileSizeLimitMin {
 		validationErrors = append(
 			validationErrors,
@@ -68,10 +104,10 @@ func validateFileSizeLimit() []string {
 }
 
 // validateIgnoreDirectories validates the ignore directories setting.
-func validateIgnoreDirectories() []string {
+func (s *Settings) validateIgnoreDirectories() []string {
 	var validationErrors []string
 
-	ignoreDirectories := viper.GetStringSlice(shared.ConfigKeyIgnoreDirectories)
+	ignoreDirectories := s.values().GetStringSlice(shared.ConfigKeyIgnoreDirectories)
 	for i, dir := range ignoreDirectories {
 		if errMsg := validateEmptyElement(shared.ConfigKeyIgnoreDirectories, dir, i); errMsg != "" {
 			validationErrors = append(validationErrors, errMsg)
@@ -99,21 +135,24 @@ func validateIgnoreDirectories() []string {
 }
 
 // validateSupportedFormats validates the supported formats setting.
-func validateSupportedFormats() []string {
+func (s *Settings) validateSupportedFormats() []string {
 	var validationErrors []string
 
-	if !viper.IsSet(shared.ConfigKeySupportedFormats) {
+	if !s.values().IsSet(shared.ConfigKeySupportedFormats) {
 		return validationErrors
 	}
 
-	supportedFormats := viper.GetStringSlice(shared.ConfigKeySupportedFormats)
-	validFormats := map[string]bool{shared.FormatJSON: true, shared.FormatYAML: true, shared.FormatMarkdown: true}
+	supportedFormats := s.values().GetStringSlice(shared.ConfigKeySupportedFormats)
 	for i, format := range supportedFormats {
 		format = strings.ToLower(strings.TrimSpace(format))
-		if !validFormats[format] {
+		if !s.IsValidFormat(format) {
 			validationErrors = append(
 				validationErrors,
-				fmt.Sprintf("supportedFormats[%d] (%s) is not a valid format (json, yaml, markdown)", i, format),
+				fmt.Sprintf(
+					"supportedFormats[%d] (%s) is not a valid format "+
+						"(json, yaml, markdown, plain, pdf, zip, or an output.plugins format)",
+					i, format,
+				),
 			)
 		}
 	}
@@ -122,14 +161,14 @@ func validateSupportedFormats() []string {
 }
 
 // validateConcurrencySettings validates the concurrency settings.
-func validateConcurrencySettings() []string {
+func (s *Settings) validateConcurrencySettings() []string {
 	var validationErrors []string
 
-	if !viper.IsSet(shared.ConfigKeyMaxConcurrency) {
+	if !s.values().IsSet(shared.ConfigKeyMaxConcurrency) {
 		return validationErrors
 	}
 
-	maxConcurrency := viper.GetInt(shared.ConfigKeyMaxConcurrency)
+	maxConcurrency := s.values().GetInt(shared.ConfigKeyMaxConcurrency)
 	if maxConcurrency < 1 {
 		validationErrors = append(
 			validationErrors, fmt.Sprintf("maxConcurrency (%d) must be at least 1", maxConcurrency),
@@ -145,15 +184,284 @@ func validateConcurrencySettings() []string {
 	return validationErrors
 }
 
+// validateFormatWorkers validates the performance.formatWorkers setting.
+func (s *Settings) validateFormatWorkers() []string {
+	if !s.values().IsSet(shared.ConfigKeyPerformanceFormatWorkers) {
+		return nil
+	}
+
+	workers := s.values().GetInt(shared.ConfigKeyPerformanceFormatWorkers)
+	if workers < 1 || workers > shared.ConfigMaxConcurrencyDefault {
+		return []string{fmt.Sprintf(
+			"performance.formatWorkers (%d) must be between 1 and %d", workers, shared.ConfigMaxConcurrencyDefault,
+		)}
+	}
+
+	return nil
+}
+
+// validateMaxOpenFiles validates the performance.maxOpenFiles setting.
+func (s *Settings) validateMaxOpenFiles() []string {
+	if maxOpen := s.values().GetInt(shared.ConfigKeyPerformanceMaxOpenFiles); maxOpen < 0 {
+		return []string{fmt.Sprintf("performance.maxOpenFiles (%d) must be 0 or positive", maxOpen)}
+	}
+
+	return nil
+}
+
+// validatePrefetch validates the performance.prefetchBytes and performance.prefetchReaders settings.
+// Prefetched content counts against the backpressure memory budget, so it cannot exceed it.
+func (s *Settings) validatePrefetch() []string {
+	var validationErrors []string
+	budget := s.values().GetInt64(shared.ConfigKeyPerformancePrefetchBytes)
+	maxMemory := s.values().GetInt64(shared.ConfigKeyBackpressureMaxMemoryUsage)
+	switch {
+	case budget < 0:
+		validationErrors = append(validationErrors,
+			fmt.Sprintf("performance.prefetchBytes (%d) must be 0 or positive", budget))
+	case maxMemory > 0 && budget > maxMemory:
+		validationErrors = append(validationErrors, fmt.Sprintf(
+			"performance.prefetchBytes (%d) must not exceed backpressure.maxMemoryUsage (%d)", budget, maxMemory))
+	}
+	if readers := s.values().GetInt(shared.ConfigKeyPerformancePrefetchReaders); readers < 1 {
+		validationErrors = append(validationErrors,
+			fmt.Sprintf("performance.prefetchReaders (%d) must be at least 1", readers))
+	}
+
+	return validationErrors
+}
+
+// validateHashAlgorithm validates the performance.hashAlgorithm setting.
+func (s *Settings) validateHashAlgorithm() []string {
+	algorithm := s.values().GetString(shared.ConfigKeyPerformanceHashAlgorithm)
+	if !s.values().IsSet(shared.ConfigKeyPerformanceHashAlgorithm) || slices.Contains(shared.HashAlgorithms(), algorithm) {
+		return nil
+	}
+
+	return []string{fmt.Sprintf(
+		"performance.hashAlgorithm (%q) must be one of %v", algorithm, shared.HashAlgorithms(),
+	)}
+}
+
+// validateUISettings validates the ui.progressStyle and ui.theme settings.
+func (s *Settings) validateUISettings() []string {
+	var validationErrors []string
+
+	settings := []struct {
+		key     string
+		allowed []string
+	}{
+		{
+			key: shared.ConfigKeyUIProgressStyle,
+			allowed: []string{
+				shared.UIProgressStyleBar, shared.UIProgressStyleSpinner,
+				shared.UIProgressStyleDots, shared.UIProgressStyleNone,
+			},
+		},
+		{
+			key:     shared.ConfigKeyUITheme,
+			allowed: []string{shared.UIThemeAuto, shared.UIThemeUnicode, shared.UIThemeASCII},
+		},
+	}
+	for _, setting := range settings {
+		value := s.values().GetString(setting.key)
+		if s.values().IsSet(setting.key) && !slices.Contains(setting.allowed, value) {
+			validationErrors = append(validationErrors, fmt.Sprintf(
+				"%s (%q) must be one of %v", setting.key, value, setting.allowed,
+			))
+		}
+	}
+
+	return validationErrors
+}
+
+// validateWarningSettings validates the warnings.slowFileSec setting.
+func (s *Settings) validateWarningSettings() []string {
+	if seconds := s.values().GetInt(shared.ConfigKeyWarningsSlowFileSec); seconds < 0 {
+		return []string{fmt.Sprintf("warnings.slowFileSec (%d) must not be negative", seconds)}
+	}
+
+	return nil
+}
+
+// validateTokenSettings validates the tokens.bytesPerToken setting and every tokens.languages
+// calibration.
+func (s *Settings) validateTokenSettings() []string {
+	var validationErrors []string
+
+	keys := []string{shared.ConfigKeyTokensBytesPerToken}
+	for _, language := range slices.Sorted(maps.Keys(s.values().GetStringMap(shared.ConfigKeyTokensLanguages))) {
+		keys = append(keys, shared.ConfigKeyTokensLanguages+"."+language)
+	}
+	for _, key := range keys {
+		if ratio := s.values().GetFloat64(key); ratio <= 0 || ratio > shared.ConfigTokensBytesPerTokenMax {
+			validationErrors = append(validationErrors, fmt.Sprintf(
+				"%s (%v) must be above 0 and at most %d", key, s.values().Get(key), shared.ConfigTokensBytesPerTokenMax,
+			))
+		}
+	}
+
+	return validationErrors
+}
+
+// validateDocLanguageSettings validates the docLanguage.include setting.
+func (s *Settings) validateDocLanguageSettings() []string {
+	if err := ValidateDocLanguages(s.DocLanguageInclude()); err != nil {
+		return []string{shared.ConfigKeyDocLanguageInclude + ": " + err.Error()}
+	}
+
+	return nil
+}
+
+// validateStripCommentsLanguages validates that comments can be stripped from every language of
+// stripComments.languages.
+func (s *Settings) validateStripCommentsLanguages() []string {
+	var validationErrors []string
+	for _, language := range s.StripCommentsLanguages() {
+		if !slices.Contains(shared.CommentStripLanguages, language) {
+			validationErrors = append(validationErrors, fmt.Sprintf(
+				"%s: unsupported language %q (supported: %s)",
+				shared.ConfigKeyStripCommentsLanguages, language, strings.Join(shared.CommentStripLanguages, ", "),
+			))
+		}
+	}
+
+	return validationErrors
+}
+
+// validateCollectionRollups validates the collection.rollups globs.
+func (s *Settings) validateCollectionRollups() []string {
+	var validationErrors []string
+	for i, pattern := range s.CollectionRollups() {
+		if errMsg := validateEmptyElement(shared.ConfigKeyCollectionRollups, pattern, i); errMsg != "" {
+			validationErrors = append(validationErrors, errMsg)
+		}
+	}
+
+	return validationErrors
+}
+
+// validateCollectionOutlierPercent validates that collection.outlierPercent is a percentage.
+func (s *Settings) validateCollectionOutlierPercent() []string {
+	if percent := s.CollectionOutlierPercent(); percent < 0 || percent > 100 {
+		return []string{fmt.Sprintf("%s (%d) must be between 0 and 100", shared.ConfigKeyCollectionOutlierPercent, percent)}
+	}
+
+	return nil
+}
+
+// validateOrderPriority validates the order.priority globs.
+func (s *Settings) validateOrderPriority() []string {
+	var validationErrors []string
+	for i, pattern := range s.OrderPriority() {
+		if errMsg := validateEmptyElement(shared.ConfigKeyOrderPriority, pattern, i); errMsg != "" {
+			validationErrors = append(validationErrors, errMsg)
+		}
+	}
+
+	return validationErrors
+}
+
+// validateBudgets validates the budgets: every glob needs a positive byte or token limit and a
+// known fallback.
+func (s *Settings) validateBudgets() []string {
+	if !s.values().IsSet(shared.ConfigKeyBudgets) {
+		return nil
+	}
+
+	budgets := make(map[string]Budget)
+	if err := s.values().UnmarshalKey(shared.ConfigKeyBudgets, &budgets); err != nil {
+		return []string{fmt.Sprintf("%s could not be decoded: %v", shared.ConfigKeyBudgets, err)}
+	}
+
+	var validationErrors []string
+	for _, pattern := range slices.Sorted(maps.Keys(budgets)) {
+		budget := budgets[pattern]
+		key := shared.ConfigKeyBudgets + "." + pattern
+		if budget.MaxBytes < 0 || budget.MaxTokens < 0 {
+			validationErrors = append(validationErrors, key+" limits must not be negative")
+		}
+		if budget.MaxBytes <= 0 && budget.MaxTokens <= 0 {
+			validationErrors = append(validationErrors, key+" needs maxBytes or maxTokens above 0")
+		}
+		if budget.Fallback != "" && !slices.Contains(shared.BudgetFallbacks, strings.ToLower(budget.Fallback)) {
+			validationErrors = append(validationErrors, fmt.Sprintf(
+				"%s.fallback (%s) must be one of: %s", key, budget.Fallback, strings.Join(shared.BudgetFallbacks, ", "),
+			))
+		}
+	}
+
+	return validationErrors
+}
+
+// validateTransformsWasm validates the transforms.wasm entries: every transform needs a glob and
+// a module file.
+func (s *Settings) validateTransformsWasm() []string {
+	if !s.values().IsSet(shared.ConfigKeyTransformsWasm) {
+		return nil
+	}
+
+	var transforms []WasmTransform
+	if err := s.values().UnmarshalKey(shared.ConfigKeyTransformsWasm, &transforms); err != nil {
+		return []string{fmt.Sprintf("%s could not be decoded: %v", shared.ConfigKeyTransformsWasm, err)}
+	}
+
+	var validationErrors []string
+	for i, transform := range transforms {
+		key := fmt.Sprintf("%s[%d]", shared.ConfigKeyTransformsWasm, i)
+		if strings.TrimSpace(transform.Pattern) == "" {
+			validationErrors = append(validationErrors, key+".pattern must not be empty")
+		}
+		if strings.TrimSpace(transform.Module) == "" {
+			validationErrors = append(validationErrors, key+".module must not be empty")
+		}
+	}
+
+	return validationErrors
+}
+
+// validateShareSettings validates the share settings: a known backend, an HTTP(S) URL, which
+// the paste backend requires, and a positive size limit.
+func (s *Settings) validateShareSettings() []string {
+	var validationErrors []string
+
+	backends := []string{shared.ShareBackendGist, shared.ShareBackendPaste}
+	backend := s.values().GetString(shared.ConfigKeyShareBackend)
+	if !slices.Contains(backends, backend) {
+		validationErrors = append(validationErrors, fmt.Sprintf(
+			"%s (%q) must be one of %v", shared.ConfigKeyShareBackend, backend, backends,
+		))
+	}
+
+	rawURL := s.values().GetString(shared.ConfigKeyShareURL)
+	if parsed, err := url.Parse(rawURL); rawURL != "" &&
+		(err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "") {
+		validationErrors = append(validationErrors, fmt.Sprintf(
+			"%s (%q) must be an http or https URL", shared.ConfigKeyShareURL, rawURL,
+		))
+	}
+	if rawURL == "" && backend == shared.ShareBackendPaste {
+		validationErrors = append(validationErrors, shared.ConfigKeyShareURL+" is required by the paste backend")
+	}
+
+	if maxSize := s.values().GetInt64(shared.ConfigKeyShareMaxSize); maxSize <= 0 {
+		validationErrors = append(validationErrors, fmt.Sprintf(
+			"%s (%d) must be positive", shared.ConfigKeyShareMaxSize, maxSize,
+		))
+	}
+
+	return validationErrors
+}
+
 // validateFilePatterns validates the file patterns setting.
-func validateFilePatterns() []string {
+func (s *Settings) validateFilePatterns() []string {
 	var validationErrors []string
 
-	if !viper.IsSet(shared.ConfigKeyFilePatterns) {
+	if !s.values().IsSet(shared.ConfigKeyFilePatterns) {
 		return validationErrors
 	}
 
-	filePatterns := viper.GetStringSlice(shared.ConfigKeyFilePatterns)
+	filePatterns := s.values().GetStringSlice(shared.ConfigKeyFilePatterns)
 	for i, pattern := range filePatterns {
 		if errMsg := validateEmptyElement(shared.ConfigKeyFilePatterns, pattern, i); errMsg != "" {
 			validationErrors = append(validationErrors, errMsg)
@@ -174,25 +482,25 @@ func validateFilePatterns() []string {
 }
 
 // validateFileTypeSettings validates file type configuration settings.
-func validateFileTypeSettings() []string {
+func (s *Settings) validateFileTypeSettings() []string {
 	var validationErrors []string
 
-	validationErrors = append(validationErrors, validateCustomImageExtensions()...)
-	validationErrors = append(validationErrors, validateCustomBinaryExtensions()...)
-	validationErrors = append(validationErrors, validateCustomLanguages()...)
+	validationErrors = append(validationErrors, s.validateCustomImageExtensions()...)
+	validationErrors = append(validationErrors, s.validateCustomBinaryExtensions()...)
+	validationErrors = append(validationErrors, s.validateCustomLanguages()...)
 
 	return validationErrors
 }
 
 // validateCustomImageExtensions validates custom image extensions.
-func validateCustomImageExtensions() []string {
+func (s *Settings) validateCustomImageExtensions() []string {
 	var validationErrors []string
 
-	if !viper.IsSet(shared.ConfigKeyFileTypesCustomImageExtensions) {
+	if !s.values().IsSet(shared.ConfigKeyFileTypesCustomImageExtensions) {
 		return validationErrors
 	}
 
-	customImages := viper.GetStringSlice(shared.ConfigKeyFileTypesCustomImageExtensions)
+	customImages := s.values().GetStringSlice(shared.ConfigKeyFileTypesCustomImageExtensions)
 	for i, ext := range customImages {
 		if errMsg := validateEmptyElement(shared.ConfigKeyFileTypesCustomImageExtensions, ext, i); errMsg != "" {
 			validationErrors = append(validationErrors, errMsg)
@@ -209,14 +517,14 @@ func validateCustomImageExtensions() []string {
 }
 
 // validateCustomBinaryExtensions validates custom binary extensions.
-func validateCustomBinaryExtensions() []string {
+func (s *Settings) validateCustomBinaryExtensions() []string {
 	var validationErrors []string
 
-	if !viper.IsSet(shared.ConfigKeyFileTypesCustomBinaryExtensions) {
+	if !s.values().IsSet(shared.ConfigKeyFileTypesCustomBinaryExtensions) {
 		return validationErrors
 	}
 
-	customBinary := viper.GetStringSlice(shared.ConfigKeyFileTypesCustomBinaryExtensions)
+	customBinary := s.values().GetStringSlice(shared.ConfigKeyFileTypesCustomBinaryExtensions)
 	for i, ext := range customBinary {
 		if errMsg := validateEmptyElement(shared.ConfigKeyFileTypesCustomBinaryExtensions, ext, i); errMsg != "" {
 			validationErrors = append(validationErrors, errMsg)
@@ -233,14 +541,14 @@ func validateCustomBinaryExtensions() []string {
 }
 
 // validateCustomLanguages validates custom language mappings.
-func validateCustomLanguages() []string {
+func (s *Settings) validateCustomLanguages() []string {
 	var validationErrors []string
 
-	if !viper.IsSet(shared.ConfigKeyFileTypesCustomLanguages) {
+	if !s.values().IsSet(shared.ConfigKeyFileTypesCustomLanguages) {
 		return validationErrors
 	}
 
-	customLangs := viper.GetStringMapString(shared.ConfigKeyFileTypesCustomLanguages)
+	customLangs := s.values().GetStringMapString(shared.ConfigKeyFileTypesCustomLanguages)
 	for ext, lang := range customLangs {
 		ext = strings.TrimSpace(ext)
 		if ext == "" {
@@ -263,26 +571,26 @@ func validateCustomLanguages() []string {
 }
 
 // validateBackpressureSettings validates back-pressure configuration settings.
-func validateBackpressureSettings() []string {
+func (s *Settings) validateBackpressureSettings() []string {
 	var validationErrors []string
 
-	validationErrors = append(validationErrors, validateMaxPendingFiles()...)
-	validationErrors = append(validationErrors, validateMaxPendingWrites()...)
-	validationErrors = append(validationErrors, validateMaxMemoryUsage()...)
-	validationErrors = append(validationErrors, validateMemoryCheckInterval()...)
+	validationErrors = append(validationErrors, s.validateMaxPendingFiles()...)
+	validationErrors = append(validationErrors, s.validateMaxPendingWrites()...)
+	validationErrors = append(validationErrors, s.validateMaxMemoryUsage()...)
+	validationErrors = append(validationErrors, s.validateMemoryCheckInterval()...)
 
 	return validationErrors
 }
 
 // validateMaxPendingFiles validates backpressure.maxPendingFiles setting.
-func validateMaxPendingFiles() []string {
+func (s *Settings) validateMaxPendingFiles() []string {
 	var validationErrors []string
 
-	if !viper.IsSet(shared.ConfigKeyBackpressureMaxPendingFiles) {
+	if !s.values().IsSet(shared.ConfigKeyBackpressureMaxPendingFiles) {
 		return validationErrors
 	}
 
-	maxPendingFiles := viper.GetInt(shared.ConfigKeyBackpressureMaxPendingFiles)
+	maxPendingFiles := s.values().GetInt(shared.ConfigKeyBackpressureMaxPendingFiles)
 	if maxPendingFiles < 1 {
 		validationErrors = append(
 			validationErrors,
@@ -300,14 +608,14 @@ func validateMaxPendingFiles() []string {
 }
 
 // validateMaxPendingWrites validates backpressure.maxPendingWrites setting.
-func validateMaxPendingWrites() []string {
+func (s *Settings) validateMaxPendingWrites() []string {
 	var validationErrors []string
 
-	if !viper.IsSet(shared.ConfigKeyBackpressureMaxPendingWrites) {
+	if !s.values().IsSet(shared.ConfigKeyBackpressureMaxPendingWrites) {
 		return validationErrors
 	}
 
-	maxPendingWrites := viper.GetInt(shared.ConfigKeyBackpressureMaxPendingWrites)
+	maxPendingWrites := s.values().GetInt(shared.ConfigKeyBackpressureMaxPendingWrites)
 	if maxPendingWrites < 1 {
 		validationErrors = append(
 			validationErrors,
@@ -325,14 +633,14 @@ func validateMaxPendingWrites() []string {
 }
 
 // validateMaxMemoryUsage validates backpressure.maxMemoryUsage setting.
-func validateMaxMemoryUsage() []string {
+func (s *Settings) validateMaxMemoryUsage() []string {
 	var validationErrors []string
 
-	if !viper.IsSet(shared.ConfigKeyBackpressureMaxMemoryUsage) {
+	if !s.values().IsSet(shared.ConfigKeyBackpressureMaxMemoryUsage) {
 		return validationErrors
 	}
 
-	maxMemoryUsage := viper.GetInt64(shared.ConfigKeyBackpressureMaxMemoryUsage)
+	maxMemoryUsage := s.values().GetInt64(shared.ConfigKeyBackpressureMaxMemoryUsage)
 	minMemory := int64(shared.BytesPerMB)      // 1MB minimum
 	maxMemory := int64(10 * shared.BytesPerGB) // 10GB maximum
 	if maxMemoryUsage < minMemory {
@@ -352,14 +660,14 @@ func validateMaxMemoryUsage() []string {
 }
 
 // validateMemoryCheckInterval validates backpressure.memoryCheckInterval setting.
-func validateMemoryCheckInterval() []string {
+func (s *Settings) validateMemoryCheckInterval() []string {
 	var validationErrors []string
 
-	if !viper.IsSet(shared.ConfigKeyBackpressureMemoryCheckInt) {
+	if !s.values().IsSet(shared.ConfigKeyBackpressureMemoryCheckInt) {
 		return validationErrors
 	}
 
-	interval := viper.GetInt(shared.ConfigKeyBackpressureMemoryCheckInt)
+	interval := s.values().GetInt(shared.ConfigKeyBackpressureMemoryCheckInt)
 	if interval < 1 {
 		validationErrors = append(
 			validationErrors,
@@ -377,27 +685,27 @@ func validateMemoryCheckInterval() []string {
 }
 
 // validateResourceLimitSettings validates resource limit configuration settings.
-func validateResourceLimitSettings() []string {
+func (s *Settings) validateResourceLimitSettings() []string {
 	var validationErrors []string
 
-	validationErrors = append(validationErrors, validateMaxFilesLimit()...)
-	validationErrors = append(validationErrors, validateMaxTotalSizeLimit()...)
-	validationErrors = append(validationErrors, validateTimeoutLimits()...)
-	validationErrors = append(validationErrors, validateConcurrencyLimits()...)
-	validationErrors = append(validationErrors, validateMemoryLimits()...)
+	validationErrors = append(validationErrors, s.validateMaxFilesLimit()...)
+	validationErrors = append(validationErrors, s.validateMaxTotalSizeLimit()...)
+	validationErrors = append(validationErrors, s.validateTimeoutLimits()...)
+	validationErrors = append(validationErrors, s.validateConcurrencyLimits()...)
+	validationErrors = append(validationErrors, s.validateMemoryLimits()...)
 
 	return validationErrors
 }
 
 // validateMaxFilesLimit validates resourceLimits.maxFiles setting.
-func validateMaxFilesLimit() []string {
+func (s *Settings) validateMaxFilesLimit() []string {
 	var validationErrors []string
 
-	if !viper.IsSet(shared.ConfigKeyResourceLimitsMaxFiles) {
+	if !s.values().IsSet(shared.ConfigKeyResourceLimitsMaxFiles) {
 		return validationErrors
 	}
 
-	maxFiles := viper.GetInt(shared.ConfigKeyResourceLimitsMaxFiles)
+	maxFiles := s.values().GetInt(shared.ConfigKeyResourceLimitsMaxFiles)
 	if maxFiles < shared.ConfigMaxFilesMin {
 		validationErrors = append(
 			validationErrors,
@@ -415,14 +723,14 @@ func validateMaxFilesLimit() []string {
 }
 
 // validateMaxTotalSizeLimit validates resourceLimits.maxTotalSize setting.
-func validateMaxTotalSizeLimit() []string {
+func (s *Settings) validateMaxTotalSizeLimit() []string {
 	var validationErrors []string
 
-	if !viper.IsSet(shared.ConfigKeyResourceLimitsMaxTotalSize) {
+	if !s.values().IsSet(shared.ConfigKeyResourceLimitsMaxTotalSize) {
 		return validationErrors
 	}
 
-	maxTotalSize := viper.GetInt64(shared.ConfigKeyResourceLimitsMaxTotalSize)
+	maxTotalSize := s.values().GetInt64(shared.ConfigKeyResourceLimitsMaxTotalSize)
 	minTotalSize := int64(shared.ConfigMaxTotalSizeMin)
 	maxTotalSizeLimit := int64(shared.ConfigMaxTotalSizeMax)
 	if maxTotalSize < minTotalSize {
@@ -442,11 +750,11 @@ func validateMaxTotalSizeLimit() []string {
 }
 
 // validateTimeoutLimits validates timeout-related resource limit settings.
-func validateTimeoutLimits() []string {
+func (s *Settings) validateTimeoutLimits() []string {
 	var validationErrors []string
 
-	if viper.IsSet(shared.ConfigKeyResourceLimitsFileProcessingTO) {
-		timeout := viper.GetInt(shared.ConfigKeyResourceLimitsFileProcessingTO)
+	if s.values().IsSet(shared.ConfigKeyResourceLimitsFileProcessingTO) {
+		timeout := s.values().GetInt(shared.ConfigKeyResourceLimitsFileProcessingTO)
 		if timeout < shared.ConfigFileProcessingTimeoutSecMin {
 			validationErrors = append(
 				validationErrors,
@@ -469,8 +777,8 @@ func validateTimeoutLimits() []string {
 		}
 	}
 
-	if viper.IsSet(shared.ConfigKeyResourceLimitsOverallTO) {
-		timeout := viper.GetInt(shared.ConfigKeyResourceLimitsOverallTO)
+	if s.values().IsSet(shared.ConfigKeyResourceLimitsOverallTO) {
+		timeout := s.values().GetInt(shared.ConfigKeyResourceLimitsOverallTO)
 		minTimeout := shared.ConfigOverallTimeoutSecMin
 		maxTimeout := shared.ConfigOverallTimeoutSecMax
 		if timeout < minTimeout {
@@ -491,11 +799,11 @@ func validateTimeoutLimits() []string {
 }
 
 // validateConcurrencyLimits validates concurrency-related resource limit settings.
-func validateConcurrencyLimits() []string {
+func (s *Settings) validateConcurrencyLimits() []string {
 	var validationErrors []string
 
-	if viper.IsSet(shared.ConfigKeyResourceLimitsMaxConcurrentReads) {
-		maxReads := viper.GetInt(shared.ConfigKeyResourceLimitsMaxConcurrentReads)
+	if s.values().IsSet(shared.ConfigKeyResourceLimitsMaxConcurrentReads) {
+		maxReads := s.values().GetInt(shared.ConfigKeyResourceLimitsMaxConcurrentReads)
 		minReads := shared.ConfigMaxConcurrentReadsMin
 		maxReadsLimit := shared.ConfigMaxConcurrentReadsMax
 		if maxReads < minReads {
@@ -512,8 +820,8 @@ func validateConcurrencyLimits() []string {
 		}
 	}
 
-	if viper.IsSet(shared.ConfigKeyResourceLimitsRateLimitFilesPerSec) {
-		rateLimit := viper.GetInt(shared.ConfigKeyResourceLimitsRateLimitFilesPerSec)
+	if s.values().IsSet(shared.ConfigKeyResourceLimitsRateLimitFilesPerSec) {
+		rateLimit := s.values().GetInt(shared.ConfigKeyResourceLimitsRateLimitFilesPerSec)
 		minRate := shared.ConfigRateLimitFilesPerSecMin
 		maxRate := shared.ConfigRateLimitFilesPerSecMax
 		if rateLimit < minRate {
@@ -534,14 +842,14 @@ func validateConcurrencyLimits() []string {
 }
 
 // validateMemoryLimits validates memory-related resource limit settings.
-func validateMemoryLimits() []string {
+func (s *Settings) validateMemoryLimits() []string {
 	var validationErrors []string
 
-	if !viper.IsSet(shared.ConfigKeyResourceLimitsHardMemoryLimitMB) {
+	if !s.values().IsSet(shared.ConfigKeyResourceLimitsHardMemoryLimitMB) {
 		return validationErrors
 	}
 
-	memLimit := viper.GetInt(shared.ConfigKeyResourceLimitsHardMemoryLimitMB)
+	memLimit := s.values().GetInt(shared.ConfigKeyResourceLimitsHardMemoryLimitMB)
 	minMemLimit := shared.ConfigHardMemoryLimitMBMin
 	maxMemLimit := shared.ConfigHardMemoryLimitMBMax
 	if memLimit < minMemLimit {
@@ -561,8 +869,8 @@ func validateMemoryLimits() []string {
 }
 
 // ValidateFileSize checks if a file size is within the configured limit.
-func ValidateFileSize(size int64) error {
-	limit := FileSizeLimit()
+func (s *Settings) ValidateFileSize(size int64) error {
+	limit := s.FileSizeLimit()
 	if size > limit {
 		return shared.NewStructuredError(
 			shared.ErrorTypeValidation,
@@ -577,22 +885,59 @@ func ValidateFileSize(size int64) error {
 }
 
 // ValidateOutputFormat checks if an output format is valid.
-func ValidateOutputFormat(format string) error {
-	if !IsValidFormat(format) {
-		return shared.NewStructuredError(
-			shared.ErrorTypeValidation,
-			shared.CodeValidationFormat,
-			fmt.Sprintf("unsupported output format: %s (supported: json, yaml, markdown)", format),
-			"",
-			map[string]any{"format": format},
-		)
+func (s *Settings) ValidateOutputFormat(format string) error {
+	if !s.IsValidFormat(format) {
+		return unsupportedFormatError(format)
+	}
+
+	return nil
+}
+
+// ValidateOutputFormatName checks that format is a built-in format or could name an
+// output.plugins format, for flags parsed before the configuration registering the plugins is
+// loaded. ValidateOutputFormat checks the plugin is registered once it is.
+func ValidateOutputFormatName(format string) error {
+	if !IsBuiltinFormat(format) && !formatPluginName.MatchString(format) {
+		return unsupportedFormatError(format)
+	}
+
+	return nil
+}
+
+// unsupportedFormatError reports an output format that is neither built in nor registered.
+func unsupportedFormatError(format string) error {
+	return shared.NewStructuredError(
+		shared.ErrorTypeValidation,
+		shared.CodeValidationFormat,
+		fmt.Sprintf(
+			"unsupported output format: %s "+
+				"(supported: json, yaml, markdown, plain, pdf, zip, or an output.plugins format)", format,
+		),
+		"",
+		map[string]any{"format": format},
+	)
+}
+
+// ValidateDocLanguages checks that every language is a supported documentation language code.
+func ValidateDocLanguages(languages []string) error {
+	for _, language := range languages {
+		if !slices.Contains(shared.DocLanguagesSupported, language) {
+			return shared.NewStructuredError(
+				shared.ErrorTypeValidation,
+				shared.CodeValidationFormat,
+				fmt.Sprintf("unsupported documentation language: %q (supported: %s)",
+					language, strings.Join(shared.DocLanguagesSupported, ", ")),
+				"",
+				map[string]any{"language": language},
+			)
+		}
 	}
 
 	return nil
 }
 
 // ValidateConcurrency checks if a concurrency level is valid.
-func ValidateConcurrency(concurrency int) error {
+func (s *Settings) ValidateConcurrency(concurrency int) error {
 	if concurrency < 1 {
 		return shared.NewStructuredError(
 			shared.ErrorTypeValidation,
@@ -603,8 +948,8 @@ func ValidateConcurrency(concurrency int) error {
 		)
 	}
 
-	if viper.IsSet(shared.ConfigKeyMaxConcurrency) {
-		maxConcurrency := MaxConcurrency()
+	if s.values().IsSet(shared.ConfigKeyMaxConcurrency) {
+		maxConcurrency := s.MaxConcurrency()
 		if concurrency > maxConcurrency {
 			return shared.NewStructuredError(
 				shared.ErrorTypeValidation,
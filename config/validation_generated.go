@@ -0,0 +1,45 @@
+// Package config handles application configuration management.
+package config
+
+import (
+	"fmt"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// validateGeneratedTextSettings validates the minified/high-entropy text detection settings.
+func (s *Settings) validateGeneratedTextSettings() []string {
+	var validationErrors []string
+
+	if s.values().IsSet(shared.ConfigKeyGeneratedTextAction) {
+		action := s.values().GetString(shared.ConfigKeyGeneratedTextAction)
+		if action != shared.GeneratedTextActionSkip && action != shared.GeneratedTextActionSummarize {
+			validationErrors = append(validationErrors, fmt.Sprintf(
+				"generatedText.action (%q) must be %q or %q",
+				action, shared.GeneratedTextActionSkip, shared.GeneratedTextActionSummarize,
+			))
+		}
+	}
+
+	minSize := s.values().GetInt64(shared.ConfigKeyGeneratedTextMinSize)
+	if s.values().IsSet(shared.ConfigKeyGeneratedTextMinSize) && minSize < 0 {
+		validationErrors = append(validationErrors, "generatedText.minSize must not be negative")
+	}
+
+	if s.values().IsSet(shared.ConfigKeyGeneratedTextMaxLineLength) &&
+		s.values().GetInt(shared.ConfigKeyGeneratedTextMaxLineLength) <= 0 {
+		validationErrors = append(validationErrors, "generatedText.maxLineLength must be positive")
+	}
+
+	if s.values().IsSet(shared.ConfigKeyGeneratedTextEntropy) {
+		threshold := s.values().GetFloat64(shared.ConfigKeyGeneratedTextEntropy)
+		if threshold <= 0 || threshold > shared.ConfigGeneratedTextEntropyMax {
+			validationErrors = append(validationErrors, fmt.Sprintf(
+				"generatedText.entropyThreshold (%g) must be greater than 0 and at most %g",
+				threshold, shared.ConfigGeneratedTextEntropyMax,
+			))
+		}
+	}
+
+	return validationErrors
+}
@@ -0,0 +1,69 @@
+// Package config handles application configuration management.
+package config
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// validateGitSettings validates git integration configuration settings.
+func (s *Settings) validateGitSettings() []string {
+	validationErrors := append(s.validateGitChurn(), s.validateGitLFS()...)
+
+	if !s.values().IsSet(shared.ConfigKeyGitAuthorThreshold) {
+		return validationErrors
+	}
+
+	threshold := s.values().GetFloat64(shared.ConfigKeyGitAuthorThreshold)
+	if threshold < shared.ConfigGitAuthorThresholdMin || threshold > shared.ConfigGitAuthorThresholdMax {
+		validationErrors = append(
+			validationErrors,
+			fmt.Sprintf(
+				"git.authorThreshold (%g) must be between %g and %g",
+				threshold, shared.ConfigGitAuthorThresholdMin, shared.ConfigGitAuthorThresholdMax,
+			),
+		)
+	}
+
+	return validationErrors
+}
+
+// validateGitChurn validates the churn window and the size of the top-churn section.
+func (s *Settings) validateGitChurn() []string {
+	var validationErrors []string
+
+	days := s.values().GetInt(shared.ConfigKeyGitChurnDays)
+	if s.values().IsSet(shared.ConfigKeyGitChurnDays) &&
+		(days < shared.ConfigGitChurnDaysMin || days > shared.ConfigGitChurnDaysMax) {
+		validationErrors = append(validationErrors, fmt.Sprintf(
+			"git.churnDays (%d) must be between %d and %d", days, shared.ConfigGitChurnDaysMin, shared.ConfigGitChurnDaysMax,
+		))
+	}
+	if top := s.values().GetInt(shared.ConfigKeyGitChurnTop); top < 0 {
+		validationErrors = append(validationErrors, fmt.Sprintf("git.churnTop (%d) must not be negative", top))
+	}
+
+	return validationErrors
+}
+
+// validateGitLFS validates the handling of Git LFS pointer files.
+func (s *Settings) validateGitLFS() []string {
+	var validationErrors []string
+
+	action := s.values().GetString(shared.ConfigKeyGitLFSAction)
+	if s.values().IsSet(shared.ConfigKeyGitLFSAction) &&
+		!slices.Contains([]string{shared.LFSActionSkip, shared.LFSActionInclude, shared.LFSActionFetch}, action) {
+		validationErrors = append(validationErrors, fmt.Sprintf(
+			"git.lfs.action (%s) must be one of: %s, %s, %s",
+			action, shared.LFSActionSkip, shared.LFSActionInclude, shared.LFSActionFetch,
+		))
+	}
+	size := s.values().GetInt64(shared.ConfigKeyGitLFSMaxFetchSize)
+	if s.values().IsSet(shared.ConfigKeyGitLFSMaxFetchSize) && size <= 0 {
+		validationErrors = append(validationErrors, fmt.Sprintf("git.lfs.maxFetchSize (%d) must be positive", size))
+	}
+
+	return validationErrors
+}
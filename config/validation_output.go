@@ -0,0 +1,164 @@
+// Package config handles application configuration management.
+package config
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// validateOutputSettings validates the output content transformation and buffering settings.
+func (s *Settings) validateOutputSettings() []string {
+	var validationErrors []string
+
+	if s.values().IsSet(shared.ConfigKeyOutputNormalizeLineEndings) {
+		style := s.values().GetString(shared.ConfigKeyOutputNormalizeLineEndings)
+		allowed := []string{shared.LineEndingsLF, shared.LineEndingsCRLF, shared.LineEndingsPreserve}
+		if !slices.Contains(allowed, style) {
+			validationErrors = append(validationErrors, fmt.Sprintf(
+				"output.normalizeLineEndings (%q) must be one of %v", style, allowed,
+			))
+		}
+	}
+
+	if s.values().IsSet(shared.ConfigKeyOutputBufferSize) {
+		size := s.values().GetInt(shared.ConfigKeyOutputBufferSize)
+		if size < 0 || size > shared.ConfigOutputBufferSizeMax {
+			validationErrors = append(validationErrors, fmt.Sprintf(
+				"output.bufferSize (%d) must be between 0 and %d", size, shared.ConfigOutputBufferSizeMax,
+			))
+		}
+	}
+
+	if length := s.OutputCompactMaxLineLength(); length < 0 {
+		validationErrors = append(validationErrors, fmt.Sprintf(
+			"%s (%d) must not be negative", shared.ConfigKeyOutputCompactMaxLineLength, length,
+		))
+	}
+
+	validationErrors = append(validationErrors, s.validateMarkdownWrap()...)
+	validationErrors = append(validationErrors, s.validatePlainDelimiter()...)
+	validationErrors = append(validationErrors, s.validatePDF()...)
+	validationErrors = append(validationErrors, s.validateOutputPlugins()...)
+	validationErrors = append(validationErrors, s.validateWhitespace(
+		shared.ConfigKeyOutputWhitespaceIndent, shared.ConfigKeyOutputWhitespaceTabWidth,
+	)...)
+	for language := range s.values().GetStringMap(shared.ConfigKeyOutputWhitespaceLanguages) {
+		prefix := shared.ConfigKeyOutputWhitespaceLanguages + "." + language
+		validationErrors = append(validationErrors, s.validateWhitespace(prefix+".indent", prefix+".tabWidth")...)
+	}
+
+	return validationErrors
+}
+
+// validateMarkdownWrap validates the markdown line wrapping settings. A hard-wrapped line must
+// have room for at least one character besides the marker.
+func (s *Settings) validateMarkdownWrap() []string {
+	limit := s.values().GetInt(shared.ConfigKeyOutputMarkdownMaxLineLen)
+	if limit < 0 {
+		return []string{fmt.Sprintf("output.markdown.maxLineLength (%d) must not be negative", limit)}
+	}
+
+	marker := s.values().GetString(shared.ConfigKeyOutputMarkdownWrapMarker)
+	if limit > 0 && s.values().GetBool(shared.ConfigKeyOutputMarkdownWrapCode) && utf8.RuneCountInString(marker) >= limit {
+		return []string{fmt.Sprintf(
+			"output.markdown.maxLineLength (%d) must be longer than output.markdown.wrapMarker (%q)", limit, marker,
+		)}
+	}
+
+	return nil
+}
+
+// validateWhitespace validates one set of indentation settings, global or per language.
+func (s *Settings) validateWhitespace(indentKey, tabWidthKey string) []string {
+	var validationErrors []string
+
+	if s.values().IsSet(indentKey) {
+		indent := s.values().GetString(indentKey)
+		allowed := []string{shared.IndentPreserve, shared.IndentSpaces, shared.IndentTabs}
+		if !slices.Contains(allowed, indent) {
+			validationErrors = append(validationErrors, fmt.Sprintf("%s (%q) must be one of %v", indentKey, indent, allowed))
+		}
+	}
+
+	if s.values().IsSet(tabWidthKey) {
+		width := s.values().GetInt(tabWidthKey)
+		if width < 1 || width > shared.ConfigOutputWhitespaceTabWidthMax {
+			validationErrors = append(validationErrors, fmt.Sprintf(
+				"%s (%d) must be between 1 and %d", tabWidthKey, width, shared.ConfigOutputWhitespaceTabWidthMax,
+			))
+		}
+	}
+
+	return validationErrors
+}
+
+// validateOutputPlugins validates the output.plugins formats: every plugin needs a command
+// and a name that is not a built-in format.
+func (s *Settings) validateOutputPlugins() []string {
+	if !s.values().IsSet(shared.ConfigKeyOutputPlugins) {
+		return nil
+	}
+
+	plugins := make(map[string]FormatPlugin)
+	if err := s.values().UnmarshalKey(shared.ConfigKeyOutputPlugins, &plugins); err != nil {
+		return []string{fmt.Sprintf("%s could not be decoded: %v", shared.ConfigKeyOutputPlugins, err)}
+	}
+
+	var validationErrors []string
+	for _, name := range slices.Sorted(maps.Keys(plugins)) {
+		key := shared.ConfigKeyOutputPlugins + "." + name
+		switch {
+		case IsBuiltinFormat(name):
+			validationErrors = append(validationErrors, key+" cannot replace the built-in "+name+" format")
+		case !formatPluginName.MatchString(name):
+			validationErrors = append(validationErrors, key+" must be lowercase letters, digits, - and _")
+		}
+		if command := plugins[name].Command; len(command) == 0 || strings.TrimSpace(command[0]) == "" {
+			validationErrors = append(validationErrors, key+".command must name the plugin program")
+		}
+	}
+
+	return validationErrors
+}
+
+// validatePlainDelimiter validates the plain bundle delimiter: a single line naming the file,
+// so every file section can be found again.
+func (s *Settings) validatePlainDelimiter() []string {
+	if !s.values().IsSet(shared.ConfigKeyOutputPlainDelimiter) {
+		return nil
+	}
+
+	delimiter := s.values().GetString(shared.ConfigKeyOutputPlainDelimiter)
+	if strings.Count(delimiter, shared.PlainDelimiterPath) != 1 || strings.ContainsAny(delimiter, "\r\n") {
+		return []string{fmt.Sprintf(
+			"output.plain.delimiter (%q) must be a single line containing %s once", delimiter, shared.PlainDelimiterPath,
+		)}
+	}
+
+	return nil
+}
+
+// validatePDF validates the page size and font size of PDF bundles.
+func (s *Settings) validatePDF() []string {
+	var validationErrors []string
+
+	allowed := []string{shared.PDFPageSizeA4, shared.PDFPageSizeLetter}
+	if size := s.OutputPDFPageSize(); !slices.Contains(allowed, size) {
+		validationErrors = append(validationErrors, fmt.Sprintf(
+			"%s (%q) must be one of %v", shared.ConfigKeyOutputPDFPageSize, size, allowed,
+		))
+	}
+	if size := s.OutputPDFFontSize(); size < shared.ConfigPDFFontSizeMin || size > shared.ConfigPDFFontSizeMax {
+		validationErrors = append(validationErrors, fmt.Sprintf(
+			"%s (%d) must be between %d and %d",
+			shared.ConfigKeyOutputPDFFontSize, size, shared.ConfigPDFFontSizeMin, shared.ConfigPDFFontSizeMax,
+		))
+	}
+
+	return validationErrors
+}
@@ -0,0 +1,43 @@
+// Package config handles application configuration management.
+package config
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// redactionName matches the names of custom redaction rules, which appear in the
+// [REDACTED:<name>] markers and the metrics report.
+var redactionName = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
+// validateRedactionSettings validates the redaction.patterns entries: every rule needs a
+// lowercase name and a regular expression that compiles.
+func (s *Settings) validateRedactionSettings() []string {
+	if !s.values().IsSet(shared.ConfigKeyRedactionPatterns) {
+		return nil
+	}
+
+	var patterns []RedactionPattern
+	if err := s.values().UnmarshalKey(shared.ConfigKeyRedactionPatterns, &patterns); err != nil {
+		return []string{fmt.Sprintf("%s could not be decoded: %v", shared.ConfigKeyRedactionPatterns, err)}
+	}
+
+	var validationErrors []string
+	for i, pattern := range patterns {
+		key := fmt.Sprintf("%s[%d]", shared.ConfigKeyRedactionPatterns, i)
+		if !redactionName.MatchString(pattern.Name) {
+			validationErrors = append(validationErrors, fmt.Sprintf(
+				"%s.name (%q) must start with a lowercase letter followed by letters, digits, _ or -", key, pattern.Name,
+			))
+		}
+		if pattern.Pattern == "" {
+			validationErrors = append(validationErrors, key+".pattern must not be empty")
+		} else if _, err := regexp.Compile(pattern.Pattern); err != nil {
+			validationErrors = append(validationErrors, fmt.Sprintf("%s.pattern is invalid: %v", key, err))
+		}
+	}
+
+	return validationErrors
+}
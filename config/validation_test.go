@@ -75,6 +75,290 @@ func TestValidateConfig(t *testing.T) {
 			wantErr:     true,
 			errContains: "maxConcurrency",
 		},
+		{
+			name: "git author threshold out of range",
+			config: map[string]any{
+				"git.authorThreshold": 150.0,
+			},
+			wantErr:     true,
+			errContains: "git.authorThreshold",
+		},
+		{
+			name: "plain delimiter without path",
+			config: map[string]any{
+				"output.plain.delimiter": "=====",
+			},
+			wantErr:     true,
+			errContains: "output.plain.delimiter",
+		},
+		{
+			name: "pdf page size unknown",
+			config: map[string]any{
+				"output.pdf.pageSize": "a3",
+			},
+			wantErr:     true,
+			errContains: "output.pdf.pageSize",
+		},
+		{
+			name: "pdf font size too small",
+			config: map[string]any{
+				"output.pdf.fontSize": 2,
+			},
+			wantErr:     true,
+			errContains: "output.pdf.fontSize",
+		},
+		{
+			name: "plugin replacing a built-in format",
+			config: map[string]any{
+				"output.plugins": map[string]any{"json": map[string]any{"command": []string{"jq"}}},
+			},
+			wantErr:     true,
+			errContains: "cannot replace the built-in json format",
+		},
+		{
+			name: "plugin without command",
+			config: map[string]any{
+				"output.plugins": map[string]any{"xml": map[string]any{}},
+			},
+			wantErr:     true,
+			errContains: "output.plugins.xml.command",
+		},
+		{
+			name: "budget with unknown fallback",
+			config: map[string]any{
+				"budgets": map[string]any{"docs/**": map[string]any{"maxBytes": 1000, "fallback": "drop"}},
+			},
+			wantErr:     true,
+			errContains: "budgets.docs/**.fallback",
+		},
+		{
+			name: "budget without limits",
+			config: map[string]any{
+				"budgets": map[string]any{"docs/**": map[string]any{"fallback": "outline"}},
+			},
+			wantErr:     true,
+			errContains: "needs maxBytes or maxTokens",
+		},
+		{
+			name: "wasm transform without module",
+			config: map[string]any{
+				"transforms.wasm": []any{map[string]any{"pattern": "**/*.sql"}},
+			},
+			wantErr:     true,
+			errContains: "transforms.wasm[0].module must not be empty",
+		},
+		{
+			name: "git churn window out of range",
+			config: map[string]any{
+				"git.churnDays": 0,
+			},
+			wantErr:     true,
+			errContains: "git.churnDays",
+		},
+		{
+			name: "unknown generated text action",
+			config: map[string]any{
+				"generatedText.action": "drop",
+			},
+			wantErr:     true,
+			errContains: "generatedText.action",
+		},
+		{
+			name: "generated text entropy threshold out of range",
+			config: map[string]any{
+				"generatedText.entropyThreshold": 9.0,
+			},
+			wantErr:     true,
+			errContains: "generatedText.entropyThreshold",
+		},
+		{
+			name: "redaction pattern that does not compile",
+			config: map[string]any{
+				"redaction.patterns": []any{map[string]any{"name": "ticket", "pattern": "TICKET-(\\d+"}},
+			},
+			wantErr:     true,
+			errContains: "redaction.patterns[0].pattern is invalid",
+		},
+		{
+			name: "redaction pattern without name",
+			config: map[string]any{
+				"redaction.patterns": []any{map[string]any{"pattern": "internal\\.example\\.com"}},
+			},
+			wantErr:     true,
+			errContains: "redaction.patterns[0].name",
+		},
+		{
+			name: "comments stripped from an unsupported language",
+			config: map[string]any{
+				"stripComments.languages": []string{"go", "cobol"},
+			},
+			wantErr:     true,
+			errContains: `stripComments.languages: unsupported language "cobol"`,
+		},
+		{
+			name: "negative compact line length",
+			config: map[string]any{
+				"output.compact.maxLineLength": -1,
+			},
+			wantErr:     true,
+			errContains: "output.compact.maxLineLength (-1) must not be negative",
+		},
+		{
+			name: "outlier percentage above 100",
+			config: map[string]any{
+				"collection.outlierPercent": 150,
+			},
+			wantErr:     true,
+			errContains: "collection.outlierPercent (150) must be between 0 and 100",
+		},
+		{
+			name: "format workers out of range",
+			config: map[string]any{
+				"performance.formatWorkers": 0,
+			},
+			wantErr:     true,
+			errContains: "performance.formatWorkers",
+		},
+		{
+			name: "negative max open files",
+			config: map[string]any{
+				"performance.maxOpenFiles": -1,
+			},
+			wantErr:     true,
+			errContains: "performance.maxOpenFiles (-1) must be 0 or positive",
+		},
+		{
+			name: "prefetch budget above memory budget",
+			config: map[string]any{
+				"backpressure.maxMemoryUsage": 1048576,
+				"performance.prefetchBytes":   2097152,
+			},
+			wantErr:     true,
+			errContains: "must not exceed backpressure.maxMemoryUsage",
+		},
+		{
+			name: "no prefetch readers",
+			config: map[string]any{
+				"performance.prefetchReaders": 0,
+			},
+			wantErr:     true,
+			errContains: "performance.prefetchReaders (0) must be at least 1",
+		},
+		{
+			name: "unknown hash algorithm",
+			config: map[string]any{
+				"performance.hashAlgorithm": "md5",
+			},
+			wantErr:     true,
+			errContains: "performance.hashAlgorithm",
+		},
+		{
+			name: "non-positive token calibration",
+			config: map[string]any{
+				"tokens.languages": map[string]any{"go": 0},
+			},
+			wantErr:     true,
+			errContains: "tokens.languages.go",
+		},
+		{
+			name: "negative slow file threshold",
+			config: map[string]any{
+				"warnings.slowFileSec": -1,
+			},
+			wantErr:     true,
+			errContains: "warnings.slowFileSec",
+		},
+		{
+			name: "unsupported documentation language",
+			config: map[string]any{
+				"docLanguage.include": []string{"en", "klingon"},
+			},
+			wantErr:     true,
+			errContains: "docLanguage.include",
+		},
+		{
+			name: "unknown progress style",
+			config: map[string]any{
+				"ui.progressStyle": "percent",
+			},
+			wantErr:     true,
+			errContains: "ui.progressStyle",
+		},
+		{
+			name: "unknown UI theme",
+			config: map[string]any{
+				"ui.theme": "emoji",
+			},
+			wantErr:     true,
+			errContains: "ui.theme",
+		},
+		{
+			name: "unknown share backend",
+			config: map[string]any{
+				"share.backend": "dropbox",
+			},
+			wantErr:     true,
+			errContains: "share.backend",
+		},
+		{
+			name: "paste backend without URL",
+			config: map[string]any{
+				"share.backend": "paste",
+			},
+			wantErr:     true,
+			errContains: "share.url is required",
+		},
+		{
+			name: "share URL without scheme",
+			config: map[string]any{
+				"share.backend": "paste",
+				"share.url":     "paste.internal/api",
+			},
+			wantErr:     true,
+			errContains: "must be an http or https URL",
+		},
+		{
+			name: "negative output buffer size",
+			config: map[string]any{
+				"output.bufferSize": -1,
+			},
+			wantErr:     true,
+			errContains: "output.bufferSize",
+		},
+		{
+			name: "negative markdown line length",
+			config: map[string]any{
+				"output.markdown.maxLineLength": -1,
+			},
+			wantErr:     true,
+			errContains: "output.markdown.maxLineLength",
+		},
+		{
+			name: "markdown line length too short for the wrap marker",
+			config: map[string]any{
+				"output.markdown.maxLineLength": 2,
+				"output.markdown.wrapCode":      true,
+				"output.markdown.wrapMarker":    " >>",
+			},
+			wantErr:     true,
+			errContains: "output.markdown.wrapMarker",
+		},
+		{
+			name: "unknown line ending style",
+			config: map[string]any{
+				"output.normalizeLineEndings": "cr",
+			},
+			wantErr:     true,
+			errContains: "output.normalizeLineEndings",
+		},
+		{
+			name: "unknown per-language indentation",
+			config: map[string]any{
+				"output.whitespace.languages": map[string]any{"python": map[string]any{"indent": "mixed"}},
+			},
+			wantErr:     true,
+			errContains: "output.whitespace.languages.python.indent",
+		},
 		{
 			name: "valid comprehensive config",
 			config: map[string]any{
@@ -131,7 +415,7 @@ func TestIsValidFormat(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		result := config.IsValidFormat(tt.format)
+		result := config.Global().IsValidFormat(tt.format)
 		if result != tt.valid {
 			t.Errorf("IsValidFormat(%q) = %v, want %v", tt.format, result, tt.valid)
 		}
@@ -155,7 +439,7 @@ func TestValidateFileSize(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		err := config.ValidateFileSize(tt.size)
+		err := config.Global().ValidateFileSize(tt.size)
 		if (err != nil) != tt.wantErr {
 			t.Errorf("%s: ValidateFileSize(%d) error = %v, wantErr %v", tt.name, tt.size, err, tt.wantErr)
 		}
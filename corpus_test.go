@@ -0,0 +1,213 @@
+package main
+
+import (
+	"encoding/json"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/ivuorinen/gibidify/fileproc"
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// corpusDir holds the fixture repositories written by scripts/generate-corpus.sh.
+const corpusDir = "testdata/corpus"
+
+// corpusFixtures lists, per fixture repository, the files every bundle must contain with their
+// language. Everything else in the fixture (ignored directories, .ignore matches, images and
+// binary data) must be left out.
+var corpusFixtures = map[string]map[string]string{
+	"gomodule": {
+		"README.md":                    "markdown",
+		"go.mod":                       "",
+		"main.go":                      "go",
+		"internal/greet/greet.go":      "go",
+		"internal/greet/greet_test.go": "go",
+	},
+	"nodeapp": {
+		".ignore":           "",
+		"package.json":      "json",
+		"package-lock.json": "json",
+		"src/index.js":      "javascript",
+		"src/util.ts":       "typescript",
+	},
+	"pythonpkg": {
+		"pyproject.toml":            "toml",
+		"src/pythonpkg/__init__.py": "python",
+		"src/pythonpkg/core.py":     "python",
+		"tests/test_core.py":        "python",
+	},
+	"mixedassets": {
+		"config.yaml":      "yaml",
+		"docs/guide.md":    "markdown",
+		"notes.txt":        "",
+		"scripts/build.sh": "bash",
+	},
+	"unicodepaths": {
+		"docs/🚀 launch plan.md":  "markdown",
+		"src/שלום עולם.py":       "python",
+		"src/مرحبا/main.go":      "go",
+		"données/café crème.txt": "",
+		"emoji 🎉/party.json":     "json",
+	},
+}
+
+// markdownFileHeader matches the header of a file section in a markdown bundle.
+var markdownFileHeader = regexp.MustCompile("(?m)^## File: `([^`]+)`$")
+
+// TestCorpusBundles bundles every fixture repository in every format through the full CLI
+// and checks which files are included and that their content survives intact.
+func TestCorpusBundles(t *testing.T) {
+	for _, fixture := range slices.Sorted(maps.Keys(corpusFixtures)) {
+		for _, format := range []string{shared.FormatJSON, shared.FormatYAML, shared.FormatMarkdown} {
+			t.Run(fixture+"/"+format, func(t *testing.T) {
+				source := copyCorpusFixture(t, fixture)
+				output := runCorpusBundle(t, source, format)
+				verifyCorpusBundle(t, source, format, output, corpusFixtures[fixture])
+			})
+		}
+	}
+}
+
+// copyCorpusFixture copies fixture to a temporary directory, outside this repository, so
+// git-derived metadata such as CODEOWNERS owners does not leak into the bundles.
+func copyCorpusFixture(t *testing.T, fixture string) string {
+	t.Helper()
+
+	source := filepath.Join(t.TempDir(), fixture)
+	testutil.MustSucceed(t, os.CopyFS(source, os.DirFS(filepath.Join(corpusDir, fixture))), "copying fixture")
+
+	return source
+}
+
+// runCorpusBundle runs the CLI on source and returns the bundle it wrote.
+func runCorpusBundle(t *testing.T, source, format string) []byte {
+	t.Helper()
+	defer testutil.SuppressAllOutput(t)()
+	defer withIsolatedFlags(t)()
+
+	destination := filepath.Join(t.TempDir(), "bundle."+format)
+	os.Args = []string{
+		shared.AppName, "-source", source, "-destination", destination, "-format", format, "-no-ui",
+	}
+	testutil.MustSucceed(t, run(t.Context()), "running gibidify")
+
+	output, err := os.ReadFile(destination) // #nosec G304 -- destination is in t.TempDir()
+	testutil.MustSucceed(t, err, "reading bundle")
+
+	return output
+}
+
+// verifyCorpusBundle checks that output holds exactly the wanted files of source, in their
+// original form.
+func verifyCorpusBundle(t *testing.T, source, format string, output []byte, want map[string]string) {
+	t.Helper()
+
+	if format == shared.FormatMarkdown {
+		verifyMarkdownCorpusBundle(t, source, string(output), want)
+
+		return
+	}
+
+	var bundle fileproc.OutputData
+	if format == shared.FormatJSON {
+		testutil.MustSucceed(t, json.Unmarshal(output, &bundle), "decoding JSON bundle")
+	} else {
+		testutil.MustSucceed(t, yaml.Unmarshal(output, &bundle), "decoding YAML bundle")
+	}
+
+	got := make(map[string]string, len(bundle.Files))
+	for _, file := range bundle.Files {
+		got[file.Path] = file.Language
+		if wantContent := corpusSection(t, source, file.Path); file.Content != wantContent {
+			t.Errorf("%s content = %q, want %q", file.Path, file.Content, wantContent)
+		}
+	}
+	if !maps.Equal(got, want) {
+		t.Errorf("bundled files = %v, want %v", got, want)
+	}
+}
+
+// verifyMarkdownCorpusBundle checks the file sections of a markdown bundle.
+func verifyMarkdownCorpusBundle(t *testing.T, source, output string, want map[string]string) {
+	t.Helper()
+
+	var got []string
+	for _, match := range markdownFileHeader.FindAllStringSubmatch(output, -1) {
+		got = append(got, match[1])
+		if section := corpusSection(t, source, match[1]); !strings.Contains(output, section) {
+			t.Errorf("markdown bundle is missing the content of %s", match[1])
+		}
+	}
+	slices.Sort(got)
+	if wantPaths := slices.Sorted(maps.Keys(want)); !slices.Equal(got, wantPaths) {
+		t.Errorf("bundled files = %v, want %v", got, wantPaths)
+	}
+}
+
+// corpusSection returns the section the file processor renders for the file at relPath.
+func corpusSection(t *testing.T, source, relPath string) string {
+	t.Helper()
+
+	content, err := os.ReadFile(filepath.Join(source, relPath)) // #nosec G304 -- fixture file in t.TempDir()
+	testutil.MustSucceed(t, err, "reading fixture file")
+
+	return "\n---\n" + relPath + "\n" + string(content) + "\n"
+}
+
+// TestCorpusMatchesGenerator checks that the committed corpus is what scripts/generate-corpus.sh writes.
+func TestCorpusMatchesGenerator(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not available")
+	}
+
+	generated := t.TempDir()
+	cmd := exec.Command("bash", "scripts/generate-corpus.sh", generated) // #nosec G204 -- fixed script
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("generate-corpus.sh failed: %v\n%s", err, out)
+	}
+
+	want := readCorpusTree(t, generated)
+	got := readCorpusTree(t, corpusDir)
+	for _, path := range slices.Sorted(maps.Keys(want)) {
+		if content, ok := got[path]; !ok || content != want[path] {
+			t.Errorf("%s differs from the generator output; rerun scripts/generate-corpus.sh", path)
+		}
+	}
+	for path := range got {
+		if _, ok := want[path]; !ok {
+			t.Errorf("%s is not written by the generator; remove it or add it to scripts/generate-corpus.sh", path)
+		}
+	}
+}
+
+// readCorpusTree returns the content of every file under root, keyed by slash-separated relative path.
+func readCorpusTree(t *testing.T, root string) map[string]string {
+	t.Helper()
+
+	files := make(map[string]string)
+	err := filepath.WalkDir(root, func(path string, entry os.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		content, err := os.ReadFile(path) // #nosec G304 -- walking the corpus
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		files[filepath.ToSlash(rel)] = string(content)
+
+		return err
+	})
+	testutil.MustSucceed(t, err, "reading corpus")
+
+	return files
+}
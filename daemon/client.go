@@ -0,0 +1,62 @@
+// Package daemon serves gibidify commands over a unix socket using line-delimited JSON-RPC 2.0.
+package daemon
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// Call sends a single request to the daemon listening on socketPath and decodes
+// the result into result, which may be nil to discard it.
+func Call(ctx context.Context, socketPath, method string, params, result any) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "unix", socketPath)
+	if err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIORead, "connecting to daemon").
+			WithFilePath(socketPath)
+	}
+	defer shared.SafeCloseReader(conn, socketPath)
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	req := Request{JSONRPC: jsonRPCVersion, ID: json.RawMessage("1"), Method: method}
+	if params != nil {
+		if req.Params, err = json.Marshal(params); err != nil {
+			return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOEncoding, "encoding daemon params")
+		}
+	}
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOWrite, "sending daemon request")
+	}
+
+	reader := bufio.NewReader(conn)
+	line, err := reader.ReadBytes('\n')
+	if err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIORead, "reading daemon response")
+	}
+
+	var resp struct {
+		Result json.RawMessage `json:"result"`
+		Error  *Error          `json:"error"`
+	}
+	if err := json.Unmarshal(line, &resp); err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOEncoding, "decoding daemon response")
+	}
+	if resp.Error != nil {
+		return resp.Error
+	}
+	if result == nil || len(resp.Result) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(resp.Result, result); err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOEncoding, "decoding daemon result")
+	}
+
+	return nil
+}
@@ -0,0 +1,146 @@
+// Package daemon serves gibidify commands over a unix socket using line-delimited JSON-RPC 2.0.
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	httppprof "net/http/pprof"
+	"net/url"
+	"runtime/pprof"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+const (
+	// profileReadHeaderTimeout bounds reading the request headers of the profiling endpoints.
+	profileReadHeaderTimeout = 10 * time.Second
+	// profilePushTimeout bounds one profile upload to the Pyroscope server.
+	profilePushTimeout = 30 * time.Second
+)
+
+// ServeProfiles serves the net/http/pprof endpoints under /debug/pprof/ on the TCP address addr
+// until ctx is canceled, and returns the address listened on. CPU and goroutine profiles carry
+// the LabelMethod and LabelRequest labels of the requests being served.
+func ServeProfiles(ctx context.Context, addr string) (net.Addr, error) {
+	var lc net.ListenConfig
+	listener, err := lc.Listen(ctx, "tcp", addr)
+	if err != nil {
+		return nil, shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOFileCreate, "failed to listen for profiling").
+			WithContext("addr", addr)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", httppprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", httppprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", httppprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", httppprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", httppprof.Trace)
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: profileReadHeaderTimeout}
+
+	go func() {
+		<-ctx.Done()
+		_ = server.Close()
+	}()
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			shared.LogError("Profiling endpoint failed", err)
+		}
+	}()
+
+	return listener.Addr(), nil
+}
+
+// ProfilePusher pushes the CPU and heap profiles of the process to a Pyroscope server, one of
+// each per interval, under an application name. CPU samples keep their pprof labels, which
+// Pyroscope offers as tags.
+type ProfilePusher struct {
+	endpoint string
+	app      string
+	interval time.Duration
+	http     *http.Client
+}
+
+// NewProfilePusher creates a pusher uploading to the Pyroscope server at serverURL.
+func NewProfilePusher(serverURL, app string, interval time.Duration) *ProfilePusher {
+	return &ProfilePusher{
+		endpoint: strings.TrimSuffix(serverURL, "/") + "/ingest",
+		app:      app,
+		interval: interval,
+		http:     &http.Client{Timeout: profilePushTimeout},
+	}
+}
+
+// Run profiles the process and pushes the profiles until ctx is canceled, finishing with the
+// profiles of the last, partial interval.
+func (p *ProfilePusher) Run(ctx context.Context) {
+	for ctx.Err() == nil {
+		p.pushInterval(ctx)
+	}
+}
+
+// pushInterval records a CPU profile for one interval and pushes it with a heap profile. The
+// CPU profile is skipped while another one is being recorded, such as by /debug/pprof/profile.
+func (p *ProfilePusher) pushInterval(ctx context.Context) {
+	from := time.Now()
+	var cpu bytes.Buffer
+	cpuErr := pprof.StartCPUProfile(&cpu)
+
+	timer := time.NewTimer(p.interval)
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+	timer.Stop()
+	until := time.Now()
+
+	if cpuErr == nil {
+		pprof.StopCPUProfile()
+		p.push(ctx, cpu.Bytes(), from, until)
+	} else {
+		shared.GetLogger().Debugf("Skipping CPU profile push: %v", cpuErr)
+	}
+
+	var heap bytes.Buffer
+	if err := pprof.Lookup("heap").WriteTo(&heap, 0); err == nil {
+		p.push(ctx, heap.Bytes(), from, until)
+	}
+}
+
+// push uploads one pprof profile covering from to until, logging failures: profiling must not
+// disturb the daemon. The upload outlives ctx so the last profiles are pushed on shutdown.
+func (p *ProfilePusher) push(ctx context.Context, profile []byte, from, until time.Time) {
+	query := url.Values{
+		"name":    {p.app},
+		"from":    {strconv.FormatInt(from.Unix(), 10)},
+		"until":   {strconv.FormatInt(until.Unix(), 10)},
+		"format":  {"pprof"},
+		"spyName": {"gospy"},
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), profilePushTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint+"?"+query.Encode(), bytes.NewReader(profile))
+	if err != nil {
+		shared.LogError("Error building profile push", err)
+
+		return
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	resp, err := p.http.Do(req) // #nosec G107 -- the server is given with --pyroscope
+	if err != nil {
+		shared.GetLogger().Warnf("Pushing profile to %s failed: %v", p.endpoint, err)
+
+		return
+	}
+	defer shared.SafeCloseReader(resp.Body, p.endpoint)
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		shared.GetLogger().Warnf("Pushing profile to %s returned %s", p.endpoint, resp.Status)
+	}
+}
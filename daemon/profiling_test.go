@@ -0,0 +1,61 @@
+package daemon_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ivuorinen/gibidify/daemon"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// TestServeProfiles tests that the pprof index is served on the returned address.
+func TestServeProfiles(t *testing.T) {
+	addr, err := daemon.ServeProfiles(t.Context(), "127.0.0.1:0")
+	testutil.MustSucceed(t, err, "serving profiles")
+
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, "http://"+addr.String()+"/debug/pprof/", nil)
+	testutil.MustSucceed(t, err, "building request")
+	resp, err := http.DefaultClient.Do(req)
+	testutil.MustSucceed(t, err, "fetching index")
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), "goroutine") {
+		t.Errorf("index = %s %q, want the profile list", resp.Status, body)
+	}
+}
+
+// TestProfilePusher tests that profiles are pushed as pprof under the application name, and
+// that Run returns once canceled.
+func TestProfilePusher(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		pushes []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		query := r.URL.Query()
+		mu.Lock()
+		if r.URL.Path == "/ingest" && query.Get("format") == "pprof" && len(body) > 0 {
+			pushes = append(pushes, query.Get("name"))
+		}
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+	daemon.NewProfilePusher(server.URL+"/", "app{source=demo}", 20*time.Millisecond).Run(ctx)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(pushes) < 2 || pushes[0] != "app{source=demo}" {
+		t.Errorf("pushes = %v, want profiles named app{source=demo}", pushes)
+	}
+}
@@ -0,0 +1,46 @@
+// Package daemon serves gibidify commands over a unix socket using line-delimited JSON-RPC 2.0.
+package daemon
+
+import "encoding/json"
+
+// jsonRPCVersion is the protocol version carried in every message.
+const jsonRPCVersion = "2.0"
+
+// JSON-RPC 2.0 error codes.
+const (
+	// CodeParseError is returned when a request line is not valid JSON.
+	CodeParseError = -32700
+	// CodeInvalidRequest is returned when a request lacks a method.
+	CodeInvalidRequest = -32600
+	// CodeMethodNotFound is returned for unregistered methods.
+	CodeMethodNotFound = -32601
+	// CodeInternalError is returned when a handler fails.
+	CodeInternalError = -32603
+)
+
+// Request is a JSON-RPC 2.0 request. Each request occupies a single line on the socket.
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// Response is a JSON-RPC 2.0 response carrying either a result or an error.
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Error is a JSON-RPC 2.0 error object.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	return e.Message
+}
@@ -0,0 +1,167 @@
+// Package daemon serves gibidify commands over a unix socket using line-delimited JSON-RPC 2.0.
+package daemon
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"runtime/pprof"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// socketPermission restricts the control socket to the current user.
+const socketPermission = 0o600
+
+// HandlerFunc handles one method call. params is nil when the request carries none.
+type HandlerFunc func(ctx context.Context, params json.RawMessage) (any, error)
+
+// Profiler labels set on every request, so CPU and goroutine profiles attribute samples to it.
+const (
+	// LabelMethod holds the method of the request.
+	LabelMethod = "rpc_method"
+	// LabelRequest holds the number of the request, counted from 1 since the server started.
+	LabelRequest = "rpc_request"
+)
+
+// Server dispatches JSON-RPC requests to registered handlers.
+type Server struct {
+	handlers map[string]HandlerFunc
+	requests atomic.Uint64
+}
+
+// NewServer creates a server without handlers.
+func NewServer() *Server {
+	return &Server{handlers: make(map[string]HandlerFunc)}
+}
+
+// Handle registers fn for method, replacing any previous handler.
+func (s *Server) Handle(method string, fn HandlerFunc) {
+	s.handlers[method] = fn
+}
+
+// Listen creates a unix socket at path, replacing a stale socket left by a previous run.
+func Listen(path string) (net.Listener, error) {
+	if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		if conn, dialErr := net.Dial("unix", path); dialErr == nil {
+			_ = conn.Close()
+
+			return nil, shared.NewStructuredError(
+				shared.ErrorTypeValidation, shared.CodeFSAccess, "daemon socket is already in use", path, nil,
+			)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, shared.WrapError(err, shared.ErrorTypeFileSystem, shared.CodeFSAccess, "removing stale socket").
+				WithFilePath(path)
+		}
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOFileCreate, "failed to listen on socket").
+			WithFilePath(path)
+	}
+	if err := os.Chmod(path, socketPermission); err != nil {
+		_ = listener.Close()
+
+		return nil, shared.WrapError(err, shared.ErrorTypeFileSystem, shared.CodeFSPermission, "securing socket").
+			WithFilePath(path)
+	}
+
+	return listener, nil
+}
+
+// Serve accepts connections until ctx is canceled, then closes the listener and
+// waits for open connections to finish their current request.
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	go func() {
+		<-ctx.Done()
+		_ = listener.Close()
+	}()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+
+			return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIORead, "accepting daemon connection")
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.serveConn(ctx, conn)
+		}()
+	}
+}
+
+// serveConn answers requests on conn, one JSON object per line, until the client disconnects.
+func (s *Server) serveConn(ctx context.Context, conn net.Conn) {
+	defer shared.SafeCloseReader(conn, "daemon connection")
+
+	go func() {
+		<-ctx.Done()
+		_ = conn.Close()
+	}()
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, shared.FileProcessingStreamChunkSize), shared.BytesPerMB)
+	encoder := json.NewEncoder(conn)
+	for scanner.Scan() {
+		if err := encoder.Encode(s.dispatch(ctx, scanner.Bytes())); err != nil {
+			shared.GetLogger().Debugf("Daemon client went away: %v", err)
+
+			return
+		}
+	}
+}
+
+// dispatch decodes one request line and runs its handler.
+func (s *Server) dispatch(ctx context.Context, line []byte) Response {
+	var req Request
+	if err := json.Unmarshal(line, &req); err != nil {
+		return errorResponse(nil, CodeParseError, "parse error: "+err.Error())
+	}
+	if req.Method == "" {
+		return errorResponse(req.ID, CodeInvalidRequest, "missing method")
+	}
+
+	handler, ok := s.handlers[req.Method]
+	if !ok {
+		return errorResponse(req.ID, CodeMethodNotFound, "method not found: "+req.Method)
+	}
+
+	var (
+		result any
+		err    error
+	)
+	labels := pprof.Labels(LabelMethod, req.Method, LabelRequest, strconv.FormatUint(s.requests.Add(1), 10))
+	pprof.Do(ctx, labels, func(ctx context.Context) {
+		result, err = handler(ctx, req.Params)
+	})
+	if err != nil {
+		return errorResponse(req.ID, CodeInternalError, err.Error())
+	}
+
+	return Response{JSONRPC: jsonRPCVersion, ID: req.ID, Result: result}
+}
+
+// errorResponse builds an error response for the request id.
+func errorResponse(id json.RawMessage, code int, msg string) Response {
+	if id == nil {
+		id = json.RawMessage(shared.LiteralNull)
+	}
+
+	return Response{JSONRPC: jsonRPCVersion, ID: id, Error: &Error{Code: code, Message: msg}}
+}
@@ -0,0 +1,106 @@
+package daemon_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"runtime/pprof"
+	"strings"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/daemon"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// startServer serves s on a socket in a temp dir until the test ends and returns the socket path.
+func startServer(t *testing.T, s *daemon.Server) string {
+	t.Helper()
+
+	socket := filepath.Join(t.TempDir(), "d.sock")
+	listener, err := daemon.Listen(socket)
+	testutil.MustSucceed(t, err, "listening")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- s.Serve(ctx, listener) }()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Serve returned %v", err)
+		}
+	})
+
+	return socket
+}
+
+// TestServerDispatch tests method dispatch, results, and JSON-RPC errors.
+func TestServerDispatch(t *testing.T) {
+	s := daemon.NewServer()
+	s.Handle("echo", func(_ context.Context, params json.RawMessage) (any, error) {
+		var in map[string]string
+		if err := json.Unmarshal(params, &in); err != nil {
+			return nil, err
+		}
+
+		return in, nil
+	})
+	s.Handle("fail", func(context.Context, json.RawMessage) (any, error) {
+		return nil, errors.New("boom")
+	})
+	socket := startServer(t, s)
+
+	var out map[string]string
+	err := daemon.Call(t.Context(), socket, "echo", map[string]string{"hello": "world"}, &out)
+	testutil.MustSucceed(t, err, "calling echo")
+	if out["hello"] != "world" {
+		t.Errorf("echo result = %v", out)
+	}
+
+	tests := []struct {
+		method   string
+		wantCode int
+		wantMsg  string
+	}{
+		{method: "fail", wantCode: daemon.CodeInternalError, wantMsg: "boom"},
+		{method: "missing", wantCode: daemon.CodeMethodNotFound, wantMsg: "method not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			err := daemon.Call(t.Context(), socket, tt.method, nil, nil)
+			var rpcErr *daemon.Error
+			if !errors.As(err, &rpcErr) || rpcErr.Code != tt.wantCode || !strings.Contains(rpcErr.Message, tt.wantMsg) {
+				t.Errorf("Call(%s) error = %v, want code %d containing %q", tt.method, err, tt.wantCode, tt.wantMsg)
+			}
+		})
+	}
+}
+
+// TestServerProfilerLabels tests that handlers run under the method and request number labels.
+func TestServerProfilerLabels(t *testing.T) {
+	s := daemon.NewServer()
+	s.Handle("labels", func(ctx context.Context, _ json.RawMessage) (any, error) {
+		method, _ := pprof.Label(ctx, daemon.LabelMethod)
+		request, _ := pprof.Label(ctx, daemon.LabelRequest)
+
+		return []string{method, request}, nil
+	})
+	socket := startServer(t, s)
+
+	for _, want := range []string{"1", "2"} {
+		var labels []string
+		testutil.MustSucceed(t, daemon.Call(t.Context(), socket, "labels", nil, &labels), "calling labels")
+		if len(labels) != 2 || labels[0] != "labels" || labels[1] != want {
+			t.Errorf("labels = %v, want [labels %s]", labels, want)
+		}
+	}
+}
+
+// TestListenSocketInUse tests that a live socket is not replaced.
+func TestListenSocketInUse(t *testing.T) {
+	socket := startServer(t, daemon.NewServer())
+
+	if _, err := daemon.Listen(socket); err == nil || !strings.Contains(err.Error(), "already in use") {
+		t.Errorf("expected socket in use error, got %v", err)
+	}
+}
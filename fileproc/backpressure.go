@@ -27,12 +27,18 @@ type BackpressureManager struct {
 
 // NewBackpressureManager creates a new back-pressure manager with configuration.
 func NewBackpressureManager() *BackpressureManager {
+	return NewBackpressureManagerWithSettings(config.Global())
+}
+
+// NewBackpressureManagerWithSettings creates a new back-pressure manager with the backpressure
+// configuration in settings.
+func NewBackpressureManagerWithSettings(settings *config.Settings) *BackpressureManager {
 	return &BackpressureManager{
-		enabled:             config.BackpressureEnabled(),
-		maxMemoryUsage:      config.MaxMemoryUsage(),
-		memoryCheckInterval: config.MemoryCheckInterval(),
-		maxPendingFiles:     config.MaxPendingFiles(),
-		maxPendingWrites:    config.MaxPendingWrites(),
+		enabled:             settings.BackpressureEnabled(),
+		maxMemoryUsage:      settings.MaxMemoryUsage(),
+		memoryCheckInterval: settings.MemoryCheckInterval(),
+		maxPendingFiles:     settings.MaxPendingFiles(),
+		maxPendingWrites:    settings.MaxPendingWrites(),
 		lastMemoryCheck:     time.Now(),
 	}
 }
@@ -0,0 +1,271 @@
+// Package fileproc handles file processing, collection, and output formatting.
+package fileproc
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	ignore "github.com/sabhiram/go-gitignore"
+
+	"github.com/ivuorinen/gibidify/config"
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// outlineDeclaration matches the lines an outline keeps: declarations of functions, types,
+// classes and modules in the common languages, and package or import lines.
+var outlineDeclaration = regexp.MustCompile(
+	`^\s*(?:(?:export|public|private|protected|internal|static|abstract|final|async|default|` +
+		`pub(?:\([a-z]+\))?)\s+)*` +
+		`(?:func|def|class|type|interface|struct|enum|trait|impl|fn|function|module|package|import|namespace|` +
+		`object|record|const|var|let|val|mod|use)\b`,
+)
+
+// Budgets limits the bytes and tokens of the files matching the budgets globs. A glob over its
+// budget gets its fallback: its files are truncated or reduced to an outline to share the
+// budget, or rolled up into one entry.
+type Budgets struct {
+	root    string
+	budgets []*budget
+	limits  map[string]budgetLimit
+	// settings calibrates the token estimates.
+	settings *config.Settings
+}
+
+// budget is one budgets entry and the files counted toward it.
+type budget struct {
+	config.Budget
+	pattern string
+	matcher *ignore.GitIgnore
+	files   []budgetFile
+}
+
+// budgetFile is a file counted toward a budget.
+type budgetFile struct {
+	path   string
+	size   int64
+	tokens int64
+}
+
+// budgetLimit is the share of its budget a truncated or outlined file may take, math.MaxInt64
+// when the budget does not limit bytes or tokens.
+type budgetLimit struct {
+	fallback  string
+	pattern   string
+	maxBytes  int64
+	maxTokens int64
+}
+
+// NewBudgets creates the budgets of the gitignore-style globs in budgets, matched
+// case-insensitively against paths relative to root. It returns nil when there are none.
+// A file counts toward the most specific (longest) glob matching it.
+func NewBudgets(root string, budgets map[string]config.Budget) *Budgets {
+	return NewBudgetsWithSettings(root, budgets, config.Global())
+}
+
+// NewBudgetsWithSettings works like NewBudgets, estimating tokens with the tokens
+// calibration in settings.
+func NewBudgetsWithSettings(root string, budgets map[string]config.Budget, settings *config.Settings) *Budgets {
+	if len(budgets) == 0 {
+		return nil
+	}
+
+	b := &Budgets{root: root, limits: make(map[string]budgetLimit), settings: settings}
+	for pattern, limits := range budgets {
+		pattern = strings.ToLower(pattern)
+		b.budgets = append(b.budgets, &budget{
+			Budget:  limits,
+			pattern: pattern,
+			matcher: ignore.CompileIgnoreLines(pattern),
+		})
+	}
+	slices.SortFunc(b.budgets, func(x, y *budget) int {
+		return cmp.Or(cmp.Compare(len(y.pattern), len(x.pattern)), strings.Compare(x.pattern, y.pattern))
+	})
+
+	return b
+}
+
+// Apply counts files toward their budgets, sizes giving their sizes in bytes and registry their
+// languages for token estimates. Files of budgets over their limit get the budget's fallback:
+// rolled up files are taken out of the returned files and listed in the returned entries, and
+// truncated or outlined files get a share of the budget that Limit reports.
+func (b *Budgets) Apply(files []string, sizes map[string]int64, registry *FileTypeRegistry) ([]string, []WriteRequest) {
+	for _, file := range files {
+		rel, err := filepath.Rel(b.root, file)
+		if err != nil {
+			continue
+		}
+		rel = strings.ToLower(filepath.ToSlash(rel))
+		for _, bu := range b.budgets {
+			if bu.matcher.MatchesPath(rel) {
+				tokens, _ := estimateTokens(b.settings, sizes[file], registry.Language(file))
+				bu.files = append(bu.files, budgetFile{path: file, size: sizes[file], tokens: tokens})
+
+				break
+			}
+		}
+	}
+
+	rolledUp := make(map[string]bool)
+	var entries []WriteRequest
+	for _, bu := range b.budgets {
+		if !bu.exceeded() {
+			continue
+		}
+		shared.GetLogger().Infof("Budget %s exceeded by its %d files, applying %s",
+			bu.pattern, len(bu.files), bu.fallback())
+		if bu.fallback() == shared.BudgetFallbackRollup {
+			rollups := NewRollups(b.root, []string{bu.pattern})
+			for _, file := range bu.files {
+				rollups.Claim(file.path, file.size)
+				rolledUp[file.path] = true
+			}
+			entries = append(entries, rollups.Entries()...)
+
+			continue
+		}
+		b.share(bu)
+	}
+
+	kept := make([]string, 0, len(files))
+	for _, file := range files {
+		if !rolledUp[file] {
+			kept = append(kept, file)
+		}
+	}
+
+	return kept, entries
+}
+
+// exceeded reports whether the files of the budget hold more bytes or tokens than it allows.
+func (bu *budget) exceeded() bool {
+	var size, tokens int64
+	for _, file := range bu.files {
+		size += file.size
+		tokens += file.tokens
+	}
+
+	return (bu.MaxBytes > 0 && size > bu.MaxBytes) || (bu.MaxTokens > 0 && tokens > bu.MaxTokens)
+}
+
+// fallback returns the fallback of the budget, truncation unless configured otherwise.
+func (bu *budget) fallback() string {
+	return cmp.Or(strings.ToLower(bu.Fallback), shared.BudgetFallbackTruncate)
+}
+
+// share gives every file of the budget the same cap, chosen so the files fit the budget
+// together; files smaller than the cap leave their unused share to the others.
+func (b *Budgets) share(bu *budget) {
+	sizes := make([]int64, 0, len(bu.files))
+	tokens := make([]int64, 0, len(bu.files))
+	for _, file := range bu.files {
+		sizes = append(sizes, file.size)
+		tokens = append(tokens, file.tokens)
+	}
+
+	limit := budgetLimit{fallback: bu.fallback(), pattern: bu.pattern, maxBytes: math.MaxInt64, maxTokens: math.MaxInt64}
+	if bu.MaxBytes > 0 {
+		limit.maxBytes = fairShare(sizes, bu.MaxBytes)
+	}
+	if bu.MaxTokens > 0 {
+		limit.maxTokens = fairShare(tokens, bu.MaxTokens)
+	}
+	for _, file := range bu.files {
+		b.limits[file.path] = limit
+	}
+}
+
+// fairShare returns the largest cap such that the amounts, each cut to the cap, add up to at
+// most budget.
+func fairShare(amounts []int64, budget int64) int64 {
+	sorted := slices.Sorted(slices.Values(amounts))
+	remaining := budget
+	for i, amount := range sorted {
+		share := remaining / int64(len(sorted)-i)
+		if amount > share {
+			return share
+		}
+		remaining -= amount
+	}
+
+	return math.MaxInt64
+}
+
+// Limit applies the fallback of the budget of the file at filePath, shown as relPath, to its
+// content text. It returns the reduced text and the note recorded as omitted metadata, or
+// text unchanged and "" when the file is within its budget.
+func (b *Budgets) Limit(filePath, relPath, text string, registry *FileTypeRegistry) (string, string) {
+	if b == nil {
+		return text, ""
+	}
+	limit, ok := b.limits[filePath]
+	if !ok {
+		return text, ""
+	}
+
+	maxBytes := limit.maxBytes
+	if limit.maxTokens != math.MaxInt64 {
+		ratio := b.settings.TokensBytesPerToken(registry.Language(relPath))
+		maxBytes = min(maxBytes, int64(float64(limit.maxTokens)*ratio))
+	}
+
+	reduced, note := text, ""
+	if limit.fallback == shared.BudgetFallbackOutline {
+		reduced = outline(text)
+		note = fmt.Sprintf("outline of %d lines (budget %s)", strings.Count(text, "\n")+1, limit.pattern)
+	}
+	if int64(len(reduced)) > maxBytes {
+		reduced = truncateLines(reduced, maxBytes)
+		note = fmt.Sprintf("truncated to %d of %d bytes (budget %s)", len(reduced), len(text), limit.pattern)
+		if limit.fallback == shared.BudgetFallbackOutline {
+			note = fmt.Sprintf("outline truncated to %d bytes (budget %s)", len(reduced), limit.pattern)
+		}
+	}
+	if note == "" {
+		return text, ""
+	}
+
+	return reduced, note
+}
+
+// Applies reports whether the file at filePath is truncated or outlined, so it has to be read
+// in full rather than streamed.
+func (b *Budgets) Applies(filePath string) bool {
+	if b == nil {
+		return false
+	}
+	_, ok := b.limits[filePath]
+
+	return ok
+}
+
+// outline returns the declaration lines of text, each prefixed with its line number.
+func outline(text string) string {
+	var b strings.Builder
+	for i, line := range strings.Split(text, "\n") {
+		if outlineDeclaration.MatchString(line) {
+			fmt.Fprintf(&b, "%d: %s\n", i+1, strings.TrimRight(line, " \t\r"))
+		}
+	}
+
+	return b.String()
+}
+
+// truncateLines cuts text to at most maxBytes bytes, at the end of the last whole line that
+// fits when there is one.
+func truncateLines(text string, maxBytes int64) string {
+	if maxBytes <= 0 {
+		return ""
+	}
+	cut := text[:maxBytes]
+	if end := strings.LastIndexByte(cut, '\n'); end >= 0 {
+		return cut[:end+1]
+	}
+
+	return strings.ToValidUTF8(cut, "")
+}
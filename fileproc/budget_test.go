@@ -0,0 +1,135 @@
+package fileproc_test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/config"
+	"github.com/ivuorinen/gibidify/fileproc"
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// TestBudgetsApply tests that only budgets over their limit apply their fallback, that files
+// count toward the longest matching glob, and that the files of a budget share it.
+func TestBudgetsApply(t *testing.T) {
+	testutil.SetViperKeys(t, nil)
+	if fileproc.NewBudgets("/src", nil) != nil {
+		t.Error("NewBudgets() without budgets is not nil")
+	}
+
+	root := filepath.FromSlash("/src")
+	path := func(rel string) string { return filepath.Join(root, filepath.FromSlash(rel)) }
+	budgets := fileproc.NewBudgets(root, map[string]config.Budget{
+		"docs/**":     {MaxBytes: 100},
+		"docs/api/**": {MaxBytes: 1000},
+		"assets/**":   {MaxBytes: 10, Fallback: shared.BudgetFallbackRollup},
+		"src/**":      {MaxBytes: 1000},
+	})
+	sizes := map[string]int64{
+		path("docs/long.md"):    80,
+		path("docs/short.md"):   40,
+		path("docs/api/ref.md"): 500,
+		path("assets/logo.svg"): 20,
+		path("src/main.go"):     30,
+	}
+	files := []string{
+		path("docs/long.md"), path("docs/short.md"), path("docs/api/ref.md"),
+		path("assets/logo.svg"), path("src/main.go"),
+	}
+
+	kept, entries := budgets.Apply(files, sizes, fileproc.NewFileTypeRegistry())
+	if len(kept) != 4 || len(entries) != 1 || entries[0].Path != "assets/**" ||
+		entries[0].Metadata[shared.MetadataKeyRole] != shared.MetadataRoleRollup {
+		t.Fatalf("Apply() = %v, %+v, want assets/** rolled up", kept, entries)
+	}
+
+	wantApplies := map[string]bool{
+		"docs/long.md": true, "docs/short.md": true, "docs/api/ref.md": false, "src/main.go": false,
+	}
+	for rel, want := range wantApplies {
+		if got := budgets.Applies(path(rel)); got != want {
+			t.Errorf("Applies(%s) = %v, want %v", rel, got, want)
+		}
+	}
+
+	// The 40-byte file fits its share, leaving 60 bytes to the 80-byte file
+	long := strings.Repeat("0123456789\n", 7) + "012"
+	text, note := budgets.Limit(path("docs/long.md"), "docs/long.md", long, fileproc.NewFileTypeRegistry())
+	if text != strings.Repeat("0123456789\n", 5) || note != "truncated to 55 of 80 bytes (budget docs/**)" {
+		t.Errorf("Limit(long.md) = %q, %q", text, note)
+	}
+	short := strings.Repeat("x", 40)
+	if text, note := budgets.Limit(path("docs/short.md"), "docs/short.md", short, nil); text != short || note != "" {
+		t.Errorf("Limit(short.md) = %q, %q, want it unchanged", text, note)
+	}
+}
+
+// TestBudgetsOutline tests that the outline fallback keeps the numbered declarations of a file,
+// truncating the outline when it still exceeds the share of the file.
+func TestBudgetsOutline(t *testing.T) {
+	testutil.SetViperKeys(t, nil)
+	content := "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"hi\")\n}\n"
+
+	tests := []struct {
+		name     string
+		budget   config.Budget
+		wantText string
+		wantNote string
+	}{
+		{
+			name:     "outline",
+			budget:   config.Budget{MaxBytes: 50, Fallback: shared.BudgetFallbackOutline},
+			wantText: "1: package main\n3: import \"fmt\"\n5: func main() {\n",
+			wantNote: "outline of 8 lines (budget **)",
+		},
+		{
+			name:     "outline truncated",
+			budget:   config.Budget{MaxBytes: 40, Fallback: "Outline"},
+			wantText: "1: package main\n3: import \"fmt\"\n",
+			wantNote: "outline truncated to 32 bytes (budget **)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			file := filepath.Join(root, "main.go")
+			budgets := fileproc.NewBudgets(root, map[string]config.Budget{"**": tt.budget})
+			registry := fileproc.NewFileTypeRegistry()
+			budgets.Apply([]string{file}, map[string]int64{file: int64(len(content))}, registry)
+
+			text, note := budgets.Limit(file, "main.go", content, registry)
+			if text != tt.wantText || note != tt.wantNote {
+				t.Errorf("Limit() = %q, %q, want %q, %q", text, note, tt.wantText, tt.wantNote)
+			}
+		})
+	}
+}
+
+// TestFileProcessorBudgets tests that a file over its budget is written truncated, with the
+// truncation recorded as omitted content.
+func TestFileProcessorBudgets(t *testing.T) {
+	testutil.SetViperKeys(t, nil)
+	root := t.TempDir()
+	content := strings.Repeat("line\n", 10)
+	file := testutil.CreateTestFile(t, root, "notes.txt", []byte(content))
+
+	budgets := fileproc.NewBudgets(root, map[string]config.Budget{"*.txt": {MaxBytes: 20}})
+	budgets.Apply([]string{file}, map[string]int64{file: int64(len(content))}, fileproc.NewFileTypeRegistry())
+	processor := fileproc.NewFileProcessor(root)
+	processor.SetBudgets(budgets)
+
+	outCh := make(chan fileproc.WriteRequest, 1)
+	processor.Process(file, outCh)
+	close(outCh)
+	req := <-outCh
+	if !strings.Contains(req.Content, strings.Repeat("line\n", 4)) ||
+		strings.Contains(req.Content, strings.Repeat("line\n", 5)) {
+		t.Errorf("content = %q, want 4 lines", req.Content)
+	}
+	if got := req.Metadata[shared.MetadataKeyOmitted]; got != "truncated to 20 of 50 bytes (budget *.txt)" {
+		t.Errorf("omitted = %q", got)
+	}
+}
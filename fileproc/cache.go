@@ -49,12 +49,12 @@ func (r *FileTypeRegistry) getFileTypeResult(filename string) FileTypeResult {
 	}
 	r.cacheMutex.RUnlock()
 
-	// Cache miss
-	r.updateStats(func() {
-		r.stats.CacheMisses++
-	})
+	// Cache miss: compute and cache the result under the write lock, so a concurrent
+	// registry modification cannot leave a stale result behind
+	r.cacheMutex.Lock()
+	defer r.cacheMutex.Unlock()
+	r.stats.CacheMisses++
 
-	// Compute result
 	result := FileTypeResult{
 		Extension: ext,
 		IsImage:   r.imageExts[ext],
@@ -67,14 +67,11 @@ func (r *FileTypeRegistry) getFileTypeResult(filename string) FileTypeResult {
 		result.IsBinary = true
 	}
 
-	// Cache the result (write lock)
-	r.cacheMutex.Lock()
 	if len(r.resultCache) >= r.maxCacheSize {
 		r.clearResultCache()
 		r.stats.CacheEvictions++
 	}
 	r.resultCache[ext] = result
-	r.cacheMutex.Unlock()
 
 	return result
 }
@@ -112,11 +109,8 @@ func (r *FileTypeRegistry) clearCache(cache *map[string]string, maxSize int) {
 	*cache = newCache
 }
 
-// invalidateCache clears both caches when the registry is modified.
+// invalidateCache clears both caches when the registry is modified. The caller must hold cacheMutex.
 func (r *FileTypeRegistry) invalidateCache() {
-	r.cacheMutex.Lock()
-	defer r.cacheMutex.Unlock()
-
 	r.extCache = make(map[string]string, r.maxCacheSize)
 	r.resultCache = make(map[string]FileTypeResult, r.maxCacheSize)
 	r.stats.CacheEvictions++
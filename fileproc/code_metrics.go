@@ -0,0 +1,151 @@
+// Package fileproc handles file processing, collection, and output formatting.
+package fileproc
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// commentSyntax is how a language marks comments, and whether its complexity is estimated.
+type commentSyntax struct {
+	line       []string
+	blockStart string
+	blockEnd   string
+	branches   bool
+}
+
+var (
+	cComments    = commentSyntax{line: []string{"//"}, blockStart: "/*", blockEnd: "*/", branches: true}
+	hashComments = commentSyntax{line: []string{"#"}, branches: true}
+	xmlComments  = commentSyntax{blockStart: "<!--", blockEnd: "-->"}
+	cssComments  = commentSyntax{blockStart: "/*", blockEnd: "*/"}
+)
+
+// commentSyntaxes maps the languages whose comment lines are counted to their comment syntax.
+// Complexity is only estimated for programming languages, not for markup and style sheets.
+var commentSyntaxes = map[string]commentSyntax{
+	"go": cComments, "c": cComments, "cpp": cComments, "objc": cComments, "objcpp": cComments,
+	"csharp": cComments, "java": cComments, "javascript": cComments, "typescript": cComments,
+	"kotlin": cComments, "scala": cComments, "swift": cComments, "rust": cComments, "dart": cComments,
+	"python": hashComments, "ruby": hashComments, "bash": hashComments, "zsh": hashComments,
+	"fish": hashComments, "perl": hashComments, "r": hashComments, "elixir": hashComments, "nim": hashComments,
+	"html": xmlComments, "xml": xmlComments, "vue": xmlComments,
+	"css": cssComments, "scss": cComments.markup(), "less": cComments.markup(), "sass": cComments.markup(),
+
+	"php":        {line: []string{"//", "#"}, blockStart: "/*", blockEnd: "*/", branches: true},
+	"powershell": {line: []string{"#"}, blockStart: "<#", blockEnd: "#>", branches: true},
+	"lua":        {line: []string{"--"}, blockStart: "--[[", blockEnd: "]]", branches: true},
+	"haskell":    {line: []string{"--"}, blockStart: "{-", blockEnd: "-}", branches: true},
+	"elm":        {line: []string{"--"}, blockStart: "{-", blockEnd: "-}", branches: true},
+	"sql":        {line: []string{"--"}, blockStart: "/*", blockEnd: "*/", branches: true},
+	"erlang":     {line: []string{"%"}, branches: true},
+	"clojure":    {line: []string{";"}, branches: true},
+	"ocaml":      {blockStart: "(*", blockEnd: "*)", branches: true},
+	"fsharp":     {line: []string{"//"}, blockStart: "(*", blockEnd: "*)", branches: true},
+}
+
+// branchKeywords are the words opening a decision point in the languages of commentSyntaxes.
+var branchKeywords = map[string]bool{
+	"if": true, "elif": true, "elsif": true, "unless": true, "for": true, "foreach": true, "while": true,
+	"until": true, "case": true, "when": true, "catch": true, "except": true, "rescue": true, "guard": true,
+}
+
+// markup returns the syntax without complexity estimation.
+func (s commentSyntax) markup() commentSyntax {
+	s.branches = false
+
+	return s
+}
+
+// lineComment reports whether the trimmed line is a line comment.
+func (s commentSyntax) lineComment(line string) bool {
+	for _, prefix := range s.line {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CodeMetrics counts the lines of a file by kind and estimates its cyclomatic complexity.
+type CodeMetrics struct {
+	// Code counts the lines holding code, including code followed by a comment. In languages
+	// without a known comment syntax every non-blank line counts as code.
+	Code int
+	// Comments counts the lines holding only comments.
+	Comments int
+	// Complexity is one plus the decision points (branching keywords, && and ||) in the code
+	// lines, or 0 for languages that are not programming languages.
+	Complexity int
+}
+
+// MeasureCode returns the code metrics of content in language. The counts are approximate:
+// strings are not parsed, so comment markers and keywords inside them count as well.
+func MeasureCode(content, language string) CodeMetrics {
+	syntax := commentSyntaxes[language]
+	var metrics CodeMetrics
+	inBlock := false
+	for line := range strings.Lines(content) {
+		text := strings.TrimSpace(line)
+		switch {
+		case inBlock:
+			metrics.Comments++
+			inBlock = !strings.Contains(text, syntax.blockEnd)
+		case text == "":
+			// Blank lines are neither code nor comments
+		case syntax.blockStart != "" && strings.HasPrefix(text, syntax.blockStart):
+			metrics.Comments++
+			inBlock = !strings.Contains(text[len(syntax.blockStart):], syntax.blockEnd)
+		case syntax.lineComment(text):
+			metrics.Comments++
+		default:
+			metrics.Code++
+			if syntax.branches {
+				metrics.Complexity += decisionPoints(text)
+			}
+		}
+	}
+	if syntax.branches {
+		metrics.Complexity++
+	}
+
+	return metrics
+}
+
+// decisionPoints counts the branching keywords and short-circuit operators in a line of code.
+func decisionPoints(line string) int {
+	points := strings.Count(line, "&&") + strings.Count(line, "||")
+	for word := range strings.FieldsFuncSeq(line, func(r rune) bool {
+		return r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if branchKeywords[word] {
+			points++
+		}
+	}
+
+	return points
+}
+
+// codeNotes adds the code metrics of the content of the file at relPath to notes when
+// codeMetrics.enabled is set. Comment lines and complexity are only added for languages with a
+// known comment syntax and for programming languages.
+func (t *textTransform) codeNotes(notes map[string]string, relPath, content string) {
+	if !t.codeMetrics {
+		return
+	}
+
+	language := t.registry.Language(relPath)
+	metrics := MeasureCode(content, language)
+	notes[shared.MetadataKeyCodeLines] = strconv.Itoa(metrics.Code)
+	syntax, known := commentSyntaxes[language]
+	if known {
+		notes[shared.MetadataKeyCommentLines] = strconv.Itoa(metrics.Comments)
+	}
+	if syntax.branches {
+		notes[shared.MetadataKeyComplexity] = strconv.Itoa(metrics.Complexity)
+	}
+}
@@ -0,0 +1,143 @@
+package fileproc_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/fileproc"
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// goMetricsSample has 13 code lines, 3 comment lines and 5 decision points.
+const goMetricsSample = `package main
+
+// Add adds.
+/* block
+   comment */
+func Add(a, b int) int {
+	if a > 0 && b > 0 { // positive
+		return a + b
+	}
+	for i := 0; i < b; i++ {
+	}
+	switch a {
+	case 1:
+	case 2:
+	}
+	return 0
+}
+`
+
+// TestMeasureCode tests line classification and complexity estimation across comment syntaxes.
+func TestMeasureCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		language string
+		want     fileproc.CodeMetrics
+	}{
+		{name: "go", content: goMetricsSample, language: "go",
+			want: fileproc.CodeMetrics{Code: 13, Comments: 3, Complexity: 6}},
+		{
+			name:     "python",
+			content:  "# comment\ndef f(x):\n    if x and y:\n        return 1\n    elif x:\n        return 2\n",
+			language: "python",
+			want:     fileproc.CodeMetrics{Code: 5, Comments: 1, Complexity: 3},
+		},
+		{
+			name:     "lua block comment",
+			content:  "--[[ long\ncomment ]]\n-- line\nif x then end\n",
+			language: "lua",
+			want:     fileproc.CodeMetrics{Code: 1, Comments: 3, Complexity: 2},
+		},
+		{name: "style sheet", content: "/* reset */\na { color: red }\n", language: "css",
+			want: fileproc.CodeMetrics{Code: 1, Comments: 1}},
+		{name: "prose", content: "# Title\n\nif only.\n", language: "markdown", want: fileproc.CodeMetrics{Code: 2}},
+		{name: "empty", language: "go", want: fileproc.CodeMetrics{Complexity: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fileproc.MeasureCode(tt.content, tt.language); got != tt.want {
+				t.Errorf("MeasureCode() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestFileProcessorCodeMetrics tests that codeMetrics.enabled adds line counts and complexity
+// to the file metadata, and leaves out what does not apply to the language.
+func TestFileProcessorCodeMetrics(t *testing.T) {
+	dir := t.TempDir()
+	goPath := testutil.CreateTestFile(t, dir, "main.go", []byte(goMetricsSample))
+	mdPath := testutil.CreateTestFile(t, dir, "README.md", []byte("# Title\n\ntext\n"))
+
+	tests := []struct {
+		name    string
+		path    string
+		enabled bool
+		want    map[string]string
+	}{
+		{name: "code", path: goPath, enabled: true, want: map[string]string{
+			shared.MetadataKeyCodeLines: "13", shared.MetadataKeyCommentLines: "3", shared.MetadataKeyComplexity: "6",
+		}},
+		{name: "prose", path: mdPath, enabled: true, want: map[string]string{shared.MetadataKeyCodeLines: "2"}},
+		{name: "disabled", path: goPath, want: map[string]string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testutil.SetViperKeys(t, map[string]any{shared.ConfigKeyCodeMetricsEnabled: tt.enabled})
+			outCh := make(chan fileproc.WriteRequest, 1)
+			testutil.MustSucceed(t,
+				fileproc.NewFileProcessor(dir).ProcessWithContext(context.Background(), tt.path, outCh), "processing")
+
+			req := <-outCh
+			for _, key := range []string{
+				shared.MetadataKeyCodeLines, shared.MetadataKeyCommentLines, shared.MetadataKeyComplexity,
+			} {
+				if got, want := req.Metadata[key], tt.want[key]; got != want {
+					t.Errorf("metadata %s = %q, want %q", key, got, want)
+				}
+			}
+		})
+	}
+}
+
+// TestRunSummaryCode tests that the run summary totals the code metrics of the file sections.
+func TestRunSummaryCode(t *testing.T) {
+	outFile, path := testutil.CreateTempOutputFile(t, "code_*.json")
+	entries := []fileproc.WriteRequest{
+		{Path: "b.go", Content: "package b", Metadata: map[string]string{
+			shared.MetadataKeyCodeLines: "10", shared.MetadataKeyCommentLines: "2", shared.MetadataKeyComplexity: "7",
+		}},
+		{Path: "a.go", Content: "package a", Metadata: map[string]string{
+			shared.MetadataKeyCodeLines: "30", shared.MetadataKeyCommentLines: "5", shared.MetadataKeyComplexity: "7",
+		}},
+		{Path: "README.md", Content: "# Docs", Metadata: map[string]string{shared.MetadataKeyCodeLines: "4"}},
+	}
+	writeCh := make(chan fileproc.WriteRequest, len(entries))
+	for _, entry := range entries {
+		writeCh <- entry
+	}
+	close(writeCh)
+
+	done := make(chan struct{})
+	opts := fileproc.WriterOptions{Summary: func(*fileproc.RunSummary) {}}
+	fileproc.StartWriterWithOptions(outFile, writeCh, done, shared.FormatJSON, "", "", opts)
+	<-done
+	testutil.CloseFile(t, outFile)
+
+	data, err := os.ReadFile(path) // #nosec G304 -- path is in t.TempDir()
+	testutil.MustSucceed(t, err, "reading output")
+	var bundle fileproc.OutputData
+	testutil.MustSucceed(t, json.Unmarshal(data, &bundle), "parsing output")
+
+	want := fileproc.CodeSummary{CodeLines: 44, CommentLines: 7, Complexity: 14, MostComplex: "a.go", MaxComplexity: 7}
+	if bundle.Summary == nil || bundle.Summary.Code == nil || *bundle.Summary.Code != want {
+		t.Errorf("summary = %+v, want code %+v", bundle.Summary, want)
+	}
+}
@@ -8,3 +8,10 @@ func CollectFiles(root string) ([]string, error) {
 
 	return w.Walk(root)
 }
+
+// CollectFilesWithRegistry works like CollectFiles, classifying binary and image files with registry.
+func CollectFilesWithRegistry(root string, registry *FileTypeRegistry) ([]string, error) {
+	w := NewProdWalkerWithRegistry(registry)
+
+	return w.Walk(root)
+}
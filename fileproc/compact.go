@@ -0,0 +1,119 @@
+// Package fileproc handles file processing, collection, and output formatting.
+package fileproc
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ivuorinen/gibidify/config"
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// compactRule describes how --compact shrinks file content; the zero rule changes nothing.
+type compactRule struct {
+	collapse bool
+	trim     bool
+	maxLine  int
+}
+
+// compactRuleFor returns the output.compact rule of settings when enabled is set, or the zero
+// rule.
+func compactRuleFor(settings *config.Settings, enabled bool) compactRule {
+	if !enabled {
+		return compactRule{}
+	}
+
+	return compactRule{
+		collapse: settings.OutputCompactCollapseBlankLines(),
+		trim:     settings.OutputCompactTrimTrailing(),
+		maxLine:  settings.OutputCompactMaxLineLength(),
+	}
+}
+
+// newCompactor creates a compactor applying the rule to one file, or returns nil when the rule
+// changes nothing.
+func (r compactRule) newCompactor() *compactor {
+	if !r.collapse && !r.trim && r.maxLine <= 0 {
+		return nil
+	}
+
+	return &compactor{rule: r}
+}
+
+// compactor applies a compactRule to the lines of one file, carrying whether the previous line
+// was blank from one line to the next.
+type compactor struct {
+	rule compactRule
+	// blank reports whether the previous line kept was blank.
+	blank bool
+	// truncated counts the lines cut at maxLine.
+	truncated int
+}
+
+// content compacts every line of content.
+func (c *compactor) content(content string) string {
+	lines := strings.Split(content, "\n")
+	kept := lines[:0]
+	for i, line := range lines {
+		compacted, keep := c.line([]byte(line))
+		if keep || i == len(lines)-1 {
+			kept = append(kept, string(compacted))
+		}
+	}
+
+	return strings.Join(kept, "\n")
+}
+
+// line compacts one line without its "\n" terminator and reports whether to keep it: a blank
+// line following another is dropped when blank lines are collapsed. A trailing "\r" is kept.
+func (c *compactor) line(line []byte) ([]byte, bool) {
+	body, cr := bytes.CutSuffix(line, []byte{'\r'})
+	if c.rule.trim {
+		body = bytes.TrimRight(body, " \t")
+	}
+	blank := len(bytes.TrimSpace(body)) == 0
+	if blank && c.blank && c.rule.collapse {
+		return nil, false
+	}
+	c.blank = blank
+
+	body = c.truncate(body)
+	if cr {
+		body = append(body, '\r')
+	}
+
+	return body, true
+}
+
+// truncate cuts a line longer than maxLine bytes at a rune boundary, marking the cut with
+// shared.CompactEllipsis.
+func (c *compactor) truncate(line []byte) []byte {
+	if c.rule.maxLine <= 0 || len(line) <= c.rule.maxLine {
+		return line
+	}
+
+	cut := c.rule.maxLine
+	for cut > 0 && !utf8.RuneStart(line[cut]) {
+		cut--
+	}
+	c.truncated++
+
+	return append(line[:cut:cut], shared.CompactEllipsis...)
+}
+
+// compactContent compacts the content of one file, noting how many lines were truncated.
+func (t *textTransform) compactContent(content string, notes map[string]string) string {
+	compactor := t.compact.newCompactor()
+	if compactor == nil {
+		return content
+	}
+
+	content = compactor.content(content)
+	if compactor.truncated > 0 {
+		notes[shared.MetadataKeyLinesTruncated] = strconv.Itoa(compactor.truncated)
+	}
+
+	return content
+}
@@ -0,0 +1,115 @@
+package fileproc_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/fileproc"
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// TestFileProcessorCompact tests the output.compact rules: blank line runs collapse, trailing
+// whitespace goes and long lines are cut at a rune boundary, each rule toggled on its own.
+func TestFileProcessorCompact(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		config    map[string]any
+		compact   bool
+		want      string
+		truncated string
+	}{
+		{
+			name:    "collapse and trim",
+			content: "a  \n\n\n\t\nb\t\n\n",
+			compact: true,
+			want:    "a\n\nb\n\n",
+		},
+		{
+			name:    "collapse only",
+			content: "a  \n\n  \n\nb\n",
+			config:  map[string]any{shared.ConfigKeyOutputCompactTrimTrailing: false},
+			compact: true,
+			want:    "a  \n\nb\n",
+		},
+		{
+			name:    "trim only",
+			content: "a  \n\n\nb\n",
+			config:  map[string]any{shared.ConfigKeyOutputCompactCollapseBlankLines: false},
+			compact: true,
+			want:    "a\n\n\nb\n",
+		},
+		{
+			name:      "truncate long lines",
+			content:   "short\nnaïve café au lait\n",
+			config:    map[string]any{shared.ConfigKeyOutputCompactMaxLineLength: 11},
+			compact:   true,
+			want:      "short\nnaïve caf" + shared.CompactEllipsis + "\n",
+			truncated: "1",
+		},
+		{
+			name:    "crlf line breaks kept",
+			content: "a \r\n\r\n\r\nb\r\n",
+			compact: true,
+			want:    "a\r\n\r\nb\r\n",
+		},
+		{
+			name:    "enabled in the config",
+			content: "a\n\n\nb\n",
+			config:  map[string]any{shared.ConfigKeyOutputCompactEnabled: true},
+			want:    "a\n\nb\n",
+		},
+		{name: "disabled", content: "a  \n\n\nb\n", want: "a  \n\n\nb\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testutil.ResetViperConfig(t, "")
+			if tt.config != nil {
+				testutil.SetViperKeys(t, tt.config)
+			}
+			dir := t.TempDir()
+			filePath := testutil.CreateTestFile(t, dir, "notes.txt", []byte(tt.content))
+
+			processor := fileproc.NewFileProcessor(dir)
+			processor.SetCompact(tt.compact)
+			outCh := make(chan fileproc.WriteRequest, 1)
+			testutil.MustSucceed(t, processor.ProcessWithContext(context.Background(), filePath, outCh), "processing")
+
+			req := <-outCh
+			if !strings.HasSuffix(req.Content, "\nnotes.txt\n"+tt.want+"\n") {
+				t.Errorf("content = %q, want it to end with %q", req.Content, tt.want)
+			}
+			if got := req.Metadata[shared.MetadataKeyLinesTruncated]; got != tt.truncated {
+				t.Errorf("%s = %q, want %q", shared.MetadataKeyLinesTruncated, got, tt.truncated)
+			}
+		})
+	}
+}
+
+// TestFileProcessorCompactStreamed tests that streamed files are compacted line by line.
+func TestFileProcessorCompactStreamed(t *testing.T) {
+	testutil.ResetViperConfig(t, "")
+	block := "x := 1   \n\n\n\n"
+	dir := t.TempDir()
+	filePath := testutil.CreateTestFile(t, dir, "large.go",
+		[]byte(strings.Repeat(block, shared.FileProcessingStreamThreshold/len(block)+1)))
+
+	processor := fileproc.NewFileProcessor(dir)
+	processor.SetCompact(true)
+	outCh := make(chan fileproc.WriteRequest, 1)
+	testutil.MustSucceed(t, processor.ProcessWithContext(context.Background(), filePath, outCh), "processing")
+
+	req := <-outCh
+	content, err := io.ReadAll(req.Reader)
+	testutil.MustSucceed(t, err, "reading stream")
+	if strings.Contains(string(content), "   \n") || strings.Contains(string(content), "\n\n\n") {
+		t.Errorf("streamed content not compacted: %q", content[:min(len(content), 200)])
+	}
+	if !strings.Contains(string(content), "x := 1\n\nx := 1\n") {
+		t.Errorf("compacted lines missing from %q", content[:min(len(content), 200)])
+	}
+}
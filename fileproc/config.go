@@ -31,14 +31,26 @@ func (r *FileTypeRegistry) addExtensions(extensions []string, adder func(string)
 	}
 }
 
-// ConfigureFromSettings applies configuration settings to the registry.
-// This function is called from main.go after config is loaded to avoid circular imports.
+// Configure applies the fileTypes configuration settings to the registry: custom
+// extensions are added first, then disabled ones removed.
+func (r *FileTypeRegistry) Configure(
+	customImages, customBinary []string,
+	customLanguages map[string]string,
+	disabledImages, disabledBinary, disabledLanguages []string,
+) {
+	r.ApplyCustomExtensions(customImages, customBinary, customLanguages)
+	r.DisableExtensions(disabledImages, disabledBinary, disabledLanguages)
+}
+
+// ConfigureFromSettings applies configuration settings to the default registry.
+//
+// Deprecated: use FileTypeRegistry.Configure on a registry from NewFileTypeRegistry.
 func ConfigureFromSettings(
 	customImages, customBinary []string,
 	customLanguages map[string]string,
 	disabledImages, disabledBinary, disabledLanguages []string,
 ) {
-	registry := DefaultRegistry()
-	registry.ApplyCustomExtensions(customImages, customBinary, customLanguages)
-	registry.DisableExtensions(disabledImages, disabledBinary, disabledLanguages)
+	getRegistry().Configure(
+		customImages, customBinary, customLanguages, disabledImages, disabledBinary, disabledLanguages,
+	)
 }
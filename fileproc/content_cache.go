@@ -0,0 +1,253 @@
+// Package fileproc handles file processing, collection, and output formatting.
+package fileproc
+
+import (
+	"encoding/json"
+	"maps"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// contentCacheVersion is bumped whenever the on-disk cache layout or the content transforms change.
+const contentCacheVersion = 2
+
+// contentCacheEntry is the transformed content of one file, with what transforming it reported,
+// so the file can be bundled again without reading it while its modification time and size stay
+// the same.
+type contentCacheEntry struct {
+	ModTime     int64             `json:"modTime"`
+	Size        int64             `json:"size"`
+	Content     string            `json:"content"`
+	Notes       map[string]string `json:"notes,omitempty"`
+	DocLanguage string            `json:"docLanguage,omitempty"`
+	Sanitized   bool              `json:"sanitized,omitempty"`
+	Findings    map[string]int    `json:"findings,omitempty"`
+	Redactions  map[string]int    `json:"redactions,omitempty"`
+	Warnings    []cachedWarning   `json:"warnings,omitempty"`
+}
+
+// cachedWarning is a warning reported while transforming a cached file, replayed on a hit.
+type cachedWarning struct {
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
+// ContentCache stores the transformed content of in-memory files keyed by their path relative
+// to the source, modification time and size. Entries hold the result of the settings the cache
+// was loaded with; a cache saved with other settings is discarded when loaded.
+type ContentCache struct {
+	path     string
+	root     string
+	settings string
+	entries  map[string]contentCacheEntry
+	touched  map[string]bool
+	dirty    bool
+	mu       sync.RWMutex
+	hits     atomic.Int64
+	misses   atomic.Int64
+}
+
+type contentCacheFile struct {
+	Version  int                          `json:"version"`
+	Settings string                       `json:"settings"`
+	Entries  map[string]contentCacheEntry `json:"entries"`
+}
+
+// NewContentCache creates an in-memory cache for the files under root, holding content
+// transformed with the settings fingerprint. An empty path disables persistence.
+func NewContentCache(path, root, settings string) *ContentCache {
+	return &ContentCache{
+		path:     path,
+		root:     root,
+		settings: settings,
+		entries:  make(map[string]contentCacheEntry),
+		touched:  make(map[string]bool),
+	}
+}
+
+// DefaultContentCachePath returns the cache file location for the source directory root,
+// keyed by the digest of root computed with the named hash algorithm.
+func DefaultContentCachePath(root, algorithm string) (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", shared.WrapError(err, shared.ErrorTypeFileSystem, shared.CodeFSPathResolution, "locating cache dir")
+	}
+
+	name := "content-" + shared.ShortChecksum(algorithm, []byte(root), 8) + ".json"
+
+	return filepath.Join(base, shared.AppName, name), nil
+}
+
+// LoadContentCache reads the cache at path. A missing or unreadable cache, or one saved with
+// other settings, yields an empty one.
+func LoadContentCache(path, root, settings string) *ContentCache {
+	cache := NewContentCache(path, root, settings)
+
+	data, err := os.ReadFile(path) // #nosec G304 -- path is derived from the user cache dir
+	if err != nil {
+		return cache
+	}
+
+	var file contentCacheFile
+	if err := json.Unmarshal(data, &file); err != nil || file.Version != contentCacheVersion {
+		shared.GetLogger().Debugf("Ignoring incompatible content cache %s", path)
+
+		return cache
+	}
+	if file.Settings != settings {
+		shared.GetLogger().Debugf("Ignoring content cache %s saved with other settings", path)
+
+		return cache
+	}
+	if file.Entries != nil {
+		cache.entries = file.Entries
+	}
+
+	return cache
+}
+
+// get returns the entry of relPath if the file still has the cached modification time and
+// size, counting the lookup as a hit or a miss.
+func (c *ContentCache) get(relPath string, info os.FileInfo) (contentCacheEntry, bool) {
+	c.mu.Lock()
+	entry, ok := c.entries[relPath]
+	c.touched[relPath] = true
+	c.mu.Unlock()
+
+	if !ok || entry.ModTime != info.ModTime().UnixNano() || entry.Size != info.Size() {
+		c.misses.Add(1)
+
+		return contentCacheEntry{}, false
+	}
+	c.hits.Add(1)
+
+	return entry, true
+}
+
+// put stores entry for relPath, recording the modification time and size of info.
+func (c *ContentCache) put(relPath string, info os.FileInfo, entry *contentCacheEntry) {
+	if c == nil || entry == nil {
+		return
+	}
+	entry.ModTime = info.ModTime().UnixNano()
+	entry.Size = info.Size()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[relPath] = *entry
+	c.touched[relPath] = true
+	c.dirty = true
+}
+
+// Stats returns the number of files emitted from the cache and the number looked up but not found.
+func (c *ContentCache) Stats() (hits, misses int64) {
+	if c == nil {
+		return 0, 0
+	}
+
+	return c.hits.Load(), c.misses.Load()
+}
+
+// Save writes the cache to disk if it has a path and was modified. Entries of files this run
+// did not look up are kept unless the file no longer exists.
+func (c *ContentCache) Save() error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.path == "" || !c.dirty {
+		return nil
+	}
+	for relPath := range c.entries {
+		if _, err := os.Stat(filepath.Join(c.root, relPath)); !c.touched[relPath] && err != nil {
+			delete(c.entries, relPath)
+		}
+	}
+
+	file := contentCacheFile{Version: contentCacheVersion, Settings: c.settings, Entries: c.entries}
+	data, err := json.Marshal(file)
+	if err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOEncoding, "encoding content cache")
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o750); err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOFileCreate, "creating cache dir").
+			WithFilePath(c.path)
+	}
+
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOFileWrite, "writing content cache").
+			WithFilePath(c.path)
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOFileWrite, "replacing content cache").
+			WithFilePath(c.path)
+	}
+	c.dirty = false
+
+	return nil
+}
+
+// capture makes the hooks of t also record what they report in entry, and returns a function
+// restoring them. It does nothing when entry is nil.
+func (t *textTransform) capture(entry *contentCacheEntry) func() {
+	if entry == nil {
+		return func() {}
+	}
+
+	onSanitized, onFindings, onRedacted, onWarning := t.onSanitized, t.onFindings, t.onRedacted, t.onWarning
+	if onSanitized != nil {
+		t.onSanitized = func() {
+			entry.Sanitized = true
+			onSanitized()
+		}
+	}
+	if onFindings != nil {
+		t.onFindings = func(findings map[string]int) {
+			entry.Findings = maps.Clone(findings)
+			onFindings(findings)
+		}
+	}
+	if onRedacted != nil {
+		t.onRedacted = func(counts map[string]int) {
+			entry.Redactions = maps.Clone(counts)
+			onRedacted(counts)
+		}
+	}
+	if onWarning != nil {
+		t.onWarning = func(kind, path, message string) {
+			entry.Warnings = append(entry.Warnings, cachedWarning{Kind: kind, Message: message})
+			onWarning(kind, path, message)
+		}
+	}
+
+	return func() {
+		t.onSanitized, t.onFindings, t.onRedacted, t.onWarning = onSanitized, onFindings, onRedacted, onWarning
+	}
+}
+
+// replay reports what transforming the file at relPath reported when entry was cached to
+// the hooks of t.
+func (t *textTransform) replay(relPath string, entry contentCacheEntry) {
+	if entry.Sanitized && t.onSanitized != nil {
+		t.onSanitized()
+	}
+	if len(entry.Findings) > 0 && t.scanning() {
+		t.onFindings(maps.Clone(entry.Findings))
+	}
+	if len(entry.Redactions) > 0 && t.onRedacted != nil {
+		t.onRedacted(maps.Clone(entry.Redactions))
+	}
+	if t.onWarning != nil {
+		for _, warning := range entry.Warnings {
+			t.onWarning(warning.Kind, relPath, warning.Message)
+		}
+	}
+}
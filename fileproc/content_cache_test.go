@@ -0,0 +1,102 @@
+package fileproc_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ivuorinen/gibidify/fileproc"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// processCached processes file with cache and returns its write request and the number of
+// times the sanitize hook was called.
+func processCached(t *testing.T, cache *fileproc.ContentCache, root, file string) (fileproc.WriteRequest, int) {
+	t.Helper()
+
+	sanitized := 0
+	processor := fileproc.NewFileProcessor(root)
+	processor.SetContentCache(cache)
+	processor.SetSanitizeHook(func() { sanitized++ })
+
+	outCh := make(chan fileproc.WriteRequest, 1)
+	processor.Process(file, outCh)
+	close(outCh)
+
+	return <-outCh, sanitized
+}
+
+// TestContentCache tests that an unchanged file is bundled from the cache with its sanitize
+// report replayed, and that a changed modification time or size is a miss.
+func TestContentCache(t *testing.T) {
+	testutil.SetViperKeys(t, nil)
+	root := t.TempDir()
+	file := testutil.CreateTestFile(t, root, "main.go", []byte("\uFEFFpackage main\n"))
+	modTime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	testutil.MustSucceed(t, os.Chtimes(file, modTime, modTime), "setting modification time")
+	cache := fileproc.NewContentCache("", root, "settings")
+
+	first, sanitized := processCached(t, cache, root, file)
+	if sanitized != 1 || !strings.Contains(first.Content, "package main") {
+		t.Fatalf("first run = %q, %d sanitized, want the content sanitized once", first.Content, sanitized)
+	}
+
+	// Same size and modification time: the cached content is bundled without reading the file
+	testutil.CreateTestFile(t, root, "main.go", []byte("\uFEFFpackage test\n"))
+	testutil.MustSucceed(t, os.Chtimes(file, modTime, modTime), "restoring modification time")
+	second, sanitized := processCached(t, cache, root, file)
+	if second.Content != first.Content || sanitized != 1 {
+		t.Errorf("cached run = %q, %d sanitized, want %q, 1 sanitized", second.Content, sanitized, first.Content)
+	}
+
+	testutil.MustSucceed(t, os.Chtimes(file, time.Now(), time.Now()), "touching file")
+	third, _ := processCached(t, cache, root, file)
+	if !strings.Contains(third.Content, "package test") {
+		t.Errorf("run after touching = %q, want the new content", third.Content)
+	}
+
+	if hits, misses := cache.Stats(); hits != 1 || misses != 2 {
+		t.Errorf("Stats() = %d hits, %d misses, want 1, 2", hits, misses)
+	}
+}
+
+// TestContentCacheSaveLoad tests that a saved cache is loaded with the same settings only, and
+// that entries of deleted files are dropped on save.
+func TestContentCacheSaveLoad(t *testing.T) {
+	testutil.SetViperKeys(t, nil)
+	root := t.TempDir()
+	kept := testutil.CreateTestFile(t, root, "kept.go", []byte("package kept\n"))
+	deleted := testutil.CreateTestFile(t, root, "deleted.go", []byte("package deleted\n"))
+	path := filepath.Join(t.TempDir(), "cache", "content.json")
+
+	cache := fileproc.NewContentCache(path, root, "settings")
+	processCached(t, cache, root, kept)
+	processCached(t, cache, root, deleted)
+	testutil.MustSucceed(t, cache.Save(), "Save")
+	testutil.MustSucceed(t, os.Remove(deleted), "removing file")
+
+	// A run that does not look the deleted file up drops it when the cache is saved again
+	loaded := fileproc.LoadContentCache(path, root, "settings")
+	testutil.MustSucceed(t, os.WriteFile(kept, []byte("package renamed\n"), 0o600), "changing file")
+	processCached(t, loaded, root, kept)
+	testutil.MustSucceed(t, loaded.Save(), "Save")
+
+	data, err := os.ReadFile(path)
+	testutil.MustSucceed(t, err, "reading cache")
+	if !strings.Contains(string(data), "package renamed") || strings.Contains(string(data), "deleted.go") {
+		t.Errorf("saved cache = %s, want only the changed kept.go", data)
+	}
+
+	for _, tt := range []struct {
+		settings string
+		hits     int64
+	}{{settings: "settings", hits: 1}, {settings: "other", hits: 0}} {
+		cache := fileproc.LoadContentCache(path, root, tt.settings)
+		processCached(t, cache, root, kept)
+		if hits, _ := cache.Stats(); hits != tt.hits {
+			t.Errorf("LoadContentCache(%s) gave %d hits, want %d", tt.settings, hits, tt.hits)
+		}
+	}
+}
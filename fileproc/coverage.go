@@ -0,0 +1,198 @@
+// Package fileproc handles file processing, collection, and output formatting.
+package fileproc
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// CoverageReport holds the test coverage of every file in a Go cover profile or an LCOV report,
+// keyed by the path the report names the file by.
+type CoverageReport struct {
+	files  map[string]*coverageCounts
+	byName map[string][]string
+}
+
+// coverageCounts holds the statement blocks (Go) or lines (LCOV) of a file, keyed so that
+// merged reports listing them again are counted once.
+type coverageCounts struct {
+	units map[string]coverageUnit
+}
+
+// coverageUnit is a block of statements or a line, weighted by its statement count.
+type coverageUnit struct {
+	weight int
+	hit    bool
+}
+
+// percent returns the covered share of the file, 100 for a file without statements.
+func (c *coverageCounts) percent() float64 {
+	covered, total := 0, 0
+	for _, unit := range c.units {
+		total += unit.weight
+		if unit.hit {
+			covered += unit.weight
+		}
+	}
+	if total == 0 {
+		return 100
+	}
+
+	return 100 * float64(covered) / float64(total)
+}
+
+// coverageParser accumulates the files of a report line by line.
+type coverageParser struct {
+	report  *CoverageReport
+	current string
+}
+
+// LoadCoverageReport reads the Go cover profile (go test -coverprofile) or LCOV report
+// (lcov.info) at path.
+func LoadCoverageReport(path string) (*CoverageReport, error) {
+	file, err := os.Open(path) // #nosec G304 - path is the user-supplied --coverage report
+	if err != nil {
+		return nil, shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIORead, "failed to open coverage report").
+			WithFilePath(path)
+	}
+	defer shared.SafeCloseReader(file, path)
+
+	report, err := ParseCoverageReport(file)
+	if err != nil {
+		return nil, shared.WrapError(err, shared.ErrorTypeValidation, shared.CodeValidationFormat,
+			"failed to parse coverage report").WithFilePath(path)
+	}
+
+	return report, nil
+}
+
+// ParseCoverageReport parses a Go cover profile, recognized by its "mode:" first line, or an
+// LCOV report. Malformed lines are skipped; a report without any file is an error.
+func ParseCoverageReport(r io.Reader) (*CoverageReport, error) {
+	p := &coverageParser{
+		report: &CoverageReport{files: make(map[string]*coverageCounts), byName: make(map[string][]string)},
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, shared.FileProcessingStreamChunkSize), shared.BytesPerMB)
+	goProfile, first := false, true
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if first {
+			first = false
+			if goProfile = strings.HasPrefix(line, "mode:"); goProfile {
+				continue
+			}
+		}
+		if goProfile {
+			p.goProfileLine(line)
+		} else {
+			p.lcovLine(line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIORead, "failed to read coverage report")
+	}
+	if len(p.report.files) == 0 {
+		return nil, shared.NewStructuredError(
+			shared.ErrorTypeValidation, shared.CodeValidationFormat, "no coverage data found", "", nil,
+		)
+	}
+
+	return p.report, nil
+}
+
+// goProfileLine records a "file.go:12.3,15.2 3 1" block: its position, statement count and
+// execution count.
+func (p *coverageParser) goProfileLine(line string) {
+	colon := strings.LastIndex(line, ":")
+	fields := strings.Fields(line[colon+1:])
+	if colon <= 0 || len(fields) != 3 {
+		return
+	}
+	stmts, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return
+	}
+	count, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return
+	}
+
+	p.record(line[:colon], fields[0], stmts, count > 0)
+}
+
+// lcovLine records the SF: (source file) and DA: (line, execution count) records of an LCOV report.
+func (p *coverageParser) lcovLine(line string) {
+	switch {
+	case strings.HasPrefix(line, "SF:"):
+		p.current = strings.TrimPrefix(line, "SF:")
+	case line == "end_of_record":
+		p.current = ""
+	case strings.HasPrefix(line, "DA:") && p.current != "":
+		lineNo, count, ok := strings.Cut(strings.TrimPrefix(line, "DA:"), ",")
+		hits, err := strconv.Atoi(strings.SplitN(count, ",", 2)[0])
+		if !ok || err != nil {
+			return
+		}
+		p.record(p.current, lineNo, 1, hits > 0)
+	}
+}
+
+// record adds the unit at position of the file name, weighing weight statements; a unit
+// covered in any listing is covered.
+func (p *coverageParser) record(name, position string, weight int, hit bool) {
+	name = filepath.ToSlash(name)
+	counts, ok := p.report.files[name]
+	if !ok {
+		counts = &coverageCounts{units: make(map[string]coverageUnit)}
+		p.report.files[name] = counts
+		base := path.Base(name)
+		p.report.byName[base] = append(p.report.byName[base], name)
+	}
+	counts.units[position] = coverageUnit{weight: weight, hit: hit || counts.units[position].hit}
+}
+
+// Percent returns the coverage of the file at filePath, shown as relPath, and whether the report
+// covers it. Report paths are matched exactly, as relPath, or as a path ending in relPath, the
+// way a Go profile names files by import path.
+func (r *CoverageReport) Percent(filePath, relPath string) (float64, bool) {
+	if name, ok := matchReportPath(r.files, r.byName, filePath, relPath); ok {
+		return r.files[name].percent(), true
+	}
+
+	return 0, false
+}
+
+// matchReportPath returns the name under which a report keyed by file name lists the file at
+// filePath, shown as relPath: filePath or relPath itself, else the first name, in sorted order,
+// ending in /relPath. byName lists the report names by base name.
+func matchReportPath[V any](files map[string]V, byName map[string][]string, filePath, relPath string) (string, bool) {
+	relPath = filepath.ToSlash(relPath)
+	for _, name := range []string{filepath.ToSlash(filePath), relPath} {
+		if _, ok := files[name]; ok {
+			return name, true
+		}
+	}
+
+	candidates := slices.Clone(byName[path.Base(relPath)])
+	slices.Sort(candidates)
+	for _, name := range candidates {
+		if strings.HasSuffix(name, "/"+relPath) {
+			return name, true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,53 @@
+package fileproc_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/fileproc"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+func TestParseCoverageReport(t *testing.T) {
+	goProfile := "mode: set\n" +
+		"github.com/org/repo/pkg/walker.go:10.2,12.3 3 1\n" +
+		"github.com/org/repo/pkg/walker.go:14.2,16.3 1 0\n" +
+		"github.com/org/repo/pkg/walker.go:14.2,16.3 1 1\n" + // merged profile repeats the block, now covered
+		"github.com/org/repo/pkg/walker.go:18.2,20.3 4 0\n" +
+		"github.com/org/repo/main.go:3.13,5.2 2 0\n" +
+		"malformed line\n"
+	lcov := "TN:\nSF:src/app.ts\nDA:1,4\nDA:2,0\nDA:3,1\nDA:4,0\nend_of_record\n" +
+		"SF:/abs/lib/util.js\nDA:1,1\nend_of_record\n"
+
+	tests := []struct {
+		name     string
+		report   string
+		filePath string
+		relPath  string
+		want     float64
+		wantOK   bool
+	}{
+		{name: "go import path", report: goProfile, relPath: "pkg/walker.go", want: 50, wantOK: true},
+		{name: "go uncovered", report: goProfile, relPath: "main.go", want: 0, wantOK: true},
+		{name: "go missing", report: goProfile, relPath: "pkg/other.go", wantOK: false},
+		{name: "go partial name", report: goProfile, relPath: "kg/walker.go", wantOK: false},
+		{name: "lcov relative", report: lcov, relPath: "src/app.ts", want: 50, wantOK: true},
+		{name: "lcov absolute", report: lcov, filePath: "/abs/lib/util.js", relPath: "lib/util.js", want: 100, wantOK: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := fileproc.ParseCoverageReport(strings.NewReader(tt.report))
+			testutil.MustSucceed(t, err, "parsing coverage report")
+
+			got, ok := report.Percent(tt.filePath, tt.relPath)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("Percent(%s) = %v, %v, want %v, %v", tt.relPath, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+
+	if _, err := fileproc.ParseCoverageReport(strings.NewReader("not a coverage report\n")); err == nil {
+		t.Error("ParseCoverageReport() accepted a report without coverage data")
+	}
+}
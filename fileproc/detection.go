@@ -5,17 +5,24 @@ import "strings"
 
 // Package-level detection functions
 
-// IsImage checks if the file extension indicates an image file.
+// IsImage checks if the file extension indicates an image file, using the default registry.
+//
+// Deprecated: use FileTypeRegistry.IsImage.
 func IsImage(filename string) bool {
 	return getRegistry().IsImage(filename)
 }
 
-// IsBinary checks if the file extension indicates a binary file.
+// IsBinary checks if the file extension indicates a binary file, using the default registry.
+//
+// Deprecated: use FileTypeRegistry.IsBinary.
 func IsBinary(filename string) bool {
 	return getRegistry().IsBinary(filename)
 }
 
-// Language returns the language identifier for the given filename based on its extension.
+// Language returns the language identifier for the given filename based on its extension,
+// using the default registry.
+//
+// Deprecated: use FileTypeRegistry.Language.
 func Language(filename string) string {
 	return getRegistry().Language(filename)
 }
@@ -60,23 +67,32 @@ func (r *FileTypeRegistry) AddBinaryExtension(ext string) {
 
 // AddLanguageMapping adds a new language mapping to the registry.
 func (r *FileTypeRegistry) AddLanguageMapping(ext, language string) {
+	r.cacheMutex.Lock()
+	defer r.cacheMutex.Unlock()
+
 	r.languageMap[strings.ToLower(ext)] = language
 	r.invalidateCache()
 }
 
 // addExtension is a helper to add extensions to a map.
 func (r *FileTypeRegistry) addExtension(ext string, target map[string]bool) {
+	r.cacheMutex.Lock()
+	defer r.cacheMutex.Unlock()
+
 	target[strings.ToLower(ext)] = true
 	r.invalidateCache()
 }
 
-// removeExtension is a helper to remove extensions from a map.
+// removeExtension is a helper to remove extensions from a map. The caller must hold cacheMutex.
 func (r *FileTypeRegistry) removeExtension(ext string, target map[string]bool) {
 	delete(target, strings.ToLower(ext))
 }
 
 // DisableExtensions removes specified extensions from the registry.
 func (r *FileTypeRegistry) DisableExtensions(disabledImages, disabledBinary, disabledLanguages []string) {
+	r.cacheMutex.Lock()
+	defer r.cacheMutex.Unlock()
+
 	// Disable image extensions
 	for _, ext := range disabledImages {
 		if ext != "" {
@@ -0,0 +1,175 @@
+// Package fileproc handles file processing, collection, and output formatting.
+package fileproc
+
+import (
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/ivuorinen/gibidify/config"
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// docLanguageStopWords lists frequent function words of every detectable language, by code.
+// Words several languages share count towards each of them.
+var docLanguageStopWords = map[string][]string{
+	"de": {
+		"der", "die", "und", "das", "ist", "nicht", "mit", "den", "von", "zu", "ein", "eine", "sie",
+		"auf", "für", "dem", "es", "sich", "des", "auch", "werden", "wird", "oder", "bei", "wie",
+	},
+	"en": {
+		"the", "and", "of", "to", "is", "in", "that", "it", "for", "with", "as", "this", "are", "be",
+		"on", "you", "not", "or", "by", "can", "from", "an", "which", "have", "will",
+	},
+	"es": {
+		"el", "la", "los", "las", "y", "que", "de", "es", "en", "un", "una", "por", "con", "para",
+		"no", "se", "del", "al", "lo", "como", "su", "más", "pero", "este", "son",
+	},
+	"fi": {
+		"ja", "on", "ei", "että", "se", "ovat", "tai", "kun", "mutta", "myös", "jos", "niin", "ole",
+		"oli", "kanssa", "tämä", "joka", "sekä", "mukaan", "voi", "kuin", "sen", "vain", "jotka", "tässä",
+	},
+	"fr": {
+		"le", "la", "les", "et", "des", "est", "une", "un", "du", "pour", "dans", "que", "qui", "pas",
+		"sur", "avec", "ce", "il", "sont", "au", "ou", "par", "plus", "cette", "nous",
+	},
+	"nl": {
+		"de", "het", "een", "en", "van", "is", "dat", "niet", "op", "te", "voor", "met", "zijn", "aan",
+		"er", "ook", "als", "bij", "maar", "om", "dit", "wordt", "naar", "kan", "worden",
+	},
+	"sv": {
+		"och", "att", "det", "som", "en", "är", "på", "för", "med", "inte", "av", "den", "till", "har",
+		"om", "kan", "ett", "jag", "men", "eller", "vi", "de", "så", "från", "ska",
+	},
+}
+
+// docLanguagesByWord indexes docLanguageStopWords by word.
+var docLanguagesByWord = indexStopWords(docLanguageStopWords)
+
+// indexStopWords returns the languages of every word in stopWords.
+func indexStopWords(stopWords map[string][]string) map[string][]string {
+	index := make(map[string][]string)
+	for language, words := range stopWords {
+		for _, word := range words {
+			index[word] = append(index[word], language)
+		}
+	}
+
+	return index
+}
+
+// DocLanguageFilter detects the natural language of Markdown, reStructuredText and plain text
+// files, and leaves out those not written in the included languages.
+type DocLanguageFilter struct {
+	enabled bool
+	include []string
+}
+
+// NewDocLanguageFilter creates a filter with the current configuration.
+func NewDocLanguageFilter() *DocLanguageFilter {
+	return newDocLanguageFilter(config.Global())
+}
+
+// newDocLanguageFilter creates a filter with the configuration in settings.
+func newDocLanguageFilter(settings *config.Settings) *DocLanguageFilter {
+	include := settings.DocLanguageInclude()
+
+	return &DocLanguageFilter{
+		enabled: settings.DocLanguageDetect() || len(include) > 0,
+		include: include,
+	}
+}
+
+// Inspect samples the beginning of the file at filePath, a file of language, and returns the
+// natural language it is written in. It returns "" for files that are not prose and for prose
+// too short or too mixed to tell.
+func (f *DocLanguageFilter) Inspect(filePath, language string) (string, error) {
+	if !f.enabled || !isProse(filePath, language) {
+		return "", nil
+	}
+
+	file, err := openLimited(filePath)
+	if err != nil {
+		return "", shared.WrapError(err, shared.ErrorTypeProcessing, shared.CodeProcessingFileRead, "failed to open file").
+			WithFilePath(filePath)
+	}
+	defer shared.SafeCloseReader(file, filePath)
+
+	sample := make([]byte, shared.DocLanguageSampleSize)
+	n, err := io.ReadFull(file, sample)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", shared.WrapError(err, shared.ErrorTypeProcessing, shared.CodeProcessingFileRead, "failed to read file").
+			WithFilePath(filePath)
+	}
+
+	return DetectDocLanguage(string(trimPartialRune(sample[:n]))), nil
+}
+
+// Excludes reports whether prose detected as written in language is left out of the bundle.
+// Prose of undetermined language is kept.
+func (f *DocLanguageFilter) Excludes(language string) bool {
+	return language != "" && len(f.include) > 0 && !slices.Contains(f.include, language)
+}
+
+// isProse reports whether the file at filePath, a file of language, is documentation prose.
+func isProse(filePath, language string) bool {
+	if language == shared.FormatMarkdown || language == "rst" {
+		return true
+	}
+	ext := strings.ToLower(filepath.Ext(filePath))
+
+	return ext == ".txt" || ext == ".text"
+}
+
+// DetectDocLanguage returns the code of the natural language text is written in, judged by its
+// stop words outside of fenced code blocks, or "" when no language clearly leads.
+func DetectDocLanguage(text string) string {
+	scores := make(map[string]int, len(docLanguageStopWords))
+	for _, word := range strings.FieldsFunc(strings.ToLower(proseText(text)), isNotLetter) {
+		for _, language := range docLanguagesByWord[word] {
+			scores[language]++
+		}
+	}
+
+	best, bestScore, runnerUp := "", 0, 0
+	for _, language := range shared.DocLanguagesSupported {
+		switch score := scores[language]; {
+		case score > bestScore:
+			best, bestScore, runnerUp = language, score, bestScore
+		case score > runnerUp:
+			runnerUp = score
+		}
+	}
+	// The leader needs enough evidence and a margin of half again over the runner-up
+	if bestScore < shared.DocLanguageMinMatches || 2*bestScore < 3*runnerUp {
+		return ""
+	}
+
+	return best
+}
+
+// proseText returns text without its fenced code blocks.
+func proseText(text string) string {
+	var prose strings.Builder
+	inFence := false
+	for line := range strings.Lines(text) {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, markdownFence) || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+
+			continue
+		}
+		if !inFence {
+			prose.WriteString(line)
+		}
+	}
+
+	return prose.String()
+}
+
+// isNotLetter reports whether r separates words.
+func isNotLetter(r rune) bool {
+	return !unicode.IsLetter(r)
+}
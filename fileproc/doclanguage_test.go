@@ -0,0 +1,86 @@
+package fileproc_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/fileproc"
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+const (
+	englishDoc = "# Install\n\nThis is the guide to the tool. It is easy to use and you can run it from the " +
+		"shell with the default settings, which are described in the next section.\n"
+	finnishDoc = "# Asennus\n\nTämä on ohje, joka kertoo miten työkalu asennetaan. Se on helppo ja " +
+		"sen voi ajaa myös ilman asetuksia, mutta jos haluat, niin ne ovat mukana tässä.\n"
+	germanDoc = "# Installation\n\nDas ist die Anleitung für das Werkzeug. Es ist einfach und wird mit " +
+		"den Standardwerten auf der Kommandozeile gestartet, die auch im nächsten Abschnitt stehen.\n"
+)
+
+func TestDetectDocLanguage(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "english", text: englishDoc, want: "en"},
+		{name: "finnish", text: finnishDoc, want: "fi"},
+		{name: "german", text: germanDoc, want: "de"},
+		{name: "too short", text: "The end.", want: ""},
+		{name: "no prose", text: "| id | name |\n|----|------|\n| 1 | foo |\n| 2 | bar |\n", want: ""},
+		{
+			name: "code blocks ignored",
+			text: finnishDoc + "\n```go\n// The value is in the map and it is not for the user to see\n```\n",
+			want: "fi",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fileproc.DetectDocLanguage(tt.text); got != tt.want {
+				t.Errorf("DetectDocLanguage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestFileProcessorDocLanguage tests that prose files get their detected language as metadata,
+// that prose in languages not included is skipped, and that code is never inspected.
+func TestFileProcessorDocLanguage(t *testing.T) {
+	dir := t.TempDir()
+	english := testutil.CreateTestFile(t, dir, "README.md", []byte(englishDoc))
+	finnish := testutil.CreateTestFile(t, dir, "LUEMINUT.txt", []byte(finnishDoc))
+	code := testutil.CreateTestFile(t, dir, "main.go", []byte("// "+finnishDoc))
+
+	tests := []struct {
+		path        string
+		wantSkipped bool
+		wantMeta    string
+	}{
+		{path: english, wantMeta: "en"},
+		{path: finnish, wantSkipped: true},
+		{path: code, wantMeta: ""},
+	}
+
+	testutil.ResetViperConfig(t, "")
+	processor := fileproc.NewFileProcessor(dir)
+	processor.SetDocLanguages("en")
+	for _, tt := range tests {
+		outCh := make(chan fileproc.WriteRequest, 1)
+		err := processor.ProcessWithContext(context.Background(), tt.path, outCh)
+		if tt.wantSkipped {
+			var structErr *shared.StructuredError
+			if !errors.As(err, &structErr) || structErr.Code != shared.CodeValidationDocLanguage {
+				t.Errorf("processing %s: error = %v, want %s", tt.path, err, shared.CodeValidationDocLanguage)
+			}
+
+			continue
+		}
+		testutil.MustSucceed(t, err, "processing")
+		if req := <-outCh; req.Metadata[shared.MetadataKeyDocLanguage] != tt.wantMeta {
+			t.Errorf("%s metadata = %v, want doc_language %q", req.Path, req.Metadata, tt.wantMeta)
+		}
+	}
+}
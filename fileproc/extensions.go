@@ -145,6 +145,10 @@ func getLanguageMap() map[string]string {
 		".rst": "rst",
 		".tex": "latex",
 
+		// Patches
+		".diff":  "diff",
+		".patch": "diff",
+
 		// Functional languages
 		".hs":  "haskell",
 		".ml":  "ocaml",
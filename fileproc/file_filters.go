@@ -5,33 +5,75 @@ import (
 	"os"
 
 	"github.com/ivuorinen/gibidify/config"
+	"github.com/ivuorinen/gibidify/shared"
 )
 
+// SkipHook is called for every file the walker leaves out, with the skip reason (one of
+// shared.SkipReasonSizeLimit, SkipReasonBinary and SkipReasonIgnored) and the file size.
+type SkipHook func(path, reason string, size int64)
+
 // FileFilter defines filtering criteria for files and directories.
 type FileFilter struct {
 	ignoredDirs []string
 	sizeLimit   int64
+	registry    *FileTypeRegistry
+	onSkip      SkipHook
+
+	respectGitignore bool
 }
 
-// NewFileFilter creates a new file filter with current configuration.
+// NewFileFilter creates a new file filter with current configuration and the default registry.
 func NewFileFilter() *FileFilter {
+	return NewFileFilterWithRegistry(getRegistry())
+}
+
+// NewFileFilterWithRegistry creates a new file filter with current configuration that
+// classifies binary and image files with registry.
+func NewFileFilterWithRegistry(registry *FileTypeRegistry) *FileFilter {
+	return NewFileFilterWithSettings(registry, config.Global())
+}
+
+// NewFileFilterWithSettings creates a new file filter with the configuration in settings that
+// classifies binary and image files with registry.
+func NewFileFilterWithSettings(registry *FileTypeRegistry, settings *config.Settings) *FileFilter {
 	return &FileFilter{
-		ignoredDirs: config.IgnoredDirectories(),
-		sizeLimit:   config.FileSizeLimit(),
+		ignoredDirs: settings.IgnoredDirectories(),
+		sizeLimit:   settings.FileSizeLimit(),
+		registry:    registry,
+
+		respectGitignore: settings.CollectionRespectGitignore(),
 	}
 }
 
+// ignoreFileNames returns the names of the per-directory ignore files the filter honors.
+func (f *FileFilter) ignoreFileNames() []string {
+	if f.respectGitignore {
+		return []string{gitIgnoreFile, ignoreFile}
+	}
+
+	return []string{ignoreFile}
+}
+
 // shouldSkipEntry determines if an entry should be skipped based on ignore rules and filters.
+// Skipped files are reported to the skip hook.
 func (f *FileFilter) shouldSkipEntry(entry os.DirEntry, fullPath string, rules []ignoreRule) bool {
 	if entry.IsDir() {
 		return f.shouldSkipDirectory(entry)
 	}
 
-	if f.shouldSkipFile(entry, fullPath) {
-		return true
+	reason := f.fileSkipReason(entry, fullPath, rules)
+	if reason == "" {
+		return false
+	}
+	if f.onSkip != nil {
+		var size int64
+		if info, err := entry.Info(); err == nil {
+			size = info.Size()
+		}
+		f.onSkip(fullPath, reason, size)
 	}
 
-	return matchesIgnoreRules(fullPath, rules)
+	return true
 }
 
 // shouldSkipDirectory checks if a directory should be skipped based on the ignored directories list.
@@ -45,13 +87,22 @@ func (f *FileFilter) shouldSkipDirectory(entry os.DirEntry) bool {
 	return false
 }
 
-// shouldSkipFile checks if a file should be skipped based on size limit and file type.
-func (f *FileFilter) shouldSkipFile(entry os.DirEntry, fullPath string) bool {
+// fileSkipReason returns why a file should be skipped based on size limit, file type and
+// ignore rules, or "" when it should be collected.
+func (f *FileFilter) fileSkipReason(entry os.DirEntry, fullPath string, rules []ignoreRule) string {
 	// Check if file exceeds the configured size limit.
 	if info, err := entry.Info(); err == nil && info.Size() > f.sizeLimit {
-		return true
+		return shared.SkipReasonSizeLimit
 	}
 
 	// Apply the default filter to ignore binary and image files.
-	return IsBinary(fullPath) || IsImage(fullPath)
+	if f.registry.IsBinary(fullPath) || f.registry.IsImage(fullPath) {
+		return shared.SkipReasonBinary
+	}
+
+	if matchesIgnoreRules(fullPath, rules) {
+		return shared.SkipReasonIgnored
+	}
+
+	return ""
 }
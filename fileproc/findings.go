@@ -0,0 +1,203 @@
+// Package fileproc handles file processing, collection, and output formatting.
+package fileproc
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// FindingsReport holds the static analysis findings of a SARIF or golangci-lint JSON report,
+// keyed by the path the report names the file by.
+type FindingsReport struct {
+	files  map[string][]Finding
+	byName map[string][]string
+}
+
+// Finding is one linter complaint about a file. Line and Column are 0 when the report omits them.
+type Finding struct {
+	Line     int
+	Column   int
+	Severity string
+	Rule     string
+	Message  string
+}
+
+// findingsDocument decodes both report formats: SARIF lists runs, golangci-lint lists Issues.
+type findingsDocument struct {
+	Runs   []sarifRun      `json:"runs"`
+	Issues []golangciIssue `json:"Issues"`
+}
+
+// sarifRun is a SARIF 2.1.0 run: one tool's results.
+type sarifRun struct {
+	Tool struct {
+		Driver struct {
+			Name string `json:"name"`
+		} `json:"driver"`
+	} `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+// sarifResult is one SARIF result and the places it was found.
+type sarifResult struct {
+	RuleID  string `json:"ruleId"`
+	Level   string `json:"level"`
+	Message struct {
+		Text string `json:"text"`
+	} `json:"message"`
+	Locations []struct {
+		PhysicalLocation struct {
+			ArtifactLocation struct {
+				URI string `json:"uri"`
+			} `json:"artifactLocation"`
+			Region struct {
+				StartLine   int `json:"startLine"`
+				StartColumn int `json:"startColumn"`
+			} `json:"region"`
+		} `json:"physicalLocation"`
+	} `json:"locations"`
+}
+
+// golangciIssue is one issue of golangci-lint run --out-format json (v1) or --output.json.path (v2).
+type golangciIssue struct {
+	FromLinter string `json:"FromLinter"`
+	Text       string `json:"Text"`
+	Severity   string `json:"Severity"`
+	Pos        struct {
+		Filename string `json:"Filename"`
+		Line     int    `json:"Line"`
+		Column   int    `json:"Column"`
+	} `json:"Pos"`
+}
+
+// LoadFindingsReport reads the SARIF or golangci-lint JSON report at path.
+func LoadFindingsReport(path string) (*FindingsReport, error) {
+	file, err := os.Open(path) // #nosec G304 - path is the user-supplied --findings report
+	if err != nil {
+		return nil, shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIORead, "failed to open findings report").
+			WithFilePath(path)
+	}
+	defer shared.SafeCloseReader(file, path)
+
+	report, err := ParseFindingsReport(file)
+	if err != nil {
+		return nil, shared.WrapError(err, shared.ErrorTypeValidation, shared.CodeValidationFormat,
+			"failed to parse findings report").WithFilePath(path)
+	}
+
+	return report, nil
+}
+
+// ParseFindingsReport parses a SARIF 2.1.0 log or golangci-lint JSON output. Results without a
+// file location are skipped; a document that is neither format is an error.
+func ParseFindingsReport(r io.Reader) (*FindingsReport, error) {
+	var doc findingsDocument
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, shared.WrapError(err, shared.ErrorTypeValidation, shared.CodeValidationFormat, "invalid JSON")
+	}
+	if doc.Runs == nil && doc.Issues == nil {
+		return nil, shared.NewStructuredError(
+			shared.ErrorTypeValidation, shared.CodeValidationFormat,
+			"neither a SARIF log (runs) nor golangci-lint output (Issues)", "", nil,
+		)
+	}
+
+	report := &FindingsReport{files: make(map[string][]Finding), byName: make(map[string][]string)}
+	for _, run := range doc.Runs {
+		for _, result := range run.Results {
+			if len(result.Locations) == 0 {
+				continue
+			}
+			location := result.Locations[0].PhysicalLocation
+			report.add(sarifPath(location.ArtifactLocation.URI), Finding{
+				Line:     location.Region.StartLine,
+				Column:   location.Region.StartColumn,
+				Severity: cmp.Or(result.Level, "warning"),
+				Rule:     cmp.Or(result.RuleID, run.Tool.Driver.Name),
+				Message:  result.Message.Text,
+			})
+		}
+	}
+	for _, issue := range doc.Issues {
+		report.add(issue.Pos.Filename, Finding{
+			Line:     issue.Pos.Line,
+			Column:   issue.Pos.Column,
+			Severity: cmp.Or(issue.Severity, "warning"),
+			Rule:     issue.FromLinter,
+			Message:  issue.Text,
+		})
+	}
+
+	return report, nil
+}
+
+// sarifPath returns the file path of a SARIF artifact URI: the path of a file: URI, or the
+// unescaped relative reference.
+func sarifPath(uri string) string {
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return uri
+	}
+	if parsed.Scheme == "file" || parsed.Scheme == "" {
+		return parsed.Path
+	}
+
+	return uri
+}
+
+// add records finding for the file name. Findings without a file name are dropped.
+func (r *FindingsReport) add(name string, finding Finding) {
+	if name == "" {
+		return
+	}
+	name = filepath.ToSlash(name)
+	if _, ok := r.files[name]; !ok {
+		base := path.Base(name)
+		r.byName[base] = append(r.byName[base], name)
+	}
+	r.files[name] = append(r.files[name], finding)
+}
+
+// Findings returns the findings about the file at filePath, shown as relPath, ordered by
+// position. Report paths are matched like CoverageReport.Percent matches them.
+func (r *FindingsReport) Findings(filePath, relPath string) []Finding {
+	name, ok := matchReportPath(r.files, r.byName, filePath, relPath)
+	if !ok {
+		return nil
+	}
+
+	return slices.SortedStableFunc(slices.Values(r.files[name]), func(a, b Finding) int {
+		return cmp.Or(cmp.Compare(a.Line, b.Line), cmp.Compare(a.Column, b.Column))
+	})
+}
+
+// FormatFindings renders findings one per line as "line:column severity [rule] message".
+func FormatFindings(findings []Finding) string {
+	lines := make([]string, 0, len(findings))
+	for _, f := range findings {
+		var position string
+		switch {
+		case f.Line > 0 && f.Column > 0:
+			position = fmt.Sprintf("%d:%d ", f.Line, f.Column)
+		case f.Line > 0:
+			position = fmt.Sprintf("%d ", f.Line)
+		}
+		rule := ""
+		if f.Rule != "" {
+			rule = "[" + f.Rule + "] "
+		}
+		lines = append(lines, position+f.Severity+" "+rule+strings.Join(strings.Fields(f.Message), " "))
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,65 @@
+package fileproc_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/fileproc"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+func TestParseFindingsReport(t *testing.T) {
+	sarif := `{"version":"2.1.0","runs":[{"tool":{"driver":{"name":"gosec"}},"results":[
+		{"ruleId":"G304","level":"error","message":{"text":"Potential file inclusion"},
+		 "locations":[{"physicalLocation":{"artifactLocation":{"uri":"cmd/main.go"},
+		 "region":{"startLine":14,"startColumn":9}}}]},
+		{"message":{"text":"Hard-coded\ncredentials"},
+		 "locations":[{"physicalLocation":{"artifactLocation":{"uri":"file:///src/repo/cmd/main.go"},
+		 "region":{"startLine":3}}}]},
+		{"ruleId":"G101","message":{"text":"no location"}}]}]}`
+	golangci := `{"Issues":[
+		{"FromLinter":"errcheck","Text":"Error return value is not checked","Severity":"",
+		 "Pos":{"Filename":"pkg/io.go","Line":20,"Column":2}},
+		{"FromLinter":"revive","Text":"exported function should have comment","Severity":"error",
+		 "Pos":{"Filename":"pkg/io.go","Line":7,"Column":1}}],"Report":{}}`
+
+	tests := []struct {
+		name     string
+		report   string
+		filePath string
+		relPath  string
+		want     string
+	}{
+		{
+			name: "sarif relative uri", report: sarif, relPath: "cmd/main.go",
+			want: "14:9 error [G304] Potential file inclusion",
+		},
+		{
+			name: "sarif file uri", report: sarif, filePath: "/src/repo/cmd/main.go", relPath: "cmd/main.go",
+			want: "3 warning [gosec] Hard-coded credentials",
+		},
+		{
+			name: "golangci sorted by line", report: golangci, relPath: "pkg/io.go",
+			want: "7:1 error [revive] exported function should have comment\n" +
+				"20:2 warning [errcheck] Error return value is not checked",
+		},
+		{name: "file without findings", report: golangci, relPath: "pkg/other.go"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := fileproc.ParseFindingsReport(strings.NewReader(tt.report))
+			testutil.MustSucceed(t, err, "parsing findings report")
+
+			if got := fileproc.FormatFindings(report.Findings(tt.filePath, tt.relPath)); got != tt.want {
+				t.Errorf("findings of %s = %q, want %q", tt.relPath, got, tt.want)
+			}
+		})
+	}
+
+	for _, invalid := range []string{"not json", `{"mode":"set"}`} {
+		if _, err := fileproc.ParseFindingsReport(strings.NewReader(invalid)); err == nil {
+			t.Errorf("ParseFindingsReport(%q) accepted a report that is neither SARIF nor golangci-lint JSON", invalid)
+		}
+	}
+}
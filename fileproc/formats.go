@@ -1,11 +1,30 @@
 // Package fileproc handles file processing, collection, and output formatting.
 package fileproc
 
+import "github.com/ivuorinen/gibidify/shared"
+
 // FileData represents a single file's path and content.
 type FileData struct {
-	Path     string `json:"path"     yaml:"path"`
-	Content  string `json:"content"  yaml:"content"`
-	Language string `json:"language" yaml:"language"`
+	Path     string            `json:"path"               yaml:"path"`
+	Content  string            `json:"content"            yaml:"content"`
+	Language string            `json:"language"           yaml:"language"`
+	Metadata map[string]string `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+	Span     *SourceSpan       `json:"span,omitempty"     yaml:"span,omitempty"`
+}
+
+// SourceSpan maps a JSON file entry back to its source. Offset and Length locate the
+// escaped content string within the JSON document; the line and byte range describe the
+// part of the file the content covers, leaving out the header naming the file. Truncated is
+// set when a budget cut the content short, so the range covers only the start of the file,
+// or left part of it out otherwise.
+type SourceSpan struct {
+	Offset    int64 `json:"offset"              yaml:"offset"`
+	Length    int64 `json:"length"              yaml:"length"`
+	StartLine int   `json:"startLine"           yaml:"startLine"`
+	EndLine   int   `json:"endLine"             yaml:"endLine"`
+	StartByte int64 `json:"startByte"           yaml:"startByte"`
+	EndByte   int64 `json:"endByte"             yaml:"endByte"`
+	Truncated bool  `json:"truncated,omitempty" yaml:"truncated,omitempty"`
 }
 
 // OutputData represents the full output structure.
@@ -13,6 +32,10 @@ type OutputData struct {
 	Prefix string     `json:"prefix,omitempty" yaml:"prefix,omitempty"`
 	Suffix string     `json:"suffix,omitempty" yaml:"suffix,omitempty"`
 	Files  []FileData `json:"files"            yaml:"files"`
+	// Summary is the run summary written with output.appendRunSummary.
+	Summary *RunSummary `json:"summary,omitempty" yaml:"summary,omitempty"`
+	// Config holds the settings recorded with output.configProvenance in JSON bundles.
+	Config map[string]any `json:"config,omitempty" yaml:"-"`
 }
 
 // FormatWriter defines the interface for format-specific writers.
@@ -22,9 +45,15 @@ type FormatWriter interface {
 	Close() error
 }
 
-// detectLanguage tries to infer the code block language from the file extension.
-func detectLanguage(filePath string) string {
-	registry := DefaultRegistry()
-
-	return registry.Language(filePath)
+// entryLanguage returns the code block language for a write request, detected by registry.
+// Patch entries render as diffs and pull request or issue text as markdown, whatever their path.
+func entryLanguage(req WriteRequest, registry *FileTypeRegistry) string {
+	switch req.Metadata[shared.MetadataKeyRole] {
+	case shared.MetadataRolePatch:
+		return shared.LanguageDiff
+	case shared.MetadataRoleDescription, shared.MetadataRoleIssue:
+		return shared.LanguageMarkdown
+	default:
+		return registry.Language(req.Path)
+	}
 }
@@ -0,0 +1,138 @@
+// Package fileproc handles file processing, collection, and output formatting.
+package fileproc
+
+import (
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/ivuorinen/gibidify/config"
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// Generated text reasons, used as skip reasons in the metrics report.
+const (
+	GeneratedReasonMinified    = "minified"
+	GeneratedReasonHighEntropy = "high entropy"
+)
+
+// minASCIIRatio is the share of printable ASCII a sample needs before its entropy is judged;
+// multi-byte UTF-8 text has a naturally high byte entropy.
+const minASCIIRatio = 0.95
+
+// GeneratedTextVerdict explains why a text file looks machine-generated.
+type GeneratedTextVerdict struct {
+	Reason string
+	Detail string
+}
+
+// GeneratedTextFilter flags minified bundles, source maps, and encoded blobs that
+// pass the binary check but are useless to a reader of the bundle.
+type GeneratedTextFilter struct {
+	enabled          bool
+	action           string
+	minSize          int64
+	maxLineLength    int
+	entropyThreshold float64
+}
+
+// NewGeneratedTextFilter creates a filter with the current configuration.
+func NewGeneratedTextFilter() *GeneratedTextFilter {
+	return newGeneratedTextFilter(config.Global())
+}
+
+// newGeneratedTextFilter creates a filter with the configuration in settings.
+func newGeneratedTextFilter(settings *config.Settings) *GeneratedTextFilter {
+	return &GeneratedTextFilter{
+		enabled:          settings.GeneratedTextEnabled(),
+		action:           settings.GeneratedTextAction(),
+		minSize:          settings.GeneratedTextMinSize(),
+		maxLineLength:    settings.GeneratedTextMaxLineLength(),
+		entropyThreshold: settings.GeneratedTextEntropyThreshold(),
+	}
+}
+
+// Summarize reports whether flagged files are summarized instead of skipped.
+func (f *GeneratedTextFilter) Summarize() bool {
+	return f.action == shared.GeneratedTextActionSummarize
+}
+
+// Inspect samples the beginning of the file at filePath and classifies it.
+// Files smaller than the configured minimum size are not read.
+func (f *GeneratedTextFilter) Inspect(filePath string, size int64) (*GeneratedTextVerdict, error) {
+	if !f.enabled || size < f.minSize {
+		return nil, nil
+	}
+
+	file, err := openLimited(filePath)
+	if err != nil {
+		return nil, shared.WrapError(err, shared.ErrorTypeProcessing, shared.CodeProcessingFileRead, "failed to open file").
+			WithFilePath(filePath)
+	}
+	defer shared.SafeCloseReader(file, filePath)
+
+	sample := make([]byte, shared.GeneratedTextSampleSize)
+	n, err := io.ReadFull(file, sample)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, shared.WrapError(err, shared.ErrorTypeProcessing, shared.CodeProcessingFileRead, "failed to read file").
+			WithFilePath(filePath)
+	}
+
+	return f.Classify(sample[:n]), nil
+}
+
+// Classify returns a verdict when sample looks minified or encoded, or nil otherwise.
+func (f *GeneratedTextFilter) Classify(sample []byte) *GeneratedTextVerdict {
+	longest, entropy, asciiRatio := profileText(sample)
+
+	if longest > f.maxLineLength {
+		return &GeneratedTextVerdict{
+			Reason: GeneratedReasonMinified,
+			Detail: fmt.Sprintf("%s: line of %d bytes", GeneratedReasonMinified, longest),
+		}
+	}
+	if asciiRatio >= minASCIIRatio && entropy > f.entropyThreshold {
+		return &GeneratedTextVerdict{
+			Reason: GeneratedReasonHighEntropy,
+			Detail: fmt.Sprintf("%s: %.2f bits/byte", GeneratedReasonHighEntropy, entropy),
+		}
+	}
+
+	return nil
+}
+
+// profileText returns the longest line length, the Shannon entropy in bits per byte,
+// and the share of printable ASCII bytes in sample.
+func profileText(sample []byte) (longest int, entropy, asciiRatio float64) {
+	if len(sample) == 0 {
+		return 0, 0, 1
+	}
+
+	var counts [256]int
+	ascii, line := 0, 0
+	for _, b := range sample {
+		counts[b]++
+		if b == '\n' {
+			longest = max(longest, line)
+			line = 0
+
+			continue
+		}
+		line++
+		if b == '\t' || b == '\r' || (b >= ' ' && b < 0x7f) {
+			ascii++
+		}
+	}
+	longest = max(longest, line)
+
+	total := float64(len(sample))
+	for _, c := range counts {
+		if c == 0 {
+			continue
+		}
+		p := float64(c) / total
+		entropy -= p * math.Log2(p)
+	}
+
+	return longest, entropy, float64(ascii+counts['\n']) / total
+}
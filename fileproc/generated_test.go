@@ -0,0 +1,110 @@
+package fileproc_test
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/fileproc"
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// randomBase64 returns n bytes of base64 text wrapped at 76 columns, like an embedded blob.
+func randomBase64(t *testing.T, n int) string {
+	t.Helper()
+	raw := make([]byte, n)
+	_, err := rand.Read(raw)
+	testutil.MustSucceed(t, err, "generating random data")
+	encoded := base64.StdEncoding.EncodeToString(raw)
+
+	var b strings.Builder
+	for len(encoded) > 76 {
+		b.WriteString(encoded[:76] + "\n")
+		encoded = encoded[76:]
+	}
+	b.WriteString(encoded)
+
+	return b.String()
+}
+
+// TestGeneratedTextFilterClassify tests the minified and high-entropy heuristics.
+func TestGeneratedTextFilterClassify(t *testing.T) {
+	testutil.ResetViperConfig(t, "")
+	filter := fileproc.NewGeneratedTextFilter()
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"source code", strings.Repeat("func main() {\n\tfmt.Println(\"hello\")\n}\n", 500), ""},
+		{"minified bundle", strings.Repeat("var a=function(b){return b*2};", 400), fileproc.GeneratedReasonMinified},
+		{"base64 blob", randomBase64(t, 24*1024), fileproc.GeneratedReasonHighEntropy},
+		{"non-ASCII prose", strings.Repeat("日本語のテキストです。漢字とかなを含む。\n", 600), ""},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verdict := filter.Classify([]byte(tt.content))
+			got := ""
+			if verdict != nil {
+				got = verdict.Reason
+			}
+			if got != tt.want {
+				t.Errorf("Classify() reason = %q, want %q (verdict %+v)", got, tt.want, verdict)
+			}
+		})
+	}
+}
+
+// TestFileProcessorGeneratedText tests that flagged files are skipped or summarized per generatedText.action.
+func TestFileProcessorGeneratedText(t *testing.T) {
+	dir := t.TempDir()
+	minified := strings.Repeat("var a=function(b){return b*2};", 1000)
+	filePath := testutil.CreateTestFile(t, dir, "app.min.js", []byte(minified))
+	smallPath := testutil.CreateTestFile(t, dir, "small.min.js", []byte(minified[:1024]))
+
+	t.Run("skip", func(t *testing.T) {
+		testutil.ResetViperConfig(t, "")
+		outCh := make(chan fileproc.WriteRequest, 1)
+		err := fileproc.NewFileProcessor(dir).ProcessWithContext(context.Background(), filePath, outCh)
+
+		var structErr *shared.StructuredError
+		if !errors.As(err, &structErr) || structErr.Code != shared.CodeValidationGenerated {
+			t.Fatalf("expected generated text error, got %v", err)
+		}
+		if structErr.Context["reason"] != fileproc.GeneratedReasonMinified || len(outCh) != 0 {
+			t.Errorf("unexpected skip: context %v, %d queued", structErr.Context, len(outCh))
+		}
+	})
+
+	t.Run("below minimum size", func(t *testing.T) {
+		testutil.ResetViperConfig(t, "")
+		outCh := make(chan fileproc.WriteRequest, 1)
+		testutil.MustSucceed(t,
+			fileproc.NewFileProcessor(dir).ProcessWithContext(context.Background(), smallPath, outCh), "processing")
+		if req := <-outCh; !strings.Contains(req.Content, minified[:1024]) {
+			t.Errorf("small file content was not kept: %q", req.Content)
+		}
+	})
+
+	t.Run("summarize", func(t *testing.T) {
+		testutil.SetViperKeys(t, map[string]any{shared.ConfigKeyGeneratedTextAction: shared.GeneratedTextActionSummarize})
+		outCh := make(chan fileproc.WriteRequest, 1)
+		testutil.MustSucceed(t,
+			fileproc.NewFileProcessor(dir).ProcessWithContext(context.Background(), filePath, outCh), "processing")
+
+		req := <-outCh
+		if strings.Contains(req.Content, minified) || !strings.Contains(req.Content, "bytes omitted: minified") {
+			t.Errorf("content was not summarized: %q", req.Content)
+		}
+		if !strings.HasPrefix(req.Metadata[shared.MetadataKeyOmitted], fileproc.GeneratedReasonMinified) {
+			t.Errorf("missing omitted metadata: %v", req.Metadata)
+		}
+	})
+}
@@ -0,0 +1,240 @@
+// Package fileproc handles file processing, collection, and output formatting.
+package fileproc
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// globAnyDirs is the path segment matching any number of directories, including none.
+const globAnyDirs = "**"
+
+// Glob is a compiled doublestar-style glob matched against slash-separated relative paths.
+type Glob struct {
+	pattern      string
+	alternatives [][]string
+}
+
+// CompileGlob compiles a doublestar-style glob: `*`, `?` and `[...]` match within one path
+// segment, a `**` segment matches any number of directories and `{a,b}` matches either
+// alternative. Patterns are relative, so a leading `/` is not allowed.
+func CompileGlob(pattern string) (*Glob, error) {
+	if strings.TrimSpace(pattern) == "" {
+		return nil, globError(pattern, "pattern is empty")
+	}
+	if strings.HasPrefix(pattern, "/") {
+		return nil, globError(pattern, "pattern must be relative to the source directory")
+	}
+
+	expanded, err := expandBraces(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	glob := &Glob{pattern: pattern}
+	for _, alternative := range expanded {
+		segments := strings.Split(alternative, "/")
+		for _, segment := range segments {
+			if _, err := path.Match(segment, ""); err != nil {
+				return nil, globError(pattern, fmt.Sprintf("malformed segment %q", segment))
+			}
+		}
+		glob.alternatives = append(glob.alternatives, segments)
+	}
+
+	return glob, nil
+}
+
+// String returns the pattern the glob was compiled from.
+func (g *Glob) String() string {
+	return g.pattern
+}
+
+// Match reports whether the slash-separated relative path rel matches the glob.
+func (g *Glob) Match(rel string) bool {
+	name := strings.Split(rel, "/")
+	for _, segments := range g.alternatives {
+		if matchSegments(segments, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchSegments matches the path segments of name against the glob segments of pattern.
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == globAnyDirs {
+			for skip := 0; skip <= len(name); skip++ {
+				if matchSegments(pattern[1:], name[skip:]) {
+					return true
+				}
+			}
+
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if matched, err := path.Match(pattern[0], name[0]); err != nil || !matched {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+
+	return len(name) == 0
+}
+
+// expandBraces returns the patterns pattern stands for once its `{a,b}` alternations are
+// expanded, nested ones included.
+func expandBraces(pattern string) ([]string, error) {
+	open := strings.IndexByte(pattern, '{')
+	if open < 0 {
+		if strings.IndexByte(pattern, '}') >= 0 {
+			return nil, globError(pattern, "unmatched }")
+		}
+
+		return []string{pattern}, nil
+	}
+
+	choices, closing := splitBraces(pattern[open+1:])
+	if closing < 0 {
+		return nil, globError(pattern, "unmatched {")
+	}
+	rest, err := expandBraces(pattern[open+1+closing+1:])
+	if err != nil {
+		return nil, err
+	}
+
+	var expanded []string
+	for _, choice := range choices {
+		inner, err := expandBraces(choice)
+		if err != nil {
+			return nil, err
+		}
+		for _, head := range inner {
+			for _, tail := range rest {
+				expanded = append(expanded, pattern[:open]+head+tail)
+			}
+		}
+	}
+
+	return expanded, nil
+}
+
+// splitBraces splits the body of a brace alternation, the text after its `{`, on its
+// top-level commas. It returns the alternatives and the index of the closing `}`, or -1.
+func splitBraces(body string) ([]string, int) {
+	var choices []string
+	depth, start := 0, 0
+	for i := range len(body) {
+		switch body[i] {
+		case '{':
+			depth++
+		case '}':
+			if depth == 0 {
+				return append(choices, body[start:i]), i
+			}
+			depth--
+		case ',':
+			if depth == 0 {
+				choices = append(choices, body[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	return nil, -1
+}
+
+// globError returns the validation error of an invalid glob pattern.
+func globError(pattern, reason string) error {
+	return shared.NewStructuredError(
+		shared.ErrorTypeValidation, shared.CodeValidationFormat,
+		fmt.Sprintf("invalid glob %q: %s", pattern, reason), "", nil,
+	)
+}
+
+// GlobFilter keeps the files matching at least one include glob, or every file without
+// include globs, that match no exclude glob.
+type GlobFilter struct {
+	root    string
+	include []*Glob
+	exclude []*Glob
+}
+
+// NewGlobFilter compiles the include and exclude globs, matched against paths relative to
+// root. It returns nil when there are no globs.
+func NewGlobFilter(root string, include, exclude []string) (*GlobFilter, error) {
+	if len(include) == 0 && len(exclude) == 0 {
+		return nil, nil
+	}
+
+	filter := &GlobFilter{root: root}
+	var err error
+	if filter.include, err = compileGlobs(include); err != nil {
+		return nil, err
+	}
+	if filter.exclude, err = compileGlobs(exclude); err != nil {
+		return nil, err
+	}
+
+	return filter, nil
+}
+
+// compileGlobs compiles every pattern of patterns.
+func compileGlobs(patterns []string) ([]*Glob, error) {
+	globs := make([]*Glob, 0, len(patterns))
+	for _, pattern := range patterns {
+		glob, err := CompileGlob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		globs = append(globs, glob)
+	}
+
+	return globs, nil
+}
+
+// Keep reports whether the filter keeps the file at filePath.
+func (f *GlobFilter) Keep(filePath string) bool {
+	rel, err := filepath.Rel(f.root, filePath)
+	if err != nil {
+		rel = filePath
+	}
+	rel = filepath.ToSlash(rel)
+
+	if len(f.include) > 0 && !matchesAny(f.include, rel) {
+		return false
+	}
+
+	return !matchesAny(f.exclude, rel)
+}
+
+// Filter returns the files the filter keeps, in their order.
+func (f *GlobFilter) Filter(files []string) []string {
+	kept := make([]string, 0, len(files))
+	for _, file := range files {
+		if f.Keep(file) {
+			kept = append(kept, file)
+		}
+	}
+
+	return kept
+}
+
+// matchesAny reports whether rel matches one of globs.
+func matchesAny(globs []*Glob, rel string) bool {
+	for _, glob := range globs {
+		if glob.Match(rel) {
+			return true
+		}
+	}
+
+	return false
+}
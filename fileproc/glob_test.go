@@ -0,0 +1,97 @@
+package fileproc_test
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/fileproc"
+)
+
+// TestGlobMatch tests the doublestar glob syntax: single-segment wildcards, `**` segments
+// matching any number of directories, and brace alternations.
+func TestGlobMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		rel     string
+		want    bool
+	}{
+		{pattern: "*.go", rel: "main.go", want: true},
+		{pattern: "*.go", rel: "cmd/main.go", want: false},
+		{pattern: "**/*.go", rel: "main.go", want: true},
+		{pattern: "**/*.go", rel: "cmd/app/main.go", want: true},
+		{pattern: "**/*_test.go", rel: "cmd/main.go", want: false},
+		{pattern: "cmd/**", rel: "cmd/app/main.go", want: true},
+		{pattern: "cmd/**/main.go", rel: "cmd/main.go", want: true},
+		{pattern: "cmd/**/main.go", rel: "internal/cmd/main.go", want: false},
+		{pattern: "docs/?.md", rel: "docs/a.md", want: true},
+		{pattern: "docs/[ab].md", rel: "docs/c.md", want: false},
+		{pattern: "**/*.{go,md}", rel: "docs/guide.md", want: true},
+		{pattern: "{cmd,internal/{app,lib}}/*.go", rel: "internal/lib/x.go", want: true},
+		{pattern: "{cmd,internal/{app,lib}}/*.go", rel: "internal/x.go", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.rel, func(t *testing.T) {
+			glob, err := fileproc.CompileGlob(tt.pattern)
+			if err != nil {
+				t.Fatalf("CompileGlob(%q) failed: %v", tt.pattern, err)
+			}
+			if got := glob.Match(tt.rel); got != tt.want {
+				t.Errorf("Match(%q) = %v, want %v", tt.rel, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestCompileGlobErrors tests that malformed globs are rejected with the reason.
+func TestCompileGlobErrors(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{pattern: "", want: "pattern is empty"},
+		{pattern: "/src/*.go", want: "must be relative"},
+		{pattern: "src/[a-.go", want: `malformed segment "[a-.go"`},
+		{pattern: "*.{go,md", want: "unmatched {"},
+		{pattern: "*.go}", want: "unmatched }"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			_, err := fileproc.CompileGlob(tt.pattern)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("CompileGlob(%q) error = %v, want %q", tt.pattern, err, tt.want)
+			}
+		})
+	}
+}
+
+// TestGlobFilter tests that files must match an include glob, when there are any, and no
+// exclude glob.
+func TestGlobFilter(t *testing.T) {
+	root := filepath.FromSlash("/src")
+	path := func(rel string) string { return filepath.Join(root, filepath.FromSlash(rel)) }
+	files := []string{path("main.go"), path("main_test.go"), path("docs/guide.md"), path("cmd/app/run.go")}
+
+	if filter, err := fileproc.NewGlobFilter(root, nil, nil); filter != nil || err != nil {
+		t.Errorf("NewGlobFilter() without globs = %v, %v, want nil", filter, err)
+	}
+
+	filter, err := fileproc.NewGlobFilter(root, []string{"**/*.go"}, []string{"**/*_test.go"})
+	if err != nil {
+		t.Fatalf("NewGlobFilter() failed: %v", err)
+	}
+	if got, want := filter.Filter(files), []string{path("main.go"), path("cmd/app/run.go")}; !slices.Equal(got, want) {
+		t.Errorf("Filter() = %v, want %v", got, want)
+	}
+
+	filter, err = fileproc.NewGlobFilter(root, nil, []string{"docs/**"})
+	if err != nil {
+		t.Fatalf("NewGlobFilter() failed: %v", err)
+	}
+	if got := filter.Filter(files); len(got) != 3 || slices.Contains(got, path("docs/guide.md")) {
+		t.Errorf("Filter() = %v, want everything but docs", got)
+	}
+}
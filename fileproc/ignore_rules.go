@@ -2,8 +2,10 @@
 package fileproc
 
 import (
+	"cmp"
 	"os"
 	"path/filepath"
+	"strings"
 
 	ignore "github.com/sabhiram/go-gitignore"
 )
@@ -14,15 +16,20 @@ type ignoreRule struct {
 	base string
 }
 
-// loadIgnoreRules loads ignore rules from the current directory and combines them with parent rules.
-func loadIgnoreRules(currentDir string, parentRules []ignoreRule) []ignoreRule {
-	// Pre-allocate for parent rules plus possible .gitignore and .ignore
-	const expectedIgnoreFiles = 2
-	rules := make([]ignoreRule, 0, len(parentRules)+expectedIgnoreFiles)
+// Per-directory ignore files read by the walker.
+const (
+	gitIgnoreFile = ".gitignore"
+	ignoreFile    = ".ignore"
+)
+
+// loadIgnoreRules loads ignore rules from the ignore files named fileNames in the current
+// directory and combines them with parent rules.
+func loadIgnoreRules(currentDir string, parentRules []ignoreRule, fileNames []string) []ignoreRule {
+	rules := make([]ignoreRule, 0, len(parentRules)+len(fileNames))
 	rules = append(rules, parentRules...)
 
-	// Check for .gitignore and .ignore files in the current directory.
-	for _, fileName := range []string{".gitignore", ".ignore"} {
+	// Check for the ignore files in the current directory.
+	for _, fileName := range fileNames {
 		if rule := tryLoadIgnoreFile(currentDir, fileName); rule != nil {
 			rules = append(rules, *rule)
 		}
@@ -47,6 +54,60 @@ func tryLoadIgnoreFile(dir, fileName string) *ignoreRule {
 	return nil
 }
 
+// gitRepoRules returns the rules git applies to root from outside of it: the global excludes
+// file at excludesFile, if any, the .git/info/exclude file of the enclosing repository and the
+// .gitignore files of the directories between the repository root and root. Outside of a git
+// repository only the global excludes file applies, relative to root.
+func gitRepoRules(root, excludesFile string) []ignoreRule {
+	repoRoot := findRepoRoot(root)
+
+	var rules []ignoreRule
+	if excludesFile != "" {
+		if rule := tryLoadIgnoreFile(filepath.Dir(excludesFile), filepath.Base(excludesFile)); rule != nil {
+			rule.base = cmp.Or(repoRoot, root)
+			rules = append(rules, *rule)
+		}
+	}
+	if repoRoot == "" {
+		return rules
+	}
+
+	if rule := tryLoadIgnoreFile(filepath.Join(repoRoot, ".git", "info"), "exclude"); rule != nil {
+		// Exclude patterns are relative to the repository root, like a top-level .gitignore
+		rule.base = repoRoot
+		rules = append(rules, *rule)
+	}
+
+	rel, err := filepath.Rel(repoRoot, root)
+	if err != nil || rel == "." {
+		return rules
+	}
+	dir := repoRoot
+	for _, name := range strings.Split(rel, string(filepath.Separator)) {
+		if rule := tryLoadIgnoreFile(dir, gitIgnoreFile); rule != nil {
+			rules = append(rules, *rule)
+		}
+		dir = filepath.Join(dir, name)
+	}
+
+	return rules
+}
+
+// findRepoRoot returns the closest directory at or above dir holding a .git directory or
+// file, or "" when there is none.
+func findRepoRoot(dir string) string {
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
 // matchesIgnoreRules checks if a path matches any of the ignore rules.
 func matchesIgnoreRules(fullPath string, rules []ignoreRule) bool {
 	for _, rule := range rules {
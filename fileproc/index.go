@@ -0,0 +1,102 @@
+// Package fileproc handles file processing, collection, and output formatting.
+package fileproc
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// IndexEntry locates one file section inside a bundle.
+type IndexEntry struct {
+	ID       string `json:"id"`
+	Path     string `json:"path"`
+	Language string `json:"language"`
+	Size     int64  `json:"size"`
+	Offset   int64  `json:"offset"`
+	Length   int64  `json:"length"`
+}
+
+// BundleIndex is a lightweight table of the sections written to a bundle, letting
+// editors jump from a bundle section back to the workspace file without parsing the bundle.
+type BundleIndex struct {
+	Bundle  string       `json:"bundle"`
+	Format  string       `json:"format"`
+	Entries []IndexEntry `json:"files"`
+
+	disabled bool
+}
+
+// NewBundleIndex creates an empty index for the bundle at path.
+func NewBundleIndex(bundle, format string) *BundleIndex {
+	return &BundleIndex{Bundle: bundle, Format: format, Entries: []IndexEntry{}}
+}
+
+// Available reports whether offsets could be recorded; writing to an unseekable
+// destination such as a named pipe disables the index.
+func (idx *BundleIndex) Available() bool {
+	return !idx.disabled
+}
+
+// Shift moves every recorded offset by delta, for bundles embedded in a larger document.
+func (idx *BundleIndex) Shift(delta int64) {
+	for i := range idx.Entries {
+		idx.Entries[i].Offset += delta
+	}
+}
+
+// Save writes the index to path as JSON.
+func (idx *BundleIndex) Save(path string) error {
+	data, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOEncoding, "encoding bundle index")
+	}
+	if err := os.WriteFile(path, append(data, '\n'), shared.OutputFilePermission); err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOFileWrite, "writing bundle index").
+			WithFilePath(path)
+	}
+
+	return nil
+}
+
+// offset returns the current write position of output, disabling the index when it cannot be determined.
+func (idx *BundleIndex) offset(output *bundleOutput) (int64, bool) {
+	if idx == nil || idx.disabled {
+		return 0, false
+	}
+
+	pos, err := output.offset()
+	if err != nil {
+		shared.GetLogger().Warnf("Bundle index disabled: cannot determine output offsets: %v", err)
+		idx.disabled = true
+
+		return 0, false
+	}
+
+	return pos, true
+}
+
+// record adds the section written for req between start and the current position of output,
+// with its language detected by registry and its file ID hashed with algorithm.
+func (idx *BundleIndex) record(
+	output *bundleOutput,
+	req WriteRequest,
+	start int64,
+	registry *FileTypeRegistry,
+	algorithm string,
+) {
+	end, ok := idx.offset(output)
+	if !ok {
+		return
+	}
+
+	idx.Entries = append(idx.Entries, IndexEntry{
+		ID:       fileID(algorithm, req.Path),
+		Path:     req.Path,
+		Language: entryLanguage(req, registry),
+		Size:     req.Size,
+		Offset:   start,
+		Length:   end - start,
+	})
+}
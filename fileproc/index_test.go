@@ -0,0 +1,57 @@
+package fileproc_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/fileproc"
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// TestStartIndexedWriterOffsets tests that recorded offsets cover each file section in every format.
+func TestStartIndexedWriterOffsets(t *testing.T) {
+	reqs := []fileproc.WriteRequest{
+		{Path: "main.go", Content: shared.LiteralPackageMain, Size: int64(len(shared.LiteralPackageMain))},
+		{Path: "lib/util.py", IsStream: true, Reader: strings.NewReader("print(1)\n"), Size: 9},
+	}
+
+	for _, format := range []string{shared.FormatJSON, shared.FormatYAML, shared.FormatMarkdown} {
+		t.Run(format, func(t *testing.T) {
+			outFile, path := testutil.CreateTempOutputFile(t, "index_*")
+			writeCh := make(chan fileproc.WriteRequest, len(reqs))
+			done := make(chan struct{})
+			for _, req := range reqs {
+				if req.IsStream {
+					req.Reader = strings.NewReader("print(1)\n")
+				}
+				writeCh <- req
+			}
+			close(writeCh)
+
+			index := fileproc.NewBundleIndex(path, format)
+			fileproc.StartIndexedWriter(outFile, writeCh, done, format, "PREFIX", "SUFFIX", index)
+			<-done
+			testutil.CloseFile(t, outFile)
+
+			data, err := os.ReadFile(path)
+			testutil.MustSucceed(t, err, "reading output")
+			if len(index.Entries) != len(reqs) {
+				t.Fatalf("index has %d entries, want %d", len(index.Entries), len(reqs))
+			}
+			for i, entry := range index.Entries {
+				section := string(data[entry.Offset : entry.Offset+entry.Length])
+				if entry.Path != reqs[i].Path || !strings.Contains(section, reqs[i].Path) {
+					t.Errorf("entry %d (%s) does not cover its section: %q", i, entry.Path, section)
+				}
+				if entry.ID != fileproc.FileID(entry.Path) {
+					t.Errorf("entry %d has ID %q, want %q", i, entry.ID, fileproc.FileID(entry.Path))
+				}
+				if entry.Size != reqs[i].Size || entry.Language == "" {
+					t.Errorf("entry %d has size %d language %q", i, entry.Size, entry.Language)
+				}
+			}
+		})
+	}
+}
@@ -2,32 +2,81 @@
 package fileproc
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"os"
+	"strings"
 
+	"github.com/ivuorinen/gibidify/config"
 	"github.com/ivuorinen/gibidify/shared"
 )
 
 // JSONWriter handles JSON format output with streaming support.
 type JSONWriter struct {
-	outFile   *os.File
+	outFile   *countingWriter
 	firstFile bool
+	spans     bool
+	registry  *FileTypeRegistry
 }
 
-// NewJSONWriter creates a new JSON writer.
+// NewJSONWriter creates a new JSON writer detecting languages with the default registry.
 func NewJSONWriter(outFile *os.File) *JSONWriter {
+	return newJSONWriter(outFile, getRegistry(), config.Global())
+}
+
+// newJSONWriter creates a JSON writer for any output.
+func newJSONWriter(out io.Writer, registry *FileTypeRegistry, settings *config.Settings) *JSONWriter {
 	return &JSONWriter{
-		outFile:   outFile,
+		outFile:   &countingWriter{w: out},
 		firstFile: true,
+		spans:     settings.OutputSourceSpans(),
+		registry:  registry,
 	}
 }
 
+// countingWriter tracks how many bytes of the document have been written, so span
+// offsets are relative to the JSON document even when appending or writing to a pipe.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+// Write writes p to the underlying writer and counts the bytes written.
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+
+	return n, err
+}
+
+// WriteString writes s to the underlying writer and counts the bytes written.
+func (c *countingWriter) WriteString(s string) (int, error) {
+	return c.Write([]byte(s))
+}
+
 // Start writes the JSON header.
 func (w *JSONWriter) Start(prefix, suffix string) error {
+	return w.start("{", prefix, suffix)
+}
+
+// StartWithConfig writes the JSON header with settings as the leading config field.
+func (w *JSONWriter) StartWithConfig(prefix, suffix string, settings map[string]any) error {
+	encoded, err := encodeConfigProvenance(settings)
+	if err != nil {
+		return err
+	}
+
+	return w.start(`{"config":`+encoded+",", prefix, suffix)
+}
+
+// start writes the JSON header after open, the start of the document up to the prefix field.
+func (w *JSONWriter) start(open, prefix, suffix string) error {
 	// Start JSON structure
-	if _, err := w.outFile.WriteString(`{"prefix":"`); err != nil {
+	if _, err := w.outFile.WriteString(open + `"prefix":"`); err != nil {
 		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOWrite, "failed to write JSON start")
 	}
 
@@ -66,6 +115,12 @@ func (w *JSONWriter) WriteFile(req WriteRequest) error {
 	if req.IsStream {
 		return w.writeStreaming(req)
 	}
+	if w.spans {
+		// Spans need the content offset, so inline content goes through the streaming path.
+		req.Reader = strings.NewReader(req.Content)
+
+		return w.writeStreaming(req)
+	}
 
 	return w.writeInline(req)
 }
@@ -80,15 +135,40 @@ func (w *JSONWriter) Close() error {
 	return nil
 }
 
+// CloseWithSummary writes the JSON footer with a run summary as the closing summary field.
+func (w *JSONWriter) CloseWithSummary(summary RunSummary) error {
+	data, err := json.Marshal(summary)
+	if err != nil {
+		return shared.WrapError(err, shared.ErrorTypeProcessing, shared.CodeProcessingEncode, "failed to encode run summary")
+	}
+	if _, err := w.outFile.WriteString(`],"summary":` + string(data) + "}"); err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOWrite, "failed to write JSON end")
+	}
+
+	return nil
+}
+
 // writeStreaming writes a large file as JSON in streaming chunks.
 func (w *JSONWriter) writeStreaming(req WriteRequest) error {
 	defer shared.SafeCloseReader(req.Reader, req.Path)
 
-	language := detectLanguage(req.Path)
+	language := entryLanguage(req, w.registry)
+
+	metadata, err := encodeJSONMetadata(req.Metadata)
+	if err != nil {
+		return shared.WrapError(
+			err,
+			shared.ErrorTypeProcessing,
+			shared.CodeProcessingEncode,
+			"failed to marshal JSON metadata",
+		).WithFilePath(req.Path)
+	}
 
 	// Write file start
 	escapedPath := shared.EscapeForJSON(req.Path)
-	if _, err := fmt.Fprintf(w.outFile, `{"path":"%s","language":"%s","content":"`, escapedPath, language); err != nil {
+	if _, err := fmt.Fprintf(
+		w.outFile, `{"path":"%s","language":"%s",%s"content":"`, escapedPath, language, metadata,
+	); err != nil {
 		return shared.WrapError(
 			err,
 			shared.ErrorTypeIO,
@@ -98,12 +178,18 @@ func (w *JSONWriter) writeStreaming(req WriteRequest) error {
 	}
 
 	// Stream content with JSON escaping
-	if err := w.streamJSONContent(req.Reader, req.Path); err != nil {
+	reader, source := req.Reader, sourceRange{end: math.MaxInt64}
+	if w.spans {
+		reader, source = sourceOf(req)
+	}
+	span := SourceSpan{Offset: w.outFile.n, Truncated: req.Metadata[shared.MetadataKeyOmitted] != ""}
+	if err := w.streamJSONContent(reader, req.Path, source, &span); err != nil {
 		return err
 	}
+	span.Length = w.outFile.n - span.Offset
 
 	// Write file end
-	if _, err := w.outFile.WriteString(`"}`); err != nil {
+	if _, err := w.outFile.WriteString(`"` + w.encodeSpan(span) + `}`); err != nil {
 		return shared.WrapError(
 			err,
 			shared.ErrorTypeIO,
@@ -117,16 +203,41 @@ func (w *JSONWriter) writeStreaming(req WriteRequest) error {
 
 // writeInline writes a small file directly as JSON.
 func (w *JSONWriter) writeInline(req WriteRequest) error {
-	language := detectLanguage(req.Path)
+	encoded, err := renderedEntry(req, w)
+	if err != nil {
+		return err
+	}
+	defer shared.ScratchBuffers.Put(encoded)
+
+	if _, err := w.outFile.Write(*encoded); err != nil {
+		return shared.WrapError(
+			err,
+			shared.ErrorTypeIO,
+			shared.CodeIOWrite,
+			"failed to write JSON file",
+		).WithFilePath(req.Path)
+	}
+
+	return nil
+}
+
+// renderInline encodes a small file as a JSON object. Entries with source spans need their
+// output offset, so they are not rendered ahead of time.
+func (w *JSONWriter) renderInline(dst []byte, req WriteRequest) ([]byte, error) {
+	if w.spans {
+		return nil, nil
+	}
+
 	fileData := FileData{
 		Path:     req.Path,
 		Content:  req.Content,
-		Language: language,
+		Language: entryLanguage(req, w.registry),
+		Metadata: req.Metadata,
 	}
-
-	encoded, err := json.Marshal(fileData)
-	if err != nil {
-		return shared.WrapError(
+	// Encoding into dst rather than with json.Marshal reuses the scratch buffer
+	buf := bytes.NewBuffer(dst)
+	if err := json.NewEncoder(buf).Encode(fileData); err != nil {
+		return nil, shared.WrapError(
 			err,
 			shared.ErrorTypeProcessing,
 			shared.CodeProcessingEncode,
@@ -134,36 +245,112 @@ func (w *JSONWriter) writeInline(req WriteRequest) error {
 		).WithFilePath(req.Path)
 	}
 
-	if _, err := w.outFile.Write(encoded); err != nil {
-		return shared.WrapError(
-			err,
-			shared.ErrorTypeIO,
-			shared.CodeIOWrite,
-			"failed to write JSON file",
-		).WithFilePath(req.Path)
+	return bytes.TrimSuffix(buf.Bytes(), []byte{'\n'}), nil
+}
+
+// encodeJSONMetadata renders metadata as a `"metadata":{...},` object member, or "" when empty.
+func encodeJSONMetadata(meta map[string]string) (string, error) {
+	if len(meta) == 0 {
+		return "", nil
 	}
 
-	return nil
+	encoded, err := json.Marshal(meta)
+	if err != nil {
+		return "", err
+	}
+
+	return `"metadata":` + string(encoded) + ",", nil
+}
+
+// encodeSpan renders span as a `,"span":{...}` object member, or "" when spans are disabled.
+func (w *JSONWriter) encodeSpan(span SourceSpan) string {
+	if !w.spans {
+		return ""
+	}
+
+	encoded, err := json.Marshal(span)
+	if err != nil {
+		return ""
+	}
+
+	return `,"span":` + string(encoded)
+}
+
+// sourceRange locates the source file within the content of a file entry, as byte positions.
+type sourceRange struct {
+	start, end int64
 }
 
-// streamJSONContent streams content with JSON escaping.
-func (w *JSONWriter) streamJSONContent(reader io.Reader, path string) error {
+// sourceOf returns the reader of the content of req and the range of the source file within
+// it: the processor puts the header naming the file before it and, unless it is streamed, a
+// newline after it. Content without the header is all source.
+func sourceOf(req WriteRequest) (io.Reader, sourceRange) {
+	header := fileHeader(req.Path)
+	if !req.IsStream {
+		if !strings.HasPrefix(req.Content, header) {
+			return req.Reader, sourceRange{end: int64(len(req.Content))}
+		}
+
+		return req.Reader, sourceRange{
+			start: int64(len(header)), end: int64(len(strings.TrimSuffix(req.Content, "\n"))),
+		}
+	}
+
+	buffered := bufio.NewReader(req.Reader)
+	if peeked, err := buffered.Peek(len(header)); err == nil && string(peeked) == header {
+		return buffered, sourceRange{start: int64(len(header)), end: math.MaxInt64}
+	}
+
+	return buffered, sourceRange{end: math.MaxInt64}
+}
+
+// streamJSONContent streams content with JSON escaping, counting the bytes and lines of the
+// source range of the content into span.
+func (w *JSONWriter) streamJSONContent(reader io.Reader, path string, source sourceRange, span *SourceSpan) error {
+	var pos int64
+	lastByte := byte('\n')
+	escaper := shared.NewJSONStreamEscaper()
+	defer escaper.Release()
 	if err := shared.StreamContent(
 		reader, w.outFile, shared.FileProcessingStreamChunkSize, path, func(chunk []byte) []byte {
-			escaped := shared.EscapeForJSON(string(chunk))
+			from, to := max(source.start-pos, 0), min(source.end-pos, int64(len(chunk)))
+			if from < to {
+				span.EndByte += to - from
+				span.EndLine += bytes.Count(chunk[from:to], []byte{'\n'})
+				lastByte = chunk[to-1]
+			}
+			pos += int64(len(chunk))
 
-			return []byte(escaped)
+			return escaper.Escape(chunk)
 		},
 	); err != nil {
 		return fmt.Errorf("streaming JSON content: %w", err)
 	}
+	if _, err := w.outFile.Write(escaper.Flush()); err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOWrite, "failed to write JSON content").
+			WithFilePath(path)
+	}
+
+	// A final line without a trailing newline still counts as a line.
+	if lastByte != '\n' {
+		span.EndLine++
+	}
+	if span.EndLine > 0 {
+		span.StartLine = 1
+	}
 
 	return nil
 }
 
 // startJSONWriter handles JSON format output with streaming support.
-func startJSONWriter(outFile *os.File, writeCh <-chan WriteRequest, done chan<- struct{}, prefix, suffix string) {
-	startFormatWriter(outFile, writeCh, done, prefix, suffix, func(f *os.File) FormatWriter {
-		return NewJSONWriter(f)
+func startJSONWriter(
+	outFile *os.File,
+	writeCh <-chan WriteRequest,
+	done chan<- struct{},
+	prefix, suffix string,
+	opts WriterOptions,
+) {
+	startFormatWriter(outFile, writeCh, done, prefix, suffix, opts, func(out outputWriter) FormatWriter {
+		return newJSONWriter(out, opts.registry(), opts.settings())
 	})
 }
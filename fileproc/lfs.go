@@ -0,0 +1,82 @@
+// Package fileproc handles file processing, collection, and output formatting.
+package fileproc
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/ivuorinen/gibidify/config"
+	"github.com/ivuorinen/gibidify/gitutil"
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// LFSPointerReason is the skip reason of Git LFS pointer files, used in the metrics report.
+const LFSPointerReason = "lfs pointer"
+
+// LFSFilter finds Git LFS pointer files, which would otherwise be bundled as three-line stubs
+// in place of the content they stand for, and fetches the objects they name per git.lfs.action.
+type LFSFilter struct {
+	action       string
+	maxFetchSize int64
+}
+
+// NewLFSFilter creates a filter with the current configuration. Objects are only fetched with
+// git integration enabled; otherwise pointers are skipped.
+func NewLFSFilter() *LFSFilter {
+	return newLFSFilter(config.Global())
+}
+
+// newLFSFilter creates a filter with the configuration in settings.
+func newLFSFilter(settings *config.Settings) *LFSFilter {
+	action := settings.GitLFSAction()
+	if action == shared.LFSActionFetch && !settings.GitEnabled() {
+		action = shared.LFSActionSkip
+	}
+
+	return &LFSFilter{action: action, maxFetchSize: settings.GitLFSMaxFetchSize()}
+}
+
+// Inspect returns the pointer and the content of the file at filePath when it is a Git LFS
+// pointer to skip or fetch, or nil for ordinary files and when pointers are bundled as text.
+func (f *LFSFilter) Inspect(filePath string, size int64) (*gitutil.LFSPointer, []byte) {
+	if f.action == shared.LFSActionInclude || size > gitutil.LFSPointerMaxSize {
+		return nil, nil
+	}
+
+	content, err := readLimited(filePath)
+	if err != nil {
+		// Read errors surface again, with full context, when the file is processed
+		return nil, nil
+	}
+	pointer, ok := gitutil.ParseLFSPointer(content)
+	if !ok {
+		return nil, nil
+	}
+
+	return &pointer, content
+}
+
+// Fetch returns the content of the object pointer names, given the content of the pointer file
+// at filePath, or why it was not fetched.
+func (f *LFSFilter) Fetch(filePath string, pointerFile []byte, pointer gitutil.LFSPointer) ([]byte, string) {
+	switch {
+	case f.action != shared.LFSActionFetch:
+		return nil, fmt.Sprintf("object of %d bytes not fetched", pointer.Size)
+	case pointer.Size > f.maxFetchSize:
+		return nil, fmt.Sprintf("object of %d bytes over git.lfs.maxFetchSize", pointer.Size)
+	}
+
+	content, err := gitutil.Git{}.LFSSmudge(filepath.Dir(filePath), pointerFile)
+	if err != nil {
+		return nil, "fetching failed: " + err.Error()
+	}
+	if _, ok := gitutil.ParseLFSPointer(content); ok {
+		// git lfs smudge passes the pointer through when GIT_LFS_SKIP_SMUDGE is set
+		return nil, "object not available"
+	}
+	if reason := SniffBinary(content[:min(len(content), shared.ContentSniffSampleSize)]); reason != "" {
+		return nil, "binary object, " + reason
+	}
+
+	return content, ""
+}
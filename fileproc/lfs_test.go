@@ -0,0 +1,83 @@
+package fileproc_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/fileproc"
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// TestFileProcessorLFSPointer tests that Git LFS pointers are skipped, bundled as text or only
+// fetched under git.lfs.maxFetchSize with git integration, per git.lfs.action.
+func TestFileProcessorLFSPointer(t *testing.T) {
+	dir := t.TempDir()
+	pointer := "version https://git-lfs.github.com/spec/v1\noid sha256:" + strings.Repeat("ab", 32) + "\nsize 4096\n"
+	filePath := testutil.CreateTestFile(t, dir, "model.txt", []byte(pointer))
+
+	tests := []struct {
+		name       string
+		settings   map[string]any
+		offline    bool
+		wantDetail string
+	}{
+		{name: "skip", wantDetail: "object of 4096 bytes not fetched"},
+		{
+			name:     "include",
+			settings: map[string]any{shared.ConfigKeyGitLFSAction: shared.LFSActionInclude},
+		},
+		{
+			name: "fetch over the limit",
+			settings: map[string]any{
+				shared.ConfigKeyGitLFSAction: shared.LFSActionFetch, shared.ConfigKeyGitLFSMaxFetchSize: 1024,
+			},
+			wantDetail: "object of 4096 bytes over git.lfs.maxFetchSize",
+		},
+		{
+			name: "fetch without git",
+			settings: map[string]any{
+				shared.ConfigKeyGitLFSAction: shared.LFSActionFetch, shared.ConfigKeyGitEnabled: false,
+			},
+			wantDetail: "object of 4096 bytes not fetched",
+		},
+		{
+			name:       "fetch offline",
+			settings:   map[string]any{shared.ConfigKeyGitLFSAction: shared.LFSActionFetch},
+			offline:    true,
+			wantDetail: "object of 4096 bytes not fetched",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testutil.ResetViperConfig(t, "")
+			testutil.SetViperKeys(t, tt.settings)
+			outCh := make(chan fileproc.WriteRequest, 1)
+			processor := fileproc.NewFileProcessor(dir)
+			processor.SetOffline(tt.offline)
+			err := processor.ProcessWithContext(context.Background(), filePath, outCh)
+
+			if tt.wantDetail == "" {
+				testutil.MustSucceed(t, err, "processing")
+				if req := <-outCh; !strings.Contains(req.Content, pointer) {
+					t.Errorf("content = %q, want the pointer text", req.Content)
+				}
+
+				return
+			}
+			var structErr *shared.StructuredError
+			if !errors.As(err, &structErr) || structErr.Code != shared.CodeValidationLFS {
+				t.Fatalf("expected Git LFS pointer error, got %v", err)
+			}
+			if !strings.Contains(structErr.Message, tt.wantDetail) || structErr.Context["reason"] != fileproc.LFSPointerReason {
+				t.Errorf("error = %v with context %v, want %q", structErr, structErr.Context, tt.wantDetail)
+			}
+			if len(outCh) != 0 {
+				t.Errorf("%d entries queued for a skipped pointer", len(outCh))
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+// Package fileproc handles file processing, collection, and output formatting.
+package fileproc
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// DetectLineEndings reports the line-ending style of content: lf, crlf, cr, mixed,
+// or "" when content has no line breaks.
+func DetectLineEndings(content []byte) string {
+	style := ""
+	for i := 0; i < len(content); i++ {
+		current := ""
+		switch {
+		case content[i] == '\r' && i+1 < len(content) && content[i+1] == '\n':
+			current = shared.LineEndingsCRLF
+			i++
+		case content[i] == '\r':
+			current = shared.LineEndingsCR
+		case content[i] == '\n':
+			current = shared.LineEndingsLF
+		default:
+			continue
+		}
+		if style != "" && style != current {
+			return shared.LineEndingsMixed
+		}
+		style = current
+	}
+
+	return style
+}
+
+// NormalizeLineEndings converts every line break in content to the target style (lf or crlf).
+// Any other target returns content unchanged.
+func NormalizeLineEndings(content, target string) string {
+	newline, ok := lineBreak(target)
+	if !ok {
+		return content
+	}
+
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	content = strings.ReplaceAll(content, "\r", "\n")
+	if newline == "\n" {
+		return content
+	}
+
+	return strings.ReplaceAll(content, "\n", newline)
+}
+
+// lineBreak returns the line break written for target, and false when target does not normalize.
+func lineBreak(target string) (string, bool) {
+	switch target {
+	case shared.LineEndingsLF:
+		return "\n", true
+	case shared.LineEndingsCRLF:
+		return "\r\n", true
+	default:
+		return "", false
+	}
+}
+
+// lineEndingReader normalizes line breaks while streaming, handling "\r\n" pairs split across reads.
+type lineEndingReader struct {
+	src     *bufio.Reader
+	newline string
+	pending []byte
+}
+
+// newLineEndingReader wraps src so every line break is read as the target style.
+// src is returned unchanged when target does not normalize.
+func newLineEndingReader(src io.Reader, target string) io.Reader {
+	newline, ok := lineBreak(target)
+	if !ok {
+		return src
+	}
+	buffered, isBuffered := src.(*bufio.Reader)
+	if !isBuffered {
+		buffered = bufio.NewReaderSize(src, shared.FileProcessingStreamChunkSize)
+	}
+
+	return &lineEndingReader{src: buffered, newline: newline}
+}
+
+// Read implements io.Reader.
+func (r *lineEndingReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if len(r.pending) > 0 {
+			copied := copy(p[n:], r.pending)
+			r.pending = r.pending[copied:]
+			n += copied
+
+			continue
+		}
+
+		b, err := r.src.ReadByte()
+		if err != nil {
+			if n > 0 && err == io.EOF {
+				return n, nil
+			}
+
+			return n, err //nolint:wrapcheck // EOF must not be wrapped
+		}
+		if b != '\r' && b != '\n' {
+			p[n] = b
+			n++
+
+			continue
+		}
+		if b == '\r' {
+			if next, err := r.src.ReadByte(); err == nil && next != '\n' {
+				_ = r.src.UnreadByte()
+			}
+		}
+		r.pending = []byte(r.newline)
+	}
+
+	return n, nil
+}
@@ -0,0 +1,92 @@
+package fileproc_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/fileproc"
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// TestDetectLineEndings tests line-ending style detection.
+func TestDetectLineEndings(t *testing.T) {
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{"a\nb\n", shared.LineEndingsLF},
+		{"a\r\nb\r\n", shared.LineEndingsCRLF},
+		{"a\rb\r", shared.LineEndingsCR},
+		{"a\r\nb\n", shared.LineEndingsMixed},
+		{"single line", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := fileproc.DetectLineEndings([]byte(tt.content)); got != tt.want {
+			t.Errorf("DetectLineEndings(%q) = %q, want %q", tt.content, got, tt.want)
+		}
+	}
+}
+
+// TestNormalizeLineEndings tests conversion to each target style.
+func TestNormalizeLineEndings(t *testing.T) {
+	content := "a\r\nb\rc\nd"
+
+	tests := []struct {
+		target string
+		want   string
+	}{
+		{shared.LineEndingsLF, "a\nb\nc\nd"},
+		{shared.LineEndingsCRLF, "a\r\nb\r\nc\r\nd"},
+		{shared.LineEndingsPreserve, content},
+	}
+
+	for _, tt := range tests {
+		if got := fileproc.NormalizeLineEndings(content, tt.target); got != tt.want {
+			t.Errorf("NormalizeLineEndings(%q) = %q, want %q", tt.target, got, tt.want)
+		}
+	}
+}
+
+// TestFileProcessorNormalizesLineEndings tests normalization of in-memory and streamed files
+// and that the original style is recorded in metadata.
+func TestFileProcessorNormalizesLineEndings(t *testing.T) {
+	testutil.SetViperKeys(t, map[string]any{shared.ConfigKeyOutputNormalizeLineEndings: shared.LineEndingsLF})
+
+	dir := t.TempDir()
+	line := "fmt.Println(\"windows\")\r\n"
+	small := testutil.CreateTestFile(t, dir, "small.go", []byte(strings.Repeat(line, 3)))
+	large := testutil.CreateTestFile(t, dir, "large.go",
+		[]byte(strings.Repeat(line, shared.FileProcessingStreamThreshold/len(line)+1)))
+	unix := testutil.CreateTestFile(t, dir, "unix.go", []byte("package main\n"))
+
+	processor := fileproc.NewFileProcessor(dir)
+	for _, path := range []string{small, large} {
+		outCh := make(chan fileproc.WriteRequest, 1)
+		testutil.MustSucceed(t, processor.ProcessWithContext(context.Background(), path, outCh), "processing "+path)
+		req := <-outCh
+
+		content := req.Content
+		if req.IsStream {
+			data, err := io.ReadAll(req.Reader)
+			testutil.MustSucceed(t, err, "reading stream")
+			content = string(data)
+		}
+		if strings.Contains(content, "\r") {
+			t.Errorf("%s: carriage returns left after normalization", path)
+		}
+		if req.Metadata[shared.MetadataKeyLineEndings] != shared.LineEndingsCRLF {
+			t.Errorf("%s: original style not recorded: %v", path, req.Metadata)
+		}
+	}
+
+	outCh := make(chan fileproc.WriteRequest, 1)
+	testutil.MustSucceed(t, processor.ProcessWithContext(context.Background(), unix, outCh), "processing unix.go")
+	if req := <-outCh; len(req.Metadata) != 0 {
+		t.Errorf("unchanged file should carry no line-ending note: %v", req.Metadata)
+	}
+}
@@ -0,0 +1,153 @@
+// Package fileproc handles file processing, collection, and output formatting.
+package fileproc
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/ivuorinen/gibidify/config"
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// markdownSectionHeader starts every file section of a Markdown bundle.
+const markdownSectionHeader = "## File: `"
+
+// BundleManifest maps the path of every file section in a bundle to a hash of the section.
+type BundleManifest map[string][sha256.Size]byte
+
+// ManifestDiff lists the paths one bundle adds, removes and changes relative to another.
+type ManifestDiff struct {
+	Added   []string
+	Removed []string
+	Changed []string
+}
+
+// Empty reports whether the bundles hold the same files with the same content.
+func (d ManifestDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// ReadBundleManifest reads the manifest of the bundle at path written in format. Markdown and
+// plain sections are hashed with their metadata; JSON and YAML entries by their content.
+func ReadBundleManifest(path, format string) (BundleManifest, error) {
+	return ReadBundleManifestWithSettings(path, format, config.Global())
+}
+
+// ReadBundleManifestWithSettings works like ReadBundleManifest for a bundle written with the
+// configuration in settings, which names the delimiter of plain bundles.
+func ReadBundleManifestWithSettings(path, format string, settings *config.Settings) (BundleManifest, error) {
+	file, err := os.Open(path) // #nosec G304 -- path is a bundle the caller is about to overwrite
+	if err != nil {
+		return nil, shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIORead, "failed to open bundle").
+			WithFilePath(path)
+	}
+	defer shared.SafeCloseReader(file, path)
+
+	switch format {
+	case shared.FormatMarkdown:
+		return readSectionManifest(file, markdownSectionPath)
+	case shared.FormatPlain:
+		delimiter := plainDelimiter(settings)
+
+		return readSectionManifest(file, func(line string) (string, bool) {
+			return plainSectionPath(delimiter, line)
+		})
+	}
+
+	var bundle OutputData
+	if format == shared.FormatYAML {
+		err = yaml.NewDecoder(file).Decode(&bundle)
+	} else {
+		err = json.NewDecoder(file).Decode(&bundle)
+	}
+	if err != nil {
+		return nil, shared.WrapError(
+			err, shared.ErrorTypeProcessing, shared.CodeProcessingEncode, "failed to decode "+format+" bundle",
+		).WithFilePath(path)
+	}
+
+	manifest := make(BundleManifest, len(bundle.Files))
+	for _, entry := range bundle.Files {
+		manifest[entry.Path] = sha256.Sum256([]byte(entry.Content))
+	}
+
+	return manifest, nil
+}
+
+// markdownSectionPath returns the path named by a "## File:" header line, and whether line is one.
+func markdownSectionPath(line string) (string, bool) {
+	header, isHeader := strings.CutPrefix(line, markdownSectionHeader)
+	if !isHeader || !strings.HasSuffix(header, "`") {
+		return "", false
+	}
+
+	return strings.TrimSuffix(header, "`"), true
+}
+
+// readSectionManifest hashes every section of a Markdown or plain bundle up to the next one.
+// sectionPath recognizes the header lines starting sections; content lines that look like one
+// are taken as the start of a new section.
+func readSectionManifest(file *os.File, sectionPath func(line string) (string, bool)) (BundleManifest, error) {
+	manifest := make(BundleManifest)
+	reader := bufio.NewReaderSize(file, shared.FileProcessingStreamChunkSize)
+
+	var path string
+	var section strings.Builder
+	for {
+		line, err := reader.ReadString('\n')
+		if header, isHeader := sectionPath(strings.TrimSuffix(line, "\n")); isHeader {
+			addManifestSection(manifest, path, section.String())
+			section.Reset()
+			path = header
+		} else {
+			section.WriteString(line)
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIORead, "failed to read bundle").
+				WithFilePath(file.Name())
+		}
+	}
+	addManifestSection(manifest, path, section.String())
+
+	return manifest, nil
+}
+
+// addManifestSection hashes the section of path into manifest; text before the first section is skipped.
+func addManifestSection(manifest BundleManifest, path, section string) {
+	if path != "" {
+		manifest[path] = sha256.Sum256([]byte(section))
+	}
+}
+
+// Diff returns the paths m adds, removes and changes relative to previous, each sorted.
+func (m BundleManifest) Diff(previous BundleManifest) ManifestDiff {
+	var diff ManifestDiff
+	for _, path := range slices.Sorted(maps.Keys(m)) {
+		old, ok := previous[path]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, path)
+		case old != m[path]:
+			diff.Changed = append(diff.Changed, path)
+		}
+	}
+	for _, path := range slices.Sorted(maps.Keys(previous)) {
+		if _, ok := m[path]; !ok {
+			diff.Removed = append(diff.Removed, path)
+		}
+	}
+
+	return diff
+}
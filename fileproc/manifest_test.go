@@ -0,0 +1,61 @@
+package fileproc_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/fileproc"
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// writeManifestBundle writes the files in format with the bundle writer and returns the path.
+func writeManifestBundle(t *testing.T, format string, files map[string]string) string {
+	t.Helper()
+
+	outFile, path := testutil.CreateTempOutputFile(t, "manifest_*."+format)
+	writeCh := make(chan fileproc.WriteRequest, len(files))
+	for _, name := range []string{"keep.go", "edit.go", "old.go", "new.go"} {
+		if content, ok := files[name]; ok {
+			writeCh <- fileproc.WriteRequest{Path: name, Content: content}
+		}
+	}
+	close(writeCh)
+
+	done := make(chan struct{})
+	fileproc.StartWriter(outFile, writeCh, done, format, "Start", "End")
+	<-done
+	testutil.CloseFile(t, outFile)
+
+	return path
+}
+
+func TestBundleManifestDiff(t *testing.T) {
+	previous := map[string]string{"keep.go": "package keep\n", "edit.go": "package edit\n", "old.go": "package old\n"}
+	current := map[string]string{"keep.go": "package keep\n", "edit.go": "package edited\n", "new.go": "package new\n"}
+
+	for _, format := range []string{shared.FormatMarkdown, shared.FormatJSON, shared.FormatYAML, shared.FormatPlain} {
+		t.Run(format, func(t *testing.T) {
+			old, err := fileproc.ReadBundleManifest(writeManifestBundle(t, format, previous), format)
+			testutil.MustSucceed(t, err, "reading previous manifest")
+			cur, err := fileproc.ReadBundleManifest(writeManifestBundle(t, format, current), format)
+			testutil.MustSucceed(t, err, "reading current manifest")
+
+			diff := cur.Diff(old)
+			if !slices.Equal(diff.Added, []string{"new.go"}) || !slices.Equal(diff.Removed, []string{"old.go"}) ||
+				!slices.Equal(diff.Changed, []string{"edit.go"}) {
+				t.Errorf("diff = %+v, want new.go added, old.go removed and edit.go changed", diff)
+			}
+			if !cur.Diff(cur).Empty() {
+				t.Errorf("diff of a manifest with itself = %+v, want empty", cur.Diff(cur))
+			}
+		})
+	}
+}
+
+func TestReadBundleManifestInvalid(t *testing.T) {
+	path := testutil.CreateTestFile(t, t.TempDir(), "bundle.json", []byte("not json"))
+	if _, err := fileproc.ReadBundleManifest(path, shared.FormatJSON); err == nil {
+		t.Error("ReadBundleManifest succeeded on an invalid bundle, want an error")
+	}
+}
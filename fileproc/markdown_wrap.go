@@ -0,0 +1,148 @@
+// Package fileproc handles file processing, collection, and output formatting.
+package fileproc
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ivuorinen/gibidify/config"
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// markdownWrap breaks the lines of markdown file sections that are longer than
+// output.markdown.maxLineLength characters, for targets that mangle long lines.
+type markdownWrap struct {
+	limit  int
+	code   bool
+	marker []byte
+}
+
+// newMarkdownWrap creates a wrap with the configuration in settings.
+func newMarkdownWrap(settings *config.Settings) markdownWrap {
+	return markdownWrap{
+		limit:  settings.TemplateMarkdownMaxLineLength(),
+		code:   settings.TemplateMarkdownWrapCode(),
+		marker: []byte(settings.TemplateMarkdownWrapMarker()),
+	}
+}
+
+// lineRule returns how lines of a file in language are wrapped, or nil when they are kept.
+// Prose (markdown, reStructuredText and files without a language) is soft-wrapped at spaces;
+// code is hard-wrapped only with output.markdown.wrapCode.
+func (w markdownWrap) lineRule(language string) func(line []byte) []byte {
+	switch {
+	case w.limit <= 0:
+		return nil
+	case language == "" || language == shared.FormatMarkdown || language == "rst":
+		return w.softWrap
+	case w.code && utf8.RuneCount(w.marker) < w.limit:
+		return w.hardWrap
+	default:
+		return nil
+	}
+}
+
+// apply wraps the in-memory content of a file in language.
+func (w markdownWrap) apply(content, language string) string {
+	rule := w.lineRule(language)
+	if rule == nil {
+		return content
+	}
+
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		lines[i] = string(rule([]byte(line)))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// reader wraps the streamed content of a file in language.
+func (w markdownWrap) reader(src io.Reader, language string) io.Reader {
+	rule := w.lineRule(language)
+	if rule == nil {
+		return src
+	}
+
+	return newLineReader(src, rule)
+}
+
+// softWrap breaks line at the last space that keeps each part within the limit, indenting the
+// continuations like the line. Words longer than the limit are left whole on a line of their own.
+func (w markdownWrap) softWrap(line []byte) []byte {
+	if utf8.RuneCount(line) <= w.limit {
+		return line
+	}
+	body, eol := splitCR(line)
+	indent := body[:len(body)-len(bytes.TrimLeft(body, " \t"))]
+
+	var out []byte
+	for utf8.RuneCount(body) > w.limit {
+		cut := lastSpaceWithin(body, w.limit)
+		if cut <= len(indent) {
+			cut = bytes.IndexByte(body[len(indent):], ' ') + len(indent)
+		}
+		if cut <= len(indent) {
+			break
+		}
+		out = append(out, bytes.TrimRight(body[:cut], " ")...)
+		out = append(out, eol...)
+		out = append(out, '\n')
+		body = append(append([]byte{}, indent...), bytes.TrimLeft(body[cut:], " ")...)
+	}
+
+	return append(append(out, body...), eol...)
+}
+
+// hardWrap breaks line into parts of the limit, each but the last ending in the marker, so that
+// removing the markers and the breaks after them restores the line.
+func (w markdownWrap) hardWrap(line []byte) []byte {
+	if utf8.RuneCount(line) <= w.limit {
+		return line
+	}
+	body, eol := splitCR(line)
+	width := w.limit - utf8.RuneCount(w.marker)
+
+	var out []byte
+	for utf8.RuneCount(body) > w.limit {
+		cut := runeOffset(body, width)
+		out = append(out, body[:cut]...)
+		out = append(out, w.marker...)
+		out = append(out, eol...)
+		out = append(out, '\n')
+		body = body[cut:]
+	}
+
+	return append(append(out, body...), eol...)
+}
+
+// splitCR splits a trailing "\r" off line.
+func splitCR(line []byte) (body, eol []byte) {
+	if body, found := bytes.CutSuffix(line, []byte{'\r'}); found {
+		return body, []byte{'\r'}
+	}
+
+	return line, nil
+}
+
+// lastSpaceWithin returns the byte offset of the last space among the first limit+1 characters
+// of line, so that the text before it fits the limit, or -1 when there is none.
+func lastSpaceWithin(line []byte, limit int) int {
+	return bytes.LastIndexByte(line[:runeOffset(line, limit+1)], ' ')
+}
+
+// runeOffset returns the byte offset of the first n characters of line.
+func runeOffset(line []byte, n int) int {
+	offset := 0
+	for range n {
+		if offset >= len(line) {
+			break
+		}
+		_, size := utf8.DecodeRune(line[offset:])
+		offset += size
+	}
+
+	return offset
+}
@@ -0,0 +1,74 @@
+package fileproc_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/fileproc"
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+func TestMarkdownWrap(t *testing.T) {
+	const prose = "  The quick brown fox jumps over the lazy dog\r\nSupercalifragilistic word\r\n"
+	const code = "x := \"ÄÖÜäöü0123456789\"\nshort()\n"
+
+	tests := []struct {
+		name     string
+		settings map[string]any
+		path     string
+		content  string
+		want     string
+	}{
+		{
+			name:     "disabled",
+			settings: map[string]any{shared.ConfigKeyOutputMarkdownMaxLineLen: 0},
+			path:     "notes.txt",
+			content:  prose,
+			want:     prose,
+		},
+		{
+			name:     "prose soft-wrapped with indentation",
+			settings: map[string]any{shared.ConfigKeyOutputMarkdownMaxLineLen: 16},
+			path:     "notes.txt",
+			content:  prose,
+			want: "  The quick\r\n  brown fox\r\n  jumps over the\r\n  lazy dog\r\n" +
+				"Supercalifragilistic\r\nword\r\n",
+		},
+		{
+			name:     "code kept without wrapCode",
+			settings: map[string]any{shared.ConfigKeyOutputMarkdownMaxLineLen: 10},
+			path:     "main.go",
+			content:  code,
+			want:     code,
+		},
+		{
+			name: "code hard-wrapped by characters",
+			settings: map[string]any{
+				shared.ConfigKeyOutputMarkdownMaxLineLen: 10,
+				shared.ConfigKeyOutputMarkdownWrapCode:   true,
+				shared.ConfigKeyOutputMarkdownWrapMarker: "\\",
+			},
+			path:    "main.go",
+			content: code,
+			want:    "x := \"ÄÖÜ\\\näöü012345\\\n6789\"\nshort()\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testutil.SetViperKeys(t, tt.settings)
+
+			for _, stream := range []bool{false, true} {
+				req := fileproc.WriteRequest{Path: tt.path, Content: tt.content}
+				if stream {
+					req = fileproc.WriteRequest{Path: tt.path, IsStream: true, Reader: strings.NewReader(tt.content)}
+				}
+				output := string(writeWithFormatWorkers(t, shared.FormatMarkdown, 1, []fileproc.WriteRequest{req}))
+				if !strings.Contains(output, "\n"+tt.want) {
+					t.Errorf("stream %v: output does not contain %q:\n%q", stream, tt.want, output)
+				}
+			}
+		})
+	}
+}
@@ -2,21 +2,42 @@
 package fileproc
 
 import (
+	"bufio"
 	"fmt"
 	"os"
+	"strings"
 
+	"github.com/ivuorinen/gibidify/config"
 	"github.com/ivuorinen/gibidify/shared"
 )
 
 // MarkdownWriter handles Markdown format output with streaming support.
 type MarkdownWriter struct {
-	outFile *os.File
-	suffix  string
+	outFile  outputWriter
+	suffix   string
+	registry *FileTypeRegistry
+	// placeholder is the output.markdown.sectionPlaceholder block written after every file section.
+	placeholder string
+	// hashAlgorithm hashes the {{id}} of the placeholder.
+	hashAlgorithm string
+	wrap          markdownWrap
+	warn          WarningHook
 }
 
-// NewMarkdownWriter creates a new markdown writer.
+// NewMarkdownWriter creates a new markdown writer detecting languages with the default registry.
 func NewMarkdownWriter(outFile *os.File) *MarkdownWriter {
-	return &MarkdownWriter{outFile: outFile}
+	return newMarkdownWriter(outFile, getRegistry(), config.Global())
+}
+
+// newMarkdownWriter creates a markdown writer for any output.
+func newMarkdownWriter(out outputWriter, registry *FileTypeRegistry, settings *config.Settings) *MarkdownWriter {
+	return &MarkdownWriter{
+		outFile:       out,
+		registry:      registry,
+		placeholder:   strings.TrimRight(settings.TemplateMarkdownSectionPlaceholder(), "\n"),
+		hashAlgorithm: settings.PerformanceHashAlgorithm(),
+		wrap:          newMarkdownWrap(settings),
+	}
 }
 
 // Start writes the markdown header and stores the suffix for later use.
@@ -33,6 +54,19 @@ func (w *MarkdownWriter) Start(prefix, suffix string) error {
 	return nil
 }
 
+// StartWithConfig writes settings in a Markdown comment, then the markdown header.
+func (w *MarkdownWriter) StartWithConfig(prefix, suffix string, settings map[string]any) error {
+	encoded, err := encodeConfigProvenance(settings)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w.outFile, "<!-- %s%s -->\n\n", configProvenancePrefix, encoded); err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOWrite, "failed to write config provenance")
+	}
+
+	return w.Start(prefix, suffix)
+}
+
 // WriteFile writes a file entry in Markdown format.
 func (w *MarkdownWriter) WriteFile(req WriteRequest) error {
 	if req.IsStream {
@@ -53,14 +87,37 @@ func (w *MarkdownWriter) Close() error {
 	return nil
 }
 
+// CloseWithSummary writes the markdown footer followed by a run summary section.
+func (w *MarkdownWriter) CloseWithSummary(summary RunSummary) error {
+	if err := w.Close(); err != nil {
+		return err
+	}
+	section, err := summary.markdown()
+	if err != nil {
+		return err
+	}
+	if _, err := w.outFile.WriteString(section); err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOWrite, "failed to write run summary")
+	}
+
+	return nil
+}
+
 // writeStreaming writes a large file in streaming chunks.
 func (w *MarkdownWriter) writeStreaming(req WriteRequest) error {
 	defer shared.SafeCloseReader(req.Reader, req.Path)
 
-	language := detectLanguage(req.Path)
+	language := entryLanguage(req, w.registry)
+
+	// Choose the fence from the first chunk, which is then streamed from the buffer
+	chunkSize := shared.FileProcessingStreamChunkSize
+	src := bufio.NewReaderSize(w.wrap.reader(req.Reader, language), chunkSize)
+	sample, _ := src.Peek(chunkSize) // shorter content is peeked whole
+	fence := markdownFenceFor(string(sample))
 
 	// Write file header
-	if _, err := fmt.Fprintf(w.outFile, "## File: `%s`\n```%s\n", req.Path, language); err != nil {
+	header := "## File: `" + req.Path + "`\n" + formatMarkdownMetadata(req.Metadata)
+	if _, err := fmt.Fprintf(w.outFile, "%s%s%s\n", header, fence, language); err != nil {
 		return shared.WrapError(
 			err,
 			shared.ErrorTypeIO,
@@ -70,13 +127,13 @@ func (w *MarkdownWriter) writeStreaming(req WriteRequest) error {
 	}
 
 	// Stream file content in chunks
-	chunkSize := shared.FileProcessingStreamChunkSize
-	if err := shared.StreamContent(req.Reader, w.outFile, chunkSize, req.Path, nil); err != nil {
+	content := fenceWarningReader(src, w.warn, req.Path, fence)
+	if err := shared.StreamContent(content, w.outFile, chunkSize, req.Path, nil); err != nil {
 		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOWrite, "streaming content for markdown file")
 	}
 
 	// Write file footer
-	if _, err := w.outFile.WriteString("\n```\n\n"); err != nil {
+	if _, err := w.outFile.WriteString("\n" + fence + "\n\n" + w.sectionPlaceholder(req, language)); err != nil {
 		return shared.WrapError(
 			err,
 			shared.ErrorTypeIO,
@@ -90,10 +147,13 @@ func (w *MarkdownWriter) writeStreaming(req WriteRequest) error {
 
 // writeInline writes a small file directly from content.
 func (w *MarkdownWriter) writeInline(req WriteRequest) error {
-	language := detectLanguage(req.Path)
-	formatted := fmt.Sprintf("## File: `%s`\n```%s\n%s\n```\n\n", req.Path, language, req.Content)
+	formatted, err := renderedEntry(req, w)
+	if err != nil {
+		return err
+	}
+	defer shared.ScratchBuffers.Put(formatted)
 
-	if _, err := w.outFile.WriteString(formatted); err != nil {
+	if _, err := w.outFile.Write(*formatted); err != nil {
 		return shared.WrapError(
 			err,
 			shared.ErrorTypeIO,
@@ -105,9 +165,62 @@ func (w *MarkdownWriter) writeInline(req WriteRequest) error {
 	return nil
 }
 
+// renderInline renders a small file as a markdown section, fenced with a fence longer than any
+// starting a line of its content.
+func (w *MarkdownWriter) renderInline(dst []byte, req WriteRequest) ([]byte, error) {
+	language := entryLanguage(req, w.registry)
+	content := w.wrap.apply(req.Content, language)
+	fence := markdownFenceFor(content)
+
+	return fmt.Appendf(
+		dst, "## File: `%s`\n%s%s%s\n%s\n%s\n\n%s",
+		req.Path, formatMarkdownMetadata(req.Metadata), fence, language, content, fence,
+		w.sectionPlaceholder(req, language),
+	), nil
+}
+
+// sectionPlaceholder returns the placeholder block following the section of req, with {{path}},
+// {{language}} and {{id}} filled in. Prelude, patch and pull request entries get none.
+func (w *MarkdownWriter) sectionPlaceholder(req WriteRequest, language string) string {
+	if w.placeholder == "" || req.Metadata[shared.MetadataKeyRole] != "" {
+		return ""
+	}
+
+	return strings.NewReplacer(
+		"{{path}}", req.Path,
+		"{{language}}", language,
+		"{{id}}", fileID(w.hashAlgorithm, req.Path),
+	).Replace(w.placeholder) + "\n\n"
+}
+
+// formatMarkdownMetadata renders file metadata as blockquote lines placed between the header and the code block.
+// Multi-line values, such as notes, continue on further blockquote lines.
+func formatMarkdownMetadata(meta map[string]string) string {
+	if len(meta) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	for _, key := range sortedMetadataKeys(meta) {
+		fmt.Fprintf(&b, "> %s: %s\n", key, strings.ReplaceAll(meta[key], "\n", "\n> "))
+	}
+	b.WriteString("\n")
+
+	return b.String()
+}
+
 // startMarkdownWriter handles Markdown format output with streaming support.
-func startMarkdownWriter(outFile *os.File, writeCh <-chan WriteRequest, done chan<- struct{}, prefix, suffix string) {
-	startFormatWriter(outFile, writeCh, done, prefix, suffix, func(f *os.File) FormatWriter {
-		return NewMarkdownWriter(f)
+func startMarkdownWriter(
+	outFile *os.File,
+	writeCh <-chan WriteRequest,
+	done chan<- struct{},
+	prefix, suffix string,
+	opts WriterOptions,
+) {
+	startFormatWriter(outFile, writeCh, done, prefix, suffix, opts, func(out outputWriter) FormatWriter {
+		writer := newMarkdownWriter(out, opts.registry(), opts.settings())
+		writer.warn = opts.Warning
+
+		return writer
 	})
 }
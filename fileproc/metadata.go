@@ -0,0 +1,61 @@
+// Package fileproc handles file processing, collection, and output formatting.
+package fileproc
+
+import (
+	"maps"
+	"path/filepath"
+	"slices"
+
+	"github.com/ivuorinen/gibidify/config"
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// Annotator computes per-file metadata entries attached to each WriteRequest.
+// filePath is the absolute path on disk, relPath the path shown in the output.
+// Returning nil or an empty map adds nothing.
+type Annotator func(filePath, relPath string) map[string]string
+
+// annotate runs all annotators for a file and merges their results.
+// Later annotators override keys set by earlier ones.
+func annotate(annotators []Annotator, filePath, relPath string) map[string]string {
+	var meta map[string]string
+	for _, annotator := range annotators {
+		entries := annotator(filePath, relPath)
+		if len(entries) == 0 {
+			continue
+		}
+		if meta == nil {
+			meta = make(map[string]string, len(entries))
+		}
+		maps.Copy(meta, entries)
+	}
+
+	return meta
+}
+
+// sortedMetadataKeys returns the metadata keys in deterministic order for rendering.
+func sortedMetadataKeys(meta map[string]string) []string {
+	return slices.Sorted(maps.Keys(meta))
+}
+
+// FileID returns the stable short ID of the entry at path. It depends only on the
+// slash-separated path and performance.hashAlgorithm, so the same file gets the same ID
+// in every format and run.
+func FileID(path string) string {
+	return fileID(config.PerformanceHashAlgorithm(), path)
+}
+
+// fileID returns the file ID of path hashed with algorithm.
+func fileID(algorithm, path string) string {
+	return shared.ShortChecksum(algorithm, []byte(filepath.ToSlash(path)), shared.FileIDBytes)
+}
+
+// withFileID returns a copy of req whose metadata includes its file ID hashed with algorithm.
+func withFileID(req WriteRequest, algorithm string) WriteRequest {
+	meta := make(map[string]string, len(req.Metadata)+1)
+	maps.Copy(meta, req.Metadata)
+	meta[shared.MetadataKeyID] = fileID(algorithm, req.Path)
+	req.Metadata = meta
+
+	return req
+}
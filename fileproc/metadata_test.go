@@ -0,0 +1,149 @@
+package fileproc_test
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/ivuorinen/gibidify/fileproc"
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// writeMetadataRequest runs a single metadata-carrying request through the writer for format.
+func writeMetadataRequest(t *testing.T, format string, req fileproc.WriteRequest) []byte {
+	t.Helper()
+	outFile, path := testutil.CreateTempOutputFile(t, "metadata_*")
+	writeCh := make(chan fileproc.WriteRequest, 1)
+	done := make(chan struct{})
+
+	writeCh <- req
+	close(writeCh)
+	fileproc.StartWriter(outFile, writeCh, done, format, "", "")
+	<-done
+	testutil.CloseFile(t, outFile)
+
+	data, err := os.ReadFile(path)
+	testutil.MustSucceed(t, err, "reading output")
+
+	return data
+}
+
+// TestWritersRenderMetadata tests that every writer renders per-file metadata for inline and streamed files.
+func TestWritersRenderMetadata(t *testing.T) {
+	meta := map[string]string{shared.MetadataKeyOwners: "@org/core @alice"}
+
+	for _, stream := range []bool{false, true} {
+		req := fileproc.WriteRequest{Path: "main.go", Content: shared.LiteralPackageMain, Metadata: meta}
+		if stream {
+			req = fileproc.WriteRequest{
+				Path: "main.go", IsStream: true, Reader: strings.NewReader(shared.LiteralPackageMain), Metadata: meta,
+			}
+		}
+
+		verifyJSONMetadata(t, writeMetadataRequest(t, shared.FormatJSON, req), meta)
+		verifyYAMLMetadata(t, writeMetadataRequest(t, shared.FormatYAML, req), meta)
+
+		markdown := string(writeMetadataRequest(t, shared.FormatMarkdown, req))
+		if !strings.Contains(markdown, "> owners: @org/core @alice\n") {
+			t.Errorf("markdown output missing metadata (stream=%v):\n%s", stream, markdown)
+		}
+	}
+}
+
+// TestWritersEmitFileIDs tests that output.fileIds adds the same stable ID to every format.
+func TestWritersEmitFileIDs(t *testing.T) {
+	testutil.SetViperKeys(t, map[string]any{shared.ConfigKeyOutputFileIDs: true})
+
+	id := fileproc.FileID("pkg/main.go")
+	if len(id) != 2*shared.FileIDBytes || id != fileproc.FileID("pkg/main.go") || id == fileproc.FileID("main.go") {
+		t.Fatalf("FileID is not a stable per-path short hash: %q", id)
+	}
+
+	meta := map[string]string{shared.MetadataKeyOwners: "@org/core"}
+	req := fileproc.WriteRequest{Path: "pkg/main.go", Content: shared.LiteralPackageMain, Metadata: meta}
+	want := map[string]string{shared.MetadataKeyOwners: "@org/core", shared.MetadataKeyID: id}
+
+	for _, format := range []string{shared.FormatJSON, shared.FormatYAML} {
+		var out fileproc.OutputData
+		data := writeMetadataRequest(t, format, req)
+		if format == shared.FormatJSON {
+			testutil.MustSucceed(t, json.Unmarshal(data, &out), "decoding JSON")
+		} else {
+			testutil.MustSucceed(t, yaml.Unmarshal(data, &out), "decoding YAML")
+		}
+		if len(out.Files) != 1 || !maps.Equal(out.Files[0].Metadata, want) {
+			t.Errorf("%s: unexpected metadata %+v", format, out.Files)
+		}
+	}
+	markdown := string(writeMetadataRequest(t, shared.FormatMarkdown, req))
+	if !strings.Contains(markdown, "> id: "+id+"\n") {
+		t.Errorf("markdown output missing file ID:\n%s", markdown)
+	}
+	if len(meta) != 1 {
+		t.Errorf("writer modified the request's metadata map: %v", meta)
+	}
+	if !slices.Contains(shared.HashAlgorithms(), shared.HashXXHash) {
+		return
+	}
+
+	testutil.SetViperKeys(t, map[string]any{
+		shared.ConfigKeyOutputFileIDs:            true,
+		shared.ConfigKeyPerformanceHashAlgorithm: shared.HashXXHash,
+	})
+	if xxID := fileproc.FileID("pkg/main.go"); len(xxID) != len(id) || xxID == id {
+		t.Errorf("FileID with xxhash = %q, want a different ID of the same length as %q", xxID, id)
+	}
+}
+
+// verifyJSONMetadata checks that the single JSON file entry carries the expected owners.
+func verifyJSONMetadata(t *testing.T, data []byte, want map[string]string) {
+	t.Helper()
+	var out fileproc.OutputData
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("JSON unmarshal failed: %v\n%s", err, data)
+	}
+	if len(out.Files) != 1 || out.Files[0].Metadata[shared.MetadataKeyOwners] != want[shared.MetadataKeyOwners] {
+		t.Errorf("unexpected JSON metadata: %+v", out.Files)
+	}
+}
+
+// verifyYAMLMetadata checks that the single YAML file entry carries the expected owners.
+func verifyYAMLMetadata(t *testing.T, data []byte, want map[string]string) {
+	t.Helper()
+	var out fileproc.OutputData
+	if err := yaml.Unmarshal(data, &out); err != nil {
+		t.Fatalf("YAML unmarshal failed: %v\n%s", err, data)
+	}
+	if len(out.Files) != 1 || out.Files[0].Metadata[shared.MetadataKeyOwners] != want[shared.MetadataKeyOwners] {
+		t.Errorf("unexpected YAML metadata: %+v", out.Files)
+	}
+}
+
+// TestFileProcessorAnnotators tests that annotator results are merged into the write request.
+func TestFileProcessorAnnotators(t *testing.T) {
+	testutil.ResetViperConfig(t, "")
+	dir := t.TempDir()
+	filePath := testutil.CreateTestFile(t, dir, "main.go", []byte(shared.LiteralPackageMain))
+
+	processor := fileproc.NewFileProcessor(dir)
+	processor.SetAnnotators(
+		func(_, relPath string) map[string]string { return map[string]string{"a": relPath, "b": "first"} },
+		func(string, string) map[string]string { return nil },
+		func(string, string) map[string]string { return map[string]string{"b": "second"} },
+	)
+
+	outCh := make(chan fileproc.WriteRequest, 1)
+	testutil.MustSucceed(t, processor.ProcessWithContext(context.Background(), filePath, outCh), "processing")
+	req := <-outCh
+
+	if req.Metadata["a"] != "main.go" || req.Metadata["b"] != "second" {
+		t.Errorf("unexpected metadata: %+v", req.Metadata)
+	}
+}
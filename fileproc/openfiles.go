@@ -0,0 +1,189 @@
+// Package fileproc handles file processing, collection, and output formatting.
+package fileproc
+
+import (
+	"errors"
+	"math"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ivuorinen/gibidify/config"
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+const (
+	// openFileRetryDelay is how long an open failing for lack of file descriptors waits
+	// before trying again.
+	openFileRetryDelay = 10 * time.Millisecond
+	// openFileRetries is how many times such an open is tried again before its error is returned.
+	openFileRetries = 100
+)
+
+// OpenFileBudget describes how many source files a run reads at once.
+type OpenFileBudget struct {
+	// Limit is the soft open file limit (RLIMIT_NOFILE) of the process; 0 when the platform
+	// has none or it cannot be read.
+	Limit uint64
+	// Raised reports whether the soft limit was raised to the hard limit for the run.
+	Raised bool
+	// MaxOpen is the most source files open at once; 0 leaves opens unbounded.
+	MaxOpen int
+}
+
+// sourceFiles bounds the source files open at once. File descriptors belong to the whole
+// process, so every processor shares it.
+var sourceFiles = newOpenFileLimiter()
+
+// ConfigureOpenFiles bounds the source files read at once below the open file limit of the
+// process, first raising the soft limit to the hard limit when settings ask for it. A
+// performance.maxOpenFiles setting replaces the bound derived from the limit. Reads past the
+// bound wait for an open file to be closed instead of failing with "too many open files".
+func ConfigureOpenFiles(settings *config.Settings) OpenFileBudget {
+	var budget OpenFileBudget
+	if settings.PerformanceRaiseOpenFileLimit() {
+		budget.Raised = raiseOpenFileLimit()
+	}
+	budget.Limit = openFileLimit()
+	budget.MaxOpen = settings.PerformanceMaxOpenFiles()
+	if budget.MaxOpen == 0 {
+		budget.MaxOpen = openFileBound(budget.Limit)
+	}
+	sourceFiles.setLimit(budget.MaxOpen)
+
+	return budget
+}
+
+// openFileBound returns how many source files may be open at once under the soft limit,
+// leaving shared.OpenFileReserve descriptors, or half of a smaller limit, to the rest of the
+// process. Unknown and unlimited limits bound nothing.
+func openFileBound(limit uint64) int {
+	if limit == 0 || limit > math.MaxInt32 {
+		return 0
+	}
+	if limit > 2*shared.OpenFileReserve {
+		return int(limit) - shared.OpenFileReserve
+	}
+
+	return max(1, int(limit)/2)
+}
+
+// openFileLimiter counts the open source files, holding back opens past its limit.
+type openFileLimiter struct {
+	mu     sync.Mutex
+	closed *sync.Cond
+	limit  int
+	open   int
+}
+
+// newOpenFileLimiter creates a limiter that bounds nothing until setLimit is called.
+func newOpenFileLimiter() *openFileLimiter {
+	l := &openFileLimiter{}
+	l.closed = sync.NewCond(&l.mu)
+
+	return l
+}
+
+// setLimit bounds the files open at once to limit; 0 leaves them unbounded.
+func (l *openFileLimiter) setLimit(limit int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.limit = limit
+	l.closed.Broadcast()
+}
+
+// acquire waits until another file may be opened and counts it as open.
+func (l *openFileLimiter) acquire() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for l.limit > 0 && l.open >= l.limit {
+		l.closed.Wait()
+	}
+	l.open++
+}
+
+// release counts a file as closed, letting a waiting open proceed.
+func (l *openFileLimiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.open--
+	l.closed.Broadcast()
+}
+
+// exhausted lowers the limit to the files open now, after an open failed for lack of file
+// descriptors: the rest of the process holds more of them than the limit allowed for.
+func (l *openFileLimiter) exhausted() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.limit == 0 || l.open-1 < l.limit {
+		l.limit = max(1, l.open-1)
+	}
+}
+
+// retryOpen calls open until it succeeds or fails for a reason other than the process, or
+// the system, running out of file descriptors.
+func (l *openFileLimiter) retryOpen(open func() error) error {
+	err := open()
+	for range openFileRetries {
+		if !errors.Is(err, syscall.EMFILE) && !errors.Is(err, syscall.ENFILE) {
+			break
+		}
+		l.exhausted()
+		time.Sleep(openFileRetryDelay)
+		err = open()
+	}
+
+	return err
+}
+
+// readLimited reads the file at path once the open file bound allows it.
+func readLimited(path string) ([]byte, error) {
+	sourceFiles.acquire()
+	defer sourceFiles.release()
+
+	var content []byte
+	err := sourceFiles.retryOpen(func() error {
+		var err error
+		content, err = os.ReadFile(path) // #nosec G304 - path is validated by walker
+
+		return err //nolint:wrapcheck // callers wrap read errors
+	})
+
+	return content, err
+}
+
+// openLimited opens the file at path once the open file bound allows it. The file counts
+// against the bound until it is closed.
+func openLimited(path string) (*limitedFile, error) {
+	sourceFiles.acquire()
+
+	var file *os.File
+	err := sourceFiles.retryOpen(func() error {
+		var err error
+		file, err = os.Open(path) // #nosec G304 - path is validated by walker
+
+		return err //nolint:wrapcheck // callers wrap open errors
+	})
+	if err != nil {
+		sourceFiles.release()
+
+		return nil, err
+	}
+
+	return &limitedFile{File: file}, nil
+}
+
+// limitedFile is a source file counted against the open file bound until it is closed.
+type limitedFile struct {
+	*os.File
+	once sync.Once
+}
+
+// Close closes the file and, the first time, frees its place under the open file bound.
+func (f *limitedFile) Close() error {
+	err := f.File.Close()
+	f.once.Do(sourceFiles.release)
+
+	return err //nolint:wrapcheck // callers wrap close errors
+}
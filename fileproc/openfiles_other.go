@@ -0,0 +1,14 @@
+//go:build !(linux || darwin || freebsd)
+
+// Package fileproc handles file processing, collection, and output formatting.
+package fileproc
+
+// openFileLimit returns 0: this platform has no open file limit to bound opens by.
+func openFileLimit() uint64 {
+	return 0
+}
+
+// raiseOpenFileLimit raises nothing on this platform.
+func raiseOpenFileLimit() bool {
+	return false
+}
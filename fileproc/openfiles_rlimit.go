@@ -0,0 +1,28 @@
+//go:build linux || darwin || freebsd
+
+// Package fileproc handles file processing, collection, and output formatting.
+package fileproc
+
+import "syscall"
+
+// openFileLimit returns the soft RLIMIT_NOFILE of the process, or 0 when it cannot be read.
+func openFileLimit() uint64 {
+	var limit syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &limit); err != nil {
+		return 0
+	}
+
+	return uint64(limit.Cur) //nolint:unconvert,gosec // field types vary by platform
+}
+
+// raiseOpenFileLimit raises the soft RLIMIT_NOFILE to the hard limit, reporting whether it
+// was raised. The Go runtime already does so at startup where the system allows it.
+func raiseOpenFileLimit() bool {
+	var limit syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &limit); err != nil || limit.Cur >= limit.Max {
+		return false
+	}
+	limit.Cur = limit.Max
+
+	return syscall.Setrlimit(syscall.RLIMIT_NOFILE, &limit) == nil
+}
@@ -0,0 +1,126 @@
+package fileproc
+
+import (
+	"errors"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ivuorinen/gibidify/config"
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// TestOpenFileBound tests deriving the source files open at once from the soft limit.
+func TestOpenFileBound(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit uint64
+		want  int
+	}{
+		{name: "unknown", limit: 0, want: 0},
+		{name: "unlimited", limit: 1 << 63, want: 0},
+		{name: "common default", limit: 1024, want: 1024 - shared.OpenFileReserve},
+		{name: "small", limit: 100, want: 50},
+		{name: "tiny", limit: 1, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := openFileBound(tt.limit); got != tt.want {
+				t.Errorf("openFileBound(%d) = %d, want %d", tt.limit, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestConfigureOpenFiles tests that performance.maxOpenFiles replaces the bound derived from
+// the open file limit.
+func TestConfigureOpenFiles(t *testing.T) {
+	t.Cleanup(func() { sourceFiles.setLimit(0) })
+
+	settings := config.NewSettings()
+	budget := ConfigureOpenFiles(settings)
+	if budget.MaxOpen != openFileBound(budget.Limit) {
+		t.Errorf("MaxOpen = %d for limit %d, want %d", budget.MaxOpen, budget.Limit, openFileBound(budget.Limit))
+	}
+
+	settings.Set(shared.ConfigKeyPerformanceMaxOpenFiles, 5)
+	if budget := ConfigureOpenFiles(settings); budget.MaxOpen != 5 {
+		t.Errorf("MaxOpen = %d with performance.maxOpenFiles 5, want 5", budget.MaxOpen)
+	}
+}
+
+// TestOpenLimitedQueues tests that an open past the bound waits for an open file to be closed.
+func TestOpenLimitedQueues(t *testing.T) {
+	sourceFiles.setLimit(1)
+	t.Cleanup(func() { sourceFiles.setLimit(0) })
+	dir := t.TempDir()
+	first := testutil.CreateTestFile(t, dir, "first.go", []byte(shared.LiteralPackageMain))
+	second := testutil.CreateTestFile(t, dir, "second.go", []byte(shared.LiteralPackageMain))
+
+	file, err := openLimited(first)
+	testutil.MustSucceed(t, err, "opening first file")
+
+	read := make(chan error)
+	go func() {
+		_, err := readLimited(second)
+		read <- err
+	}()
+
+	select {
+	case err := <-read:
+		t.Fatalf("read past the bound finished (%v) while the first file was open", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	testutil.MustSucceed(t, file.Close(), "closing first file")
+	testutil.MustSucceed(t, <-read, "reading second file")
+	// Closing twice fails but must not free a second place under the bound
+	if err := file.Close(); err == nil {
+		t.Error("closing the first file again succeeded")
+	}
+	if sourceFiles.open != 0 {
+		t.Errorf("%d files counted open after closing them, want 0", sourceFiles.open)
+	}
+}
+
+// TestRetryOpen tests that opens failing for lack of file descriptors are retried under a
+// lowered bound, and other failures are returned at once.
+func TestRetryOpen(t *testing.T) {
+	tests := []struct {
+		name      string
+		errs      []error
+		wantCalls int
+		wantLimit int
+		wantErr   error
+	}{
+		{name: "success", errs: []error{nil}, wantCalls: 1, wantLimit: 10},
+		{
+			name: "descriptors run out", errs: []error{syscall.EMFILE, syscall.ENFILE, nil},
+			wantCalls: 3, wantLimit: 3,
+		},
+		{name: "other failure", errs: []error{syscall.ENOENT}, wantCalls: 1, wantLimit: 10, wantErr: syscall.ENOENT},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := newOpenFileLimiter()
+			limiter.setLimit(10)
+			limiter.open = 4
+
+			calls := 0
+			err := limiter.retryOpen(func() error {
+				calls++
+
+				return tt.errs[calls-1]
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("retryOpen() error = %v, want %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls || limiter.limit != tt.wantLimit {
+				t.Errorf("got %d calls and limit %d, want %d and %d", calls, limiter.limit, tt.wantCalls, tt.wantLimit)
+			}
+		})
+	}
+}
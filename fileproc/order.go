@@ -0,0 +1,131 @@
+// Package fileproc handles file processing, collection, and output formatting.
+package fileproc
+
+import (
+	"cmp"
+	"math"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	ignore "github.com/sabhiram/go-gitignore"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// OrderOptions holds what the ordering strategies know about the files beyond their paths.
+type OrderOptions struct {
+	// Root is the directory paths are ordered relative to.
+	Root string
+	// Sizes holds the size in bytes of every file, for OrderSize.
+	Sizes map[string]int64
+	// LastCommit returns the time of the last commit touching a file, the zero time for files
+	// never committed, for OrderRecency.
+	LastCommit func(path string) time.Time
+	// Priority holds the gitignore-style globs of OrderPriority, most important first.
+	Priority []string
+	// Complexity holds the approximate cyclomatic complexity of every file, for OrderComplexity.
+	Complexity map[string]int64
+}
+
+// orderedFile is a file with the key its strategy orders it by.
+type orderedFile struct {
+	path string
+	rel  string
+	key  int64
+}
+
+// OrderFiles returns files in the order of strategy, one of shared.OrderStrategies, so the most
+// important files are handed out first. Files the strategy ranks the same are ordered by their
+// relative paths. Unknown strategies leave files as they are.
+//
+//   - alphabetical orders by relative path.
+//   - size puts the smallest files first.
+//   - recency puts the most recently committed files first, after the files never committed.
+//   - depth puts the files closest to Root first.
+//   - priority puts files in the order of the first Priority glob matching them, the files
+//     matching none last.
+//   - complexity puts the most complex files first.
+func OrderFiles(files []string, strategy string, opts OrderOptions) []string {
+	key := orderKey(strategy, opts)
+	if key == nil {
+		return files
+	}
+
+	ordered := make([]orderedFile, 0, len(files))
+	for _, file := range files {
+		rel, err := filepath.Rel(opts.Root, file)
+		if err != nil {
+			rel = file
+		}
+		rel = filepath.ToSlash(rel)
+		ordered = append(ordered, orderedFile{path: file, rel: rel, key: key(file, rel)})
+	}
+	slices.SortFunc(ordered, func(a, b orderedFile) int {
+		return cmp.Or(cmp.Compare(a.key, b.key), strings.Compare(a.rel, b.rel))
+	})
+
+	result := make([]string, 0, len(ordered))
+	for _, file := range ordered {
+		result = append(result, file.path)
+	}
+
+	return result
+}
+
+// orderKey returns the function giving a file its sort key under strategy, smaller first, or nil
+// for an unknown strategy.
+func orderKey(strategy string, opts OrderOptions) func(path, rel string) int64 {
+	switch strategy {
+	case shared.OrderAlphabetical:
+		return func(string, string) int64 { return 0 }
+	case shared.OrderSize:
+		return func(path, _ string) int64 { return opts.Sizes[path] }
+	case shared.OrderRecency:
+		return recencyKey(opts.LastCommit)
+	case shared.OrderDepth:
+		return func(_, rel string) int64 { return int64(strings.Count(rel, "/")) }
+	case shared.OrderPriority:
+		return priorityKey(opts.Priority)
+	case shared.OrderComplexity:
+		return func(path, _ string) int64 { return -opts.Complexity[path] }
+	default:
+		return nil
+	}
+}
+
+// recencyKey orders files by their last commit, newest first; files never committed are the
+// newest changes of all.
+func recencyKey(lastCommit func(path string) time.Time) func(path, rel string) int64 {
+	return func(path, _ string) int64 {
+		if lastCommit == nil {
+			return 0
+		}
+		committed := lastCommit(path)
+		if committed.IsZero() {
+			return math.MinInt64
+		}
+
+		return -committed.Unix()
+	}
+}
+
+// priorityKey orders files by the index of the first glob in patterns matching them, matched
+// against their relative paths; files matching none come after all of them.
+func priorityKey(patterns []string) func(path, rel string) int64 {
+	matchers := make([]*ignore.GitIgnore, 0, len(patterns))
+	for _, pattern := range patterns {
+		matchers = append(matchers, ignore.CompileIgnoreLines(pattern))
+	}
+
+	return func(_, rel string) int64 {
+		for i, matcher := range matchers {
+			if matcher.MatchesPath(rel) {
+				return int64(i)
+			}
+		}
+
+		return int64(len(matchers))
+	}
+}
@@ -0,0 +1,86 @@
+package fileproc_test
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ivuorinen/gibidify/fileproc"
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// TestOrderFiles tests every ordering strategy, with ties ordered by relative path.
+func TestOrderFiles(t *testing.T) {
+	root := filepath.FromSlash("/src")
+	rels := []string{"z.go", "docs/guide.md", "cmd/app/main.go", "a.go", "docs/api.md"}
+	files := make([]string, 0, len(rels))
+	for _, rel := range rels {
+		files = append(files, filepath.Join(root, filepath.FromSlash(rel)))
+	}
+	path := func(rel string) string { return filepath.Join(root, filepath.FromSlash(rel)) }
+
+	opts := fileproc.OrderOptions{
+		Root: root,
+		Sizes: map[string]int64{
+			path("z.go"): 10, path("docs/guide.md"): 500, path("cmd/app/main.go"): 300,
+			path("a.go"): 10, path("docs/api.md"): 50,
+		},
+		LastCommit: func(p string) time.Time {
+			commits := map[string]int64{path("z.go"): 100, path("docs/guide.md"): 300, path("a.go"): 200}
+			if seconds, ok := commits[p]; ok {
+				return time.Unix(seconds, 0)
+			}
+
+			return time.Time{}
+		},
+		Priority: []string{"cmd/**", "*.md"},
+		Complexity: map[string]int64{
+			path("z.go"): 4, path("cmd/app/main.go"): 12, path("a.go"): 4,
+		},
+	}
+
+	tests := []struct {
+		strategy string
+		want     []string
+	}{
+		{
+			strategy: shared.OrderAlphabetical,
+			want:     []string{"a.go", "cmd/app/main.go", "docs/api.md", "docs/guide.md", "z.go"},
+		},
+		{
+			strategy: shared.OrderSize,
+			want:     []string{"a.go", "z.go", "docs/api.md", "cmd/app/main.go", "docs/guide.md"},
+		},
+		{
+			strategy: shared.OrderRecency,
+			want:     []string{"cmd/app/main.go", "docs/api.md", "docs/guide.md", "a.go", "z.go"},
+		},
+		{
+			strategy: shared.OrderDepth,
+			want:     []string{"a.go", "z.go", "docs/api.md", "docs/guide.md", "cmd/app/main.go"},
+		},
+		{
+			strategy: shared.OrderPriority,
+			want:     []string{"cmd/app/main.go", "docs/api.md", "docs/guide.md", "a.go", "z.go"},
+		},
+		{
+			strategy: shared.OrderComplexity,
+			want:     []string{"cmd/app/main.go", "a.go", "z.go", "docs/api.md", "docs/guide.md"},
+		},
+		{strategy: "unknown", want: rels},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.strategy, func(t *testing.T) {
+			got := fileproc.OrderFiles(files, tt.strategy, opts)
+			want := make([]string, 0, len(tt.want))
+			for _, rel := range tt.want {
+				want = append(want, path(rel))
+			}
+			if !slices.Equal(got, want) {
+				t.Errorf("OrderFiles(%s) = %v, want %v", tt.strategy, got, want)
+			}
+		})
+	}
+}
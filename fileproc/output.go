@@ -0,0 +1,126 @@
+// Package fileproc handles file processing, collection, and output formatting.
+package fileproc
+
+import (
+	"bufio"
+	"io"
+	"os"
+
+	"github.com/ivuorinen/gibidify/chaos"
+)
+
+// OutputStats counts the writes that reached the output file, to show whether output
+// buffering is effective. Every write is one system call.
+type OutputStats struct {
+	Writes int64
+	Bytes  int64
+	// Err is the first error writing to the output file, which leaves the bundle incomplete.
+	Err error
+}
+
+// bundleOutput is the output the format writers write to: the output file behind an
+// optional write buffer, counting the writes that reach the file.
+type bundleOutput struct {
+	file  *os.File
+	w     outputWriter
+	buf   *bufio.Writer
+	stats OutputStats
+}
+
+// newBundleOutput wraps file in a buffer of size bytes; a size of 0 leaves writes unbuffered.
+func newBundleOutput(file *os.File, size int) *bundleOutput {
+	out := &bundleOutput{file: file}
+	out.w = countingFile{out: out}
+	if size > 0 {
+		out.buf = bufio.NewWriterSize(countingFile{out: out}, size)
+		out.w = out.buf
+	}
+
+	return out
+}
+
+// Write implements io.Writer.
+func (o *bundleOutput) Write(p []byte) (int, error) {
+	return o.w.Write(p) //nolint:wrapcheck // the writers wrap output errors
+}
+
+// WriteString implements io.StringWriter.
+func (o *bundleOutput) WriteString(s string) (int, error) {
+	return o.w.WriteString(s) //nolint:wrapcheck // the writers wrap output errors
+}
+
+// Flush writes any buffered data to the file.
+func (o *bundleOutput) Flush() error {
+	if o.buf == nil {
+		return nil
+	}
+
+	return o.buf.Flush() //nolint:wrapcheck // the caller logs flush errors
+}
+
+// written returns the number of bytes written to the output, including those still buffered.
+func (o *bundleOutput) written() int64 {
+	if o.buf == nil {
+		return o.stats.Bytes
+	}
+
+	return o.stats.Bytes + int64(o.buf.Buffered())
+}
+
+// offset returns the position in the file the next byte written will end up at.
+func (o *bundleOutput) offset() (int64, error) {
+	pos, err := o.file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, err //nolint:wrapcheck // the index reports why offsets are unavailable
+	}
+	if o.buf != nil {
+		pos += int64(o.buf.Buffered())
+	}
+
+	return pos, nil
+}
+
+// count records a write of n bytes to the file, and err when it is the first one failing.
+func (o *bundleOutput) count(n int, err error) {
+	o.stats.Writes++
+	o.stats.Bytes += int64(n)
+	o.fail(err)
+}
+
+// fail records err when it is the first error writing to the file.
+func (o *bundleOutput) fail(err error) {
+	if o.stats.Err == nil {
+		o.stats.Err = err
+	}
+}
+
+// countingFile writes to the output file, counting every write in the output's stats.
+type countingFile struct {
+	out *bundleOutput
+}
+
+// Write implements io.Writer.
+func (c countingFile) Write(p []byte) (int, error) {
+	if err := chaos.BeforeWrite(); err != nil {
+		c.out.fail(err)
+
+		return 0, err //nolint:wrapcheck // the writers wrap output errors
+	}
+	n, err := c.out.file.Write(p)
+	c.out.count(n, err)
+
+	return n, err //nolint:wrapcheck // the writers wrap output errors
+}
+
+// WriteString implements io.StringWriter.
+func (c countingFile) WriteString(s string) (int, error) {
+	if err := chaos.BeforeWrite(); err != nil {
+		c.out.fail(err)
+
+		return 0, err //nolint:wrapcheck // the writers wrap output errors
+	}
+	n, err := c.out.file.WriteString(s)
+	c.out.count(n, err)
+
+	return n, err //nolint:wrapcheck // the writers wrap output errors
+}
@@ -0,0 +1,270 @@
+// Package fileproc handles file processing, collection, and output formatting.
+package fileproc
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// devNull is the placeholder path used by unified diffs for created or deleted files.
+const devNull = "/dev/null"
+
+// PatchFile describes a file touched by a unified diff.
+type PatchFile struct {
+	OldPath string
+	NewPath string
+}
+
+// Deleted reports whether the patch removes the file.
+func (f PatchFile) Deleted() bool {
+	return f.NewPath == ""
+}
+
+// ParsePatch extracts the files referenced by a unified diff (plain or git-style).
+// Paths have their a/ and b/ prefixes stripped; created and deleted files have an empty old or new path.
+func ParsePatch(r io.Reader) ([]PatchFile, error) {
+	var files []PatchFile
+	var prev string
+	var pendingRename *PatchFile
+	var hunk hunkState
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, shared.FileProcessingStreamChunkSize), shared.BytesPerMB)
+	for scanner.Scan() {
+		line := scanner.Text()
+		// Hunk bodies may contain lines starting with "--- " or "+++ ", so they are
+		// consumed by line count before any header is recognized.
+		if hunk.consume(line) {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "@@ "):
+			hunk = parseHunkHeader(line)
+		case strings.HasPrefix(line, "diff --git "):
+			files = flushRename(files, pendingRename)
+			pendingRename = nil
+		case strings.HasPrefix(line, "rename from "):
+			pendingRename = &PatchFile{OldPath: strings.TrimPrefix(line, "rename from ")}
+		case strings.HasPrefix(line, "rename to ") && pendingRename != nil:
+			pendingRename.NewPath = strings.TrimPrefix(line, "rename to ")
+		case strings.HasPrefix(line, "+++ ") && strings.HasPrefix(prev, "--- "):
+			files = append(files, PatchFile{
+				OldPath: parsePatchPath(strings.TrimPrefix(prev, "--- ")),
+				NewPath: parsePatchPath(strings.TrimPrefix(line, "+++ ")),
+			})
+			pendingRename = nil
+		}
+		prev = line
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIORead, "failed to read patch")
+	}
+
+	return flushRename(files, pendingRename), nil
+}
+
+// hunkState tracks how many old and new lines of the current hunk remain.
+type hunkState struct {
+	oldLines int
+	newLines int
+}
+
+// consume reports whether line belongs to the current hunk body and updates the remaining counts.
+func (h *hunkState) consume(line string) bool {
+	if h.oldLines <= 0 && h.newLines <= 0 {
+		return false
+	}
+	if strings.HasPrefix(line, "diff --git ") {
+		// Tolerate hunks whose counts overstate their length.
+		*h = hunkState{}
+
+		return false
+	}
+
+	switch {
+	case strings.HasPrefix(line, "-"):
+		h.oldLines--
+	case strings.HasPrefix(line, "+"):
+		h.newLines--
+	case strings.HasPrefix(line, `\`):
+		// "\ No newline at end of file" does not count towards the hunk.
+	default:
+		h.oldLines--
+		h.newLines--
+	}
+
+	return true
+}
+
+// parseHunkHeader reads the line counts from a "@@ -a,b +c,d @@" header.
+// Omitted counts default to 1, as in the unified diff format.
+func parseHunkHeader(line string) hunkState {
+	fields := strings.Fields(line)
+	if len(fields) < 3 {
+		return hunkState{}
+	}
+
+	return hunkState{oldLines: hunkRangeCount(fields[1]), newLines: hunkRangeCount(fields[2])}
+}
+
+// hunkRangeCount returns the line count of a "-a,b" or "+c,d" hunk range.
+func hunkRangeCount(rng string) int {
+	_, count, found := strings.Cut(rng, ",")
+	if !found {
+		return 1
+	}
+	n, err := strconv.Atoi(count)
+	if err != nil {
+		return 0
+	}
+
+	return n
+}
+
+// flushRename records a content-less git rename that never produced ---/+++ headers.
+func flushRename(files []PatchFile, rename *PatchFile) []PatchFile {
+	if rename == nil || rename.NewPath == "" {
+		return files
+	}
+
+	return append(files, *rename)
+}
+
+// parsePatchPath normalizes a path from a ---/+++ header line.
+func parsePatchPath(raw string) string {
+	// Plain diff headers may carry a tab-separated timestamp.
+	if idx := strings.IndexByte(raw, '\t'); idx >= 0 {
+		raw = raw[:idx]
+	}
+	raw = strings.TrimSpace(raw)
+	if unquoted, err := strconv.Unquote(raw); err == nil && strings.HasPrefix(raw, `"`) {
+		raw = unquoted
+	}
+	if raw == devNull {
+		return ""
+	}
+	if strings.HasPrefix(raw, "a/") || strings.HasPrefix(raw, "b/") {
+		raw = raw[2:]
+	}
+
+	return raw
+}
+
+// CollectPatchFiles parses the patch at patchPath and returns the absolute paths of the
+// referenced files that exist under root, in patch order and without duplicates.
+// Deleted and missing files, files resolving outside root through a symlink, and files a walk
+// of root leaves out, such as ignored, binary and image files, are skipped.
+func CollectPatchFiles(root, patchPath string) ([]string, error) {
+	return CollectPatchFilesWithRegistry(root, patchPath, getRegistry())
+}
+
+// CollectPatchFilesWithRegistry works like CollectPatchFiles, classifying binary and image
+// files with registry.
+func CollectPatchFilesWithRegistry(root, patchPath string, registry *FileTypeRegistry) ([]string, error) {
+	return CollectPatchFilesWithWalker(root, patchPath, NewProdWalkerWithRegistry(registry))
+}
+
+// CollectPatchFilesWithWalker works like CollectPatchFiles, skipping the files walker leaves
+// out. Skipped files other than deleted and missing ones are reported to its skip hook.
+func CollectPatchFilesWithWalker(root, patchPath string, walker *ProdWalker) ([]string, error) {
+	absRoot, err := shared.AbsolutePath(root)
+	if err != nil {
+		return nil, shared.WrapError(
+			err, shared.ErrorTypeFileSystem, shared.CodeFSPathResolution, "failed to resolve root path",
+		).WithFilePath(root)
+	}
+	realRoot, err := filepath.EvalSymlinks(absRoot)
+	if err != nil {
+		return nil, shared.WrapError(
+			err, shared.ErrorTypeFileSystem, shared.CodeFSPathResolution, "failed to resolve root path",
+		).WithFilePath(root)
+	}
+
+	patch, err := os.Open(patchPath) // #nosec G304 -- patch path is supplied explicitly by the user
+	if err != nil {
+		return nil, shared.WrapError(err, shared.ErrorTypeFileSystem, shared.CodeFSAccess, "failed to open patch").
+			WithFilePath(patchPath)
+	}
+	defer shared.SafeCloseReader(patch, patchPath)
+
+	entries, err := ParsePatch(patch)
+	if err != nil {
+		return nil, err
+	}
+
+	logger := shared.GetLogger()
+	files := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Deleted() {
+			continue
+		}
+
+		fullPath := filepath.Join(absRoot, filepath.FromSlash(entry.NewPath))
+		if !strings.HasPrefix(fullPath, absRoot+string(filepath.Separator)) {
+			logger.Warnf("Skipping patch path outside source directory: %s", entry.NewPath)
+
+			continue
+		}
+		info, err := patchFileInfo(realRoot, fullPath)
+		if err != nil {
+			logger.Warnf("Skipping patch file %s: %v", entry.NewPath, err)
+
+			continue
+		}
+		if slices.Contains(files, fullPath) || walker.skipPatchFile(absRoot, fullPath, info) {
+			continue
+		}
+		files = append(files, fullPath)
+	}
+
+	return files, nil
+}
+
+// patchFileInfo describes the regular file at fullPath, following symlinks only as long as
+// they resolve inside realRoot, the source directory with its own symlinks resolved.
+func patchFileInfo(realRoot, fullPath string) (os.FileInfo, error) {
+	info, err := os.Lstat(fullPath)
+	if err != nil {
+		return nil, errors.New("missing from working tree")
+	}
+	resolved, err := filepath.EvalSymlinks(fullPath)
+	if err != nil {
+		return nil, errors.New("symlink target is missing")
+	}
+	if !strings.HasPrefix(resolved, realRoot+string(filepath.Separator)) {
+		return nil, errors.New("resolves outside source directory")
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		if info, err = os.Stat(resolved); err != nil {
+			return nil, errors.New("symlink target is missing")
+		}
+	}
+	if !info.Mode().IsRegular() {
+		return nil, errors.New("not a regular file")
+	}
+
+	return info, nil
+}
+
+// skipPatchFile reports whether a walk of absRoot leaves out the patch file at fullPath,
+// reporting skipped files to the skip hook.
+func (w *ProdWalker) skipPatchFile(absRoot, fullPath string, info os.FileInfo) bool {
+	reason := w.skipReason(absRoot, fullPath, info)
+	if reason == "" {
+		return false
+	}
+	if w.filter.onSkip != nil {
+		w.filter.onSkip(fullPath, reason, info.Size())
+	}
+
+	return true
+}
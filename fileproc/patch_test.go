@@ -0,0 +1,148 @@
+package fileproc_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+
+	"github.com/ivuorinen/gibidify/fileproc"
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+const sampleGitPatch = `diff --git a/main.go b/main.go
+index 1111111..2222222 100644
+--- a/main.go
++++ b/main.go
+@@ -1,2 +1,2 @@
+ package main
+--- not a header
++++ not a header either
+diff --git a/old.go b/old.go
+deleted file mode 100644
+--- a/old.go
++++ /dev/null
+@@ -1 +0,0 @@
+-package old
+diff --git a/new.go b/new.go
+new file mode 100644
+--- /dev/null
++++ b/new.go
+@@ -0,0 +1 @@
++package new
+diff --git a/a.txt b/moved.txt
+similarity index 100%
+rename from a.txt
+rename to moved.txt
+diff --git "a/with space.go" "b/with space.go"
+--- "a/with space.go"
++++ "b/with space.go"
+@@ -1 +1 @@
+-x
++y
+`
+
+// TestParsePatch tests extraction of file paths from git-style unified diffs.
+func TestParsePatch(t *testing.T) {
+	files, err := fileproc.ParsePatch(strings.NewReader(sampleGitPatch))
+	testutil.MustSucceed(t, err, "parsing patch")
+
+	want := []fileproc.PatchFile{
+		{OldPath: "main.go", NewPath: "main.go"},
+		{OldPath: "old.go", NewPath: ""},
+		{OldPath: "", NewPath: "new.go"},
+		{OldPath: "a.txt", NewPath: "moved.txt"},
+		{OldPath: "with space.go", NewPath: "with space.go"},
+	}
+	if !slices.Equal(files, want) {
+		t.Fatalf("ParsePatch() = %+v, want %+v", files, want)
+	}
+	if !files[1].Deleted() || files[0].Deleted() {
+		t.Error("Deleted() misreported")
+	}
+}
+
+// TestParsePatchPlainDiff tests headers produced by `diff -u` with timestamps.
+func TestParsePatchPlainDiff(t *testing.T) {
+	patch := "--- src/app.js\t2024-01-01 10:00:00\n+++ src/app.js\t2024-01-02 10:00:00\n@@ -1 +1 @@\n-a\n+b\n"
+	files, err := fileproc.ParsePatch(strings.NewReader(patch))
+	testutil.MustSucceed(t, err, "parsing plain diff")
+
+	if len(files) != 1 || files[0].NewPath != "src/app.js" {
+		t.Errorf("unexpected files: %+v", files)
+	}
+}
+
+// TestCollectPatchFiles tests resolving patch entries against the working tree.
+func TestCollectPatchFiles(t *testing.T) {
+	testutil.ResetViperConfig(t, "")
+	root := t.TempDir()
+	mainFile := testutil.CreateTestFile(t, root, "main.go", []byte("package main"))
+	newFile := testutil.CreateTestFile(t, root, "new.go", []byte("package new"))
+	testutil.CreateTestFile(t, root, "with space.go", []byte("y"))
+	patchPath := filepath.Join(t.TempDir(), "changes.patch")
+	testutil.MustSucceed(t, os.WriteFile(patchPath, []byte(sampleGitPatch), 0o600), "writing patch")
+	defer testutil.SuppressLogs(t)()
+
+	files, err := fileproc.CollectPatchFiles(root, patchPath)
+	testutil.MustSucceed(t, err, "collecting patch files")
+
+	// old.go is deleted and moved.txt is missing from the working tree.
+	want := []string{mainFile, newFile, filepath.Join(root, "with space.go")}
+	if !slices.Equal(files, want) {
+		t.Errorf("CollectPatchFiles() = %v, want %v", files, want)
+	}
+
+	if _, err := fileproc.CollectPatchFiles(root, filepath.Join(root, "missing.patch")); err == nil {
+		t.Error("expected error for missing patch file")
+	}
+}
+
+// TestCollectPatchFilesSkips tests that patch entries the walk would leave out, or that
+// resolve outside the source directory through a symlink, are skipped.
+func TestCollectPatchFilesSkips(t *testing.T) {
+	testutil.ResetViperConfig(t, "")
+	viper.Set(shared.ConfigKeyIgnoreDirectories, []string{"vendor"})
+	defer testutil.SuppressLogs(t)()
+
+	root := t.TempDir()
+	outside := t.TempDir()
+	secret := testutil.CreateTestFile(t, outside, "secret.txt", []byte("secret"))
+	mainFile := testutil.CreateTestFile(t, root, "main.go", []byte("package main"))
+	testutil.CreateTestFile(t, root, ".gitignore", []byte("*.log\n"))
+	testutil.CreateTestFile(t, root, "debug.log", []byte("log"))
+	testutil.MustSucceed(t, os.Mkdir(filepath.Join(root, "vendor"), 0o750), "creating vendor")
+	testutil.CreateTestFile(t, filepath.Join(root, "vendor"), "dep.go", []byte("package dep"))
+	testutil.MustSucceed(t, os.Symlink("main.go", filepath.Join(root, "inside.go")), "linking inside")
+	testutil.MustSucceed(t, os.Symlink(secret, filepath.Join(root, "escape.txt")), "linking a file outside")
+	testutil.MustSucceed(t, os.Symlink(outside, filepath.Join(root, "linked")), "linking a directory outside")
+
+	var patch strings.Builder
+	names := []string{"main.go", "debug.log", "vendor/dep.go", "inside.go", "escape.txt", "linked/secret.txt"}
+	for _, name := range names {
+		patch.WriteString("--- a/" + name + "\n+++ b/" + name + "\n")
+	}
+	patchPath := filepath.Join(t.TempDir(), "changes.patch")
+	testutil.MustSucceed(t, os.WriteFile(patchPath, []byte(patch.String()), 0o600), "writing patch")
+
+	walker := fileproc.NewProdWalker()
+	var skipped []string
+	walker.SetSkipHook(func(path, _ string, _ int64) {
+		skipped = append(skipped, path)
+	})
+	files, err := fileproc.CollectPatchFilesWithWalker(root, patchPath, walker)
+	testutil.MustSucceed(t, err, "collecting patch files")
+
+	want := []string{mainFile, filepath.Join(root, "inside.go")}
+	if !slices.Equal(files, want) {
+		t.Errorf("CollectPatchFilesWithWalker() = %v, want %v", files, want)
+	}
+	wantSkipped := []string{filepath.Join(root, "debug.log"), filepath.Join(root, "vendor", "dep.go")}
+	if !slices.Equal(skipped, wantSkipped) {
+		t.Errorf("skipped = %v, want %v", skipped, wantSkipped)
+	}
+}
@@ -0,0 +1,561 @@
+// Package fileproc handles file processing, collection, and output formatting.
+package fileproc
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/ivuorinen/gibidify/config"
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+const (
+	// pdfMargin is the margin around the text of every page, in points.
+	pdfMargin = 36.0
+	// pdfCharWidth is the advance width of every Courier glyph per point of font size.
+	pdfCharWidth = 0.6
+	// pdfLeading is the distance between two baselines per point of font size.
+	pdfLeading = 1.2
+	// pdfTabWidth is the column multiple a tab advances to.
+	pdfTabWidth = 4
+	// pdfMinTitleRows is the fewest rows a page must have left for a file to start on it.
+	pdfMinTitleRows = 4
+	// pdfContentsTitle heads the table of contents pages.
+	pdfContentsTitle = "Contents"
+)
+
+// Object numbers reserved for the objects every PDF bundle has; pages and bookmarks follow.
+const (
+	pdfObjCatalog = iota + 1
+	pdfObjPages
+	pdfObjFont
+	pdfObjFontBold
+	pdfObjFirstFree
+)
+
+// pdfPageSizes maps output.pdf.pageSize to the page width and height in points.
+var pdfPageSizes = map[string][2]float64{
+	shared.PDFPageSizeA4:     {595.28, 841.89},
+	shared.PDFPageSizeLetter: {612, 792},
+}
+
+// pdfWinAnsi maps the runes WinAnsiEncoding places in 0x80-0x9F, where it differs from Latin-1.
+var pdfWinAnsi = map[rune]byte{
+	'€': 0x80, '‚': 0x82, '„': 0x84, '…': 0x85, '‘': 0x91, '’': 0x92,
+	'“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '™': 0x99,
+}
+
+// pdfEntry is a file in the table of contents and bookmarks: its path, the content page its
+// section starts on, and the page object and baseline to jump to.
+type pdfEntry struct {
+	path   string
+	page   int
+	object int
+	top    float64
+}
+
+// PDFWriter handles PDF output: a printable document in a monospace font with a table of
+// contents and a bookmark for every file, and the file path in the header of every page.
+// Pages are written as they fill; the table of contents is written last and ordered first.
+// The document is 7-bit ASCII, with the text in WinAnsiEncoding; other runes print as '?'.
+type PDFWriter struct {
+	out    *pdfOutput
+	suffix string
+
+	width, height float64
+	fontSize      float64
+	columns, rows int
+
+	// pages and contents hold the page objects of the file sections and the table of contents.
+	pages, contents []int
+	entries         []pdfEntry
+	inContents      bool
+
+	// page holds the text of the open page, row the rows used on it and object its page
+	// object, zero while no page is open.
+	page   bytes.Buffer
+	row    int
+	object int
+	// title is the path shown in the header of the open page, current the path of the file
+	// being written, which continued pages show.
+	title, current string
+}
+
+// NewPDFWriter creates a new PDF writer using the output.pdf settings.
+func NewPDFWriter(outFile *os.File) *PDFWriter {
+	return newPDFWriter(outFile, config.Global())
+}
+
+// newPDFWriter creates a PDF writer for any output using the output.pdf settings of settings.
+func newPDFWriter(out outputWriter, settings *config.Settings) *PDFWriter {
+	size, ok := pdfPageSizes[settings.OutputPDFPageSize()]
+	if !ok {
+		size = pdfPageSizes[shared.ConfigPDFPageSizeDefault]
+	}
+	fontSize := float64(settings.OutputPDFFontSize())
+	if fontSize < shared.ConfigPDFFontSizeMin || fontSize > shared.ConfigPDFFontSizeMax {
+		fontSize = shared.ConfigPDFFontSizeDefault
+	}
+	w := &PDFWriter{
+		out:      &pdfOutput{w: out, next: pdfObjFirstFree},
+		width:    size[0],
+		height:   size[1],
+		fontSize: fontSize,
+	}
+	w.columns = int((w.width - 2*pdfMargin) / (fontSize * pdfCharWidth))
+	w.rows = int((w.bodyTop()-pdfMargin)/w.leading()) - 1
+
+	return w
+}
+
+// Start writes the document header, the fonts and the prefix, and stores the suffix for Close.
+func (w *PDFWriter) Start(prefix, suffix string) error {
+	w.suffix = suffix
+	w.out.write("%PDF-1.4\n")
+	w.out.object(pdfObjFont, pdfFont("Courier"))
+	w.out.object(pdfObjFontBold, pdfFont("Courier-Bold"))
+	if prefix != "" {
+		w.text(prefix, false)
+		w.blank()
+	}
+
+	return w.check("failed to write prefix", "")
+}
+
+// WriteFile writes a file section: the path in bold, its metadata and its content.
+func (w *PDFWriter) WriteFile(req WriteRequest) error {
+	content := io.Reader(strings.NewReader(req.Content))
+	if req.IsStream {
+		defer shared.SafeCloseReader(req.Reader, req.Path)
+		content = req.Reader
+	}
+
+	w.startFile(req)
+	if err := w.lines(content); err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIORead, "failed to read file content").
+			WithFilePath(req.Path)
+	}
+	w.blank()
+
+	return w.check("failed to write file section", req.Path)
+}
+
+// Close writes the suffix, the table of contents and the document trailer.
+func (w *PDFWriter) Close() error {
+	return w.finish("")
+}
+
+// CloseWithSummary works like Close, ending the last page with the one-line run summary.
+func (w *PDFWriter) CloseWithSummary(summary RunSummary) error {
+	return w.finish("Run summary: " + summary.sentence())
+}
+
+// finish writes the suffix and the summary line when not empty, then completes the document.
+func (w *PDFWriter) finish(summary string) error {
+	if w.suffix != "" {
+		w.text(w.suffix, false)
+	}
+	if summary != "" {
+		w.text(summary, false)
+	}
+	if w.object != 0 || len(w.pages) == 0 {
+		w.flushPage()
+	}
+	w.writeContents()
+	w.writeDocument()
+
+	return w.check("failed to write document", "")
+}
+
+// startFile moves to a new page unless the open one has room for the heading of req and some
+// content, then writes the heading and records the entry of req.
+func (w *PDFWriter) startFile(req WriteRequest) {
+	if w.object != 0 && w.rows-w.row < pdfMinTitleRows {
+		w.flushPage()
+	}
+	w.current = req.Path
+	w.openPage()
+	if w.title == "" {
+		w.title = req.Path
+	}
+	w.entries = append(w.entries, pdfEntry{
+		path:   req.Path,
+		page:   len(w.pages) + 1,
+		object: w.object,
+		top:    w.bodyTop() - float64(w.row)*w.leading() + w.fontSize,
+	})
+
+	w.text(req.Path, true)
+	for _, key := range sortedMetadataKeys(req.Metadata) {
+		w.text(key+": "+req.Metadata[key], false)
+	}
+	w.blank()
+}
+
+// lines writes every line of content, wrapping the long ones.
+func (w *PDFWriter) lines(content io.Reader) error {
+	reader := bufio.NewReaderSize(content, shared.FileProcessingStreamChunkSize)
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" {
+			w.text(strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r"), false)
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// text writes every line of s, wrapped at the page width, in the bold font when bold is set.
+func (w *PDFWriter) text(s string, bold bool) {
+	for line := range strings.SplitSeq(s, "\n") {
+		encoded := pdfEncode(nil, strings.TrimSuffix(line, "\r"))
+		for len(encoded) > w.columns {
+			w.writeRow(encoded[:w.columns], bold)
+			encoded = encoded[w.columns:]
+		}
+		w.writeRow(encoded, bold)
+	}
+}
+
+// blank writes an empty row.
+func (w *PDFWriter) blank() {
+	w.writeRow(nil, false)
+}
+
+// writeRow writes one row of encoded text, opening a page first when none is open and closing the
+// page once it is full.
+func (w *PDFWriter) writeRow(encoded []byte, bold bool) {
+	w.openPage()
+	if bold {
+		fmt.Fprintf(&w.page, "/F2 %s Tf %s ' /F1 %s Tf\n", w.number(w.fontSize), pdfString(encoded), w.number(w.fontSize))
+	} else {
+		fmt.Fprintf(&w.page, "%s '\n", pdfString(encoded))
+	}
+	w.row++
+	if w.row == w.rows {
+		w.flushPage()
+	}
+}
+
+// openPage starts a page unless one is open, reserving its page object for the bookmarks.
+func (w *PDFWriter) openPage() {
+	if w.object != 0 {
+		return
+	}
+	w.object = w.out.allocate()
+	w.row, w.title = 0, w.current
+	w.page.Reset()
+	fmt.Fprintf(&w.page, "BT /F1 %s Tf %s TL %s %s Td\n",
+		w.number(w.fontSize), w.number(w.leading()), w.number(pdfMargin), w.number(w.bodyTop()+w.leading()))
+}
+
+// flushPage writes the open page, or an empty one when none is open, with its header and footer.
+func (w *PDFWriter) flushPage() {
+	w.openPage()
+	kind, number := &w.pages, strconv.Itoa(len(w.pages)+1)
+	title := w.title
+	if w.inContents {
+		kind, number, title = &w.contents, pdfRoman(len(w.contents)+1), pdfContentsTitle
+	}
+
+	var page bytes.Buffer
+	page.Write(w.page.Bytes())
+	page.WriteString("ET\n")
+	w.decorate(&page, title, number)
+
+	stream := w.out.allocate()
+	w.out.stream(stream, page.Bytes())
+	w.out.object(w.object, fmt.Sprintf(
+		"<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %s %s] "+
+			"/Resources << /Font << /F1 %d 0 R /F2 %d 0 R >> >> /Contents %d 0 R >>",
+		pdfObjPages, w.number(w.width), w.number(w.height), pdfObjFont, pdfObjFontBold, stream,
+	))
+	*kind = append(*kind, w.object)
+	w.object = 0
+}
+
+// decorate draws the header, the title in bold above a rule, and the centered page number.
+func (w *PDFWriter) decorate(page *bytes.Buffer, title, number string) {
+	headerY := w.height - pdfMargin - w.fontSize
+	ruleY := headerY - w.fontSize/2
+	footerX := (w.width - float64(len(number))*w.fontSize*pdfCharWidth) / 2
+
+	fmt.Fprintf(page, "BT /F2 %s Tf %s %s Td %s Tj ET\n",
+		w.number(w.fontSize), w.number(pdfMargin), w.number(headerY), pdfString(pdfFit(pdfEncode(nil, title), w.columns)))
+	fmt.Fprintf(page, "0.5 w %s %s m %s %s l S\n",
+		w.number(pdfMargin), w.number(ruleY), w.number(w.width-pdfMargin), w.number(ruleY))
+	fmt.Fprintf(page, "BT /F1 %s Tf %s %s Td (%s) Tj ET\n",
+		w.number(w.fontSize), w.number(footerX), w.number(pdfMargin), number)
+}
+
+// writeContents writes the table of contents pages, one row per file with its page number.
+func (w *PDFWriter) writeContents() {
+	if len(w.entries) == 0 {
+		return
+	}
+	w.inContents = true
+	w.text(pdfContentsTitle, true)
+	w.blank()
+	for _, entry := range w.entries {
+		number := strconv.Itoa(entry.page)
+		path := pdfFit(pdfEncode(nil, entry.path), w.columns-len(number)-2)
+		row := append(path, ' ')
+		row = append(row, bytes.Repeat([]byte{'.'}, max(w.columns-len(path)-len(number)-2, 0))...)
+		row = append(row, ' ')
+		w.writeRow(append(row, number...), false)
+	}
+	if w.object != 0 {
+		w.flushPage()
+	}
+}
+
+// writeDocument writes the page tree with the table of contents first, the bookmarks, the
+// catalog labelling the table of contents pages with roman numerals, and the trailer.
+func (w *PDFWriter) writeDocument() {
+	kids := make([]string, 0, len(w.contents)+len(w.pages))
+	for _, object := range append(w.contents, w.pages...) {
+		kids = append(kids, strconv.Itoa(object)+" 0 R")
+	}
+	w.out.object(pdfObjPages, fmt.Sprintf(
+		"<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(kids),
+	))
+
+	labels := "0 << /S /D >>"
+	if len(w.contents) > 0 {
+		labels = fmt.Sprintf("0 << /S /r >> %d << /S /D >>", len(w.contents))
+	}
+	catalog := fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R /PageLabels << /Nums [%s] >>", pdfObjPages, labels)
+	if outlines := w.writeOutlines(); outlines != 0 {
+		catalog += fmt.Sprintf(" /Outlines %d 0 R /PageMode /UseOutlines", outlines)
+	}
+	w.out.object(pdfObjCatalog, catalog+" >>")
+	w.out.trailer()
+}
+
+// writeOutlines writes a bookmark for every file and returns the object number of the outline
+// root, or zero without files.
+func (w *PDFWriter) writeOutlines() int {
+	if len(w.entries) == 0 {
+		return 0
+	}
+	root := w.out.allocate()
+	first := w.out.next
+	last := first + len(w.entries) - 1
+	w.out.next += len(w.entries)
+
+	for i, entry := range w.entries {
+		item := first + i
+		links := ""
+		if item > first {
+			links += fmt.Sprintf(" /Prev %d 0 R", item-1)
+		}
+		if item < last {
+			links += fmt.Sprintf(" /Next %d 0 R", item+1)
+		}
+		w.out.object(item, fmt.Sprintf("<< /Title %s /Parent %d 0 R%s /Dest [%d 0 R /XYZ 0 %s null] >>",
+			pdfTextString(entry.path), root, links, entry.object, w.number(entry.top)))
+	}
+	w.out.object(root, fmt.Sprintf(
+		"<< /Type /Outlines /First %d 0 R /Last %d 0 R /Count %d >>", first, last, len(w.entries),
+	))
+
+	return root
+}
+
+// check wraps the first write error of the document, if any.
+func (w *PDFWriter) check(message, path string) error {
+	if w.out.err == nil {
+		return nil
+	}
+	err := shared.WrapError(w.out.err, shared.ErrorTypeIO, shared.CodeIOWrite, message)
+	if path != "" {
+		return err.WithFilePath(path)
+	}
+
+	return err
+}
+
+// leading returns the distance between two baselines.
+func (w *PDFWriter) leading() float64 {
+	return w.fontSize * pdfLeading
+}
+
+// bodyTop returns the baseline of the first row of a page, two rows below the header.
+func (w *PDFWriter) bodyTop() float64 {
+	return w.height - pdfMargin - w.fontSize - 2*w.leading()
+}
+
+// number formats a coordinate or size for the document.
+func (w *PDFWriter) number(value float64) string {
+	return strconv.FormatFloat(value, 'f', 2, 64)
+}
+
+// startPDFWriter handles PDF format output with streaming support.
+func startPDFWriter(
+	outFile *os.File,
+	writeCh <-chan WriteRequest,
+	done chan<- struct{},
+	prefix, suffix string,
+	opts WriterOptions,
+) {
+	startFormatWriter(outFile, writeCh, done, prefix, suffix, opts, func(out outputWriter) FormatWriter {
+		return newPDFWriter(out, opts.settings())
+	})
+}
+
+// pdfOutput writes the objects of a document, recording their offsets for the cross-reference
+// table. It keeps the first write error, so a document is checked once rather than per write.
+type pdfOutput struct {
+	w      outputWriter
+	offset int64
+	// offsets holds the offset of every object by object number.
+	offsets []int64
+	next    int
+	err     error
+}
+
+// write writes s unless an earlier write failed.
+func (o *pdfOutput) write(s string) {
+	if o.err != nil {
+		return
+	}
+	n, err := o.w.WriteString(s)
+	o.offset += int64(n)
+	o.err = err
+}
+
+// allocate reserves the next object number.
+func (o *pdfOutput) allocate() int {
+	o.next++
+
+	return o.next - 1
+}
+
+// object writes object n holding body.
+func (o *pdfOutput) object(n int, body string) {
+	for len(o.offsets) <= n {
+		o.offsets = append(o.offsets, 0)
+	}
+	o.offsets[n] = o.offset
+	o.write(strconv.Itoa(n) + " 0 obj\n" + body + "\nendobj\n")
+}
+
+// stream writes object n as a stream holding data.
+func (o *pdfOutput) stream(n int, data []byte) {
+	o.object(n, fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(data), data))
+}
+
+// trailer writes the cross-reference table of every object written and the trailer.
+func (o *pdfOutput) trailer() {
+	start := o.offset
+	var xref strings.Builder
+	fmt.Fprintf(&xref, "xref\n0 %d\n0000000000 65535 f \n", o.next)
+	for n := 1; n < o.next; n++ {
+		fmt.Fprintf(&xref, "%010d 00000 n \n", o.offsets[n])
+	}
+	fmt.Fprintf(&xref, "trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", o.next, pdfObjCatalog, start)
+	o.write(xref.String())
+}
+
+// pdfFont returns the dictionary of a standard Type 1 font in WinAnsiEncoding.
+func pdfFont(name string) string {
+	return "<< /Type /Font /Subtype /Type1 /BaseFont /" + name + " /Encoding /WinAnsiEncoding >>"
+}
+
+// pdfEncode appends line to dst in WinAnsiEncoding, expanding tabs and replacing the control
+// characters and the runes the encoding lacks with '?'.
+func pdfEncode(dst []byte, line string) []byte {
+	start := len(dst)
+	for _, r := range line {
+		switch {
+		case r == '\t':
+			dst = append(dst, ' ')
+			for (len(dst)-start)%pdfTabWidth != 0 {
+				dst = append(dst, ' ')
+			}
+		case r >= ' ' && r < 0x7f, r >= 0xa0 && r <= 0xff:
+			dst = append(dst, byte(r))
+		default:
+			b, ok := pdfWinAnsi[r]
+			if !ok {
+				b = '?'
+			}
+			dst = append(dst, b)
+		}
+	}
+
+	return dst
+}
+
+// pdfFit shortens encoded text longer than columns, keeping its end after "...".
+func pdfFit(encoded []byte, columns int) []byte {
+	const ellipsis = "..."
+	if len(encoded) <= columns || columns <= len(ellipsis) {
+		return encoded
+	}
+
+	return append([]byte(ellipsis), encoded[len(encoded)-columns+len(ellipsis):]...)
+}
+
+// pdfString returns encoded text as a literal string, escaping the delimiters and writing the
+// bytes outside printable ASCII as octal escapes.
+func pdfString(encoded []byte) string {
+	var b strings.Builder
+	b.WriteByte('(')
+	for _, c := range encoded {
+		switch {
+		case c == '\\' || c == '(' || c == ')':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < ' ' || c >= 0x7f:
+			fmt.Fprintf(&b, "\\%03o", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte(')')
+
+	return b.String()
+}
+
+// pdfTextString returns s as a UTF-16BE hex string, which bookmarks show with any rune.
+func pdfTextString(s string) string {
+	units := utf16.Encode([]rune(s))
+	encoded := make([]byte, 0, 2+2*len(units))
+	encoded = append(encoded, 0xfe, 0xff)
+	for _, unit := range units {
+		encoded = append(encoded, byte(unit>>8), byte(unit))
+	}
+
+	return "<" + hex.EncodeToString(encoded) + ">"
+}
+
+// pdfRoman returns n in lower case roman numerals, numbering the table of contents pages.
+func pdfRoman(n int) string {
+	numerals := []struct {
+		value  int
+		symbol string
+	}{
+		{1000, "m"}, {900, "cm"}, {500, "d"}, {400, "cd"}, {100, "c"}, {90, "xc"},
+		{50, "l"}, {40, "xl"}, {10, "x"}, {9, "ix"}, {5, "v"}, {4, "iv"}, {1, "i"},
+	}
+	var b strings.Builder
+	for _, numeral := range numerals {
+		for ; n >= numeral.value; n -= numeral.value {
+			b.WriteString(numeral.symbol)
+		}
+	}
+
+	return b.String()
+}
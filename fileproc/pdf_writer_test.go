@@ -0,0 +1,83 @@
+package fileproc_test
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/fileproc"
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// TestPDFWriter tests the pdf format: a valid cross-reference table, the table of contents
+// ordered first and labelled in roman numerals, a bookmark per file, and the path of the file
+// in the header of every page it continues on.
+func TestPDFWriter(t *testing.T) {
+	testutil.ResetViperConfig(t, "")
+	testutil.SetViperKeys(t, map[string]any{shared.ConfigKeyOutputPDFPageSize: shared.PDFPageSizeLetter})
+
+	long := strings.Repeat("x := 1\n", 150)
+	output := writeWithFormatWorkers(t, shared.FormatPDF, 1, []fileproc.WriteRequest{
+		{Path: "main.go", Content: long, Metadata: map[string]string{"note": "reviewed"}},
+		{Path: "docs/naïve (draft).md", Content: "tab\there – €5 ✓\n"},
+		{Path: "large.txt", IsStream: true, Reader: strings.NewReader(long)},
+	})
+
+	verifyPDFCrossReferences(t, output)
+
+	checks := []struct {
+		name, want string
+	}{
+		{"letter pages", "/MediaBox [0 0 612.00 792.00]"},
+		{"pages with contents first", "/Count 6 >>"},
+		{"roman contents labels", "/PageLabels << /Nums [0 << /S /r >> 1 << /S /D >>] >>"},
+		{"contents row", "(docs/na\\357ve \\(draft\\).md ."},
+		{"continued page header", "/F2 8.00 Tf 36.00 748.00 Td (main.go) Tj ET"},
+		{"metadata", "(note: reviewed) '"},
+		{"encoded text", "(tab here \\226 \\2005 ?) '"},
+		{"prefix", "(prefix) '"},
+		{"suffix", "(suffix) '"},
+		{"bookmark", "/Title <feff006d00610069006e002e0067006f>"},
+	}
+	for _, check := range checks {
+		if !bytes.Contains(output, []byte(check.want)) {
+			t.Errorf("%s: %q not found in the document", check.name, check.want)
+		}
+	}
+
+	contents := regexp.MustCompile(`\(large\.txt \.+ (\d+)\) '`).FindSubmatch(output)
+	if contents == nil || string(contents[1]) != "3" {
+		t.Errorf("contents row of large.txt = %q, want page 3", contents)
+	}
+}
+
+// verifyPDFCrossReferences checks that startxref points at the cross-reference table and every
+// entry of the table at its object.
+func verifyPDFCrossReferences(t *testing.T, output []byte) {
+	t.Helper()
+
+	match := regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`).FindSubmatch(output)
+	if match == nil {
+		t.Fatal("startxref not found at the end of the document")
+	}
+	start, err := strconv.Atoi(string(match[1]))
+	testutil.MustSucceed(t, err, "parsing startxref")
+	if !bytes.HasPrefix(output[start:], []byte("xref\n0 ")) {
+		t.Fatalf("startxref %d does not point at the cross-reference table", start)
+	}
+
+	entries := regexp.MustCompile(`(\d{10}) 00000 n \n`).FindAllSubmatch(output[start:], -1)
+	if len(entries) == 0 {
+		t.Fatal("cross-reference table is empty")
+	}
+	for i, entry := range entries {
+		offset, err := strconv.Atoi(string(entry[1]))
+		testutil.MustSucceed(t, err, "parsing offset")
+		if want := strconv.Itoa(i+1) + " 0 obj\n"; !bytes.HasPrefix(output[offset:], []byte(want)) {
+			t.Errorf("object %d is not at offset %d", i+1, offset)
+		}
+	}
+}
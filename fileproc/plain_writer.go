@@ -0,0 +1,191 @@
+// Package fileproc handles file processing, collection, and output formatting.
+package fileproc
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ivuorinen/gibidify/config"
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// PlainWriter handles plain text output: every file starts with a delimiter line naming it,
+// followed by its metadata and its content as is, so content holding Markdown fences or
+// JSON needs no escaping.
+type PlainWriter struct {
+	outFile   outputWriter
+	suffix    string
+	delimiter string
+}
+
+// NewPlainWriter creates a new plain text writer using output.plain.delimiter.
+func NewPlainWriter(outFile *os.File) *PlainWriter {
+	return newPlainWriter(outFile, config.Global())
+}
+
+// newPlainWriter creates a plain text writer for any output.
+func newPlainWriter(out outputWriter, settings *config.Settings) *PlainWriter {
+	return &PlainWriter{outFile: out, delimiter: plainDelimiter(settings)}
+}
+
+// plainDelimiter returns the output.plain.delimiter of settings, or the default when it does
+// not name the file.
+func plainDelimiter(settings *config.Settings) string {
+	if delimiter := settings.OutputPlainDelimiter(); strings.Contains(delimiter, shared.PlainDelimiterPath) {
+		return delimiter
+	}
+
+	return shared.ConfigPlainDelimiterDefault
+}
+
+// Start writes the prefix line and stores the suffix for later use.
+func (w *PlainWriter) Start(prefix, suffix string) error {
+	w.suffix = suffix
+
+	if prefix != "" {
+		if _, err := fmt.Fprintf(w.outFile, "%s\n\n", prefix); err != nil {
+			return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOWrite, "failed to write prefix")
+		}
+	}
+
+	return nil
+}
+
+// WriteFile writes a file entry in plain text format.
+func (w *PlainWriter) WriteFile(req WriteRequest) error {
+	if req.IsStream {
+		return w.writeStreaming(req)
+	}
+
+	formatted, err := renderedEntry(req, w)
+	if err != nil {
+		return err
+	}
+	defer shared.ScratchBuffers.Put(formatted)
+
+	if _, err := w.outFile.Write(*formatted); err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOWrite, "failed to write inline content").
+			WithFilePath(req.Path)
+	}
+
+	return nil
+}
+
+// Close writes the suffix line stored in Start.
+func (w *PlainWriter) Close() error {
+	if w.suffix != "" {
+		if _, err := fmt.Fprintf(w.outFile, "%s\n", w.suffix); err != nil {
+			return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOWrite, "failed to write suffix")
+		}
+	}
+
+	return nil
+}
+
+// CloseWithSummary writes the suffix followed by the one-line run summary.
+func (w *PlainWriter) CloseWithSummary(summary RunSummary) error {
+	if err := w.Close(); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w.outFile, "Run summary: %s\n", summary.sentence()); err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOWrite, "failed to write run summary")
+	}
+
+	return nil
+}
+
+// writeStreaming writes a large file in streaming chunks.
+func (w *PlainWriter) writeStreaming(req WriteRequest) error {
+	defer shared.SafeCloseReader(req.Reader, req.Path)
+
+	if _, err := w.outFile.WriteString(w.header(req)); err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOWrite, "failed to write file header").
+			WithFilePath(req.Path)
+	}
+
+	content := &lastByteReader{reader: req.Reader}
+	if err := shared.StreamContent(content, w.outFile, shared.FileProcessingStreamChunkSize, req.Path, nil); err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOWrite, "streaming content for plain file")
+	}
+
+	footer := "\n"
+	if content.last != '\n' && content.read {
+		footer = "\n\n"
+	}
+	if _, err := w.outFile.WriteString(footer); err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOWrite, "failed to write file footer").
+			WithFilePath(req.Path)
+	}
+
+	return nil
+}
+
+// renderInline renders a small file as a plain text section.
+func (w *PlainWriter) renderInline(dst []byte, req WriteRequest) ([]byte, error) {
+	dst = append(dst, w.header(req)...)
+	dst = append(dst, req.Content...)
+	if req.Content != "" && !strings.HasSuffix(req.Content, "\n") {
+		dst = append(dst, '\n')
+	}
+
+	return append(dst, '\n'), nil
+}
+
+// header returns the delimiter line of req followed by its metadata, one "key: value" line
+// each with continuation lines indented, and a blank line when there is metadata.
+func (w *PlainWriter) header(req WriteRequest) string {
+	var b strings.Builder
+	b.WriteString(strings.Replace(w.delimiter, shared.PlainDelimiterPath, req.Path, 1) + "\n")
+	for _, key := range sortedMetadataKeys(req.Metadata) {
+		fmt.Fprintf(&b, "%s: %s\n", key, strings.ReplaceAll(req.Metadata[key], "\n", "\n  "))
+	}
+	if len(req.Metadata) > 0 {
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// lastByteReader remembers the last byte read, so the section can end with exactly one newline.
+type lastByteReader struct {
+	reader io.Reader
+	last   byte
+	read   bool
+}
+
+// Read reads from the underlying reader, recording the last byte.
+func (r *lastByteReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		r.last, r.read = p[n-1], true
+	}
+
+	return n, err
+}
+
+// startPlainWriter handles plain text format output with streaming support.
+func startPlainWriter(
+	outFile *os.File,
+	writeCh <-chan WriteRequest,
+	done chan<- struct{},
+	prefix, suffix string,
+	opts WriterOptions,
+) {
+	startFormatWriter(outFile, writeCh, done, prefix, suffix, opts, func(out outputWriter) FormatWriter {
+		return newPlainWriter(out, opts.settings())
+	})
+}
+
+// plainSectionPath returns the path named by a delimiter line of a plain bundle written with
+// delimiter, and whether line is one.
+func plainSectionPath(delimiter, line string) (string, bool) {
+	before, after, ok := strings.Cut(delimiter, shared.PlainDelimiterPath)
+	if !ok || len(line) <= len(before)+len(after) ||
+		!strings.HasPrefix(line, before) || !strings.HasSuffix(line, after) {
+		return "", false
+	}
+
+	return line[len(before) : len(line)-len(after)], true
+}
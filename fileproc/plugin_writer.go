@@ -0,0 +1,190 @@
+// Package fileproc handles file processing, collection, and output formatting.
+package fileproc
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"os/exec"
+
+	"github.com/ivuorinen/gibidify/config"
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// PluginProtocolVersion is the version of the event stream format plugins read, sent in the
+// start event so plugins can reject streams they do not understand.
+const PluginProtocolVersion = 1
+
+// Events of the format plugin protocol, one JSON object per line on the plugin's stdin.
+const (
+	pluginEventStart = "start"
+	pluginEventFile  = "file"
+	pluginEventEnd   = "end"
+)
+
+// pluginStart is the first event: the protocol version, the format, the run ID and the bundle
+// settings.
+type pluginStart struct {
+	Event    string         `json:"event"`
+	Protocol int            `json:"protocol"`
+	Format   string         `json:"format"`
+	RunID    string         `json:"runId,omitempty"`
+	Prefix   string         `json:"prefix"`
+	Suffix   string         `json:"suffix"`
+	Config   map[string]any `json:"config,omitempty"`
+}
+
+// pluginFile is the event of one file section, with the fields of a JSON bundle entry.
+type pluginFile struct {
+	Event string `json:"event"`
+	FileData
+	Size int64 `json:"size"`
+}
+
+// pluginEnd is the last event, with the run summary when output.appendRunSummary is set.
+type pluginEnd struct {
+	Event   string      `json:"event"`
+	Summary *RunSummary `json:"summary,omitempty"`
+}
+
+// PluginWriter writes the bundle through an external format plugin registered in
+// output.plugins: it starts the plugin command with the bundle output as its stdout and
+// streams it the bundle as NDJSON events on stdin, a start event, one file event per file
+// section and an end event. The plugin writes the bundle once its stdin is closed, or as the
+// events arrive.
+type PluginWriter struct {
+	format   string
+	command  []string
+	out      io.Writer
+	registry *FileTypeRegistry
+	runID    string
+	cmd      *exec.Cmd
+	stdin    io.WriteCloser
+	events   *bufio.Writer
+	encoder  *json.Encoder
+}
+
+// newPluginWriter creates a writer for the plugin of format, writing the bundle of the run
+// runID to out.
+func newPluginWriter(
+	out io.Writer,
+	format string,
+	plugin config.FormatPlugin,
+	registry *FileTypeRegistry,
+	runID string,
+) *PluginWriter {
+	return &PluginWriter{format: format, command: plugin.Command, out: out, registry: registry, runID: runID}
+}
+
+// Start starts the plugin and sends it the start event.
+func (w *PluginWriter) Start(prefix, suffix string) error {
+	return w.StartWithConfig(prefix, suffix, nil)
+}
+
+// StartWithConfig starts the plugin and sends it the start event with settings.
+func (w *PluginWriter) StartWithConfig(prefix, suffix string, settings map[string]any) error {
+	w.cmd = exec.Command(w.command[0], w.command[1:]...) // #nosec G204 -- the user configures plugin commands
+	w.cmd.Stdout = w.out
+	w.cmd.Stderr = os.Stderr
+	stdin, err := w.cmd.StdinPipe()
+	if err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOWrite, "failed to connect to format plugin")
+	}
+	if err := w.cmd.Start(); err != nil {
+		return shared.WrapError(err, shared.ErrorTypeProcessing, shared.CodeProcessingEncode,
+			"failed to start format plugin "+w.command[0])
+	}
+	w.stdin = stdin
+	w.events = bufio.NewWriter(stdin)
+	w.encoder = json.NewEncoder(w.events)
+	w.encoder.SetEscapeHTML(false)
+
+	return w.send(pluginStart{
+		Event:    pluginEventStart,
+		Protocol: PluginProtocolVersion,
+		Format:   w.format,
+		RunID:    w.runID,
+		Prefix:   prefix,
+		Suffix:   suffix,
+		Config:   settings,
+	})
+}
+
+// WriteFile sends the file event of a file section. Streamed content is read in full, as an
+// event holds the whole content.
+func (w *PluginWriter) WriteFile(req WriteRequest) error {
+	content := req.Content
+	if req.IsStream {
+		defer shared.SafeCloseReader(req.Reader, req.Path)
+		data, err := io.ReadAll(req.Reader)
+		if err != nil {
+			return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIORead, "failed to read streamed content").
+				WithFilePath(req.Path)
+		}
+		content = string(data)
+	}
+
+	return w.send(pluginFile{
+		Event: pluginEventFile,
+		FileData: FileData{
+			Path:     req.Path,
+			Content:  content,
+			Language: entryLanguage(req, w.registry),
+			Metadata: req.Metadata,
+		},
+		Size: req.Size,
+	})
+}
+
+// Close sends the end event, closes the plugin's stdin and waits for it to write the bundle.
+func (w *PluginWriter) Close() error {
+	return w.finish(pluginEnd{Event: pluginEventEnd})
+}
+
+// CloseWithSummary sends the end event with the run summary and waits for the plugin.
+func (w *PluginWriter) CloseWithSummary(summary RunSummary) error {
+	return w.finish(pluginEnd{Event: pluginEventEnd, Summary: &summary})
+}
+
+// finish sends end, closes the plugin's stdin and waits for the plugin to exit.
+func (w *PluginWriter) finish(end pluginEnd) error {
+	sendErr := w.send(end)
+	if err := w.stdin.Close(); err != nil && sendErr == nil {
+		sendErr = shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOWrite, "failed to close format plugin input")
+	}
+	if err := w.cmd.Wait(); err != nil {
+		return shared.WrapError(err, shared.ErrorTypeProcessing, shared.CodeProcessingEncode,
+			"format plugin "+w.command[0]+" failed")
+	}
+
+	return sendErr
+}
+
+// send writes event to the plugin as one line of JSON, flushed so the plugin can act on it.
+func (w *PluginWriter) send(event any) error {
+	if err := w.encoder.Encode(event); err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOWrite, "failed to write to format plugin")
+	}
+	if err := w.events.Flush(); err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOWrite, "failed to write to format plugin")
+	}
+
+	return nil
+}
+
+// startPluginWriter handles output through the format plugin of format. The plugin writes to
+// outFile itself, so its output bypasses the output buffer and is not counted in its stats.
+func startPluginWriter(
+	outFile *os.File,
+	writeCh <-chan WriteRequest,
+	done chan<- struct{},
+	prefix, suffix string,
+	opts WriterOptions,
+	format string,
+	plugin config.FormatPlugin,
+) {
+	startFormatWriter(outFile, writeCh, done, prefix, suffix, opts, func(outputWriter) FormatWriter {
+		return newPluginWriter(outFile, format, plugin, opts.registry(), opts.RunID)
+	})
+}
@@ -0,0 +1,99 @@
+//go:build unix
+
+package fileproc_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/fileproc"
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// writeWithPlugin writes entries through the output.plugins format named format and returns
+// the bundle the plugin wrote.
+func writeWithPlugin(t *testing.T, format string, entries []fileproc.WriteRequest) []byte {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "bundle."+format)
+	outFile, err := os.Create(path)
+	testutil.MustSucceed(t, err, "creating output")
+	writeCh := make(chan fileproc.WriteRequest, len(entries))
+	for _, entry := range entries {
+		writeCh <- entry
+	}
+	close(writeCh)
+
+	done := make(chan struct{})
+	opts := fileproc.WriterOptions{RunID: "20261015T093012-9f3a61c2"}
+	fileproc.StartWriterWithOptions(outFile, writeCh, done, format, "prefix", "suffix", opts)
+	<-done
+	testutil.MustSucceed(t, outFile.Close(), "closing output")
+
+	data, err := os.ReadFile(path)
+	testutil.MustSucceed(t, err, "reading output")
+
+	return data
+}
+
+// TestPluginWriterEvents tests the events a format plugin receives, using cat as a plugin
+// that writes them out unchanged.
+func TestPluginWriterEvents(t *testing.T) {
+	testutil.SetViperKeys(t, map[string]any{
+		shared.ConfigKeyOutputPlugins: map[string]any{"events": map[string]any{"command": []string{"cat"}}},
+	})
+
+	data := writeWithPlugin(t, "events", []fileproc.WriteRequest{
+		{Path: "main.go", Content: "package main\n", Size: 13, Metadata: map[string]string{"note": "entry"}},
+		{Path: "big.md", Reader: strings.NewReader("# Big <doc>\n"), IsStream: true, Size: 12},
+	})
+
+	var events []map[string]any
+	for line := range bytes.Lines(data) {
+		var event map[string]any
+		testutil.MustSucceed(t, json.Unmarshal(line, &event), "decoding event "+string(line))
+		events = append(events, event)
+	}
+	if len(events) != 4 {
+		t.Fatalf("events = %v, want start, two files and end", events)
+	}
+
+	start, first, second, end := events[0], events[1], events[2], events[3]
+	if start["event"] != "start" || start["protocol"] != float64(fileproc.PluginProtocolVersion) ||
+		start["format"] != "events" || start["runId"] != "20261015T093012-9f3a61c2" ||
+		start["prefix"] != "prefix" || start["suffix"] != "suffix" {
+		t.Errorf("start event = %v", start)
+	}
+	if first["event"] != "file" || first["path"] != "main.go" || first["content"] != "package main\n" ||
+		first["language"] != "go" || first["size"] != float64(13) ||
+		first["metadata"].(map[string]any)["note"] != "entry" {
+		t.Errorf("first file event = %v", first)
+	}
+	if second["path"] != "big.md" || second["content"] != "# Big <doc>\n" || second["language"] != "markdown" {
+		t.Errorf("streamed file event = %v", second)
+	}
+	if end["event"] != "end" {
+		t.Errorf("end event = %v", end)
+	}
+	if !bytes.Contains(data, []byte("<doc>")) {
+		t.Errorf("events escape HTML: %s", data)
+	}
+}
+
+// TestPluginWriterFailure tests that a plugin exiting early ends the write without output.
+func TestPluginWriterFailure(t *testing.T) {
+	testutil.SetViperKeys(t, map[string]any{
+		shared.ConfigKeyOutputPlugins: map[string]any{"broken": map[string]any{"command": []string{"false"}}},
+	})
+	defer testutil.SuppressLogs(t)()
+
+	data := writeWithPlugin(t, "broken", []fileproc.WriteRequest{{Path: "a.go", Content: "package a\n"}})
+	if len(data) != 0 {
+		t.Errorf("output = %q, want none from a failing plugin", data)
+	}
+}
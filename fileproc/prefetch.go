@@ -0,0 +1,346 @@
+// Package fileproc handles file processing, collection, and output formatting.
+package fileproc
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ivuorinen/gibidify/chaos"
+	"github.com/ivuorinen/gibidify/config"
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// prefetchQueueSize is how many upcoming files may wait for a prefetch reader; files added
+// while the queue is full are read by their worker.
+const prefetchQueueSize = 1024
+
+// prefetchState is how far a prefetch reader got with a file.
+type prefetchState int
+
+const (
+	prefetchQueued prefetchState = iota
+	prefetchReading
+	prefetchDone
+)
+
+// prefetchEntry is an upcoming file read ahead of its worker.
+type prefetchEntry struct {
+	path  string
+	state prefetchState
+	// claimed is set once a worker took or discarded the file; a reader does not start it then.
+	claimed bool
+	// wanted is set when the worker taking the file waits for the read in progress.
+	wanted bool
+	buf    *[]byte
+	// size is how many bytes of the budget the file holds.
+	size int64
+	err  error
+	done chan struct{}
+}
+
+// content returns the prefetched content of the file.
+func (e *prefetchEntry) content() []byte {
+	if e.buf == nil {
+		return nil
+	}
+
+	return *e.buf
+}
+
+// PrefetchStats reports how much a Prefetcher read ahead of the workers.
+type PrefetchStats struct {
+	// Files is the number of files read ahead, Bytes their content.
+	Files int64
+	Bytes int64
+	// Hits is the number of files workers took from the prefetcher, Misses how many they read themselves.
+	Hits   int64
+	Misses int64
+}
+
+// Prefetcher reads upcoming small files into buffers from shared.PrefetchBuffers while the
+// workers format the files before them, hiding filesystem latency such as that of network
+// mounts. The content held at once is bounded by a byte budget; files that do not fit wait for
+// the workers to release content, and a worker reaching a file not read yet reads it itself.
+// A nil Prefetcher reads nothing ahead.
+type Prefetcher struct {
+	budget int64
+	queue  chan *prefetchEntry
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	freed   *sync.Cond
+	used    int64
+	entries map[string]*prefetchEntry
+	closed  bool
+
+	files  atomic.Int64
+	bytes  atomic.Int64
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// NewPrefetcher creates a prefetcher holding at most budget bytes of content, read by readers
+// goroutines. Close stops it.
+func NewPrefetcher(budget int64, readers int) *Prefetcher {
+	pf := &Prefetcher{
+		budget:  budget,
+		queue:   make(chan *prefetchEntry, prefetchQueueSize),
+		entries: make(map[string]*prefetchEntry),
+	}
+	pf.freed = sync.NewCond(&pf.mu)
+	for range max(1, readers) {
+		pf.wg.Go(pf.read)
+	}
+
+	return pf
+}
+
+// NewPrefetcherWithSettings creates a prefetcher with the prefetch configuration in settings,
+// or returns nil when performance.prefetch is off. Without a performance.prefetchBytes budget
+// prefetched content may hold a shared.PrefetchMemoryShare of backpressure.maxMemoryUsage.
+func NewPrefetcherWithSettings(settings *config.Settings) *Prefetcher {
+	if !settings.PerformancePrefetch() {
+		return nil
+	}
+
+	return NewPrefetcher(PrefetchBudget(settings), settings.PerformancePrefetchReaders())
+}
+
+// PrefetchBudget returns how many bytes of prefetched content settings allow at once.
+func PrefetchBudget(settings *config.Settings) int64 {
+	if budget := settings.PerformancePrefetchBytes(); budget > 0 {
+		return budget
+	}
+
+	return settings.MaxMemoryUsage() / shared.PrefetchMemoryShare
+}
+
+// Add queues the file at path to be read ahead. Call it before handing the file to a worker.
+func (pf *Prefetcher) Add(path string) {
+	if pf == nil {
+		return
+	}
+	pf.mu.Lock()
+	defer pf.mu.Unlock()
+	if _, queued := pf.entries[path]; queued || pf.closed {
+		return
+	}
+	entry := &prefetchEntry{path: path, done: make(chan struct{})}
+	select {
+	case pf.queue <- entry:
+		pf.entries[path] = entry
+	default:
+	}
+}
+
+// Stats returns how much the prefetcher read ahead so far.
+func (pf *Prefetcher) Stats() PrefetchStats {
+	if pf == nil {
+		return PrefetchStats{}
+	}
+
+	return PrefetchStats{
+		Files:  pf.files.Load(),
+		Bytes:  pf.bytes.Load(),
+		Hits:   pf.hits.Load(),
+		Misses: pf.misses.Load(),
+	}
+}
+
+// Close stops the readers and frees the content no worker took.
+func (pf *Prefetcher) Close() {
+	if pf == nil {
+		return
+	}
+	pf.mu.Lock()
+	if pf.closed {
+		pf.mu.Unlock()
+
+		return
+	}
+	pf.closed = true
+	close(pf.queue)
+	pf.freed.Broadcast()
+	pf.mu.Unlock()
+
+	pf.wg.Wait()
+
+	pf.mu.Lock()
+	defer pf.mu.Unlock()
+	for path, entry := range pf.entries {
+		if entry.state == prefetchDone {
+			pf.releaseLocked(entry)
+		}
+		delete(pf.entries, path)
+	}
+}
+
+// read reads queued files until the prefetcher is closed.
+func (pf *Prefetcher) read() {
+	for entry := range pf.queue {
+		pf.fetch(entry)
+	}
+}
+
+// fetch reads entry once the budget has room for it, unless its worker claims it first.
+// Files streamed rather than read in full, and files larger than the budget, are left to
+// their worker.
+func (pf *Prefetcher) fetch(entry *prefetchEntry) {
+	info, err := os.Stat(entry.path)
+	if err != nil || !info.Mode().IsRegular() || info.Size() > shared.FileProcessingStreamThreshold ||
+		info.Size() > pf.budget || !pf.reserve(entry, info.Size()) {
+		pf.abandon(entry)
+
+		return
+	}
+
+	buf := shared.PrefetchBuffers.Get()
+	err = chaos.BeforeRead()
+	if err == nil {
+		*buf, err = readPrefetched(entry.path, *buf, info.Size())
+	}
+
+	pf.mu.Lock()
+	defer pf.mu.Unlock()
+	defer close(entry.done)
+	entry.state = prefetchDone
+	if err != nil {
+		shared.PrefetchBuffers.Put(buf)
+		entry.err = err
+		pf.releaseLocked(entry)
+
+		return
+	}
+	pf.used += int64(len(*buf)) - entry.size
+	entry.buf, entry.size = buf, int64(len(*buf))
+	pf.files.Add(1)
+	pf.bytes.Add(entry.size)
+	if entry.claimed && !entry.wanted {
+		pf.releaseLocked(entry)
+	}
+}
+
+// reserve waits until size bytes fit the budget and counts them against it, reporting false
+// when a worker claimed entry or the prefetcher was closed first.
+func (pf *Prefetcher) reserve(entry *prefetchEntry, size int64) bool {
+	pf.mu.Lock()
+	defer pf.mu.Unlock()
+	for !entry.claimed && !pf.closed && pf.used+size > pf.budget {
+		pf.freed.Wait()
+	}
+	if entry.claimed || pf.closed {
+		return false
+	}
+	entry.state = prefetchReading
+	entry.size = size
+	pf.used += size
+
+	return true
+}
+
+// abandon leaves entry to its worker.
+func (pf *Prefetcher) abandon(entry *prefetchEntry) {
+	pf.mu.Lock()
+	defer pf.mu.Unlock()
+	if !entry.claimed && pf.entries[entry.path] == entry {
+		delete(pf.entries, entry.path)
+	}
+	entry.claimed = true
+}
+
+// take returns the entry read ahead for path, waiting for a read in progress, or nil when
+// the file was not read ahead and the caller reads it itself. Content taken is released with
+// release once it is no longer used.
+func (pf *Prefetcher) take(path string) *prefetchEntry {
+	if pf == nil {
+		return nil
+	}
+	pf.mu.Lock()
+	entry, ok := pf.entries[path]
+	if ok {
+		delete(pf.entries, path)
+		entry.claimed = true
+		entry.wanted = entry.state != prefetchQueued
+	}
+	pf.freed.Broadcast()
+	pf.mu.Unlock()
+
+	if !ok || !entry.wanted {
+		pf.misses.Add(1)
+
+		return nil
+	}
+	<-entry.done
+	pf.hits.Add(1)
+
+	return entry
+}
+
+// discard frees the content read ahead for path when its worker does not take it, for
+// example because the file was skipped.
+func (pf *Prefetcher) discard(path string) {
+	if pf == nil {
+		return
+	}
+	pf.mu.Lock()
+	defer pf.mu.Unlock()
+	entry, ok := pf.entries[path]
+	if !ok {
+		return
+	}
+	delete(pf.entries, path)
+	entry.claimed = true
+	if entry.state == prefetchDone {
+		pf.releaseLocked(entry)
+	}
+	pf.freed.Broadcast()
+}
+
+// release frees the content of entry taken with take.
+func (pf *Prefetcher) release(entry *prefetchEntry) {
+	pf.mu.Lock()
+	defer pf.mu.Unlock()
+	pf.releaseLocked(entry)
+}
+
+// releaseLocked returns the buffer of entry to the pool and its bytes to the budget.
+func (pf *Prefetcher) releaseLocked(entry *prefetchEntry) {
+	shared.PrefetchBuffers.Put(entry.buf)
+	entry.buf = nil
+	pf.used -= entry.size
+	entry.size = 0
+	pf.freed.Broadcast()
+}
+
+// readPrefetched reads the file at path, expected to hold size bytes, into buf within the
+// open file bound.
+func readPrefetched(path string, buf []byte, size int64) ([]byte, error) {
+	file, err := openLimited(path)
+	if err != nil {
+		return buf, err
+	}
+	defer func() {
+		shared.LogError("Error closing prefetched file", file.Close())
+	}()
+
+	// One spare byte lets the read see the end of the file without growing buf
+	buf = slices.Grow(buf[:0], int(size)+1)
+	for {
+		if len(buf) == cap(buf) {
+			buf = slices.Grow(buf, bytes.MinRead)
+		}
+		n, err := file.Read(buf[len(buf):cap(buf)])
+		buf = buf[:len(buf)+n]
+		if errors.Is(err, io.EOF) {
+			return buf, nil
+		}
+		if err != nil {
+			return buf, err //nolint:wrapcheck // callers wrap read errors
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package fileproc
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ivuorinen/gibidify/config"
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// createPrefetchFiles creates count files of size bytes each and returns their paths.
+func createPrefetchFiles(t *testing.T, count, size int) []string {
+	t.Helper()
+	dir := t.TempDir()
+	paths := make([]string, count)
+	for i := range paths {
+		content := strings.Repeat(fmt.Sprintf("%d", i%10), size)
+		paths[i] = testutil.CreateTestFile(t, dir, fmt.Sprintf("file%d.go", i), []byte(content))
+	}
+
+	return paths
+}
+
+// waitPrefetched waits until pf read files files ahead.
+func waitPrefetched(t *testing.T, pf *Prefetcher, files int64) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for pf.Stats().Files < files {
+		if time.Now().After(deadline) {
+			t.Fatalf("prefetched %d files, want %d", pf.Stats().Files, files)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// usedBytes returns the bytes of prefetched content pf holds.
+func usedBytes(pf *Prefetcher) int64 {
+	pf.mu.Lock()
+	defer pf.mu.Unlock()
+
+	return pf.used
+}
+
+// TestPrefetcherReadContent tests that workers get the content of prefetched files and that
+// it returns to the budget once released.
+func TestPrefetcherReadContent(t *testing.T) {
+	tests := []struct {
+		name     string
+		budget   int64
+		files    int
+		size     int
+		wantHeld int64
+	}{
+		{name: "all files fit", budget: shared.BytesPerMB, files: 5, size: 100, wantHeld: 500},
+		{name: "budget holds one file", budget: 100, files: 3, size: 100, wantHeld: 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paths := createPrefetchFiles(t, tt.files, tt.size)
+			pf := NewPrefetcher(tt.budget, 2)
+			defer pf.Close()
+			for _, path := range paths {
+				pf.Add(path)
+			}
+			waitPrefetched(t, pf, tt.wantHeld/int64(tt.size))
+			if held := usedBytes(pf); held != tt.wantHeld {
+				t.Errorf("held %d bytes, want %d", held, tt.wantHeld)
+			}
+
+			p := &FileProcessor{prefetch: pf}
+			for i, path := range paths {
+				content, release, err := p.readContent(path)
+				testutil.MustSucceed(t, err, "reading "+path)
+				if want := strings.Repeat(fmt.Sprintf("%d", i%10), tt.size); string(content) != want {
+					t.Errorf("content of %s = %q, want %q", path, content, want)
+				}
+				release()
+			}
+			if held := usedBytes(pf); held != 0 {
+				t.Errorf("held %d bytes after releasing every file, want 0", held)
+			}
+			if stats := pf.Stats(); stats.Hits+stats.Misses != int64(tt.files) || stats.Hits == 0 {
+				t.Errorf("stats = %+v, want hits and misses adding up to %d with some hits", stats, tt.files)
+			}
+		})
+	}
+}
+
+// TestPrefetcherDiscard tests that content no worker takes returns to the budget.
+func TestPrefetcherDiscard(t *testing.T) {
+	paths := createPrefetchFiles(t, 1, 100)
+	pf := NewPrefetcher(shared.BytesPerMB, 1)
+	defer pf.Close()
+	pf.Add(paths[0])
+	waitPrefetched(t, pf, 1)
+
+	pf.discard(paths[0])
+	if held := usedBytes(pf); held != 0 {
+		t.Errorf("held %d bytes after discard, want 0", held)
+	}
+	if entry := pf.take(paths[0]); entry != nil {
+		t.Error("take() after discard returned prefetched content")
+	}
+}
+
+// TestPrefetcherLeavesLargeFiles tests that files larger than the budget are read by their worker.
+func TestPrefetcherLeavesLargeFiles(t *testing.T) {
+	paths := createPrefetchFiles(t, 1, 200)
+	pf := NewPrefetcher(100, 1)
+	pf.Add(paths[0])
+	pf.Close()
+
+	if stats := pf.Stats(); stats.Files != 0 {
+		t.Errorf("prefetched %d files larger than the budget, want 0", stats.Files)
+	}
+	p := &FileProcessor{prefetch: pf}
+	content, release, err := p.readContent(paths[0])
+	testutil.MustSucceed(t, err, "reading "+paths[0])
+	defer release()
+	if len(content) != 200 {
+		t.Errorf("read %d bytes, want 200", len(content))
+	}
+}
+
+// TestNewPrefetcherWithSettings tests that prefetching is off by default and that its budget
+// defaults to a share of backpressure.maxMemoryUsage.
+func TestNewPrefetcherWithSettings(t *testing.T) {
+	settings := config.NewSettings()
+	if pf := NewPrefetcherWithSettings(settings); pf != nil {
+		pf.Close()
+		t.Fatal("NewPrefetcherWithSettings() created a prefetcher with performance.prefetch off")
+	}
+
+	settings.Set(shared.ConfigKeyPerformancePrefetch, true)
+	pf := NewPrefetcherWithSettings(settings)
+	defer pf.Close()
+	if want := settings.MaxMemoryUsage() / shared.PrefetchMemoryShare; pf.budget != want {
+		t.Errorf("budget = %d, want %d", pf.budget, want)
+	}
+
+	settings.Set(shared.ConfigKeyPerformancePrefetchBytes, 4096)
+	if budget := PrefetchBudget(settings); budget != 4096 {
+		t.Errorf("PrefetchBudget() = %d with performance.prefetchBytes 4096, want 4096", budget)
+	}
+}
@@ -6,12 +6,14 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/ivuorinen/gibidify/chaos"
 	"github.com/ivuorinen/gibidify/config"
 	"github.com/ivuorinen/gibidify/shared"
 )
@@ -22,7 +24,11 @@ type WriteRequest struct {
 	Content  string
 	IsStream bool
 	Reader   io.Reader
-	Size     int64 // File size for streaming files
+	Size     int64             // File size for streaming files
+	Metadata map[string]string // Per-file annotations rendered by the writers
+
+	rendered   *[]byte       // Entry pre-rendered by the format worker pool, in a shared.ScratchBuffers buffer
+	renderTime time.Duration // Time the format worker pool spent rendering the entry
 }
 
 // FileProcessor handles file processing operations.
@@ -30,23 +36,162 @@ type FileProcessor struct {
 	rootPath        string
 	sizeLimit       int64
 	resourceMonitor *ResourceMonitor
+	annotators      []Annotator
+	lfs             *LFSFilter
+	sniffer         *ContentSniffer
+	generated       *GeneratedTextFilter
+	docLanguage     *DocLanguageFilter
+	transform       *textTransform
+	budgets         *Budgets
+	wasm            *WasmTransforms
+	cache           *ContentCache
+	prefetch        *Prefetcher
+	timing          TimingHook
 }
 
 // NewFileProcessor creates a new file processor.
 func NewFileProcessor(rootPath string) *FileProcessor {
-	return &FileProcessor{
-		rootPath:        rootPath,
-		sizeLimit:       config.FileSizeLimit(),
-		resourceMonitor: NewResourceMonitor(),
-	}
+	return NewFileProcessorWithMonitor(rootPath, NewResourceMonitor())
 }
 
 // NewFileProcessorWithMonitor creates a new file processor with a shared resource monitor.
 func NewFileProcessorWithMonitor(rootPath string, monitor *ResourceMonitor) *FileProcessor {
+	return NewFileProcessorWithSettings(rootPath, monitor, config.Global())
+}
+
+// NewFileProcessorWithSettings creates a new file processor with a shared resource monitor
+// that reads the configuration in settings rather than the global configuration.
+func NewFileProcessorWithSettings(
+	rootPath string,
+	monitor *ResourceMonitor,
+	settings *config.Settings,
+) *FileProcessor {
 	return &FileProcessor{
 		rootPath:        rootPath,
-		sizeLimit:       config.FileSizeLimit(),
+		sizeLimit:       settings.FileSizeLimit(),
 		resourceMonitor: monitor,
+		lfs:             newLFSFilter(settings),
+		sniffer:         newContentSniffer(settings),
+		generated:       newGeneratedTextFilter(settings),
+		docLanguage:     newDocLanguageFilter(settings),
+		transform:       newTextTransform(settings),
+	}
+}
+
+// SetAnnotators sets the annotators used to attach metadata to each processed file.
+func (p *FileProcessor) SetAnnotators(annotators ...Annotator) {
+	p.annotators = annotators
+}
+
+// SetSanitizeHook sets a function called for every file whose content is changed by
+// BOM stripping or invisible character removal.
+func (p *FileProcessor) SetSanitizeHook(hook func()) {
+	p.transform.onSanitized = hook
+}
+
+// SetSecurityHook sets a function called with the security scan findings of every file
+// that has any. It is only called when securityScan.enabled is set.
+func (p *FileProcessor) SetSecurityHook(hook func(findings map[string]int)) {
+	p.transform.onFindings = hook
+}
+
+// SetStripComments strips the comments of the stripComments.languages when enabled is set,
+// even with stripComments.enabled off; false keeps the configuration.
+func (p *FileProcessor) SetStripComments(enabled bool) {
+	if enabled {
+		p.transform.stripLanguages = stripLanguageSet(p.transform.settings)
+	}
+}
+
+// SetCompact compacts file content by the output.compact rules when enabled is set, even with
+// output.compact.enabled off; false keeps the configuration.
+func (p *FileProcessor) SetCompact(enabled bool) {
+	if enabled {
+		p.transform.compact = compactRuleFor(p.transform.settings, true)
+	}
+}
+
+// SetRedactionHook sets a function called with the number of replacements by redaction name
+// of every file that had any.
+func (p *FileProcessor) SetRedactionHook(hook func(counts map[string]int)) {
+	p.transform.onRedacted = hook
+}
+
+// SetWarningHook sets a function called with the non-fatal issues found in the content of
+// every file, such as content that is not valid UTF-8.
+func (p *FileProcessor) SetWarningHook(hook WarningHook) {
+	p.transform.onWarning = hook
+}
+
+// SetSecretRedactions sets the redactions of redaction.enabled, as SecretRedactions returns
+// them, so they are not compiled again for every file.
+func (p *FileProcessor) SetSecretRedactions(redactions []Redaction) {
+	p.transform.secrets = redactions
+}
+
+// SetRedactions sets the mandatory redactions applied to the content of every file, after
+// the secret redactions of redaction.enabled.
+func (p *FileProcessor) SetRedactions(redactions ...Redaction) {
+	p.transform.redactions = redactions
+}
+
+// SetDocLanguages sets the natural languages prose files must be written in, overriding
+// docLanguage.include and enabling detection. An empty list keeps the configuration.
+func (p *FileProcessor) SetDocLanguages(languages ...string) {
+	if len(languages) > 0 {
+		p.docLanguage.enabled = true
+		p.docLanguage.include = languages
+	}
+}
+
+// SetRegistry sets the file type registry used to detect the language of every file.
+// The default registry is used otherwise.
+func (p *FileProcessor) SetRegistry(registry *FileTypeRegistry) {
+	p.transform.registry = registry
+}
+
+// SetOffline keeps the processor off the network when offline is set: Git LFS objects are not
+// fetched, so pointers are skipped as with git.lfs.action skip.
+func (p *FileProcessor) SetOffline(offline bool) {
+	if offline && p.lfs.action == shared.LFSActionFetch {
+		p.lfs.action = shared.LFSActionSkip
+	}
+}
+
+// SetBudgets sets the directory budgets whose truncate and outline fallbacks reduce the
+// content of the files over them.
+func (p *FileProcessor) SetBudgets(budgets *Budgets) {
+	p.budgets = budgets
+}
+
+// SetWasmTransforms sets the WASM transforms run on the content of the files matching them,
+// before the built-in transforms. Files they match are read in full.
+func (p *FileProcessor) SetWasmTransforms(transforms *WasmTransforms) {
+	p.wasm = transforms
+}
+
+// SetContentCache sets the cache of transformed content that in-memory files are bundled from
+// while they are unchanged. Files reduced to fit a budget or rewritten by a WASM transform are
+// never cached.
+func (p *FileProcessor) SetContentCache(cache *ContentCache) {
+	p.cache = cache
+}
+
+// SetPrefetcher sets the prefetcher files read in full are taken from when it read them ahead.
+func (p *FileProcessor) SetPrefetcher(prefetch *Prefetcher) {
+	p.prefetch = prefetch
+}
+
+// SetTimingHook sets a function called with the time every file spends being read and transformed.
+// Streamed files are read and transformed while they are written, so the writer reports their read time.
+func (p *FileProcessor) SetTimingHook(hook TimingHook) {
+	p.timing = hook
+}
+
+// timed reports the time since start that relPath spent in phase to the timing hook, if one is set.
+func (p *FileProcessor) timed(relPath, phase string, size int64, start time.Time) {
+	if p.timing != nil {
+		p.timing(relPath, phase, size, time.Since(start))
 	}
 }
 
@@ -86,6 +231,9 @@ func (p *FileProcessor) Process(filePath string, outCh chan<- WriteRequest) {
 
 // ProcessWithContext handles file processing with context and resource monitoring.
 func (p *FileProcessor) ProcessWithContext(ctx context.Context, filePath string, outCh chan<- WriteRequest) error {
+	// Content read ahead for a file skipped or streamed goes back to the prefetch budget
+	defer p.prefetch.discard(filePath)
+
 	// Create file processing context with timeout
 	fileCtx, fileCancel := p.resourceMonitor.CreateFileProcessingContext(ctx)
 	defer fileCancel()
@@ -140,17 +288,37 @@ func (p *FileProcessor) ProcessWithContext(ctx context.Context, filePath string,
 		return err
 	}
 
-	// Get relative path
+	// Get relative path and per-file metadata
 	relPath := p.getRelativePath(filePath)
+	meta := annotate(p.annotators, filePath, relPath)
+
+	// Bundle unchanged files from the content cache without reading them again
+	entry, handled, err := p.handleCached(fileCtx, filePath, relPath, fileInfo, meta, outCh)
+	if handled {
+		return err
+	}
+
+	// Handle Git LFS pointers, binary content behind a text extension, and minified and encoded
+	// text before reading the file in full
+	if handled, err := p.handleSpecialContent(fileCtx, filePath, relPath, fileInfo.Size(), meta, outCh); handled {
+		return err
+	}
+
+	// Record the natural language of prose, leaving out prose in languages not included
+	if meta, err = p.handleDocLanguage(filePath, relPath, meta); err != nil {
+		return err
+	}
 
 	// Process file with timeout
 	processStart := time.Now()
 
-	// Choose processing strategy based on file size
-	if fileInfo.Size() <= shared.FileProcessingStreamThreshold {
-		err = p.processInMemoryWithContext(fileCtx, filePath, relPath, outCh)
+	// Choose processing strategy based on file size; files reduced to fit a budget or rewritten
+	// by a WASM transform are read in full
+	inMemory := p.budgets.Applies(filePath) || p.wasm.Applies(relPath)
+	if fileInfo.Size() <= shared.FileProcessingStreamThreshold || inMemory {
+		err = p.processInMemoryWithContext(fileCtx, filePath, relPath, meta, entry, outCh)
 	} else {
-		err = p.processStreamingWithContext(fileCtx, filePath, relPath, outCh, fileInfo.Size())
+		err = p.processStreamingWithContext(fileCtx, filePath, relPath, meta, outCh, fileInfo.Size())
 	}
 
 	// Only record success if processing completed without error
@@ -159,6 +327,7 @@ func (p *FileProcessor) ProcessWithContext(ctx context.Context, filePath string,
 	}
 
 	// Record successful processing only on success path
+	p.cache.put(relPath, fileInfo, entry)
 	p.resourceMonitor.RecordFileProcessed(fileInfo.Size())
 	logger := shared.GetLogger()
 	logger.Debugf("File processed in %v: %s", time.Since(processStart), filePath)
@@ -224,10 +393,209 @@ func (p *FileProcessor) getRelativePath(filePath string) string {
 	return relPath
 }
 
-// processInMemoryWithContext loads the entire file into memory with context awareness.
+// handleSpecialContent skips or replaces the content of Git LFS pointers, binary content and
+// generated-looking text. handled is false when the file should be processed normally.
+func (p *FileProcessor) handleSpecialContent(
+	ctx context.Context,
+	filePath, relPath string,
+	size int64,
+	meta map[string]string,
+	outCh chan<- WriteRequest,
+) (handled bool, err error) {
+	if handled, err = p.handleLFSPointer(ctx, filePath, relPath, size, meta, outCh); handled {
+		return true, err
+	}
+	if err = p.checkBinaryContent(filePath); err != nil {
+		return true, err
+	}
+
+	return p.handleGeneratedText(ctx, filePath, relPath, size, meta, outCh)
+}
+
+// handleLFSPointer skips Git LFS pointer files, or bundles the content of the object they name
+// with git.lfs.action fetch. handled is false for ordinary files and when pointers are bundled
+// as text.
+func (p *FileProcessor) handleLFSPointer(
+	ctx context.Context,
+	filePath, relPath string,
+	size int64,
+	meta map[string]string,
+	outCh chan<- WriteRequest,
+) (handled bool, err error) {
+	pointer, pointerFile := p.lfs.Inspect(filePath, size)
+	if pointer == nil {
+		return false, nil
+	}
+
+	content, detail := p.lfs.Fetch(filePath, pointerFile, *pointer)
+	if detail != "" {
+		shared.GetLogger().Infof("Skipping Git LFS pointer %s (%s)", filePath, detail)
+
+		return true, shared.NewStructuredError(
+			shared.ErrorTypeValidation,
+			shared.CodeValidationLFS,
+			"Git LFS pointer ("+detail+")",
+			filePath,
+			map[string]any{"reason": LFSPointerReason, "oid": pointer.OID},
+		)
+	}
+
+	fetched := make(map[string]string, len(meta)+1)
+	maps.Copy(fetched, meta)
+	fetched[shared.MetadataKeyLFS] = pointer.OID
+
+	return true, p.emitContent(ctx, filePath, relPath, fetched, nil, content, outCh)
+}
+
+// checkBinaryContent returns an error skipping the file when its content looks binary.
+func (p *FileProcessor) checkBinaryContent(filePath string) error {
+	reason, err := p.sniffer.Inspect(filePath)
+	if err != nil || reason == "" {
+		// Read errors surface again, with full context, when the file is processed
+		return nil
+	}
+	shared.GetLogger().Infof("Skipping binary-looking file %s (%s)", filePath, reason)
+
+	return shared.NewStructuredError(
+		shared.ErrorTypeValidation,
+		shared.CodeValidationBinary,
+		"binary content ("+reason+")",
+		filePath,
+		map[string]any{"reason": BinaryContentReason},
+	)
+}
+
+// handleGeneratedText skips or summarizes files that look minified or encoded.
+// handled is false when the file should be processed normally.
+func (p *FileProcessor) handleGeneratedText(
+	ctx context.Context,
+	filePath, relPath string,
+	size int64,
+	meta map[string]string,
+	outCh chan<- WriteRequest,
+) (handled bool, err error) {
+	verdict, err := p.generated.Inspect(filePath, size)
+	if err != nil || verdict == nil {
+		// Read errors surface again, with full context, when the file is processed
+		return false, nil
+	}
+
+	if !p.generated.Summarize() {
+		shared.GetLogger().Infof("Skipping generated-looking file %s (%s)", filePath, verdict.Detail)
+
+		return true, shared.NewStructuredError(
+			shared.ErrorTypeValidation,
+			shared.CodeValidationGenerated,
+			"generated-looking text ("+verdict.Detail+")",
+			filePath,
+			map[string]any{"reason": verdict.Reason},
+		)
+	}
+
+	summarized := make(map[string]string, len(meta)+1)
+	maps.Copy(summarized, meta)
+	summarized[shared.MetadataKeyOmitted] = verdict.Detail
+
+	select {
+	case <-ctx.Done():
+		return true, shared.NewStructuredError(
+			shared.ErrorTypeValidation,
+			shared.CodeResourceLimitTimeout,
+			"file processing canceled before output",
+			filePath,
+			nil,
+		)
+	case outCh <- WriteRequest{
+		Path:     relPath,
+		Content:  p.formatContent(relPath, fmt.Sprintf("[%d bytes omitted: %s]", size, verdict.Detail)),
+		Size:     size,
+		Metadata: summarized,
+	}:
+	}
+
+	return true, nil
+}
+
+// handleDocLanguage adds the detected natural language of prose files to meta, or returns an
+// error skipping files in a language that is not included.
+func (p *FileProcessor) handleDocLanguage(filePath, relPath string, meta map[string]string) (map[string]string, error) {
+	language, err := p.docLanguage.Inspect(filePath, p.transform.registry.Language(relPath))
+	if err != nil || language == "" {
+		// Read errors surface again, with full context, when the file is processed
+		return meta, nil
+	}
+
+	if p.docLanguage.Excludes(language) {
+		shared.GetLogger().Infof("Skipping documentation in language %q: %s", language, filePath)
+
+		return nil, shared.NewStructuredError(
+			shared.ErrorTypeValidation,
+			shared.CodeValidationDocLanguage,
+			"documentation in excluded language "+language,
+			filePath,
+			map[string]any{"reason": "language " + language},
+		)
+	}
+
+	annotated := make(map[string]string, len(meta)+1)
+	maps.Copy(annotated, meta)
+	annotated[shared.MetadataKeyDocLanguage] = language
+
+	return annotated, nil
+}
+
+// handleCached sends the cached content of an unchanged file. handled is false when the file
+// must be processed, in which case entry is the cache entry to fill in, or nil when the file is
+// not cached.
+func (p *FileProcessor) handleCached(
+	ctx context.Context,
+	filePath, relPath string,
+	info os.FileInfo,
+	meta map[string]string,
+	outCh chan<- WriteRequest,
+) (entry *contentCacheEntry, handled bool, err error) {
+	if p.cache == nil || info.Size() > shared.FileProcessingStreamThreshold ||
+		p.budgets.Applies(filePath) || p.wasm.Applies(relPath) {
+		return nil, false, nil
+	}
+	cached, ok := p.cache.get(relPath, info)
+	if !ok {
+		return &contentCacheEntry{}, false, nil
+	}
+
+	p.transform.replay(relPath, cached)
+	if cached.DocLanguage != "" {
+		meta = mergeMetadata(meta, map[string]string{shared.MetadataKeyDocLanguage: cached.DocLanguage})
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, true, shared.NewStructuredError(
+			shared.ErrorTypeValidation,
+			shared.CodeResourceLimitTimeout,
+			"file processing canceled before output",
+			filePath,
+			nil,
+		)
+	case outCh <- WriteRequest{
+		Path:     relPath,
+		Content:  p.formatContent(relPath, cached.Content),
+		Size:     info.Size(),
+		Metadata: mergeMetadata(meta, cached.Notes),
+	}:
+	}
+	p.resourceMonitor.RecordFileProcessed(info.Size())
+
+	return nil, true, nil
+}
+
+// processInMemoryWithContext loads the entire file into memory with context awareness. The
+// transformed content is recorded in entry unless it is nil.
 func (p *FileProcessor) processInMemoryWithContext(
 	ctx context.Context,
 	filePath, relPath string,
+	meta map[string]string,
+	entry *contentCacheEntry,
 	outCh chan<- WriteRequest,
 ) error {
 	// Check context before reading
@@ -246,7 +614,8 @@ func (p *FileProcessor) processInMemoryWithContext(
 	default:
 	}
 
-	content, err := os.ReadFile(filePath) // #nosec G304 - filePath is validated by walker
+	start := time.Now()
+	content, release, err := p.readContent(filePath)
 	if err != nil {
 		structErr := shared.WrapError(
 			err,
@@ -258,6 +627,9 @@ func (p *FileProcessor) processInMemoryWithContext(
 
 		return structErr
 	}
+	defer release()
+
+	p.timed(relPath, shared.MetricsPhaseRead, int64(len(content)), start)
 
 	// Check context again after reading
 	select {
@@ -275,6 +647,41 @@ func (p *FileProcessor) processInMemoryWithContext(
 	default:
 	}
 
+	return p.emitContent(ctx, filePath, relPath, meta, entry, content, outCh)
+}
+
+// emitContent transforms the content read from filePath and sends it to outCh, recording the
+// result in entry when it is not nil.
+func (p *FileProcessor) emitContent(
+	ctx context.Context,
+	filePath, relPath string,
+	meta map[string]string,
+	entry *contentCacheEntry,
+	content []byte,
+	outCh chan<- WriteRequest,
+) error {
+	start := time.Now()
+	transformed, applied, err := p.wasm.Apply(ctx, relPath, content)
+	if err != nil {
+		return err
+	}
+	restore := p.transform.capture(entry)
+	text, notes := p.transform.apply(relPath, string(transformed))
+	restore()
+	if len(applied) > 0 {
+		notes[shared.MetadataKeyTransforms] = strings.Join(applied, " ")
+	}
+	if limited, note := p.budgets.Limit(filePath, relPath, text, p.transform.registry); note != "" {
+		text = limited
+		notes[shared.MetadataKeyOmitted] = note
+		p.transform.tokenNotes(notes, relPath, int64(len(text)))
+	}
+	p.timed(relPath, shared.MetricsPhaseTransform, int64(len(content)), start)
+	if entry != nil {
+		entry.Content, entry.Notes = text, maps.Clone(notes)
+		entry.DocLanguage = meta[shared.MetadataKeyDocLanguage]
+	}
+
 	// Try to send the result, but respect context cancellation
 	select {
 	case <-ctx.Done():
@@ -290,9 +697,10 @@ func (p *FileProcessor) processInMemoryWithContext(
 		return structErr
 	case outCh <- WriteRequest{
 		Path:     relPath,
-		Content:  p.formatContent(relPath, string(content)),
+		Content:  p.formatContent(relPath, text),
 		IsStream: false,
 		Size:     int64(len(content)),
+		Metadata: mergeMetadata(meta, notes),
 	}:
 	}
 
@@ -303,6 +711,7 @@ func (p *FileProcessor) processInMemoryWithContext(
 func (p *FileProcessor) processStreamingWithContext(
 	ctx context.Context,
 	filePath, relPath string,
+	meta map[string]string,
 	outCh chan<- WriteRequest,
 	size int64,
 ) error {
@@ -322,7 +731,7 @@ func (p *FileProcessor) processStreamingWithContext(
 	default:
 	}
 
-	reader := p.createStreamReaderWithContext(ctx, filePath, relPath)
+	reader, notes := p.createStreamReaderWithContext(ctx, filePath, relPath)
 	if reader == nil {
 		// Error already logged, create and return error
 		return shared.NewStructuredError(
@@ -333,6 +742,8 @@ func (p *FileProcessor) processStreamingWithContext(
 			nil,
 		)
 	}
+	// Streamed content is transformed as it is written, so its tokens are estimated from the file size
+	p.transform.tokenNotes(notes, relPath, size)
 
 	// Try to send the result, but respect context cancellation
 	select {
@@ -353,6 +764,7 @@ func (p *FileProcessor) processStreamingWithContext(
 		IsStream: true,
 		Reader:   reader,
 		Size:     size,
+		Metadata: mergeMetadata(meta, notes),
 	}:
 	}
 
@@ -360,17 +772,18 @@ func (p *FileProcessor) processStreamingWithContext(
 }
 
 // createStreamReaderWithContext creates a reader that combines header and file content with context awareness.
+// It also returns the metadata notes of the content transform.
 func (p *FileProcessor) createStreamReaderWithContext(
 	ctx context.Context, filePath, relPath string,
-) io.Reader {
+) (io.Reader, map[string]string) {
 	// Check context before opening file
 	select {
 	case <-ctx.Done():
-		return nil
+		return nil, nil
 	default:
 	}
 
-	file, err := os.Open(filePath) // #nosec G304 - filePath is validated by walker
+	file, err := openSourceFile(filePath)
 	if err != nil {
 		structErr := shared.WrapError(
 			err,
@@ -380,35 +793,75 @@ func (p *FileProcessor) createStreamReaderWithContext(
 		).WithFilePath(filePath)
 		shared.LogErrorf(structErr, "Failed to open file for streaming %s", filePath)
 
-		return nil
+		return nil, nil
 	}
 	header := p.formatHeader(relPath)
+	start := time.Now()
+	content, notes := p.transform.wrap(relPath, chaos.Reader(file))
+	p.timed(relPath, shared.MetricsPhaseTransform, 0, start)
+
+	return newHeaderFileReader(header, content, file), notes
+}
+
+// readSourceFile reads the source file at filePath within the open file bound, failing or
+// slowed down when chaos injects a failure.
+func readSourceFile(filePath string) ([]byte, error) {
+	if err := chaos.BeforeRead(); err != nil {
+		return nil, err //nolint:wrapcheck // wrapped like read errors by the caller
+	}
+
+	return readLimited(filePath)
+}
 
-	return newHeaderFileReader(header, file)
+// readContent reads the source file at filePath, taking its content from the prefetcher when it
+// was read ahead, and returns a function freeing the content once it is no longer used.
+func (p *FileProcessor) readContent(filePath string) ([]byte, func(), error) {
+	if entry := p.prefetch.take(filePath); entry != nil {
+		return entry.content(), func() { p.prefetch.release(entry) }, entry.err
+	}
+	content, err := readSourceFile(filePath)
+
+	return content, func() {}, err
+}
+
+// openSourceFile opens the source file at filePath for streaming within the open file bound,
+// failing or slowed down when chaos injects a failure.
+func openSourceFile(filePath string) (*limitedFile, error) {
+	if err := chaos.BeforeRead(); err != nil {
+		return nil, err //nolint:wrapcheck // wrapped like open errors by the caller
+	}
+
+	return openLimited(filePath)
 }
 
 // formatContent formats the file content with header.
 func (p *FileProcessor) formatContent(relPath, content string) string {
-	return fmt.Sprintf("\n---\n%s\n%s\n", relPath, content)
+	return fileHeader(relPath) + content + "\n"
 }
 
 // formatHeader creates a reader for the file header.
 func (p *FileProcessor) formatHeader(relPath string) io.Reader {
-	return strings.NewReader(fmt.Sprintf("\n---\n%s\n", relPath))
+	return strings.NewReader(fileHeader(relPath))
+}
+
+// fileHeader returns the header naming the file at relPath that precedes its content.
+func fileHeader(relPath string) string {
+	return "\n---\n" + relPath + "\n"
 }
 
 // headerFileReader wraps a MultiReader and closes the file when EOF is reached.
 type headerFileReader struct {
 	reader io.Reader
-	file   *os.File
+	file   io.Closer
 	mu     sync.Mutex
 	closed bool
 }
 
-// newHeaderFileReader creates a new headerFileReader.
-func newHeaderFileReader(header io.Reader, file *os.File) *headerFileReader {
+// newHeaderFileReader creates a new headerFileReader reading the header followed by content,
+// which reads from file, possibly through a transform.
+func newHeaderFileReader(header, content io.Reader, file io.Closer) *headerFileReader {
 	return &headerFileReader{
-		reader: io.MultiReader(header, file),
+		reader: io.MultiReader(header, content),
 		file:   file,
 	}
 }
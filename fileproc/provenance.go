@@ -0,0 +1,39 @@
+// Package fileproc handles file processing, collection, and output formatting.
+package fileproc
+
+import (
+	"encoding/json"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// configProvenancePrefix introduces the settings recorded in Markdown and YAML comments.
+const configProvenancePrefix = "gibidify config: "
+
+// configStarter is a FormatWriter that can start the bundle with the settings that shaped it.
+type configStarter interface {
+	StartWithConfig(prefix, suffix string, settings map[string]any) error
+}
+
+// startFormatWriterOutput starts writer, recording settings first when there are any.
+func startFormatWriterOutput(writer FormatWriter, prefix, suffix string, settings map[string]any) error {
+	starter, ok := writer.(configStarter)
+	if len(settings) == 0 || !ok {
+		return writer.Start(prefix, suffix)
+	}
+
+	return starter.StartWithConfig(prefix, suffix, settings)
+}
+
+// encodeConfigProvenance encodes settings as one line of JSON with sorted keys. HTML escaping
+// keeps "-->" out of it, so it can sit in a Markdown comment.
+func encodeConfigProvenance(settings map[string]any) (string, error) {
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return "", shared.WrapError(
+			err, shared.ErrorTypeProcessing, shared.CodeProcessingEncode, "failed to encode config provenance",
+		)
+	}
+
+	return string(data), nil
+}
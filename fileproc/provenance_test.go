@@ -0,0 +1,103 @@
+package fileproc_test
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/ivuorinen/gibidify/fileproc"
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// provenanceSettings are the settings the provenance tests record, including a value that
+// would end a Markdown comment.
+var provenanceSettings = map[string]any{"maxconcurrency": 3, "output.custom.header": "a --> b"}
+
+// writeProvenanceBundle writes one file in format with provenanceSettings recorded and returns the output.
+func writeProvenanceBundle(t *testing.T, format string) string {
+	t.Helper()
+
+	outFile, path := testutil.CreateTempOutputFile(t, "provenance_*."+format)
+	writeCh := make(chan fileproc.WriteRequest, 1)
+	writeCh <- fileproc.WriteRequest{Path: "main.go", Content: "package main"}
+	close(writeCh)
+
+	done := make(chan struct{})
+	opts := fileproc.WriterOptions{Config: provenanceSettings}
+	fileproc.StartWriterWithOptions(outFile, writeCh, done, format, "Start", "End", opts)
+	<-done
+	testutil.CloseFile(t, outFile)
+
+	data, err := os.ReadFile(path) // #nosec G304 -- path is in t.TempDir()
+	testutil.MustSucceed(t, err, "reading output")
+
+	return string(data)
+}
+
+func TestConfigProvenanceJSON(t *testing.T) {
+	output := writeProvenanceBundle(t, shared.FormatJSON)
+
+	var bundle fileproc.OutputData
+	testutil.MustSucceed(t, json.Unmarshal([]byte(output), &bundle), "decoding bundle")
+	if bundle.Config["maxconcurrency"] != float64(3) || bundle.Config["output.custom.header"] != "a --> b" {
+		t.Errorf("config = %v, want %v", bundle.Config, provenanceSettings)
+	}
+	if bundle.Prefix != "Start" || len(bundle.Files) != 1 {
+		t.Errorf("bundle = %+v, want prefix Start and one file", bundle)
+	}
+}
+
+func TestConfigProvenanceComments(t *testing.T) {
+	// JSON escapes ">", so the value cannot end the Markdown comment early.
+	const want = `gibidify config: {"maxconcurrency":3,"output.custom.header":"a --\u003e b"}`
+	tests := []struct {
+		format    string
+		firstLine string
+	}{
+		{format: shared.FormatMarkdown, firstLine: "<!-- " + want + " -->"},
+		{format: shared.FormatYAML, firstLine: "# " + want},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			output := writeProvenanceBundle(t, tt.format)
+
+			firstLine, rest, _ := strings.Cut(output, "\n")
+			if firstLine != tt.firstLine {
+				t.Errorf("first line = %q, want %q", firstLine, tt.firstLine)
+			}
+			if strings.Contains(rest, "gibidify config") {
+				t.Errorf("config recorded more than once:\n%s", output)
+			}
+		})
+	}
+
+	var bundle fileproc.OutputData
+	testutil.MustSucceed(t, yaml.Unmarshal([]byte(writeProvenanceBundle(t, shared.FormatYAML)), &bundle), "decoding YAML")
+	if bundle.Prefix != "Start" || len(bundle.Files) != 1 {
+		t.Errorf("YAML bundle = %+v, want prefix Start and one file", bundle)
+	}
+}
+
+func TestConfigProvenanceDisabled(t *testing.T) {
+	for _, format := range []string{shared.FormatJSON, shared.FormatYAML, shared.FormatMarkdown} {
+		outFile, path := testutil.CreateTempOutputFile(t, "provenance_*."+format)
+		writeCh := make(chan fileproc.WriteRequest)
+		close(writeCh)
+
+		done := make(chan struct{})
+		fileproc.StartWriterWithOptions(outFile, writeCh, done, format, "", "", fileproc.WriterOptions{})
+		<-done
+		testutil.CloseFile(t, outFile)
+
+		data, err := os.ReadFile(path) // #nosec G304 -- path is in t.TempDir()
+		testutil.MustSucceed(t, err, "reading output")
+		if strings.Contains(string(data), "config") {
+			t.Errorf("%s output without settings records a config: %s", format, data)
+		}
+	}
+}
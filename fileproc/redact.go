@@ -0,0 +1,138 @@
+// Package fileproc handles file processing, collection, and output formatting.
+package fileproc
+
+import (
+	"cmp"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Redaction replaces every match of Pattern in file content with Replacement.
+// Patterns are applied line by line, so a match never spans a line break.
+type Redaction struct {
+	Name        string
+	Pattern     *regexp.Regexp
+	Replacement string
+}
+
+// redactor applies redactions to the lines of one file and counts the replacements by
+// redaction name. With privateKeys set it also redacts the key material of PEM private key
+// blocks, which spans lines.
+type redactor struct {
+	redactions  []Redaction
+	privateKeys bool
+	counts      map[string]int
+	inKey       bool
+	keyLines    int
+}
+
+// newRedactor creates a redactor for one file, or returns nil when there is nothing to redact.
+func newRedactor(redactions []Redaction, privateKeys bool) *redactor {
+	if len(redactions) == 0 && !privateKeys {
+		return nil
+	}
+
+	return &redactor{redactions: redactions, privateKeys: privateKeys, counts: map[string]int{}}
+}
+
+// line applies the redactions to one line.
+func (r *redactor) line(line []byte) []byte {
+	if r.inKey {
+		return r.keyLine(line)
+	}
+
+	for _, redaction := range r.redactions {
+		if n := len(redaction.Pattern.FindAllIndex(line, -1)); n > 0 {
+			r.counts[cmp.Or(redaction.Name, "unnamed")] += n
+			line = redaction.Pattern.ReplaceAll(line, []byte(redaction.Replacement))
+		}
+	}
+	if r.privateKeys && privateKeyBegin.Match(line) {
+		r.inKey, r.keyLines = true, 0
+		r.counts[RedactionPrivateKey]++
+	}
+
+	return line
+}
+
+// keyLine redacts a line of a private key block. The BEGIN and END lines are kept, the first
+// line of key material becomes the marker and the others are emptied, so the lines after the
+// block keep their numbers.
+func (r *redactor) keyLine(line []byte) []byte {
+	if privateKeyEnd.Match(line) {
+		r.inKey = false
+
+		return line
+	}
+
+	r.keyLines++
+	if r.keyLines == 1 {
+		return []byte(redactionMarker(RedactionPrivateKey))
+	}
+
+	return nil
+}
+
+// redactContent applies the redactions of r to every line of content.
+func redactContent(content string, r *redactor) string {
+	if r == nil {
+		return content
+	}
+
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		lines[i] = string(r.line([]byte(line)))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// RedactEntry applies the secret redactions, as SecretRedactions returns them, followed by the
+// mandatory redactions to the content of an entry prepared outside a FileProcessor, such as a
+// prelude document or the --from-patch diff. It returns the entry with the number of
+// replacements by redaction name. Streamed entries are returned unchanged.
+func RedactEntry(req WriteRequest, secrets, redactions []Redaction) (WriteRequest, map[string]int) {
+	r := newRedactor(slices.Concat(secrets, redactions), len(secrets) > 0)
+	if r == nil || req.IsStream {
+		return req, nil
+	}
+
+	req.Content = redactContent(req.Content, r)
+	req.Size = int64(len(req.Content))
+
+	return req, r.counts
+}
+
+// redactReader applies the redactions of a redactor to streamed content and reports the
+// counts once the stream ends.
+type redactReader struct {
+	io.Reader
+	redactor *redactor
+	report   func(map[string]int)
+	done     bool
+}
+
+// newRedactReader wraps src so the redactions of r are applied to every streamed line and
+// report, when set, is called at EOF when anything was redacted.
+func newRedactReader(src io.Reader, r *redactor, report func(map[string]int)) io.Reader {
+	if r == nil {
+		return src
+	}
+
+	return &redactReader{Reader: newLineReader(src, r.line), redactor: r, report: report}
+}
+
+// Read implements io.Reader.
+func (r *redactReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	if err == io.EOF && !r.done {
+		r.done = true
+		if r.report != nil && len(r.redactor.counts) > 0 {
+			r.report(r.redactor.counts)
+		}
+	}
+
+	return n, err //nolint:wrapcheck // EOF must not be wrapped; the header reader wraps other errors
+}
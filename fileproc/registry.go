@@ -5,18 +5,21 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/ivuorinen/gibidify/shared"
 )
 
 const minExtensionLength = 2
 
-var (
-	registry     *FileTypeRegistry
-	registryOnce sync.Once
-)
+// registry holds the default file type registry behind the deprecated package-level
+// functions, and used by components not given a registry of their own. It is an atomic
+// pointer rather than a sync.Once so ResetRegistryForTesting cannot race with lookups.
+var registry atomic.Pointer[FileTypeRegistry]
 
-// FileTypeRegistry manages file type detection and classification.
+// FileTypeRegistry manages file type detection and classification. A registry is safe for
+// concurrent use; the CLI creates one per processor from the fileTypes configuration and
+// passes it to the walker, the file processor and the writers.
 type FileTypeRegistry struct {
 	imageExts   map[string]bool
 	binaryExts  map[string]bool
@@ -48,8 +51,8 @@ type FileTypeResult struct {
 	Extension string
 }
 
-// initRegistry initializes the default file type registry with common extensions.
-func initRegistry() *FileTypeRegistry {
+// NewFileTypeRegistry creates a file type registry with the built-in extensions.
+func NewFileTypeRegistry() *FileTypeRegistry {
 	return &FileTypeRegistry{
 		imageExts:    getImageExtensions(),
 		binaryExts:   getBinaryExtensions(),
@@ -62,14 +65,20 @@ func initRegistry() *FileTypeRegistry {
 
 // getRegistry returns the singleton file type registry, creating it if necessary.
 func getRegistry() *FileTypeRegistry {
-	registryOnce.Do(func() {
-		registry = initRegistry()
-	})
+	if r := registry.Load(); r != nil {
+		return r
+	}
+	if r := NewFileTypeRegistry(); registry.CompareAndSwap(nil, r) {
+		return r
+	}
 
-	return registry
+	return getRegistry()
 }
 
-// DefaultRegistry returns the default file type registry.
+// DefaultRegistry returns the process-wide default file type registry.
+//
+// Deprecated: create a registry with NewFileTypeRegistry and pass it to the components that
+// need it; mutating the shared default makes concurrent processors interfere.
 func DefaultRegistry() *FileTypeRegistry {
 	return getRegistry()
 }
@@ -93,8 +102,7 @@ func (r *FileTypeRegistry) CacheInfo() (extCacheSize, resultCacheSize, maxCacheS
 // ResetRegistryForTesting resets the registry to its initial state.
 // This function should only be used in tests.
 func ResetRegistryForTesting() {
-	registryOnce = sync.Once{}
-	registry = nil
+	registry.Store(nil)
 }
 
 // normalizeExtension extracts and normalizes the file extension.
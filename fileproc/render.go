@@ -0,0 +1,112 @@
+// Package fileproc handles file processing, collection, and output formatting.
+package fileproc
+
+import (
+	"time"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// inlineRenderer is implemented by format writers that can render an in-memory file entry
+// without writing it. renderInline appends the entry to dst, must be safe for concurrent use,
+// and returns nil when the entry has to be written another way.
+type inlineRenderer interface {
+	renderInline(dst []byte, req WriteRequest) ([]byte, error)
+}
+
+// renderedEntry returns the entry pre-rendered for req by the format worker pool, rendering
+// it with r when there is none. The entry is held in a buffer from shared.ScratchBuffers,
+// which the caller returns once the entry is written.
+func renderedEntry(req WriteRequest, r inlineRenderer) (*[]byte, error) {
+	if req.rendered != nil {
+		return req.rendered, nil
+	}
+
+	return renderScratch(req, r)
+}
+
+// renderScratch renders req with r into a buffer from shared.ScratchBuffers.
+// It returns nil, returning the buffer to the pool, when the entry is not rendered.
+func renderScratch(req WriteRequest, r inlineRenderer) (*[]byte, error) {
+	buf := shared.ScratchBuffers.Get()
+	entry, err := r.renderInline(*buf, req)
+	if err != nil || entry == nil {
+		shared.ScratchBuffers.Put(buf)
+
+		return nil, err
+	}
+	*buf = entry
+
+	return buf, nil
+}
+
+// renderJob is an entry waiting for a format worker, and where to deliver it.
+type renderJob struct {
+	req    WriteRequest
+	result chan WriteRequest
+}
+
+// renderConcurrently pre-renders the in-memory entries received on in with workers goroutines
+// and delivers them in their original order, so prelude and trailing entries keep their place.
+// At most workers entries are rendered or waiting to be written at a time. Streamed entries and
+// entries that fail to render are passed on as they are, for the writer to handle. Rendered
+// entries get their file ID, hashed with algorithm, first when fileIDs is set.
+func renderConcurrently(
+	in <-chan WriteRequest,
+	r inlineRenderer,
+	workers int,
+	fileIDs bool,
+	algorithm string,
+) <-chan WriteRequest {
+	jobs := make(chan renderJob, workers)
+	order := make(chan chan WriteRequest, workers)
+	out := make(chan WriteRequest)
+
+	go func() {
+		defer close(jobs)
+		defer close(order)
+		for req := range in {
+			result := make(chan WriteRequest, 1)
+			order <- result
+			jobs <- renderJob{req: req, result: result}
+		}
+	}()
+
+	for range workers {
+		go func() {
+			for job := range jobs {
+				job.result <- prerender(job.req, r, fileIDs, algorithm)
+			}
+		}()
+	}
+
+	go func() {
+		defer close(out)
+		for result := range order {
+			out <- <-result
+		}
+	}()
+
+	return out
+}
+
+// prerender renders an in-memory entry with r, recording how long it took.
+func prerender(req WriteRequest, r inlineRenderer, fileIDs bool, algorithm string) WriteRequest {
+	if req.IsStream {
+		return req
+	}
+
+	start := time.Now()
+	prepared := req
+	if fileIDs {
+		prepared = withFileID(req, algorithm)
+	}
+	rendered, err := renderScratch(prepared, r)
+	if err != nil || rendered == nil {
+		return req
+	}
+	prepared.rendered = rendered
+	prepared.renderTime = time.Since(start)
+
+	return prepared
+}
@@ -46,7 +46,7 @@ func (rm *ResourceMonitor) ReleaseReadSlot() {
 
 // CreateFileProcessingContext creates a context with file processing timeout.
 func (rm *ResourceMonitor) CreateFileProcessingContext(parent context.Context) (context.Context, context.CancelFunc) {
-	if !rm.enabled || rm.fileProcessingTimeout <= 0 {
+	if !rm.limited || rm.fileProcessingTimeout <= 0 {
 		// No-op cancel function - monitoring disabled or no timeout configured
 		return parent, func() {}
 	}
@@ -59,7 +59,7 @@ func (rm *ResourceMonitor) CreateOverallProcessingContext(parent context.Context
 	context.Context,
 	context.CancelFunc,
 ) {
-	if !rm.enabled || rm.overallTimeout <= 0 {
+	if !rm.limited || rm.overallTimeout <= 0 {
 		// No-op cancel function - monitoring disabled or no timeout configured
 		return parent, func() {}
 	}
@@ -0,0 +1,96 @@
+// Package fileproc handles file processing, collection, and output formatting.
+package fileproc
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// ResourceLimits are limits of one run that lower the configured resourceLimits, such as the
+// limits a server applies to a single request. A zero field keeps the configured limit.
+type ResourceLimits struct {
+	MaxFiles              int
+	MaxTotalSize          int64
+	FileProcessingTimeout time.Duration
+	OverallTimeout        time.Duration
+}
+
+// Limits returns the limits the monitor enforces. A zero field is not enforced.
+func (rm *ResourceMonitor) Limits() ResourceLimits {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	return rm.limits()
+}
+
+// limits returns the enforced limits; the caller holds rm.mu.
+func (rm *ResourceMonitor) limits() ResourceLimits {
+	if !rm.limited {
+		return ResourceLimits{}
+	}
+
+	return ResourceLimits{
+		MaxFiles:              rm.maxFiles,
+		MaxTotalSize:          rm.maxTotalSize,
+		FileProcessingTimeout: rm.fileProcessingTimeout,
+		OverallTimeout:        rm.overallTimeout,
+	}
+}
+
+// Restrict lowers the limits the monitor enforces to the non-zero fields of limits. The
+// configured limits are the maximums: a negative limit, or one above the configured limit,
+// is rejected and leaves every limit unchanged. While resource limits are disabled there is
+// no maximum and only the fields of limits are enforced. Call Restrict before processing starts.
+func (rm *ResourceMonitor) Restrict(limits ResourceLimits) error {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	current := rm.limits()
+	checks := []struct {
+		name      string
+		code      string
+		requested int64
+		maximum   int64
+	}{
+		{"max_files", shared.CodeResourceLimitFiles, int64(limits.MaxFiles), int64(current.MaxFiles)},
+		{"max_total_size", shared.CodeResourceLimitTotalSize, limits.MaxTotalSize, current.MaxTotalSize},
+		{
+			"file_timeout", shared.CodeResourceLimitTimeout,
+			int64(limits.FileProcessingTimeout), int64(current.FileProcessingTimeout),
+		},
+		{"timeout", shared.CodeResourceLimitTimeout, int64(limits.OverallTimeout), int64(current.OverallTimeout)},
+	}
+	for _, check := range checks {
+		if check.requested < 0 || (check.maximum > 0 && check.requested > check.maximum) {
+			return shared.NewStructuredError(
+				shared.ErrorTypeValidation,
+				check.code,
+				fmt.Sprintf("requested %s must be between 0 and the configured limit", check.name),
+				"",
+				map[string]any{"requested": check.requested, "limit": check.maximum},
+			)
+		}
+	}
+
+	if limits == (ResourceLimits{}) {
+		return nil
+	}
+	rm.maxFiles = lowerLimit(current.MaxFiles, limits.MaxFiles)
+	rm.maxTotalSize = lowerLimit(current.MaxTotalSize, limits.MaxTotalSize)
+	rm.fileProcessingTimeout = lowerLimit(current.FileProcessingTimeout, limits.FileProcessingTimeout)
+	rm.overallTimeout = lowerLimit(current.OverallTimeout, limits.OverallTimeout)
+	rm.limited = true
+
+	return nil
+}
+
+// lowerLimit returns requested when it is set, and limit otherwise.
+func lowerLimit[T int | int64 | time.Duration](limit, requested T) T {
+	if requested == 0 {
+		return limit
+	}
+
+	return requested
+}
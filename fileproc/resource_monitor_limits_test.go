@@ -0,0 +1,112 @@
+package fileproc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// TestResourceMonitorRestrict tests that per-run limits lower the configured limits, which
+// bound them, and that a rejected request changes nothing.
+func TestResourceMonitorRestrict(t *testing.T) {
+	configured := ResourceLimits{
+		MaxFiles:              100,
+		MaxTotalSize:          shared.BytesPerMB,
+		FileProcessingTimeout: 30 * time.Second,
+		OverallTimeout:        time.Minute,
+	}
+
+	tests := []struct {
+		name     string
+		limits   ResourceLimits
+		want     ResourceLimits
+		wantCode string
+	}{
+		{name: "zero keeps the configured limits", want: configured},
+		{
+			name:   "lowered",
+			limits: ResourceLimits{MaxFiles: 10, OverallTimeout: 5 * time.Second},
+			want: ResourceLimits{
+				MaxFiles:              10,
+				MaxTotalSize:          shared.BytesPerMB,
+				FileProcessingTimeout: 30 * time.Second,
+				OverallTimeout:        5 * time.Second,
+			},
+		},
+		{
+			name:     "above the configured size limit",
+			limits:   ResourceLimits{MaxFiles: 10, MaxTotalSize: 2 * shared.BytesPerMB},
+			want:     configured,
+			wantCode: shared.CodeResourceLimitTotalSize,
+		},
+		{
+			name:     "negative timeout",
+			limits:   ResourceLimits{OverallTimeout: -time.Second},
+			want:     configured,
+			wantCode: shared.CodeResourceLimitTimeout,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testutil.ResetViperConfig(t, "")
+			viper.Set(shared.TestCfgResourceLimitsEnabled, true)
+			viper.Set(shared.ConfigKeyResourceLimitsMaxFiles, configured.MaxFiles)
+			viper.Set(shared.ConfigKeyResourceLimitsMaxTotalSize, configured.MaxTotalSize)
+			viper.Set(shared.ConfigKeyResourceLimitsFileProcessingTO, 30)
+			viper.Set(shared.ConfigKeyResourceLimitsOverallTO, 60)
+
+			rm := NewResourceMonitor()
+			defer rm.Close()
+
+			err := rm.Restrict(tt.limits)
+			if tt.wantCode != "" {
+				assertStructuredError(t, err, tt.wantCode)
+			} else {
+				testutil.MustSucceed(t, err, "restricting limits")
+			}
+			if got := rm.Limits(); got != tt.want {
+				t.Errorf("Limits() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestResourceMonitorRestrictDisabled tests that per-run limits are enforced, and not bounded
+// by the configured limits, while resource limits are disabled.
+func TestResourceMonitorRestrictDisabled(t *testing.T) {
+	testutil.ResetViperConfig(t, "")
+	viper.Set(shared.TestCfgResourceLimitsEnabled, false)
+	viper.Set(shared.ConfigKeyResourceLimitsMaxFiles, 10)
+
+	rm := NewResourceMonitor()
+	defer rm.Close()
+
+	if got := rm.Limits(); got != (ResourceLimits{}) {
+		t.Errorf("Limits() = %+v before Restrict, want none", got)
+	}
+	testutil.MustSucceed(t, rm.Restrict(ResourceLimits{}), "restricting to no limits")
+	testutil.MustSucceed(t, rm.ValidateFileProcessing("unlimited.txt", shared.BytesPerMB), "validating unlimited")
+
+	limits := ResourceLimits{MaxFiles: 20, OverallTimeout: time.Minute}
+	testutil.MustSucceed(t, rm.Restrict(limits), "restricting limits")
+	if got := rm.Limits(); got != limits {
+		t.Errorf("Limits() = %+v, want %+v", got, limits)
+	}
+
+	for range limits.MaxFiles {
+		testutil.MustSucceed(t, rm.ValidateFileProcessing("file.txt", 1), "validating within the limit")
+		rm.RecordFileProcessed(1)
+	}
+	assertStructuredError(t, rm.ValidateFileProcessing("file.txt", 1), shared.CodeResourceLimitFiles)
+
+	ctx, cancel := rm.CreateOverallProcessingContext(t.Context())
+	defer cancel()
+	if _, ok := ctx.Deadline(); !ok {
+		t.Error("overall processing context has no deadline")
+	}
+}
@@ -2,6 +2,7 @@
 package fileproc
 
 import (
+	"maps"
 	"runtime"
 	"sync/atomic"
 	"time"
@@ -11,12 +12,28 @@ import (
 
 // RecordFileProcessed records that a file has been successfully processed.
 func (rm *ResourceMonitor) RecordFileProcessed(fileSize int64) {
-	if rm.enabled {
+	if rm.limited {
 		atomic.AddInt64(&rm.filesProcessed, 1)
 		atomic.AddInt64(&rm.totalSizeProcessed, fileSize)
 	}
 }
 
+// RecordFileSkipped records a file of fileSize bytes left out of the bundle for reason,
+// one of the shared.SkipReason* constants.
+func (rm *ResourceMonitor) RecordFileSkipped(reason string, fileSize int64) {
+	if !rm.enabled {
+		return
+	}
+
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	stats := rm.skipped[reason]
+	stats.Files++
+	stats.Bytes += fileSize
+	rm.skipped[reason] = stats
+}
+
 // Metrics returns current resource usage metrics.
 func (rm *ResourceMonitor) Metrics() ResourceMetrics {
 	if !rm.enableResourceMon {
@@ -62,6 +79,7 @@ func (rm *ResourceMonitor) Metrics() ResourceMetrics {
 		ViolationsDetected:  violations,
 		DegradationActive:   rm.degradationActive,
 		EmergencyStopActive: rm.emergencyStopRequested,
+		Skipped:             maps.Clone(rm.skipped),
 		LastUpdated:         time.Now(),
 	}
 }
@@ -1,11 +1,13 @@
 package fileproc
 
 import (
+	"maps"
 	"testing"
 	"time"
 
 	"github.com/spf13/viper"
 
+	"github.com/ivuorinen/gibidify/shared"
 	"github.com/ivuorinen/gibidify/testutil"
 )
 
@@ -47,3 +49,43 @@ func TestResourceMonitorMetrics(t *testing.T) {
 		t.Error("Expected recent LastUpdated timestamp")
 	}
 }
+
+func TestResourceMonitorRecordFileSkipped(t *testing.T) {
+	tests := []struct {
+		name    string
+		enabled bool
+		want    map[string]SkipStats
+	}{
+		{
+			name:    "enabled",
+			enabled: true,
+			want: map[string]SkipStats{
+				shared.SkipReasonBinary: {Files: 2, Bytes: 300},
+				shared.SkipReasonError:  {Files: 1, Bytes: 50},
+			},
+		},
+		{name: "disabled", enabled: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testutil.ResetViperConfig(t, "")
+			viper.Set(shared.ConfigKeyResourceLimitsEnabled, tt.enabled)
+			viper.Set(shared.ConfigKeyResourceLimitsEnableMonitoring, true)
+
+			rm := NewResourceMonitor()
+			defer rm.Close()
+
+			rm.RecordFileSkipped(shared.SkipReasonBinary, 100)
+			rm.RecordFileSkipped(shared.SkipReasonBinary, 200)
+			rm.RecordFileSkipped(shared.SkipReasonError, 50)
+
+			if got := rm.Metrics().Skipped; !maps.Equal(got, tt.want) {
+				t.Errorf("Skipped = %v, want %v", got, tt.want)
+			}
+			if rm.Metrics().FilesProcessed != 0 {
+				t.Error("skipped files were counted as processed")
+			}
+		})
+	}
+}
@@ -11,7 +11,10 @@ import (
 
 // ResourceMonitor monitors resource usage and enforces limits to prevent DoS attacks.
 type ResourceMonitor struct {
-	enabled               bool
+	enabled bool
+	// limited is set when the file count, size and timeout limits are enforced: when resource
+	// limits are enabled, or when Restrict set limits while they are disabled.
+	limited               bool
 	maxFiles              int
 	maxTotalSize          int64
 	fileProcessingTimeout time.Duration
@@ -25,6 +28,7 @@ type ResourceMonitor struct {
 	// Current state tracking
 	filesProcessed       int64
 	totalSizeProcessed   int64
+	skipped              map[string]SkipStats
 	concurrentReads      int64
 	startTime            time.Time
 	lastRateLimitCheck   time.Time
@@ -57,7 +61,15 @@ type ResourceMetrics struct {
 	ViolationsDetected  []string      `json:"violations_detected"`
 	DegradationActive   bool          `json:"degradation_active"`
 	EmergencyStopActive bool          `json:"emergency_stop_active"`
-	LastUpdated         time.Time     `json:"last_updated"`
+	// Skipped holds the files left out of the bundle by skip reason (shared.SkipReason*).
+	Skipped     map[string]SkipStats `json:"skipped,omitempty"`
+	LastUpdated time.Time            `json:"last_updated"`
+}
+
+// SkipStats counts the files left out of the bundle for one reason and their total size.
+type SkipStats struct {
+	Files int64 `json:"files"`
+	Bytes int64 `json:"bytes"`
 }
 
 // ResourceViolation represents a detected resource limit violation.
@@ -72,21 +84,29 @@ type ResourceViolation struct {
 
 // NewResourceMonitor creates a new resource monitor with configuration.
 func NewResourceMonitor() *ResourceMonitor {
+	return NewResourceMonitorWithSettings(config.Global())
+}
+
+// NewResourceMonitorWithSettings creates a new resource monitor with the resourceLimits
+// configuration in settings.
+func NewResourceMonitorWithSettings(settings *config.Settings) *ResourceMonitor {
 	rm := &ResourceMonitor{
-		enabled:               config.ResourceLimitsEnabled(),
-		maxFiles:              config.MaxFiles(),
-		maxTotalSize:          config.MaxTotalSize(),
-		fileProcessingTimeout: time.Duration(config.FileProcessingTimeoutSec()) * time.Second,
-		overallTimeout:        time.Duration(config.OverallTimeoutSec()) * time.Second,
-		maxConcurrentReads:    config.MaxConcurrentReads(),
-		rateLimitFilesPerSec:  config.RateLimitFilesPerSec(),
-		hardMemoryLimitMB:     config.HardMemoryLimitMB(),
-		enableGracefulDegr:    config.EnableGracefulDegradation(),
-		enableResourceMon:     config.EnableResourceMonitoring(),
+		enabled:               settings.ResourceLimitsEnabled(),
+		limited:               settings.ResourceLimitsEnabled(),
+		maxFiles:              settings.MaxFiles(),
+		maxTotalSize:          settings.MaxTotalSize(),
+		fileProcessingTimeout: time.Duration(settings.FileProcessingTimeoutSec()) * time.Second,
+		overallTimeout:        time.Duration(settings.OverallTimeoutSec()) * time.Second,
+		maxConcurrentReads:    settings.MaxConcurrentReads(),
+		rateLimitFilesPerSec:  settings.RateLimitFilesPerSec(),
+		hardMemoryLimitMB:     settings.HardMemoryLimitMB(),
+		enableGracefulDegr:    settings.EnableGracefulDegradation(),
+		enableResourceMon:     settings.EnableResourceMonitoring(),
 		startTime:             time.Now(),
 		lastRateLimitCheck:    time.Now(),
 		violationLogged:       make(map[string]bool),
-		hardMemoryLimitBytes:  int64(config.HardMemoryLimitMB()) * int64(shared.BytesPerMB),
+		skipped:               make(map[string]SkipStats),
+		hardMemoryLimitBytes:  int64(settings.HardMemoryLimitMB()) * int64(shared.BytesPerMB),
 		done:                  make(chan struct{}),
 	}
 
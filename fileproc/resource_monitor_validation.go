@@ -11,13 +11,13 @@ import (
 
 // ValidateFileProcessing checks if a file can be processed based on resource limits.
 func (rm *ResourceMonitor) ValidateFileProcessing(filePath string, fileSize int64) error {
-	if !rm.enabled {
-		return nil
-	}
-
 	rm.mu.RLock()
 	defer rm.mu.RUnlock()
 
+	if !rm.limited {
+		return nil
+	}
+
 	// Check if emergency stop is active
 	if rm.emergencyStopRequested {
 		return shared.NewStructuredError(
@@ -33,7 +33,7 @@ func (rm *ResourceMonitor) ValidateFileProcessing(filePath string, fileSize int6
 
 	// Check file count limit
 	currentFiles := atomic.LoadInt64(&rm.filesProcessed)
-	if int(currentFiles) >= rm.maxFiles {
+	if rm.maxFiles > 0 && int(currentFiles) >= rm.maxFiles {
 		return shared.NewStructuredError(
 			shared.ErrorTypeValidation,
 			shared.CodeResourceLimitFiles,
@@ -48,7 +48,7 @@ func (rm *ResourceMonitor) ValidateFileProcessing(filePath string, fileSize int6
 
 	// Check total size limit
 	currentTotalSize := atomic.LoadInt64(&rm.totalSizeProcessed)
-	if currentTotalSize+fileSize > rm.maxTotalSize {
+	if rm.maxTotalSize > 0 && currentTotalSize+fileSize > rm.maxTotalSize {
 		return shared.NewStructuredError(
 			shared.ErrorTypeValidation,
 			shared.CodeResourceLimitTotalSize,
@@ -63,7 +63,7 @@ func (rm *ResourceMonitor) ValidateFileProcessing(filePath string, fileSize int6
 	}
 
 	// Check overall timeout
-	if time.Since(rm.startTime) > rm.overallTimeout {
+	if rm.overallTimeout > 0 && time.Since(rm.startTime) > rm.overallTimeout {
 		return shared.NewStructuredError(
 			shared.ErrorTypeValidation,
 			shared.CodeResourceLimitTimeout,
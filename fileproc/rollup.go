@@ -0,0 +1,119 @@
+// Package fileproc handles file processing, collection, and output formatting.
+package fileproc
+
+import (
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	ignore "github.com/sabhiram/go-gitignore"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// Rollups collects the files matching the collection.rollups globs, such as assets/** or
+// public/**, so that each glob is summarized as one entry instead of embedding every asset.
+type Rollups struct {
+	root    string
+	rollups []*rollup
+}
+
+// rollup holds the files claimed by one glob.
+type rollup struct {
+	pattern string
+	matcher *ignore.GitIgnore
+	files   []rollupFile
+	size    int64
+}
+
+// rollupFile is a file listed in a rollup entry.
+type rollupFile struct {
+	path string
+	size int64
+}
+
+// NewRollups creates rollups for the gitignore-style patterns, matched against paths relative
+// to root. It returns nil when there are no patterns.
+func NewRollups(root string, patterns []string) *Rollups {
+	if len(patterns) == 0 {
+		return nil
+	}
+
+	r := &Rollups{root: root}
+	for _, pattern := range patterns {
+		r.rollups = append(r.rollups, &rollup{pattern: pattern, matcher: ignore.CompileIgnoreLines(pattern)})
+	}
+
+	return r
+}
+
+// Claim records the file at path, of size bytes, in the first rollup whose glob matches it and
+// reports whether one did. Claimed files are left out of the bundle.
+func (r *Rollups) Claim(path string, size int64) bool {
+	relPath, err := filepath.Rel(r.root, path)
+	if err != nil {
+		return false
+	}
+	relPath = filepath.ToSlash(relPath)
+
+	for _, ru := range r.rollups {
+		if ru.matcher.MatchesPath(relPath) {
+			ru.files = append(ru.files, rollupFile{path: relPath, size: size})
+			ru.size += size
+
+			return true
+		}
+	}
+
+	return false
+}
+
+// Entries returns a summary entry for every rollup that claimed files, in pattern order. Each
+// lists the file count and total size, the count per extension, and every file with its size.
+func (r *Rollups) Entries() []WriteRequest {
+	var entries []WriteRequest
+	for _, ru := range r.rollups {
+		if len(ru.files) == 0 {
+			continue
+		}
+		entries = append(entries, WriteRequest{
+			Path:     ru.pattern,
+			Content:  ru.summary(),
+			Size:     ru.size,
+			Metadata: map[string]string{shared.MetadataKeyRole: shared.MetadataRoleRollup},
+		})
+	}
+
+	return entries
+}
+
+// summary renders the listing of the rollup.
+func (ru *rollup) summary() string {
+	counts := make(map[string]int)
+	for _, file := range ru.files {
+		ext := strings.ToLower(filepath.Ext(file.path))
+		if ext == "" {
+			ext = "(none)"
+		}
+		counts[ext]++
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%d files, %d bytes rolled up]\n", len(ru.files), ru.size)
+	b.WriteString("Types:")
+	for _, ext := range slices.Sorted(maps.Keys(counts)) {
+		fmt.Fprintf(&b, " %s %d", ext, counts[ext])
+	}
+	b.WriteString("\n\n")
+
+	files := slices.SortedFunc(slices.Values(ru.files), func(a, b rollupFile) int {
+		return strings.Compare(a.path, b.path)
+	})
+	for _, file := range files {
+		fmt.Fprintf(&b, "%s (%d bytes)\n", file.path, file.size)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,45 @@
+package fileproc_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/fileproc"
+)
+
+// TestRollupsClaim tests that every file is claimed by the first matching glob, that files
+// outside the globs are kept, and that globs without files get no entry.
+func TestRollupsClaim(t *testing.T) {
+	if fileproc.NewRollups("/src", nil) != nil {
+		t.Error("NewRollups() without patterns is not nil")
+	}
+
+	root := filepath.FromSlash("/src")
+	rollups := fileproc.NewRollups(root, []string{"public/img/**", "public/**", "*.svg", "vendor/**"})
+	tests := []struct {
+		path        string
+		wantClaimed bool
+	}{
+		{path: "public/img/a.png", wantClaimed: true},
+		{path: "public/index.html", wantClaimed: true},
+		{path: "docs/diagram.svg", wantClaimed: true},
+		{path: "main.go", wantClaimed: false},
+		{path: "publicity.md", wantClaimed: false},
+	}
+	for _, tt := range tests {
+		if got := rollups.Claim(filepath.Join(root, filepath.FromSlash(tt.path)), 10); got != tt.wantClaimed {
+			t.Errorf("Claim(%s) = %v, want %v", tt.path, got, tt.wantClaimed)
+		}
+	}
+
+	entries := rollups.Entries()
+	wantPaths := []string{"public/img/**", "public/**", "*.svg"}
+	if len(entries) != len(wantPaths) {
+		t.Fatalf("entries = %+v, want one per glob %v", entries, wantPaths)
+	}
+	for i, entry := range entries {
+		if entry.Path != wantPaths[i] || entry.Size != 10 {
+			t.Errorf("entry %d = %s (%d bytes), want %s (10 bytes)", i, entry.Path, entry.Size, wantPaths[i])
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package fileproc_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+	"pgregory.net/rapid"
+
+	"github.com/ivuorinen/gibidify/fileproc"
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// roundTripRunes are the characters most likely to break escaping: YAML and JSON syntax,
+// whitespace, line breaks, control characters and multi-byte runes.
+var roundTripRunes = []rune{
+	'a', 'Z', '0', ' ', '\t', '\n', '\r', '"', '\'', '\\', '/', ':', '#', '-', '|', '>', '{', '[', '&', '<',
+	0x00, 0x07, 0x1B, 0x7F, 0x85, 0xA0, 0xE9, 0x2028, 0x2029, 0x4E2D, 0xFEFF, 0xFFFD, 0x1F600,
+}
+
+// roundTripContent generates file content: arbitrary bytes, including invalid UTF-8, or text
+// built from roundTripRunes.
+func roundTripContent() *rapid.Generator[string] {
+	return rapid.OneOf(
+		rapid.Map(rapid.SliceOf(rapid.Byte()), func(b []byte) string { return string(b) }),
+		rapid.StringOf(rapid.SampledFrom(roundTripRunes)),
+	)
+}
+
+// decodedContent returns what a bundle decodes content to: content itself, with every invalid
+// UTF-8 byte replaced by U+FFFD as the JSON and YAML encoders do.
+func decodedContent(content string) string {
+	return string([]rune(content))
+}
+
+// writeRoundTripBundle writes entries in format with prefix and suffix and returns the output.
+func writeRoundTripBundle(t *testing.T, format, prefix, suffix string, entries ...fileproc.WriteRequest) []byte {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "roundtrip."+format)
+	outFile, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("creating output: %v", err)
+	}
+	writeCh := make(chan fileproc.WriteRequest, len(entries))
+	for _, entry := range entries {
+		writeCh <- entry
+	}
+	close(writeCh)
+
+	done := make(chan struct{})
+	fileproc.StartWriter(outFile, writeCh, done, format, prefix, suffix)
+	<-done
+	if err := outFile.Close(); err != nil {
+		t.Fatalf("closing output: %v", err)
+	}
+
+	data, err := os.ReadFile(path) // #nosec G304 -- path is in t.TempDir()
+	if err != nil {
+		t.Fatalf("reading output: %v", err)
+	}
+
+	return data
+}
+
+// decodeRoundTripBundle decodes a JSON or YAML bundle.
+func decodeRoundTripBundle(t *rapid.T, format string, data []byte) fileproc.OutputData {
+	var bundle fileproc.OutputData
+	var err error
+	if format == shared.FormatJSON {
+		err = json.Unmarshal(data, &bundle)
+	} else {
+		err = yaml.Unmarshal(data, &bundle)
+	}
+	if err != nil {
+		t.Fatalf("decoding %s bundle: %v\n%s", format, err, data)
+	}
+
+	return bundle
+}
+
+// TestWritersRoundTrip checks that for any content, prefix and suffix, the JSON and YAML
+// bundles decode back to exactly what was written.
+func TestWritersRoundTrip(t *testing.T) {
+	for _, format := range []string{shared.FormatJSON, shared.FormatYAML} {
+		t.Run(format, func(t *testing.T) {
+			rapid.Check(t, func(rt *rapid.T) {
+				prefix := roundTripContent().Draw(rt, "prefix")
+				suffix := roundTripContent().Draw(rt, "suffix")
+				contents := rapid.SliceOfN(roundTripContent(), 1, 4).Draw(rt, "contents")
+
+				entries := make([]fileproc.WriteRequest, len(contents))
+				for i, content := range contents {
+					entries[i] = fileproc.WriteRequest{Path: "file" + string(rune('a'+i)) + ".txt", Content: content}
+				}
+				bundle := decodeRoundTripBundle(rt, format, writeRoundTripBundle(t, format, prefix, suffix, entries...))
+
+				if bundle.Prefix != decodedContent(prefix) || bundle.Suffix != decodedContent(suffix) {
+					rt.Fatalf("prefix, suffix = %q, %q, want %q, %q", bundle.Prefix, bundle.Suffix, prefix, suffix)
+				}
+				if len(bundle.Files) != len(contents) {
+					rt.Fatalf("decoded %d files, want %d", len(bundle.Files), len(contents))
+				}
+				for i, file := range bundle.Files {
+					if want := decodedContent(contents[i]); file.Content != want {
+						rt.Fatalf("%s content = %q, want %q", file.Path, file.Content, want)
+					}
+				}
+			})
+		})
+	}
+}
+
+// TestStreamingWritersRoundTrip checks that streamed files decode back to what was read.
+// Streamed YAML is written as a literal block without looking ahead, so it holds only for
+// content a literal block can carry, and a missing final newline is added.
+func TestStreamingWritersRoundTrip(t *testing.T) {
+	for _, format := range []string{shared.FormatJSON, shared.FormatYAML} {
+		t.Run(format, func(t *testing.T) {
+			rapid.Check(t, func(rt *rapid.T) {
+				contents := rapid.SliceOfN(roundTripContent(), 1, 3).Draw(rt, "contents")
+
+				entries := make([]fileproc.WriteRequest, 0, 2*len(contents))
+				wants := make([]string, 0, 2*len(contents))
+				for i, content := range contents {
+					want := decodedContent(content)
+					if format == shared.FormatYAML {
+						if !shared.YAMLLiteralSafe(content) {
+							continue
+						}
+						if content != "" && !strings.HasSuffix(content, "\n") {
+							want += "\n"
+						}
+					}
+					path := "stream" + string(rune('a'+i)) + ".txt"
+					entries = append(entries,
+						fileproc.WriteRequest{Path: path, IsStream: true, Reader: strings.NewReader(content)},
+						fileproc.WriteRequest{Path: "after-" + path, Content: "next"},
+					)
+					wants = append(wants, want, "next")
+				}
+				bundle := decodeRoundTripBundle(rt, format, writeRoundTripBundle(t, format, "", "", entries...))
+
+				if len(bundle.Files) != len(wants) {
+					rt.Fatalf("decoded %d files, want %d", len(bundle.Files), len(wants))
+				}
+				for i, file := range bundle.Files {
+					if file.Content != wants[i] {
+						rt.Fatalf("%s content = %q, want %q", file.Path, file.Content, wants[i])
+					}
+				}
+			})
+		})
+	}
+}
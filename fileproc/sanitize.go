@@ -0,0 +1,135 @@
+// Package fileproc handles file processing, collection, and output formatting.
+package fileproc
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// utf8BOM is the UTF-8 encoded byte order mark.
+const utf8BOM = "\ufeff"
+
+// InvisibleCounts counts the invisible characters that can make code read differently
+// from how it compiles (trojan-source attacks).
+type InvisibleCounts struct {
+	ZeroWidth int
+	Bidi      int
+}
+
+// Total returns the number of invisible characters counted.
+func (c InvisibleCounts) Total() int {
+	return c.ZeroWidth + c.Bidi
+}
+
+// add counts r and reports whether it is an invisible character.
+func (c *InvisibleCounts) add(r rune) bool {
+	switch r {
+	case '\u200b', '\u200c', '\u200d', '\u2060', '\ufeff':
+		c.ZeroWidth++
+	case '\u061c', '\u200e', '\u200f', '\u202a', '\u202b', '\u202c', '\u202d', '\u202e',
+		'\u2066', '\u2067', '\u2068', '\u2069':
+		c.Bidi++
+	default:
+		return false
+	}
+
+	return true
+}
+
+// note renders the counts as a security metadata note, or "" when there are none.
+func (c InvisibleCounts) note(removed bool) string {
+	if c.Total() == 0 {
+		return ""
+	}
+
+	var parts []string
+	if c.Bidi > 0 {
+		parts = append(parts, fmt.Sprintf("%d bidi control", c.Bidi))
+	}
+	if c.ZeroWidth > 0 {
+		parts = append(parts, fmt.Sprintf("%d zero-width", c.ZeroWidth))
+	}
+	action := "found"
+	if removed {
+		action = "removed"
+	}
+
+	return strings.Join(parts, " and ") + " characters " + action
+}
+
+// CountInvisible counts zero-width and bidi control characters in content, ignoring a leading BOM.
+func CountInvisible(content []byte) InvisibleCounts {
+	var counts InvisibleCounts
+	for _, r := range string(bytes.TrimPrefix(content, []byte(utf8BOM))) {
+		counts.add(r)
+	}
+
+	return counts
+}
+
+// RemoveInvisible returns content without zero-width and bidi control characters.
+func RemoveInvisible(content string) string {
+	var counts InvisibleCounts
+
+	return strings.Map(func(r rune) rune {
+		if counts.add(r) {
+			return -1
+		}
+
+		return r
+	}, content)
+}
+
+// invisibleReader drops zero-width and bidi control characters while streaming.
+type invisibleReader struct {
+	src     *bufio.Reader
+	pending []byte
+	buf     [utf8.UTFMax]byte
+}
+
+// newInvisibleReader wraps src so invisible characters are removed.
+func newInvisibleReader(src *bufio.Reader) io.Reader {
+	return &invisibleReader{src: src}
+}
+
+// Read implements io.Reader.
+func (r *invisibleReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if len(r.pending) > 0 {
+			copied := copy(p[n:], r.pending)
+			r.pending = r.pending[copied:]
+			n += copied
+
+			continue
+		}
+
+		ch, size, err := r.src.ReadRune()
+		if err != nil {
+			if n > 0 && err == io.EOF {
+				return n, nil
+			}
+
+			return n, err //nolint:wrapcheck // EOF must not be wrapped; the header reader wraps other errors
+		}
+		var counts InvisibleCounts
+		if counts.add(ch) {
+			continue
+		}
+		if ch == utf8.RuneError && size == 1 {
+			// Keep invalid bytes as they are rather than replacing them
+			_ = r.src.UnreadRune()
+			b, _ := r.src.ReadByte()
+			r.pending = append(r.buf[:0], b)
+
+			continue
+		}
+		r.pending = utf8.AppendRune(r.buf[:0], ch)
+	}
+
+	return n, nil
+}
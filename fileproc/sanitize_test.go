@@ -0,0 +1,101 @@
+package fileproc_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/fileproc"
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// TestInvisibleCharacters tests counting and removal of zero-width and bidi control characters.
+func TestInvisibleCharacters(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    fileproc.InvisibleCounts
+		clean   string
+	}{
+		{name: "plain text", content: "x := 1", clean: "x := 1"},
+		{name: "leading BOM ignored", content: "\ufeffx", clean: "x"},
+		{
+			name:    "zero-width space",
+			content: "ad\u200bmin",
+			want:    fileproc.InvisibleCounts{ZeroWidth: 1},
+			clean:   "admin",
+		},
+		{
+			name:    "trojan source",
+			content: "/*\u202e } \u2066if (isAdmin)\u2069 \u2066 begin*/",
+			want:    fileproc.InvisibleCounts{Bidi: 4},
+			clean:   "/* } if (isAdmin)  begin*/",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fileproc.CountInvisible([]byte(tt.content)); got != tt.want {
+				t.Errorf("CountInvisible() = %+v, want %+v", got, tt.want)
+			}
+			if got := fileproc.RemoveInvisible(strings.TrimPrefix(tt.content, "\ufeff")); got != tt.clean {
+				t.Errorf("RemoveInvisible() = %q, want %q", got, tt.clean)
+			}
+		})
+	}
+}
+
+// TestFileProcessorSanitize tests BOM stripping, invisible character removal and the security note
+// for in-memory and streamed files.
+func TestFileProcessorSanitize(t *testing.T) {
+	line := "access := \"user\u202e\u2066\" // admin\n"
+	large := strings.Repeat(line, shared.FileProcessingStreamThreshold/len(line)+1)
+
+	tests := []struct {
+		name      string
+		remove    bool
+		content   string
+		wantNote  string
+		wantClean bool
+	}{
+		{name: "note only", content: line, wantNote: "2 bidi control characters found"},
+		{name: "remove", remove: true, content: line, wantNote: "2 bidi control characters removed", wantClean: true},
+		{name: "remove streamed", remove: true, content: large, wantNote: "removed", wantClean: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testutil.SetViperKeys(t, map[string]any{shared.ConfigKeyOutputSanitizeInvisible: tt.remove})
+			dir := t.TempDir()
+			path := testutil.CreateTestFile(t, dir, "main.go", []byte("\ufeff"+tt.content))
+
+			sanitized := 0
+			processor := fileproc.NewFileProcessor(dir)
+			processor.SetSanitizeHook(func() { sanitized++ })
+			outCh := make(chan fileproc.WriteRequest, 1)
+			testutil.MustSucceed(t, processor.ProcessWithContext(context.Background(), path, outCh), "processing")
+			req := <-outCh
+
+			content := req.Content
+			if req.IsStream {
+				data, err := io.ReadAll(req.Reader)
+				testutil.MustSucceed(t, err, "reading stream")
+				content = string(data)
+			}
+			if strings.Contains(content, "\ufeff") {
+				t.Error("BOM not stripped")
+			}
+			if clean := !strings.ContainsAny(content, "\u202e\u2066"); clean != tt.wantClean {
+				t.Errorf("invisible characters removed = %v, want %v", clean, tt.wantClean)
+			}
+			if note := req.Metadata[shared.MetadataKeySecurity]; !strings.Contains(note, tt.wantNote) {
+				t.Errorf("security note = %q, want %q", note, tt.wantNote)
+			}
+			if sanitized != 1 {
+				t.Errorf("sanitize hook called %d times, want 1", sanitized)
+			}
+		})
+	}
+}
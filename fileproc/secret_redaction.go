@@ -0,0 +1,80 @@
+// Package fileproc handles file processing, collection, and output formatting.
+package fileproc
+
+import (
+	"regexp"
+	"slices"
+
+	"github.com/ivuorinen/gibidify/config"
+)
+
+// Names of the built-in secret redactions, shown in the [REDACTED:<name>] markers and the
+// metrics report.
+const (
+	RedactionPrivateKey   = "private-key"
+	RedactionAWSAccessKey = "aws-access-key"
+	RedactionAWSSecretKey = "aws-secret-key"
+	RedactionGitHubToken  = "github-token"
+	RedactionSlackToken   = "slack-token"
+	RedactionJWT          = "jwt"
+	RedactionPassword     = "password"
+	RedactionSecret       = "secret"
+)
+
+var (
+	privateKeyBegin = regexp.MustCompile(`-----BEGIN (?:[A-Z0-9]+ )*PRIVATE KEY-----`)
+	privateKeyEnd   = regexp.MustCompile(`-----END (?:[A-Z0-9]+ )*PRIVATE KEY-----`)
+)
+
+// secretRedactions are the built-in rules of redaction.enabled. Rules for values assigned to a
+// key keep the key, so redacted configuration still reads as configuration; values starting
+// with $, { or < are left alone as references rather than secrets. Private key blocks spanning
+// lines are handled by the redactor; this rule only covers keys written on one line.
+var secretRedactions = []Redaction{
+	secretRedaction(RedactionPrivateKey,
+		`-----BEGIN (?:[A-Z0-9]+ )*PRIVATE KEY-----.*?-----END (?:[A-Z0-9]+ )*PRIVATE KEY-----`, ""),
+	secretRedaction(RedactionAWSAccessKey, `\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`, ""),
+	secretRedaction(RedactionAWSSecretKey,
+		`(?i)(\baws_?secret_?access_?key["']?\s*[:=]\s*["']?)[A-Za-z0-9/+=]{40}`, "${1}"),
+	secretRedaction(RedactionGitHubToken, `\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`, ""),
+	secretRedaction(RedactionSlackToken, `\bxox[abprs]-[A-Za-z0-9-]{10,}`, ""),
+	secretRedaction(RedactionJWT, `\beyJ[A-Za-z0-9_-]{8,}\.eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}`, ""),
+	secretRedaction(RedactionPassword,
+		`(?i)(\b(?:[a-z0-9]+[_-])*passw(?:or)?d["']?\s*[:=]\s*["']?)[^\s"'$<{\[][^\s"',;&]{3,}`, "${1}"),
+	secretRedaction(RedactionSecret,
+		`(?i)(\b(?:[a-z0-9]+[_-])*(?:api[_-]?key|secret|token)["']?\s*[:=]\s*["'])[^\s"'$<{\[][^\s"']{7,}`, "${1}"),
+}
+
+// secretRedaction creates a built-in rule replacing matches of pattern with prefix, which may
+// refer to submatches, followed by the marker of name.
+func secretRedaction(name, pattern, prefix string) Redaction {
+	return Redaction{Name: name, Pattern: regexp.MustCompile(pattern), Replacement: prefix + redactionMarker(name)}
+}
+
+// redactionMarker returns the text replacing a secret found by the redaction name.
+func redactionMarker(name string) string {
+	return "[REDACTED:" + name + "]"
+}
+
+// SecretRedactions returns the built-in secret redactions followed by the redaction.patterns
+// rules of settings, or nil when redaction.enabled is off. Rules that do not compile are left
+// out; validation reports them. Compile them once per run and pass them to every
+// FileProcessor with SetSecretRedactions.
+func SecretRedactions(settings *config.Settings) []Redaction {
+	if !settings.RedactionEnabled() {
+		return nil
+	}
+
+	redactions := slices.Clone(secretRedactions)
+	for _, custom := range settings.RedactionPatterns() {
+		pattern, err := regexp.Compile(custom.Pattern)
+		if err != nil || custom.Pattern == "" {
+			continue
+		}
+		redactions = append(redactions, Redaction{
+			Name: custom.Name, Pattern: pattern, Replacement: redactionMarker(custom.Name),
+		})
+	}
+
+	return redactions
+}
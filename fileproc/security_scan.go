@@ -0,0 +1,112 @@
+// Package fileproc handles file processing, collection, and output formatting.
+package fileproc
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// Security finding kinds, reported in the run summary.
+const (
+	SecurityFindingSecret        = "potential secret"
+	SecurityFindingSuspiciousURL = "suspicious URL"
+	SecurityFindingPrivateKey    = "private key"
+)
+
+// securityPattern matches one kind of finding on a single line.
+type securityPattern struct {
+	kind    string
+	pattern *regexp.Regexp
+}
+
+// securityPatterns are deliberately few and cheap: the scan reports what a reviewer should
+// look at before sharing a bundle, it does not try to be a complete secret scanner.
+var securityPatterns = []securityPattern{
+	{SecurityFindingPrivateKey, regexp.MustCompile(`-----BEGIN (?:[A-Z0-9]+ )*PRIVATE KEY-----`)},
+	{SecurityFindingSecret, regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{SecurityFindingSecret, regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)},
+	{SecurityFindingSecret, regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`)},
+	{SecurityFindingSecret, regexp.MustCompile(
+		`(?i)\b[a-z0-9_]*(?:api[_-]?key|secret|passw(?:or)?d|token)["']?\s*[:=]\s*["'][^"'\s]{8,}["']`,
+	)},
+	{SecurityFindingSuspiciousURL, regexp.MustCompile(`\b[a-z][a-z0-9+.-]*://[^/\s:@"']+:[^/\s@"']+@[^\s"']+`)},
+	{SecurityFindingSuspiciousURL, regexp.MustCompile(`\bhttps?://(?:\d{1,3}\.){3}\d{1,3}(?::\d+)?(?:/[^\s"']*)?`)},
+	{SecurityFindingSuspiciousURL, regexp.MustCompile(
+		`(?i)\bhttps?://(?:[a-z0-9-]+\.)*(?:pastebin\.com|ngrok\.io|ngrok-free\.app|transfer\.sh)\b`,
+	)},
+}
+
+// ScanSecurity counts potential secrets, suspicious URLs and embedded private keys in content,
+// keyed by finding kind.
+func ScanSecurity(content []byte) map[string]int {
+	findings := map[string]int{}
+	for line := range bytes.Lines(content) {
+		scanSecurityLine(line, findings)
+	}
+
+	return findings
+}
+
+// scanSecurityLine adds the findings on one line to findings.
+func scanSecurityLine(line []byte, findings map[string]int) {
+	for _, p := range securityPatterns {
+		if n := len(p.pattern.FindAllIndex(line, -1)); n > 0 {
+			findings[p.kind] += n
+		}
+	}
+}
+
+// securityScanReader scans streamed content line by line as it is read and reports
+// the findings once the stream ends.
+type securityScanReader struct {
+	src      io.Reader
+	line     []byte
+	findings map[string]int
+	report   func(map[string]int)
+	done     bool
+}
+
+// newSecurityScanReader wraps src so its content is scanned and report is called at EOF
+// when anything was found.
+func newSecurityScanReader(src io.Reader, report func(map[string]int)) io.Reader {
+	return &securityScanReader{src: src, findings: map[string]int{}, report: report}
+}
+
+// Read implements io.Reader.
+func (r *securityScanReader) Read(p []byte) (int, error) {
+	n, err := r.src.Read(p)
+	r.scan(p[:n])
+	if err == io.EOF && !r.done {
+		r.done = true
+		scanSecurityLine(r.line, r.findings)
+		if len(r.findings) > 0 {
+			r.report(r.findings)
+		}
+	}
+
+	return n, err //nolint:wrapcheck // EOF must not be wrapped; the header reader wraps other errors
+}
+
+// scan scans every line completed by data and keeps the remainder for the next read.
+// Overlong lines are scanned in chunks so memory stays bounded.
+func (r *securityScanReader) scan(data []byte) {
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			r.line = append(r.line, data...)
+			if len(r.line) >= shared.FileProcessingStreamChunkSize {
+				scanSecurityLine(r.line, r.findings)
+				r.line = r.line[:0]
+			}
+
+			return
+		}
+		r.line = append(r.line, data[:i]...)
+		scanSecurityLine(r.line, r.findings)
+		r.line = r.line[:0]
+		data = data[i+1:]
+	}
+}
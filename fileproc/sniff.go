@@ -0,0 +1,96 @@
+// Package fileproc handles file processing, collection, and output formatting.
+package fileproc
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"unicode/utf8"
+
+	"github.com/ivuorinen/gibidify/config"
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// BinaryContentReason is the skip reason of files whose content looks binary, used in the
+// metrics report.
+const BinaryContentReason = "binary content"
+
+// maxNonTextRatio is the share of control characters and invalid UTF-8 bytes above which a
+// sample without null bytes looks binary. Text in a legacy 8-bit encoding with a few accented
+// letters stays well below it and is bundled with an encoding warning.
+const maxNonTextRatio = 0.3
+
+// ContentSniffer catches binary files the extension check lets through, such as a blob named
+// data.txt, by inspecting their first bytes.
+type ContentSniffer struct {
+	enabled bool
+}
+
+// NewContentSniffer creates a sniffer with the current configuration.
+func NewContentSniffer() *ContentSniffer {
+	return newContentSniffer(config.Global())
+}
+
+// newContentSniffer creates a sniffer with the configuration in settings.
+func newContentSniffer(settings *config.Settings) *ContentSniffer {
+	return &ContentSniffer{enabled: settings.ContentSniffingEnabled()}
+}
+
+// Inspect samples the beginning of the file at filePath and returns why it looks binary, or ""
+// when it looks like text.
+func (s *ContentSniffer) Inspect(filePath string) (string, error) {
+	if !s.enabled {
+		return "", nil
+	}
+
+	file, err := openLimited(filePath)
+	if err != nil {
+		return "", shared.WrapError(err, shared.ErrorTypeProcessing, shared.CodeProcessingFileRead, "failed to open file").
+			WithFilePath(filePath)
+	}
+	defer shared.SafeCloseReader(file, filePath)
+
+	sample := make([]byte, shared.ContentSniffSampleSize)
+	n, err := io.ReadFull(file, sample)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", shared.WrapError(err, shared.ErrorTypeProcessing, shared.CodeProcessingFileRead, "failed to read file").
+			WithFilePath(filePath)
+	}
+
+	return SniffBinary(sample[:n]), nil
+}
+
+// SniffBinary returns why sample, the beginning of a file, looks binary: it holds a null byte,
+// or more than 30% of it is control characters and bytes that are not valid UTF-8. It returns
+// "" for text.
+func SniffBinary(sample []byte) string {
+	if i := bytes.IndexByte(sample, 0); i >= 0 {
+		return fmt.Sprintf("null byte at offset %d", i)
+	}
+
+	sample = trimPartialRune(sample)
+	nonText := 0
+	for i := 0; i < len(sample); {
+		r, size := utf8.DecodeRune(sample[i:])
+		if (r == utf8.RuneError && size == 1) || isBinaryControl(r) {
+			nonText += size
+		}
+		i += size
+	}
+	if ratio := float64(nonText) / float64(max(len(sample), 1)); ratio > maxNonTextRatio {
+		return fmt.Sprintf("%.0f%% control characters or invalid UTF-8", ratio*100)
+	}
+
+	return ""
+}
+
+// isBinaryControl reports whether r is a control character text files do not contain. Tabs,
+// line and page breaks, backspaces and the escapes of ANSI-colored logs count as text.
+func isBinaryControl(r rune) bool {
+	switch r {
+	case '\t', '\n', '\v', '\f', '\r', '\b', '\x1b':
+		return false
+	default:
+		return r < ' ' || r == '\x7f'
+	}
+}
@@ -0,0 +1,70 @@
+package fileproc_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/fileproc"
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// TestSniffBinary tests the null byte and UTF-8 validity heuristics.
+func TestSniffBinary(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{name: "source code", content: strings.Repeat("func main() {\n\tfmt.Println(\"hello\")\n}\n", 100)},
+		{name: "non-ASCII prose", content: strings.Repeat("日本語のテキストです。\n", 100)},
+		{name: "colored log", content: strings.Repeat("\x1b[31mERROR\x1b[0m failed\r\n", 100)},
+		{name: "Latin-1 text", content: strings.Repeat("caf\xe9 cr\xe8me br\xfbl\xe9e\n", 100)},
+		{name: "empty"},
+		{name: "null byte", content: "PK\x03\x04\x14\x00\x00\x00", want: "null byte at offset 5"},
+		{name: "control characters", content: strings.Repeat("\x01\x02\x03\x8f\xfe\x7fab", 100),
+			want: "75% control characters or invalid UTF-8"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fileproc.SniffBinary([]byte(tt.content)); got != tt.want {
+				t.Errorf("SniffBinary() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestFileProcessorBinaryContent tests that files with a text extension and binary content are
+// skipped unless fileTypes.contentSniffing.enabled is off.
+func TestFileProcessorBinaryContent(t *testing.T) {
+	dir := t.TempDir()
+	blob := "header\x00\x00\x01\x02payload"
+	filePath := testutil.CreateTestFile(t, dir, "data.txt", []byte(blob))
+
+	t.Run("enabled", func(t *testing.T) {
+		testutil.ResetViperConfig(t, "")
+		outCh := make(chan fileproc.WriteRequest, 1)
+		err := fileproc.NewFileProcessor(dir).ProcessWithContext(context.Background(), filePath, outCh)
+
+		var structErr *shared.StructuredError
+		if !errors.As(err, &structErr) || structErr.Code != shared.CodeValidationBinary {
+			t.Fatalf("expected binary content error, got %v", err)
+		}
+		if structErr.Context["reason"] != fileproc.BinaryContentReason || len(outCh) != 0 {
+			t.Errorf("unexpected skip: context %v, %d queued", structErr.Context, len(outCh))
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		testutil.SetViperKeys(t, map[string]any{shared.ConfigKeyContentSniffingEnabled: false})
+		outCh := make(chan fileproc.WriteRequest, 1)
+		testutil.MustSucceed(t,
+			fileproc.NewFileProcessor(dir).ProcessWithContext(context.Background(), filePath, outCh), "processing")
+		if req := <-outCh; req.Path != "data.txt" {
+			t.Errorf("queued %q, want data.txt", req.Path)
+		}
+	})
+}
@@ -0,0 +1,254 @@
+// Package fileproc handles file processing, collection, and output formatting.
+package fileproc
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// splitCloseReserve is the room in bytes left in every part for closing its document, besides
+// the suffix.
+const splitCloseReserve = 256
+
+// SplitOptions splits a bundle into part files holding at most Limit bytes, or Limit estimated
+// tokens with Tokens. Parts roll over between file sections, so a file larger than the limit
+// gets a part of its own that exceeds it.
+type SplitOptions struct {
+	// Destination names the bundle; part n is written to SplitPartPath(Destination, n).
+	Destination string
+	Limit       int64
+	Tokens      bool
+	// Sync flushes every part the writer creates to stable storage before closing it.
+	Sync bool
+	// Parts receives the paths of the part files in order when set.
+	Parts *[]string
+}
+
+// SplitPartPath returns the path of part n of a bundle split from destination: output.md
+// becomes output.part1.md.
+func SplitPartPath(destination string, n int) string {
+	ext := filepath.Ext(destination)
+
+	return strings.TrimSuffix(destination, ext) + ".part" + strconv.Itoa(n) + ext
+}
+
+// bundlePart is the part file of a split bundle being written.
+type bundlePart struct {
+	file    *os.File
+	output  *bundleOutput
+	timer   *sectionTimer
+	writer  FormatWriter
+	started bool
+	entries int
+	// size is the bytes, or estimated tokens, written to the part so far.
+	size int64
+}
+
+// splitWriter writes a bundle into parts, each started with a header naming the part and its
+// first file section.
+type splitWriter struct {
+	opts           WriterOptions
+	split          *SplitOptions
+	prefix, suffix string
+	factory        func(outputWriter) FormatWriter
+	part           *bundlePart
+	number         int
+	stats          OutputStats
+	summary        *RunSummary
+}
+
+// startSplitWriter writes the requests from writeCh into the parts of a split bundle, the first
+// of which is outFile. The caller closes outFile; the writer closes the parts it creates.
+func startSplitWriter(
+	outFile *os.File,
+	writeCh <-chan WriteRequest,
+	done chan<- struct{},
+	prefix, suffix string,
+	opts WriterOptions,
+	writerFactory func(outputWriter) FormatWriter,
+) {
+	defer close(done)
+
+	w := &splitWriter{opts: opts, split: opts.Split, prefix: prefix, suffix: suffix, factory: writerFactory}
+	if opts.Summary != nil {
+		w.summary = &RunSummary{RunID: w.opts.RunID}
+	}
+	w.openPart(outFile)
+
+	settings := opts.settings()
+	fileIDs, algorithm := settings.OutputFileIDs(), settings.PerformanceHashAlgorithm()
+	requests := writeCh
+	if renderer, ok := w.part.writer.(inlineRenderer); ok && settings.PerformanceFormatWorkers() > 1 {
+		requests = renderConcurrently(writeCh, renderer, settings.PerformanceFormatWorkers(), fileIDs, algorithm)
+	}
+
+	for req := range requests {
+		if fileIDs && req.rendered == nil {
+			req = withFileID(req, algorithm)
+		}
+		if err := w.write(req); err != nil {
+			shared.LogError("Failed to write file", err)
+		}
+	}
+
+	if err := w.closePart(true); err != nil {
+		shared.LogError("Failed to close bundle part", err)
+	}
+	if opts.Stats != nil {
+		*opts.Stats = w.stats
+	}
+}
+
+// openPart makes file the next part of the bundle.
+func (w *splitWriter) openPart(file *os.File) {
+	w.number++
+	output := newBundleOutput(file, w.opts.settings().OutputBufferSize())
+	timer, out := newSectionTimer(output, w.opts.Timing)
+	w.part = &bundlePart{file: file, output: output, timer: timer, writer: w.factory(out)}
+	if w.split.Parts != nil {
+		*w.split.Parts = append(*w.split.Parts, file.Name())
+	}
+}
+
+// write writes req to the current part, first rolling over to a new part when the current
+// one has file sections and req would take it over the limit.
+func (w *splitWriter) write(req WriteRequest) error {
+	language := w.opts.registry().Language(req.Path)
+	if w.part.entries > 0 && w.part.size+w.measure(splitEntrySize(req), language) > w.limit() {
+		if err := w.rollOver(); err != nil {
+			return err
+		}
+	}
+	if err := w.start(req.Path); err != nil {
+		return err
+	}
+
+	before := w.part.output.written()
+	req = w.part.timer.begin(req)
+	err := w.part.writer.WriteFile(req)
+	w.part.timer.end(req)
+	if err != nil {
+		return err //nolint:wrapcheck // the format writers return structured errors
+	}
+	w.part.entries++
+	w.part.size += w.measure(w.part.output.written()-before, language)
+	w.summary.add(req, w.opts.registry())
+
+	return nil
+}
+
+// rollOver ends the current part and creates the next one.
+func (w *splitWriter) rollOver() error {
+	path := SplitPartPath(w.split.Destination, w.number+1)
+	// #nosec G304 -- parts are named after the destination validated in flags.validate()
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, shared.OutputFilePermission)
+	if err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOFileCreate, "failed to create bundle part").
+			WithFilePath(path)
+	}
+	if err := w.closePart(false); err != nil {
+		shared.LogError("Failed to close bundle part", err)
+	}
+	w.openPart(file)
+
+	return nil
+}
+
+// start starts the current part, unless started, with a header naming it and first, the path
+// of its first file section.
+func (w *splitWriter) start(first string) error {
+	if w.part.started {
+		return nil
+	}
+	w.part.started = true
+
+	header := fmt.Sprintf("Part %d of %s", w.number, filepath.Base(w.split.Destination))
+	if first != "" {
+		header += ", from " + first
+	}
+	if w.prefix != "" {
+		header = w.prefix + " - " + header
+	}
+	if err := startFormatWriterOutput(w.part.writer, header, w.suffix, w.opts.Config); err != nil {
+		return err
+	}
+	w.part.size = w.measure(w.part.output.written(), "")
+
+	return nil
+}
+
+// closePart ends the current part, with the run summary when it is the last one. Parts the
+// writer created are closed; the first one belongs to the caller.
+func (w *splitWriter) closePart(last bool) error {
+	part := w.part
+	err := w.start("")
+	if err == nil {
+		err = w.endDocument(last)
+	}
+	err = errors.Join(err, part.output.Flush())
+	w.stats.Writes += part.output.stats.Writes
+	w.stats.Bytes += part.output.stats.Bytes
+	w.fail(part.output.stats.Err)
+	if w.number > 1 {
+		if w.split.Sync {
+			err = errors.Join(err, part.file.Sync())
+		}
+		err = errors.Join(err, part.file.Close())
+	}
+	if err != nil {
+		w.fail(err)
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOWrite, "failed to finish bundle part").
+			WithFilePath(part.file.Name())
+	}
+
+	return nil
+}
+
+// fail records err when it is the first error writing the bundle.
+func (w *splitWriter) fail(err error) {
+	if w.stats.Err == nil {
+		w.stats.Err = err
+	}
+}
+
+// endDocument ends the document of the current part, with the run summary when it is the last one.
+func (w *splitWriter) endDocument(last bool) error {
+	if last {
+		return closeFormatWriter(w.part.writer, w.summary, w.opts.Summary)
+	}
+
+	return w.part.writer.Close() //nolint:wrapcheck // wrapped by closePart
+}
+
+// limit returns the room for file sections in a part, after the reserve for ending it.
+func (w *splitWriter) limit() int64 {
+	return w.split.Limit - w.measure(int64(splitCloseReserve+len(w.suffix)), "")
+}
+
+// measure returns size bytes of content in language in the unit of the limit.
+func (w *splitWriter) measure(size int64, language string) int64 {
+	if !w.split.Tokens {
+		return size
+	}
+	tokens, _ := estimateTokens(w.opts.settings(), size, language)
+
+	return tokens
+}
+
+// splitEntrySize returns the size of the content of req, which its section exceeds by its header.
+func splitEntrySize(req WriteRequest) int64 {
+	switch {
+	case req.rendered != nil:
+		return int64(len(*req.rendered))
+	case req.IsStream:
+		return req.Size
+	default:
+		return int64(len(req.Content))
+	}
+}
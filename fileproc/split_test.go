@@ -0,0 +1,122 @@
+package fileproc_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/fileproc"
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+func TestSplitPartPath(t *testing.T) {
+	tests := []struct {
+		destination string
+		n           int
+		want        string
+	}{
+		{destination: "output.md", n: 1, want: "output.part1.md"},
+		{destination: filepath.Join("out", "bundle.tar.json"), n: 12, want: filepath.Join("out", "bundle.tar.part12.json")},
+		{destination: "bundle", n: 2, want: "bundle.part2"},
+	}
+
+	for _, tt := range tests {
+		if got := fileproc.SplitPartPath(tt.destination, tt.n); got != tt.want {
+			t.Errorf("SplitPartPath(%q, %d) = %q, want %q", tt.destination, tt.n, got, tt.want)
+		}
+	}
+}
+
+// writeSplitBundle writes count Go files of size bytes as a JSON bundle split by split into
+// parts of destination, and returns the parts in order.
+func writeSplitBundle(t *testing.T, split *fileproc.SplitOptions, count, size int) []fileproc.OutputData {
+	t.Helper()
+
+	var parts []string
+	split.Parts = &parts
+	outFile, err := os.Create(fileproc.SplitPartPath(split.Destination, 1))
+	testutil.MustSucceed(t, err, "creating the first part")
+
+	writeCh := make(chan fileproc.WriteRequest, count)
+	for i := range count {
+		content := "package main\n" + strings.Repeat("x", size-len("package main\n"))
+		writeCh <- fileproc.WriteRequest{Path: fmt.Sprintf("file%d.go", i), Content: content}
+	}
+	close(writeCh)
+
+	done := make(chan struct{})
+	opts := fileproc.WriterOptions{Split: split, Summary: func(*fileproc.RunSummary) {}}
+	fileproc.StartWriterWithOptions(outFile, writeCh, done, shared.FormatJSON, "Docs", "End", opts)
+	<-done
+	testutil.CloseFile(t, outFile)
+
+	bundles := make([]fileproc.OutputData, 0, len(parts))
+	for n, part := range parts {
+		if want := fileproc.SplitPartPath(split.Destination, n+1); part != want {
+			t.Errorf("part %d = %s, want %s", n+1, part, want)
+		}
+		data, err := os.ReadFile(part) // #nosec G304 -- part is in t.TempDir()
+		testutil.MustSucceed(t, err, "reading part")
+		var bundle fileproc.OutputData
+		testutil.MustSucceed(t, json.Unmarshal(data, &bundle), "parsing part "+part)
+		// Only a part holding a single file may exceed the limit
+		if !split.Tokens && int64(len(data)) > split.Limit && len(bundle.Files) > 1 {
+			t.Errorf("part %d has %d bytes, over the limit of %d", n+1, len(data), split.Limit)
+		}
+		bundles = append(bundles, bundle)
+	}
+
+	return bundles
+}
+
+// TestStartWriterSplit tests that split bundles roll over between files, with a header naming
+// every part and its first file, the suffix on every part and the run summary on the last.
+func TestStartWriterSplit(t *testing.T) {
+	tests := []struct {
+		name      string
+		split     fileproc.SplitOptions
+		count     int
+		size      int
+		wantParts int
+	}{
+		{name: "bytes", split: fileproc.SplitOptions{Limit: 2048}, count: 6, size: 600, wantParts: 3},
+		{name: "file over the limit", split: fileproc.SplitOptions{Limit: 1024}, count: 2, size: 2000, wantParts: 2},
+		{name: "under the limit", split: fileproc.SplitOptions{Limit: 1 << 20}, count: 6, size: 600, wantParts: 1},
+		{name: "tokens", split: fileproc.SplitOptions{Limit: 500, Tokens: true}, count: 6, size: 600, wantParts: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			split := tt.split
+			split.Destination = filepath.Join(t.TempDir(), "bundle.json")
+			bundles := writeSplitBundle(t, &split, tt.count, tt.size)
+			if len(bundles) != tt.wantParts {
+				t.Fatalf("wrote %d parts, want %d", len(bundles), tt.wantParts)
+			}
+
+			files := 0
+			for n, bundle := range bundles {
+				want := fmt.Sprintf("Docs - Part %d of bundle.json, from %s", n+1, bundle.Files[0].Path)
+				if bundle.Prefix != want || bundle.Suffix != "End" {
+					t.Errorf("part %d prefix, suffix = %q, %q, want %q, End", n+1, bundle.Prefix, bundle.Suffix, want)
+				}
+				if (bundle.Summary != nil) != (n == len(bundles)-1) {
+					t.Errorf("part %d has summary %v, want it on the last part only", n+1, bundle.Summary)
+				}
+				for _, file := range bundle.Files {
+					if file.Path != fmt.Sprintf("file%d.go", files) {
+						t.Errorf("part %d has %s, want file%d.go", n+1, file.Path, files)
+					}
+					files++
+				}
+			}
+			if files != tt.count {
+				t.Errorf("parts hold %d files, want %d", files, tt.count)
+			}
+		})
+	}
+}
@@ -0,0 +1,273 @@
+package fileproc_test
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ivuorinen/gibidify/fileproc"
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// Stress test sizes. They are kept small enough to finish quickly under -race, which is
+// how `make test` and CI run them.
+const (
+	stressRounds     = 20
+	stressItems      = 400
+	stressGoroutines = 16
+	// stressProcs is the minimum GOMAXPROCS the stress tests run with, so goroutines run in
+	// parallel threads and interleave at arbitrary points even on a single CPU.
+	stressProcs = 4
+)
+
+// TestBackpressurePipelineStress drives a producer, worker and consumer pipeline through
+// channels of two slots, cancelling it at a random point in every round but the last.
+func TestBackpressurePipelineStress(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(max(runtime.GOMAXPROCS(0), stressProcs)))
+	defer testutil.SuppressLogs(t)()
+	testutil.SetViperKeys(t, map[string]any{
+		shared.ConfigKeyBackpressureEnabled:          true,
+		shared.ConfigKeyBackpressureMaxPendingFiles:  2,
+		shared.ConfigKeyBackpressureMaxPendingWrites: 2,
+		shared.ConfigKeyBackpressureMemoryCheckInt:   100,
+		// Every memory check exceeds the limit, so back-pressure is applied throughout.
+		shared.ConfigKeyBackpressureMaxMemoryUsage: int64(1),
+	})
+
+	for round := range stressRounds {
+		cancelAt := rand.IntN(stressItems) // #nosec G404 -- test randomness
+		if round == stressRounds-1 {
+			cancelAt = -1
+		}
+		sent, received, filesProcessed := runBackpressurePipeline(t.Context(), cancelAt)
+
+		if received > sent {
+			t.Errorf("round %d: received %d requests, only %d files were sent", round, received, sent)
+		}
+		if cancelAt < 0 && received != stressItems {
+			t.Errorf("uncanceled round received %d requests, want %d", received, stressItems)
+		}
+		if filesProcessed > stressItems {
+			t.Errorf("round %d: back-pressure counted %d files, more than %d sent", round, filesProcessed, stressItems)
+		}
+	}
+}
+
+// runBackpressurePipeline runs one pipeline round, canceling it before the item at cancelAt
+// is sent (never when cancelAt is negative). It returns how many files were sent and written
+// and how many files the back-pressure manager counted.
+func runBackpressurePipeline(parent context.Context, cancelAt int) (sent, received int, filesProcessed int64) {
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	bp := fileproc.NewBackpressureManager()
+	fileCh, writeCh := bp.CreateChannels()
+
+	var workers sync.WaitGroup
+	for range stressGoroutines {
+		workers.Go(func() {
+			for path := range fileCh {
+				bp.WaitForChannelSpace(ctx, nil, writeCh)
+				select {
+				case writeCh <- fileproc.WriteRequest{Path: path}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		})
+	}
+
+	consumed := make(chan int)
+	go func() {
+		count := 0
+		for range writeCh {
+			count++
+			_ = bp.Stats()
+		}
+		consumed <- count
+	}()
+
+produce:
+	for i := range stressItems {
+		if i == cancelAt {
+			cancel()
+		}
+		if bp.ShouldApplyBackpressure(ctx) {
+			bp.ApplyBackpressure(ctx)
+		}
+		bp.WaitForChannelSpace(ctx, fileCh, nil)
+		select {
+		case fileCh <- fmt.Sprintf("file%03d.txt", i):
+			sent++
+		case <-ctx.Done():
+			break produce
+		}
+	}
+	close(fileCh)
+	workers.Wait()
+	close(writeCh)
+
+	return sent, <-consumed, bp.Stats().FilesProcessed
+}
+
+// TestResourceMonitorStress shares one resource monitor between many goroutines acquiring
+// read slots, rate limiting, validating and recording files under random deadlines, while
+// others read its metrics and state, and closes it concurrently at the end.
+func TestResourceMonitorStress(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(max(runtime.GOMAXPROCS(0), stressProcs)))
+	defer testutil.SuppressLogs(t)()
+	const maxReads = 3
+	testutil.SetViperKeys(t, map[string]any{
+		shared.ConfigKeyResourceLimitsEnabled:              true,
+		shared.ConfigKeyResourceLimitsMaxConcurrentReads:   maxReads,
+		shared.ConfigKeyResourceLimitsRateLimitFilesPerSec: 5000,
+		shared.ConfigKeyResourceLimitsEnableMonitoring:     true,
+	})
+
+	rm := fileproc.NewResourceMonitor()
+	var active, peak atomic.Int64
+	var recorded atomic.Int64
+
+	ctx, cancel := context.WithCancel(t.Context())
+	var recorders, readers sync.WaitGroup
+	for range stressGoroutines {
+		recorders.Go(func() {
+			for range stressItems / stressGoroutines * 4 {
+				if ctx.Err() != nil {
+					return
+				}
+				if stressResourceMonitorFile(ctx, rm, &active, &peak) && recorded.Add(1) >= stressItems {
+					cancel()
+				}
+			}
+		})
+		readers.Go(func() {
+			for ctx.Err() == nil {
+				_ = rm.Metrics()
+				_ = rm.IsEmergencyStopActive()
+				_ = rm.IsDegradationActive()
+				time.Sleep(time.Millisecond)
+			}
+		})
+	}
+
+	// The readers stop once enough files are recorded or, when deadlines drop too many, every
+	// recorder has run out of files
+	recorders.Wait()
+	cancel()
+	readers.Wait()
+
+	var closers sync.WaitGroup
+	for range 4 {
+		closers.Go(rm.Close)
+	}
+	closers.Wait()
+
+	if got := peak.Load(); got > maxReads {
+		t.Errorf("%d reads held slots at once, limit is %d", got, maxReads)
+	}
+	if metrics := rm.Metrics(); metrics.ConcurrentReads != 0 || metrics.FilesProcessed != recorded.Load() {
+		t.Errorf("metrics = %d concurrent reads, %d files, want 0 and %d",
+			metrics.ConcurrentReads, metrics.FilesProcessed, recorded.Load())
+	}
+}
+
+// stressResourceMonitorFile processes one simulated file through rm under a random deadline,
+// tracking how many reads hold a slot at once. It reports whether the file was recorded.
+func stressResourceMonitorFile(parent context.Context, rm *fileproc.ResourceMonitor, active, peak *atomic.Int64) bool {
+	timeout := time.Duration(rand.IntN(2000)) * time.Microsecond // #nosec G404 -- test randomness
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	defer cancel()
+
+	if rm.WaitForRateLimit(ctx) != nil || rm.AcquireReadSlot(ctx) != nil {
+		return false
+	}
+	defer rm.ReleaseReadSlot()
+
+	current := active.Add(1)
+	defer active.Add(-1)
+	for {
+		seen := peak.Load()
+		if current <= seen || peak.CompareAndSwap(seen, current) {
+			break
+		}
+	}
+
+	if rm.ValidateFileProcessing("stress.txt", 64) != nil || rm.CheckHardMemoryLimit() != nil {
+		return false
+	}
+	rm.RecordFileProcessed(64)
+
+	return true
+}
+
+// TestFileTypeRegistryReloadStress looks up file types from many goroutines while the file
+// type configuration is reapplied to the same registry.
+func TestFileTypeRegistryReloadStress(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(max(runtime.GOMAXPROCS(0), stressProcs)))
+	registry := fileproc.NewFileTypeRegistry()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	var wg sync.WaitGroup
+	var lookups atomic.Int64
+	errs := make(chan error, stressGoroutines)
+	for range stressGoroutines {
+		wg.Go(func() {
+			for i := 0; ctx.Err() == nil; i++ {
+				if err := checkStableFileTypes(registry, i); err != nil {
+					errs <- err
+					cancel()
+
+					return
+				}
+				lookups.Add(1)
+			}
+		})
+	}
+
+	for round := range stressRounds * 5 {
+		// Let lookups run between reloads.
+		for seen := lookups.Load(); ctx.Err() == nil && lookups.Load() == seen; {
+			runtime.Gosched()
+		}
+		language := []string{"alpha", "beta"}[round%2]
+		registry.Configure(
+			[]string{".stressimg"}, []string{".stressbin"}, map[string]string{".stress": language},
+			nil, nil, nil,
+		)
+		if round%3 == 0 {
+			registry.DisableExtensions([]string{".stressimg"}, nil, []string{".stress"})
+		}
+		_ = registry.Stats()
+		_, _, _ = registry.CacheInfo()
+	}
+	cancel()
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+// checkStableFileTypes checks lookups the reload in TestFileTypeRegistryReloadStress never
+// changes, plus one of the names it does change so their cache entries are contended.
+func checkStableFileTypes(registry *fileproc.FileTypeRegistry, i int) error {
+	if !registry.IsImage(shared.TestFilePNG) || !registry.IsBinary(shared.TestFileEXE) {
+		return fmt.Errorf("built-in image or binary extension lost during reload")
+	}
+	if lang := registry.Language(shared.TestFileGo); lang != "go" {
+		return fmt.Errorf("language of %s = %q during reload, want go", shared.TestFileGo, lang)
+	}
+	if lang := registry.Language(fmt.Sprintf("file%d.stress", i%100)); lang != "" && lang != "alpha" && lang != "beta" {
+		return fmt.Errorf("language of .stress = %q, want alpha, beta or none", lang)
+	}
+
+	return nil
+}
@@ -0,0 +1,321 @@
+// Package fileproc handles file processing, collection, and output formatting.
+package fileproc
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ivuorinen/gibidify/config"
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// commentStyle is how a language writes comments and the string literals that may hold comment
+// markers, as far as stripping comments without touching strings requires.
+type commentStyle struct {
+	// line opens a comment running to the end of the line.
+	line string
+	// block enables /* */ comments, and nested lets them nest.
+	block  bool
+	nested bool
+	// charLiterals makes ' open a character literal, such as 'a', rather than a string.
+	charLiterals bool
+	// backticks makes ` open a string that may span lines, without escapes when rawBackticks is set.
+	backticks    bool
+	rawBackticks bool
+	// triple lists the delimiters of strings that may span lines, such as """.
+	triple []string
+	// keep lists the prefixes of whole-line comments kept as directives, such as //go:build.
+	keep []string
+}
+
+var (
+	cStyle      = commentStyle{line: "//", block: true, charLiterals: true}
+	jvmStyle    = commentStyle{line: "//", block: true, charLiterals: true, triple: []string{`"""`}}
+	jsStyle     = commentStyle{line: "//", block: true, backticks: true}
+	nestedStyle = commentStyle{line: "//", block: true, nested: true, charLiterals: true, triple: []string{`"""`}}
+)
+
+// commentStyles maps the languages of shared.CommentStripLanguages to their comment style.
+var commentStyles = map[string]commentStyle{
+	"c": cStyle, "cpp": cStyle, "java": jvmStyle, "csharp": jvmStyle, "javascript": jsStyle, "typescript": jsStyle,
+	"kotlin": nestedStyle, "swift": nestedStyle, "scala": nestedStyle,
+
+	"go": {
+		line: "//", block: true, charLiterals: true, backticks: true, rawBackticks: true,
+		keep: []string{"//go:", "// +build", "//export "},
+	},
+	"rust":   {line: "//", block: true, nested: true, charLiterals: true},
+	"python": {line: "#", triple: []string{`"""`, `'''`}, keep: []string{"#!"}},
+}
+
+// What the code at a position opens, as classified by commentStripper.next.
+const (
+	codeText = iota
+	lineComment
+	blockComment
+)
+
+var (
+	blockStart = []byte("/*")
+	blockEnd   = []byte("*/")
+)
+
+// commentStripper removes the comments from the lines of one file, carrying block comments and
+// strings that span lines from one line to the next. Markers inside strings are left alone;
+// anything needing a parser, such as JavaScript regular expression literals, is not understood.
+type commentStripper struct {
+	style commentStyle
+	// depth is the nesting depth of the block comment the next line starts in.
+	depth int
+	// quote is the delimiter of the string the next line starts in.
+	quote string
+	// count counts the comments removed.
+	count int
+}
+
+// commentStripLanguages returns the languages comments are stripped from with
+// stripComments.enabled set in settings, or nil when it is not.
+func commentStripLanguages(settings *config.Settings) map[string]bool {
+	if !settings.StripCommentsEnabled() {
+		return nil
+	}
+
+	return stripLanguageSet(settings)
+}
+
+// stripLanguageSet returns the stripComments.languages of settings as a set.
+func stripLanguageSet(settings *config.Settings) map[string]bool {
+	languages := make(map[string]bool)
+	for _, language := range settings.StripCommentsLanguages() {
+		languages[language] = true
+	}
+
+	return languages
+}
+
+// newCommentStripper creates a stripper for a file in language, or returns nil when comments
+// cannot be stripped from it.
+func newCommentStripper(language string) *commentStripper {
+	style, ok := commentStyles[language]
+	if !ok {
+		return nil
+	}
+
+	return &commentStripper{style: style}
+}
+
+// content strips the comments from every line of content.
+func (s *commentStripper) content(content string) string {
+	lines := strings.Split(content, "\n")
+	kept := lines[:0]
+	for i, line := range lines {
+		stripped, keep := s.line([]byte(line))
+		if keep || i == len(lines)-1 {
+			kept = append(kept, string(stripped))
+		}
+	}
+
+	return strings.Join(kept, "\n")
+}
+
+// line strips the comments from one line and reports whether to keep it: lines holding only
+// comments are dropped, other lines lose their comments and the whitespace before them.
+func (s *commentStripper) line(line []byte) ([]byte, bool) {
+	body, cr := bytes.CutSuffix(line, []byte{'\r'})
+	out, keep := s.strip(body)
+	if cr && keep {
+		out = append(out, '\r')
+	}
+
+	return out, keep
+}
+
+// strip strips the comments from a line without its line break.
+func (s *commentStripper) strip(line []byte) ([]byte, bool) {
+	out := make([]byte, 0, len(line))
+	stripped := s.depth > 0
+	for i := 0; i < len(line); {
+		switch {
+		case s.depth > 0:
+			i = s.skipBlock(line, i)
+		case s.quote != "":
+			end := s.stringEnd(line, i)
+			out, i = append(out, line[i:end]...), end
+		default:
+			n, kind := s.next(line[i:])
+			switch kind {
+			case lineComment:
+				if len(bytes.TrimSpace(out)) == 0 && s.directive(line[i:]) {
+					return line, true
+				}
+				s.count++
+
+				return s.finish(line, out, true)
+			case blockComment:
+				s.depth, stripped = 1, true
+				s.count++
+			default:
+				out = append(out, line[i:i+n]...)
+			}
+			i += n
+		}
+	}
+
+	return s.finish(line, out, stripped)
+}
+
+// finish returns out as the stripped line. A string that cannot span lines ends with the line
+// unless a backslash continues it. When a comment was removed outside a string, the whitespace
+// it leaves at the end of the line goes, and a line left blank is dropped.
+func (s *commentStripper) finish(line, out []byte, stripped bool) ([]byte, bool) {
+	if (s.quote == `"` || s.quote == "'") && !bytes.HasSuffix(line, []byte{'\\'}) {
+		s.quote = ""
+	}
+	if !stripped || s.quote != "" {
+		return out, true
+	}
+
+	out = bytes.TrimRight(out, " \t")
+
+	return out, len(bytes.TrimSpace(out)) > 0
+}
+
+// next classifies the code at the start of rest as a comment opening, or as n bytes of code to
+// keep, which open a string when rest starts with one.
+func (s *commentStripper) next(rest []byte) (int, int) {
+	switch {
+	case bytes.HasPrefix(rest, []byte(s.style.line)):
+		return 0, lineComment
+	case s.style.block && bytes.HasPrefix(rest, blockStart):
+		return len(blockStart), blockComment
+	}
+
+	return s.literal(rest), codeText
+}
+
+// literal returns the length of the character literal or string delimiter at the start of
+// rest, entering the string, or 1 for any other byte.
+func (s *commentStripper) literal(rest []byte) int {
+	for _, delimiter := range s.style.triple {
+		if bytes.HasPrefix(rest, []byte(delimiter)) {
+			s.quote = delimiter
+
+			return len(delimiter)
+		}
+	}
+
+	switch {
+	case rest[0] == '"':
+		s.quote = `"`
+	case rest[0] == '\'' && s.style.charLiterals:
+		return charLiteralLen(rest)
+	case rest[0] == '\'':
+		s.quote = "'"
+	case rest[0] == '`' && s.style.backticks:
+		s.quote = "`"
+	}
+
+	return 1
+}
+
+// stringEnd returns where the string the stripper is in ends on line, leaving it, or the end of
+// the line when the string goes on.
+func (s *commentStripper) stringEnd(line []byte, i int) int {
+	escapes := s.quote != "`" || !s.style.rawBackticks
+	for i < len(line) {
+		switch {
+		case escapes && line[i] == '\\':
+			i += 2
+		case bytes.HasPrefix(line[i:], []byte(s.quote)):
+			end := i + len(s.quote)
+			s.quote = ""
+
+			return end
+		default:
+			i++
+		}
+	}
+
+	return min(i, len(line))
+}
+
+// skipBlock returns where the block comment the stripper is in ends on line, leaving it, or the
+// end of the line when the comment goes on.
+func (s *commentStripper) skipBlock(line []byte, i int) int {
+	for i < len(line) {
+		switch {
+		case bytes.HasPrefix(line[i:], blockEnd):
+			s.depth--
+			i += len(blockEnd)
+			if s.depth == 0 {
+				return i
+			}
+		case s.style.nested && bytes.HasPrefix(line[i:], blockStart):
+			s.depth++
+			i += len(blockStart)
+		default:
+			i++
+		}
+	}
+
+	return i
+}
+
+// directive reports whether the comment at the start of rest is kept as a directive.
+func (s *commentStripper) directive(rest []byte) bool {
+	for _, prefix := range s.style.keep {
+		if bytes.HasPrefix(rest, []byte(prefix)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// charLiteralLen returns the length of the character literal at the start of rest, such as 'a',
+// '\n' or '世', or 1 when the quote opens none, as in a Rust lifetime.
+func charLiteralLen(rest []byte) int {
+	const maxEscape = 12
+	if len(rest) > 2 && rest[1] == '\\' {
+		if end := bytes.IndexByte(rest[3:], '\''); end >= 0 && end < maxEscape {
+			return end + 4
+		}
+
+		return 1
+	}
+
+	_, size := utf8.DecodeRune(rest[1:])
+	if len(rest) > size+1 && size > 0 && rest[size+1] == '\'' {
+		return size + 2
+	}
+
+	return 1
+}
+
+// stripComments removes the comments from the content of the file at relPath when its language
+// is stripped, noting how many were removed.
+func (t *textTransform) stripComments(relPath, content string, notes map[string]string) string {
+	stripper := t.newCommentStripper(relPath)
+	if stripper == nil {
+		return content
+	}
+
+	content = stripper.content(content)
+	if stripper.count > 0 {
+		notes[shared.MetadataKeyCommentsStripped] = strconv.Itoa(stripper.count)
+	}
+
+	return content
+}
+
+// newCommentStripper returns a stripper for the file at relPath, or nil when its comments are kept.
+func (t *textTransform) newCommentStripper(relPath string) *commentStripper {
+	language := t.registry.Language(relPath)
+	if !t.stripLanguages[language] {
+		return nil
+	}
+
+	return newCommentStripper(language)
+}
@@ -0,0 +1,116 @@
+package fileproc_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/fileproc"
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+const goWithComments = "//go:build linux\n\n// Package main does things.\npackage main\n\n/* block\ncomment */\n" +
+	"import \"fmt\" // trailing\n\nfunc main() {\n\turl := \"http://example.com\" // a URL\n" +
+	"\traw := `// not a comment\n/* nor this */`\n\tr := '\"' // quote rune\n" +
+	"\tfmt.Println(url, raw, r) /* inline */ // end\n}\n"
+
+const goStripped = "//go:build linux\n\npackage main\n\nimport \"fmt\"\n\nfunc main() {\n" +
+	"\turl := \"http://example.com\"\n\traw := `// not a comment\n/* nor this */`\n\tr := '\"'\n" +
+	"\tfmt.Println(url, raw, r)\n}\n"
+
+// TestFileProcessorStripComments tests comment stripping across languages, leaving comment
+// markers inside strings and character literals alone.
+func TestFileProcessorStripComments(t *testing.T) {
+	tests := []struct {
+		name      string
+		file      string
+		content   string
+		languages []string
+		want      string
+		stripped  string
+	}{
+		{name: "go", file: "main.go", content: goWithComments, want: goStripped, stripped: "7"},
+		{
+			name: "python",
+			file: "tool.py",
+			content: "#!/usr/bin/env python3\n# comment\ndef f(s='#not', t=\"\"\"\n# inside docstring\n\"\"\"):\n" +
+				"    return s  # trailing\n",
+			want:     "#!/usr/bin/env python3\ndef f(s='#not', t=\"\"\"\n# inside docstring\n\"\"\"):\n    return s\n",
+			stripped: "2",
+		},
+		{
+			name:     "javascript",
+			file:     "app.js",
+			content:  "const tpl = `line ${x} // kept\n// kept too`;\nconst s = 'it\\'s // fine'; /* gone */\n// gone\n",
+			want:     "const tpl = `line ${x} // kept\n// kept too`;\nconst s = 'it\\'s // fine';\n",
+			stripped: "2",
+		},
+		{
+			name:     "rust nested comments and lifetimes",
+			file:     "lib.rs",
+			content:  "fn f<'a>(x: &'a str) -> char { /* outer /* inner */ still */ 'x' }\n// line\n",
+			want:     "fn f<'a>(x: &'a str) -> char {  'x' }\n",
+			stripped: "2",
+		},
+		{
+			name:      "language not selected",
+			file:      "main.go",
+			content:   goWithComments,
+			languages: []string{"python"},
+			want:      goWithComments,
+		},
+		{name: "unsupported language", file: "README.md", content: "# Title\n// text\n", want: "# Title\n// text\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testutil.ResetViperConfig(t, "")
+			if tt.languages != nil {
+				testutil.SetViperKeys(t, map[string]any{shared.ConfigKeyStripCommentsLanguages: tt.languages})
+			}
+			dir := t.TempDir()
+			filePath := testutil.CreateTestFile(t, dir, tt.file, []byte(tt.content))
+
+			processor := fileproc.NewFileProcessor(dir)
+			processor.SetStripComments(true)
+			outCh := make(chan fileproc.WriteRequest, 1)
+			testutil.MustSucceed(t, processor.ProcessWithContext(context.Background(), filePath, outCh), "processing")
+
+			req := <-outCh
+			if !strings.HasSuffix(req.Content, "\n"+tt.file+"\n"+tt.want+"\n") {
+				t.Errorf("content = %q, want it to hold %q", req.Content, tt.want)
+			}
+			if got := req.Metadata[shared.MetadataKeyCommentsStripped]; got != tt.stripped {
+				t.Errorf("%s = %q, want %q", shared.MetadataKeyCommentsStripped, got, tt.stripped)
+			}
+		})
+	}
+}
+
+// TestFileProcessorStripCommentsStreamed tests that streamed files are stripped when
+// stripComments.enabled is set, and left alone otherwise.
+func TestFileProcessorStripCommentsStreamed(t *testing.T) {
+	line := "x := 1 // filler comment\n"
+	dir := t.TempDir()
+	filePath := testutil.CreateTestFile(t, dir, "large.go",
+		[]byte("/* header\n   comment */\n"+strings.Repeat(line, shared.FileProcessingStreamThreshold/len(line)+1)))
+
+	for _, enabled := range []bool{false, true} {
+		testutil.SetViperKeys(t, map[string]any{shared.ConfigKeyStripCommentsEnabled: enabled})
+		outCh := make(chan fileproc.WriteRequest, 1)
+		testutil.MustSucceed(t,
+			fileproc.NewFileProcessor(dir).ProcessWithContext(context.Background(), filePath, outCh), "processing")
+
+		req := <-outCh
+		content, err := io.ReadAll(req.Reader)
+		testutil.MustSucceed(t, err, "reading stream")
+		if kept := strings.Contains(string(content), "comment"); kept == enabled {
+			t.Errorf("enabled=%v: comments kept %v", enabled, kept)
+		}
+		if enabled && !strings.Contains(string(content), "\nx := 1\nx := 1\n") {
+			t.Errorf("stripped code lines missing from %q", content[:min(len(content), 200)])
+		}
+	}
+}
@@ -0,0 +1,193 @@
+// Package fileproc handles file processing, collection, and output formatting.
+package fileproc
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// summaryUnknownLanguage is the language a run summary counts files without a detected language under.
+const summaryUnknownLanguage = "unknown"
+
+// RunSummary describes what a bundle holds. With output.appendRunSummary it is written at the
+// end of the bundle, so the bundle describes itself when shared without the terminal output.
+type RunSummary struct {
+	// RunID identifies the run that wrote the bundle, as in its log lines and final report.
+	RunID string `json:"runId,omitempty" yaml:"runId,omitempty"`
+	// Files counts the file sections; prelude, patch and pull request entries are not files.
+	Files int `json:"files" yaml:"files"`
+	// Languages counts the file sections by detected language.
+	Languages map[string]int `json:"languages,omitempty" yaml:"languages,omitempty"`
+	// Summarized counts the files whose content was replaced by a one-line summary.
+	Summarized int `json:"summarized,omitempty" yaml:"summarized,omitempty"`
+	// Skipped counts the files left out of the bundle by reason, one of the shared.SkipReason* values.
+	Skipped map[string]int64 `json:"skipped,omitempty" yaml:"skipped,omitempty"`
+	// Code totals the code metrics of the file sections with codeMetrics.enabled.
+	Code *CodeSummary `json:"code,omitempty" yaml:"code,omitempty"`
+}
+
+// CodeSummary totals the code metrics of the file sections of a bundle.
+type CodeSummary struct {
+	CodeLines    int `json:"codeLines"    yaml:"codeLines"`
+	CommentLines int `json:"commentLines" yaml:"commentLines"`
+	// Complexity sums the approximate cyclomatic complexity of the files.
+	Complexity int `json:"complexity" yaml:"complexity"`
+	// MostComplex is the path of the most complex file, and MaxComplexity its complexity.
+	MostComplex   string `json:"mostComplex,omitempty"   yaml:"mostComplex,omitempty"`
+	MaxComplexity int    `json:"maxComplexity,omitempty" yaml:"maxComplexity,omitempty"`
+}
+
+// SummaryHook completes a run summary with what the writer cannot see, such as the files left
+// out of the bundle. It is called once every file section has been written.
+type SummaryHook func(summary *RunSummary)
+
+// summaryCloser is a FormatWriter that can end the bundle with a run summary.
+type summaryCloser interface {
+	CloseWithSummary(summary RunSummary) error
+}
+
+// add counts a written entry. A nil summary counts nothing.
+func (s *RunSummary) add(req WriteRequest, registry *FileTypeRegistry) {
+	if s == nil || req.Metadata[shared.MetadataKeyRole] != "" {
+		return
+	}
+
+	s.Files++
+	language := cmp.Or(entryLanguage(req, registry), summaryUnknownLanguage)
+	if s.Languages == nil {
+		s.Languages = make(map[string]int)
+	}
+	s.Languages[language]++
+	if req.Metadata[shared.MetadataKeyOmitted] != "" {
+		s.Summarized++
+	}
+	s.addCode(req)
+}
+
+// addCode adds the code metrics of a file section, when it has them, to the summary. Of files
+// equally complex, the first by path is the most complex, whatever order they were written in.
+func (s *RunSummary) addCode(req WriteRequest) {
+	lines, ok := req.Metadata[shared.MetadataKeyCodeLines]
+	if !ok {
+		return
+	}
+	if s.Code == nil {
+		s.Code = &CodeSummary{}
+	}
+
+	code, _ := strconv.Atoi(lines)
+	comments, _ := strconv.Atoi(req.Metadata[shared.MetadataKeyCommentLines])
+	complexity, _ := strconv.Atoi(req.Metadata[shared.MetadataKeyComplexity])
+	s.Code.CodeLines += code
+	s.Code.CommentLines += comments
+	s.Code.Complexity += complexity
+	if complexity > s.Code.MaxComplexity ||
+		(complexity > 0 && complexity == s.Code.MaxComplexity && req.Path < s.Code.MostComplex) {
+		s.Code.MostComplex, s.Code.MaxComplexity = req.Path, complexity
+	}
+}
+
+// sentence describes the summary in one line, for example
+// "12 files in 2 languages (go 10, markdown 2); 1 summarized; 4 left out (binary 3, size_limit 1)."
+func (s RunSummary) sentence() string {
+	languages := slices.SortedFunc(maps.Keys(s.Languages), func(a, b string) int {
+		return cmp.Or(cmp.Compare(s.Languages[b], s.Languages[a]), cmp.Compare(a, b))
+	})
+	counts := make([]string, 0, len(languages))
+	for _, language := range languages {
+		counts = append(counts, fmt.Sprintf("%s %d", language, s.Languages[language]))
+	}
+	parts := []string{fmt.Sprintf("%d files in %d languages", s.Files, len(languages))}
+	if len(counts) > 0 {
+		parts[0] += " (" + strings.Join(counts, ", ") + ")"
+	}
+	if s.Summarized > 0 {
+		parts = append(parts, fmt.Sprintf("%d summarized", s.Summarized))
+	}
+	if s.Code != nil {
+		parts = append(parts, s.Code.sentence())
+	}
+
+	var leftOut int64
+	reasons := make([]string, 0, len(s.Skipped))
+	for _, reason := range slices.Sorted(maps.Keys(s.Skipped)) {
+		leftOut += s.Skipped[reason]
+		reasons = append(reasons, fmt.Sprintf("%s %d", reason, s.Skipped[reason]))
+	}
+	if leftOut > 0 {
+		parts = append(parts, fmt.Sprintf("%d left out (%s)", leftOut, strings.Join(reasons, ", ")))
+	}
+	if s.RunID != "" {
+		parts = append(parts, "run "+s.RunID)
+	}
+
+	return strings.Join(parts, "; ") + "."
+}
+
+// sentence describes the code metrics, for example "5210 lines of code and 830 of comments,
+// complexity 640 (most complex: cli/flags.go, 95)".
+func (c CodeSummary) sentence() string {
+	sentence := fmt.Sprintf(
+		"%d lines of code and %d of comments, complexity %d", c.CodeLines, c.CommentLines, c.Complexity,
+	)
+	if c.MostComplex != "" {
+		sentence += fmt.Sprintf(" (most complex: %s, %d)", c.MostComplex, c.MaxComplexity)
+	}
+
+	return sentence
+}
+
+// markdown renders the summary as a closing markdown section: a sentence for readers and a
+// JSON block for tools.
+func (s RunSummary) markdown() (string, error) {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return "", shared.WrapError(
+			err, shared.ErrorTypeProcessing, shared.CodeProcessingEncode, "failed to encode run summary",
+		)
+	}
+
+	return fmt.Sprintf("\n## Run summary\n\n%s\n\n```json\n%s\n```\n", s.sentence(), data), nil
+}
+
+// yaml renders the summary as the closing summary key of a YAML bundle.
+func (s RunSummary) yaml() (string, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	err := encoder.Encode(struct {
+		Summary RunSummary `yaml:"summary"`
+	}{s})
+	if err == nil {
+		err = encoder.Close()
+	}
+	if err != nil {
+		return "", shared.WrapError(
+			err, shared.ErrorTypeProcessing, shared.CodeProcessingEncode, "failed to encode run summary",
+		)
+	}
+
+	return buf.String(), nil
+}
+
+// closeFormatWriter closes writer, ending the bundle with summary completed by hook when the
+// run summary is enabled.
+func closeFormatWriter(writer FormatWriter, summary *RunSummary, hook SummaryHook) error {
+	closer, ok := writer.(summaryCloser)
+	if summary == nil || !ok {
+		return writer.Close()
+	}
+	hook(summary)
+
+	return closer.CloseWithSummary(*summary)
+}
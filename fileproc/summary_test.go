@@ -0,0 +1,134 @@
+package fileproc_test
+
+import (
+	"encoding/json"
+	"maps"
+	"os"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/ivuorinen/gibidify/fileproc"
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// writeSummarizedBundle writes a bundle in format with a run summary completed by a hook that
+// adds two binary files left out, and returns the output.
+func writeSummarizedBundle(t *testing.T, format string) string {
+	t.Helper()
+
+	outFile, path := testutil.CreateTempOutputFile(t, "summary_*."+format)
+	entries := []fileproc.WriteRequest{
+		{
+			Path:     "README.md",
+			Content:  "# Prelude",
+			Metadata: map[string]string{shared.MetadataKeyRole: shared.MetadataRolePrelude},
+		},
+		{Path: "main.go", Content: "package main"},
+		{Path: "util.go", IsStream: true, Reader: strings.NewReader("package main\n")},
+		{Path: "app.min.js", Content: "[minified]", Metadata: map[string]string{shared.MetadataKeyOmitted: "minified"}},
+		{Path: "NOTES", Content: "notes"},
+	}
+	writeCh := make(chan fileproc.WriteRequest, len(entries))
+	for _, entry := range entries {
+		writeCh <- entry
+	}
+	close(writeCh)
+
+	done := make(chan struct{})
+	opts := fileproc.WriterOptions{RunID: "20261015T093012-9f3a61c2", Summary: func(summary *fileproc.RunSummary) {
+		summary.Skipped = map[string]int64{shared.SkipReasonBinary: 2}
+	}}
+	fileproc.StartWriterWithOptions(outFile, writeCh, done, format, "Start", "End", opts)
+	<-done
+	testutil.CloseFile(t, outFile)
+
+	data, err := os.ReadFile(path) // #nosec G304 -- path is in t.TempDir()
+	testutil.MustSucceed(t, err, "reading output")
+
+	return string(data)
+}
+
+// wantRunSummary is the summary of the bundle writeSummarizedBundle writes.
+var wantRunSummary = fileproc.RunSummary{
+	RunID:      "20261015T093012-9f3a61c2",
+	Files:      4,
+	Languages:  map[string]int{"go": 2, "javascript": 1, "unknown": 1},
+	Summarized: 1,
+	Skipped:    map[string]int64{shared.SkipReasonBinary: 2},
+}
+
+func TestRunSummaryStructured(t *testing.T) {
+	for _, format := range []string{shared.FormatJSON, shared.FormatYAML} {
+		t.Run(format, func(t *testing.T) {
+			output := writeSummarizedBundle(t, format)
+
+			var bundle fileproc.OutputData
+			var err error
+			if format == shared.FormatJSON {
+				err = json.Unmarshal([]byte(output), &bundle)
+			} else {
+				err = yaml.Unmarshal([]byte(output), &bundle)
+			}
+			testutil.MustSucceed(t, err, "decoding bundle")
+
+			if len(bundle.Files) != 5 || bundle.Suffix != "End" {
+				t.Errorf("bundle has %d files and suffix %q, want 5 and End", len(bundle.Files), bundle.Suffix)
+			}
+			if bundle.Summary == nil {
+				t.Fatalf("bundle has no summary:\n%s", output)
+			}
+			verifyRunSummary(t, *bundle.Summary)
+		})
+	}
+}
+
+func TestRunSummaryMarkdown(t *testing.T) {
+	output := writeSummarizedBundle(t, shared.FormatMarkdown)
+
+	_, section, found := strings.Cut(output, "\n# End\n\n## Run summary\n\n")
+	if !found {
+		t.Fatalf("no run summary after the suffix:\n%s", output)
+	}
+	sentence := "4 files in 3 languages (go 2, javascript 1, unknown 1); 1 summarized; 2 left out (binary 2); " +
+		"run 20261015T093012-9f3a61c2.\n"
+	if !strings.HasPrefix(section, sentence) {
+		t.Errorf("summary section = %q, want it to start with %q", section, sentence)
+	}
+
+	block := strings.TrimSuffix(strings.TrimPrefix(section, sentence+"\n```json\n"), "\n```\n")
+	var summary fileproc.RunSummary
+	testutil.MustSucceed(t, json.Unmarshal([]byte(block), &summary), "decoding summary block")
+	verifyRunSummary(t, summary)
+}
+
+func TestRunSummaryDisabled(t *testing.T) {
+	outFile, path := testutil.CreateTempOutputFile(t, "summary_*.json")
+	writeCh := make(chan fileproc.WriteRequest, 1)
+	writeCh <- fileproc.WriteRequest{Path: "main.go", Content: "package main"}
+	close(writeCh)
+
+	done := make(chan struct{})
+	fileproc.StartWriterWithOptions(outFile, writeCh, done, shared.FormatJSON, "", "", fileproc.WriterOptions{})
+	<-done
+	testutil.CloseFile(t, outFile)
+
+	data, err := os.ReadFile(path) // #nosec G304 -- path is in t.TempDir()
+	testutil.MustSucceed(t, err, "reading output")
+	if strings.Contains(string(data), "summary") {
+		t.Errorf("output without a summary hook has a summary: %s", data)
+	}
+}
+
+// verifyRunSummary checks got against wantRunSummary.
+func verifyRunSummary(t *testing.T, got fileproc.RunSummary) {
+	t.Helper()
+
+	if got.RunID != wantRunSummary.RunID || got.Files != wantRunSummary.Files ||
+		got.Summarized != wantRunSummary.Summarized ||
+		!maps.Equal(got.Languages, wantRunSummary.Languages) || !maps.Equal(got.Skipped, wantRunSummary.Skipped) {
+		t.Errorf("summary = %+v, want %+v", got, wantRunSummary)
+	}
+}
@@ -0,0 +1,103 @@
+// Package fileproc handles file processing, collection, and output formatting.
+package fileproc
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// testNaming is a test file naming convention: a test file is named prefix + stem + suffix and
+// tests the file named stem + subjectSuffix.
+type testNaming struct {
+	prefix        string
+	suffix        string
+	subjectSuffix string
+}
+
+// testNamings lists the test file naming conventions of the supported languages.
+var testNamings = []testNaming{
+	{suffix: "_test.go", subjectSuffix: ".go"},
+	{prefix: "test_", suffix: ".py", subjectSuffix: ".py"},
+	{suffix: "_test.py", subjectSuffix: ".py"},
+	{suffix: ".test.js", subjectSuffix: ".js"},
+	{suffix: ".spec.js", subjectSuffix: ".js"},
+	{suffix: ".test.jsx", subjectSuffix: ".jsx"},
+	{suffix: ".spec.jsx", subjectSuffix: ".jsx"},
+	{suffix: ".test.ts", subjectSuffix: ".ts"},
+	{suffix: ".spec.ts", subjectSuffix: ".ts"},
+	{suffix: ".test.tsx", subjectSuffix: ".tsx"},
+	{suffix: ".spec.tsx", subjectSuffix: ".tsx"},
+	{suffix: "_spec.rb", subjectSuffix: ".rb"},
+	{suffix: "_test.rb", subjectSuffix: ".rb"},
+	{suffix: "Test.java", subjectSuffix: ".java"},
+	{suffix: "Tests.java", subjectSuffix: ".java"},
+	{suffix: "Test.kt", subjectSuffix: ".kt"},
+	{suffix: "Test.php", subjectSuffix: ".php"},
+	{suffix: "Tests.cs", subjectSuffix: ".cs"},
+}
+
+// testDirectories are directory names test files are kept in apart from their subjects.
+var testDirectories = map[string]bool{"test": true, "tests": true, "__tests__": true, "spec": true}
+
+// IsTestFile reports whether the file at path is named like a test file of its language,
+// such as foo_test.go, foo.spec.ts or test_foo.py.
+func IsTestFile(path string) bool {
+	return testSubjectName(filepath.Base(path)) != ""
+}
+
+// testSubjectName returns the name of the file the test file named name tests, or "" when
+// name is not a test file name.
+func testSubjectName(name string) string {
+	for _, naming := range testNamings {
+		if len(name) > len(naming.prefix)+len(naming.suffix) &&
+			strings.HasPrefix(name, naming.prefix) && strings.HasSuffix(name, naming.suffix) {
+			return name[len(naming.prefix):len(name)-len(naming.suffix)] + naming.subjectSuffix
+		}
+	}
+
+	return ""
+}
+
+// PairTestFiles maps every test file in files to the file in files it tests: the file named
+// after it in the same directory, else in the parent of a test directory such as tests/, else
+// the only file of that name anywhere. Test files without a subject in files are left out.
+func PairTestFiles(files []string) map[string]string {
+	present := make(map[string]bool, len(files))
+	byName := make(map[string][]string)
+	for _, file := range files {
+		present[file] = true
+		byName[filepath.Base(file)] = append(byName[filepath.Base(file)], file)
+	}
+
+	pairs := make(map[string]string)
+	for _, file := range files {
+		name := testSubjectName(filepath.Base(file))
+		if name == "" {
+			continue
+		}
+		if subject := findTestSubject(file, name, present, byName[name]); subject != "" {
+			pairs[file] = subject
+		}
+	}
+
+	return pairs
+}
+
+// findTestSubject returns the subject named name of the test file at path, or "" when there is
+// no subject or several files could be it.
+func findTestSubject(path, name string, present map[string]bool, sameName []string) string {
+	dir := filepath.Dir(path)
+	if candidate := filepath.Join(dir, name); present[candidate] {
+		return candidate
+	}
+	if testDirectories[filepath.Base(dir)] {
+		if candidate := filepath.Join(filepath.Dir(dir), name); present[candidate] {
+			return candidate
+		}
+	}
+	if len(sameName) == 1 {
+		return sameName[0]
+	}
+
+	return ""
+}
@@ -0,0 +1,55 @@
+package fileproc_test
+
+import (
+	"maps"
+	"path/filepath"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/fileproc"
+)
+
+func TestIsTestFile(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{path: "pkg/walker_test.go", want: true},
+		{path: "src/app.spec.ts", want: true},
+		{path: "src/App.test.jsx", want: true},
+		{path: "tests/test_parser.py", want: true},
+		{path: "lib/user_spec.rb", want: true},
+		{path: "src/UserServiceTest.java", want: true},
+		{path: "pkg/walker.go", want: false},
+		{path: "testdata.go", want: false},
+		{path: "test_.py", want: false},
+		{path: "Test.java", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := fileproc.IsTestFile(filepath.FromSlash(tt.path)); got != tt.want {
+			t.Errorf("IsTestFile(%s) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+// TestPairTestFiles tests that test files are paired with the subject next to them, in the
+// parent of their test directory, or with the only file of the subject's name.
+func TestPairTestFiles(t *testing.T) {
+	abs := func(path string) string { return filepath.FromSlash("/src/" + path) }
+	files := []string{
+		abs("pkg/walker.go"), abs("pkg/walker_test.go"),
+		abs("web/app.ts"), abs("web/__tests__/app.spec.ts"),
+		abs("lib/parser.py"), abs("tests/unit/test_parser.py"),
+		abs("a/util.js"), abs("b/util.js"), abs("c/util.test.js"),
+		abs("pkg/orphan_test.go"),
+	}
+
+	want := map[string]string{
+		abs("pkg/walker_test.go"):        abs("pkg/walker.go"),
+		abs("web/__tests__/app.spec.ts"): abs("web/app.ts"),
+		abs("tests/unit/test_parser.py"): abs("lib/parser.py"),
+	}
+	if got := fileproc.PairTestFiles(files); !maps.Equal(got, want) {
+		t.Errorf("PairTestFiles() = %v, want %v", got, want)
+	}
+}
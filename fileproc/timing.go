@@ -0,0 +1,164 @@
+// Package fileproc handles file processing, collection, and output formatting.
+package fileproc
+
+import (
+	"io"
+	"time"
+
+	"github.com/ivuorinen/gibidify/config"
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// TimingHook receives the time the file at path, of size bytes, spent in a processing phase,
+// one of the shared.MetricsPhase* names. size is 0 when it is not known yet.
+type TimingHook func(path, phase string, size int64, d time.Duration)
+
+// WriterOptions holds the optional features of a writer started with StartWriterWithOptions.
+type WriterOptions struct {
+	// Index records the byte range of every file section when set.
+	Index *BundleIndex
+	// Timing receives the read, format and write time of every file section when set.
+	Timing TimingHook
+	// Stats receives the number of writes and bytes that reached the output file when set.
+	Stats *OutputStats
+	// Registry detects the language of every file section; the default registry when nil.
+	Registry *FileTypeRegistry
+	// Summary ends the bundle with a run summary when set, completed by the hook once every
+	// file section has been written.
+	Summary SummaryHook
+	// Config records these settings at the start of the bundle when not empty: in a comment in
+	// Markdown and YAML, and in a config field in JSON.
+	Config map[string]any
+	// Warning receives the non-fatal issues the writer finds when set, such as Markdown code
+	// fences in file content.
+	Warning WarningHook
+	// Split writes the bundle as part files when set. Index is not supported with it.
+	Split *SplitOptions
+	// Settings is the configuration the writers read; the global configuration when nil.
+	Settings *config.Settings
+	// RunID identifies the run in the run summary and the start event of format plugins when set.
+	RunID string
+}
+
+// registry returns the registry the writers detect languages with.
+func (o WriterOptions) registry() *FileTypeRegistry {
+	if o.Registry != nil {
+		return o.Registry
+	}
+
+	return getRegistry()
+}
+
+// settings returns the configuration the writers read.
+func (o WriterOptions) settings() *config.Settings {
+	if o.Settings != nil {
+		return o.Settings
+	}
+
+	return config.Global()
+}
+
+// outputWriter is the output the format writers write to.
+type outputWriter interface {
+	io.Writer
+	io.StringWriter
+}
+
+// timedWriter measures the time spent writing to the output.
+type timedWriter struct {
+	w       outputWriter
+	elapsed time.Duration
+}
+
+// Write implements io.Writer.
+func (t *timedWriter) Write(p []byte) (int, error) {
+	start := time.Now()
+	n, err := t.w.Write(p)
+	t.elapsed += time.Since(start)
+
+	return n, err //nolint:wrapcheck // the writers wrap output errors
+}
+
+// WriteString implements io.StringWriter.
+func (t *timedWriter) WriteString(s string) (int, error) {
+	start := time.Now()
+	n, err := t.w.WriteString(s)
+	t.elapsed += time.Since(start)
+
+	return n, err //nolint:wrapcheck // the writers wrap output errors
+}
+
+// timedReader measures the time spent reading a streamed file, including its lazy transform.
+type timedReader struct {
+	r       io.Reader
+	elapsed *time.Duration
+}
+
+// Read implements io.Reader.
+func (t timedReader) Read(p []byte) (int, error) {
+	start := time.Now()
+	n, err := t.r.Read(p)
+	*t.elapsed += time.Since(start)
+
+	return n, err //nolint:wrapcheck // EOF must not be wrapped; the writers wrap other errors
+}
+
+// Close closes the underlying reader, so the writers release the streamed file.
+func (t timedReader) Close() error {
+	if closer, ok := t.r.(io.Closer); ok {
+		return closer.Close() //nolint:wrapcheck // the writers log close errors
+	}
+
+	return nil
+}
+
+// sectionTimer splits the time spent writing each file section into the read, format and write phases.
+type sectionTimer struct {
+	hook  TimingHook
+	out   *timedWriter
+	read  time.Duration
+	start time.Time
+	wrote time.Duration
+}
+
+// newSectionTimer returns a timer reporting to hook and the output the format writer should write to.
+// A nil hook times nothing and returns output itself.
+func newSectionTimer(output outputWriter, hook TimingHook) (*sectionTimer, outputWriter) {
+	if hook == nil {
+		return nil, output
+	}
+	out := &timedWriter{w: output}
+
+	return &sectionTimer{hook: hook, out: out}, out
+}
+
+// begin starts timing the section of req, wrapping its stream so reads are measured.
+func (s *sectionTimer) begin(req WriteRequest) WriteRequest {
+	if s == nil {
+		return req
+	}
+
+	s.read = 0
+	s.wrote = s.out.elapsed
+	s.start = time.Now()
+	if req.Reader != nil {
+		req.Reader = timedReader{r: req.Reader, elapsed: &s.read}
+	}
+
+	return req
+}
+
+// end reports the phases of the section started by begin.
+func (s *sectionTimer) end(req WriteRequest) {
+	if s == nil {
+		return
+	}
+
+	total := time.Since(s.start)
+	write := s.out.elapsed - s.wrote
+	if req.Reader != nil {
+		s.hook(req.Path, shared.MetricsPhaseRead, req.Size, s.read)
+	}
+	s.hook(req.Path, shared.MetricsPhaseFormat, req.Size, req.renderTime+max(total-s.read-write, 0))
+	s.hook(req.Path, shared.MetricsPhaseWrite, req.Size, write)
+}
@@ -0,0 +1,44 @@
+// Package fileproc handles file processing, collection, and output formatting.
+package fileproc
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/ivuorinen/gibidify/config"
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// tokenMethodDefault names the uncalibrated bytes-per-token ratio in token estimation methods.
+const tokenMethodDefault = "default"
+
+// EstimateTokens estimates the LLM tokens in size bytes of content in language, "" for mixed
+// content, with the tokens.languages calibration of the language or tokens.bytesPerToken. It
+// also describes the method, for example "bytes/3.3 (go)".
+func EstimateTokens(size int64, language string) (int64, string) {
+	return estimateTokens(config.Global(), size, language)
+}
+
+// estimateTokens works like EstimateTokens with the calibration in settings.
+func estimateTokens(settings *config.Settings, size int64, language string) (int64, string) {
+	ratio := settings.TokensBytesPerToken(language)
+	calibration := tokenMethodDefault
+	if settings.TokensCalibrated(language) {
+		calibration = language
+	}
+	method := "bytes/" + strconv.FormatFloat(ratio, 'g', -1, 64) + " (" + calibration + ")"
+
+	return int64(math.Ceil(float64(size) / ratio)), method
+}
+
+// tokenNotes adds the estimated tokens of size bytes of the file at relPath, and the method, to
+// notes when tokens.estimate is set.
+func (t *textTransform) tokenNotes(notes map[string]string, relPath string, size int64) {
+	if !t.tokens {
+		return
+	}
+
+	tokens, method := estimateTokens(t.settings, size, t.registry.Language(relPath))
+	notes[shared.MetadataKeyTokens] = strconv.FormatInt(tokens, 10)
+	notes[shared.MetadataKeyTokenMethod] = method
+}
@@ -0,0 +1,92 @@
+package fileproc_test
+
+import (
+	"context"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/fileproc"
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     map[string]any
+		size       int64
+		language   string
+		wantTokens int64
+		wantMethod string
+	}{
+		{name: "calibrated code", size: 330, language: "go", wantTokens: 100, wantMethod: "bytes/3.3 (go)"},
+		{name: "calibrated prose", size: 421, language: "markdown", wantTokens: 101, wantMethod: "bytes/4.2 (markdown)"},
+		{name: "uncalibrated", size: 400, language: "brainfuck", wantTokens: 100, wantMethod: "bytes/4 (default)"},
+		{name: "mixed content", size: 0, wantTokens: 0, wantMethod: "bytes/4 (default)"},
+		{
+			name:       "configured calibration",
+			config:     map[string]any{shared.ConfigKeyTokensLanguages + ".go": 2.5},
+			size:       100,
+			language:   "go",
+			wantTokens: 40,
+			wantMethod: "bytes/2.5 (go)",
+		},
+		{
+			name:       "configured default",
+			config:     map[string]any{shared.ConfigKeyTokensBytesPerToken: 5},
+			size:       100,
+			language:   "",
+			wantTokens: 20,
+			wantMethod: "bytes/5 (default)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testutil.SetViperKeys(t, tt.config)
+			tokens, method := fileproc.EstimateTokens(tt.size, tt.language)
+			if tokens != tt.wantTokens || method != tt.wantMethod {
+				t.Errorf("EstimateTokens(%d, %q) = %d, %q, want %d, %q",
+					tt.size, tt.language, tokens, method, tt.wantTokens, tt.wantMethod)
+			}
+		})
+	}
+}
+
+// TestFileProcessorTokenNotes tests that in-memory and streamed files get their token estimate
+// and its method as metadata when tokens.estimate is set.
+func TestFileProcessorTokenNotes(t *testing.T) {
+	line := "// filler line\n"
+	large := strings.Repeat(line, shared.FileProcessingStreamThreshold/len(line)+1)
+	dir := t.TempDir()
+	small := testutil.CreateTestFile(t, dir, "small.go", []byte(strings.Repeat("x", 33)))
+	big := testutil.CreateTestFile(t, dir, "big.md", []byte(large))
+
+	tests := []struct {
+		path       string
+		wantTokens string
+		wantMethod string
+	}{
+		{path: small, wantTokens: "10", wantMethod: "bytes/3.3 (go)"},
+		{path: big, wantTokens: strconv.Itoa(int(math.Ceil(float64(len(large)) / 4.2))), wantMethod: "bytes/4.2 (markdown)"},
+	}
+
+	testutil.SetViperKeys(t, map[string]any{shared.ConfigKeyTokensEstimate: true})
+	processor := fileproc.NewFileProcessor(dir)
+	for _, tt := range tests {
+		outCh := make(chan fileproc.WriteRequest, 1)
+		testutil.MustSucceed(t, processor.ProcessWithContext(context.Background(), tt.path, outCh), "processing")
+		req := <-outCh
+		if req.IsStream {
+			_, err := io.Copy(io.Discard, req.Reader)
+			testutil.MustSucceed(t, err, "reading stream")
+		}
+		if req.Metadata[shared.MetadataKeyTokens] != tt.wantTokens ||
+			req.Metadata[shared.MetadataKeyTokenMethod] != tt.wantMethod {
+			t.Errorf("%s metadata = %v, want %s tokens by %s", req.Path, req.Metadata, tt.wantTokens, tt.wantMethod)
+		}
+	}
+}
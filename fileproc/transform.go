@@ -0,0 +1,190 @@
+// Package fileproc handles file processing, collection, and output formatting.
+package fileproc
+
+import (
+	"bufio"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ivuorinen/gibidify/config"
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// textTransform rewrites file content as it is read, identically for in-memory and
+// streamed files, and reports what it changed as per-file metadata notes.
+type textTransform struct {
+	lineEndings string
+	stripBOM    bool
+	invisible   bool
+	onSanitized func()
+	scan        bool
+	onFindings  func(map[string]int)
+	onWarning   WarningHook
+	tokens      bool
+	codeMetrics bool
+	// secrets holds the redactions of redaction.enabled, compiled on first use unless set.
+	secrets    []Redaction
+	redactions []Redaction
+	onRedacted func(map[string]int)
+	// stripLanguages holds the languages comments are stripped from.
+	stripLanguages map[string]bool
+	compact        compactRule
+	registry       *FileTypeRegistry
+	settings       *config.Settings
+}
+
+// newTextTransform creates a transform with the configuration in settings, detecting
+// languages with the default registry.
+func newTextTransform(settings *config.Settings) *textTransform {
+	return &textTransform{
+		lineEndings:    settings.OutputNormalizeLineEndings(),
+		stripBOM:       settings.OutputSanitizeStripBOM(),
+		invisible:      settings.OutputSanitizeInvisibleChars(),
+		scan:           settings.SecurityScanEnabled(),
+		tokens:         settings.TokensEstimate(),
+		codeMetrics:    settings.CodeMetricsEnabled(),
+		stripLanguages: commentStripLanguages(settings),
+		compact:        compactRuleFor(settings, settings.OutputCompactEnabled()),
+		registry:       getRegistry(),
+		settings:       settings,
+	}
+}
+
+// apply transforms the in-memory content of the file at relPath.
+func (t *textTransform) apply(relPath, content string) (string, map[string]string) {
+	content, bom := t.trimBOM(content)
+	if t.onWarning != nil && !utf8.ValidString(content) {
+		t.onWarning(shared.WarningEncoding, relPath, warningMsgEncoding)
+	}
+	counts := CountInvisible([]byte(content))
+	t.recordSanitized(bom, counts)
+	if t.invisible {
+		content = RemoveInvisible(content)
+	}
+
+	notes := t.notes([]byte(content), counts)
+	content = t.stripComments(relPath, content, notes)
+	redactor := t.newRedactor()
+	content = redactContent(content, redactor)
+	t.reportRedactions(redactor)
+	content = whitespaceRuleFor(t.settings, t.registry.Language(relPath)).apply(content)
+	content = t.compactContent(content, notes)
+	content = NormalizeLineEndings(content, t.lineEndings)
+	t.tokenNotes(notes, relPath, int64(len(content)))
+	t.codeNotes(notes, relPath, content)
+	if t.scanning() {
+		if findings := ScanSecurity([]byte(content)); len(findings) > 0 {
+			t.onFindings(findings)
+		}
+	}
+
+	return content, notes
+}
+
+// wrap transforms the streamed content of the file at relPath. The original line-ending
+// style, invisible characters and invalid UTF-8 are detected from the first chunk.
+func (t *textTransform) wrap(relPath string, src io.Reader) (io.Reader, map[string]string) {
+	buffered := bufio.NewReaderSize(src, shared.FileProcessingStreamChunkSize)
+	bom := false
+	if prefix, err := buffered.Peek(len(utf8BOM)); err == nil && t.stripBOM && string(prefix) == utf8BOM {
+		_, _ = buffered.Discard(len(utf8BOM))
+		bom = true
+	}
+
+	sample, _ := buffered.Peek(shared.FileProcessingStreamChunkSize)
+	if t.onWarning != nil && !utf8.Valid(trimPartialRune(sample)) {
+		t.onWarning(shared.WarningEncoding, relPath, warningMsgEncoding)
+	}
+	counts := CountInvisible(sample)
+	notes := t.notes(sample, counts)
+	t.recordSanitized(bom, counts)
+
+	var content io.Reader = buffered
+	if t.invisible {
+		content = newInvisibleReader(buffered)
+	}
+	if stripper := t.newCommentStripper(relPath); stripper != nil {
+		content = newFilterLineReader(content, stripper.line)
+	}
+	content = newRedactReader(content, t.newRedactor(), t.onRedacted)
+	content = newWhitespaceReader(content, whitespaceRuleFor(t.settings, t.registry.Language(relPath)))
+	if compactor := t.compact.newCompactor(); compactor != nil {
+		content = newFilterLineReader(content, compactor.line)
+	}
+	content = newLineEndingReader(content, t.lineEndings)
+	if t.scanning() {
+		content = newSecurityScanReader(content, t.onFindings)
+	}
+
+	return content, notes
+}
+
+// newRedactor returns a redactor applying the secret redactions and then the mandatory ones to
+// one file, or nil when there are none.
+func (t *textTransform) newRedactor() *redactor {
+	if t.secrets == nil {
+		t.secrets = SecretRedactions(t.settings)
+	}
+
+	return newRedactor(slices.Concat(t.secrets, t.redactions), len(t.secrets) > 0)
+}
+
+// reportRedactions reports what r redacted, if anything.
+func (t *textTransform) reportRedactions(r *redactor) {
+	if r != nil && len(r.counts) > 0 && t.onRedacted != nil {
+		t.onRedacted(r.counts)
+	}
+}
+
+// scanning reports whether the security scan is enabled and has somewhere to report to.
+func (t *textTransform) scanning() bool {
+	return t.scan && t.onFindings != nil
+}
+
+// trimBOM strips a leading UTF-8 byte order mark when configured.
+func (t *textTransform) trimBOM(content string) (string, bool) {
+	if !t.stripBOM || !strings.HasPrefix(content, utf8BOM) {
+		return content, false
+	}
+
+	return content[len(utf8BOM):], true
+}
+
+// recordSanitized reports a file whose content was changed by BOM stripping or invisible character removal.
+func (t *textTransform) recordSanitized(bom bool, counts InvisibleCounts) {
+	if t.onSanitized != nil && (bom || (t.invisible && counts.Total() > 0)) {
+		t.onSanitized()
+	}
+}
+
+// notes records the original line-ending style of content when normalization changes it,
+// and warns about invisible characters.
+func (t *textTransform) notes(content []byte, counts InvisibleCounts) map[string]string {
+	notes := map[string]string{}
+	if note := counts.note(t.invisible); note != "" {
+		notes[shared.MetadataKeySecurity] = note
+	}
+	if _, ok := lineBreak(t.lineEndings); ok {
+		if style := DetectLineEndings(content); style != "" && style != t.lineEndings {
+			notes[shared.MetadataKeyLineEndings] = style
+		}
+	}
+
+	return notes
+}
+
+// mergeMetadata returns meta with notes added, copying rather than modifying meta.
+func mergeMetadata(meta, notes map[string]string) map[string]string {
+	if len(notes) == 0 {
+		return meta
+	}
+
+	merged := make(map[string]string, len(meta)+len(notes))
+	maps.Copy(merged, meta)
+	maps.Copy(merged, notes)
+
+	return merged
+}
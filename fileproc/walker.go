@@ -2,9 +2,13 @@
 package fileproc
 
 import (
+	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 
+	"github.com/ivuorinen/gibidify/config"
 	"github.com/ivuorinen/gibidify/shared"
 )
 
@@ -14,10 +18,11 @@ type Walker interface {
 }
 
 // ProdWalker implements Walker using a custom directory walker that
-// respects .gitignore and .ignore files, configuration-defined ignore directories,
+// respects .gitignore, .git/info/exclude and .ignore files, configuration-defined ignore directories,
 // and ignores binary and image files by default.
 type ProdWalker struct {
-	filter *FileFilter
+	filter         *FileFilter
+	globalExcludes string
 }
 
 // NewProdWalker creates a new production walker with current configuration.
@@ -27,6 +32,41 @@ func NewProdWalker() *ProdWalker {
 	}
 }
 
+// NewProdWalkerWithRegistry creates a new production walker with current configuration
+// that classifies binary and image files with registry.
+func NewProdWalkerWithRegistry(registry *FileTypeRegistry) *ProdWalker {
+	return &ProdWalker{
+		filter: NewFileFilterWithRegistry(registry),
+	}
+}
+
+// NewProdWalkerWithSettings creates a new production walker with the configuration in settings
+// that classifies binary and image files with registry.
+func NewProdWalkerWithSettings(registry *FileTypeRegistry, settings *config.Settings) *ProdWalker {
+	return &ProdWalker{
+		filter: NewFileFilterWithSettings(registry, settings),
+	}
+}
+
+// SetRespectGitignore sets whether the walk honors the rules git ignores files by: .gitignore
+// files, including those above the root in its repository, and .git/info/exclude. It overrides
+// collection.respectGitignore. .ignore files are always honored.
+func (w *ProdWalker) SetRespectGitignore(respect bool) {
+	w.filter.respectGitignore = respect
+}
+
+// SetGlobalExcludesFile sets the user's global git excludes file (core.excludesFile), whose
+// patterns apply relative to the repository root, or to the walked root outside of a repository.
+// Like the other git rules, it is only honored while gitignore rules are respected.
+func (w *ProdWalker) SetGlobalExcludesFile(path string) {
+	w.globalExcludes = path
+}
+
+// SetSkipHook sets a function called for every file the walk leaves out.
+func (w *ProdWalker) SetSkipHook(hook SkipHook) {
+	w.filter.onSkip = hook
+}
+
 // Walk scans the given root directory recursively and returns a slice of file paths
 // that are not ignored based on .gitignore/.ignore files, the configuration, or the default binary/image filter.
 func (w *ProdWalker) Walk(root string) ([]string, error) {
@@ -40,7 +80,37 @@ func (w *ProdWalker) Walk(root string) ([]string, error) {
 		).WithFilePath(root)
 	}
 
-	return w.walkDir(absRoot, []ignoreRule{})
+	var rules []ignoreRule
+	if w.filter.respectGitignore {
+		rules = gitRepoRules(absRoot, w.globalExcludes)
+	}
+
+	return w.walkDir(absRoot, rules)
+}
+
+// skipReason returns why a walk of absRoot leaves out the file at fullPath, described by info:
+// one of the shared.SkipReason* constants, or "" when the walk collects it. It applies the same
+// ignored directories, ignore files and filters as the walk, for files found some other way.
+func (w *ProdWalker) skipReason(absRoot, fullPath string, info os.FileInfo) string {
+	var rules []ignoreRule
+	if w.filter.respectGitignore {
+		rules = gitRepoRules(absRoot, w.globalExcludes)
+	}
+
+	dir := absRoot
+	rules = loadIgnoreRules(dir, rules, w.filter.ignoreFileNames())
+	rel, err := filepath.Rel(absRoot, filepath.Dir(fullPath))
+	if err == nil && rel != "." {
+		for _, name := range strings.Split(rel, string(filepath.Separator)) {
+			if slices.Contains(w.filter.ignoredDirs, name) {
+				return shared.SkipReasonIgnored
+			}
+			dir = filepath.Join(dir, name)
+			rules = loadIgnoreRules(dir, rules, w.filter.ignoreFileNames())
+		}
+	}
+
+	return w.filter.fileSkipReason(fs.FileInfoToDirEntry(info), fullPath, rules)
 }
 
 // walkDir recursively walks the directory tree starting at currentDir.
@@ -61,7 +131,7 @@ func (w *ProdWalker) walkDir(currentDir string, parentRules []ignoreRule) ([]str
 		).WithFilePath(currentDir)
 	}
 
-	rules := loadIgnoreRules(currentDir, parentRules)
+	rules := loadIgnoreRules(currentDir, parentRules, w.filter.ignoreFileNames())
 
 	for _, entry := range entries {
 		fullPath := filepath.Join(currentDir, entry.Name())
@@ -1,12 +1,15 @@
 package fileproc_test
 
 import (
+	"maps"
 	"path/filepath"
+	"slices"
 	"testing"
 
 	"github.com/spf13/viper"
 
 	"github.com/ivuorinen/gibidify/fileproc"
+	"github.com/ivuorinen/gibidify/shared"
 	"github.com/ivuorinen/gibidify/testutil"
 )
 
@@ -101,3 +104,132 @@ func TestProdWalkerSizeLimit(t *testing.T) {
 		t.Errorf("Expected smallfile.go, got %s", found[0])
 	}
 }
+
+func TestProdWalkerSkipHook(t *testing.T) {
+	rootDir := t.TempDir()
+	testutil.CreateTestFiles(t, rootDir, []testutil.FileSpec{
+		{Name: "keep.go", Content: "package main"},
+		{Name: "tool.exe", Content: "binary"},
+		{Name: "notes.txt", Content: "ignored"},
+		{Name: ".gitignore", Content: "*.txt\n"},
+	})
+	testutil.CreateTestFile(t, rootDir, "large.go", make([]byte, 2048))
+	testutil.CreateTestFile(t, testutil.CreateTestDirectory(t, rootDir, "vendor"), "dep.go", []byte("package dep"))
+
+	testutil.ResetViperConfig(t, "")
+	viper.Set(shared.ConfigKeyFileSizeLimit, 1024)
+	viper.Set("ignoreDirectories", []string{"vendor"})
+
+	w := fileproc.NewProdWalkerWithRegistry(fileproc.NewFileTypeRegistry())
+	skipped := map[string]string{}
+	var skippedBytes int64
+	w.SetSkipHook(func(path, reason string, size int64) {
+		skipped[filepath.Base(path)] = reason
+		skippedBytes += size
+	})
+	found, err := w.Walk(rootDir)
+	testutil.MustSucceed(t, err, "walking directory")
+
+	want := map[string]string{
+		"tool.exe":  shared.SkipReasonBinary,
+		"notes.txt": shared.SkipReasonIgnored,
+		"large.go":  shared.SkipReasonSizeLimit,
+	}
+	if len(found) != 2 || !maps.Equal(skipped, want) {
+		t.Errorf("found %v, skipped %v, want .gitignore and keep.go found and %v skipped", found, skipped, want)
+	}
+	if wantBytes := int64(len("binary") + len("ignored") + 2048); skippedBytes != wantBytes {
+		t.Errorf("skipped %d bytes, want %d", skippedBytes, wantBytes)
+	}
+}
+
+// TestProdWalkerGitignore tests that walking a directory inside a git repository honors the
+// repository's .git/info/exclude and the .gitignore files above the directory, and that
+// turning gitignore support off keeps only the .ignore rules.
+func TestProdWalkerGitignore(t *testing.T) {
+	repo := t.TempDir()
+	info := testutil.CreateTestDirectory(t, testutil.CreateTestDirectory(t, repo, ".git"), "info")
+	testutil.CreateTestFile(t, info, "exclude", []byte("secrets.env\n"))
+	testutil.CreateTestFile(t, repo, ".gitignore", []byte("*.log\n.gitignore\n"))
+	src := testutil.CreateTestDirectory(t, repo, "src")
+	testutil.CreateTestFiles(t, src, []testutil.FileSpec{
+		{Name: "main.go", Content: "package main"},
+		{Name: "debug.log", Content: "log"},
+		{Name: "secrets.env", Content: "TOKEN=x"},
+		{Name: "scratch.tmp", Content: "tmp"},
+		{Name: ".ignore", Content: "*.tmp\n.ignore\n"},
+	})
+
+	tests := []struct {
+		name    string
+		respect bool
+		want    []string
+	}{
+		{name: "respected", respect: true, want: []string{"main.go"}},
+		{name: "not respected", respect: false, want: []string{"debug.log", "main.go", "secrets.env"}},
+	}
+
+	testutil.ResetViperConfig(t, "")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := fileproc.NewProdWalkerWithRegistry(fileproc.NewFileTypeRegistry())
+			w.SetRespectGitignore(tt.respect)
+			found, err := w.Walk(src)
+			testutil.MustSucceed(t, err, "walking directory")
+
+			var names []string
+			for _, path := range found {
+				names = append(names, filepath.Base(path))
+			}
+			slices.Sort(names)
+			if !slices.Equal(names, tt.want) {
+				t.Errorf("found %v, want %v", names, tt.want)
+			}
+		})
+	}
+}
+
+// TestProdWalkerGlobalExcludes tests that the global git excludes file applies relative to the
+// walked directory outside of a repository, and only while gitignore rules are respected.
+func TestProdWalkerGlobalExcludes(t *testing.T) {
+	excludes := testutil.CreateTestFile(t, t.TempDir(), "ignore", []byte("*.swp\n.idea/\n"))
+	src := t.TempDir()
+	testutil.CreateTestFiles(t, src, []testutil.FileSpec{
+		{Name: "main.go", Content: "package main"},
+		{Name: "main.go.swp", Content: "swap"},
+	})
+	testutil.CreateTestFile(t, testutil.CreateTestDirectory(t, src, ".idea"), "workspace.xml", []byte("<project/>"))
+
+	tests := []struct {
+		name     string
+		respect  bool
+		excludes string
+		want     []string
+	}{
+		{name: "global excludes", respect: true, excludes: excludes, want: []string{"main.go"}},
+		{name: "missing file", respect: true, excludes: excludes + ".missing",
+			want: []string{"main.go", "main.go.swp", "workspace.xml"}},
+		{name: "gitignore not respected", respect: false, excludes: excludes,
+			want: []string{"main.go", "main.go.swp", "workspace.xml"}},
+	}
+
+	testutil.ResetViperConfig(t, "")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := fileproc.NewProdWalkerWithRegistry(fileproc.NewFileTypeRegistry())
+			w.SetRespectGitignore(tt.respect)
+			w.SetGlobalExcludesFile(tt.excludes)
+			found, err := w.Walk(src)
+			testutil.MustSucceed(t, err, "walking directory")
+
+			var names []string
+			for _, path := range found {
+				names = append(names, filepath.Base(path))
+			}
+			slices.Sort(names)
+			if !slices.Equal(names, tt.want) {
+				t.Errorf("found %v, want %v", names, tt.want)
+			}
+		})
+	}
+}
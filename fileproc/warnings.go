@@ -0,0 +1,93 @@
+// Package fileproc handles file processing, collection, and output formatting.
+package fileproc
+
+import (
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// markdownFence opens and closes the code block around a file section of a Markdown bundle whose
+// content has no lines starting with a fence of its own.
+const markdownFence = "```"
+
+// maxFenceIndent is the indentation up to which a line starting with backticks is a code fence.
+const maxFenceIndent = 3
+
+// Warning messages of the shared.Warning* kinds the processor and the writers report.
+const (
+	warningMsgEncoding       = "not valid UTF-8; JSON and YAML bundles replace the invalid bytes with U+FFFD"
+	warningMsgFenceCollision = "has a code fence past its first chunk that ends the Markdown code block early"
+)
+
+// WarningHook receives a non-fatal issue with the file at path, one of the shared.Warning*
+// kinds. It is called from the worker and writer goroutines, so it must be safe for concurrent use.
+type WarningHook func(kind, path, message string)
+
+// trimPartialRune strips an incomplete character from the end of content, such as a streamed
+// sample cut inside a character.
+func trimPartialRune(content []byte) []byte {
+	for i := 1; i < utf8.UTFMax && i <= len(content); i++ {
+		if !utf8.RuneStart(content[len(content)-i]) {
+			continue
+		}
+		if !utf8.FullRune(content[len(content)-i:]) {
+			return content[:len(content)-i]
+		}
+
+		break
+	}
+
+	return content
+}
+
+// fenceRun returns the length of the run of backticks starting line after at most three spaces of
+// indentation, the way CommonMark recognizes code fences, or 0 when it does not start with one.
+func fenceRun(line string) int {
+	trimmed := strings.TrimLeft(line, " ")
+	if len(line)-len(trimmed) > maxFenceIndent {
+		return 0
+	}
+	run := len(trimmed) - len(strings.TrimLeft(trimmed, "`"))
+	if run < len(markdownFence) {
+		return 0
+	}
+
+	return run
+}
+
+// markdownFenceFor returns the code fence of a section holding content: three backticks, or one
+// more than the longest fence starting a line of content, so no line of it ends the code block.
+func markdownFenceFor(content string) string {
+	longest := 0
+	for line := range strings.Lines(content) {
+		longest = max(longest, fenceRun(line))
+	}
+	if longest == 0 {
+		return markdownFence
+	}
+
+	return strings.Repeat("`", longest+1)
+}
+
+// fenceWarningReader reports the first line of a streamed Markdown section that starts with a
+// code fence at least as long as fence, passing the content through unchanged. Such lines only
+// occur past the start of the content the fence was chosen from.
+func fenceWarningReader(src io.Reader, hook WarningHook, path, fence string) io.Reader {
+	if hook == nil {
+		return src
+	}
+
+	warned := false
+
+	return newLineReader(src, func(line []byte) []byte {
+		if !warned && fenceRun(string(line)) >= len(fence) {
+			warned = true
+			hook(shared.WarningFenceCollision, path, warningMsgFenceCollision)
+		}
+
+		return line
+	})
+}
@@ -0,0 +1,159 @@
+package fileproc_test
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/fileproc"
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// warningRecorder collects the warnings passed to its hook.
+type warningRecorder struct {
+	mu    sync.Mutex
+	kinds map[string][]string
+}
+
+// hook records a warning of kind for path.
+func (r *warningRecorder) hook(kind, path, _ string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.kinds == nil {
+		r.kinds = make(map[string][]string)
+	}
+	r.kinds[kind] = append(r.kinds[kind], path)
+}
+
+// TestFileProcessorEncodingWarning tests that in-memory and streamed files that are not valid
+// UTF-8 are warned about, and that a streamed sample cut inside a character is not.
+func TestFileProcessorEncodingWarning(t *testing.T) {
+	testutil.ResetViperConfig(t, "")
+	line := "// café filler line\n"
+	lines := strings.Repeat(line, shared.FileProcessingStreamThreshold/len(line)+1)
+	// The streamed sample ends on the first byte of the "é" after cut.txt's padding
+	padding := strings.Repeat("x\n", (shared.FileProcessingStreamChunkSize-1)/2) + "x"
+	dir := t.TempDir()
+	files := map[string]string{
+		"latin1.txt": "caf\xe9\n",
+		"utf8.txt":   line,
+		"large.txt":  "\xff" + lines,
+		"cut.txt":    padding + "é" + lines,
+	}
+	for name, content := range files {
+		testutil.CreateTestFile(t, dir, name, []byte(content))
+	}
+
+	var recorder warningRecorder
+	processor := fileproc.NewFileProcessor(dir)
+	processor.SetWarningHook(recorder.hook)
+	for name := range files {
+		outCh := make(chan fileproc.WriteRequest, 1)
+		path := filepath.Join(dir, name)
+		testutil.MustSucceed(t, processor.ProcessWithContext(context.Background(), path, outCh), "processing "+name)
+		if req := <-outCh; req.IsStream {
+			_, err := io.Copy(io.Discard, req.Reader)
+			testutil.MustSucceed(t, err, "reading stream")
+		}
+	}
+
+	got := recorder.kinds[shared.WarningEncoding]
+	if len(got) != 2 || !strings.Contains(strings.Join(got, " "), "latin1.txt") ||
+		!strings.Contains(strings.Join(got, " "), "large.txt") {
+		t.Errorf("encoding warnings for %v, want latin1.txt and large.txt", got)
+	}
+}
+
+// TestMarkdownFenceSelection tests that the Markdown writer fences sections with a fence longer
+// than any starting a line of their content, so nested fences stay inside the code block.
+func TestMarkdownFenceSelection(t *testing.T) {
+	tests := []struct {
+		name    string
+		entry   fileproc.WriteRequest
+		want    string
+		wantNot string
+	}{
+		{
+			name:  "no fences",
+			entry: fileproc.WriteRequest{Path: "main.go", Content: "package main // ``` inline is fine"},
+			want:  "```go\npackage main // ``` inline is fine\n```\n",
+		},
+		{
+			name:  "nested fence",
+			entry: fileproc.WriteRequest{Path: "README.md", Content: "Usage:\n```sh\nmake\n```"},
+			want:  "````markdown\nUsage:\n```sh\nmake\n```\n````\n",
+		},
+		{
+			name:  "longer indented fence",
+			entry: fileproc.WriteRequest{Path: "doc.md", Content: "   `````\nfive\n   `````"},
+			want:  "``````markdown\n",
+		},
+		{
+			name:    "indented code is not a fence",
+			entry:   fileproc.WriteRequest{Path: "doc.md", Content: "    ```\nindented\n    ```"},
+			want:    "```markdown\n",
+			wantNot: "````",
+		},
+		{
+			name: "streamed nested fence",
+			entry: fileproc.WriteRequest{
+				Path: "doc.md", IsStream: true, Reader: strings.NewReader("intro\n````\ncode\n````"),
+			},
+			want: "`````markdown\nintro\n````\ncode\n````\n`````\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var recorder warningRecorder
+			got := writeMarkdown(t, recorder.hook, tt.entry)
+			if !strings.Contains(got, tt.want) || (tt.wantNot != "" && strings.Contains(got, tt.wantNot)) {
+				t.Errorf("section = %q, want it to contain %q", got, tt.want)
+			}
+			if warnings := recorder.kinds[shared.WarningFenceCollision]; len(warnings) > 0 {
+				t.Errorf("fence collision warnings for %v, want none", warnings)
+			}
+		})
+	}
+}
+
+// TestMarkdownFenceCollisionWarning tests that the Markdown writer warns once about a streamed
+// file with a fence past the first chunk it chose the fence from.
+func TestMarkdownFenceCollisionWarning(t *testing.T) {
+	padding := strings.Repeat("x\n", shared.FileProcessingStreamChunkSize/2)
+	content := padding + "```\ncode\n```\n"
+
+	var recorder warningRecorder
+	entry := fileproc.WriteRequest{Path: "doc.md", IsStream: true, Reader: strings.NewReader(content)}
+	writeMarkdown(t, recorder.hook, entry)
+
+	if got := recorder.kinds[shared.WarningFenceCollision]; len(got) != 1 || got[0] != "doc.md" {
+		t.Errorf("fence collision warnings for %v, want doc.md once", got)
+	}
+}
+
+// writeMarkdown writes entry to a Markdown bundle, reporting warnings to hook, and returns the bundle.
+func writeMarkdown(t *testing.T, hook fileproc.WarningHook, entry fileproc.WriteRequest) string {
+	t.Helper()
+
+	outFile, path := testutil.CreateTempOutputFile(t, "fence_*.md")
+	writeCh := make(chan fileproc.WriteRequest, 1)
+	writeCh <- entry
+	close(writeCh)
+
+	done := make(chan struct{})
+	opts := fileproc.WriterOptions{Warning: hook}
+	fileproc.StartWriterWithOptions(outFile, writeCh, done, shared.FormatMarkdown, "", "", opts)
+	<-done
+	testutil.CloseFile(t, outFile)
+
+	data, err := os.ReadFile(path) // #nosec G304 -- path is in t.TempDir()
+	testutil.MustSucceed(t, err, "reading bundle")
+
+	return string(data)
+}
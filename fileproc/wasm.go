@@ -0,0 +1,148 @@
+// Package fileproc handles file processing, collection, and output formatting.
+package fileproc
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/ivuorinen/gibidify/config"
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// wasmEngine compiles and runs WASM transform modules. The minimal build has none.
+type wasmEngine interface {
+	// compile compiles the module binary read from path.
+	compile(ctx context.Context, path string, binary []byte) (wasmProgram, error)
+	// close releases the engine and every module it compiled.
+	close(ctx context.Context) error
+}
+
+// wasmProgram is a compiled WASM transform module.
+type wasmProgram interface {
+	// run runs the module on the content of the file at relPath, returning its output and
+	// whether the file stays in the bundle.
+	run(ctx context.Context, relPath string, content []byte) ([]byte, bool, error)
+}
+
+// wasmTransform is a compiled transforms.wasm entry.
+type wasmTransform struct {
+	glob    *Glob
+	module  string
+	program wasmProgram
+}
+
+// WasmTransforms runs the user transforms of transforms.wasm on the files matching their globs.
+// A transform is a WASI command module run in a sandbox without file system or network access,
+// with capped memory and the per-file timeout: it reads the file content on stdin and writes
+// the content to bundle on stdout, or exits with shared.WasmExitLeaveOut to leave the file out.
+// The path of the file relative to the source is its first argument. Transforms run in
+// configuration order, each on the output of the one before.
+type WasmTransforms struct {
+	engine     wasmEngine
+	transforms []wasmTransform
+}
+
+// LoadWasmTransforms compiles the modules of transforms. It returns nil when there are none.
+func LoadWasmTransforms(ctx context.Context, transforms []config.WasmTransform) (*WasmTransforms, error) {
+	if len(transforms) == 0 {
+		return nil, nil
+	}
+
+	engine, err := newWasmEngine(ctx)
+	if err != nil {
+		return nil, err
+	}
+	w := &WasmTransforms{engine: engine}
+	for _, transform := range transforms {
+		if err := w.add(ctx, transform); err != nil {
+			shared.LogError("Error closing WASM runtime", engine.close(ctx))
+
+			return nil, err
+		}
+	}
+
+	return w, nil
+}
+
+// add compiles transform and appends it to the transforms run.
+func (w *WasmTransforms) add(ctx context.Context, transform config.WasmTransform) error {
+	glob, err := CompileGlob(transform.Pattern)
+	if err != nil {
+		return err
+	}
+	binary, err := os.ReadFile(transform.Module)
+	if err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIORead, "failed to read WASM transform").
+			WithFilePath(transform.Module)
+	}
+	program, err := w.engine.compile(ctx, transform.Module, binary)
+	if err != nil {
+		return err
+	}
+	w.transforms = append(w.transforms, wasmTransform{glob: glob, module: transform.Module, program: program})
+
+	return nil
+}
+
+// Applies reports whether a transform runs on the file at relPath, so it must be read in full.
+func (w *WasmTransforms) Applies(relPath string) bool {
+	if w == nil {
+		return false
+	}
+	rel := filepath.ToSlash(relPath)
+	for _, transform := range w.transforms {
+		if transform.glob.Match(rel) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Apply runs the transforms matching relPath on content. It returns the transformed content and
+// the names of the modules that ran, or a CodeValidationTransform error when one left the file
+// out.
+func (w *WasmTransforms) Apply(ctx context.Context, relPath string, content []byte) ([]byte, []string, error) {
+	if w == nil {
+		return content, nil, nil
+	}
+
+	rel := filepath.ToSlash(relPath)
+	var applied []string
+	for _, transform := range w.transforms {
+		if !transform.glob.Match(rel) {
+			continue
+		}
+		output, keep, err := transform.program.run(ctx, rel, content)
+		if err != nil {
+			return nil, nil, shared.WrapError(err, shared.ErrorTypeProcessing, shared.CodeProcessingFileRead,
+				"WASM transform "+transform.module+" failed").WithFilePath(relPath)
+		}
+		name := filepath.Base(transform.module)
+		if !keep {
+			shared.GetLogger().Infof("Skipping file left out by WASM transform %s: %s", name, relPath)
+
+			return nil, nil, shared.NewStructuredError(
+				shared.ErrorTypeValidation,
+				shared.CodeValidationTransform,
+				"left out by WASM transform "+name,
+				relPath,
+				map[string]any{"reason": "transform " + name},
+			)
+		}
+		content = output
+		applied = append(applied, name)
+	}
+
+	return content, applied, nil
+}
+
+// Close releases the compiled modules.
+func (w *WasmTransforms) Close(ctx context.Context) error {
+	if w == nil {
+		return nil
+	}
+
+	return w.engine.close(ctx)
+}
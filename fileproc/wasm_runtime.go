@@ -0,0 +1,102 @@
+//go:build !minimal
+
+// Package fileproc handles file processing, collection, and output formatting.
+package fileproc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/sys"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// wazeroEngine runs WASM transforms with the wazero runtime.
+type wazeroEngine struct {
+	runtime wazero.Runtime
+}
+
+// wazeroProgram is a WASM transform compiled by wazero, instantiated afresh for every file.
+type wazeroProgram struct {
+	runtime  wazero.Runtime
+	compiled wazero.CompiledModule
+	name     string
+}
+
+// newWasmEngine creates a wazero runtime with WASI, capping module memory and stopping modules
+// once their context is done.
+func newWasmEngine(ctx context.Context) (wasmEngine, error) {
+	runtime := wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfig().
+		WithMemoryLimitPages(shared.WasmMemoryLimitPages).
+		WithCloseOnContextDone(true))
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, runtime); err != nil {
+		return nil, shared.WrapError(err, shared.ErrorTypeProcessing, shared.CodeProcessingFileRead,
+			"failed to set up WASI for WASM transforms")
+	}
+
+	return &wazeroEngine{runtime: runtime}, nil
+}
+
+// WasmAvailable reports whether WASM transforms are built in.
+func WasmAvailable() bool {
+	return true
+}
+
+// compile compiles a WASM transform module.
+func (e *wazeroEngine) compile(ctx context.Context, path string, binary []byte) (wasmProgram, error) {
+	compiled, err := e.runtime.CompileModule(ctx, binary)
+	if err != nil {
+		return nil, shared.WrapError(err, shared.ErrorTypeValidation, shared.CodeValidationFormat,
+			"failed to compile WASM transform").WithFilePath(path)
+	}
+
+	return &wazeroProgram{runtime: e.runtime, compiled: compiled, name: path}, nil
+}
+
+// close closes the runtime and every module compiled with it.
+func (e *wazeroEngine) close(ctx context.Context) error {
+	if err := e.runtime.Close(ctx); err != nil {
+		return shared.WrapError(err, shared.ErrorTypeProcessing, shared.CodeProcessingFileRead,
+			"failed to close WASM runtime")
+	}
+
+	return nil
+}
+
+// run instantiates the module with content on stdin, which runs its _start function, and
+// returns what it wrote to stdout. Exiting with shared.WasmExitLeaveOut leaves the file out;
+// any other failure is an error carrying what the module wrote to stderr.
+func (p *wazeroProgram) run(ctx context.Context, relPath string, content []byte) ([]byte, bool, error) {
+	var stdout, stderr bytes.Buffer
+	moduleConfig := wazero.NewModuleConfig().
+		WithName("").
+		WithArgs(p.name, relPath).
+		WithStdin(bytes.NewReader(content)).
+		WithStdout(&stdout).
+		WithStderr(&stderr)
+
+	module, err := p.runtime.InstantiateModule(ctx, p.compiled, moduleConfig)
+	if module != nil {
+		shared.LogError("Error closing WASM transform", module.Close(ctx))
+	}
+
+	var exitErr *sys.ExitError
+	switch {
+	case err == nil:
+		return stdout.Bytes(), true, nil
+	case errors.As(err, &exitErr) && exitErr.ExitCode() == 0:
+		return stdout.Bytes(), true, nil
+	case errors.As(err, &exitErr) && exitErr.ExitCode() == shared.WasmExitLeaveOut:
+		return nil, false, nil
+	}
+	if message := strings.TrimSpace(stderr.String()); message != "" {
+		return nil, false, errors.Join(err, errors.New(message))
+	}
+
+	return nil, false, err
+}
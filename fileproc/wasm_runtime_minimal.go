@@ -0,0 +1,23 @@
+//go:build minimal
+
+// Package fileproc handles file processing, collection, and output formatting.
+package fileproc
+
+import (
+	"context"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// newWasmEngine always fails in the minimal build, which leaves WASM transforms out.
+func newWasmEngine(_ context.Context) (wasmEngine, error) {
+	return nil, shared.NewStructuredError(
+		shared.ErrorTypeConfiguration, shared.CodeConfigValidation,
+		"transforms.wasm is set, but this binary is built without WASM transform support", "", nil,
+	)
+}
+
+// WasmAvailable reports whether WASM transforms are built in.
+func WasmAvailable() bool {
+	return false
+}
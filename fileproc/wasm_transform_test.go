@@ -0,0 +1,239 @@
+//go:build !minimal
+
+package fileproc_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/config"
+	"github.com/ivuorinen/gibidify/fileproc"
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// WASM instructions used by the test modules.
+const (
+	wasmBlock       = 0x02
+	wasmLoop        = 0x03
+	wasmEmptyType   = 0x40
+	wasmBr          = 0x0C
+	wasmBrIf        = 0x0D
+	wasmCall        = 0x10
+	wasmDrop        = 0x1A
+	wasmI32Load     = 0x28
+	wasmI32Store    = 0x36
+	wasmI32Const    = 0x41
+	wasmI32Eqz      = 0x45
+	wasmEnd         = 0x0B
+	wasmUnreachable = 0x00
+)
+
+// Imported WASI functions, by function index.
+const (
+	wasiFdRead   = 0
+	wasiFdWrite  = 1
+	wasiProcExit = 2
+)
+
+// wasmPrefixBody writes the prefix stored in the data segment, then copies stdin to stdout.
+var wasmPrefixBody = []byte{
+	// fd_write(stdout, iovec 0, 1, nwritten 8)
+	wasmI32Const, 1, wasmI32Const, 0, wasmI32Const, 1, wasmI32Const, 8, wasmCall, wasiFdWrite, wasmDrop,
+	wasmBlock, wasmEmptyType, wasmLoop, wasmEmptyType,
+	// fd_read(stdin, iovec 16, 1, nread 24); stop at end of input
+	wasmI32Const, 0, wasmI32Const, 16, wasmI32Const, 1, wasmI32Const, 24, wasmCall, wasiFdRead, wasmDrop,
+	wasmI32Const, 24, wasmI32Load, 2, 0, wasmI32Eqz, wasmBrIf, 1,
+	// fd_write(stdout, iovec 32 holding the nread bytes read, 1, nwritten 40)
+	wasmI32Const, 36, wasmI32Const, 24, wasmI32Load, 2, 0, wasmI32Store, 2, 0,
+	wasmI32Const, 1, wasmI32Const, 32, wasmI32Const, 1, wasmI32Const, 40, wasmCall, wasiFdWrite, wasmDrop,
+	wasmBr, 0,
+	wasmEnd, wasmEnd, wasmEnd,
+}
+
+// wasmLeaveOutBody exits with shared.WasmExitLeaveOut.
+var wasmLeaveOutBody = []byte{wasmI32Const, shared.WasmExitLeaveOut, wasmCall, wasiProcExit, wasmEnd}
+
+// wasmTrapBody traps.
+var wasmTrapBody = []byte{wasmUnreachable, wasmEnd}
+
+// wasmModule encodes a WASI command module whose _start function runs body. Its memory starts
+// with the iovecs body uses: the prefix at 64 for stdout, and 60000 bytes at 1024 for stdin.
+func wasmModule(body []byte, prefix string) []byte {
+	data := make([]byte, 64, 64+len(prefix))
+	copy(data[0:], []byte{64, 0, 0, 0, byte(len(prefix)), 0, 0, 0})
+	copy(data[16:], []byte{0, 4, 0, 0, 0x60, 0xEA, 0, 0})
+	copy(data[32:], []byte{0, 4, 0, 0})
+	data = append(data, prefix...)
+
+	name := func(s string) []byte { return append([]byte{byte(len(s))}, s...) }
+	imports := []byte{3}
+	for _, function := range []struct {
+		name      string
+		typeIndex byte
+	}{{"fd_read", 0}, {"fd_write", 0}, {"proc_exit", 1}} {
+		imports = append(imports, name("wasi_snapshot_preview1")...)
+		imports = append(imports, name(function.name)...)
+		imports = append(imports, 0x00, function.typeIndex)
+	}
+	exports := append(append([]byte{2}, name("memory")...), 0x02, 0)
+	exports = append(append(exports, name("_start")...), 0x00, 3)
+	code := append(wasmLEB(len(body)+1), 0)
+	code = append([]byte{1}, append(code, body...)...)
+	segment := append([]byte{1, 0, wasmI32Const, 0, wasmEnd}, wasmLEB(len(data))...)
+
+	module := []byte{0x00, 'a', 's', 'm', 1, 0, 0, 0}
+	for _, section := range []struct {
+		id      byte
+		content []byte
+	}{
+		{1, []byte{3, 0x60, 4, 0x7F, 0x7F, 0x7F, 0x7F, 1, 0x7F, 0x60, 1, 0x7F, 0, 0x60, 0, 0}},
+		{2, imports},
+		{3, []byte{1, 2}},
+		{5, []byte{1, 0x00, 1}},
+		{7, exports},
+		{10, code},
+		{11, append(segment, data...)},
+	} {
+		module = append(module, section.id)
+		module = append(module, wasmLEB(len(section.content))...)
+		module = append(module, section.content...)
+	}
+
+	return module
+}
+
+// wasmLEB encodes n as unsigned LEB128.
+func wasmLEB(n int) []byte {
+	var encoded []byte
+	for {
+		b := byte(n & 0x7F)
+		n >>= 7
+		if n == 0 {
+			return append(encoded, b)
+		}
+		encoded = append(encoded, b|0x80)
+	}
+}
+
+// writeWasmModule writes a test module to dir and returns its path.
+func writeWasmModule(t *testing.T, dir, name string, module []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	testutil.MustSucceed(t, os.WriteFile(path, module, 0o600), "writing WASM module")
+
+	return path
+}
+
+// TestWasmTransformsApply tests that matching transforms run in order on stdin, that a module
+// exiting with WasmExitLeaveOut leaves the file out, and that traps are errors.
+func TestWasmTransformsApply(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	header := writeWasmModule(t, dir, "header.wasm", wasmModule(wasmPrefixBody, "// header\n"))
+	license := writeWasmModule(t, dir, "license.wasm", wasmModule(wasmPrefixBody, "// MIT\n"))
+	drop := writeWasmModule(t, dir, "drop.wasm", wasmModule(wasmLeaveOutBody, ""))
+	trap := writeWasmModule(t, dir, "trap.wasm", wasmModule(wasmTrapBody, ""))
+
+	transforms, err := fileproc.LoadWasmTransforms(ctx, []config.WasmTransform{
+		{Pattern: "**/*.go", Module: header},
+		{Pattern: "cmd/**", Module: license},
+		{Pattern: "**/*.gen.go", Module: drop},
+		{Pattern: "broken/*", Module: trap},
+	})
+	testutil.MustSucceed(t, err, "LoadWasmTransforms")
+	defer func() { testutil.MustSucceed(t, transforms.Close(ctx), "closing transforms") }()
+
+	content := []byte(strings.Repeat("package main\n", 5000))
+	got, applied, err := transforms.Apply(ctx, filepath.FromSlash("cmd/main.go"), content)
+	testutil.MustSucceed(t, err, "Apply")
+	if want := "// MIT\n// header\n" + string(content); string(got) != want {
+		t.Errorf("Apply() = %.40q..., want %.40q...", got, want)
+	}
+	if strings.Join(applied, " ") != "header.wasm license.wasm" {
+		t.Errorf("applied = %v, want header.wasm license.wasm", applied)
+	}
+
+	if transforms.Applies("README.md") {
+		t.Error("Applies(README.md) = true, want false")
+	}
+	if got, applied, err := transforms.Apply(ctx, "README.md", content); err != nil || len(applied) != 0 ||
+		string(got) != string(content) {
+		t.Errorf("Apply(README.md) = %d bytes, %v, %v, want content unchanged", len(got), applied, err)
+	}
+
+	var structErr *shared.StructuredError
+	_, _, err = transforms.Apply(ctx, "api.gen.go", content)
+	if !errors.As(err, &structErr) || structErr.Code != shared.CodeValidationTransform {
+		t.Errorf("Apply(api.gen.go) error = %v, want %s", err, shared.CodeValidationTransform)
+	}
+	_, _, err = transforms.Apply(ctx, "broken/x", content)
+	if err == nil || errors.As(err, &structErr) && structErr.Code == shared.CodeValidationTransform {
+		t.Errorf("Apply(broken/x) error = %v, want a failure", err)
+	}
+}
+
+// TestLoadWasmTransformsErrors tests that invalid globs and modules fail loading.
+func TestLoadWasmTransformsErrors(t *testing.T) {
+	dir := t.TempDir()
+	valid := writeWasmModule(t, dir, "valid.wasm", wasmModule(wasmTrapBody, ""))
+	invalid := writeWasmModule(t, dir, "invalid.wasm", []byte("not wasm"))
+
+	tests := []struct {
+		name      string
+		transform config.WasmTransform
+		want      string
+	}{
+		{name: "invalid glob", transform: config.WasmTransform{Pattern: "[a-", Module: valid}, want: "invalid glob"},
+		{
+			name:      "missing module",
+			transform: config.WasmTransform{Pattern: "**", Module: filepath.Join(dir, "missing.wasm")},
+			want:      "failed to read WASM transform",
+		},
+		{
+			name:      "invalid module",
+			transform: config.WasmTransform{Pattern: "**", Module: invalid},
+			want:      "failed to compile WASM transform",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := fileproc.LoadWasmTransforms(context.Background(), []config.WasmTransform{tt.transform})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("LoadWasmTransforms() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// TestFileProcessorWasmTransforms tests that the content of a transformed file is written
+// with the transforms that ran in its metadata.
+func TestFileProcessorWasmTransforms(t *testing.T) {
+	testutil.SetViperKeys(t, nil)
+	ctx := context.Background()
+	root := t.TempDir()
+	file := testutil.CreateTestFile(t, root, "main.go", []byte(shared.LiteralPackageMain))
+	module := writeWasmModule(t, t.TempDir(), "header.wasm", wasmModule(wasmPrefixBody, "// header\n"))
+
+	transforms, err := fileproc.LoadWasmTransforms(ctx, []config.WasmTransform{{Pattern: "*.go", Module: module}})
+	testutil.MustSucceed(t, err, "LoadWasmTransforms")
+	defer func() { testutil.MustSucceed(t, transforms.Close(ctx), "closing transforms") }()
+	processor := fileproc.NewFileProcessor(root)
+	processor.SetWasmTransforms(transforms)
+
+	outCh := make(chan fileproc.WriteRequest, 1)
+	processor.Process(file, outCh)
+	close(outCh)
+	req := <-outCh
+	if !strings.Contains(req.Content, "// header\n"+shared.LiteralPackageMain) {
+		t.Errorf("content = %q, want the header before the file", req.Content)
+	}
+	if got := req.Metadata[shared.MetadataKeyTransforms]; got != "header.wasm" {
+		t.Errorf("transforms = %q, want header.wasm", got)
+	}
+}
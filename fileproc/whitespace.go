@@ -0,0 +1,178 @@
+// Package fileproc handles file processing, collection, and output formatting.
+package fileproc
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ivuorinen/gibidify/config"
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// whitespaceRule describes how indentation and trailing whitespace are rewritten for one file.
+type whitespaceRule struct {
+	indent   string
+	tabWidth int
+	trim     bool
+}
+
+// whitespaceRuleFor returns the rule of settings for language, with per-language overrides applied.
+func whitespaceRuleFor(settings *config.Settings, language string) whitespaceRule {
+	return whitespaceRule{
+		indent:   settings.OutputWhitespaceIndent(language),
+		tabWidth: max(settings.OutputWhitespaceTabWidth(language), 1),
+		trim:     settings.OutputWhitespaceTrimTrailing(language),
+	}
+}
+
+// active reports whether the rule changes anything.
+func (r whitespaceRule) active() bool {
+	return r.trim || r.indent == shared.IndentSpaces || r.indent == shared.IndentTabs
+}
+
+// apply rewrites every line of content.
+func (r whitespaceRule) apply(content string) string {
+	if !r.active() {
+		return content
+	}
+
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		lines[i] = string(r.line([]byte(line)))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// line rewrites a single line without its "\n" terminator; a trailing "\r" is kept.
+func (r whitespaceRule) line(line []byte) []byte {
+	cr := bytes.HasSuffix(line, []byte{'\r'})
+	if cr {
+		line = line[:len(line)-1]
+	}
+
+	switch r.indent {
+	case shared.IndentSpaces:
+		line = expandTabs(line, r.tabWidth)
+	case shared.IndentTabs:
+		line = indentWithTabs(line, r.tabWidth)
+	}
+	if r.trim {
+		line = bytes.TrimRight(line, " \t")
+	}
+	if cr {
+		line = append(line, '\r')
+	}
+
+	return line
+}
+
+// expandTabs replaces every tab with spaces up to the next tab stop.
+func expandTabs(line []byte, width int) []byte {
+	if !bytes.ContainsRune(line, '\t') {
+		return line
+	}
+
+	out := make([]byte, 0, len(line)+width)
+	column := 0
+	for _, b := range line {
+		if b == '\t' {
+			spaces := width - column%width
+			out = append(out, bytes.Repeat([]byte{' '}, spaces)...)
+			column += spaces
+
+			continue
+		}
+		out = append(out, b)
+		if !utf8.RuneStart(b) {
+			continue // continuation bytes do not advance the column
+		}
+		column++
+	}
+
+	return out
+}
+
+// indentWithTabs rewrites the leading indentation as tabs, keeping any remainder narrower than a tab as spaces.
+func indentWithTabs(line []byte, width int) []byte {
+	column, end := 0, 0
+	for end < len(line) && (line[end] == ' ' || line[end] == '\t') {
+		if line[end] == '\t' {
+			column += width - column%width
+		} else {
+			column++
+		}
+		end++
+	}
+	if end == 0 {
+		return line
+	}
+
+	out := make([]byte, 0, column/width+column%width+len(line)-end)
+	out = append(out, bytes.Repeat([]byte{'\t'}, column/width)...)
+	out = append(out, bytes.Repeat([]byte{' '}, column%width)...)
+
+	return append(out, line[end:]...)
+}
+
+// newWhitespaceReader wraps src so that rule is applied to every line.
+func newWhitespaceReader(src io.Reader, rule whitespaceRule) io.Reader {
+	if !rule.active() {
+		return src
+	}
+
+	return newLineReader(src, rule.line)
+}
+
+// lineReader rewrites streamed content line by line. The filter function receives
+// each line without its "\n" terminator and returns its replacement, or false to drop it.
+type lineReader struct {
+	src     *bufio.Reader
+	filter  func(line []byte) ([]byte, bool)
+	pending []byte
+	err     error
+}
+
+// newLineReader wraps src so that rewrite is applied to every line.
+func newLineReader(src io.Reader, rewrite func(line []byte) []byte) io.Reader {
+	return newFilterLineReader(src, func(line []byte) ([]byte, bool) {
+		return rewrite(line), true
+	})
+}
+
+// newFilterLineReader wraps src so that filter is applied to every line.
+func newFilterLineReader(src io.Reader, filter func(line []byte) ([]byte, bool)) io.Reader {
+	return &lineReader{src: bufio.NewReaderSize(src, shared.FileProcessingStreamChunkSize), filter: filter}
+}
+
+// Read implements io.Reader.
+func (r *lineReader) Read(p []byte) (int, error) {
+	for len(r.pending) == 0 {
+		if r.err != nil {
+			return 0, r.err //nolint:wrapcheck // EOF must not be wrapped; the header reader wraps other errors
+		}
+
+		line, err := r.src.ReadBytes('\n')
+		r.err = err
+		newline := bytes.HasSuffix(line, []byte{'\n'})
+		if newline {
+			line = line[:len(line)-1]
+		}
+		rewritten, keep := r.filter(line)
+		if !keep {
+			continue
+		}
+		r.pending = rewritten
+		if newline {
+			r.pending = append(r.pending, '\n')
+		}
+	}
+
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+
+	return n, nil
+}
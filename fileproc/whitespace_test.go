@@ -0,0 +1,116 @@
+package fileproc_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/fileproc"
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// processContent runs the file at path through a FileProcessor and returns its output content.
+func processContent(t *testing.T, dir, path string) string {
+	t.Helper()
+	outCh := make(chan fileproc.WriteRequest, 1)
+	testutil.MustSucceed(t,
+		fileproc.NewFileProcessor(dir).ProcessWithContext(context.Background(), path, outCh), "processing "+path)
+
+	req := <-outCh
+	if !req.IsStream {
+		return req.Content
+	}
+	data, err := io.ReadAll(req.Reader)
+	testutil.MustSucceed(t, err, "reading stream")
+
+	return string(data)
+}
+
+// TestFileProcessorWhitespace tests tab expansion, tab indentation, trimming and per-language overrides.
+func TestFileProcessorWhitespace(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  map[string]any
+		file    string
+		content string
+		want    string
+	}{
+		{
+			name:    "preserve by default",
+			file:    "main.go",
+			content: "func f() {\n\treturn  \n}\n",
+			want:    "func f() {\n\treturn  \n}\n",
+		},
+		{
+			name:    "expand tabs to tab stops",
+			config:  map[string]any{shared.ConfigKeyOutputWhitespaceIndent: shared.IndentSpaces},
+			file:    "main.go",
+			content: "\tx := 1\t// one\n",
+			want:    "    x := 1  // one\n",
+		},
+		{
+			name: "leading spaces to tabs",
+			config: map[string]any{
+				shared.ConfigKeyOutputWhitespaceIndent:   shared.IndentTabs,
+				shared.ConfigKeyOutputWhitespaceTabWidth: 2,
+			},
+			file:    "main.go",
+			content: "     x := \"a  b\"\n",
+			want:    "\t\t x := \"a  b\"\n",
+		},
+		{
+			name:    "trim trailing whitespace keeps CRLF",
+			config:  map[string]any{shared.ConfigKeyOutputWhitespaceTrimTrailing: true},
+			file:    "main.go",
+			content: "a \t\r\nb  \n",
+			want:    "a\r\nb\n",
+		},
+		{
+			name: "per-language override",
+			config: map[string]any{
+				shared.ConfigKeyOutputWhitespaceIndent: shared.IndentSpaces,
+				shared.ConfigKeyOutputWhitespaceLanguages: map[string]any{
+					"go": map[string]any{"indent": shared.IndentPreserve},
+				},
+			},
+			file:    "main.go",
+			content: "\treturn\n",
+			want:    "\treturn\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testutil.SetViperKeys(t, tt.config)
+			dir := t.TempDir()
+			path := testutil.CreateTestFile(t, dir, tt.file, []byte(tt.content))
+
+			if got := processContent(t, dir, path); !strings.HasSuffix(got, "\n"+tt.want+"\n") {
+				t.Errorf("content = %q, want suffix %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestFileProcessorWhitespaceStreaming tests that streamed files get the same whitespace rules.
+func TestFileProcessorWhitespaceStreaming(t *testing.T) {
+	testutil.SetViperKeys(t, map[string]any{
+		shared.ConfigKeyOutputWhitespaceIndent:       shared.IndentSpaces,
+		shared.ConfigKeyOutputWhitespaceTrimTrailing: true,
+	})
+
+	line := "\tfmt.Println(\"x\")  \n"
+	dir := t.TempDir()
+	path := testutil.CreateTestFile(t, dir, "large.go",
+		[]byte(strings.Repeat(line, shared.FileProcessingStreamThreshold/len(line)+1)))
+
+	got := processContent(t, dir, path)
+	if strings.ContainsAny(got, "\t") || strings.Contains(got, "  \n") {
+		t.Error("streamed content still has tabs or trailing whitespace")
+	}
+	if !strings.Contains(got, "\n    fmt.Println(\"x\")\n") {
+		t.Errorf("streamed content not expanded: %q", got[:80])
+	}
+}
@@ -15,42 +15,117 @@ func startFormatWriter(
 	writeCh <-chan WriteRequest,
 	done chan<- struct{},
 	prefix, suffix string,
-	writerFactory func(*os.File) FormatWriter,
+	opts WriterOptions,
+	writerFactory func(outputWriter) FormatWriter,
 ) {
+	if opts.Split != nil {
+		startSplitWriter(outFile, writeCh, done, prefix, suffix, opts, writerFactory)
+
+		return
+	}
 	defer close(done)
 
-	writer := writerFactory(outFile)
+	settings := opts.settings()
+	output := newBundleOutput(outFile, settings.OutputBufferSize())
+	timer, out := newSectionTimer(output, opts.Timing)
+	writer := writerFactory(out)
+	index := opts.Index
+	var summary *RunSummary
+	if opts.Summary != nil {
+		summary = &RunSummary{RunID: opts.RunID}
+	}
 
 	// Start writing
-	if err := writer.Start(prefix, suffix); err != nil {
+	if err := startFormatWriterOutput(writer, prefix, suffix, opts.Config); err != nil {
 		shared.LogError("Failed to start writer", err)
 
 		return
 	}
 
+	fileIDs, algorithm := settings.OutputFileIDs(), settings.PerformanceHashAlgorithm()
+
+	// Render in-memory entries concurrently when more than one format worker is configured
+	requests := writeCh
+	if renderer, ok := writer.(inlineRenderer); ok && settings.PerformanceFormatWorkers() > 1 {
+		requests = renderConcurrently(writeCh, renderer, settings.PerformanceFormatWorkers(), fileIDs, algorithm)
+	}
+
 	// Process files
-	for req := range writeCh {
-		if err := writer.WriteFile(req); err != nil {
+	for req := range requests {
+		if fileIDs && req.rendered == nil {
+			req = withFileID(req, algorithm)
+		}
+		start, indexed := index.offset(output)
+		req = timer.begin(req)
+		err := writer.WriteFile(req)
+		timer.end(req)
+		if err != nil {
 			shared.LogError("Failed to write file", err)
+
+			continue
+		}
+		if indexed {
+			index.record(output, req, start, opts.registry(), algorithm)
 		}
+		summary.add(req, opts.registry())
 	}
 
 	// Close writer
-	if err := writer.Close(); err != nil {
+	if err := closeFormatWriter(writer, summary, opts.Summary); err != nil {
 		shared.LogError("Failed to close writer", err)
 	}
+	if err := output.Flush(); err != nil {
+		shared.LogError("Failed to flush output", err)
+	}
+	if opts.Stats != nil {
+		*opts.Stats = output.stats
+	}
 }
 
 // StartWriter writes the output in the specified format with memory optimization.
 func StartWriter(outFile *os.File, writeCh <-chan WriteRequest, done chan<- struct{}, format, prefix, suffix string) {
+	StartIndexedWriter(outFile, writeCh, done, format, prefix, suffix, nil)
+}
+
+// StartIndexedWriter works like StartWriter and additionally records the byte range
+// of every file section in index. A nil index records nothing.
+func StartIndexedWriter(
+	outFile *os.File,
+	writeCh <-chan WriteRequest,
+	done chan<- struct{},
+	format, prefix, suffix string,
+	index *BundleIndex,
+) {
+	StartWriterWithOptions(outFile, writeCh, done, format, prefix, suffix, WriterOptions{Index: index})
+}
+
+// StartWriterWithOptions works like StartWriter with the optional features in opts enabled.
+func StartWriterWithOptions(
+	outFile *os.File,
+	writeCh <-chan WriteRequest,
+	done chan<- struct{},
+	format, prefix, suffix string,
+	opts WriterOptions,
+) {
 	switch format {
 	case shared.FormatMarkdown:
-		startMarkdownWriter(outFile, writeCh, done, prefix, suffix)
+		startMarkdownWriter(outFile, writeCh, done, prefix, suffix, opts)
 	case shared.FormatJSON:
-		startJSONWriter(outFile, writeCh, done, prefix, suffix)
+		startJSONWriter(outFile, writeCh, done, prefix, suffix, opts)
 	case shared.FormatYAML:
-		startYAMLWriter(outFile, writeCh, done, prefix, suffix)
+		startYAMLWriter(outFile, writeCh, done, prefix, suffix, opts)
+	case shared.FormatPlain:
+		startPlainWriter(outFile, writeCh, done, prefix, suffix, opts)
+	case shared.FormatPDF:
+		startPDFWriter(outFile, writeCh, done, prefix, suffix, opts)
+	case shared.FormatZip:
+		startZipWriter(outFile, writeCh, done, prefix, suffix, opts)
 	default:
+		if plugin, ok := opts.settings().OutputPlugin(format); ok {
+			startPluginWriter(outFile, writeCh, done, prefix, suffix, opts, format, plugin)
+
+			return
+		}
 		context := map[string]any{
 			"format": format,
 		}
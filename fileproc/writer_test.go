@@ -1,6 +1,7 @@
 package fileproc_test
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -12,10 +13,13 @@ import (
 	"testing"
 	"time"
 
+	"github.com/spf13/viper"
 	"gopkg.in/yaml.v3"
 
+	"github.com/ivuorinen/gibidify/config"
 	"github.com/ivuorinen/gibidify/fileproc"
 	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
 )
 
 func TestStartWriterFormats(t *testing.T) {
@@ -28,6 +32,8 @@ func TestStartWriterFormats(t *testing.T) {
 		{"JSON format", "json", false},
 		{"YAML format", "yaml", false},
 		{"Markdown format", "markdown", false},
+		{"Plain format", "plain", false},
+		{"PDF format", "pdf", false},
 		{"Invalid format", "invalid", true},
 	}
 
@@ -121,6 +127,14 @@ func verifyValidOutput(t *testing.T, data []byte, format string) {
 		if !strings.Contains(content, "```") {
 			t.Error("Expected markdown code fences not found")
 		}
+	case "plain":
+		if !strings.Contains(content, "===== ") {
+			t.Error("Expected plain delimiter lines not found")
+		}
+	case "pdf":
+		if !strings.HasPrefix(content, "%PDF-") || !strings.HasSuffix(content, "%%EOF\n") {
+			t.Error("Expected PDF header and trailer not found")
+		}
 	default:
 		// Unknown format - basic validation that we have content
 		if len(content) == 0 {
@@ -163,6 +177,8 @@ func TestStartWriterStreamingFormats(t *testing.T) {
 		{"JSON streaming", "json", strings.Repeat("line\n", 1000)},
 		{"YAML streaming", "yaml", strings.Repeat("data: value\n", 1000)},
 		{"Markdown streaming", "markdown", strings.Repeat("# Header\nContent\n", 1000)},
+		{"Plain streaming", "plain", strings.Repeat("```go\ncode\n```\n", 1000)},
+		{"PDF streaming", "pdf", strings.Repeat("func (w *writer) Close() error {\n\treturn nil\n}\n", 1000)},
 	}
 
 	for _, tc := range tests {
@@ -189,6 +205,31 @@ func TestStartWriterStreamingFormats(t *testing.T) {
 	}
 }
 
+func TestStartWriterStreamingPreservesSplitCharacters(t *testing.T) {
+	// An odd-length line puts multi-byte characters across the stream chunk boundaries
+	content := strings.Repeat("é世🌍 \"quoted\" <tag>\n", shared.FileProcessingStreamThreshold/25)
+
+	for _, format := range []string{shared.FormatJSON, shared.FormatYAML} {
+		t.Run(format, func(t *testing.T) {
+			data := runStreamingWriterTest(t, format, content)
+
+			var output fileproc.OutputData
+			var err error
+			if format == shared.FormatJSON {
+				err = json.Unmarshal(data, &output)
+			} else {
+				err = yaml.Unmarshal(data, &output)
+			}
+			if err != nil {
+				t.Fatalf("decoding %s output: %v", format, err)
+			}
+			if len(output.Files) != 1 || output.Files[0].Content != content {
+				t.Errorf("%s output does not decode to the streamed content", format)
+			}
+		})
+	}
+}
+
 // runStreamingWriterTest executes the writer with streaming content.
 func runStreamingWriterTest(t *testing.T, format, content string) []byte {
 	t.Helper()
@@ -532,8 +573,323 @@ func TestStartWriterWriterCloseErrors(t *testing.T) {
 	}
 }
 
+// TestJSONWriterSourceSpans tests that JSON spans locate each entry's content in the document and source.
+func TestJSONWriterSourceSpans(t *testing.T) {
+	testutil.SetViperKeys(t, map[string]any{shared.ConfigKeyOutputSourceSpans: true})
+
+	contents := map[string]string{
+		"inline.go": "package main\n\nfunc main() {}\n",
+		"stream.js": "const s = \"<tab>\\t\";\nexport default s",
+		"empty.txt": "",
+	}
+	outFile, path := testutil.CreateTempOutputFile(t, "spans_*.json")
+	writeCh := make(chan fileproc.WriteRequest, len(contents))
+	done := make(chan struct{})
+	writeCh <- fileproc.WriteRequest{Path: "inline.go", Content: contents["inline.go"]}
+	writeCh <- fileproc.WriteRequest{Path: "stream.js", IsStream: true, Reader: strings.NewReader(contents["stream.js"])}
+	writeCh <- fileproc.WriteRequest{Path: "empty.txt"}
+	close(writeCh)
+
+	fileproc.StartWriter(outFile, writeCh, done, shared.FormatJSON, "prefix", "suffix")
+	<-done
+	testutil.CloseFile(t, outFile)
+
+	data, err := os.ReadFile(path)
+	testutil.MustSucceed(t, err, "reading output")
+	var output fileproc.OutputData
+	testutil.MustSucceed(t, json.Unmarshal(data, &output), "decoding output")
+
+	wantLines := map[string]int{"inline.go": 3, "stream.js": 2, "empty.txt": 0}
+	for _, file := range output.Files {
+		span := file.Span
+		if span == nil {
+			t.Fatalf("%s: missing span", file.Path)
+		}
+		var decoded string
+		raw := `"` + string(data[span.Offset:span.Offset+span.Length]) + `"`
+		testutil.MustSucceed(t, json.Unmarshal([]byte(raw), &decoded), "decoding span of "+file.Path)
+		if decoded != contents[file.Path] {
+			t.Errorf("%s: span covers %q, want %q", file.Path, decoded, contents[file.Path])
+		}
+		if span.EndLine != wantLines[file.Path] || span.EndByte != int64(len(contents[file.Path])) {
+			t.Errorf("%s: source span lines %d-%d bytes %d-%d", file.Path,
+				span.StartLine, span.EndLine, span.StartByte, span.EndByte)
+		}
+	}
+}
+
+// TestJSONWriterSourceSpanOfProcessedFile tests that the span of a processed file covers its
+// source bytes and lines only, not the header naming it, and marks budget truncation.
+func TestJSONWriterSourceSpanOfProcessedFile(t *testing.T) {
+	testutil.SetViperKeys(t, map[string]any{shared.ConfigKeyOutputSourceSpans: true})
+	root := t.TempDir()
+	small := testutil.CreateTestFile(t, root, "a.txt", []byte("line one\nab\n"))
+	long := testutil.CreateTestFile(t, root, "notes.txt", []byte(strings.Repeat("line\n", 10)))
+	budgets := fileproc.NewBudgets(root, map[string]config.Budget{"notes.txt": {MaxBytes: 20}})
+	budgets.Apply([]string{long}, map[string]int64{long: 50}, fileproc.NewFileTypeRegistry())
+	processor := fileproc.NewFileProcessor(root)
+	processor.SetBudgets(budgets)
+
+	outFile, path := testutil.CreateTempOutputFile(t, "spans_*.json")
+	writeCh := make(chan fileproc.WriteRequest, 2)
+	done := make(chan struct{})
+	processor.Process(small, writeCh)
+	processor.Process(long, writeCh)
+	close(writeCh)
+	fileproc.StartWriter(outFile, writeCh, done, shared.FormatJSON, "", "")
+	<-done
+	testutil.CloseFile(t, outFile)
+
+	data, err := os.ReadFile(path)
+	testutil.MustSucceed(t, err, "reading output")
+	var output fileproc.OutputData
+	testutil.MustSucceed(t, json.Unmarshal(data, &output), "decoding output")
+
+	want := map[string]fileproc.SourceSpan{
+		"a.txt":     {StartLine: 1, EndLine: 2, StartByte: 0, EndByte: 12},
+		"notes.txt": {StartLine: 1, EndLine: 4, StartByte: 0, EndByte: 20, Truncated: true},
+	}
+	for _, file := range output.Files {
+		span := *file.Span
+		span.Offset, span.Length = 0, 0
+		if span != want[file.Path] {
+			t.Errorf("%s: span = %+v, want %+v", file.Path, span, want[file.Path])
+		}
+	}
+}
+
+// closeRecorder is a reader that records whether it was closed.
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed = true
+
+	return nil
+}
+
+func TestStartWriterWithOptionsTiming(t *testing.T) {
+	for _, format := range []string{shared.FormatJSON, shared.FormatMarkdown, shared.FormatYAML} {
+		t.Run(format, func(t *testing.T) {
+			outFile, _ := testutil.CreateTempOutputFile(t, "timing_*."+format)
+			stream := &closeRecorder{Reader: strings.NewReader("streamed content\n")}
+			writeCh := make(chan fileproc.WriteRequest, 2)
+			writeCh <- fileproc.WriteRequest{Path: "inline.go", Content: shared.LiteralPackageMain}
+			writeCh <- fileproc.WriteRequest{Path: "stream.txt", IsStream: true, Reader: stream}
+			close(writeCh)
+
+			var mu sync.Mutex
+			counts := map[string]int{}
+			hook := func(_, phase string, _ int64, d time.Duration) {
+				mu.Lock()
+				defer mu.Unlock()
+				if d < 0 {
+					t.Errorf("negative %s timing %v", phase, d)
+				}
+				counts[phase]++
+			}
+
+			done := make(chan struct{})
+			fileproc.StartWriterWithOptions(
+				outFile, writeCh, done, format, "", "", fileproc.WriterOptions{Timing: hook},
+			)
+			<-done
+			testutil.CloseFile(t, outFile)
+
+			// Only the streamed file is read by the writer
+			want := map[string]int{shared.MetricsPhaseRead: 1, shared.MetricsPhaseFormat: 2, shared.MetricsPhaseWrite: 2}
+			for phase, n := range want {
+				if counts[phase] != n {
+					t.Errorf("%s recorded %d times, want %d", phase, counts[phase], n)
+				}
+			}
+			if !stream.closed {
+				t.Error("streamed reader was not closed through the timing wrapper")
+			}
+		})
+	}
+}
+
+// TestStartWriterWithOptionsRegistry checks that the writer detects languages with the registry
+// it is given, and that configuring one registry leaves new ones untouched.
+func TestStartWriterWithOptionsRegistry(t *testing.T) {
+	registry := fileproc.NewFileTypeRegistry()
+	registry.Configure(nil, nil, map[string]string{".injected": "injected"}, nil, nil, nil)
+
+	outFile, path := testutil.CreateTempOutputFile(t, "registry_*.json")
+	writeCh := make(chan fileproc.WriteRequest, 1)
+	writeCh <- fileproc.WriteRequest{Path: "file.injected", Content: "content"}
+	close(writeCh)
+
+	done := make(chan struct{})
+	fileproc.StartWriterWithOptions(
+		outFile, writeCh, done, shared.FormatJSON, "", "", fileproc.WriterOptions{Registry: registry},
+	)
+	<-done
+	testutil.CloseFile(t, outFile)
+
+	data, err := os.ReadFile(path) // #nosec G304 -- path is in t.TempDir()
+	testutil.MustSucceed(t, err, "reading output")
+	var bundle fileproc.OutputData
+	testutil.MustSucceed(t, json.Unmarshal(data, &bundle), "decoding output")
+	if len(bundle.Files) != 1 || bundle.Files[0].Language != "injected" {
+		t.Errorf("files = %+v, want file.injected with language injected", bundle.Files)
+	}
+	if lang := fileproc.NewFileTypeRegistry().Language("file.injected"); lang != "" {
+		t.Errorf("a new registry detects %q for file.injected, want none", lang)
+	}
+}
+
+// escapeHeavyContent returns content dominated by characters that JSON and YAML must escape.
+func escapeHeavyContent(size int) string {
+	const line = "\"quoted\"\t\\path\\to\\file <tag attr='x'> & \u00e9\u4e2d\x01\r\n"
+
+	return strings.Repeat(line, size/len(line)+1)[:size]
+}
+
+// writeWithFormatWorkers writes entries in format with the given number of format workers
+// and returns the output.
+func writeWithFormatWorkers(tb testing.TB, format string, workers int, entries []fileproc.WriteRequest) []byte {
+	tb.Helper()
+	viper.Set(shared.ConfigKeyPerformanceFormatWorkers, workers)
+
+	path := filepath.Join(tb.TempDir(), "workers."+format)
+	outFile, err := os.Create(path)
+	if err != nil {
+		tb.Fatalf("creating output: %v", err)
+	}
+	writeCh := make(chan fileproc.WriteRequest, len(entries))
+	for _, entry := range entries {
+		writeCh <- entry
+	}
+	close(writeCh)
+
+	done := make(chan struct{})
+	fileproc.StartWriter(outFile, writeCh, done, format, "prefix", "suffix")
+	<-done
+	if err := outFile.Close(); err != nil {
+		tb.Fatalf("closing output: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		tb.Fatalf("reading output: %v", err)
+	}
+
+	return data
+}
+
+func TestStartWriterFormatWorkers(t *testing.T) {
+	testutil.SetViperKeys(t, map[string]any{shared.ConfigKeyOutputFileIDs: true})
+	t.Cleanup(func() {
+		viper.Set(shared.ConfigKeyPerformanceFormatWorkers, shared.ConfigPerformanceFormatWorkersDefault)
+	})
+
+	newEntries := func() []fileproc.WriteRequest {
+		entries := make([]fileproc.WriteRequest, 0, 50)
+		for i := range 50 {
+			path := fmt.Sprintf("file%02d.go", i)
+			if i%10 == 5 {
+				entries = append(entries, fileproc.WriteRequest{
+					Path: path, IsStream: true, Reader: strings.NewReader(escapeHeavyContent(200)),
+				})
+
+				continue
+			}
+			entries = append(entries, fileproc.WriteRequest{
+				Path: path, Content: escapeHeavyContent(100 * (i + 1)), Metadata: map[string]string{"n": fmt.Sprint(i)},
+			})
+		}
+
+		return entries
+	}
+
+	for _, format := range []string{shared.FormatJSON, shared.FormatMarkdown, shared.FormatYAML, shared.FormatPlain} {
+		t.Run(format, func(t *testing.T) {
+			want := writeWithFormatWorkers(t, format, 1, newEntries())
+			got := writeWithFormatWorkers(t, format, 4, newEntries())
+			if string(got) != string(want) {
+				t.Errorf("output with 4 format workers differs from 1 worker:\ngot  %.300s\nwant %.300s", got, want)
+			}
+		})
+	}
+}
+
+// TestMarkdownSectionPlaceholder tests that output.markdown.sectionPlaceholder follows every file
+// section, inline and streamed, but not the prelude.
+func TestMarkdownSectionPlaceholder(t *testing.T) {
+	testutil.SetViperKeys(t, map[string]any{
+		shared.ConfigKeyOutputMarkdownSectionPlaceholder: "- [ ] Reviewed {{path}} ({{language}}, {{id}})\n",
+	})
+
+	output := string(writeWithFormatWorkers(t, shared.FormatMarkdown, 1, []fileproc.WriteRequest{
+		{
+			Path:     "README.md",
+			Content:  "# Context",
+			Metadata: map[string]string{shared.MetadataKeyRole: shared.MetadataRolePrelude},
+		},
+		{Path: "main.go", Content: shared.LiteralPackageMain},
+		{Path: "app.py", IsStream: true, Reader: strings.NewReader("print(1)")},
+	}))
+
+	for _, want := range []string{
+		"```\n\n- [ ] Reviewed main.go (go, " + fileproc.FileID("main.go") + ")\n\n## File: `app.py`",
+		"```\n\n- [ ] Reviewed app.py (python, " + fileproc.FileID("app.py") + ")\n\n",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output does not contain %q:\n%s", want, output)
+		}
+	}
+	if strings.Count(output, "- [ ] Reviewed") != 2 {
+		t.Errorf("want a placeholder after the two file sections only:\n%s", output)
+	}
+}
+
+// TestPlainWriter tests the plain format: a delimiter line, metadata lines and the content
+// unchanged, each section ending with one blank line whether the content ends in a newline or not.
+func TestPlainWriter(t *testing.T) {
+	testutil.SetViperKeys(t, map[string]any{shared.ConfigKeyOutputPlainDelimiter: "--- FILE: {{path}} ---"})
+
+	output := string(writeWithFormatWorkers(t, shared.FormatPlain, 1, []fileproc.WriteRequest{
+		{Path: "README.md", Content: "```go\nfmt.Println()\n```\n", Metadata: map[string]string{"note": "a\nb"}},
+		{Path: "main.go", Content: shared.LiteralPackageMain},
+		{Path: "app.py", IsStream: true, Reader: strings.NewReader("print(1)")},
+	}))
+
+	want := "prefix\n\n--- FILE: README.md ---\nnote: a\n  b\n\n```go\nfmt.Println()\n```\n\n" +
+		"--- FILE: main.go ---\n" + shared.LiteralPackageMain + "\n\n" +
+		"--- FILE: app.py ---\nprint(1)\n\nsuffix\n"
+	if output != want {
+		t.Errorf("plain output = %q, want %q", output, want)
+	}
+}
+
 // Benchmarks for writer performance
 
+// BenchmarkFormatWorkers benchmarks rendering an escape-heavy corpus with different numbers of format workers.
+func BenchmarkFormatWorkers(b *testing.B) {
+	b.Cleanup(func() {
+		viper.Set(shared.ConfigKeyPerformanceFormatWorkers, shared.ConfigPerformanceFormatWorkersDefault)
+	})
+	entries := make([]fileproc.WriteRequest, 200)
+	for i := range entries {
+		entries[i] = fileproc.WriteRequest{Path: fmt.Sprintf("file%03d.txt", i), Content: escapeHeavyContent(64 * 1024)}
+	}
+
+	for _, format := range []string{shared.FormatJSON, shared.FormatYAML, shared.FormatMarkdown} {
+		for _, workers := range []int{1, 2, 4, 8} {
+			b.Run(fmt.Sprintf("%s/workers=%d", format, workers), func(b *testing.B) {
+				b.SetBytes(int64(len(entries) * 64 * 1024))
+				for b.Loop() {
+					writeWithFormatWorkers(b, format, workers, entries)
+				}
+			})
+		}
+	}
+}
+
 // BenchmarkStartWriter benchmarks basic writer operations across formats.
 func BenchmarkStartWriter(b *testing.B) {
 	formats := []string{"json", "yaml", "markdown"}
@@ -627,3 +983,87 @@ func BenchmarkStartWriterStreaming(b *testing.B) {
 		})
 	}
 }
+
+func TestStartWriterOutputBuffering(t *testing.T) {
+	testutil.ResetViperConfig(t, "")
+	t.Cleanup(func() { viper.Set(shared.ConfigKeyOutputBufferSize, shared.ConfigOutputBufferSizeDefault) })
+
+	write := func(t *testing.T, format string, bufferSize int) ([]byte, fileproc.OutputStats) {
+		t.Helper()
+		viper.Set(shared.ConfigKeyOutputBufferSize, bufferSize)
+
+		path := filepath.Join(t.TempDir(), "buffered."+format)
+		outFile, err := os.Create(path)
+		if err != nil {
+			t.Fatalf("creating output: %v", err)
+		}
+		writeCh := make(chan fileproc.WriteRequest, 100)
+		for i := range 100 {
+			writeCh <- fileproc.WriteRequest{Path: fmt.Sprintf("file%03d.go", i), Content: "package main\n"}
+		}
+		close(writeCh)
+
+		var stats fileproc.OutputStats
+		done := make(chan struct{})
+		fileproc.StartWriterWithOptions(outFile, writeCh, done, format, "prefix", "suffix", fileproc.WriterOptions{
+			Stats: &stats,
+		})
+		<-done
+		testutil.CloseFile(t, outFile)
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("reading output: %v", err)
+		}
+		if stats.Bytes != int64(len(data)) {
+			t.Errorf("stats counted %d bytes, output has %d", stats.Bytes, len(data))
+		}
+
+		return data, stats
+	}
+
+	for _, format := range []string{shared.FormatJSON, shared.FormatMarkdown, shared.FormatYAML} {
+		t.Run(format, func(t *testing.T) {
+			unbuffered, direct := write(t, format, 0)
+			buffered, batched := write(t, format, shared.ConfigOutputBufferSizeDefault)
+
+			if !bytes.Equal(unbuffered, buffered) {
+				t.Error("buffered output differs from unbuffered output")
+			}
+			if direct.Writes < 100 || batched.Writes != 1 {
+				t.Errorf("got %d unbuffered and %d buffered writes, want at least 100 and 1", direct.Writes, batched.Writes)
+			}
+		})
+	}
+}
+
+// TestStartWriterOutputError tests that the first error writing to the output file is
+// recorded in the output stats rather than only logged.
+func TestStartWriterOutputError(t *testing.T) {
+	testutil.ResetViperConfig(t, "")
+
+	for _, format := range []string{shared.FormatJSON, shared.FormatMarkdown, shared.FormatYAML} {
+		t.Run(format, func(t *testing.T) {
+			outFile, err := os.Create(filepath.Join(t.TempDir(), "closed."+format))
+			if err != nil {
+				t.Fatalf("creating output: %v", err)
+			}
+			testutil.CloseFile(t, outFile)
+
+			writeCh := make(chan fileproc.WriteRequest, 1)
+			writeCh <- fileproc.WriteRequest{Path: "main.go", Content: "package main\n"}
+			close(writeCh)
+
+			var stats fileproc.OutputStats
+			done := make(chan struct{})
+			fileproc.StartWriterWithOptions(outFile, writeCh, done, format, "", "", fileproc.WriterOptions{
+				Stats: &stats,
+			})
+			<-done
+
+			if !errors.Is(stats.Err, os.ErrClosed) {
+				t.Errorf("stats.Err = %v, want %v", stats.Err, os.ErrClosed)
+			}
+		})
+	}
+}
@@ -11,12 +11,18 @@ import (
 
 // YAMLWriter handles YAML format output with streaming support.
 type YAMLWriter struct {
-	outFile *os.File
+	outFile  outputWriter
+	registry *FileTypeRegistry
 }
 
-// NewYAMLWriter creates a new YAML writer.
+// NewYAMLWriter creates a new YAML writer detecting languages with the default registry.
 func NewYAMLWriter(outFile *os.File) *YAMLWriter {
-	return &YAMLWriter{outFile: outFile}
+	return newYAMLWriter(outFile, getRegistry())
+}
+
+// newYAMLWriter creates a YAML writer for any output.
+func newYAMLWriter(out outputWriter, registry *FileTypeRegistry) *YAMLWriter {
+	return &YAMLWriter{outFile: out, registry: registry}
 }
 
 // Start writes the YAML header.
@@ -34,6 +40,19 @@ func (w *YAMLWriter) Start(prefix, suffix string) error {
 	return nil
 }
 
+// StartWithConfig writes settings in a YAML comment, then the YAML header.
+func (w *YAMLWriter) StartWithConfig(prefix, suffix string, settings map[string]any) error {
+	encoded, err := encodeConfigProvenance(settings)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w.outFile, "# %s%s\n", configProvenancePrefix, encoded); err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOWrite, "failed to write config provenance")
+	}
+
+	return w.Start(prefix, suffix)
+}
+
 // WriteFile writes a file entry in YAML format.
 func (w *YAMLWriter) WriteFile(req WriteRequest) error {
 	if req.IsStream {
@@ -48,31 +67,34 @@ func (w *YAMLWriter) Close() error {
 	return nil
 }
 
+// CloseWithSummary writes a run summary as the closing summary key.
+func (w *YAMLWriter) CloseWithSummary(summary RunSummary) error {
+	section, err := summary.yaml()
+	if err != nil {
+		return err
+	}
+	if _, err := w.outFile.WriteString(section); err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOWrite, "failed to write run summary")
+	}
+
+	return nil
+}
+
 // writeStreaming writes a large file as YAML in streaming chunks.
 func (w *YAMLWriter) writeStreaming(req WriteRequest) error {
 	defer shared.SafeCloseReader(req.Reader, req.Path)
 
-	language := detectLanguage(req.Path)
+	language := entryLanguage(req, w.registry)
 
 	// Write YAML file entry start
-	if _, err := fmt.Fprintf(
-		w.outFile,
-		shared.YAMLFmtFileEntry,
-		shared.EscapeForYAML(req.Path),
-		language,
-	); err != nil {
-		return shared.WrapError(
-			err,
-			shared.ErrorTypeIO,
-			shared.CodeIOWrite,
-			"failed to write YAML file start",
-		).WithFilePath(req.Path)
+	if err := w.writeEntryStart(req.Path, language, req.Metadata); err != nil {
+		return err
 	}
 
 	// Stream content with YAML indentation
 	if err := shared.StreamLines(
 		req.Reader, w.outFile, req.Path, func(line string) string {
-			return "      " + line
+			return shared.YAMLContentIndent + line
 		},
 	); err != nil {
 		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOWrite, "streaming YAML content")
@@ -83,47 +105,103 @@ func (w *YAMLWriter) writeStreaming(req WriteRequest) error {
 
 // writeInline writes a small file directly as YAML.
 func (w *YAMLWriter) writeInline(req WriteRequest) error {
-	language := detectLanguage(req.Path)
-	fileData := FileData{
-		Path:     req.Path,
-		Content:  req.Content,
-		Language: language,
+	entry, err := renderedEntry(req, w)
+	if err != nil {
+		return err
 	}
+	defer shared.ScratchBuffers.Put(entry)
 
-	// Write YAML entry
-	if _, err := fmt.Fprintf(
-		w.outFile,
-		shared.YAMLFmtFileEntry,
-		shared.EscapeForYAML(fileData.Path),
-		fileData.Language,
-	); err != nil {
+	if _, err := w.outFile.Write(*entry); err != nil {
 		return shared.WrapError(
 			err,
 			shared.ErrorTypeIO,
 			shared.CodeIOWrite,
-			"failed to write YAML entry start",
+			"failed to write YAML entry",
 		).WithFilePath(req.Path)
 	}
 
-	// Write indented content
-	lines := strings.Split(fileData.Content, "\n")
-	for _, line := range lines {
-		if _, err := fmt.Fprintf(w.outFile, "      %s\n", line); err != nil {
-			return shared.WrapError(
-				err,
-				shared.ErrorTypeIO,
-				shared.CodeIOWrite,
-				"failed to write YAML content line",
-			).WithFilePath(req.Path)
+	return nil
+}
+
+// renderInline renders a small file as a YAML entry with indented content lines.
+func (w *YAMLWriter) renderInline(dst []byte, req WriteRequest) ([]byte, error) {
+	entry := append(dst, yamlEntryStart(req.Path, entryLanguage(req, w.registry), req.Metadata)...)
+
+	return appendYAMLContent(entry, req.Content), nil
+}
+
+// appendYAMLContent appends content as the value of a file entry's content key, in a form that
+// decodes back to content exactly: a literal block whose header carries the indentation and
+// trailing newlines a plain "|" would lose, or a double-quoted scalar when content holds
+// characters a literal block cannot carry.
+func appendYAMLContent(dst []byte, content string) []byte {
+	if !shared.YAMLLiteralSafe(content) {
+		dst = append(dst, shared.QuoteForYAML(content)...)
+
+		return append(dst, '\n')
+	}
+
+	dst = append(dst, '|')
+	if first := strings.TrimLeft(content, "\n"); strings.HasPrefix(first, " ") || strings.HasPrefix(first, "\t") {
+		dst = append(dst, shared.YAMLContentIndentIndicator...)
+	}
+	lines := strings.TrimSuffix(content, "\n")
+	switch trailing := len(content) - len(strings.TrimRight(content, "\n")); {
+	case trailing == 0:
+		dst = append(dst, '-')
+	case trailing > 1 || trailing == len(content):
+		dst = append(dst, '+')
+	}
+	dst = append(dst, '\n')
+
+	for line := range strings.SplitSeq(lines, "\n") {
+		dst = append(dst, shared.YAMLContentIndent...)
+		dst = append(dst, line...)
+		dst = append(dst, '\n')
+	}
+
+	return dst
+}
+
+// yamlEntryStart renders the path, language, and metadata of a YAML file entry up to its content key.
+func yamlEntryStart(path, language string, meta map[string]string) string {
+	var header strings.Builder
+	fmt.Fprintf(&header, shared.YAMLFmtFileEntryHeader, shared.EscapeForYAML(path), language)
+	if len(meta) > 0 {
+		header.WriteString(shared.YAMLFileEntryMetadata)
+		for _, key := range sortedMetadataKeys(meta) {
+			fmt.Fprintf(&header, shared.YAMLFmtMetadataEntry, shared.EscapeForYAML(key), shared.EscapeForYAML(meta[key]))
 		}
 	}
+	header.WriteString(shared.YAMLFileEntryContent)
+
+	return header.String()
+}
+
+// writeEntryStart writes the path, language, and metadata of a streamed YAML file entry and opens
+// its content block.
+func (w *YAMLWriter) writeEntryStart(path, language string, meta map[string]string) error {
+	if _, err := w.outFile.WriteString(yamlEntryStart(path, language, meta) + shared.YAMLStreamContentHeader); err != nil {
+		return shared.WrapError(
+			err,
+			shared.ErrorTypeIO,
+			shared.CodeIOWrite,
+			"failed to write YAML entry start",
+		).WithFilePath(path)
+	}
 
 	return nil
 }
 
 // startYAMLWriter handles YAML format output with streaming support.
-func startYAMLWriter(outFile *os.File, writeCh <-chan WriteRequest, done chan<- struct{}, prefix, suffix string) {
-	startFormatWriter(outFile, writeCh, done, prefix, suffix, func(f *os.File) FormatWriter {
-		return NewYAMLWriter(f)
+func startYAMLWriter(
+	outFile *os.File,
+	writeCh <-chan WriteRequest,
+	done chan<- struct{},
+	prefix, suffix string,
+	opts WriterOptions,
+) {
+	startFormatWriter(outFile, writeCh, done, prefix, suffix, opts, func(out outputWriter) FormatWriter {
+		return newYAMLWriter(out, opts.registry())
 	})
 }
@@ -0,0 +1,163 @@
+// Package fileproc handles file processing, collection, and output formatting.
+package fileproc
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// zipEpoch is the modification time of every zip entry, the earliest an MS-DOS timestamp holds.
+var zipEpoch = time.Date(1980, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// ZipManifest is the manifest entry of a zip bundle, stored at shared.FormatZipManifest.
+type ZipManifest struct {
+	Prefix string             `json:"prefix,omitempty"`
+	Suffix string             `json:"suffix,omitempty"`
+	Files  []ZipManifestEntry `json:"files"`
+	// Summary is the run summary written with output.appendRunSummary.
+	Summary *RunSummary `json:"summary,omitempty"`
+	// Config holds the settings recorded with output.configProvenance.
+	Config map[string]any `json:"config,omitempty"`
+}
+
+// ZipManifestEntry describes a file of a zip bundle: its entry name, the size and SHA-256 of
+// its content, its detected language and the metadata the other formats render with it.
+type ZipManifestEntry struct {
+	Path     string            `json:"path"`
+	Size     int64             `json:"size"`
+	SHA256   string            `json:"sha256"`
+	Language string            `json:"language,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// ZipWriter handles zip output: a shareable source archive holding every selected file at its
+// relative path with the content the other formats would show, followed by a manifest entry
+// describing the files. Every entry has the zipEpoch modification time, so the same selection
+// produces the same archive.
+type ZipWriter struct {
+	zw       *zip.Writer
+	registry *FileTypeRegistry
+	manifest ZipManifest
+}
+
+// NewZipWriter creates a new zip writer detecting languages with the default registry.
+func NewZipWriter(outFile *os.File) *ZipWriter {
+	return newZipWriter(outFile, getRegistry())
+}
+
+// newZipWriter creates a zip writer for any output detecting languages with registry.
+func newZipWriter(out io.Writer, registry *FileTypeRegistry) *ZipWriter {
+	return &ZipWriter{zw: zip.NewWriter(out), registry: registry}
+}
+
+// Start stores the prefix and suffix for the manifest; an archive has no header to write.
+func (w *ZipWriter) Start(prefix, suffix string) error {
+	w.manifest.Prefix, w.manifest.Suffix = prefix, suffix
+
+	return nil
+}
+
+// StartWithConfig works like Start, recording settings in the manifest.
+func (w *ZipWriter) StartWithConfig(prefix, suffix string, settings map[string]any) error {
+	w.manifest.Config = settings
+
+	return w.Start(prefix, suffix)
+}
+
+// WriteFile adds the file of req to the archive and its entry to the manifest.
+func (w *ZipWriter) WriteFile(req WriteRequest) error {
+	content := io.Reader(strings.NewReader(req.Content))
+	if req.IsStream {
+		defer shared.SafeCloseReader(req.Reader, req.Path)
+		content = req.Reader
+	}
+
+	name := zipEntryName(req.Path)
+	entry, err := w.zw.CreateHeader(zipHeader(name))
+	if err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOWrite, "failed to add zip entry").
+			WithFilePath(req.Path)
+	}
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(entry, hash), content)
+	if err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOWrite, "failed to write zip entry").
+			WithFilePath(req.Path)
+	}
+
+	w.manifest.Files = append(w.manifest.Files, ZipManifestEntry{
+		Path:     name,
+		Size:     size,
+		SHA256:   hex.EncodeToString(hash.Sum(nil)),
+		Language: entryLanguage(req, w.registry),
+		Metadata: req.Metadata,
+	})
+
+	return nil
+}
+
+// Close writes the manifest entry and the central directory of the archive.
+func (w *ZipWriter) Close() error {
+	if w.manifest.Files == nil {
+		w.manifest.Files = []ZipManifestEntry{}
+	}
+	data, err := json.MarshalIndent(w.manifest, "", "  ")
+	if err != nil {
+		return shared.WrapError(err, shared.ErrorTypeProcessing, shared.CodeProcessingEncode, "failed to encode manifest")
+	}
+	entry, err := w.zw.CreateHeader(zipHeader(shared.FormatZipManifest))
+	if err == nil {
+		_, err = entry.Write(append(data, '\n'))
+	}
+	if err == nil {
+		err = w.zw.Close()
+	}
+	if err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOWrite, "failed to write zip manifest")
+	}
+
+	return nil
+}
+
+// CloseWithSummary works like Close, recording the run summary in the manifest.
+func (w *ZipWriter) CloseWithSummary(summary RunSummary) error {
+	w.manifest.Summary = &summary
+
+	return w.Close()
+}
+
+// zipHeader returns the header of the compressed entry name.
+func zipHeader(name string) *zip.FileHeader {
+	return &zip.FileHeader{Name: name, Method: zip.Deflate, Modified: zipEpoch}
+}
+
+// zipEntryName returns the archive entry name of the file at path: slash-separated and
+// relative, so extracting the archive cannot write outside its target directory.
+func zipEntryName(filePath string) string {
+	name := path.Clean("/" + filepath.ToSlash(filePath))
+
+	return strings.TrimPrefix(name, "/")
+}
+
+// startZipWriter handles zip format output.
+func startZipWriter(
+	outFile *os.File,
+	writeCh <-chan WriteRequest,
+	done chan<- struct{},
+	prefix, suffix string,
+	opts WriterOptions,
+) {
+	startFormatWriter(outFile, writeCh, done, prefix, suffix, opts, func(out outputWriter) FormatWriter {
+		return newZipWriter(out, opts.registry())
+	})
+}
@@ -0,0 +1,76 @@
+package fileproc_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/fileproc"
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// TestZipWriter tests the zip format: every file at its relative path with its content,
+// streamed or not, and a manifest entry describing the files, the prefix and the suffix.
+func TestZipWriter(t *testing.T) {
+	testutil.ResetViperConfig(t, "")
+
+	long := strings.Repeat("x := 1\n", shared.BytesPerKB)
+	output := writeWithFormatWorkers(t, shared.FormatZip, 1, []fileproc.WriteRequest{
+		{Path: "main.go", Content: shared.LiteralPackageMain, Metadata: map[string]string{"note": "reviewed"}},
+		{Path: "docs/guide.md", Content: "# Guide\n"},
+		{Path: "../outside.txt", Content: "kept inside\n"},
+		{Path: "large.txt", IsStream: true, Reader: strings.NewReader(long)},
+	})
+
+	archive, err := zip.NewReader(bytes.NewReader(output), int64(len(output)))
+	testutil.MustSucceed(t, err, "opening the archive")
+	entries := make(map[string]string, len(archive.File))
+	for _, file := range archive.File {
+		entries[file.Name] = readZipEntry(t, file)
+	}
+
+	want := map[string]string{
+		"main.go":       shared.LiteralPackageMain,
+		"docs/guide.md": "# Guide\n",
+		"outside.txt":   "kept inside\n",
+		"large.txt":     long,
+	}
+	for name, content := range want {
+		if entries[name] != content {
+			t.Errorf("entry %s = %q, want %q", name, entries[name], content)
+		}
+	}
+
+	var manifest fileproc.ZipManifest
+	testutil.MustSucceed(t, json.Unmarshal([]byte(entries[shared.FormatZipManifest]), &manifest), "decoding manifest")
+	if manifest.Prefix != "prefix" || manifest.Suffix != "suffix" {
+		t.Errorf("manifest prefix and suffix = %q, %q, want prefix, suffix", manifest.Prefix, manifest.Suffix)
+	}
+	if len(manifest.Files) != len(want) {
+		t.Fatalf("manifest lists %d files, want %d", len(manifest.Files), len(want))
+	}
+	first := manifest.Files[0]
+	if first.Path != "main.go" || first.Language != "go" || first.Metadata["note"] != "reviewed" ||
+		first.Size != int64(len(shared.LiteralPackageMain)) || len(first.SHA256) != 64 {
+		t.Errorf("manifest entry of main.go = %+v", first)
+	}
+	if large := manifest.Files[3]; large.Size != int64(len(long)) {
+		t.Errorf("manifest size of large.txt = %d, want %d", large.Size, len(long))
+	}
+}
+
+// readZipEntry returns the content of file.
+func readZipEntry(t *testing.T, file *zip.File) string {
+	t.Helper()
+	reader, err := file.Open()
+	testutil.MustSucceed(t, err, "opening "+file.Name)
+	defer shared.SafeCloseReader(reader, file.Name)
+	data, err := io.ReadAll(reader)
+	testutil.MustSucceed(t, err, "reading "+file.Name)
+
+	return string(data)
+}
@@ -0,0 +1,147 @@
+// Package github fetches pull request data from the GitHub REST API for review bundles.
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+const (
+	// DefaultAPIHost is the host of the public GitHub REST API.
+	DefaultAPIHost = "api.github.com"
+	// DefaultAPIURL is the base URL of the public GitHub REST API.
+	DefaultAPIURL = "https://" + DefaultAPIHost
+	// requestTimeout bounds a single API request.
+	requestTimeout = 30 * time.Second
+	// maxResponseSize caps how much of an API response body is read.
+	maxResponseSize = 32 * shared.BytesPerMB
+)
+
+// tokenEnvVars lists the environment variables checked for an API token, in order.
+var tokenEnvVars = []string{"GITHUB_TOKEN", "GH_TOKEN"}
+
+// TokenFromEnv returns the first API token found in GITHUB_TOKEN or GH_TOKEN.
+func TokenFromEnv() string {
+	for _, name := range tokenEnvVars {
+		if token := strings.TrimSpace(os.Getenv(name)); token != "" {
+			return token
+		}
+	}
+
+	return ""
+}
+
+// Client is a minimal GitHub REST API client.
+type Client struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+// NewClient creates a client for the API at baseURL authenticating with token.
+// An empty baseURL selects DefaultAPIURL; an empty token sends unauthenticated requests.
+func NewClient(baseURL, token string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultAPIURL
+	}
+
+	return &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		token:   token,
+		http:    &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// getJSON performs a GET request against path and decodes the JSON response into out.
+func (c *Client) getJSON(ctx context.Context, path string, out any) error {
+	return c.doJSON(ctx, http.MethodGet, path, nil, http.StatusOK, out)
+}
+
+// postJSON sends in as the JSON body of a POST request against path and decodes the JSON
+// response into out.
+func (c *Client) postJSON(ctx context.Context, path string, in, out any) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOEncoding, "encoding GitHub request")
+	}
+
+	return c.doJSON(ctx, http.MethodPost, path, bytes.NewReader(body), http.StatusCreated, out)
+}
+
+// doJSON performs a request against path and decodes the JSON response into out, failing
+// unless the API answers with status.
+func (c *Client) doJSON(ctx context.Context, method, path string, body io.Reader, status int, out any) error {
+	url := c.baseURL + path
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return shared.WrapError(err, shared.ErrorTypeProcessing, shared.CodeProcessingGitHub, "building request").
+			WithContext("url", url)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req) // #nosec G107 -- URL is built from the configured API base
+	if err != nil {
+		return shared.WrapError(err, shared.ErrorTypeProcessing, shared.CodeProcessingGitHub, "GitHub request failed").
+			WithContext("url", url)
+	}
+	defer shared.SafeCloseReader(resp.Body, url)
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIORead, "reading GitHub response").
+			WithContext("url", url)
+	}
+	if resp.StatusCode != status {
+		return apiError(resp, data, url)
+	}
+
+	if err := json.Unmarshal(data, out); err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOEncoding, "decoding GitHub response").
+			WithContext("url", url)
+	}
+
+	return nil
+}
+
+// apiError converts a non-200 API response into a structured error.
+func apiError(resp *http.Response, body []byte, url string) error {
+	var payload struct {
+		Message string `json:"message"`
+	}
+	_ = json.Unmarshal(body, &payload)
+
+	msg := fmt.Sprintf("GitHub API returned %s", resp.Status)
+	if payload.Message != "" {
+		msg += ": " + payload.Message
+	}
+
+	errType := shared.ErrorTypeProcessing
+	switch resp.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		errType = shared.ErrorTypeConfiguration
+		msg += " (check GITHUB_TOKEN)"
+	case http.StatusNotFound:
+		errType = shared.ErrorTypeValidation
+	}
+
+	return shared.NewStructuredError(errType, shared.CodeProcessingGitHub, msg, "", map[string]any{
+		"url":    url,
+		"status": resp.StatusCode,
+	})
+}
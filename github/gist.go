@@ -0,0 +1,51 @@
+// Package github fetches pull request data from the GitHub REST API for review bundles.
+package github
+
+import (
+	"context"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// Gist is a single-file gist to create.
+type Gist struct {
+	Description string
+	Filename    string
+	Content     string
+	Public      bool
+}
+
+// gistRequest is the body of a create gist request.
+type gistRequest struct {
+	Description string                 `json:"description"`
+	Public      bool                   `json:"public"`
+	Files       map[string]gistContent `json:"files"`
+}
+
+type gistContent struct {
+	Content string `json:"content"`
+}
+
+// CreateGist creates gist and returns its web URL. Creating gists needs a token with the gist scope.
+func (c *Client) CreateGist(ctx context.Context, gist Gist) (string, error) {
+	if c.token == "" {
+		return "", shared.NewStructuredError(
+			shared.ErrorTypeConfiguration, shared.CodeProcessingGitHub,
+			"creating a gist needs a GitHub token (set GITHUB_TOKEN)", "", nil,
+		)
+	}
+
+	request := gistRequest{
+		Description: gist.Description,
+		Public:      gist.Public,
+		Files:       map[string]gistContent{gist.Filename: {Content: gist.Content}},
+	}
+	var created struct {
+		HTMLURL string `json:"html_url"`
+	}
+	if err := c.postJSON(ctx, "/gists", request, &created); err != nil {
+		return "", err
+	}
+
+	return created.HTMLURL, nil
+}
@@ -0,0 +1,131 @@
+package github_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/github"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// TestParsePRURL tests pull request reference parsing.
+func TestParsePRURL(t *testing.T) {
+	want := github.PRRef{Owner: "org", Repo: "repo", Number: 123}
+	tests := []struct {
+		name    string
+		raw     string
+		wantErr bool
+	}{
+		{name: "full URL", raw: "https://github.com/org/repo/pull/123"},
+		{name: "files tab URL", raw: "https://github.com/org/repo/pull/123/files"},
+		{name: "short form", raw: "org/repo#123"},
+		{name: "issue URL", raw: "https://github.com/org/repo/issues/123", wantErr: true},
+		{name: "missing number", raw: "https://github.com/org/repo/pull/", wantErr: true},
+		{name: "not a URL", raw: "pull-123", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := github.ParsePRURL(tt.raw)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParsePRURL(%q) = %v, want error", tt.raw, got)
+				}
+
+				return
+			}
+			testutil.MustSucceed(t, err, "parsing PR URL")
+			if got != want {
+				t.Errorf("ParsePRURL(%q) = %v, want %v", tt.raw, got, want)
+			}
+		})
+	}
+}
+
+// TestLinkedIssues tests closing-keyword issue reference extraction.
+func TestLinkedIssues(t *testing.T) {
+	pr := github.PRRef{Owner: "org", Repo: "repo", Number: 1}
+	body := "Fixes #12 and closes other/lib#3.\nResolves: https://github.com/org/repo/issues/7\n" +
+		"Related to #99. fixes #12 again."
+
+	got := github.LinkedIssues(body, pr)
+	want := []github.IssueRef{
+		{Owner: "org", Repo: "repo", Number: 12},
+		{Owner: "other", Repo: "lib", Number: 3},
+		{Owner: "org", Repo: "repo", Number: 7},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("LinkedIssues() = %v, want %v", got, want)
+	}
+}
+
+// TestClientPullRequestFiles tests pagination and authentication of file listing.
+func TestClientPullRequestFiles(t *testing.T) {
+	const total = 150
+	var pages []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		pages = append(pages, r.URL.Query().Get("page"))
+
+		var files []github.ChangedFile
+		for i := (page - 1) * 100; i < min(page*100, total); i++ {
+			files = append(files, github.ChangedFile{Filename: "f" + strconv.Itoa(i)})
+		}
+		_ = json.NewEncoder(w).Encode(files)
+	}))
+	defer server.Close()
+
+	ref := github.PRRef{Owner: "org", Repo: "repo", Number: 5}
+	files, err := github.NewClient(server.URL, "secret").PullRequestFiles(context.Background(), ref)
+	testutil.MustSucceed(t, err, "listing files")
+	if len(files) != total || !slices.Equal(pages, []string{"1", "2"}) {
+		t.Errorf("got %d files over pages %v, want %d over [1 2]", len(files), pages, total)
+	}
+
+	if _, err := github.NewClient(server.URL, "").PullRequestFiles(context.Background(), ref); err == nil {
+		t.Error("expected error for unauthorized request")
+	}
+}
+
+// TestClientCreateGist tests that a gist is created with its file and visibility, and that
+// creating one needs a token.
+func TestClientCreateGist(t *testing.T) {
+	var got struct {
+		Public bool `json:"public"`
+		Files  map[string]struct {
+			Content string `json:"content"`
+		} `json:"files"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/gists" || r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"html_url":"https://gist.example/abc"}`))
+	}))
+	defer server.Close()
+
+	gist := github.Gist{Filename: "bundle.md", Content: "# Bundle\n"}
+	url, err := github.NewClient(server.URL, "secret").CreateGist(context.Background(), gist)
+	testutil.MustSucceed(t, err, "creating gist")
+	if url != "https://gist.example/abc" || got.Public || got.Files["bundle.md"].Content != "# Bundle\n" {
+		t.Errorf("CreateGist() = %q with request %+v, want the gist URL and a secret bundle.md", url, got)
+	}
+
+	if _, err := github.NewClient(server.URL, "").CreateGist(context.Background(), gist); err == nil {
+		t.Error("expected error creating a gist without a token")
+	}
+}
@@ -0,0 +1,79 @@
+// Package github fetches pull request data from the GitHub REST API for review bundles.
+package github
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// linkedIssuePattern matches GitHub closing keywords followed by an issue reference:
+// "#12", "org/repo#12", or a full https://github.com/org/repo/issues/12 URL.
+var linkedIssuePattern = regexp.MustCompile(
+	`(?i)\b(?:close[sd]?|fix(?:e[sd])?|resolve[sd]?)\s*:?\s+` +
+		`(?:https?://[^/\s]+/([\w.-]+)/([\w.-]+)/issues/(\d+)|(?:([\w.-]+)/([\w.-]+))?#(\d+))`,
+)
+
+// IssueRef identifies an issue.
+type IssueRef struct {
+	Owner  string
+	Repo   string
+	Number int
+}
+
+// String returns the short owner/repo#number form of the reference.
+func (r IssueRef) String() string {
+	return fmt.Sprintf("%s/%s#%d", r.Owner, r.Repo, r.Number)
+}
+
+// Issue is the subset of issue fields used in bundles.
+type Issue struct {
+	Number  int    `json:"number"`
+	Title   string `json:"title"`
+	Body    string `json:"body"`
+	State   string `json:"state"`
+	HTMLURL string `json:"html_url"`
+	User    User   `json:"user"`
+}
+
+// LinkedIssues returns the issues a pull request body closes via closing keywords
+// ("Fixes #12", "closes org/repo#3"), in order of appearance and without duplicates.
+// Bare "#N" references resolve against the pull request's repository.
+func LinkedIssues(body string, pr PRRef) []IssueRef {
+	var refs []IssueRef
+	seen := make(map[IssueRef]bool)
+	for _, m := range linkedIssuePattern.FindAllStringSubmatch(body, -1) {
+		ref := IssueRef{Owner: pr.Owner, Repo: pr.Repo}
+		num := m[6]
+		switch {
+		case m[3] != "":
+			ref.Owner, ref.Repo, num = m[1], m[2], m[3]
+		case m[4] != "":
+			ref.Owner, ref.Repo = m[4], m[5]
+		}
+
+		n, err := strconv.Atoi(num)
+		if err != nil || n <= 0 {
+			continue
+		}
+		ref.Number = n
+		if !seen[ref] {
+			seen[ref] = true
+			refs = append(refs, ref)
+		}
+	}
+
+	return refs
+}
+
+// Issue fetches the issue identified by ref.
+func (c *Client) Issue(ctx context.Context, ref IssueRef) (*Issue, error) {
+	var issue Issue
+	path := fmt.Sprintf("/repos/%s/%s/issues/%d", ref.Owner, ref.Repo, ref.Number)
+	if err := c.getJSON(ctx, path, &issue); err != nil {
+		return nil, err
+	}
+
+	return &issue, nil
+}
@@ -0,0 +1,137 @@
+// Package github fetches pull request data from the GitHub REST API for review bundles.
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+const (
+	// filesPerPage is the page size used when listing pull request files.
+	filesPerPage = 100
+	// maxFilePages caps pagination; the API lists at most 3000 files per pull request.
+	maxFilePages = 30
+)
+
+// PRRef identifies a pull request.
+type PRRef struct {
+	Owner  string
+	Repo   string
+	Number int
+}
+
+// String returns the short owner/repo#number form of the reference.
+func (r PRRef) String() string {
+	return fmt.Sprintf("%s/%s#%d", r.Owner, r.Repo, r.Number)
+}
+
+// ParsePRURL parses a pull request URL such as https://github.com/org/repo/pull/123.
+// The short form org/repo#123 is accepted as well.
+func ParsePRURL(raw string) (PRRef, error) {
+	raw = strings.TrimSpace(raw)
+	if owner, rest, ok := strings.Cut(raw, "/"); ok && !strings.Contains(raw, "://") {
+		if repo, num, ok := strings.Cut(rest, "#"); ok {
+			return newPRRef(raw, owner, repo, num)
+		}
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return PRRef{}, invalidPRURL(raw)
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 4 || parts[2] != "pull" {
+		return PRRef{}, invalidPRURL(raw)
+	}
+
+	return newPRRef(raw, parts[0], parts[1], parts[3])
+}
+
+// newPRRef validates the parsed pieces of a pull request reference.
+func newPRRef(raw, owner, repo, num string) (PRRef, error) {
+	number, err := strconv.Atoi(num)
+	if err != nil || number <= 0 || owner == "" || repo == "" {
+		return PRRef{}, invalidPRURL(raw)
+	}
+
+	return PRRef{Owner: owner, Repo: repo, Number: number}, nil
+}
+
+// invalidPRURL builds the error returned for unparseable pull request references.
+func invalidPRURL(raw string) error {
+	return shared.NewStructuredError(
+		shared.ErrorTypeValidation,
+		shared.CodeCLIInvalidArgs,
+		"invalid pull request URL (expected https://github.com/<owner>/<repo>/pull/<number>): "+raw,
+		"",
+		nil,
+	)
+}
+
+// User is the subset of a GitHub user used in bundles.
+type User struct {
+	Login string `json:"login"`
+}
+
+// Branch is the subset of a pull request base or head used in bundles.
+type Branch struct {
+	Ref string `json:"ref"`
+}
+
+// PullRequest is the subset of pull request fields used in bundles.
+type PullRequest struct {
+	Number  int    `json:"number"`
+	Title   string `json:"title"`
+	Body    string `json:"body"`
+	State   string `json:"state"`
+	HTMLURL string `json:"html_url"`
+	User    User   `json:"user"`
+	Base    Branch `json:"base"`
+	Head    Branch `json:"head"`
+}
+
+// ChangedFile is a file changed by a pull request. Patch is empty for binary or very large diffs.
+type ChangedFile struct {
+	Filename         string `json:"filename"`
+	PreviousFilename string `json:"previous_filename"`
+	Status           string `json:"status"`
+	Additions        int    `json:"additions"`
+	Deletions        int    `json:"deletions"`
+	Patch            string `json:"patch"`
+}
+
+// PullRequest fetches the pull request identified by ref.
+func (c *Client) PullRequest(ctx context.Context, ref PRRef) (*PullRequest, error) {
+	var pr PullRequest
+	path := fmt.Sprintf("/repos/%s/%s/pulls/%d", ref.Owner, ref.Repo, ref.Number)
+	if err := c.getJSON(ctx, path, &pr); err != nil {
+		return nil, err
+	}
+
+	return &pr, nil
+}
+
+// PullRequestFiles lists every file changed by the pull request identified by ref.
+func (c *Client) PullRequestFiles(ctx context.Context, ref PRRef) ([]ChangedFile, error) {
+	var files []ChangedFile
+	for page := 1; page <= maxFilePages; page++ {
+		var batch []ChangedFile
+		path := fmt.Sprintf(
+			"/repos/%s/%s/pulls/%d/files?per_page=%d&page=%d", ref.Owner, ref.Repo, ref.Number, filesPerPage, page,
+		)
+		if err := c.getJSON(ctx, path, &batch); err != nil {
+			return nil, err
+		}
+		files = append(files, batch...)
+		if len(batch) < filesPerPage {
+			break
+		}
+	}
+
+	return files, nil
+}
@@ -0,0 +1,163 @@
+// Package gitutil provides git repository integration for gibidify.
+package gitutil
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"sync"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// BlameSummary holds the number of lines attributed to each author of a file.
+// Authors are keyed as "Name <email>".
+type BlameSummary struct {
+	Authors    map[string]int `json:"authors"`
+	TotalLines int            `json:"totalLines"`
+}
+
+// Share returns the percentage (0-100) of lines attributed to authors accepted by match.
+func (s BlameSummary) Share(match func(author string) bool) float64 {
+	if s.TotalLines == 0 {
+		return 0
+	}
+
+	matched := 0
+	for author, lines := range s.Authors {
+		if match(author) {
+			matched += lines
+		}
+	}
+
+	return float64(matched) * 100 / float64(s.TotalLines)
+}
+
+// ParseBlamePorcelain builds a BlameSummary from `git blame --line-porcelain` output.
+func ParseBlamePorcelain(data []byte) BlameSummary {
+	summary := BlameSummary{Authors: make(map[string]int)}
+
+	var author string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, shared.FileProcessingStreamChunkSize), shared.BytesPerMB)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "author "):
+			author = strings.TrimPrefix(line, "author ")
+		case strings.HasPrefix(line, "author-mail "):
+			key := author + " " + strings.TrimPrefix(line, "author-mail ")
+			summary.Authors[key]++
+			summary.TotalLines++
+		}
+	}
+
+	return summary
+}
+
+// Blamer computes and caches blame summaries for files of a single repository.
+type Blamer struct {
+	git      Git
+	root     string
+	cache    *BlameCache
+	blobs    map[string]string
+	modified map[string]bool
+	mu       sync.Mutex
+}
+
+// NewBlamer creates a Blamer for the repository containing dir.
+// A nil cache disables persistent caching; otherwise entries of files that changed or were
+// removed since they were cached are dropped from it.
+func (g Git) NewBlamer(dir string, cache *BlameCache) (*Blamer, error) {
+	root, err := g.RepoRoot(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &Blamer{git: g, root: root, cache: cache}
+	if err := b.loadIndexState(); err != nil {
+		return nil, err
+	}
+	if cache != nil {
+		cache.Retain(b.blobs)
+	}
+
+	return b, nil
+}
+
+// Root returns the repository top-level directory.
+func (b *Blamer) Root() string {
+	return b.root
+}
+
+// Summary returns the blame summary for the given file path.
+// Untracked files return an empty summary.
+func (b *Blamer) Summary(path string) (BlameSummary, error) {
+	rel, err := relativeTo(b.root, path)
+	if err != nil {
+		return BlameSummary{}, err
+	}
+
+	b.mu.Lock()
+	blob, tracked := b.blobs[rel]
+	cacheable := tracked && !b.modified[rel] && b.cache != nil
+	b.mu.Unlock()
+
+	if !tracked {
+		return BlameSummary{Authors: map[string]int{}}, nil
+	}
+	if cacheable {
+		if summary, ok := b.cache.Get(rel, blob); ok {
+			return summary, nil
+		}
+	}
+
+	out, err := b.git.Run(b.root, "blame", "--line-porcelain", "--", rel)
+	if err != nil {
+		return BlameSummary{}, err
+	}
+
+	summary := ParseBlamePorcelain(out)
+	if cacheable {
+		b.cache.Put(rel, blob, summary)
+	}
+
+	return summary, nil
+}
+
+// loadIndexState records the blob IDs of tracked files and which of them have local modifications.
+func (b *Blamer) loadIndexState() error {
+	staged, err := b.git.Run(b.root, "ls-files", "-s", "-z")
+	if err != nil {
+		return err
+	}
+
+	b.blobs = make(map[string]string)
+	for _, entry := range bytes.Split(staged, []byte{0}) {
+		meta, path, ok := strings.Cut(string(entry), "\t")
+		if !ok {
+			continue
+		}
+		if fields := strings.Fields(meta); len(fields) >= 2 {
+			b.blobs[path] = fields[1]
+		}
+	}
+
+	changed, err := b.git.Run(b.root, "diff", "--name-only", "-z", "HEAD")
+	if err != nil {
+		// A repository without commits has no HEAD; treat everything as modified.
+		changed = nil
+		for path := range b.blobs {
+			changed = append(changed, []byte(path+"\x00")...)
+		}
+	}
+
+	b.modified = make(map[string]bool)
+	for _, path := range bytes.Split(changed, []byte{0}) {
+		if len(path) > 0 {
+			b.modified[string(path)] = true
+		}
+	}
+
+	return nil
+}
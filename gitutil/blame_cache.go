@@ -0,0 +1,144 @@
+// Package gitutil provides git repository integration for gibidify.
+package gitutil
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// blameCacheVersion is bumped whenever the on-disk cache layout changes.
+const blameCacheVersion = 2
+
+// BlameCache stores blame summaries keyed by file path and git blob ID. Blob IDs are
+// content-addressed, so entries never go stale for unmodified files; the path tells apart
+// files with the same content but a different history. Entries of files since changed or
+// removed are dropped by Retain.
+type BlameCache struct {
+	path    string
+	entries map[string]BlameSummary
+	dirty   bool
+	mu      sync.RWMutex
+}
+
+type blameCacheFile struct {
+	Version int                     `json:"version"`
+	Entries map[string]BlameSummary `json:"entries"`
+}
+
+// NewBlameCache creates an in-memory cache. An empty path disables persistence.
+func NewBlameCache(path string) *BlameCache {
+	return &BlameCache{path: path, entries: make(map[string]BlameSummary)}
+}
+
+// DefaultBlameCachePath returns the cache file location for the repository rooted at root,
+// keyed by the digest of root computed with the named hash algorithm.
+func DefaultBlameCachePath(root, algorithm string) (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", shared.WrapError(err, shared.ErrorTypeFileSystem, shared.CodeFSPathResolution, "locating cache dir")
+	}
+
+	name := "blame-" + shared.ShortChecksum(algorithm, []byte(root), 8) + ".json"
+
+	return filepath.Join(base, shared.AppName, name), nil
+}
+
+// LoadBlameCache reads the cache at path. A missing or unreadable cache yields an empty one.
+func LoadBlameCache(path string) *BlameCache {
+	cache := NewBlameCache(path)
+
+	data, err := os.ReadFile(path) // #nosec G304 -- path is derived from the user cache dir
+	if err != nil {
+		return cache
+	}
+
+	var file blameCacheFile
+	if err := json.Unmarshal(data, &file); err != nil || file.Version != blameCacheVersion {
+		shared.GetLogger().Debugf("Ignoring incompatible blame cache %s", path)
+
+		return cache
+	}
+	if file.Entries != nil {
+		cache.entries = file.Entries
+	}
+
+	return cache
+}
+
+// Get returns the cached summary for the file at path, relative to the repository root, with
+// content blob.
+func (c *BlameCache) Get(path, blob string) (BlameSummary, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	summary, ok := c.entries[blameCacheKey(path, blob)]
+
+	return summary, ok
+}
+
+// Put stores the summary for the file at path, relative to the repository root, with content blob.
+func (c *BlameCache) Put(path, blob string, summary BlameSummary) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[blameCacheKey(path, blob)] = summary
+	c.dirty = true
+}
+
+// Retain drops the entries of files that are not in blobs, which maps the paths of the tracked
+// files, relative to the repository root, to their current blob IDs, or whose blob differs.
+func (c *BlameCache) Retain(blobs map[string]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key := range c.entries {
+		blob, path, _ := strings.Cut(key, ":")
+		if current, ok := blobs[path]; !ok || current != blob {
+			delete(c.entries, key)
+			c.dirty = true
+		}
+	}
+}
+
+// blameCacheKey returns the cache key of the file at path with content blob, in the
+// <blob>:<path> form git uses for tree entries.
+func blameCacheKey(path, blob string) string {
+	return blob + ":" + filepath.ToSlash(path)
+}
+
+// Save writes the cache to disk if it has a path and was modified.
+func (c *BlameCache) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.path == "" || !c.dirty {
+		return nil
+	}
+
+	data, err := json.Marshal(blameCacheFile{Version: blameCacheVersion, Entries: c.entries})
+	if err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOEncoding, "encoding blame cache")
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o750); err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOFileCreate, "creating cache dir").
+			WithFilePath(c.path)
+	}
+
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOFileWrite, "writing blame cache").
+			WithFilePath(c.path)
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOFileWrite, "replacing blame cache").
+			WithFilePath(c.path)
+	}
+	c.dirty = false
+
+	return nil
+}
@@ -0,0 +1,189 @@
+package gitutil_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/gitutil"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+const samplePorcelain = `abc123 1 1 2
+author Alice
+author-mail <alice@example.com>
+author-time 1700000000
+summary initial
+filename main.go
+	package main
+abc123 2 2
+author Alice
+author-mail <alice@example.com>
+filename main.go
+	
+def456 3 3 1
+author Bob
+author-mail <bob@example.com>
+filename main.go
+	func main() {}
+`
+
+// TestParseBlamePorcelain tests parsing of line-porcelain blame output.
+func TestParseBlamePorcelain(t *testing.T) {
+	summary := gitutil.ParseBlamePorcelain([]byte(samplePorcelain))
+
+	if summary.TotalLines != 3 {
+		t.Fatalf("TotalLines = %d, want 3", summary.TotalLines)
+	}
+	if got := summary.Authors["Alice <alice@example.com>"]; got != 2 {
+		t.Errorf("Alice lines = %d, want 2", got)
+	}
+	if got := summary.Authors["Bob <bob@example.com>"]; got != 1 {
+		t.Errorf("Bob lines = %d, want 1", got)
+	}
+}
+
+// TestBlameSummaryShare tests the authorship percentage calculation.
+func TestBlameSummaryShare(t *testing.T) {
+	summary := gitutil.BlameSummary{
+		Authors:    map[string]int{"Alice <a@x>": 3, "Bob <b@x>": 1},
+		TotalLines: 4,
+	}
+
+	tests := []struct {
+		name   string
+		needle string
+		want   float64
+	}{
+		{name: "majority author", needle: "Alice", want: 75},
+		{name: "minority author", needle: "Bob", want: 25},
+		{name: "unknown author", needle: "Carol", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := summary.Share(func(author string) bool { return strings.Contains(author, tt.needle) })
+			if got != tt.want {
+				t.Errorf("Share(%s) = %v, want %v", tt.needle, got, tt.want)
+			}
+		})
+	}
+
+	if got := (gitutil.BlameSummary{}).Share(func(string) bool { return true }); got != 0 {
+		t.Errorf("empty summary share = %v, want 0", got)
+	}
+}
+
+// TestBlamerSummary tests blaming files in a real repository and caching the result.
+func TestBlamerSummary(t *testing.T) {
+	dir := t.TempDir()
+	testutil.InitGitRepo(t, dir)
+	testutil.CreateTestFile(t, dir, "alice.go", []byte("a\nb\nc\n"))
+	testutil.GitCommitAs(t, dir, "Alice", "alice@example.com", "alice")
+	testutil.CreateTestFile(t, dir, "untracked.go", []byte("x\n"))
+
+	cachePath := filepath.Join(t.TempDir(), "blame.json")
+	cache := gitutil.NewBlameCache(cachePath)
+	blamer, err := gitutil.Git{}.NewBlamer(dir, cache)
+	testutil.MustSucceed(t, err, "creating blamer")
+
+	summary, err := blamer.Summary(filepath.Join(dir, "alice.go"))
+	testutil.MustSucceed(t, err, "blaming tracked file")
+	if summary.Authors["Alice <alice@example.com>"] != 3 {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+
+	untracked, err := blamer.Summary(filepath.Join(dir, "untracked.go"))
+	testutil.MustSucceed(t, err, "blaming untracked file")
+	if untracked.TotalLines != 0 {
+		t.Errorf("untracked file should have empty summary, got %+v", untracked)
+	}
+
+	testutil.MustSucceed(t, cache.Save(), "saving cache")
+	reloaded := gitutil.LoadBlameCache(cachePath)
+	blob := strings.Fields(testutil.RunGit(t, dir, "rev-parse", "HEAD:alice.go"))[0]
+	if cached, ok := reloaded.Get("alice.go", blob); !ok || cached.TotalLines != 3 {
+		t.Errorf("expected cached summary for blob %s, got %+v (found=%v)", blob, cached, ok)
+	}
+}
+
+// TestBlamerPrunesCache tests that cached summaries of files changed or removed since they
+// were cached are dropped when a blamer is created, and that the pruned cache is saved.
+func TestBlamerPrunesCache(t *testing.T) {
+	dir := t.TempDir()
+	testutil.InitGitRepo(t, dir)
+	testutil.CreateTestFile(t, dir, "kept.go", []byte("kept\n"))
+	testutil.CreateTestFile(t, dir, "changed.go", []byte("old\n"))
+	testutil.CreateTestFile(t, dir, "removed.go", []byte("removed\n"))
+	testutil.GitCommitAs(t, dir, "Alice", "alice@example.com", "initial")
+
+	blobOf := func(file string) string {
+		return strings.Fields(testutil.RunGit(t, dir, "rev-parse", "HEAD:"+file))[0]
+	}
+	kept, changed, removed := blobOf("kept.go"), blobOf("changed.go"), blobOf("removed.go")
+
+	cachePath := filepath.Join(t.TempDir(), "blame.json")
+	cache := gitutil.NewBlameCache(cachePath)
+	blamer, err := gitutil.Git{}.NewBlamer(dir, cache)
+	testutil.MustSucceed(t, err, "creating blamer")
+	for _, file := range []string{"kept.go", "changed.go", "removed.go"} {
+		_, err := blamer.Summary(filepath.Join(dir, file))
+		testutil.MustSucceed(t, err, "blaming "+file)
+	}
+	testutil.MustSucceed(t, cache.Save(), "saving cache")
+
+	testutil.CreateTestFile(t, dir, "changed.go", []byte("new\n"))
+	testutil.MustSucceed(t, os.Remove(filepath.Join(dir, "removed.go")), "removing file")
+	testutil.GitCommitAs(t, dir, "Bob", "bob@example.com", "update")
+
+	cache = gitutil.LoadBlameCache(cachePath)
+	_, err = gitutil.Git{}.NewBlamer(dir, cache)
+	testutil.MustSucceed(t, err, "creating blamer")
+	testutil.MustSucceed(t, cache.Save(), "saving pruned cache")
+
+	reloaded := gitutil.LoadBlameCache(cachePath)
+	if _, ok := reloaded.Get("kept.go", kept); !ok {
+		t.Error("summary of the unchanged file was dropped")
+	}
+	if _, ok := reloaded.Get("changed.go", changed); ok {
+		t.Error("summary of the changed file's old blob was kept")
+	}
+	if _, ok := reloaded.Get("removed.go", removed); ok {
+		t.Error("summary of the removed file was kept")
+	}
+}
+
+// TestBlamerSummaryIdenticalFiles tests that files with the same content but a different history
+// keep their own cached summaries.
+func TestBlamerSummaryIdenticalFiles(t *testing.T) {
+	dir := t.TempDir()
+	testutil.InitGitRepo(t, dir)
+	testutil.CreateTestFile(t, dir, "alice.go", []byte("same\n"))
+	testutil.GitCommitAs(t, dir, "Alice", "alice@example.com", "alice")
+	testutil.CreateTestFile(t, dir, "bob.go", []byte("same\n"))
+	testutil.GitCommitAs(t, dir, "Bob", "bob@example.com", "bob")
+
+	cache := gitutil.NewBlameCache("")
+	for range 2 {
+		blamer, err := gitutil.Git{}.NewBlamer(dir, cache)
+		testutil.MustSucceed(t, err, "creating blamer")
+		for file, author := range map[string]string{"alice.go": "Alice", "bob.go": "Bob"} {
+			summary, err := blamer.Summary(filepath.Join(dir, file))
+			testutil.MustSucceed(t, err, "blaming "+file)
+			if summary.Authors[author+" <"+strings.ToLower(author)+"@example.com>"] != 1 {
+				t.Errorf("%s summary = %+v, want %s", file, summary, author)
+			}
+		}
+	}
+}
+
+// TestNewBlamerOutsideRepository tests that a non-repository directory is rejected.
+func TestNewBlamerOutsideRepository(t *testing.T) {
+	testutil.RequireGit(t)
+	t.Setenv("GIT_CEILING_DIRECTORIES", filepath.Dir(t.TempDir()))
+
+	if _, err := (gitutil.Git{}).NewBlamer(t.TempDir(), nil); err == nil {
+		t.Error("expected error for directory outside a git repository")
+	}
+}
@@ -0,0 +1,59 @@
+// Package gitutil provides git repository integration for gibidify.
+package gitutil
+
+import (
+	"bufio"
+	"bytes"
+	"time"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// Churn holds the number of commits that touched each file of a repository within a window.
+type Churn struct {
+	root    string
+	commits map[string]int
+}
+
+// LoadChurn counts the commits since since that touched each file of the repository
+// containing dir. Merge commits are not counted; renamed files count under their new path.
+func (g Git) LoadChurn(dir string, since time.Time) (*Churn, error) {
+	root, err := g.RepoRoot(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := g.Run(root, "-c", "core.quotePath=false", "log", "--no-merges", "--format=",
+		"--name-only", "--since="+since.UTC().Format(time.RFC3339))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Churn{root: root, commits: ParseChurnLog(out)}, nil
+}
+
+// ParseChurnLog counts the commits of `git log --format= --name-only` output per file: every
+// non-empty line names a file one commit touched.
+func ParseChurnLog(data []byte) map[string]int {
+	commits := make(map[string]int)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, shared.FileProcessingStreamChunkSize), shared.BytesPerMB)
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" {
+			commits[line]++
+		}
+	}
+
+	return commits
+}
+
+// Commits returns the number of commits in the window that touched the file at path.
+func (c *Churn) Commits(path string) int {
+	rel, err := relativeTo(c.root, path)
+	if err != nil {
+		return 0
+	}
+
+	return c.commits[rel]
+}
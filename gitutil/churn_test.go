@@ -0,0 +1,48 @@
+package gitutil_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ivuorinen/gibidify/gitutil"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// TestParseChurnLog tests counting commits per file in name-only log output.
+func TestParseChurnLog(t *testing.T) {
+	log := "\nmain.go\nREADME.md\n\nmain.go\n\npkg/util.go\nmain.go\n"
+	commits := gitutil.ParseChurnLog([]byte(log))
+
+	want := map[string]int{"main.go": 3, "README.md": 1, "pkg/util.go": 1}
+	if len(commits) != len(want) {
+		t.Fatalf("ParseChurnLog() = %v, want %v", commits, want)
+	}
+	for path, n := range want {
+		if commits[path] != n {
+			t.Errorf("commits[%s] = %d, want %d", path, commits[path], n)
+		}
+	}
+}
+
+// TestLoadChurn tests counting the commits of a real repository within a window.
+func TestLoadChurn(t *testing.T) {
+	dir := t.TempDir()
+	testutil.InitGitRepo(t, dir)
+	testutil.CreateTestFile(t, dir, "main.go", []byte("package main\n"))
+	testutil.GitCommitAs(t, dir, "Alice", "alice@example.com", "add main")
+	testutil.CreateTestFile(t, dir, "main.go", []byte("package main\n\nfunc main() {}\n"))
+	testutil.GitCommitAs(t, dir, "Alice", "alice@example.com", "add func")
+
+	churn, err := gitutil.Git{}.LoadChurn(dir, time.Now().Add(-time.Hour))
+	testutil.MustSucceed(t, err, "loading churn")
+	if got := churn.Commits(filepath.Join(dir, "main.go")); got != 2 {
+		t.Errorf("Commits(main.go) = %d, want 2", got)
+	}
+
+	future, err := gitutil.Git{}.LoadChurn(dir, time.Now().Add(time.Hour))
+	testutil.MustSucceed(t, err, "loading churn")
+	if got := future.Commits(filepath.Join(dir, "main.go")); got != 0 {
+		t.Errorf("Commits(main.go) after the last commit = %d, want 0", got)
+	}
+}
@@ -0,0 +1,123 @@
+// Package gitutil provides git repository integration for gibidify.
+package gitutil
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	ignore "github.com/sabhiram/go-gitignore"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// CodeOwnersLocations lists where CODEOWNERS files are searched, relative to the repository root,
+// in the order GitHub and GitLab consult them.
+var CodeOwnersLocations = []string{
+	".github/CODEOWNERS",
+	"CODEOWNERS",
+	"docs/CODEOWNERS",
+	".gitlab/CODEOWNERS",
+}
+
+// codeOwnersRule associates a gitignore-style pattern with its owners.
+type codeOwnersRule struct {
+	pattern string
+	matcher *ignore.GitIgnore
+	owners  []string
+}
+
+// CodeOwners resolves file ownership from a parsed CODEOWNERS file.
+type CodeOwners struct {
+	root  string
+	rules []codeOwnersRule
+}
+
+// FindCodeOwners returns the path of the first CODEOWNERS file found under root, or "" if none exists.
+func FindCodeOwners(root string) string {
+	for _, location := range CodeOwnersLocations {
+		path := filepath.Join(root, filepath.FromSlash(location))
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+
+	return ""
+}
+
+// LoadCodeOwners parses the CODEOWNERS file at path; patterns are resolved relative to root.
+func LoadCodeOwners(path, root string) (*CodeOwners, error) {
+	file, err := os.Open(path) // #nosec G304 -- path is a CODEOWNERS file chosen by the user or discovery
+	if err != nil {
+		return nil, shared.WrapError(err, shared.ErrorTypeFileSystem, shared.CodeFSAccess, "opening CODEOWNERS").
+			WithFilePath(path)
+	}
+	defer shared.SafeCloseReader(file, path)
+
+	owners, err := ParseCodeOwners(file, root)
+	if err != nil {
+		return nil, shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIORead, "reading CODEOWNERS").
+			WithFilePath(path)
+	}
+
+	return owners, nil
+}
+
+// ParseCodeOwners parses CODEOWNERS content. Blank lines, comments, and GitLab section headers are skipped.
+func ParseCodeOwners(r io.Reader, root string) (*CodeOwners, error) {
+	co := &CodeOwners{root: root}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "[") || strings.HasPrefix(line, "^[") {
+			continue
+		}
+		if idx := strings.Index(line, " #"); idx >= 0 {
+			line = line[:idx]
+		}
+
+		fields := strings.Fields(line)
+		co.rules = append(co.rules, codeOwnersRule{
+			pattern: fields[0],
+			matcher: ignore.CompileIgnoreLines(fields[0]),
+			owners:  fields[1:],
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return co, nil
+}
+
+// Owners returns the owners of the file at path (absolute, or relative to the root).
+// The last matching rule wins, as on GitHub; a matching rule without owners leaves the file unowned.
+func (co *CodeOwners) Owners(path string) []string {
+	rel := filepath.ToSlash(path)
+	if filepath.IsAbs(path) {
+		r, err := relativeTo(co.root, path)
+		if err != nil {
+			return nil
+		}
+		rel = r
+	}
+
+	for i := len(co.rules) - 1; i >= 0; i-- {
+		if co.rules[i].matcher.MatchesPath(rel) {
+			return co.rules[i].owners
+		}
+	}
+
+	return nil
+}
+
+// IsOwnedBy reports whether owner (case-insensitive, e.g. "@org/team") owns the file at path.
+func (co *CodeOwners) IsOwnedBy(path, owner string) bool {
+	return slices.ContainsFunc(co.Owners(path), func(o string) bool {
+		return strings.EqualFold(o, owner)
+	})
+}
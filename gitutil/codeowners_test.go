@@ -0,0 +1,76 @@
+package gitutil_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/gitutil"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+const sampleCodeOwners = `# Default owners
+*       @org/everyone
+
+[Frontend]
+/web/   @org/frontend # inline comment
+*.go    @org/backend @alice
+/docs/generated/
+`
+
+// TestCodeOwnersOwners tests last-match-wins ownership resolution.
+func TestCodeOwnersOwners(t *testing.T) {
+	co, err := gitutil.ParseCodeOwners(strings.NewReader(sampleCodeOwners), "/repo")
+	testutil.MustSucceed(t, err, "parsing CODEOWNERS")
+
+	tests := []struct {
+		name string
+		path string
+		want []string
+	}{
+		{name: "default rule", path: "README.md", want: []string{"@org/everyone"}},
+		{name: "directory rule", path: "web/app.js", want: []string{"@org/frontend"}},
+		{name: "extension rule overrides directory", path: "web/server.go", want: []string{"@org/backend", "@alice"}},
+		{name: "absolute path", path: "/repo/cmd/main.go", want: []string{"@org/backend", "@alice"}},
+		{name: "explicitly unowned", path: "docs/generated/api.md", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := co.Owners(tt.path); !slices.Equal(got, tt.want) {
+				t.Errorf("Owners(%s) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+
+	if !co.IsOwnedBy("web/app.js", "@ORG/Frontend") {
+		t.Error("IsOwnedBy should match owners case-insensitively")
+	}
+	if co.IsOwnedBy("web/app.js", "@org/backend") {
+		t.Error("IsOwnedBy matched an owner of a different rule")
+	}
+}
+
+// TestFindCodeOwners tests CODEOWNERS discovery order.
+func TestFindCodeOwners(t *testing.T) {
+	root := t.TempDir()
+	if got := gitutil.FindCodeOwners(root); got != "" {
+		t.Errorf("expected no CODEOWNERS, got %s", got)
+	}
+
+	testutil.CreateTestFile(t, root, "CODEOWNERS", []byte("* @root\n"))
+	testutil.MustSucceed(t, os.Mkdir(filepath.Join(root, ".github"), 0o750), "creating .github")
+	preferred := testutil.CreateTestFile(t, root, filepath.Join(".github", "CODEOWNERS"), []byte("* @gh\n"))
+
+	if got := gitutil.FindCodeOwners(root); got != preferred {
+		t.Errorf("FindCodeOwners() = %s, want %s", got, preferred)
+	}
+
+	co, err := gitutil.LoadCodeOwners(preferred, root)
+	testutil.MustSucceed(t, err, "loading CODEOWNERS")
+	if got := co.Owners(filepath.Join(root, "x.txt")); !slices.Equal(got, []string{"@gh"}) {
+		t.Errorf("unexpected owners %v", got)
+	}
+}
@@ -0,0 +1,130 @@
+// Package gitutil provides git repository integration for gibidify.
+package gitutil
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// gitBinary is the name of the git executable looked up in PATH.
+const gitBinary = "git"
+
+// isolatedEnv is added to the environment of the commands of an isolated Git.
+var isolatedEnv = []string{
+	"GIT_CONFIG_NOSYSTEM=1",
+	"GIT_CONFIG_GLOBAL=" + os.DevNull,
+	"GIT_TERMINAL_PROMPT=0",
+	"GIT_OPTIONAL_LOCKS=0",
+}
+
+// Git runs git commands. The zero value runs them with the caller's environment; an Isolated
+// Git makes them ignore the user and system git configuration, never prompt for credentials
+// and skip optional lock files, so they read only the repository.
+type Git struct {
+	Isolated bool
+}
+
+// Available reports whether a git executable can be found in PATH.
+func Available() bool {
+	_, err := exec.LookPath(gitBinary)
+
+	return err == nil
+}
+
+// Run executes git with the given arguments inside dir and returns its standard output.
+func (g Git) Run(dir string, args ...string) ([]byte, error) {
+	return g.RunInput(dir, nil, args...)
+}
+
+// RunInput executes git like Run, with input as its standard input.
+func (g Git) RunInput(dir string, input []byte, args ...string) ([]byte, error) {
+	cmd := exec.Command(gitBinary, args...) // #nosec G204 -- arguments are built internally
+	cmd.Dir = dir
+	if input != nil {
+		cmd.Stdin = bytes.NewReader(input)
+	}
+	if g.Isolated {
+		cmd.Env = append(os.Environ(), isolatedEnv...)
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = "git " + strings.Join(args, " ") + " failed"
+		}
+
+		return nil, shared.WrapError(err, shared.ErrorTypeProcessing, shared.CodeProcessingGit, msg).WithFilePath(dir)
+	}
+
+	return out, nil
+}
+
+// RepoRoot returns the absolute top-level directory of the repository containing dir.
+func (g Git) RepoRoot(dir string) (string, error) {
+	out, err := g.Run(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Clean(strings.TrimSpace(string(out))), nil
+}
+
+// GlobalExcludesFile returns the path of the user's global excludes file: core.excludesFile as
+// git reads it in dir, or $XDG_CONFIG_HOME/git/ignore (~/.config/git/ignore when XDG_CONFIG_HOME
+// is unset), which git falls back to. An isolated Git returns "". The file may not exist.
+func (g Git) GlobalExcludesFile(dir string) string {
+	if g.Isolated {
+		return ""
+	}
+	if Available() {
+		// git config exits with status 1 when the key is not set
+		if out, err := g.Run(dir, "config", "--path", "--get", "core.excludesFile"); err == nil {
+			if path := strings.TrimSpace(string(out)); path != "" {
+				return path
+			}
+		}
+	}
+
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		return filepath.Join(xdg, "git", "ignore")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(home, ".config", "git", "ignore")
+}
+
+// relativeTo converts path into a slash-separated path relative to root,
+// resolving symlinks so that paths under symlinked temp dirs still match.
+func relativeTo(root, path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", shared.WrapError(err, shared.ErrorTypeFileSystem, shared.CodeFSPathResolution, "resolving path").
+			WithFilePath(path)
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		abs = resolved
+	}
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+
+	rel, err := filepath.Rel(root, abs)
+	if err != nil {
+		return "", shared.WrapError(err, shared.ErrorTypeFileSystem, shared.CodeFSPathResolution, "path outside root").
+			WithFilePath(path)
+	}
+
+	return filepath.ToSlash(rel), nil
+}
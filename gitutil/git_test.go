@@ -0,0 +1,33 @@
+package gitutil_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/gitutil"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// TestGlobalExcludesFile tests that core.excludesFile is preferred over the XDG default.
+func TestGlobalExcludesFile(t *testing.T) {
+	testutil.RequireGit(t)
+	xdg := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	if got, want := (gitutil.Git{}).GlobalExcludesFile(t.TempDir()), filepath.Join(xdg, "git", "ignore"); got != want {
+		t.Errorf("GlobalExcludesFile() = %q without core.excludesFile, want %q", got, want)
+	}
+
+	excludes := filepath.Join(t.TempDir(), "excludes")
+	gitConfig := testutil.CreateTestFile(t, t.TempDir(), "gitconfig", []byte("[core]\n\texcludesFile = "+excludes+"\n"))
+	t.Setenv("GIT_CONFIG_GLOBAL", gitConfig)
+	if got := (gitutil.Git{}).GlobalExcludesFile(t.TempDir()); got != excludes {
+		t.Errorf("GlobalExcludesFile() = %q, want core.excludesFile %q", got, excludes)
+	}
+	if got := (gitutil.Git{Isolated: true}).GlobalExcludesFile(t.TempDir()); got != "" {
+		t.Errorf("isolated GlobalExcludesFile() = %q, want none", got)
+	}
+}
@@ -0,0 +1,68 @@
+// Package gitutil provides git repository integration for gibidify.
+package gitutil
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// LFSPointerMaxSize is the size of the largest Git LFS pointer file; the specification keeps
+// pointers under 1024 bytes.
+const LFSPointerMaxSize = 1024
+
+// lfsPointerVersion is the first line of every Git LFS pointer file.
+const lfsPointerVersion = "version https://git-lfs.github.com/spec/v1\n"
+
+// lfsOIDPattern matches the object ID of a Git LFS pointer.
+var lfsOIDPattern = regexp.MustCompile(`^sha256:[0-9a-f]{64}$`)
+
+// LFSPointer is a Git LFS pointer file, which stands in for the object it names in the
+// repository.
+type LFSPointer struct {
+	// OID is the object ID, e.g. sha256:4d7a...
+	OID string
+	// Size is the size of the object in bytes.
+	Size int64
+}
+
+// ParseLFSPointer parses content as a Git LFS pointer file and reports whether it is one.
+func ParseLFSPointer(content []byte) (LFSPointer, bool) {
+	text, ok := strings.CutPrefix(string(content), lfsPointerVersion)
+	if !ok || len(content) > LFSPointerMaxSize {
+		return LFSPointer{}, false
+	}
+
+	var pointer LFSPointer
+	sized := false
+	for line := range strings.SplitSeq(strings.TrimSuffix(text, "\n"), "\n") {
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "oid":
+			pointer.OID = value
+		case "size":
+			size, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || size < 0 {
+				return LFSPointer{}, false
+			}
+			pointer.Size, sized = size, true
+		}
+	}
+
+	return pointer, sized && lfsOIDPattern.MatchString(pointer.OID)
+}
+
+// LFSSmudge returns the content of the Git LFS object pointer names, as git lfs smudge run in
+// dir checks it out: from the local LFS cache, or downloaded from the remote. An isolated Git
+// refuses, since downloading reaches beyond the repository.
+func (g Git) LFSSmudge(dir string, pointer []byte) ([]byte, error) {
+	if g.Isolated {
+		return nil, shared.NewStructuredError(
+			shared.ErrorTypeProcessing, shared.CodeProcessingGit, "git lfs smudge is disabled in isolated runs", dir, nil,
+		)
+	}
+
+	return g.RunInput(dir, pointer, "lfs", "smudge")
+}
@@ -0,0 +1,50 @@
+package gitutil_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/gitutil"
+)
+
+// TestParseLFSPointer tests that only well-formed Git LFS pointer files are recognized.
+func TestParseLFSPointer(t *testing.T) {
+	oid := "sha256:" + strings.Repeat("4d7a", 16)
+	tests := []struct {
+		name    string
+		content string
+		want    gitutil.LFSPointer
+		wantOK  bool
+	}{
+		{
+			name:    "pointer",
+			content: "version https://git-lfs.github.com/spec/v1\noid " + oid + "\nsize 12345\n",
+			want:    gitutil.LFSPointer{OID: oid, Size: 12345},
+			wantOK:  true,
+		},
+		{
+			name:    "extension lines",
+			content: "version https://git-lfs.github.com/spec/v1\next-0-foo sha256:00\noid " + oid + "\nsize 0\n",
+			want:    gitutil.LFSPointer{OID: oid},
+			wantOK:  true,
+		},
+		{name: "other version", content: "version https://hawser.github.com/spec/v1\noid " + oid + "\nsize 1\n"},
+		{name: "short oid", content: "version https://git-lfs.github.com/spec/v1\noid sha256:4d7a\nsize 1\n"},
+		{name: "no size", content: "version https://git-lfs.github.com/spec/v1\noid " + oid + "\n"},
+		{name: "bad size", content: "version https://git-lfs.github.com/spec/v1\noid " + oid + "\nsize -1\n"},
+		{
+			name:    "too large",
+			content: "version https://git-lfs.github.com/spec/v1\noid " + oid + "\nsize 1\n" + strings.Repeat("#", 1024),
+		},
+		{name: "text", content: "package main\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := gitutil.ParseLFSPointer([]byte(tt.content))
+			if ok != tt.wantOK || (ok && got != tt.want) {
+				t.Errorf("ParseLFSPointer() = %+v, %v, want %+v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
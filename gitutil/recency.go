@@ -0,0 +1,75 @@
+// Package gitutil provides git repository integration for gibidify.
+package gitutil
+
+import (
+	"bufio"
+	"bytes"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// recencyCommitMarker starts the lines of `git log` output holding a commit time, so they cannot
+// be mistaken for file names.
+const recencyCommitMarker = "\x00"
+
+// Recency holds the time of the last commit that touched each file of a repository.
+type Recency struct {
+	root        string
+	lastCommits map[string]time.Time
+}
+
+// LoadRecency reads the time of the last non-merge commit touching each file of the repository
+// containing dir. Renamed files count under their new path.
+func (g Git) LoadRecency(dir string) (*Recency, error) {
+	root, err := g.RepoRoot(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := g.Run(root, "-c", "core.quotePath=false", "log", "--no-merges", "--format=%x00%ct", "--name-only")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Recency{root: root, lastCommits: ParseRecencyLog(out)}, nil
+}
+
+// ParseRecencyLog returns the time of the newest commit naming each file in the output of
+// `git log --format=%x00%ct --name-only`, which lists commits newest first: a NUL-prefixed
+// line holds the commit time in Unix seconds and the non-empty lines after it name its files.
+func ParseRecencyLog(data []byte) map[string]time.Time {
+	lastCommits := make(map[string]time.Time)
+
+	var current time.Time
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, shared.FileProcessingStreamChunkSize), shared.BytesPerMB)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if stamp, ok := strings.CutPrefix(line, recencyCommitMarker); ok {
+			if seconds, err := strconv.ParseInt(stamp, 10, 64); err == nil {
+				current = time.Unix(seconds, 0)
+			}
+
+			continue
+		}
+		if _, seen := lastCommits[line]; line != "" && !seen {
+			lastCommits[line] = current
+		}
+	}
+
+	return lastCommits
+}
+
+// LastCommit returns the time of the last commit that touched the file at path, or the zero
+// time when no commit did.
+func (r *Recency) LastCommit(path string) time.Time {
+	rel, err := relativeTo(r.root, path)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return r.lastCommits[rel]
+}
@@ -0,0 +1,44 @@
+package gitutil_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ivuorinen/gibidify/gitutil"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// TestParseRecencyLog tests that every file gets the time of the newest commit naming it.
+func TestParseRecencyLog(t *testing.T) {
+	log := "\x00300\n\nmain.go\n\x00200\n\nREADME.md\nmain.go\n\x00100\n\npkg/util.go\n"
+	lastCommits := gitutil.ParseRecencyLog([]byte(log))
+
+	want := map[string]int64{"main.go": 300, "README.md": 200, "pkg/util.go": 100}
+	if len(lastCommits) != len(want) {
+		t.Fatalf("ParseRecencyLog() = %v, want %v", lastCommits, want)
+	}
+	for path, seconds := range want {
+		if got := lastCommits[path].Unix(); got != seconds {
+			t.Errorf("lastCommits[%s] = %d, want %d", path, got, seconds)
+		}
+	}
+}
+
+// TestLoadRecency tests reading the last commit times of a real repository.
+func TestLoadRecency(t *testing.T) {
+	dir := t.TempDir()
+	testutil.InitGitRepo(t, dir)
+	testutil.CreateTestFile(t, dir, "main.go", []byte("package main\n"))
+	testutil.GitCommitAs(t, dir, "Alice", "alice@example.com", "add main")
+	testutil.CreateTestFile(t, dir, "new.go", []byte("package main\n"))
+
+	recency, err := gitutil.Git{}.LoadRecency(dir)
+	testutil.MustSucceed(t, err, "loading recency")
+	if got := recency.LastCommit(filepath.Join(dir, "main.go")); time.Since(got) > time.Hour {
+		t.Errorf("LastCommit(main.go) = %v, want the commit just made", got)
+	}
+	if got := recency.LastCommit(filepath.Join(dir, "new.go")); !got.IsZero() {
+		t.Errorf("LastCommit(new.go) = %v, want zero for an uncommitted file", got)
+	}
+}
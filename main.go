@@ -46,8 +46,38 @@ func printVersion(w io.Writer) {
 	_, _ = fmt.Fprintf(w, "gibidify %s\ncommit: %s\nbuilt: %s\nby: %s\n", version, commit, date, builtBy)
 }
 
+// runSubcommand runs the subcommand named by the first argument, if any.
+// handled is false when the arguments are plain bundling flags.
+func runSubcommand(ctx context.Context) (handled bool, err error) {
+	if len(os.Args) < 2 {
+		return false, nil
+	}
+
+	return cli.RunSubcommand(ctx, os.Args[1], os.Args[2:]) //nolint:wrapcheck // errors name the subcommand's action
+}
+
+// loadConfig loads the --config file, or searches the config directories unless the run is
+// hermetic and merges the project config file of the source directory over what it found.
+func loadConfig(flags *cli.Flags) error {
+	if flags.Config == "" && !flags.Hermetic {
+		config.LoadConfig()
+		if path := config.FindProjectConfig(flags.SourceDir); path != "" && !flags.NoProjectConfig {
+			return config.Global().MergeProjectConfig(path) //nolint:wrapcheck // wrapped by run
+		}
+
+		return nil
+	}
+
+	return config.LoadConfigFrom(flags.Config) //nolint:wrapcheck // wrapped by run
+}
+
 // Run executes the main logic of the CLI application using the provided context.
 func run(ctx context.Context) error {
+	// Dispatch subcommands before parsing the bundling flags
+	if handled, err := runSubcommand(ctx); handled {
+		return err
+	}
+
 	// Parse CLI flags
 	flags, err := cli.ParseFlags()
 	if err != nil {
@@ -65,10 +95,30 @@ func run(ctx context.Context) error {
 	logger.SetLevel(shared.ParseLogLevel(flags.LogLevel))
 
 	// Load configuration
-	config.LoadConfig()
+	if err := loadConfig(flags); err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	if err := cli.ApplyProfile(flags, config.Global()); err != nil {
+		return fmt.Errorf("applying profile: %w", err)
+	}
+	cli.WarnDeprecations(flags, config.Global())
+
+	// Plugin formats are registered in the configuration, so -format is checked once it is loaded
+	if err := config.ValidateOutputFormat(flags.Format); err != nil {
+		return fmt.Errorf("parsing flags: %w", err)
+	}
+
+	// Regenerate the bundle periodically with --every
+	if flags.Every > 0 {
+		if err := cli.RunEvery(ctx, flags); err != nil {
+			return fmt.Errorf("processing: %w", err)
+		}
+
+		return nil
+	}
 
 	// Create and run processor
-	processor := cli.NewProcessor(flags)
+	processor := cli.NewProcessor(cli.WithFlags(flags))
 
 	if err := processor.Process(ctx); err != nil {
 		return fmt.Errorf("processing: %w", err)
@@ -2,8 +2,14 @@
 package metrics
 
 import (
+	"cmp"
+	"errors"
+	"fmt"
+	"maps"
 	"math"
 	"runtime"
+	"slices"
+	"strings"
 	"sync/atomic"
 	"time"
 
@@ -19,8 +25,18 @@ func NewCollector() *Collector {
 		lastUpdate:   now,
 		formatCounts: make(map[string]int64),
 		errorCounts:  make(map[string]int64),
+		skipReasons:  make(map[string]int64),
 		phaseTimings: make(map[string]time.Duration),
+		fileTimings:  make(map[string]PhaseMetrics),
+		fileHotspots: make(map[string]*FileInfo),
 		smallestFile: math.MaxInt64, // Initialize to max value to properly track minimum
+
+		securityFindings:     make(map[string]int64),
+		redactions:           make(map[string]int64),
+		warningCounts:        make(map[string]int64),
+		skippedFilesByReason: make(map[string]int64),
+		skippedBytesByReason: make(map[string]int64),
+		limitHits:            make(map[string]int64),
 	}
 }
 
@@ -31,6 +47,114 @@ func (c *Collector) RecordFileProcessed(result FileProcessingResult) {
 	c.updateFileStatusCounters(result)
 	atomic.AddInt64(&c.totalSize, result.FileSize)
 	c.updateFormatAndErrorCounts(result)
+	c.recordOutcome(result)
+}
+
+// recordOutcome records the outcome of a processed file and the resource limit it ran into.
+func (c *Collector) recordOutcome(result FileProcessingResult) {
+	outcome := FileOutcome{Path: result.FilePath, Size: result.FileSize, Reason: result.SkipReason}
+	switch {
+	case result.Success:
+		outcome.Status, outcome.Reason = FileStatusIncluded, ""
+	case result.Skipped:
+		outcome.Status = FileStatusSkipped
+	default:
+		outcome.Status = FileStatusFailed
+		if outcome.Reason == "" {
+			outcome.Reason = shared.SkipReasonError
+		}
+	}
+	if result.Error != nil {
+		outcome.Error = result.Error.Error()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.outcomes = append(c.outcomes, outcome)
+	var structErr *shared.StructuredError
+	if errors.As(result.Error, &structErr) && slices.Contains(limitCodes, structErr.Code) {
+		c.limitHits[structErr.Code]++
+	}
+}
+
+// RecordFileSkipped records a file left out of the bundle for reason, one of the
+// shared.SkipReason* constants, before it reached a worker. Only its outcome is recorded; the
+// totals by reason come from RecordSkipped.
+func (c *Collector) RecordFileSkipped(path, reason string, size int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.outcomes = append(c.outcomes, FileOutcome{Path: path, Size: size, Status: FileStatusSkipped, Reason: reason})
+}
+
+// RecordLimitHit records a resource limit the run ran into, such as a hard memory limit
+// violation, by the shared.CodeResourceLimit* code or name of the limit.
+func (c *Collector) RecordLimitHit(limit string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.limitHits[limit]++
+}
+
+// RecordSanitizedFile records a file whose content was changed by sanitization.
+func (c *Collector) RecordSanitizedFile() {
+	atomic.AddInt64(&c.sanitizedFiles, 1)
+}
+
+// RecordSecurityFindings adds the security scan findings of one file.
+func (c *Collector) RecordSecurityFindings(findings map[string]int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for kind, count := range findings {
+		c.securityFindings[kind] += int64(count)
+	}
+}
+
+// RecordRedactions adds the number of replacements by redaction name of one file.
+func (c *Collector) RecordRedactions(counts map[string]int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for name, count := range counts {
+		c.redactions[name] += int64(count)
+	}
+}
+
+// RecordWarning records a non-fatal issue of kind, one of the shared.Warning* constants, with
+// the file at path. Warnings past shared.MetricsMaxWarnings are only counted.
+func (c *Collector) RecordWarning(kind, path, message string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.recordWarning(kind, path, message)
+}
+
+// recordWarning records a warning; the caller holds c.mu.
+func (c *Collector) recordWarning(kind, path, message string) {
+	c.warningCounts[kind]++
+	if len(c.warnings) < shared.MetricsMaxWarnings {
+		c.warnings = append(c.warnings, Warning{Kind: kind, Path: path, Message: message})
+	}
+}
+
+// SetSlowFileThreshold sets the time a file may spend in the per-file phases before a
+// shared.WarningSlowFile warning is recorded for it; 0 disables the warning.
+func (c *Collector) SetSlowFileThreshold(threshold time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.slowFileThreshold = threshold
+}
+
+// RecordSkipped adds files and bytes left out of the bundle for reason, one of the
+// shared.SkipReason* constants.
+func (c *Collector) RecordSkipped(reason string, files, bytes int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.skippedFilesByReason[reason] += files
+	c.skippedBytesByReason[reason] += bytes
 }
 
 // updateFileStatusCounters updates counters based on file processing result.
@@ -84,6 +208,9 @@ func (c *Collector) updateFormatAndErrorCounts(result FileProcessingResult) {
 		errorType := c.simplifyErrorType(result.Error)
 		c.errorCounts[errorType]++
 	}
+	if result.Skipped && result.SkipReason != "" {
+		c.skipReasons[result.SkipReason]++
+	}
 	c.lastUpdate = time.Now()
 }
 
@@ -105,6 +232,126 @@ func (c *Collector) RecordPhaseTime(phase string, duration time.Duration) {
 	c.mu.Unlock()
 }
 
+// RecordFileTiming records the time the file at path, of size bytes, spent in a per-file phase
+// such as read or format. Per-file phases overlap across workers, so they are kept apart from the
+// run phase timings.
+func (c *Collector) RecordFileTiming(path, phase string, size int64, duration time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	timing := c.fileTimings[phase]
+	timing.TotalTime += duration
+	timing.Count++
+	c.fileTimings[phase] = timing
+
+	hotspot, ok := c.fileHotspots[path]
+	if !ok {
+		hotspot = &FileInfo{Path: path, Phases: make(map[string]time.Duration)}
+		c.fileHotspots[path] = hotspot
+	}
+	hotspot.Size = max(hotspot.Size, size)
+	before := hotspot.ProcessingTime
+	hotspot.ProcessingTime += duration
+	if c.slowFileThreshold > 0 && before < c.slowFileThreshold && hotspot.ProcessingTime >= c.slowFileThreshold {
+		c.recordWarning(shared.WarningSlowFile, path, fmt.Sprintf("took more than %v to process", c.slowFileThreshold))
+	}
+	hotspot.Phases[phase] += duration
+	if hotspot.DominantPhase == "" || hotspot.Phases[phase] > hotspot.Phases[hotspot.DominantPhase] {
+		hotspot.DominantPhase = phase
+	}
+}
+
+// SlowestFiles returns the n files that spent the most time in the per-file phases, slowest first.
+func (c *Collector) SlowestFiles(n int) []FileInfo {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	files := make([]FileInfo, 0, len(c.fileHotspots))
+	for _, hotspot := range c.fileHotspots {
+		file := *hotspot
+		file.Phases = maps.Clone(hotspot.Phases)
+		files = append(files, file)
+	}
+	slices.SortFunc(files, func(a, b FileInfo) int {
+		if a.ProcessingTime != b.ProcessingTime {
+			return cmp.Compare(b.ProcessingTime, a.ProcessingTime)
+		}
+
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	return files[:min(n, len(files))]
+}
+
+// RecordWorkerTime records the time worker spent processing one file.
+func (c *Collector) RecordWorkerTime(worker int, duration time.Duration) {
+	if worker < 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for len(c.workerTimings) <= worker {
+		c.workerTimings = append(c.workerTimings, WorkerTiming{Worker: len(c.workerTimings)})
+	}
+	c.workerTimings[worker].Files++
+	c.workerTimings[worker].Busy += duration
+}
+
+// RecordOutputWrites records the writes that reached the output file and the bytes they wrote.
+func (c *Collector) RecordOutputWrites(writes, bytes int64) {
+	c.mu.Lock()
+	c.outputWrites += writes
+	c.outputBytes += bytes
+	c.mu.Unlock()
+}
+
+// RecordContentCache records the files bundled from the content cache and the cacheable files
+// that had to be processed.
+func (c *Collector) RecordContentCache(hits, misses int64) {
+	c.mu.Lock()
+	c.cacheHits += hits
+	c.cacheMisses += misses
+	c.mu.Unlock()
+}
+
+// RecordWorkers records the number of workers the run was configured with.
+func (c *Collector) RecordWorkers(workers int) {
+	c.mu.Lock()
+	c.workers = workers
+	c.mu.Unlock()
+}
+
+// RecordRunID records the ID of the run the metrics belong to.
+func (c *Collector) RecordRunID(id string) {
+	c.mu.Lock()
+	c.runID = id
+	c.mu.Unlock()
+}
+
+// fileTimingsSnapshot returns the per-file phase timings with averages and shares filled in.
+// The caller must hold c.mu.
+func (c *Collector) fileTimingsSnapshot() map[string]PhaseMetrics {
+	total := time.Duration(0)
+	for _, timing := range c.fileTimings {
+		total += timing.TotalTime
+	}
+
+	snapshot := make(map[string]PhaseMetrics, len(c.fileTimings))
+	for phase, timing := range c.fileTimings {
+		if timing.Count > 0 {
+			timing.AverageTime = timing.TotalTime / time.Duration(timing.Count)
+		}
+		if total > 0 {
+			timing.Percentage = float64(timing.TotalTime) / float64(total) * 100
+		}
+		snapshot[phase] = timing
+	}
+
+	return snapshot
+}
+
 // IncrementConcurrency increments the current concurrency counter.
 func (c *Collector) IncrementConcurrency() {
 	newVal := atomic.AddInt32(&c.concurrency, 1)
@@ -174,34 +421,62 @@ func (c *Collector) CurrentMetrics() ProcessingMetrics {
 		errorCounts[k] = v
 	}
 
+	skipReasons := make(map[string]int64, len(c.skipReasons))
+	for k, v := range c.skipReasons {
+		skipReasons[k] = v
+	}
+
+	securityFindings := make(map[string]int64, len(c.securityFindings))
+	for k, v := range c.securityFindings {
+		securityFindings[k] = v
+	}
+
 	phaseTimings := make(map[string]time.Duration)
 	for k, v := range c.phaseTimings {
 		phaseTimings[k] = v
 	}
 
 	return ProcessingMetrics{
-		TotalFiles:         totalFiles,
-		ProcessedFiles:     processedFiles,
-		SkippedFiles:       atomic.LoadInt64(&c.skippedFiles),
-		ErrorFiles:         atomic.LoadInt64(&c.errorFiles),
-		LastUpdated:        c.lastUpdate,
-		TotalSize:          atomic.LoadInt64(&c.totalSize),
-		ProcessedSize:      processedSize,
-		AverageFileSize:    avgFileSize,
-		LargestFile:        atomic.LoadInt64(&c.largestFile),
-		SmallestFile:       smallestFile,
-		StartTime:          c.startTime,
-		ProcessingTime:     processingTime,
-		FilesPerSecond:     filesPerSec,
-		BytesPerSecond:     bytesPerSec,
-		PeakMemoryMB:       shared.BytesToMB(m.Sys),
-		CurrentMemoryMB:    shared.BytesToMB(m.Alloc),
-		GoroutineCount:     runtime.NumGoroutine(),
-		FormatCounts:       formatCounts,
-		ErrorCounts:        errorCounts,
-		MaxConcurrency:     int(atomic.LoadInt32(&c.peakConcurrency)),
-		CurrentConcurrency: atomic.LoadInt32(&c.concurrency),
-		PhaseTimings:       phaseTimings,
+		TotalFiles:           totalFiles,
+		ProcessedFiles:       processedFiles,
+		SkippedFiles:         atomic.LoadInt64(&c.skippedFiles),
+		ErrorFiles:           atomic.LoadInt64(&c.errorFiles),
+		SanitizedFiles:       atomic.LoadInt64(&c.sanitizedFiles),
+		LastUpdated:          c.lastUpdate,
+		TotalSize:            atomic.LoadInt64(&c.totalSize),
+		ProcessedSize:        processedSize,
+		AverageFileSize:      avgFileSize,
+		LargestFile:          atomic.LoadInt64(&c.largestFile),
+		SmallestFile:         smallestFile,
+		StartTime:            c.startTime,
+		ProcessingTime:       processingTime,
+		FilesPerSecond:       filesPerSec,
+		BytesPerSecond:       bytesPerSec,
+		PeakMemoryMB:         shared.BytesToMB(m.Sys),
+		CurrentMemoryMB:      shared.BytesToMB(m.Alloc),
+		GoroutineCount:       runtime.NumGoroutine(),
+		GOMAXPROCS:           runtime.GOMAXPROCS(0),
+		HostCPUs:             runtime.NumCPU(),
+		Workers:              c.workers,
+		RunID:                c.runID,
+		FormatCounts:         formatCounts,
+		ErrorCounts:          errorCounts,
+		SkipReasons:          skipReasons,
+		SecurityFindings:     securityFindings,
+		Redactions:           maps.Clone(c.redactions),
+		WarningCounts:        maps.Clone(c.warningCounts),
+		Warnings:             slices.Clone(c.warnings),
+		SkippedFilesByReason: maps.Clone(c.skippedFilesByReason),
+		SkippedBytesByReason: maps.Clone(c.skippedBytesByReason),
+		MaxConcurrency:       int(atomic.LoadInt32(&c.peakConcurrency)),
+		CurrentConcurrency:   atomic.LoadInt32(&c.concurrency),
+		PhaseTimings:         phaseTimings,
+		FileTimings:          c.fileTimingsSnapshot(),
+		WorkerTimings:        slices.Clone(c.workerTimings),
+		OutputWrites:         c.outputWrites,
+		OutputBytes:          c.outputBytes,
+		CacheHits:            c.cacheHits,
+		CacheMisses:          c.cacheMisses,
 	}
 }
 
@@ -278,7 +553,7 @@ func (c *Collector) GenerateReport() ProfileReport {
 	return ProfileReport{
 		Summary:          metrics,
 		TopLargestFiles:  []FileInfo{}, // Would need separate tracking
-		TopSlowestFiles:  []FileInfo{}, // Would need separate tracking
+		TopSlowestFiles:  c.SlowestFiles(shared.MetricsTopSlowestFiles),
 		FormatBreakdown:  formatBreakdown,
 		ErrorBreakdown:   metrics.ErrorCounts,
 		PhaseBreakdown:   phaseBreakdown,
@@ -342,6 +617,7 @@ func (c *Collector) Reset() {
 	atomic.StoreInt64(&c.processedFiles, 0)
 	atomic.StoreInt64(&c.skippedFiles, 0)
 	atomic.StoreInt64(&c.errorFiles, 0)
+	atomic.StoreInt64(&c.sanitizedFiles, 0)
 	atomic.StoreInt64(&c.totalSize, 0)
 	atomic.StoreInt64(&c.processedSize, 0)
 	atomic.StoreInt64(&c.largestFile, 0)
@@ -350,6 +626,24 @@ func (c *Collector) Reset() {
 
 	c.formatCounts = make(map[string]int64)
 	c.errorCounts = make(map[string]int64)
+	c.skipReasons = make(map[string]int64)
+	c.securityFindings = make(map[string]int64)
+	c.redactions = make(map[string]int64)
+	c.warningCounts = make(map[string]int64)
+	c.warnings = nil
+	c.skippedFilesByReason = make(map[string]int64)
+	c.skippedBytesByReason = make(map[string]int64)
+	c.outcomes = nil
+	c.limitHits = make(map[string]int64)
 	c.metrics = ProcessingMetrics{} // Clear final snapshot
 	c.phaseTimings = make(map[string]time.Duration)
+	c.fileTimings = make(map[string]PhaseMetrics)
+	c.workerTimings = nil
+	c.fileHotspots = make(map[string]*FileInfo)
+	c.outputWrites = 0
+	c.outputBytes = 0
+	c.cacheHits = 0
+	c.cacheMisses = 0
+	c.workers = 0
+	c.runID = ""
 }
@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"runtime"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -135,6 +137,107 @@ func TestRecordFileProcessedSkipped(t *testing.T) {
 	if metrics.ProcessedFiles != 0 {
 		t.Errorf("Expected ProcessedFiles=0, got %d", metrics.ProcessedFiles)
 	}
+
+	if metrics.SkipReasons["binary file"] != 1 {
+		t.Errorf("Expected SkipReasons[binary file]=1, got %v", metrics.SkipReasons)
+	}
+}
+
+func TestRecordSanitizedFile(t *testing.T) {
+	collector := NewCollector()
+
+	collector.RecordSanitizedFile()
+	collector.RecordSanitizedFile()
+
+	if got := collector.CurrentMetrics().SanitizedFiles; got != 2 {
+		t.Errorf("Expected SanitizedFiles=2, got %d", got)
+	}
+
+	collector.Reset()
+	if got := collector.CurrentMetrics().SanitizedFiles; got != 0 {
+		t.Errorf("Expected SanitizedFiles=0 after reset, got %d", got)
+	}
+}
+
+func TestRecordSecurityFindings(t *testing.T) {
+	collector := NewCollector()
+
+	collector.RecordSecurityFindings(map[string]int{"potential secret": 2})
+	collector.RecordSecurityFindings(map[string]int{"potential secret": 1, "private key": 1})
+
+	metrics := collector.CurrentMetrics()
+	if metrics.SecurityFindings["potential secret"] != 3 || metrics.SecurityFindings["private key"] != 1 {
+		t.Errorf("Unexpected SecurityFindings: %v", metrics.SecurityFindings)
+	}
+}
+
+func TestRecordRedactions(t *testing.T) {
+	collector := NewCollector()
+
+	collector.RecordRedactions(map[string]int{"password": 2})
+	collector.RecordRedactions(map[string]int{"password": 1, "jwt": 1})
+
+	metrics := collector.CurrentMetrics()
+	if metrics.Redactions["password"] != 3 || metrics.Redactions["jwt"] != 1 {
+		t.Errorf("Unexpected Redactions: %v", metrics.Redactions)
+	}
+
+	collector.Reset()
+	if got := collector.CurrentMetrics().Redactions; len(got) != 0 {
+		t.Errorf("Expected no redactions after reset, got %v", got)
+	}
+}
+
+func TestRecordSkipped(t *testing.T) {
+	collector := NewCollector()
+
+	collector.RecordSkipped(shared.SkipReasonBinary, 2, 300)
+	collector.RecordSkipped(shared.SkipReasonBinary, 1, 100)
+	collector.RecordSkipped(shared.SkipReasonIgnored, 4, 40)
+
+	metrics := collector.CurrentMetrics()
+	if metrics.SkippedFilesByReason[shared.SkipReasonBinary] != 3 ||
+		metrics.SkippedBytesByReason[shared.SkipReasonBinary] != 400 ||
+		metrics.SkippedFilesByReason[shared.SkipReasonIgnored] != 4 ||
+		metrics.SkippedBytesByReason[shared.SkipReasonIgnored] != 40 {
+		t.Errorf("Unexpected skip breakdown: files %v, bytes %v",
+			metrics.SkippedFilesByReason, metrics.SkippedBytesByReason)
+	}
+
+	collector.Reset()
+	if got := collector.CurrentMetrics().SkippedFilesByReason; len(got) != 0 {
+		t.Errorf("Expected no skip breakdown after reset, got %v", got)
+	}
+}
+
+func TestRecordWarning(t *testing.T) {
+	collector := NewCollector()
+	collector.SetSlowFileThreshold(time.Second)
+
+	collector.RecordWarning(shared.WarningEncoding, "latin1.txt", "not valid UTF-8")
+	collector.RecordFileTiming("big.sql", shared.MetricsPhaseRead, 0, 600*time.Millisecond)
+	collector.RecordFileTiming("big.sql", shared.MetricsPhaseFormat, 0, 600*time.Millisecond)
+	collector.RecordFileTiming("big.sql", shared.MetricsPhaseWrite, 0, time.Second)
+	for range shared.MetricsMaxWarnings {
+		collector.RecordWarning(shared.WarningFenceCollision, "README.md", "fence")
+	}
+
+	metrics := collector.CurrentMetrics()
+	if metrics.WarningCounts[shared.WarningEncoding] != 1 || metrics.WarningCounts[shared.WarningSlowFile] != 1 ||
+		metrics.WarningCounts[shared.WarningFenceCollision] != shared.MetricsMaxWarnings {
+		t.Errorf("Unexpected WarningCounts: %v", metrics.WarningCounts)
+	}
+	if len(metrics.Warnings) != shared.MetricsMaxWarnings {
+		t.Errorf("Expected %d warnings kept, got %d", shared.MetricsMaxWarnings, len(metrics.Warnings))
+	}
+	if slow := metrics.Warnings[1]; slow.Kind != shared.WarningSlowFile || slow.Path != "big.sql" {
+		t.Errorf("Expected a slow file warning for big.sql, got %+v", slow)
+	}
+
+	collector.Reset()
+	if got := collector.CurrentMetrics(); len(got.WarningCounts) != 0 || len(got.Warnings) != 0 {
+		t.Errorf("Expected no warnings after reset, got %v", got.WarningCounts)
+	}
 }
 
 func TestRecordPhaseTime(t *testing.T) {
@@ -155,6 +258,142 @@ func TestRecordPhaseTime(t *testing.T) {
 	}
 }
 
+func TestRecordFileAndWorkerTimings(t *testing.T) {
+	collector := NewCollector()
+
+	collector.RecordFileTiming("a.go", shared.MetricsPhaseRead, 100, 30*time.Millisecond)
+	collector.RecordFileTiming("b.go", shared.MetricsPhaseRead, 100, 10*time.Millisecond)
+	collector.RecordFileTiming("b.go", shared.MetricsPhaseWrite, 100, 60*time.Millisecond)
+	collector.RecordWorkerTime(1, 40*time.Millisecond)
+	collector.RecordWorkerTime(1, 20*time.Millisecond)
+	collector.RecordWorkerTime(-1, time.Second) // ignored
+
+	metrics := collector.CurrentMetrics()
+	read := metrics.FileTimings[shared.MetricsPhaseRead]
+	if read.TotalTime != 40*time.Millisecond || read.Count != 2 || read.AverageTime != 20*time.Millisecond {
+		t.Errorf("Unexpected read timing: %+v", read)
+	}
+	if got := metrics.FileTimings[shared.MetricsPhaseWrite].Percentage; got != 60 {
+		t.Errorf("Expected write share=60%%, got %.1f", got)
+	}
+	if _, ok := metrics.PhaseTimings[shared.MetricsPhaseRead]; ok {
+		t.Error("Per-file timings must not be added to the run phase timings")
+	}
+
+	want := []WorkerTiming{{Worker: 0}, {Worker: 1, Files: 2, Busy: 60 * time.Millisecond}}
+	if len(metrics.WorkerTimings) != len(want) {
+		t.Fatalf("Expected %d worker timings, got %+v", len(want), metrics.WorkerTimings)
+	}
+	for i, w := range want {
+		if metrics.WorkerTimings[i] != w {
+			t.Errorf("Worker %d: expected %+v, got %+v", i, w, metrics.WorkerTimings[i])
+		}
+	}
+
+	collector.Reset()
+	metrics = collector.CurrentMetrics()
+	if len(metrics.FileTimings) != 0 || len(metrics.WorkerTimings) != 0 {
+		t.Errorf("Expected no timings after reset, got %+v and %+v", metrics.FileTimings, metrics.WorkerTimings)
+	}
+}
+
+func TestRecordOutputWrites(t *testing.T) {
+	collector := NewCollector()
+	collector.RecordOutputWrites(3, 1000)
+	collector.RecordOutputWrites(1, 24)
+
+	metrics := collector.CurrentMetrics()
+	if metrics.OutputWrites != 4 || metrics.OutputBytes != 1024 {
+		t.Errorf("Expected 4 writes of 1024 bytes, got %d writes of %d bytes", metrics.OutputWrites, metrics.OutputBytes)
+	}
+
+	collector.Reset()
+	if metrics := collector.CurrentMetrics(); metrics.OutputWrites != 0 || metrics.OutputBytes != 0 {
+		t.Errorf("Expected no output writes after reset, got %d", metrics.OutputWrites)
+	}
+}
+
+func TestRecordContentCache(t *testing.T) {
+	collector := NewCollector()
+	collector.RecordContentCache(3, 1)
+
+	metrics := collector.CurrentMetrics()
+	if metrics.CacheHits != 3 || metrics.CacheMisses != 1 {
+		t.Errorf("Expected 3 hits and 1 miss, got %d hits and %d misses", metrics.CacheHits, metrics.CacheMisses)
+	}
+	if got := contentCacheStats(metrics); got != "Content cache: 3 hits, 1 misses (75% hit rate)" {
+		t.Errorf("Unexpected content cache stats %q", got)
+	}
+
+	collector.Reset()
+	if metrics := collector.CurrentMetrics(); contentCacheStats(metrics) != "" {
+		t.Errorf("Expected no content cache stats after reset, got %q", contentCacheStats(metrics))
+	}
+}
+
+func TestRecordWorkers(t *testing.T) {
+	collector := NewCollector()
+	collector.RecordWorkers(4)
+
+	metrics := collector.CurrentMetrics()
+	if metrics.Workers != 4 || metrics.GOMAXPROCS != runtime.GOMAXPROCS(0) || metrics.HostCPUs != runtime.NumCPU() {
+		t.Errorf("Expected 4 workers and the current CPU settings, got %+v", metrics)
+	}
+
+	collector.Reset()
+	if metrics := collector.CurrentMetrics(); metrics.Workers != 0 {
+		t.Errorf("Expected no workers after reset, got %d", metrics.Workers)
+	}
+}
+
+func TestRecordRunID(t *testing.T) {
+	collector := NewCollector()
+	collector.RecordRunID("run-1")
+	reporter := NewReporter(collector, false, false)
+
+	if metrics := collector.CurrentMetrics(); metrics.RunID != "run-1" {
+		t.Errorf("Expected run ID run-1, got %q", metrics.RunID)
+	}
+	if final := reporter.ReportFinal(); !strings.Contains(final, "Run ID: run-1") {
+		t.Errorf("Expected the run ID in the final report, got %q", final)
+	}
+
+	collector.Reset()
+	if metrics := collector.CurrentMetrics(); metrics.RunID != "" {
+		t.Errorf("Expected no run ID after reset, got %q", metrics.RunID)
+	}
+}
+
+func TestSlowestFiles(t *testing.T) {
+	collector := NewCollector()
+
+	collector.RecordFileTiming("dump.sql", shared.MetricsPhaseTransform, 0, 10*time.Millisecond)
+	collector.RecordFileTiming("dump.sql", shared.MetricsPhaseRead, 200*shared.BytesPerMB, 900*time.Millisecond)
+	collector.RecordFileTiming("dump.sql", shared.MetricsPhaseWrite, 200*shared.BytesPerMB, 50*time.Millisecond)
+	collector.RecordFileTiming("main.go", shared.MetricsPhaseFormat, 512, 2*time.Millisecond)
+	collector.RecordFileTiming("util.go", shared.MetricsPhaseFormat, 256, 2*time.Millisecond)
+
+	slowest := collector.SlowestFiles(2)
+	if len(slowest) != 2 {
+		t.Fatalf("Expected 2 files, got %+v", slowest)
+	}
+
+	dump := slowest[0]
+	if dump.Path != "dump.sql" || dump.ProcessingTime != 960*time.Millisecond {
+		t.Errorf("Expected dump.sql to be slowest with 960ms, got %+v", dump)
+	}
+	if dump.Size != 200*shared.BytesPerMB || dump.DominantPhase != shared.MetricsPhaseRead {
+		t.Errorf("Expected size=200MB and dominant phase=read, got size=%d phase=%s", dump.Size, dump.DominantPhase)
+	}
+	if slowest[1].Path != "main.go" {
+		t.Errorf("Expected ties to be ordered by path, got %s", slowest[1].Path)
+	}
+
+	if report := collector.GenerateReport(); len(report.TopSlowestFiles) != 3 {
+		t.Errorf("Expected 3 slowest files in report, got %d", len(report.TopSlowestFiles))
+	}
+}
+
 func TestConcurrencyTracking(t *testing.T) {
 	collector := NewCollector()
 
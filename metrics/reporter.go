@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"slices"
 	"strings"
+	"text/tabwriter"
 	"time"
 
 	"github.com/ivuorinen/gibidify/shared"
@@ -208,12 +209,33 @@ func (r *Reporter) formatBasicReport(metrics ProcessingMetrics) string {
 	b := newReportBuilder()
 
 	b.writeString("=== Processing Complete ===\n")
+	if metrics.RunID != "" {
+		b.fprintf("Run ID: %s\n", metrics.RunID)
+	}
 	b.writeString(
 		fmt.Sprintf(
 			"Total Files: %d (Processed: %d, Skipped: %d, Errors: %d)\n",
 			metrics.TotalFiles, metrics.ProcessedFiles, metrics.SkippedFiles, metrics.ErrorFiles,
 		),
 	)
+	for _, reason := range r.sortedMapKeys(metrics.SkipReasons) {
+		b.writeString(fmt.Sprintf("  Skipped (%s): %d\n", reason, metrics.SkipReasons[reason]))
+	}
+	for _, reason := range r.sortedMapKeys(metrics.SkippedFilesByReason) {
+		b.writeString(fmt.Sprintf(
+			"  Left out (%s): %d files, %s\n",
+			reason, metrics.SkippedFilesByReason[reason], r.formatBytes(metrics.SkippedBytesByReason[reason]),
+		))
+	}
+	if metrics.SanitizedFiles > 0 {
+		b.writeString(fmt.Sprintf("  Sanitized: %d\n", metrics.SanitizedFiles))
+	}
+	if cache := contentCacheStats(metrics); cache != "" {
+		b.writeString("  " + cache + "\n")
+	}
+	r.writeCounts(b, "Security findings (review before sharing):", metrics.SecurityFindings)
+	r.writeCounts(b, "Redacted:", metrics.Redactions)
+	r.writeCounts(b, "Warnings:", metrics.WarningCounts)
 
 	b.writeString(
 		fmt.Sprintf(
@@ -223,10 +245,47 @@ func (r *Reporter) formatBasicReport(metrics ProcessingMetrics) string {
 	)
 
 	b.writeString(fmt.Sprintf(shared.MetricsFmtProcessingTime, metrics.ProcessingTime.Truncate(time.Millisecond)))
+	b.writeString(cpuSettings(metrics) + "\n")
 
 	return b.String()
 }
 
+// writeCounts writes a titled list of counts by key, or nothing when counts is empty.
+func (r *Reporter) writeCounts(b *reportBuilder, title string, counts map[string]int64) {
+	if len(counts) == 0 {
+		return
+	}
+
+	b.writeString(title + "\n")
+	for _, key := range r.sortedMapKeys(counts) {
+		b.writeString(fmt.Sprintf("  %s: %d\n", key, counts[key]))
+	}
+}
+
+// cpuSettings describes the effective CPU settings of the run.
+func cpuSettings(metrics ProcessingMetrics) string {
+	settings := fmt.Sprintf("CPUs: GOMAXPROCS %d of %d host CPUs", metrics.GOMAXPROCS, metrics.HostCPUs)
+	if metrics.Workers > 0 {
+		settings += fmt.Sprintf(", %d workers", metrics.Workers)
+	}
+
+	return settings
+}
+
+// contentCacheStats describes the content cache lookups of the run, or returns "" when the
+// cache was not used.
+func contentCacheStats(metrics ProcessingMetrics) string {
+	lookups := metrics.CacheHits + metrics.CacheMisses
+	if lookups == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf(
+		"Content cache: %d hits, %d misses (%.0f%% hit rate)",
+		metrics.CacheHits, metrics.CacheMisses, float64(metrics.CacheHits)/float64(lookups)*100,
+	)
+}
+
 // formatVerboseReport formats a comprehensive final report.
 func (r *Reporter) formatVerboseReport(report ProfileReport) string {
 	b := newReportBuilder()
@@ -236,7 +295,13 @@ func (r *Reporter) formatVerboseReport(report ProfileReport) string {
 	r.writeSummarySection(b, report)
 	r.writeFormatBreakdown(b, report)
 	r.writePhaseBreakdown(b, report)
+	r.writeTimings(b, report)
+	r.writeSlowestFiles(b, report)
 	r.writeErrorBreakdown(b, report)
+	r.writeSkipBreakdown(b, report)
+	r.writeSecurityFindings(b, report)
+	r.writeRedactions(b, report)
+	r.writeWarnings(b, report)
 	r.writeResourceUsage(b, report)
 	r.writeFileSizeStats(b, report)
 	r.writeRecommendations(b, report)
@@ -251,10 +316,19 @@ func (r *Reporter) writeSummarySection(b *reportBuilder, report ProfileReport) {
 	metrics := report.Summary
 
 	b.writeString("SUMMARY:\n")
+	if metrics.RunID != "" {
+		b.fprintf("  Run ID: %s\n", metrics.RunID)
+	}
 	b.fprintf(
 		"  Files: %d total (%d processed, %d skipped, %d errors)\n",
 		metrics.TotalFiles, metrics.ProcessedFiles, metrics.SkippedFiles, metrics.ErrorFiles,
 	)
+	if metrics.SanitizedFiles > 0 {
+		b.fprintf("  Sanitized: %d files\n", metrics.SanitizedFiles)
+	}
+	if cache := contentCacheStats(metrics); cache != "" {
+		b.writeString("  " + cache + "\n")
+	}
 	b.fprintf(
 		"  Size: %s processed (avg: %s per file)\n",
 		r.formatBytes(metrics.ProcessedSize), r.formatBytes(int64(metrics.AverageFileSize)),
@@ -311,6 +385,158 @@ func (r *Reporter) writePhaseBreakdown(b *reportBuilder, report ProfileReport) {
 	}
 }
 
+// writeTimings writes the per-phase and per-worker timing section.
+func (r *Reporter) writeTimings(b *reportBuilder, report ProfileReport) {
+	if len(report.Summary.FileTimings) == 0 && len(report.Summary.WorkerTimings) == 0 {
+		return
+	}
+
+	b.writeString("\nTIMINGS:\n")
+	b.writeString(timingTable(report.Summary, "  "))
+}
+
+// ReportTimings returns the per-phase and per-worker timing table printed by --timings.
+func (r *Reporter) ReportTimings() string {
+	if r == nil || r.collector == nil {
+		return ""
+	}
+
+	return "=== Timings ===\n" + timingTable(r.collector.CurrentMetrics(), "")
+}
+
+// timingTable renders the time spent in each phase and by each worker as aligned tables,
+// with every line starting with indent. Collection runs once, so it has no per-file columns.
+func timingTable(metrics ProcessingMetrics, indent string) string {
+	b := &strings.Builder{}
+	tw := tabwriter.NewWriter(b, 0, 0, 2, ' ', 0)
+
+	_, _ = fmt.Fprintf(tw, "%sPHASE\tTOTAL\tFILES\tAVG/FILE\tSHARE\n", indent)
+	if collection, ok := metrics.PhaseTimings[shared.MetricsPhaseCollection]; ok {
+		_, _ = fmt.Fprintf(tw, "%s%s\t%v\t-\t-\t-\n", indent, shared.MetricsPhaseCollection, roundTiming(collection))
+	}
+	phases := []string{
+		shared.MetricsPhaseRead,
+		shared.MetricsPhaseTransform,
+		shared.MetricsPhaseFormat,
+		shared.MetricsPhaseWrite,
+	}
+	for _, phase := range phases {
+		timing, ok := metrics.FileTimings[phase]
+		if !ok {
+			continue
+		}
+		_, _ = fmt.Fprintf(
+			tw, "%s%s\t%v\t%d\t%v\t%.1f%%\n", indent, phase,
+			roundTiming(timing.TotalTime), timing.Count, roundTiming(timing.AverageTime), timing.Percentage,
+		)
+	}
+
+	if len(metrics.WorkerTimings) > 0 {
+		wall := metrics.PhaseTimings[shared.MetricsPhaseProcessing]
+		if wall == 0 {
+			wall = metrics.ProcessingTime
+		}
+		_, _ = fmt.Fprintf(tw, "\n%sWORKER\tFILES\tBUSY\tUTILIZATION\n", indent)
+		for _, worker := range metrics.WorkerTimings {
+			utilization := float64(0)
+			if wall > 0 {
+				utilization = float64(worker.Busy) / float64(wall) * 100
+			}
+			_, _ = fmt.Fprintf(
+				tw, "%s%d\t%d\t%v\t%.1f%%\n", indent, worker.Worker, worker.Files, roundTiming(worker.Busy), utilization,
+			)
+		}
+	}
+
+	_ = tw.Flush()
+
+	return b.String()
+}
+
+// writeSlowestFiles writes the slowest files section.
+func (r *Reporter) writeSlowestFiles(b *reportBuilder, report ProfileReport) {
+	if len(report.TopSlowestFiles) == 0 {
+		return
+	}
+
+	b.writeString("\nSLOWEST FILES:\n")
+	b.writeString(r.hotspotTable(report.TopSlowestFiles, "  "))
+}
+
+// ReportHotspots returns the n slowest files with the phase each spent most time in,
+// printed by --hotspots.
+func (r *Reporter) ReportHotspots(n int) string {
+	if r == nil || r.collector == nil {
+		return ""
+	}
+
+	files := r.collector.SlowestFiles(n)
+	if len(files) == 0 {
+		return ""
+	}
+
+	return "=== Slowest Files ===\n" + r.hotspotTable(files, "")
+}
+
+// hotspotTable renders files, slowest first, as a flamegraph-style table: each file has a bar
+// scaled to the slowest file, split by phase and drawn with the first letter of the phase name.
+func (r *Reporter) hotspotTable(files []FileInfo, indent string) string {
+	phases := []string{
+		shared.MetricsPhaseRead,
+		shared.MetricsPhaseTransform,
+		shared.MetricsPhaseFormat,
+		shared.MetricsPhaseWrite,
+	}
+	slowest := files[0].ProcessingTime
+
+	b := &strings.Builder{}
+	tw := tabwriter.NewWriter(b, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(tw, "%sTIME\tSIZE\tDOMINANT\tPHASES\tPATH\n", indent)
+	for _, file := range files {
+		_, _ = fmt.Fprintf(
+			tw, "%s%v\t%s\t%s\t%s\t%s\n", indent, roundTiming(file.ProcessingTime),
+			r.formatBytes(file.Size), file.DominantPhase, phaseBar(file, phases, slowest), file.Path,
+		)
+	}
+	_ = tw.Flush()
+
+	legend := make([]string, 0, len(phases))
+	for _, phase := range phases {
+		legend = append(legend, phase[:1]+"="+phase)
+	}
+	b.WriteString(indent + strings.Join(legend, " ") + "\n")
+
+	return b.String()
+}
+
+// phaseBar draws the phases of file as a bar whose width is its share of slowest.
+func phaseBar(file FileInfo, phases []string, slowest time.Duration) string {
+	if slowest <= 0 {
+		return "[" + strings.Repeat(" ", shared.MetricsHotspotBarWidth) + "]"
+	}
+
+	bar := make([]byte, 0, shared.MetricsHotspotBarWidth)
+	elapsed := time.Duration(0)
+	for _, phase := range phases {
+		elapsed += file.Phases[phase]
+		end := int(float64(elapsed) / float64(slowest) * shared.MetricsHotspotBarWidth)
+		for len(bar) < min(end, shared.MetricsHotspotBarWidth) {
+			bar = append(bar, phase[0])
+		}
+	}
+
+	return "[" + string(bar) + strings.Repeat(" ", shared.MetricsHotspotBarWidth-len(bar)) + "]"
+}
+
+// roundTiming rounds d for display, keeping sub-millisecond timings readable.
+func roundTiming(d time.Duration) time.Duration {
+	if d < time.Millisecond {
+		return d.Round(time.Microsecond)
+	}
+
+	return d.Round(time.Millisecond / 10)
+}
+
 // writeErrorBreakdown writes the error breakdown section.
 func (r *Reporter) writeErrorBreakdown(b *reportBuilder, report ProfileReport) {
 	if len(report.ErrorBreakdown) == 0 {
@@ -325,6 +551,69 @@ func (r *Reporter) writeErrorBreakdown(b *reportBuilder, report ProfileReport) {
 	}
 }
 
+// writeSkipBreakdown writes the skip reason breakdown section, followed by the files and
+// bytes left out of the bundle per reason.
+func (r *Reporter) writeSkipBreakdown(b *reportBuilder, report ProfileReport) {
+	if len(report.Summary.SkipReasons) > 0 {
+		b.writeString("\nSKIP BREAKDOWN:\n")
+		for _, reason := range r.sortedMapKeys(report.Summary.SkipReasons) {
+			b.fprintf("  %s: %d files\n", reason, report.Summary.SkipReasons[reason])
+		}
+	}
+
+	if len(report.Summary.SkippedFilesByReason) > 0 {
+		b.writeString("\nLEFT OUT BY REASON:\n")
+		for _, reason := range r.sortedMapKeys(report.Summary.SkippedFilesByReason) {
+			b.fprintf(
+				"  %s: %d files, %s\n",
+				reason, report.Summary.SkippedFilesByReason[reason],
+				r.formatBytes(report.Summary.SkippedBytesByReason[reason]),
+			)
+		}
+	}
+}
+
+// writeSecurityFindings writes the security scan findings section.
+func (r *Reporter) writeSecurityFindings(b *reportBuilder, report ProfileReport) {
+	if len(report.Summary.SecurityFindings) == 0 {
+		return
+	}
+
+	b.writeString("\nSECURITY FINDINGS (review before sharing):\n")
+	for _, kind := range r.sortedMapKeys(report.Summary.SecurityFindings) {
+		b.fprintf("  %s: %d\n", kind, report.Summary.SecurityFindings[kind])
+	}
+}
+
+// writeRedactions writes the replaced secrets section.
+func (r *Reporter) writeRedactions(b *reportBuilder, report ProfileReport) {
+	if len(report.Summary.Redactions) == 0 {
+		return
+	}
+
+	b.writeString("\nREDACTED:\n")
+	for _, name := range r.sortedMapKeys(report.Summary.Redactions) {
+		b.fprintf("  %s: %d\n", name, report.Summary.Redactions[name])
+	}
+}
+
+// writeWarnings writes the non-fatal warnings section, with the warnings kept for each kind.
+func (r *Reporter) writeWarnings(b *reportBuilder, report ProfileReport) {
+	if len(report.Summary.WarningCounts) == 0 {
+		return
+	}
+
+	b.writeString("\nWARNINGS:\n")
+	for _, kind := range r.sortedMapKeys(report.Summary.WarningCounts) {
+		b.fprintf("  %s: %d\n", kind, report.Summary.WarningCounts[kind])
+		for _, warning := range report.Summary.Warnings {
+			if warning.Kind == kind {
+				b.fprintf("    %s: %s\n", warning.Path, warning.Message)
+			}
+		}
+	}
+}
+
 // writeResourceUsage writes the resource usage section.
 func (r *Reporter) writeResourceUsage(b *reportBuilder, report ProfileReport) {
 	metrics := report.Summary
@@ -337,6 +626,13 @@ func (r *Reporter) writeResourceUsage(b *reportBuilder, report ProfileReport) {
 		"  Concurrency: %d current, %d max, %d goroutines\n",
 		metrics.CurrentConcurrency, metrics.MaxConcurrency, metrics.GoroutineCount,
 	)
+	b.writeString("  " + cpuSettings(metrics) + "\n")
+	if metrics.OutputWrites > 0 {
+		b.fprintf(
+			"  Output Writes: %d (%s written, %s per write)\n",
+			metrics.OutputWrites, r.formatBytes(metrics.OutputBytes), r.formatBytes(metrics.OutputBytes/metrics.OutputWrites),
+		)
+	}
 }
 
 // writeFileSizeStats writes the file size statistics section.
@@ -176,11 +176,16 @@ func TestReportFinalBasic(t *testing.T) {
 			Success:  false,
 			Error:    errors.New(shared.TestErrSyntaxError),
 		},
+		{FilePath: "dist/app.min.js", FileSize: 90000, Skipped: true, SkipReason: "minified"},
 	}
 
 	for _, file := range files {
 		collector.RecordFileProcessed(file)
 	}
+	collector.RecordSecurityFindings(map[string]int{"private key": 1})
+	collector.RecordRedactions(map[string]int{"jwt": 2})
+	collector.RecordSkipped(shared.SkipReasonBinary, 3, 2048)
+	collector.RecordWarning(shared.WarningEncoding, "latin1.txt", "not valid UTF-8")
 
 	collector.Finish()
 	final := reporter.ReportFinal()
@@ -189,7 +194,27 @@ func TestReportFinalBasic(t *testing.T) {
 		t.Error("Expected completion header not found")
 	}
 
-	if !strings.Contains(final, "Total Files: 3") {
+	if !strings.Contains(final, "Skipped (minified): 1") {
+		t.Error("Expected skip reason breakdown not found")
+	}
+
+	if !strings.Contains(final, "Left out (binary): 3 files, 2.0KB") {
+		t.Errorf("Expected left out breakdown not found in:\n%s", final)
+	}
+
+	if !strings.Contains(final, "Security findings (review before sharing):\n  private key: 1") {
+		t.Error("Expected security findings not found")
+	}
+
+	if !strings.Contains(final, "Redacted:\n  jwt: 2") {
+		t.Errorf("Expected redactions not found in:\n%s", final)
+	}
+
+	if !strings.Contains(final, "Warnings:\n  encoding: 1") {
+		t.Errorf("Expected warnings not found in:\n%s", final)
+	}
+
+	if !strings.Contains(final, "Total Files: 4") {
 		t.Error("Expected total files count not found")
 	}
 
@@ -200,6 +225,10 @@ func TestReportFinalBasic(t *testing.T) {
 	if !strings.Contains(final, "Errors: 1") {
 		t.Error("Expected error count not found")
 	}
+
+	if !strings.Contains(final, "CPUs: GOMAXPROCS ") || strings.Contains(final, "workers") {
+		t.Errorf("Expected CPU settings without a worker count, got:\n%s", final)
+	}
 }
 
 func TestReportFinalVerbose(t *testing.T) {
@@ -227,6 +256,9 @@ func TestReportFinalVerbose(t *testing.T) {
 	collector.RecordPhaseTime(shared.MetricsPhaseCollection, 50*time.Millisecond)
 	collector.RecordPhaseTime(shared.MetricsPhaseProcessing, 150*time.Millisecond)
 	collector.RecordPhaseTime(shared.MetricsPhaseWriting, 25*time.Millisecond)
+	collector.RecordOutputWrites(4, 8192)
+	collector.RecordWorkers(3)
+	collector.RecordWarning(shared.WarningFenceCollision, "README.md", "has a fence")
 
 	collector.Finish()
 	final := reporter.ReportFinal()
@@ -252,6 +284,10 @@ func TestReportFinalVerbose(t *testing.T) {
 		t.Error("Expected error breakdown section not found")
 	}
 
+	if !strings.Contains(final, "WARNINGS:\n  fence_collision: 1\n    README.md: has a fence\n") {
+		t.Errorf("Expected warnings section not found in:\n%s", final)
+	}
+
 	if !strings.Contains(final, "RESOURCE USAGE:") {
 		t.Error("Expected resource usage section not found")
 	}
@@ -276,6 +312,83 @@ func TestReportFinalVerbose(t *testing.T) {
 	if !strings.Contains(final, "syntax error: 1 occurrences") {
 		t.Error("Expected error count not found")
 	}
+
+	if !strings.Contains(final, "host CPUs, 3 workers") {
+		t.Errorf("Expected CPU settings with the worker count, got:\n%s", final)
+	}
+	if !strings.Contains(final, "Output Writes: 4 (8.0KB written, 2.0KB per write)") {
+		t.Error("Expected output write stats not found")
+	}
+}
+
+func TestReportTimings(t *testing.T) {
+	collector := NewCollector()
+	collector.RecordPhaseTime(shared.MetricsPhaseCollection, 5*time.Millisecond)
+	collector.RecordPhaseTime(shared.MetricsPhaseProcessing, 100*time.Millisecond)
+	collector.RecordFileTiming("a.go", shared.MetricsPhaseRead, 100, 20*time.Millisecond)
+	collector.RecordFileTiming("a.go", shared.MetricsPhaseFormat, 100, 5*time.Millisecond)
+	collector.RecordWorkerTime(0, 50*time.Millisecond)
+
+	tests := []struct {
+		name     string
+		report   func() string
+		expected []string
+	}{
+		{
+			name:   "timings table",
+			report: NewReporter(collector, false, false).ReportTimings,
+			expected: []string{
+				"PHASE", "AVG/FILE", "collection  5ms", "read", "80.0%", "format",
+				"WORKER", "UTILIZATION", "50.0%",
+			},
+		},
+		{
+			name:     "verbose report",
+			report:   NewReporter(collector, true, false).ReportFinal,
+			expected: []string{"TIMINGS:", "  PHASE", "  WORKER"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := tt.report()
+			for _, expected := range tt.expected {
+				if !strings.Contains(report, expected) {
+					t.Errorf("Expected report to contain %q, got:\n%s", expected, report)
+				}
+			}
+			for line := range strings.Lines(report) {
+				if strings.HasPrefix(strings.TrimSpace(line), shared.MetricsPhaseTransform) {
+					t.Errorf("Expected phases without timings to be left out, got:\n%s", report)
+				}
+			}
+		})
+	}
+}
+
+func TestReportHotspots(t *testing.T) {
+	collector := NewCollector()
+	reporter := NewReporter(collector, false, false)
+
+	if report := reporter.ReportHotspots(5); report != "" {
+		t.Errorf("Expected empty hotspot report without timings, got: %s", report)
+	}
+
+	collector.RecordFileTiming("dump.sql", shared.MetricsPhaseRead, 2*shared.BytesPerMB, 30*time.Millisecond)
+	collector.RecordFileTiming("dump.sql", shared.MetricsPhaseWrite, 2*shared.BytesPerMB, 10*time.Millisecond)
+	collector.RecordFileTiming("main.go", shared.MetricsPhaseFormat, 100, 20*time.Millisecond)
+
+	report := reporter.ReportHotspots(5)
+	bar := "[" + strings.Repeat("r", 30) + strings.Repeat("w", 10) + "]"
+	expected := []string{"Slowest Files", "40ms", "2.0MB", "dump.sql", bar, "[" + strings.Repeat("f", 20), "r=read"}
+	for _, want := range expected {
+		if !strings.Contains(report, want) {
+			t.Errorf("Expected hotspot report to contain %q, got:\n%s", want, report)
+		}
+	}
+	if strings.Index(report, "dump.sql") > strings.Index(report, "main.go") {
+		t.Errorf("Expected slowest file first, got:\n%s", report)
+	}
 }
 
 func TestFormatBytes(t *testing.T) {
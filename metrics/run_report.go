@@ -0,0 +1,187 @@
+// Package metrics provides performance monitoring and reporting capabilities.
+package metrics
+
+import (
+	"cmp"
+	"encoding/json"
+	"io"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// RunReportSchemaVersion is the version of the RunReport schema. It changes only when a field
+// is removed or changes meaning; new fields may appear in any version.
+const RunReportSchemaVersion = 1
+
+// limitCodes lists the error codes of the limits whose hits a RunReport counts.
+var limitCodes = []string{
+	shared.CodeValidationSize,
+	shared.CodeResourceLimitFiles,
+	shared.CodeResourceLimitTotalSize,
+	shared.CodeResourceLimitTimeout,
+	shared.CodeResourceLimitMemory,
+	shared.CodeResourceLimitConcurrency,
+	shared.CodeResourceLimitRate,
+}
+
+// RunReport is the machine-readable report of a run written with --report json: what became
+// of every file, the time spent per phase, the limits the run ran into and the secrets it
+// redacted. Durations are in milliseconds and sizes in bytes.
+type RunReport struct {
+	SchemaVersion int       `json:"schema_version"`
+	RunID         string    `json:"run_id,omitempty"`
+	StartedAt     time.Time `json:"started_at"`
+	DurationMS    float64   `json:"duration_ms"`
+
+	Totals RunReportTotals `json:"totals"`
+	// Files lists every file collected or processed, ordered by path.
+	Files []RunReportFile `json:"files"`
+	// Skipped counts the files left out of the bundle, failed ones included, by reason.
+	Skipped map[string]RunReportSkip `json:"skipped"`
+
+	// Phases holds the time spent in the run phases (collection, processing, writing, finalize)
+	// and FilePhases the time files spent in the per-file phases, summed over the workers.
+	Phases     map[string]float64 `json:"phases_ms"`
+	FilePhases map[string]float64 `json:"file_phases_ms"`
+
+	// LimitHits counts the files that ran into a size, count, time or memory limit by limit.
+	LimitHits map[string]int64 `json:"limit_hits"`
+	// Redactions counts the secrets replaced in the bundle by redaction name.
+	Redactions       map[string]int64 `json:"redactions"`
+	SecurityFindings map[string]int64 `json:"security_findings"`
+	Warnings         []Warning        `json:"warnings"`
+}
+
+// RunReportTotals counts the files of a run and their bytes.
+type RunReportTotals struct {
+	Files         int64 `json:"files"`
+	Included      int64 `json:"included"`
+	Skipped       int64 `json:"skipped"`
+	Failed        int64 `json:"failed"`
+	IncludedBytes int64 `json:"included_bytes"`
+	OutputBytes   int64 `json:"output_bytes"`
+}
+
+// RunReportFile is the outcome of one file in a RunReport.
+type RunReportFile struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+	// Status is FileStatusIncluded, FileStatusSkipped or FileStatusFailed.
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+	Error  string `json:"error,omitempty"`
+	// DurationMS is the time the file spent in the per-file phases, Phases that time by phase.
+	DurationMS float64            `json:"duration_ms,omitempty"`
+	Phases     map[string]float64 `json:"phases_ms,omitempty"`
+}
+
+// RunReportSkip counts the files left out of the bundle for one reason and their bytes.
+type RunReportSkip struct {
+	Files int64 `json:"files"`
+	Bytes int64 `json:"bytes"`
+}
+
+// RunReport returns the report of the run so far, with the paths of the files relative to
+// root, as the per-file phase timings record them. An empty root keeps the paths as recorded.
+func (c *Collector) RunReport(root string) RunReport {
+	metrics := c.CurrentMetrics()
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	report := RunReport{
+		SchemaVersion:    RunReportSchemaVersion,
+		RunID:            metrics.RunID,
+		StartedAt:        metrics.StartTime,
+		DurationMS:       milliseconds(metrics.ProcessingTime),
+		Files:            make([]RunReportFile, 0, len(c.outcomes)),
+		Skipped:          make(map[string]RunReportSkip),
+		Phases:           make(map[string]float64, len(metrics.PhaseTimings)),
+		FilePhases:       make(map[string]float64, len(metrics.FileTimings)),
+		LimitHits:        maps.Clone(c.limitHits),
+		Redactions:       metrics.Redactions,
+		SecurityFindings: metrics.SecurityFindings,
+		Warnings:         metrics.Warnings,
+	}
+	report.Totals.OutputBytes = metrics.OutputBytes
+	for phase, duration := range metrics.PhaseTimings {
+		report.Phases[phase] = milliseconds(duration)
+	}
+	for phase, timing := range metrics.FileTimings {
+		report.FilePhases[phase] = milliseconds(timing.TotalTime)
+	}
+	for _, outcome := range c.outcomes {
+		if rel, err := filepath.Rel(root, outcome.Path); root != "" && err == nil {
+			outcome.Path = rel
+		}
+		report.addFile(outcome, c.fileHotspots[outcome.Path])
+	}
+	slices.SortStableFunc(report.Files, func(a, b RunReportFile) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	if report.Warnings == nil {
+		report.Warnings = []Warning{}
+	}
+
+	return report
+}
+
+// addFile adds the outcome of a file, with its per-file phase timings when it has any, to the
+// report and its totals.
+func (r *RunReport) addFile(outcome FileOutcome, timing *FileInfo) {
+	file := RunReportFile{
+		Path:   filepath.ToSlash(outcome.Path),
+		Size:   outcome.Size,
+		Status: outcome.Status,
+		Reason: outcome.Reason,
+		Error:  outcome.Error,
+	}
+	if timing != nil {
+		file.Size = max(file.Size, timing.Size)
+		file.DurationMS = milliseconds(timing.ProcessingTime)
+		file.Phases = make(map[string]float64, len(timing.Phases))
+		for phase, duration := range timing.Phases {
+			file.Phases[phase] = milliseconds(duration)
+		}
+	}
+	r.Files = append(r.Files, file)
+
+	r.Totals.Files++
+	switch outcome.Status {
+	case FileStatusIncluded:
+		r.Totals.Included++
+		r.Totals.IncludedBytes += file.Size
+
+		return
+	case FileStatusSkipped:
+		r.Totals.Skipped++
+	default:
+		r.Totals.Failed++
+	}
+	reason := cmp.Or(outcome.Reason, shared.SkipReasonError)
+	skipped := r.Skipped[reason]
+	skipped.Files++
+	skipped.Bytes += file.Size
+	r.Skipped[reason] = skipped
+}
+
+// WriteJSON writes the report to w as an indented JSON document.
+func (r RunReport) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(r); err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOWrite, "failed to write run report")
+	}
+
+	return nil
+}
+
+// milliseconds returns d in milliseconds.
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
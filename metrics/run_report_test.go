@@ -0,0 +1,102 @@
+package metrics
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// TestRunReport tests that the run report lists the outcome of every file, relative to the root
+// and ordered by path, with the totals, skip reasons, phase timings and limit hits of the run.
+func TestRunReport(t *testing.T) {
+	root := filepath.Join(string(filepath.Separator), "src")
+	collector := NewCollector()
+	collector.RecordRunID("run-1")
+	collector.RecordFileProcessed(FileProcessingResult{
+		FilePath: filepath.Join(root, "main.go"), FileSize: 100, Format: "go", Success: true,
+	})
+	collector.RecordFileTiming("main.go", shared.MetricsPhaseRead, 100, 2*time.Millisecond)
+	collector.RecordFileProcessed(FileProcessingResult{
+		FilePath: filepath.Join(root, "logo.png"), FileSize: 50, Skipped: true, SkipReason: shared.SkipReasonBinary,
+	})
+	sizeErr := shared.NewStructuredError(
+		shared.ErrorTypeValidation, shared.CodeValidationSize, "file too large", "big.txt", nil,
+	)
+	collector.RecordFileProcessed(FileProcessingResult{
+		FilePath: filepath.Join(root, "big.txt"), FileSize: 900, SkipReason: shared.SkipReasonSizeLimit, Error: sizeErr,
+	})
+	collector.RecordFileProcessed(FileProcessingResult{
+		FilePath: filepath.Join(root, "broken.go"), Error: errors.New("read failed"),
+	})
+	collector.RecordFileSkipped(filepath.Join(root, "vendor.js"), shared.SkipReasonIgnored, 10)
+	collector.RecordPhaseTime(shared.MetricsPhaseCollection, 3*time.Millisecond)
+	collector.RecordLimitHit("hard_memory_limit")
+
+	report := collector.RunReport(root)
+
+	if report.SchemaVersion != RunReportSchemaVersion || report.RunID != "run-1" {
+		t.Errorf("schema version and run ID = %d, %q", report.SchemaVersion, report.RunID)
+	}
+	want := RunReportTotals{Files: 5, Included: 1, Skipped: 2, Failed: 2, IncludedBytes: 100}
+	if report.Totals != want {
+		t.Errorf("totals = %+v, want %+v", report.Totals, want)
+	}
+	paths := make([]string, 0, len(report.Files))
+	for _, file := range report.Files {
+		paths = append(paths, file.Path)
+	}
+	wantPaths := []string{"big.txt", "broken.go", "logo.png", "main.go", "vendor.js"}
+	if !slices.Equal(paths, wantPaths) {
+		t.Errorf("file paths = %v, want %v", paths, wantPaths)
+	}
+	if main := report.Files[3]; main.Status != FileStatusIncluded || main.Phases[shared.MetricsPhaseRead] != 2 {
+		t.Errorf("main.go = %+v, want included with a 2ms read", main)
+	}
+	if broken := report.Files[1]; broken.Status != FileStatusFailed || broken.Reason != shared.SkipReasonError ||
+		broken.Error != "read failed" {
+		t.Errorf("broken.go = %+v, want failed with its error", broken)
+	}
+
+	wantSkipped := map[string]RunReportSkip{
+		shared.SkipReasonBinary:    {Files: 1, Bytes: 50},
+		shared.SkipReasonSizeLimit: {Files: 1, Bytes: 900},
+		shared.SkipReasonError:     {Files: 1},
+		shared.SkipReasonIgnored:   {Files: 1, Bytes: 10},
+	}
+	for reason, skipped := range wantSkipped {
+		if report.Skipped[reason] != skipped {
+			t.Errorf("skipped %s = %+v, want %+v", reason, report.Skipped[reason], skipped)
+		}
+	}
+	if report.LimitHits[shared.CodeValidationSize] != 1 || report.LimitHits["hard_memory_limit"] != 1 {
+		t.Errorf("limit hits = %v, want one size limit and one memory limit hit", report.LimitHits)
+	}
+	if report.Phases[shared.MetricsPhaseCollection] != 3 {
+		t.Errorf("collection phase = %vms, want 3ms", report.Phases[shared.MetricsPhaseCollection])
+	}
+}
+
+// TestRunReportWriteJSON tests that an empty run report encodes its lists and counts as empty
+// JSON values rather than null.
+func TestRunReportWriteJSON(t *testing.T) {
+	var out bytes.Buffer
+	if err := NewCollector().RunReport("").WriteJSON(&out); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+
+	var document map[string]any
+	if err := json.Unmarshal(out.Bytes(), &document); err != nil {
+		t.Fatalf("decoding report: %v", err)
+	}
+	for _, field := range []string{"files", "skipped", "phases_ms", "limit_hits", "redactions", "warnings"} {
+		if document[field] == nil {
+			t.Errorf("%s is null or missing, want an empty value", field)
+		}
+	}
+}
@@ -8,11 +8,15 @@ import (
 
 // ProcessingMetrics provides comprehensive processing statistics.
 type ProcessingMetrics struct {
+	// RunID identifies the run, so reports can be matched to its logs and bundle.
+	RunID string `json:"run_id,omitempty"`
+
 	// File processing metrics
 	TotalFiles     int64     `json:"total_files"`
 	ProcessedFiles int64     `json:"processed_files"`
 	SkippedFiles   int64     `json:"skipped_files"`
 	ErrorFiles     int64     `json:"error_files"`
+	SanitizedFiles int64     `json:"sanitized_files,omitempty"`
 	LastUpdated    time.Time `json:"last_updated"`
 
 	// Size metrics
@@ -34,9 +38,29 @@ type ProcessingMetrics struct {
 	CurrentMemoryMB int64 `json:"current_memory_mb"`
 	GoroutineCount  int   `json:"goroutine_count"`
 
+	// Effective CPU settings, recorded so runs can be reproduced
+	GOMAXPROCS int `json:"gomaxprocs"`
+	HostCPUs   int `json:"host_cpus"`
+	Workers    int `json:"workers,omitempty"`
+
 	// Format specific metrics
 	FormatCounts map[string]int64 `json:"format_counts"`
 	ErrorCounts  map[string]int64 `json:"error_counts"`
+	SkipReasons  map[string]int64 `json:"skip_reasons,omitempty"`
+
+	// Files and bytes left out of the bundle by shared.SkipReason* reason
+	SkippedFilesByReason map[string]int64 `json:"skipped_files_by_reason,omitempty"`
+	SkippedBytesByReason map[string]int64 `json:"skipped_bytes_by_reason,omitempty"`
+
+	// Security scan findings by kind
+	SecurityFindings map[string]int64 `json:"security_findings,omitempty"`
+
+	// Secrets replaced in the bundle by redaction name
+	Redactions map[string]int64 `json:"redactions,omitempty"`
+
+	// Non-fatal warnings by shared.Warning* kind, and the first shared.MetricsMaxWarnings of them
+	WarningCounts map[string]int64 `json:"warning_counts,omitempty"`
+	Warnings      []Warning        `json:"warnings,omitempty"`
 
 	// Concurrency metrics
 	MaxConcurrency     int   `json:"max_concurrency"`
@@ -44,6 +68,18 @@ type ProcessingMetrics struct {
 
 	// Phase timings
 	PhaseTimings map[string]time.Duration `json:"phase_timings"`
+
+	// Per-file phase timings and per-worker busy time
+	FileTimings   map[string]PhaseMetrics `json:"file_timings,omitempty"`
+	WorkerTimings []WorkerTiming          `json:"worker_timings,omitempty"`
+
+	// Writes that reached the output file, one system call each, and the bytes they wrote
+	OutputWrites int64 `json:"output_writes,omitempty"`
+	OutputBytes  int64 `json:"output_bytes,omitempty"`
+
+	// Files bundled from the content cache, and cacheable files that had to be processed
+	CacheHits   int64 `json:"cache_hits,omitempty"`
+	CacheMisses int64 `json:"cache_misses,omitempty"`
 }
 
 // Collector collects and manages processing metrics.
@@ -58,6 +94,7 @@ type Collector struct {
 	processedFiles int64
 	skippedFiles   int64
 	errorFiles     int64
+	sanitizedFiles int64
 	totalSize      int64
 	processedSize  int64
 	largestFile    int64
@@ -70,9 +107,69 @@ type Collector struct {
 	// Format and error tracking with mutex protection
 	formatCounts map[string]int64
 	errorCounts  map[string]int64
+	skipReasons  map[string]int64
+
+	skippedFilesByReason map[string]int64
+	skippedBytesByReason map[string]int64
+
+	securityFindings map[string]int64
+	redactions       map[string]int64
+
+	// Warning tracking; files slower than slowFileThreshold are warned about when it is set
+	warnings          []Warning
+	warningCounts     map[string]int64
+	slowFileThreshold time.Duration
 
 	// Phase timing tracking
 	phaseTimings map[string]time.Duration
+
+	// Per-file phase and per-worker timing tracking
+	fileTimings   map[string]PhaseMetrics
+	workerTimings []WorkerTiming
+	fileHotspots  map[string]*FileInfo
+
+	// Output write tracking
+	outputWrites int64
+	outputBytes  int64
+
+	// Content cache tracking
+	cacheHits   int64
+	cacheMisses int64
+
+	// Outcome of every file, in the order they finished, and the resource limits files ran into
+	outcomes  []FileOutcome
+	limitHits map[string]int64
+
+	// Configured worker count
+	workers int
+
+	// ID of the run the metrics belong to
+	runID string
+}
+
+// Warning is a non-fatal issue with one file that did not stop it from being bundled.
+type Warning struct {
+	Kind    string `json:"kind"`
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// File outcome statuses of a FileOutcome.
+const (
+	FileStatusIncluded = "included"
+	FileStatusSkipped  = "skipped"
+	FileStatusFailed   = "failed"
+)
+
+// FileOutcome records what became of one file: whether it was included in the bundle, skipped
+// by policy or failed, and why.
+type FileOutcome struct {
+	Path   string
+	Size   int64
+	Status string
+	// Reason is the shared.SkipReason* a file was skipped or failed for.
+	Reason string
+	Error  string
 }
 
 // FileProcessingResult represents the result of processing a single file.
@@ -106,6 +203,10 @@ type FileInfo struct {
 	Size           int64         `json:"size"`
 	ProcessingTime time.Duration `json:"processing_time"`
 	Format         string        `json:"format"`
+
+	// Phases is the time the file spent in each per-file phase, and DominantPhase the longest of them
+	Phases        map[string]time.Duration `json:"phases,omitempty"`
+	DominantPhase string                   `json:"dominant_phase,omitempty"`
 }
 
 // FormatMetrics represents metrics for a specific file format.
@@ -125,6 +226,13 @@ type HourlyProcessingStats struct {
 	AverageRate    float64   `json:"average_rate"`
 }
 
+// WorkerTiming represents the time one worker spent processing files.
+type WorkerTiming struct {
+	Worker int           `json:"worker"`
+	Files  int64         `json:"files"`
+	Busy   time.Duration `json:"busy"`
+}
+
 // PhaseMetrics represents timing metrics for processing phases.
 type PhaseMetrics struct {
 	TotalTime   time.Duration `json:"total_time"`
@@ -0,0 +1,79 @@
+// Package policy enforces organizational guardrails distributed as a policy.yaml file,
+// kept separate from the user's configuration so it cannot be changed by editing config.
+package policy
+
+import (
+	"encoding/json"
+	"os"
+	"os/user"
+	"time"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// AuditRecord describes a run that overrode the policy.
+type AuditRecord struct {
+	Time        time.Time   `json:"time"`
+	RunID       string      `json:"runId,omitempty"`
+	User        string      `json:"user"`
+	Policy      string      `json:"policy"`
+	Source      string      `json:"source"`
+	Destination string      `json:"destination"`
+	Violations  []Violation `json:"violations"`
+}
+
+// NewAuditRecord creates a record of violations overridden by the current user.
+func (p *Policy) NewAuditRecord(source, destination string, violations []Violation) AuditRecord {
+	return AuditRecord{
+		Time:        time.Now().UTC(),
+		User:        currentUser(),
+		Policy:      p.path,
+		Source:      source,
+		Destination: destination,
+		Violations:  violations,
+	}
+}
+
+// Audit logs every overridden violation as a warning and appends record as a JSON line
+// to the policy's audit log, when one is configured. A run must not proceed when the
+// record cannot be written.
+func (p *Policy) Audit(record AuditRecord) error {
+	logger := shared.GetLogger()
+	for _, v := range record.Violations {
+		logger.Warnf("Policy override by %s: %s", record.User, v)
+	}
+	if p.AuditLog == "" {
+		return nil
+	}
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOEncoding, "failed to encode audit record")
+	}
+	file, err := os.OpenFile(
+		expandHome(p.AuditLog), os.O_WRONLY|os.O_CREATE|os.O_APPEND, shared.AuditLogPermission,
+	) // #nosec G304 - audit log location is chosen by the policy author
+	if err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOFileCreate, "failed to open policy audit log").
+			WithFilePath(p.AuditLog)
+	}
+	_, err = file.Write(append(line, '\n'))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOFileWrite, "failed to write policy audit log").
+			WithFilePath(p.AuditLog)
+	}
+
+	return nil
+}
+
+// currentUser returns the name of the user running gibidify.
+func currentUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+
+	return os.Getenv("USER")
+}
@@ -0,0 +1,282 @@
+// Package policy enforces organizational guardrails distributed as a policy.yaml file,
+// kept separate from the user's configuration so it cannot be changed by editing config.
+package policy
+
+import (
+	"cmp"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	ignore "github.com/sabhiram/go-gitignore"
+	"gopkg.in/yaml.v3"
+
+	"github.com/ivuorinen/gibidify/fileproc"
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// Policy rule names, used in violations and audit records.
+const (
+	RuleBannedPath         = "bannedPaths"
+	RuleMaxInputSize       = "maxInputSize"
+	RuleAllowedDestination = "allowedDestinations"
+)
+
+// Redaction is a mandatory content redaction rule.
+type Redaction struct {
+	Name        string `yaml:"name"`
+	Pattern     string `yaml:"pattern"`
+	Replacement string `yaml:"replacement"`
+}
+
+// Policy holds the guardrails of an organization policy file.
+type Policy struct {
+	// BannedPaths are gitignore-style patterns of files that must never be bundled.
+	BannedPaths []string `yaml:"bannedPaths"`
+	// Redactions are applied to the content of every file and cannot be disabled.
+	Redactions []Redaction `yaml:"redactions"`
+	// MaxInputSize is the largest total size, in bytes, of the files collected for a bundle;
+	// 0 means no limit. Format overhead and synthetic entries such as preludes do not count.
+	MaxInputSize int64 `yaml:"maxInputSize"`
+	// AllowedDestinations are directories or glob patterns the bundle may be written to, and
+	// URLs of the hosts --share may upload it to; empty allows any destination.
+	AllowedDestinations []string `yaml:"allowedDestinations"`
+	// AuditLog is the file that --policy-override runs are recorded in.
+	AuditLog string `yaml:"auditLog"`
+
+	path       string
+	banned     *ignore.GitIgnore
+	redactions []fileproc.Redaction
+}
+
+// Violation describes one way a run breaks the policy.
+type Violation struct {
+	Rule   string `json:"rule"`
+	Detail string `json:"detail"`
+}
+
+// String implements fmt.Stringer.
+func (v Violation) String() string {
+	return v.Rule + ": " + v.Detail
+}
+
+// Find returns the path of the policy file, or "" when there is none.
+// It looks in the following order:
+// 1. The file named by $GIBIDIFY_POLICY
+// 2. /etc/gibidify/policy.yaml
+// 3. $XDG_CONFIG_HOME/gibidify/policy.yaml, or $HOME/.config/gibidify/policy.yaml.
+func Find() string {
+	if path := FindAdministered(); path != "" {
+		return path
+	}
+
+	var user string
+	if xdgConfig := os.Getenv("XDG_CONFIG_HOME"); xdgConfig != "" {
+		if shared.ValidateConfigPath(xdgConfig) == nil {
+			user = filepath.Join(xdgConfig, shared.AppName, shared.PolicyFileName)
+		}
+	} else if home, err := os.UserHomeDir(); err == nil {
+		user = filepath.Join(home, ".config", shared.AppName, shared.PolicyFileName)
+	}
+	if info, err := os.Stat(user); user != "" && err == nil && !info.IsDir() {
+		return user
+	}
+
+	return ""
+}
+
+// FindAdministered returns the policy file installed by an administrator, $GIBIDIFY_POLICY or
+// /etc/gibidify/policy.yaml, or "" when there is none. Unlike Find it never looks in the user's
+// config directories, so hermetic runs stay bound by the policy without reading the home directory.
+func FindAdministered() string {
+	if path := os.Getenv(shared.PolicyEnvVar); path != "" {
+		return path
+	}
+
+	system := filepath.Join(shared.PolicySystemDir, shared.PolicyFileName)
+	if info, err := os.Stat(system); err == nil && !info.IsDir() {
+		return system
+	}
+
+	return ""
+}
+
+// Load reads and validates the policy file at path.
+func Load(path string) (*Policy, error) {
+	data, err := os.ReadFile(path) // #nosec G304 - path is the policy location chosen by the administrator
+	if err != nil {
+		return nil, shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIORead, "failed to read policy file").
+			WithFilePath(path)
+	}
+
+	p := &Policy{path: path}
+	var keys map[string]any
+	if err := yaml.Unmarshal(data, p); err != nil {
+		return nil, shared.WrapError(
+			err, shared.ErrorTypeConfiguration, shared.CodeConfigValidation, "invalid policy file",
+		).WithFilePath(path)
+	}
+	_ = yaml.Unmarshal(data, &keys) // the document already decoded into p
+	// The rule was called maxOutputSize; refuse it rather than silently dropping the limit
+	if _, ok := keys["maxOutputSize"]; ok {
+		return nil, shared.NewStructuredError(
+			shared.ErrorTypeConfiguration, shared.CodeConfigValidation,
+			"policy maxOutputSize was renamed to maxInputSize", path, nil,
+		)
+	}
+	if err := p.compile(); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// compile validates the policy and prepares its matchers.
+func (p *Policy) compile() error {
+	if p.MaxInputSize < 0 {
+		return shared.NewStructuredError(
+			shared.ErrorTypeConfiguration, shared.CodeConfigValidation,
+			fmt.Sprintf("policy maxInputSize must not be negative, got %d", p.MaxInputSize), p.path, nil,
+		)
+	}
+
+	p.banned = ignore.CompileIgnoreLines(p.BannedPaths...)
+	for i, r := range p.Redactions {
+		pattern, err := regexp.Compile(r.Pattern)
+		if err != nil || r.Pattern == "" {
+			return shared.WrapErrorf(
+				err, shared.ErrorTypeConfiguration, shared.CodeConfigValidation,
+				"invalid pattern in policy redaction %d (%s)", i, r.Name,
+			).WithFilePath(p.path)
+		}
+		replacement := r.Replacement
+		if replacement == "" {
+			replacement = "[REDACTED]"
+		}
+		p.redactions = append(p.redactions, fileproc.Redaction{
+			Name: r.Name, Pattern: pattern, Replacement: replacement,
+		})
+	}
+
+	return nil
+}
+
+// Path returns the file the policy was loaded from.
+func (p *Policy) Path() string {
+	return p.path
+}
+
+// FileRedactions returns the mandatory redactions in the form the file processor applies.
+func (p *Policy) FileRedactions() []fileproc.Redaction {
+	return p.redactions
+}
+
+// CheckDestination returns a violation when destination is not an allowed bundle location.
+func (p *Policy) CheckDestination(destination string) []Violation {
+	if len(p.AllowedDestinations) == 0 {
+		return nil
+	}
+
+	abs, err := filepath.Abs(destination)
+	if err != nil {
+		abs = destination
+	}
+	for _, allowed := range p.AllowedDestinations {
+		if destinationAllowed(expandHome(allowed), abs) {
+			return nil
+		}
+	}
+
+	return []Violation{{
+		Rule:   RuleAllowedDestination,
+		Detail: fmt.Sprintf("%s is not an allowed destination", abs),
+	}}
+}
+
+// CheckShare returns a violation when destinations are restricted and none of the allowed
+// destinations is a URL of host, the service --share uploads the bundle to.
+func (p *Policy) CheckShare(host string) []Violation {
+	if len(p.AllowedDestinations) == 0 {
+		return nil
+	}
+
+	for _, allowed := range p.AllowedDestinations {
+		if parsed, err := url.Parse(allowed); err == nil && parsed.Scheme != "" && parsed.Host != "" &&
+			strings.EqualFold(parsed.Host, host) {
+			return nil
+		}
+	}
+
+	return []Violation{{
+		Rule:   RuleAllowedDestination,
+		Detail: fmt.Sprintf("sharing to %s is not an allowed destination", cmp.Or(host, "the share service")),
+	}}
+}
+
+// CheckFiles returns a violation for every collected file matching a banned pattern and
+// for collected files totalling more than maxInputSize. files are paths under root.
+func (p *Policy) CheckFiles(root string, files []string) []Violation {
+	var violations []Violation
+	total := int64(0)
+	for _, file := range files {
+		if rel := relativePath(root, file); len(p.BannedPaths) > 0 && p.banned.MatchesPath(rel) {
+			violations = append(violations, Violation{Rule: RuleBannedPath, Detail: rel})
+		}
+		if info, err := os.Stat(file); err == nil {
+			total += info.Size()
+		}
+	}
+
+	if p.MaxInputSize > 0 && total > p.MaxInputSize {
+		violations = append(violations, Violation{
+			Rule:   RuleMaxInputSize,
+			Detail: fmt.Sprintf("collected files total %d bytes, policy allows %d", total, p.MaxInputSize),
+		})
+	}
+
+	return violations
+}
+
+// relativePath returns file relative to root with forward slashes, or file itself when it is not under root.
+func relativePath(root, file string) string {
+	absRoot, rootErr := filepath.Abs(root)
+	absFile, fileErr := filepath.Abs(file)
+	if rootErr != nil || fileErr != nil {
+		return filepath.ToSlash(file)
+	}
+	rel, err := filepath.Rel(absRoot, absFile)
+	if err != nil {
+		return filepath.ToSlash(file)
+	}
+
+	return filepath.ToSlash(rel)
+}
+
+// destinationAllowed reports whether path matches the glob allowed or lies inside the directory allowed.
+func destinationAllowed(allowed, path string) bool {
+	if abs, err := filepath.Abs(allowed); err == nil {
+		allowed = abs
+	}
+	if matched, err := filepath.Match(allowed, path); err == nil && matched {
+		return true
+	}
+	rel, err := filepath.Rel(allowed, path)
+
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// expandHome replaces a leading "~" with the user's home directory.
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+
+	return filepath.Join(home, path[1:])
+}
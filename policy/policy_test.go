@@ -0,0 +1,190 @@
+package policy_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/policy"
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// loadPolicy writes content to a policy file and loads it.
+func loadPolicy(t *testing.T, content string) *policy.Policy {
+	t.Helper()
+	path := testutil.CreateTestFile(t, t.TempDir(), shared.PolicyFileName, []byte(content))
+	p, err := policy.Load(path)
+	testutil.MustSucceed(t, err, "loading policy")
+
+	return p
+}
+
+// TestLoadInvalid tests that malformed policies are rejected.
+func TestLoadInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "not yaml", content: "bannedPaths: [unclosed"},
+		{name: "bad redaction pattern", content: "redactions:\n  - name: broken\n    pattern: \"(\"\n"},
+		{name: "empty redaction pattern", content: "redactions:\n  - name: empty\n"},
+		{name: "negative size", content: "maxInputSize: -1\n"},
+		{name: "renamed size rule", content: "maxOutputSize: 1024\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := testutil.CreateTestFile(t, t.TempDir(), shared.PolicyFileName, []byte(tt.content))
+			if _, err := policy.Load(path); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+// TestFind tests that $GIBIDIFY_POLICY takes precedence over the user config directory.
+func TestFind(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	t.Setenv(shared.PolicyEnvVar, "")
+
+	if _, err := os.Stat(filepath.Join(shared.PolicySystemDir, shared.PolicyFileName)); err == nil {
+		t.Skip("a system-wide policy is installed")
+	}
+	if got := policy.Find(); got != "" {
+		t.Errorf("Find() = %q without a policy file", got)
+	}
+
+	userPolicy := filepath.Join(configHome, shared.AppName, shared.PolicyFileName)
+	testutil.MustSucceed(t, os.MkdirAll(filepath.Dir(userPolicy), shared.TestDirPermission), "creating config dir")
+	testutil.CreateTestFile(t, filepath.Dir(userPolicy), shared.PolicyFileName, []byte("{}"))
+	if got := policy.Find(); got != userPolicy {
+		t.Errorf("Find() = %q, want %q", got, userPolicy)
+	}
+	if got := policy.FindAdministered(); got != "" {
+		t.Errorf("FindAdministered() = %q, want the user policy skipped", got)
+	}
+
+	t.Setenv(shared.PolicyEnvVar, "/opt/org/policy.yaml")
+	if got := policy.Find(); got != "/opt/org/policy.yaml" {
+		t.Errorf("Find() = %q, want the $%s path", got, shared.PolicyEnvVar)
+	}
+	if got := policy.FindAdministered(); got != "/opt/org/policy.yaml" {
+		t.Errorf("FindAdministered() = %q, want the $%s path", got, shared.PolicyEnvVar)
+	}
+}
+
+// TestCheckFiles tests banned path patterns and the maximum output size.
+func TestCheckFiles(t *testing.T) {
+	root := t.TempDir()
+	files := []string{
+		testutil.CreateTestFile(t, root, "main.go", []byte(strings.Repeat("x", 60))),
+		testutil.CreateTestFile(t, root, ".env", []byte("KEY=1")),
+		testutil.CreateTestFile(t, root, "certs.pem", []byte("-----")),
+	}
+
+	p := loadPolicy(t, "bannedPaths:\n  - .env\n  - \"*.pem\"\nmaxInputSize: 50\n")
+	violations := p.CheckFiles(root, files)
+
+	want := []policy.Violation{
+		{Rule: policy.RuleBannedPath, Detail: ".env"},
+		{Rule: policy.RuleBannedPath, Detail: "certs.pem"},
+		{Rule: policy.RuleMaxInputSize, Detail: "collected files total 70 bytes, policy allows 50"},
+	}
+	if len(violations) != len(want) {
+		t.Fatalf("violations = %v, want %v", violations, want)
+	}
+	for i := range want {
+		if violations[i] != want[i] {
+			t.Errorf("violation %d = %v, want %v", i, violations[i], want[i])
+		}
+	}
+
+	if got := loadPolicy(t, "{}").CheckFiles(root, files); len(got) != 0 {
+		t.Errorf("empty policy reported violations: %v", got)
+	}
+}
+
+// TestCheckDestination tests allowed directories and glob patterns.
+func TestCheckDestination(t *testing.T) {
+	p := loadPolicy(t, "allowedDestinations:\n  - /srv/bundles\n  - /tmp/*.md\n")
+
+	tests := []struct {
+		destination string
+		allowed     bool
+	}{
+		{"/srv/bundles/app.md", true},
+		{"/srv/bundles/team/app.json", true},
+		{"/tmp/app.md", true},
+		{"/tmp/app.json", false},
+		{"/srv/bundles-public/app.md", false},
+		{"/home/user/app.md", false},
+	}
+
+	for _, tt := range tests {
+		if got := len(p.CheckDestination(tt.destination)) == 0; got != tt.allowed {
+			t.Errorf("CheckDestination(%q) allowed = %v, want %v", tt.destination, got, tt.allowed)
+		}
+	}
+}
+
+// TestCheckShare tests that --share hosts are allowed only when destinations are unrestricted or
+// an allowed destination is a URL of the host.
+func TestCheckShare(t *testing.T) {
+	if v := loadPolicy(t, "bannedPaths: [\"*.pem\"]\n").CheckShare("paste.example"); len(v) != 0 {
+		t.Errorf("CheckShare() without allowedDestinations = %v, want no violation", v)
+	}
+
+	p := loadPolicy(t, "allowedDestinations:\n  - /srv/bundles\n  - https://paste.example/upload\n")
+	tests := []struct {
+		host    string
+		allowed bool
+	}{
+		{"paste.example", true},
+		{"PASTE.example", true},
+		{"api.github.com", false},
+		{"srv", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := len(p.CheckShare(tt.host)) == 0; got != tt.allowed {
+			t.Errorf("CheckShare(%q) allowed = %v, want %v", tt.host, got, tt.allowed)
+		}
+	}
+}
+
+// TestAudit tests that override records are appended to the audit log as JSON lines.
+func TestAudit(t *testing.T) {
+	auditLog := filepath.Join(t.TempDir(), "audit.log")
+	p := loadPolicy(t, "auditLog: "+auditLog+"\n")
+	defer testutil.SuppressLogs(t)()
+
+	violations := []policy.Violation{{Rule: policy.RuleBannedPath, Detail: ".env"}}
+	for range 2 {
+		record := p.NewAuditRecord("src", "out.md", violations)
+		record.RunID = "20261015T093012-9f3a61c2"
+		testutil.MustSucceed(t, p.Audit(record), "auditing")
+	}
+
+	file, err := os.Open(auditLog)
+	testutil.MustSucceed(t, err, "opening audit log")
+	defer testutil.CloseFile(t, file)
+
+	lines := 0
+	for scanner := bufio.NewScanner(file); scanner.Scan(); lines++ {
+		var record policy.AuditRecord
+		testutil.MustSucceed(t, json.Unmarshal(scanner.Bytes(), &record), "decoding audit record")
+		if record.Destination != "out.md" || len(record.Violations) != 1 || record.User == "" ||
+			record.RunID != "20261015T093012-9f3a61c2" {
+			t.Errorf("unexpected audit record: %+v", record)
+		}
+	}
+	if lines != 2 {
+		t.Errorf("audit log has %d records, want 2", lines)
+	}
+}
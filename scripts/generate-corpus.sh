@@ -0,0 +1,196 @@
+#!/bin/bash
+set -euo pipefail
+
+# Test Corpus Generator for gibidify
+# Writes the small fixture repositories used by the end-to-end tests (corpus_test.go).
+# The generated tree is committed under testdata/corpus; rerun this script after changing
+# it and commit the result. TestCorpusMatchesGenerator fails when the two drift apart.
+# Go fixtures are formatted with gofmt, which must be in PATH.
+#
+# Usage: scripts/generate-corpus.sh [output-dir]   (default: testdata/corpus)
+
+SCRIPT_DIR="$(cd "$(dirname "${BASH_SOURCE[0]}")" && pwd)"
+PROJECT_ROOT="$(cd "$SCRIPT_DIR/.." && pwd)"
+OUT="${1:-$PROJECT_ROOT/testdata/corpus}"
+
+# write PATH creates PATH under $OUT with the content read from stdin.
+write() {
+  mkdir -p "$(dirname "$OUT/$1")"
+  cat >"$OUT/$1"
+}
+
+# write_go PATH creates the Go file PATH under $OUT from stdin, formatted with gofmt.
+write_go() {
+  mkdir -p "$(dirname "$OUT/$1")"
+  gofmt >"$OUT/$1"
+}
+
+# write_bytes PATH FORMAT creates PATH under $OUT with the bytes of a printf FORMAT.
+write_bytes() {
+  mkdir -p "$(dirname "$OUT/$1")"
+  # shellcheck disable=SC2059 # the format carries the escaped bytes
+  printf "$2" >"$OUT/$1"
+}
+
+rm -rf "${OUT:?}/gomodule" "$OUT/nodeapp" "$OUT/pythonpkg" "$OUT/mixedassets" "$OUT/unicodepaths"
+
+# gomodule: a Go module with an internal package, a test and a vendored dependency.
+write gomodule/go.mod <<'GO'
+module example.com/corpus/gomodule
+
+go 1.22
+GO
+write_go gomodule/main.go <<'GO'
+package main
+
+import (
+  "fmt"
+
+  "example.com/corpus/gomodule/internal/greet"
+)
+
+func main() {
+  fmt.Println(greet.Hello("corpus"))
+}
+GO
+write_go gomodule/internal/greet/greet.go <<'GO'
+// Package greet builds greetings.
+package greet
+
+// Hello greets name.
+func Hello(name string) string {
+  return "Hello, " + name + "!"
+}
+GO
+write_go gomodule/internal/greet/greet_test.go <<'GO'
+package greet
+
+import "testing"
+
+func TestHello(t *testing.T) {
+  if got := Hello("go"); got != "Hello, go!" {
+    t.Errorf("Hello() = %q", got)
+  }
+}
+GO
+write gomodule/README.md <<'MD'
+# gomodule
+
+A tiny Go module used as a gibidify test fixture.
+MD
+write_go gomodule/vendor/example.com/dep/dep.go <<'GO'
+// Package dep is a vendored dependency that bundles leave out.
+package dep
+GO
+
+# nodeapp: a Node.js app with a lockfile, TypeScript source, ignored build output,
+# dependencies and a log file excluded through .ignore.
+write nodeapp/package.json <<'JSON'
+{
+  "name": "nodeapp",
+  "version": "1.0.0",
+  "main": "src/index.js",
+  "scripts": {
+    "start": "node src/index.js"
+  }
+}
+JSON
+write nodeapp/package-lock.json <<'JSON'
+{
+  "name": "nodeapp",
+  "version": "1.0.0",
+  "lockfileVersion": 3,
+  "requires": true,
+  "packages": {}
+}
+JSON
+write nodeapp/src/index.js <<'JS'
+const { pad } = require("./util");
+
+console.log(pad("corpus", 10));
+JS
+write nodeapp/src/util.ts <<'TS'
+export function pad(value: string, width: number): string {
+  return value.padStart(width, " ");
+}
+TS
+write nodeapp/.ignore <<'IGNORE'
+*.log
+IGNORE
+write nodeapp/npm-debug.log <<'LOG'
+npm ERR! this log is excluded by .ignore
+LOG
+write nodeapp/node_modules/left-pad/index.js <<'JS'
+module.exports = function leftPad() {};
+JS
+write nodeapp/dist/bundle.js <<'JS'
+console.log("build output is excluded");
+JS
+
+# pythonpkg: a Python package with a src layout and tests.
+write pythonpkg/pyproject.toml <<'TOML'
+[project]
+name = "pythonpkg"
+version = "0.1.0"
+TOML
+write pythonpkg/src/pythonpkg/__init__.py <<'PY'
+from .core import add
+
+__all__ = ["add"]
+PY
+write pythonpkg/src/pythonpkg/core.py <<'PY'
+def add(a: int, b: int) -> int:
+    """Return the sum of a and b."""
+    return a + b
+PY
+write pythonpkg/tests/test_core.py <<'PY'
+from pythonpkg import add
+
+
+def test_add():
+    assert add(2, 3) == 5
+PY
+
+# mixedassets: text files next to images, binary data and CRLF line endings.
+write mixedassets/docs/guide.md <<'MD'
+# Guide
+
+Text next to binary assets: only the text ends up in the bundle.
+MD
+write mixedassets/config.yaml <<'YAML'
+name: mixedassets
+assets:
+  - logo.png
+YAML
+write mixedassets/scripts/build.sh <<'SH'
+#!/bin/sh
+echo "building"
+SH
+write_bytes mixedassets/notes.txt 'first line\r\nsecond line\r\n'
+write_bytes mixedassets/assets/logo.png '\211PNG\r\n\032\n\000\000\000\rIHDR\000\000\000\001\000\000\000\001'
+write_bytes mixedassets/assets/icon.gif 'GIF89a\001\000\001\000\000\000\000;'
+write_bytes mixedassets/data/blob.bin '\000\001\002\003\377\376\375\374'
+write_bytes mixedassets/data/archive.zip 'PK\003\004\024\000\000\000\000\000'
+
+# unicodepaths: paths with emoji, spaces, accents and right-to-left scripts.
+write "unicodepaths/docs/🚀 launch plan.md" <<'MD'
+# Launch plan
+
+Paths are kept exactly as they are on disk.
+MD
+write "unicodepaths/src/שלום עולם.py" <<'PY'
+print("שלום עולם")
+PY
+write_go "unicodepaths/src/مرحبا/main.go" <<'GO'
+package main
+
+func main() {}
+GO
+write "unicodepaths/données/café crème.txt" <<'TXT'
+Café crème, s'il vous plaît.
+TXT
+write "unicodepaths/emoji 🎉/party.json" <<'JSON'
+{"party": "🎉"}
+JSON
+
+echo "Corpus written to $OUT"
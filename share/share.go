@@ -0,0 +1,172 @@
+// Package share uploads finished bundles to paste services, so a bundle can be handed over as a URL.
+package share
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ivuorinen/gibidify/github"
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+const (
+	// requestTimeout bounds an upload.
+	requestTimeout = 2 * time.Minute
+	// maxResponseSize caps how much of a paste endpoint response is read.
+	maxResponseSize = 64 * 1024
+)
+
+// Uploader uploads a bundle and returns the URL it can be read at.
+type Uploader interface {
+	// Upload uploads content as the file name.
+	Upload(ctx context.Context, name string, content []byte) (string, error)
+	// Describe names the service uploads go to, for the confirmation prompt.
+	Describe() string
+	// Host returns the host uploads go to, as policy allowedDestinations name it.
+	Host() string
+}
+
+// Gist uploads bundles as single-file GitHub gists.
+type Gist struct {
+	client *github.Client
+	host   string
+	public bool
+}
+
+// NewGist creates an uploader creating gists through the GitHub API at apiURL, authenticating
+// with token. Gists are secret unless public is set.
+func NewGist(apiURL, token string, public bool) *Gist {
+	return &Gist{client: github.NewClient(apiURL, token), host: GistHost(apiURL), public: public}
+}
+
+// GistHost returns the host of the GitHub API at apiURL, or of github.DefaultAPIURL when
+// apiURL is empty.
+func GistHost(apiURL string) string {
+	if apiURL == "" {
+		apiURL = github.DefaultAPIURL
+	}
+	if parsed, err := url.Parse(apiURL); err == nil && parsed.Host != "" {
+		return parsed.Host
+	}
+
+	return apiURL
+}
+
+// Upload creates a gist holding content as name.
+func (g *Gist) Upload(ctx context.Context, name string, content []byte) (string, error) {
+	return g.client.CreateGist(ctx, github.Gist{ //nolint:wrapcheck // errors are structured by the client
+		Description: "Bundle generated by " + shared.AppName,
+		Filename:    name,
+		Content:     string(content),
+		Public:      g.public,
+	})
+}
+
+// Describe names the gist visibility and the host of the API creating it.
+func (g *Gist) Describe() string {
+	if g.public {
+		return "a public GitHub gist at " + g.host
+	}
+
+	return "a secret GitHub gist at " + g.host
+}
+
+// Host returns the host of the API creating the gists.
+func (g *Gist) Host() string {
+	return g.host
+}
+
+// Paste uploads bundles to a paste endpoint: the bundle is the body of a POST request, named
+// in its Content-Disposition header, and the endpoint answers with the URL of the paste in its
+// Location header or as the first line of its response body.
+type Paste struct {
+	endpoint string
+	token    string
+	http     *http.Client
+}
+
+// NewPaste creates an uploader posting to endpoint, authenticating with token as a bearer
+// token unless it is empty.
+func NewPaste(endpoint, token string) *Paste {
+	return &Paste{endpoint: endpoint, token: token, http: &http.Client{Timeout: requestTimeout}}
+}
+
+// Upload posts content as name to the paste endpoint.
+func (p *Paste) Upload(ctx context.Context, name string, content []byte) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint, bytes.NewReader(content))
+	if err != nil {
+		return "", shared.WrapError(err, shared.ErrorTypeProcessing, shared.CodeProcessingShare, "building upload").
+			WithContext("url", p.endpoint)
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	req.Header.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+	if p.token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	}
+
+	resp, err := p.http.Do(req) // #nosec G107 -- the endpoint is configured in share.url
+	if err != nil {
+		return "", shared.WrapError(err, shared.ErrorTypeProcessing, shared.CodeProcessingShare, "upload failed").
+			WithContext("url", p.endpoint)
+	}
+	defer shared.SafeCloseReader(resp.Body, p.endpoint)
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return "", shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIORead, "reading paste response").
+			WithContext("url", p.endpoint)
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return "", shared.NewStructuredError(
+			shared.ErrorTypeProcessing, shared.CodeProcessingShare,
+			"paste endpoint returned "+resp.Status, "", map[string]any{"url": p.endpoint, "status": resp.StatusCode},
+		)
+	}
+
+	return pasteURL(resp, body)
+}
+
+// Describe names the paste endpoint host.
+func (p *Paste) Describe() string {
+	if host := p.Host(); host != "" {
+		return "the paste endpoint at " + host
+	}
+
+	return "the paste endpoint"
+}
+
+// Host returns the host of the paste endpoint, or "" when it has none.
+func (p *Paste) Host() string {
+	if parsed, err := url.Parse(p.endpoint); err == nil {
+		return parsed.Host
+	}
+
+	return ""
+}
+
+// pasteURL returns the absolute URL of the paste from the Location header of resp or the first
+// line of body.
+func pasteURL(resp *http.Response, body []byte) (string, error) {
+	candidate := resp.Header.Get("Location")
+	if candidate == "" {
+		line, _, _ := strings.Cut(string(body), "\n")
+		candidate = strings.TrimSpace(line)
+	}
+
+	location, err := url.Parse(candidate)
+	if err != nil || candidate == "" {
+		return "", shared.NewStructuredError(
+			shared.ErrorTypeProcessing, shared.CodeProcessingShare,
+			fmt.Sprintf("paste endpoint did not return a URL: %q", candidate), "", nil,
+		)
+	}
+
+	return resp.Request.URL.ResolveReference(location).String(), nil
+}
@@ -0,0 +1,87 @@
+package share_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/share"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// TestPasteUpload tests that bundles are posted with their name and token, and that the paste
+// URL is taken from the Location header or the response body.
+func TestPasteUpload(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		location string
+		body     string
+		want     string
+		wantErr  bool
+	}{
+		{name: "location header", status: http.StatusCreated, location: "/p/abc", want: "/p/abc"},
+		{name: "URL in body", status: http.StatusOK, body: "https://paste.example/xyz\nexpires in 7 days\n",
+			want: "https://paste.example/xyz"},
+		{name: "error status", status: http.StatusRequestEntityTooLarge, wantErr: true},
+		{name: "no URL", status: http.StatusOK, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var content, disposition, auth string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				data, _ := io.ReadAll(r.Body)
+				content, disposition, auth = string(data), r.Header.Get("Content-Disposition"), r.Header.Get("Authorization")
+				if tt.location != "" {
+					w.Header().Set("Location", tt.location)
+				}
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			paste := share.NewPaste(server.URL+"/upload", "secret")
+			url, err := paste.Upload(context.Background(), "bundle.md", []byte("# Bundle\n"))
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Upload() = %q, want an error", url)
+				}
+
+				return
+			}
+			testutil.MustSucceed(t, err, "Upload")
+			if want := strings.Replace(tt.want, "/p/", server.URL+"/p/", 1); url != want {
+				t.Errorf("Upload() = %q, want %q", url, want)
+			}
+			if content != "# Bundle\n" || !strings.Contains(disposition, `filename=bundle.md`) || auth != "Bearer secret" {
+				t.Errorf("request = %q, %q, %q, want the bundle named bundle.md with the token", content, disposition, auth)
+			}
+		})
+	}
+}
+
+// TestDescribe tests the service names shown when confirming an upload.
+func TestDescribe(t *testing.T) {
+	tests := []struct {
+		uploader share.Uploader
+		want     string
+	}{
+		{uploader: share.NewGist("", "", false), want: "a secret GitHub gist at api.github.com"},
+		{uploader: share.NewGist("", "", true), want: "a public GitHub gist at api.github.com"},
+		{
+			uploader: share.NewGist("https://ghe.example/api/v3", "", false),
+			want:     "a secret GitHub gist at ghe.example",
+		},
+		{uploader: share.NewPaste("https://paste.example/upload", ""), want: "the paste endpoint at paste.example"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.uploader.Describe(); got != tt.want {
+			t.Errorf("Describe() = %q, want %q", got, tt.want)
+		}
+	}
+}
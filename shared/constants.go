@@ -68,6 +68,10 @@ const (
 	ConfigHardMemoryLimitMBMin = 64
 	// ConfigHardMemoryLimitMBMax is the maximum hard memory limit (8192MB = 8GB).
 	ConfigHardMemoryLimitMBMax = 8192
+	// ContainerMemoryLimitPercent is the share of a container memory limit the default hard limit may use.
+	ContainerMemoryLimitPercent = 75
+	// CgroupUnlimitedMemory is the threshold above which a cgroup v1 memory limit means "no limit".
+	CgroupUnlimitedMemory = 1 << 62
 
 	// ConfigMaxPendingFilesDefault is the default maximum files in file channel buffer.
 	ConfigMaxPendingFilesDefault = 1000
@@ -88,12 +92,68 @@ const (
 	ConfigMarkdownHeaderLevelDefault = 0
 	// ConfigMarkdownMaxLineLengthDefault is the default maximum line length (0 = unlimited).
 	ConfigMarkdownMaxLineLengthDefault = 0
+	// ConfigMarkdownWrapCodeDefault is the default for hard-wrapping code lines longer than maxLineLength.
+	ConfigMarkdownWrapCodeDefault = false
+	// ConfigMarkdownWrapMarkerDefault is the default marker ending a hard-wrapped code line.
+	ConfigMarkdownWrapMarkerDefault = "\u21a9"
+
+	// ConfigGitAuthorThresholdDefault is the default minimum authorship share (percent) for --author.
+	ConfigGitAuthorThresholdDefault = 20.0
+	// ConfigGitAuthorThresholdMin is the minimum allowed authorship threshold (percent).
+	ConfigGitAuthorThresholdMin = 0.0
+	// ConfigGitAuthorThresholdMax is the maximum allowed authorship threshold (percent).
+	ConfigGitAuthorThresholdMax = 100.0
+	// ConfigGitChurnDaysDefault is the default window, in days, over which commits are counted as churn.
+	ConfigGitChurnDaysDefault = 90
+	// ConfigGitChurnDaysMin is the minimum allowed churn window in days.
+	ConfigGitChurnDaysMin = 1
+	// ConfigGitChurnDaysMax is the maximum allowed churn window in days (10 years).
+	ConfigGitChurnDaysMax = 3650
+	// ConfigGitChurnTopDefault is the default number of files in the top-churn section (0 = no section).
+	ConfigGitChurnTopDefault = 0
+	// ConfigGitLFSMaxFetchSizeDefault is the default largest Git LFS object (bytes) fetched (10MB).
+	ConfigGitLFSMaxFetchSizeDefault = 10 * BytesPerMB
+
+	// ConfigGeneratedTextMinSizeDefault is the size below which files are never inspected (16KB).
+	ConfigGeneratedTextMinSizeDefault = 16 * BytesPerKB
+	// ConfigGeneratedTextMaxLineLengthDefault is the longest line a non-minified file may have.
+	ConfigGeneratedTextMaxLineLengthDefault = 5000
+	// ConfigGeneratedTextEntropyDefault is the entropy (bits per byte) above which ASCII text looks encoded.
+	ConfigGeneratedTextEntropyDefault = 5.6
+	// ConfigGeneratedTextEntropyMax is the largest possible byte entropy (bits per byte).
+	ConfigGeneratedTextEntropyMax = 8.0
+	// ConfigOutputWhitespaceTabWidthDefault is the default tab stop width for indentation conversion.
+	ConfigOutputWhitespaceTabWidthDefault = 4
+	// ConfigOutputWhitespaceTabWidthMax is the largest allowed tab stop width.
+	ConfigOutputWhitespaceTabWidthMax = 16
+	// ConfigOutputBufferSizeDefault is the default size of the output write buffer (64KB).
+	ConfigOutputBufferSizeDefault = 64 * BytesPerKB
+	// ConfigOutputBufferSizeMax is the largest allowed output write buffer (64MB).
+	ConfigOutputBufferSizeMax = 64 * BytesPerMB
+
+	// GeneratedTextSampleSize is how much of a file is inspected for generated text (64KB).
+	GeneratedTextSampleSize = 64 * BytesPerKB
+	// ContentSniffSampleSize is how much of a file is inspected for binary content (8KB).
+	ContentSniffSampleSize = 8 * BytesPerKB
+	// DocLanguageSampleSize is how much of a prose file is read to detect its natural language (16KB).
+	DocLanguageSampleSize = 16 * BytesPerKB
+	// OpenFileReserve is how many file descriptors are left below the open file limit for the
+	// output, caches, logs and network connections when bounding the source files open at once.
+	OpenFileReserve = 64
+	// PrefetchMemoryShare is the fraction (1/n) of backpressure.maxMemoryUsage prefetched content
+	// may hold when performance.prefetchBytes is 0.
+	PrefetchMemoryShare = 4
+	// DocLanguageMinMatches is how many stop words of a language a sample needs before it is
+	// detected as written in that language.
+	DocLanguageMinMatches = 5
 )
 
 // Configuration Default Values - Boolean Constants
 const (
 	// ConfigFileTypesEnabledDefault is the default state for file type detection.
 	ConfigFileTypesEnabledDefault = true
+	// ConfigContentSniffingEnabledDefault is the default state for detecting binary files by their content.
+	ConfigContentSniffingEnabledDefault = true
 
 	// ConfigBackpressureEnabledDefault is the default state for backpressure.
 	ConfigBackpressureEnabledDefault = true
@@ -136,6 +196,98 @@ const (
 	ConfigMarkdownLineNumbersDefault = false
 	// ConfigMarkdownFoldLongFilesDefault is the default for folding long files.
 	ConfigMarkdownFoldLongFilesDefault = false
+
+	// ConfigGitEnabledDefault is the default state for git integration.
+	ConfigGitEnabledDefault = true
+	// ConfigGitBlameCacheDefault is the default state for the on-disk blame summary cache.
+	ConfigGitBlameCacheDefault = true
+	// ConfigGitChurnDefault is the default state for churn metadata from git history.
+	ConfigGitChurnDefault = false
+	// ConfigCodeOwnersEnabledDefault is the default state for CODEOWNERS annotation.
+	ConfigCodeOwnersEnabledDefault = true
+	// ConfigOutputSourceSpansDefault is the default for JSON source span offsets.
+	ConfigOutputSourceSpansDefault = false
+	// ConfigOutputFileIDsDefault is the default for emitting stable file IDs.
+	ConfigOutputFileIDsDefault = false
+	// ConfigOutputAppendRunSummaryDefault is the default for ending the bundle with a run summary.
+	ConfigOutputAppendRunSummaryDefault = false
+	// ConfigOutputConfigProvenanceDefault is the default for recording non-default settings in the bundle.
+	ConfigOutputConfigProvenanceDefault = false
+	// ConfigGeneratedTextEnabledDefault is the default state for minified/high-entropy text detection.
+	ConfigGeneratedTextEnabledDefault = true
+	// ConfigSecurityScanEnabledDefault is the default state for the security scan of included content.
+	ConfigSecurityScanEnabledDefault = false
+	// ConfigRedactionEnabledDefault is the default state for the secret redaction of included content.
+	ConfigRedactionEnabledDefault = false
+	// ConfigStripCommentsEnabledDefault is the default for removing comments from source files.
+	ConfigStripCommentsEnabledDefault = false
+	// ConfigPerformanceFormatWorkersDefault is the default number of goroutines rendering output entries.
+	ConfigPerformanceFormatWorkersDefault = 1
+	// ConfigPerformanceHashAlgorithmDefault is the default algorithm for file IDs and cache keys.
+	ConfigPerformanceHashAlgorithmDefault = HashSHA256
+	// ConfigShareBackendDefault is the default service --share uploads bundles to.
+	ConfigShareBackendDefault = ShareBackendGist
+	// ConfigShareMaxSizeDefault is the default largest bundle (bytes) --share uploads.
+	ConfigShareMaxSizeDefault = 10 * BytesPerMB
+	// ConfigSharePublicDefault is the default visibility of gists created by --share.
+	ConfigSharePublicDefault = false
+	// ConfigPerformanceContentCacheDefault is the default state for the on-disk transformed content cache.
+	ConfigPerformanceContentCacheDefault = true
+	// ConfigPerformanceMaxOpenFilesDefault is the default bound on source files open at once;
+	// 0 derives it from the open file limit of the process.
+	ConfigPerformanceMaxOpenFilesDefault = 0
+	// ConfigPerformanceRaiseOpenFileLimitDefault is the default for raising the soft open file limit.
+	ConfigPerformanceRaiseOpenFileLimitDefault = false
+	// ConfigPerformancePrefetchDefault is the default state for reading upcoming files ahead of the workers.
+	ConfigPerformancePrefetchDefault = false
+	// ConfigPerformancePrefetchBytesDefault is the default budget (bytes) of prefetched content;
+	// 0 gives prefetching a PrefetchMemoryShare of backpressure.maxMemoryUsage.
+	ConfigPerformancePrefetchBytesDefault = 0
+	// ConfigPerformancePrefetchReadersDefault is the default number of goroutines reading ahead.
+	ConfigPerformancePrefetchReadersDefault = 4
+	// ConfigUIProgressStyleDefault is the default progress display.
+	ConfigUIProgressStyleDefault = UIProgressStyleBar
+	// ConfigUIThemeDefault is the default set of UI markers.
+	ConfigUIThemeDefault = UIThemeAuto
+	// ConfigTokensEstimateDefault is the default for adding token estimates to the file metadata.
+	ConfigTokensEstimateDefault = false
+	// ConfigCodeMetricsEnabledDefault is the default for adding line counts and complexity to the file metadata.
+	ConfigCodeMetricsEnabledDefault = false
+	// ConfigTokensBytesPerTokenDefault is the default average number of bytes per LLM token, used
+	// for content in languages without a calibration.
+	ConfigTokensBytesPerTokenDefault = 4.0
+	// ConfigTokensBytesPerTokenMax is the largest accepted bytes-per-token ratio.
+	ConfigTokensBytesPerTokenMax = 100
+	// ConfigCollectionRespectGitignoreDefault is the default for leaving out files git ignores.
+	ConfigCollectionRespectGitignoreDefault = true
+	// ConfigCollectionGlobalGitignoreDefault is the default for honoring the user's global git excludes file.
+	ConfigCollectionGlobalGitignoreDefault = true
+	// ConfigCollectionOutlierPercentDefault is the default share of the collected size, in percent, above
+	// which a single file is reported as an outlier.
+	ConfigCollectionOutlierPercentDefault = 50
+	// CompactEllipsis marks the end of a line truncated by --compact.
+	CompactEllipsis = "…"
+	// CollectionOutlierMinFiles is the number of collected files below which outliers are not looked for.
+	CollectionOutlierMinFiles = 5
+	// ConfigDocLanguageDetectDefault is the default for detecting the natural language of prose files.
+	ConfigDocLanguageDetectDefault = false
+	// ConfigWarningsSlowFileSecDefault is the default time in seconds after which a file is reported as slow.
+	ConfigWarningsSlowFileSecDefault = 5
+	// ConfigOutputWhitespaceTrimTrailingDefault is the default for trimming trailing whitespace.
+	ConfigOutputWhitespaceTrimTrailingDefault = false
+	// ConfigOutputCompactEnabledDefault is the default for compacting file content as --compact does.
+	ConfigOutputCompactEnabledDefault = false
+	// ConfigOutputCompactCollapseBlankLinesDefault is the default for collapsing runs of blank lines when compacting.
+	ConfigOutputCompactCollapseBlankLinesDefault = true
+	// ConfigOutputCompactTrimTrailingDefault is the default for trimming trailing whitespace when compacting.
+	ConfigOutputCompactTrimTrailingDefault = true
+	// ConfigOutputCompactMaxLineLengthDefault is the default length in bytes past which compacting truncates
+	// a line; 0 keeps long lines whole.
+	ConfigOutputCompactMaxLineLengthDefault = 0
+	// ConfigOutputSanitizeStripBOMDefault is the default for stripping UTF-8 byte order marks.
+	ConfigOutputSanitizeStripBOMDefault = true
+	// ConfigOutputSanitizeInvisibleDefault is the default for removing zero-width and bidi control characters.
+	ConfigOutputSanitizeInvisibleDefault = false
 )
 
 // Configuration Default Values - String Constants
@@ -144,6 +296,34 @@ const (
 	ConfigOutputTemplateDefault = ""
 	// ConfigMarkdownCustomCSSDefault is the default custom CSS.
 	ConfigMarkdownCustomCSSDefault = ""
+	// ConfigMarkdownSectionPlaceholderDefault is the default placeholder block after file sections (empty = none).
+	ConfigMarkdownSectionPlaceholderDefault = ""
+	// PlainDelimiterPath is the placeholder in output.plain.delimiter replaced by the file path.
+	PlainDelimiterPath = "{{path}}"
+	// ConfigPlainDelimiterDefault is the default line starting every file of a plain bundle.
+	ConfigPlainDelimiterDefault = "===== {{path}} ====="
+	// PDFPageSizeA4 is the output.pdf.pageSize of ISO A4 pages, 210 by 297 mm.
+	PDFPageSizeA4 = "a4"
+	// PDFPageSizeLetter is the output.pdf.pageSize of US Letter pages, 8.5 by 11 inches.
+	PDFPageSizeLetter = "letter"
+	// ConfigPDFPageSizeDefault is the default page size of PDF bundles.
+	ConfigPDFPageSizeDefault = PDFPageSizeA4
+	// ConfigPDFFontSizeDefault is the default font size of PDF bundles, in points.
+	ConfigPDFFontSizeDefault = 8
+	// ConfigPDFFontSizeMin is the smallest output.pdf.fontSize accepted.
+	ConfigPDFFontSizeMin = 5
+	// ConfigPDFFontSizeMax is the largest output.pdf.fontSize accepted.
+	ConfigPDFFontSizeMax = 24
+	// BudgetFallbackTruncate cuts the files of a directory over its budget to a share of it.
+	BudgetFallbackTruncate = "truncate"
+	// BudgetFallbackOutline reduces the files of a directory over its budget to their declarations.
+	BudgetFallbackOutline = "outline"
+	// BudgetFallbackRollup summarizes the files of a directory over its budget as one entry.
+	BudgetFallbackRollup = "rollup"
+	// WasmExitLeaveOut is the exit code a WASM transform exits with to leave its file out of the bundle.
+	WasmExitLeaveOut = 10
+	// WasmMemoryLimitPages caps the memory of a WASM transform, in 64 KiB pages (64 MiB).
+	WasmMemoryLimitPages = 1024
 	// ConfigCustomHeaderDefault is the default custom header template.
 	ConfigCustomHeaderDefault = ""
 	// ConfigCustomFooterDefault is the default custom footer template.
@@ -152,6 +332,32 @@ const (
 	ConfigCustomFileHeaderDefault = ""
 	// ConfigCustomFileFooterDefault is the default custom file footer template.
 	ConfigCustomFileFooterDefault = ""
+	// ConfigCodeOwnersPathDefault is the default CODEOWNERS path (empty = auto-discover).
+	ConfigCodeOwnersPathDefault = ""
+	// ConfigGeneratedTextActionDefault is the default handling of generated-looking text.
+	ConfigGeneratedTextActionDefault = GeneratedTextActionSkip
+	// ConfigGitLFSActionDefault is the default handling of Git LFS pointer files.
+	ConfigGitLFSActionDefault = LFSActionSkip
+	// ConfigOutputNormalizeLineEndingsDefault is the default line-ending normalization.
+	ConfigOutputNormalizeLineEndingsDefault = LineEndingsPreserve
+	// ConfigOutputWhitespaceIndentDefault is the default indentation conversion.
+	ConfigOutputWhitespaceIndentDefault = IndentPreserve
+	// IndentPreserve leaves tabs and spaces untouched.
+	IndentPreserve = "preserve"
+	// IndentSpaces expands tabs to spaces at each tab stop.
+	IndentSpaces = "spaces"
+	// IndentTabs converts leading spaces to tabs.
+	IndentTabs = "tabs"
+	// GeneratedTextActionSkip leaves generated-looking files out of the bundle.
+	GeneratedTextActionSkip = "skip"
+	// GeneratedTextActionSummarize replaces the content of generated-looking files with a one-line summary.
+	GeneratedTextActionSummarize = "summarize"
+	// LFSActionSkip leaves Git LFS pointer files out of the bundle.
+	LFSActionSkip = "skip"
+	// LFSActionInclude bundles Git LFS pointer files as the pointer text.
+	LFSActionInclude = "include"
+	// LFSActionFetch bundles the content of the object a Git LFS pointer refers to.
+	LFSActionFetch = "fetch"
 )
 
 // Configuration Keys - Viper Path Constants
@@ -181,6 +387,8 @@ const (
 	ConfigKeyFileTypesDisabledBinaryExtensions = "fileTypes.disabledBinaryExtensions"
 	// ConfigKeyFileTypesDisabledLanguageExts is the config key for fileTypes.disabledLanguageExtensions.
 	ConfigKeyFileTypesDisabledLanguageExts = "fileTypes.disabledLanguageExtensions"
+	// ConfigKeyContentSniffingEnabled is the config key for fileTypes.contentSniffing.enabled.
+	ConfigKeyContentSniffingEnabled = "fileTypes.contentSniffing.enabled"
 
 	// ConfigKeyBackpressureEnabled is the config key for backpressure.enabled.
 	ConfigKeyBackpressureEnabled = "backpressure.enabled"
@@ -220,8 +428,22 @@ const (
 	ConfigKeyOutputMarkdownHeaderLevel = "output.markdown.headerLevel"
 	// ConfigKeyOutputMarkdownMaxLineLen is the config key for output.markdown.maxLineLength.
 	ConfigKeyOutputMarkdownMaxLineLen = "output.markdown.maxLineLength"
+	// ConfigKeyOutputMarkdownWrapCode is the config key for output.markdown.wrapCode.
+	ConfigKeyOutputMarkdownWrapCode = "output.markdown.wrapCode"
+	// ConfigKeyOutputMarkdownWrapMarker is the config key for output.markdown.wrapMarker.
+	ConfigKeyOutputMarkdownWrapMarker = "output.markdown.wrapMarker"
 	// ConfigKeyOutputMarkdownCustomCSS is the config key for output.markdown.customCSS.
 	ConfigKeyOutputMarkdownCustomCSS = "output.markdown.customCSS"
+	// ConfigKeyOutputMarkdownSectionPlaceholder is the config key for output.markdown.sectionPlaceholder.
+	ConfigKeyOutputMarkdownSectionPlaceholder = "output.markdown.sectionPlaceholder"
+	// ConfigKeyOutputPlainDelimiter is the config key for output.plain.delimiter.
+	ConfigKeyOutputPlainDelimiter = "output.plain.delimiter"
+	// ConfigKeyOutputPDFPageSize is the config key for output.pdf.pageSize.
+	ConfigKeyOutputPDFPageSize = "output.pdf.pageSize"
+	// ConfigKeyOutputPDFFontSize is the config key for output.pdf.fontSize.
+	ConfigKeyOutputPDFFontSize = "output.pdf.fontSize"
+	// ConfigKeyOutputPlugins is the config key for output.plugins.
+	ConfigKeyOutputPlugins = "output.plugins"
 	// ConfigKeyOutputCustomHeader is the config key for output.custom.header.
 	ConfigKeyOutputCustomHeader = "output.custom.header"
 	// ConfigKeyOutputCustomFooter is the config key for output.custom.footer.
@@ -232,6 +454,150 @@ const (
 	ConfigKeyOutputCustomFileFooter = "output.custom.fileFooter"
 	// ConfigKeyOutputVariables is the config key for output.variables.
 	ConfigKeyOutputVariables = "output.variables"
+	// ConfigKeyOutputPrelude is the config key for output.prelude.
+	ConfigKeyOutputPrelude = "output.prelude"
+	// ConfigKeyOutputSourceSpans is the config key for output.sourceSpans.
+	ConfigKeyOutputSourceSpans = "output.sourceSpans"
+	// ConfigKeyOutputFileIDs is the config key for output.fileIds.
+	ConfigKeyOutputFileIDs = "output.fileIds"
+	// ConfigKeyOutputAppendRunSummary is the config key for output.appendRunSummary.
+	ConfigKeyOutputAppendRunSummary = "output.appendRunSummary"
+	// ConfigKeyOutputConfigProvenance is the config key for output.configProvenance.
+	ConfigKeyOutputConfigProvenance = "output.configProvenance"
+	// ConfigKeyOutputNormalizeLineEndings is the config key for output.normalizeLineEndings.
+	ConfigKeyOutputNormalizeLineEndings = "output.normalizeLineEndings"
+	// ConfigKeyOutputWhitespace is the config key prefix for output.whitespace settings.
+	ConfigKeyOutputWhitespace = "output.whitespace"
+	// ConfigKeyOutputWhitespaceIndent is the config key for output.whitespace.indent.
+	ConfigKeyOutputWhitespaceIndent = "output.whitespace.indent"
+	// ConfigKeyOutputWhitespaceTabWidth is the config key for output.whitespace.tabWidth.
+	ConfigKeyOutputWhitespaceTabWidth = "output.whitespace.tabWidth"
+	// ConfigKeyOutputWhitespaceTrimTrailing is the config key for output.whitespace.trimTrailing.
+	ConfigKeyOutputWhitespaceTrimTrailing = "output.whitespace.trimTrailing"
+	// ConfigKeyOutputWhitespaceLanguages is the config key for per-language output.whitespace.languages overrides.
+	ConfigKeyOutputWhitespaceLanguages = "output.whitespace.languages"
+	// ConfigKeyOutputCompactEnabled is the config key for output.compact.enabled.
+	ConfigKeyOutputCompactEnabled = "output.compact.enabled"
+	// ConfigKeyOutputCompactCollapseBlankLines is the config key for output.compact.collapseBlankLines.
+	ConfigKeyOutputCompactCollapseBlankLines = "output.compact.collapseBlankLines"
+	// ConfigKeyOutputCompactTrimTrailing is the config key for output.compact.trimTrailing.
+	ConfigKeyOutputCompactTrimTrailing = "output.compact.trimTrailing"
+	// ConfigKeyOutputCompactMaxLineLength is the config key for output.compact.maxLineLength.
+	ConfigKeyOutputCompactMaxLineLength = "output.compact.maxLineLength"
+	// ConfigKeyOutputSanitizeStripBOM is the config key for output.sanitize.stripBOM.
+	ConfigKeyOutputSanitizeStripBOM = "output.sanitize.stripBOM"
+	// ConfigKeyOutputSanitizeInvisible is the config key for output.sanitize.invisibleChars.
+	ConfigKeyOutputSanitizeInvisible = "output.sanitize.invisibleChars"
+	// ConfigKeyOutputBufferSize is the config key for output.bufferSize.
+	ConfigKeyOutputBufferSize = "output.bufferSize"
+
+	// ConfigKeyGitEnabled is the config key for git.enabled.
+	ConfigKeyGitEnabled = "git.enabled"
+	// ConfigKeyGitAuthorThreshold is the config key for git.authorThreshold.
+	ConfigKeyGitAuthorThreshold = "git.authorThreshold"
+	// ConfigKeyGitBlameCache is the config key for git.blameCache.
+	ConfigKeyGitBlameCache = "git.blameCache"
+	// ConfigKeyGitChurn is the config key for git.churn.
+	ConfigKeyGitChurn = "git.churn"
+	// ConfigKeyGitChurnDays is the config key for git.churnDays.
+	ConfigKeyGitChurnDays = "git.churnDays"
+	// ConfigKeyGitChurnTop is the config key for git.churnTop.
+	ConfigKeyGitChurnTop = "git.churnTop"
+	// ConfigKeyGitLFSAction is the config key for git.lfs.action.
+	ConfigKeyGitLFSAction = "git.lfs.action"
+	// ConfigKeyGitLFSMaxFetchSize is the config key for git.lfs.maxFetchSize.
+	ConfigKeyGitLFSMaxFetchSize = "git.lfs.maxFetchSize"
+
+	// ConfigKeyCodeOwnersEnabled is the config key for codeowners.enabled.
+	ConfigKeyCodeOwnersEnabled = "codeowners.enabled"
+	// ConfigKeyCodeOwnersPath is the config key for codeowners.path.
+	ConfigKeyCodeOwnersPath = "codeowners.path"
+
+	// ConfigKeyAnnotations is the config key for annotations.
+	ConfigKeyAnnotations = "annotations"
+	// ConfigKeyProfiles is the config key for profiles.
+	ConfigKeyProfiles = "profiles"
+	// ProfileKeyFormat is the profile setting naming the output format used unless -format is given.
+	ProfileKeyFormat = "format"
+
+	// ConfigKeyGeneratedTextEnabled is the config key for generatedText.enabled.
+	ConfigKeyGeneratedTextEnabled = "generatedText.enabled"
+	// ConfigKeyGeneratedTextAction is the config key for generatedText.action.
+	ConfigKeyGeneratedTextAction = "generatedText.action"
+	// ConfigKeyGeneratedTextMinSize is the config key for generatedText.minSize.
+	ConfigKeyGeneratedTextMinSize = "generatedText.minSize"
+	// ConfigKeyGeneratedTextMaxLineLength is the config key for generatedText.maxLineLength.
+	ConfigKeyGeneratedTextMaxLineLength = "generatedText.maxLineLength"
+	// ConfigKeyGeneratedTextEntropy is the config key for generatedText.entropyThreshold.
+	ConfigKeyGeneratedTextEntropy = "generatedText.entropyThreshold"
+	// ConfigKeySecurityScanEnabled is the config key for securityScan.enabled.
+	ConfigKeySecurityScanEnabled = "securityScan.enabled"
+	// ConfigKeyRedactionEnabled is the config key for redaction.enabled.
+	ConfigKeyRedactionEnabled = "redaction.enabled"
+	// ConfigKeyRedactionPatterns is the config key for redaction.patterns.
+	ConfigKeyRedactionPatterns = "redaction.patterns"
+	// ConfigKeyStripCommentsEnabled is the config key for stripComments.enabled.
+	ConfigKeyStripCommentsEnabled = "stripComments.enabled"
+	// ConfigKeyStripCommentsLanguages is the config key for stripComments.languages.
+	ConfigKeyStripCommentsLanguages = "stripComments.languages"
+	// ConfigKeyPerformanceFormatWorkers is the config key for performance.formatWorkers.
+	ConfigKeyPerformanceFormatWorkers = "performance.formatWorkers"
+	// ConfigKeyPerformanceHashAlgorithm is the config key for performance.hashAlgorithm.
+	ConfigKeyPerformanceHashAlgorithm = "performance.hashAlgorithm"
+	// ConfigKeyShareBackend is the config key for share.backend.
+	ConfigKeyShareBackend = "share.backend"
+	// ConfigKeyShareURL is the config key for share.url.
+	ConfigKeyShareURL = "share.url"
+	// ConfigKeyShareTokenEnv is the config key for share.tokenEnv.
+	ConfigKeyShareTokenEnv = "share.tokenEnv"
+	// ConfigKeyShareMaxSize is the config key for share.maxSize.
+	ConfigKeyShareMaxSize = "share.maxSize"
+	// ConfigKeySharePublic is the config key for share.public.
+	ConfigKeySharePublic = "share.public"
+	// ConfigKeyPerformanceContentCache is the config key for performance.contentCache.
+	ConfigKeyPerformanceContentCache = "performance.contentCache"
+	// ConfigKeyPerformanceMaxOpenFiles is the config key for performance.maxOpenFiles.
+	ConfigKeyPerformanceMaxOpenFiles = "performance.maxOpenFiles"
+	// ConfigKeyPerformanceRaiseOpenFileLimit is the config key for performance.raiseOpenFileLimit.
+	ConfigKeyPerformanceRaiseOpenFileLimit = "performance.raiseOpenFileLimit"
+	// ConfigKeyPerformancePrefetch is the config key for performance.prefetch.
+	ConfigKeyPerformancePrefetch = "performance.prefetch"
+	// ConfigKeyPerformancePrefetchBytes is the config key for performance.prefetchBytes.
+	ConfigKeyPerformancePrefetchBytes = "performance.prefetchBytes"
+	// ConfigKeyPerformancePrefetchReaders is the config key for performance.prefetchReaders.
+	ConfigKeyPerformancePrefetchReaders = "performance.prefetchReaders"
+	// ConfigKeyUIProgressStyle is the config key for ui.progressStyle.
+	ConfigKeyUIProgressStyle = "ui.progressStyle"
+	// ConfigKeyUITheme is the config key for ui.theme.
+	ConfigKeyUITheme = "ui.theme"
+	// ConfigKeyWarningsSlowFileSec is the config key for warnings.slowFileSec.
+	ConfigKeyWarningsSlowFileSec = "warnings.slowFileSec"
+	// ConfigKeyTokensEstimate is the config key for tokens.estimate.
+	ConfigKeyTokensEstimate = "tokens.estimate"
+	// ConfigKeyCodeMetricsEnabled is the config key for codeMetrics.enabled.
+	ConfigKeyCodeMetricsEnabled = "codeMetrics.enabled"
+	// ConfigKeyTokensBytesPerToken is the config key for tokens.bytesPerToken.
+	ConfigKeyTokensBytesPerToken = "tokens.bytesPerToken"
+	// ConfigKeyTokensLanguages is the config key for tokens.languages.
+	ConfigKeyTokensLanguages = "tokens.languages"
+	// ConfigKeyDocLanguageDetect is the config key for docLanguage.detect.
+	ConfigKeyDocLanguageDetect = "docLanguage.detect"
+	// ConfigKeyDocLanguageInclude is the config key for docLanguage.include.
+	ConfigKeyDocLanguageInclude = "docLanguage.include"
+	// ConfigKeyCollectionRollups is the config key for collection.rollups.
+	ConfigKeyCollectionRollups = "collection.rollups"
+	// ConfigKeyCollectionRespectGitignore is the config key for collection.respectGitignore.
+	ConfigKeyCollectionRespectGitignore = "collection.respectGitignore"
+	// ConfigKeyCollectionGlobalGitignore is the config key for collection.globalGitignore.
+	ConfigKeyCollectionGlobalGitignore = "collection.globalGitignore"
+	// ConfigKeyCollectionOutlierPercent is the config key for collection.outlierPercent.
+	ConfigKeyCollectionOutlierPercent = "collection.outlierPercent"
+	// ConfigKeyBudgets is the config key for budgets.
+	ConfigKeyBudgets = "budgets"
+	// ConfigKeyOrderPriority is the config key for order.priority.
+	ConfigKeyOrderPriority = "order.priority"
+	// ConfigKeyTransformsWasm is the config key for transforms.wasm.
+	ConfigKeyTransformsWasm = "transforms.wasm"
 )
 
 // Configuration Collections - Slice and Map Variables
@@ -263,11 +629,74 @@ var (
 	// ConfigTemplateVariablesDefault is the default template variables.
 	ConfigTemplateVariablesDefault = map[string]string{}
 
+	// ConfigAnnotationsDefault is the default file notes (empty = none).
+	ConfigAnnotationsDefault = map[string]string{}
+
+	// ConfigProfilesDefault is the default named profiles selected with --profile (empty = none).
+	ConfigProfilesDefault = map[string]any{}
+
 	// ConfigSupportedFormatsDefault is the default list of supported output formats.
-	ConfigSupportedFormatsDefault = []string{"json", "yaml", "markdown"}
+	ConfigSupportedFormatsDefault = []string{"json", "yaml", "markdown", "plain", "pdf", "zip"}
 
 	// ConfigFilePatternsDefault is the default list of file patterns (empty = all files).
 	ConfigFilePatternsDefault = []string{}
+
+	// ConfigOutputPreludeDefault is the default list of prelude documents (empty = none).
+	ConfigOutputPreludeDefault = []string{}
+
+	// ConfigOutputPluginsDefault is the default external format plugins by format name (empty = none).
+	ConfigOutputPluginsDefault = map[string]any{}
+
+	// ConfigOutputWhitespaceLanguagesDefault is the default per-language output.whitespace
+	// overrides (empty = none).
+	ConfigOutputWhitespaceLanguagesDefault = map[string]any{}
+
+	// ConfigDocLanguageIncludeDefault is the default list of documentation languages (empty = all).
+	ConfigDocLanguageIncludeDefault = []string{}
+
+	// ConfigCollectionRollupsDefault is the default list of rollup globs (empty = none).
+	ConfigCollectionRollupsDefault = []string{}
+
+	// ConfigBudgetsDefault is the default size budgets by directory glob (empty = none).
+	ConfigBudgetsDefault = map[string]any{}
+
+	// ConfigOrderPriorityDefault is the default list of priority globs for --order priority (empty = none).
+	ConfigOrderPriorityDefault = []string{}
+
+	// ConfigTransformsWasmDefault is the default list of WASM transforms (empty = none).
+	ConfigTransformsWasmDefault = []any{}
+
+	// ConfigRedactionPatternsDefault is the default list of custom redaction rules (empty = built-in rules only).
+	ConfigRedactionPatternsDefault = []any{}
+
+	// OrderStrategies lists the strategies --order can order files by.
+	OrderStrategies = []string{
+		OrderAlphabetical, OrderSize, OrderRecency, OrderDepth, OrderPriority, OrderComplexity,
+	}
+
+	// BudgetFallbacks lists the fallbacks a budget can apply to the files over it.
+	BudgetFallbacks = []string{BudgetFallbackTruncate, BudgetFallbackOutline, BudgetFallbackRollup}
+
+	// CommentStripLanguages lists the languages comments can be stripped from.
+	CommentStripLanguages = []string{
+		"go", "c", "cpp", "csharp", "java", "javascript", "typescript", "rust", "kotlin", "swift", "scala", "python",
+	}
+
+	// ConfigStripCommentsLanguagesDefault is the default list of languages comments are stripped from (all).
+	ConfigStripCommentsLanguagesDefault = CommentStripLanguages
+
+	// DocLanguagesSupported lists the ISO 639-1 codes of the natural languages prose files are
+	// detected in.
+	DocLanguagesSupported = []string{"de", "en", "es", "fi", "fr", "nl", "sv"}
+
+	// ConfigTokensLanguagesDefault is the default bytes-per-token calibration by language. Prose
+	// averages about four bytes per token; punctuation-heavy code and data formats fewer.
+	ConfigTokensLanguagesDefault = map[string]any{
+		FormatMarkdown: 4.2, "rst": 4.2, "latex": 3.6,
+		"go": 3.3, "python": 3.5, "javascript": 3.2, "typescript": 3.2, "java": 3.6, "kotlin": 3.5,
+		"csharp": 3.5, "c": 3.2, "cpp": 3.2, "rust": 3.2, "ruby": 3.5, "php": 3.3, "bash": 3.3,
+		"sql": 3.6, "html": 3.0, "css": 3.0, "xml": 2.9, FormatJSON: 2.8, FormatYAML: 3.4, "toml": 3.4,
+	}
 )
 
 // Test Paths and Files
@@ -622,8 +1051,55 @@ const (
 
 // CLI UI Constants
 const (
+	// PreviewDiffMaxPaths is the number of added, removed or changed paths --preview-diff lists of each.
+	PreviewDiffMaxPaths = 20
 	// UIProgressBarChar is the character used for progress bar display.
 	UIProgressBarChar = "█"
+	// UIProgressBarCharASCII is the progress bar character in the ASCII theme.
+	UIProgressBarCharASCII = "#"
+	// UIProgressDots is the number of dots the dots progress style prints for a complete run.
+	UIProgressDots = 50
+
+	// UIProgressStyleBar shows a progress bar with the file count and rate.
+	UIProgressStyleBar = "bar"
+	// UIProgressStyleSpinner shows a spinner with the file count and rate.
+	UIProgressStyleSpinner = "spinner"
+	// UIProgressStyleDots prints a line of dots, without redrawing, for consoles and logs.
+	UIProgressStyleDots = "dots"
+	// UIProgressStyleNone shows no progress.
+	UIProgressStyleNone = "none"
+
+	// UIThemeAuto uses the ASCII theme when the terminal or locale cannot show Unicode.
+	UIThemeAuto = "auto"
+	// UIThemeUnicode marks messages with Unicode symbols and emoji.
+	UIThemeUnicode = "unicode"
+	// UIThemeASCII marks messages with ASCII text only.
+	UIThemeASCII = "ascii"
+
+	// ShareBackendGist uploads shared bundles as GitHub gists.
+	ShareBackendGist = "gist"
+	// ShareBackendPaste uploads shared bundles to the paste endpoint in share.url.
+	ShareBackendPaste = "paste"
+
+	// TestsInclude bundles test files along with the other files.
+	TestsInclude = "include"
+	// TestsExclude leaves test files out of the bundle.
+	TestsExclude = "exclude"
+	// TestsOnly bundles test files alone.
+	TestsOnly = "only"
+
+	// OrderAlphabetical orders files by their path relative to the source directory.
+	OrderAlphabetical = "alphabetical"
+	// OrderSize orders files from the smallest to the largest.
+	OrderSize = "size"
+	// OrderRecency orders files from the most to the least recently committed.
+	OrderRecency = "recency"
+	// OrderDepth orders files from the shallowest to the most deeply nested.
+	OrderDepth = "depth"
+	// OrderPriority orders files by the first order.priority glob matching them.
+	OrderPriority = "priority"
+	// OrderComplexity orders files from the most to the least complex.
+	OrderComplexity = "complexity"
 )
 
 // Error Format Strings
@@ -642,6 +1118,11 @@ const (
 	FileProcessingStreamThreshold = BytesPerMB
 	// FileProcessingMaxMemoryBuffer is the maximum memory to use for buffering content (10MB).
 	FileProcessingMaxMemoryBuffer = 10 * BytesPerMB
+	// BufferPoolScratchSize is the initial capacity of pooled escape and writer scratch buffers (4KB).
+	BufferPoolScratchSize = 4 * BytesPerKB
+	// BufferPoolMaxSize is the capacity above which buffers are left to the GC instead of pooled,
+	// so one large entry does not pin its memory for the rest of the run (2MB).
+	BufferPoolMaxSize = 2 * FileProcessingStreamThreshold
 )
 
 // File Processing Error Messages
@@ -662,10 +1143,59 @@ const (
 	MetricsPhaseWriting = "writing"
 	// MetricsPhaseFinalize represents the finalize phase.
 	MetricsPhaseFinalize = "finalize"
+
+	// MetricsPhaseRead represents the time spent reading one file.
+	MetricsPhaseRead = "read"
+	// MetricsPhaseTransform represents the time spent transforming the content of one file.
+	MetricsPhaseTransform = "transform"
+	// MetricsPhaseFormat represents the time spent formatting the output section of one file.
+	MetricsPhaseFormat = "format"
+	// MetricsPhaseWrite represents the time spent writing the output section of one file.
+	MetricsPhaseWrite = "write"
+
+	// SkipReasonSizeLimit counts files over the file size limit or the total size limit.
+	SkipReasonSizeLimit = "size_limit"
+	// SkipReasonBinary counts binary and image files left out during collection, and files whose
+	// content looks binary.
+	SkipReasonBinary = "binary"
+	// SkipReasonIgnored counts files matched by .gitignore or .ignore rules. Files under
+	// ignored directories are not walked and so not counted.
+	SkipReasonIgnored = "ignored"
+	// SkipReasonGenerated counts files skipped as minified or encoded text.
+	SkipReasonGenerated = "generated"
+	// SkipReasonDocLanguage counts prose files left out for their natural language.
+	SkipReasonDocLanguage = "doc_language"
+	// SkipReasonTransform counts files a WASM transform left out.
+	SkipReasonTransform = "transform"
+	// SkipReasonLFSPointer counts Git LFS pointer files left out, or whose object was not fetched.
+	SkipReasonLFSPointer = "lfs_pointer"
+	// SkipReasonOutlier counts files excluded for taking more than collection.outlierPercent of the
+	// collected size.
+	SkipReasonOutlier = "outlier"
+	// SkipReasonError counts files that failed to process.
+	SkipReasonError = "error"
+
+	// WarningEncoding warns about a file that is not valid UTF-8; JSON and YAML bundles
+	// replace its invalid bytes with U+FFFD.
+	WarningEncoding = "encoding"
+	// WarningFenceCollision warns about a file with lines that close the Markdown code fence around it.
+	WarningFenceCollision = "fence_collision"
+	// WarningSlowFile warns about a file that took longer than warnings.slowFileSec to process.
+	WarningSlowFile = "slow_file"
+
 	// MetricsMaxInt64 is the maximum int64 value for initial smallest file tracking.
 	MetricsMaxInt64 = int64(^uint64(0) >> 1)
 	// MetricsPerformanceIndexCap is the maximum performance index value for reasonable indexing.
 	MetricsPerformanceIndexCap = 1000
+	// MetricsTopSlowestFiles is the number of slowest files listed in the verbose report.
+	MetricsTopSlowestFiles = 10
+	// MetricsMaxWarnings is the number of warnings kept with their path and message; further
+	// warnings are only counted.
+	MetricsMaxWarnings = 100
+	// MetricsWarningExamples is the number of warnings listed per kind in the run output.
+	MetricsWarningExamples = 3
+	// MetricsHotspotBarWidth is the width of the phase bar drawn for each file in the hotspot report.
+	MetricsHotspotBarWidth = 40
 )
 
 // Metrics Format Strings
@@ -680,13 +1210,122 @@ const (
 	MetricsFmtBytesHuman = "%.1f%cB"
 )
 
+// ============================================================================
+// PER-FILE METADATA KEYS
+// ============================================================================
+
+const (
+	// MetadataKeyOwners is the per-file metadata key listing CODEOWNERS owners.
+	MetadataKeyOwners = "owners"
+	// MetadataKeyRole is the per-file metadata key marking entries that are not source files.
+	MetadataKeyRole = "role"
+	// MetadataRolePatch marks diff entries: the appended --from-patch diff and pull request file diffs.
+	MetadataRolePatch = "patch"
+	// MetadataRoleDescription marks the pull request description entry.
+	MetadataRoleDescription = "description"
+	// MetadataRoleIssue marks linked issue entries.
+	MetadataRoleIssue = "issue"
+	// MetadataRolePrelude marks context documents placed before the file sections.
+	MetadataRolePrelude = "prelude"
+	// MetadataRoleRollup marks entries summarizing the files matched by a collection.rollups glob.
+	MetadataRoleRollup = "rollup"
+	// MetadataRoleChurn marks the summary entry listing the files with the most commits (git.churnTop).
+	MetadataRoleChurn = "churn"
+	// MetadataKeyURL is the per-file metadata key holding the entry's web URL.
+	MetadataKeyURL = "url"
+	// MetadataKeyTitle is the per-file metadata key holding a pull request or issue title.
+	MetadataKeyTitle = "title"
+	// MetadataKeyStatus is the per-file metadata key holding a pull request file's change status.
+	MetadataKeyStatus = "status"
+	// MetadataKeyPreviousPath is the per-file metadata key holding a renamed file's former path.
+	MetadataKeyPreviousPath = "previous_path"
+	// MetadataKeyChanges is the per-file metadata key summarizing added and deleted lines.
+	MetadataKeyChanges = "changes"
+	// MetadataKeyID is the per-file metadata key holding the entry's stable short ID.
+	MetadataKeyID = "id"
+	// MetadataKeyOmitted is the per-file metadata key explaining why an entry's content was summarized.
+	MetadataKeyOmitted = "omitted"
+	// MetadataKeyLFS is the per-file metadata key holding the object ID of fetched Git LFS content.
+	MetadataKeyLFS = "lfs"
+	// MetadataKeyLineEndings is the per-file metadata key recording a normalized file's original line endings.
+	MetadataKeyLineEndings = "line_endings"
+	// MetadataKeySecurity is the per-file metadata key warning about zero-width or bidi control characters.
+	MetadataKeySecurity = "security"
+	// MetadataKeyTokens is the per-file metadata key holding the entry's estimated LLM token count.
+	MetadataKeyTokens = "tokens"
+	// MetadataKeyTokenMethod is the per-file metadata key describing how the token count was estimated.
+	MetadataKeyTokenMethod = "token_method"
+	// MetadataKeyCodeLines is the per-file metadata key counting the lines holding code.
+	MetadataKeyCodeLines = "code_lines"
+	// MetadataKeyCommentLines is the per-file metadata key counting the lines holding only comments.
+	MetadataKeyCommentLines = "comment_lines"
+	// MetadataKeyComplexity is the per-file metadata key holding the approximate cyclomatic complexity.
+	MetadataKeyComplexity = "complexity"
+	// MetadataKeyCommentsStripped is the per-file metadata key counting the comments removed by --strip-comments.
+	MetadataKeyCommentsStripped = "comments_stripped"
+	// MetadataKeyLinesTruncated is the per-file metadata key counting the lines --compact truncated.
+	MetadataKeyLinesTruncated = "lines_truncated"
+	// MetadataKeyTestOf is the per-file metadata key holding the path of the file a test file tests.
+	MetadataKeyTestOf = "test_of"
+	// MetadataKeyTestedBy is the per-file metadata key listing the test files of a file.
+	MetadataKeyTestedBy = "tested_by"
+	// MetadataKeyCoverage is the per-file metadata key holding the file's test coverage from --coverage.
+	MetadataKeyCoverage = "coverage"
+	// MetadataKeyChurn is the per-file metadata key holding the file's commit count over git.churnDays.
+	MetadataKeyChurn = "churn"
+	// MetadataKeyFindings is the per-file metadata key listing the file's static analysis findings from --findings.
+	MetadataKeyFindings = "findings"
+	// MetadataKeyDocLanguage is the per-file metadata key holding a prose file's detected natural language.
+	MetadataKeyDocLanguage = "doc_language"
+	// MetadataKeyNote is the per-file metadata key holding a user-authored note from annotations.
+	MetadataKeyNote = "note"
+	// MetadataKeyTransforms is the per-file metadata key listing the WASM transforms that rewrote the file.
+	MetadataKeyTransforms = "transforms"
+	// FileIDBytes is the number of hash bytes kept in a file ID (hex-encoded to twice as many characters).
+	FileIDBytes = 5
+)
+
+// Line-ending styles, used both as output.normalizeLineEndings values and as detected styles.
+const (
+	// LineEndingsLF is Unix-style "\n" line endings.
+	LineEndingsLF = "lf"
+	// LineEndingsCRLF is Windows-style "\r\n" line endings.
+	LineEndingsCRLF = "crlf"
+	// LineEndingsCR is classic Mac "\r" line endings (detected only).
+	LineEndingsCR = "cr"
+	// LineEndingsMixed marks files using more than one style (detected only).
+	LineEndingsMixed = "mixed"
+	// LineEndingsPreserve leaves line endings untouched.
+	LineEndingsPreserve = "preserve"
+)
+
+const (
+	// LanguageDiff is the code block language used for diff entries.
+	LanguageDiff = "diff"
+	// LanguageMarkdown is the code block language used for pull request and issue text entries.
+	LanguageMarkdown = "markdown"
+)
+
 // ============================================================================
 // YAML WRITER FORMATS
 // ============================================================================
 
 const (
-	// YAMLFmtFileEntry is the format string for YAML file entries.
-	YAMLFmtFileEntry = "  - path: %s\n    language: %s\n    content: |\n"
+	// YAMLFmtFileEntryHeader is the format string for YAML file entry path and language.
+	YAMLFmtFileEntryHeader = "  - path: %s\n    language: %s\n"
+	// YAMLFileEntryContent is the key of a YAML file entry's content, followed by its block header or quoted value.
+	YAMLFileEntryContent = "    content: "
+	// YAMLContentIndent indents the lines of a YAML file entry's literal content block.
+	YAMLContentIndent = "      "
+	// YAMLContentIndentIndicator is the block indentation indicator matching YAMLContentIndent.
+	YAMLContentIndentIndicator = "2"
+	// YAMLStreamContentHeader opens the literal block of a streamed file entry, whose first line and
+	// trailing newlines are not known in advance: explicit indentation, trailing newlines kept.
+	YAMLStreamContentHeader = "|2+\n"
+	// YAMLFileEntryMetadata starts the metadata mapping of a YAML file entry.
+	YAMLFileEntryMetadata = "    metadata:\n"
+	// YAMLFmtMetadataEntry is the format string for a single YAML metadata entry.
+	YAMLFmtMetadataEntry = "      %s: %s\n"
 )
 
 // ============================================================================
@@ -776,6 +1415,16 @@ const (
 	FormatYAML = "yaml"
 	// FormatMarkdown is the Markdown format identifier.
 	FormatMarkdown = "markdown"
+	// FormatPlain is the plain text format identifier: file contents between delimiter lines.
+	FormatPlain = "plain"
+	// FormatPlainExtension is the file extension of plain text bundles.
+	FormatPlainExtension = "txt"
+	// FormatPDF is the PDF format identifier: a printable document with a table of contents.
+	FormatPDF = "pdf"
+	// FormatZip is the zip format identifier: an archive of the selected files with a manifest.
+	FormatZip = "zip"
+	// FormatZipManifest is the name of the manifest entry of zip bundles.
+	FormatZipManifest = ".gibidify/manifest.json"
 )
 
 // ============================================================================
@@ -791,6 +1440,39 @@ const (
 	CLIArgConcurrency = "concurrency"
 	// CLIArgAll is the all benchmarks argument value.
 	CLIArgAll = "all"
+	// CLISubcommandPR is the subcommand that bundles a GitHub pull request.
+	CLISubcommandPR = "pr"
+	// CLISubcommandDaemon is the subcommand that serves bundles over a control socket.
+	CLISubcommandDaemon = "daemon"
+	// CLISubcommandDoctor is the subcommand that runs a self-diagnostic.
+	CLISubcommandDoctor = "doctor"
+	// CLISubcommandQuick is the subcommand that bundles a repository with zero-flag defaults.
+	CLISubcommandQuick = "quick"
+	// CLISubcommandClean is the subcommand that removes old generated bundles.
+	CLISubcommandClean = "clean"
+	// CLISubcommandVerify is the subcommand that verifies the signature of a bundle.
+	CLISubcommandVerify = "verify"
+	// CLISubcommandInit is the subcommand that writes a commented default config file.
+	CLISubcommandInit = "init"
+	// CLISubcommandConfig is the subcommand that describes the configuration.
+	CLISubcommandConfig = "config"
+	// CLIConfigActionSchema is the config action that writes the JSON Schema of the config file.
+	CLIConfigActionSchema = "schema"
+)
+
+// Scheduled run settings.
+const (
+	// DestinationTimestampPlaceholder in the destination is replaced with the time of the run.
+	DestinationTimestampPlaceholder = "{{timestamp}}"
+	// DestinationTimestampLayout formats the run time in UTC so snapshot names sort by time.
+	DestinationTimestampLayout = "20060102T150405Z"
+)
+
+// Quick subcommand defaults.
+const (
+	// QuickTokenWarning is the estimated token count above which the quick bundle is
+	// reported as too large for a 128k context window.
+	QuickTokenWarning = 128000
 )
 
 // ============================================================================
@@ -800,4 +1482,25 @@ const (
 const (
 	// AppName is the application name.
 	AppName = "gibidify"
+	// OutputFilePermission is the permission used when creating output files.
+	OutputFilePermission = 0o644
+	// AuditLogPermission is the permission used when creating the policy audit log.
+	AuditLogPermission = 0o600
+
+	// AnnotationsFileName is the sidecar file of per-file notes read from the source directory.
+	AnnotationsFileName = ".gibidify-notes.yaml"
+	// ProjectConfigFileName is the repository-local config file merged over the loaded configuration.
+	ProjectConfigFileName = ".gibidify.yaml"
+
+	// PolicyFileName is the name of the organization policy file.
+	PolicyFileName = "policy.yaml"
+	// PolicyEnvVar names an explicit policy file path, overriding the standard locations.
+	PolicyEnvVar = "GIBIDIFY_POLICY"
+	// ConfigEnvVar names an explicit config file path, read instead of searching the config
+	// directories when --config is not given.
+	ConfigEnvVar = "GIBIDIFY_CONFIG"
+	// PolicySystemDir is the system-wide directory searched for the policy file.
+	PolicySystemDir = "/etc/gibidify"
+	// CgroupRoot is where the cgroup filesystem of the current container is mounted.
+	CgroupRoot = "/sys/fs/cgroup"
 )
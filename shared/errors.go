@@ -140,6 +140,10 @@ const (
 	// CodeCLIMissingSource CLI Error Codes.
 	CodeCLIMissingSource = "MISSING_SOURCE"
 	CodeCLIInvalidArgs   = "INVALID_ARGS"
+	// CodeCLIOverwriteDeclined is returned when the user keeps the destination at the --preview-diff prompt.
+	CodeCLIOverwriteDeclined = "OVERWRITE_DECLINED"
+	// CodeCLIShareDeclined is returned when the user does not confirm the --share upload.
+	CodeCLIShareDeclined = "SHARE_DECLINED"
 
 	// CodeFSPathResolution FileSystem Error Codes.
 	CodeFSPathResolution = "PATH_RESOLUTION"
@@ -152,6 +156,9 @@ const (
 	CodeProcessingCollection = "COLLECTION"
 	CodeProcessingTraversal  = "TRAVERSAL"
 	CodeProcessingEncode     = "ENCODE"
+	CodeProcessingGit        = "GIT"
+	CodeProcessingGitHub     = "GITHUB"
+	CodeProcessingShare      = "SHARE"
 
 	// CodeConfigValidation Configuration Error Codes.
 	CodeConfigValidation = "VALIDATION"
@@ -166,11 +173,20 @@ const (
 	CodeIOClose      = "CLOSE"
 
 	// Validation Error Codes.
-	CodeValidationFormat   = "FORMAT"
-	CodeValidationFileType = "FILE_TYPE"
-	CodeValidationSize     = "SIZE_LIMIT"
-	CodeValidationRequired = "REQUIRED"
-	CodeValidationPath     = "PATH_TRAVERSAL"
+	CodeValidationFormat      = "FORMAT"
+	CodeValidationFileType    = "FILE_TYPE"
+	CodeValidationSize        = "SIZE_LIMIT"
+	CodeValidationRequired    = "REQUIRED"
+	CodeValidationPath        = "PATH_TRAVERSAL"
+	CodeValidationGenerated   = "GENERATED_TEXT"
+	CodeValidationBinary      = "BINARY_CONTENT"
+	CodeValidationLFS         = "LFS_POINTER"
+	CodeValidationPolicy      = "POLICY_VIOLATION"
+	CodeValidationRestrict    = "RESTRICTED_PATH"
+	CodeValidationWarnings    = "WARNINGS"
+	CodeValidationDocLanguage = "DOC_LANGUAGE"
+	CodeValidationTransform   = "TRANSFORM_FILTERED"
+	CodeValidationSignature   = "SIGNATURE"
 
 	// Resource Limit Error Codes.
 	CodeResourceLimitFiles       = "FILE_COUNT_LIMIT"
@@ -189,7 +205,7 @@ func NewMissingSourceError() *StructuredError {
 		ErrorTypeCLI,
 		CodeCLIMissingSource,
 		"usage: gibidify -source <source_directory> [--destination <output_file>] "+
-			"[--format=json|yaml|markdown (default: json)]",
+			"[--format=json|yaml|markdown|plain|pdf (default: json)]",
 		"",
 		nil,
 	)
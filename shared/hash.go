@@ -0,0 +1,65 @@
+// Package shared provides common utility functions.
+package shared
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"maps"
+	"slices"
+)
+
+// Hash algorithms, used as performance.hashAlgorithm values.
+const (
+	// HashSHA256 is SHA-256, the default.
+	HashSHA256 = "sha256"
+	// HashXXHash is the 64-bit non-cryptographic XXH64, the fastest choice. Not in the minimal build.
+	HashXXHash = "xxhash"
+	// HashBLAKE3 is BLAKE3, a cryptographic hash several times faster than SHA-256. Not in the minimal build.
+	HashBLAKE3 = "blake3"
+)
+
+// hashAlgorithms maps each hash algorithm name to its constructor. Algorithms needing a
+// third-party module register themselves from files the minimal build tag leaves out.
+var hashAlgorithms = map[string]func() hash.Hash{
+	HashSHA256: sha256.New,
+}
+
+// HashAlgorithms returns the names of the supported hash algorithms in sorted order.
+func HashAlgorithms() []string {
+	return slices.Sorted(maps.Keys(hashAlgorithms))
+}
+
+// NewHash returns a new hash.Hash computing the named algorithm.
+func NewHash(algorithm string) (hash.Hash, error) {
+	newHash, ok := hashAlgorithms[algorithm]
+	if !ok {
+		return nil, NewStructuredError(
+			ErrorTypeValidation, CodeValidationFormat,
+			"unsupported hash algorithm "+algorithm, "",
+			map[string]any{"supported": HashAlgorithms()},
+		)
+	}
+
+	return newHash(), nil
+}
+
+// Checksum returns the digest of data computed with the named algorithm, falling back to
+// SHA-256 for an unknown name so callers always get a stable digest.
+func Checksum(algorithm string, data []byte) []byte {
+	h, err := NewHash(algorithm)
+	if err != nil {
+		h = sha256.New()
+	}
+	_, _ = h.Write(data)
+
+	return h.Sum(nil)
+}
+
+// ShortChecksum returns the first n bytes of the digest of data, hex-encoded. n is capped at
+// the digest size, which is 8 bytes for xxhash.
+func ShortChecksum(algorithm string, data []byte, n int) string {
+	sum := Checksum(algorithm, data)
+
+	return hex.EncodeToString(sum[:min(n, len(sum))])
+}
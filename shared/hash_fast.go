@@ -0,0 +1,16 @@
+//go:build !minimal
+
+// Package shared provides common utility functions.
+package shared
+
+import (
+	"hash"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/zeebo/blake3"
+)
+
+func init() {
+	hashAlgorithms[HashXXHash] = func() hash.Hash { return xxhash.New() }
+	hashAlgorithms[HashBLAKE3] = func() hash.Hash { return blake3.New() }
+}
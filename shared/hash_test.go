@@ -0,0 +1,74 @@
+package shared
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"testing"
+)
+
+func TestNewHash(t *testing.T) {
+	tests := []struct {
+		algorithm string
+		size      int
+	}{
+		{HashSHA256, sha256.Size},
+		{HashXXHash, 8},
+		{HashBLAKE3, 32},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.algorithm, func(t *testing.T) {
+			if !slices.Contains(HashAlgorithms(), tt.algorithm) {
+				t.Skipf("%s is not built in", tt.algorithm)
+			}
+			h, err := NewHash(tt.algorithm)
+			if err != nil {
+				t.Fatalf("NewHash(%q) error = %v", tt.algorithm, err)
+			}
+			if h.Size() != tt.size {
+				t.Errorf("NewHash(%q).Size() = %d, want %d", tt.algorithm, h.Size(), tt.size)
+			}
+
+			sum := Checksum(tt.algorithm, []byte("main.go"))
+			if len(sum) != tt.size || string(sum) != string(Checksum(tt.algorithm, []byte("main.go"))) {
+				t.Errorf("Checksum(%q) = %x, want a stable %d-byte digest", tt.algorithm, sum, tt.size)
+			}
+			if string(sum) == string(Checksum(tt.algorithm, []byte("main.go\n"))) {
+				t.Errorf("Checksum(%q) does not depend on its input", tt.algorithm)
+			}
+		})
+	}
+
+	if _, err := NewHash("md5"); err == nil {
+		t.Error("NewHash(\"md5\") error = nil, want an unsupported algorithm error")
+	}
+}
+
+func TestShortChecksum(t *testing.T) {
+	want := sha256.Sum256([]byte("pkg/main.go"))
+	if got := ShortChecksum(HashSHA256, []byte("pkg/main.go"), 5); got != hex.EncodeToString(want[:5]) {
+		t.Errorf("ShortChecksum(sha256) = %q, want %q", got, hex.EncodeToString(want[:5]))
+	}
+	if got := ShortChecksum("unknown", []byte("pkg/main.go"), 5); got != hex.EncodeToString(want[:5]) {
+		t.Errorf("ShortChecksum(unknown) = %q, want the SHA-256 fallback %q", got, hex.EncodeToString(want[:5]))
+	}
+	if !slices.Contains(HashAlgorithms(), HashXXHash) {
+		return
+	}
+	if got := ShortChecksum(HashXXHash, []byte("pkg/main.go"), 32); len(got) != 16 {
+		t.Errorf("ShortChecksum(xxhash, 32) = %q, want the full 8-byte digest", got)
+	}
+}
+
+func BenchmarkChecksum(b *testing.B) {
+	data := make([]byte, FileProcessingStreamChunkSize)
+	for _, algorithm := range HashAlgorithms() {
+		b.Run(algorithm, func(b *testing.B) {
+			b.SetBytes(int64(len(data)))
+			for b.Loop() {
+				Checksum(algorithm, data)
+			}
+		})
+	}
+}
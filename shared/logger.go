@@ -43,26 +43,33 @@ var (
 func GetLogger() Logger {
 	once.Do(
 		func() {
-			logger := logrus.New()
-			logger.SetLevel(logrus.WarnLevel) // Default to WARNING level
-			logger.SetOutput(os.Stderr)
-			logger.SetFormatter(
-				&logrus.TextFormatter{
-					DisableColors: false,
-					FullTimestamp: false,
-				},
-			)
-
-			instance = &logService{
-				logger: logger,
-				entry:  logger.WithFields(logrus.Fields{}),
-			}
+			instance = NewLogger()
 		},
 	)
 
 	return instance
 }
 
+// NewLogger returns a logger writing to stderr at WARNING level, independent of the singleton:
+// its level and output can be set without affecting other loggers, as for each of several
+// processors run in one process.
+func NewLogger() Logger {
+	logger := logrus.New()
+	logger.SetLevel(logrus.WarnLevel) // Default to WARNING level
+	logger.SetOutput(os.Stderr)
+	logger.SetFormatter(
+		&logrus.TextFormatter{
+			DisableColors: false,
+			FullTimestamp: false,
+		},
+	)
+
+	return &logService{
+		logger: logger,
+		entry:  logger.WithFields(logrus.Fields{}),
+	}
+}
+
 // Debug logs a debug message.
 func (l *logService) Debug(args ...any) {
 	l.entry.Debug(args...)
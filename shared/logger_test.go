@@ -17,6 +17,23 @@ func TestGetLogger(t *testing.T) {
 	}
 }
 
+// TestNewLogger tests that a new logger has its own level and output, apart from the singleton.
+func TestNewLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger()
+	if logger == GetLogger() {
+		t.Fatal("NewLogger returned the singleton")
+	}
+	logger.SetOutput(&buf)
+	logger.SetLevel(LogLevelDebug)
+
+	GetLogger().Debug(TestLoggerInfoMsg)
+	logger.Debug(TestLoggerWarnMsg)
+	if strings.Contains(buf.String(), TestLoggerInfoMsg) || !strings.Contains(buf.String(), TestLoggerWarnMsg) {
+		t.Errorf("new logger output = %q, want only its own debug message", buf.String())
+	}
+}
+
 func TestLogServiceLevels(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -0,0 +1,112 @@
+// Package shared provides common utility functions.
+package shared
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Buffer pools shared across the pipeline. Processing hundreds of thousands of small files
+// otherwise allocates a fresh read chunk, escape buffer, and rendered entry for every file.
+var (
+	// ChunkBuffers holds the read chunks used by StreamContent.
+	ChunkBuffers = NewBufferPool("chunk", FileProcessingStreamChunkSize)
+	// EscapeBuffers holds the scratch space of EscapeForJSON.
+	EscapeBuffers = NewBufferPool("escape", BufferPoolScratchSize)
+	// ScratchBuffers holds the entries rendered by the format writers until they are written.
+	ScratchBuffers = NewBufferPool("scratch", BufferPoolScratchSize)
+	// PrefetchBuffers holds the content of files read ahead of the workers until it is formatted.
+	PrefetchBuffers = NewBufferPool("prefetch", BufferPoolScratchSize)
+)
+
+// BufferPool is a sync.Pool of byte slices that counts how it is used, so the debug stats
+// show whether buffers are actually reused.
+type BufferPool struct {
+	name string
+	pool sync.Pool
+
+	gets    atomic.Int64
+	allocs  atomic.Int64
+	puts    atomic.Int64
+	dropped atomic.Int64
+}
+
+// BufferPoolStats is a snapshot of the usage counters of a BufferPool.
+type BufferPoolStats struct {
+	Name string `json:"name"`
+	// Gets is the number of buffers taken from the pool, Allocs how many of them had to be allocated.
+	Gets   int64 `json:"gets"`
+	Allocs int64 `json:"allocs"`
+	// Puts is the number of buffers returned, Dropped how many of them were too large to keep.
+	Puts    int64 `json:"puts"`
+	Dropped int64 `json:"dropped"`
+}
+
+// ReuseRate returns the percentage of gets served by a pooled buffer.
+func (s BufferPoolStats) ReuseRate() float64 {
+	if s.Gets == 0 {
+		return 0
+	}
+
+	return float64(s.Gets-s.Allocs) / float64(s.Gets) * 100
+}
+
+// NewBufferPool creates a pool named name whose new buffers have a capacity of size bytes.
+func NewBufferPool(name string, size int) *BufferPool {
+	p := &BufferPool{name: name}
+	p.pool.New = func() any {
+		p.allocs.Add(1)
+		buf := make([]byte, 0, size)
+
+		return &buf
+	}
+
+	return p
+}
+
+// Get returns an empty buffer from the pool.
+func (p *BufferPool) Get() *[]byte {
+	p.gets.Add(1)
+	buf, ok := p.pool.Get().(*[]byte)
+	if !ok {
+		empty := []byte(nil)
+
+		return &empty
+	}
+	*buf = (*buf)[:0]
+
+	return buf
+}
+
+// Put returns buf to the pool. Buffers that grew beyond BufferPoolMaxSize are dropped.
+// buf must not be used after Put.
+func (p *BufferPool) Put(buf *[]byte) {
+	if buf == nil {
+		return
+	}
+	p.puts.Add(1)
+	if cap(*buf) > BufferPoolMaxSize {
+		p.dropped.Add(1)
+
+		return
+	}
+	p.pool.Put(buf)
+}
+
+// Stats returns the usage counters of the pool.
+func (p *BufferPool) Stats() BufferPoolStats {
+	return BufferPoolStats{
+		Name:    p.name,
+		Gets:    p.gets.Load(),
+		Allocs:  p.allocs.Load(),
+		Puts:    p.puts.Load(),
+		Dropped: p.dropped.Load(),
+	}
+}
+
+// PoolStats returns the usage counters of the shared buffer pools.
+func PoolStats() []BufferPoolStats {
+	return []BufferPoolStats{
+		ChunkBuffers.Stats(), EscapeBuffers.Stats(), ScratchBuffers.Stats(), PrefetchBuffers.Stats(),
+	}
+}
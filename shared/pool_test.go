@@ -0,0 +1,67 @@
+package shared
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestBufferPoolGetPut(t *testing.T) {
+	pool := NewBufferPool("test", 16)
+
+	buf := pool.Get()
+	if len(*buf) != 0 || cap(*buf) < 16 {
+		t.Fatalf("Get() = len %d cap %d, want len 0 cap >= 16", len(*buf), cap(*buf))
+	}
+	*buf = append(*buf, "dirty"...)
+	pool.Put(buf)
+
+	again := pool.Get()
+	if len(*again) != 0 {
+		t.Errorf("Get() after Put returned %q, want an empty buffer", *again)
+	}
+	pool.Put(again)
+	pool.Put(nil)
+
+	large := make([]byte, 0, BufferPoolMaxSize+1)
+	pool.Put(&large)
+
+	stats := pool.Stats()
+	if stats.Name != "test" || stats.Gets != 2 || stats.Puts != 3 || stats.Dropped != 1 {
+		t.Errorf("Stats() = %+v, want name test, 2 gets, 3 puts, 1 dropped", stats)
+	}
+	if stats.Allocs < 1 || stats.Allocs > stats.Gets {
+		t.Errorf("Stats().Allocs = %d, want between 1 and %d", stats.Allocs, stats.Gets)
+	}
+}
+
+func TestBufferPoolStatsReuseRate(t *testing.T) {
+	tests := []struct {
+		name  string
+		stats BufferPoolStats
+		want  float64
+	}{
+		{name: "unused", stats: BufferPoolStats{}, want: 0},
+		{name: "no reuse", stats: BufferPoolStats{Gets: 4, Allocs: 4}, want: 0},
+		{name: "partial reuse", stats: BufferPoolStats{Gets: 4, Allocs: 1}, want: 75},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.stats.ReuseRate(); got != tt.want {
+				t.Errorf("ReuseRate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPoolStats(t *testing.T) {
+	stats := PoolStats()
+	names := make([]string, 0, len(stats))
+	for _, s := range stats {
+		names = append(names, s.Name)
+	}
+	want := []string{"chunk", "escape", "scratch", "prefetch"}
+	if !slices.Equal(names, want) {
+		t.Errorf("PoolStats() names = %v, want %v", names, want)
+	}
+}
@@ -0,0 +1,36 @@
+// Package shared provides run identification for gibidify.
+package shared
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+// LogFieldRunID is the log field holding the ID of the run a log line belongs to.
+const LogFieldRunID = "run_id"
+
+// runIDKey is the context key of the run ID.
+type runIDKey struct{}
+
+// NewRunID returns an ID for one run: its UTC start time followed by random hex digits, for
+// example "20261015T093012-9f3a61c2", so IDs sort by start time and concurrent runs differ.
+func NewRunID() string {
+	random := make([]byte, 4)
+	_, _ = rand.Read(random) // never fails; see crypto/rand.Read
+
+	return time.Now().UTC().Format("20060102T150405") + "-" + hex.EncodeToString(random)
+}
+
+// ContextWithRunID returns a copy of ctx carrying the run ID id.
+func ContextWithRunID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, runIDKey{}, id)
+}
+
+// RunIDFromContext returns the run ID ctx carries, or "" when it carries none.
+func RunIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(runIDKey{}).(string)
+
+	return id
+}
@@ -0,0 +1,27 @@
+package shared
+
+import (
+	"context"
+	"regexp"
+	"testing"
+)
+
+func TestNewRunID(t *testing.T) {
+	pattern := regexp.MustCompile(`^\d{8}T\d{6}-[0-9a-f]{8}$`)
+	first, second := NewRunID(), NewRunID()
+	if !pattern.MatchString(first) {
+		t.Errorf("NewRunID() = %q, want a timestamp and 8 hex digits", first)
+	}
+	if first == second {
+		t.Errorf("NewRunID() returned %q twice", first)
+	}
+}
+
+func TestRunIDFromContext(t *testing.T) {
+	if id := RunIDFromContext(context.Background()); id != "" {
+		t.Errorf("RunIDFromContext(Background) = %q, want empty", id)
+	}
+	if id := RunIDFromContext(ContextWithRunID(context.Background(), "run-1")); id != "run-1" {
+		t.Errorf("RunIDFromContext() = %q, want %q", id, "run-1")
+	}
+}
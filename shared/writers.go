@@ -2,11 +2,15 @@
 package shared
 
 import (
+	"bufio"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"slices"
+	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
 // SafeCloseReader safely closes a reader if it implements io.Closer.
@@ -46,7 +50,12 @@ func StreamContent(
 	filePath string,
 	processChunk func([]byte) []byte,
 ) error {
-	buf := make([]byte, chunkSize)
+	pooled := ChunkBuffers.Get()
+	defer ChunkBuffers.Put(pooled)
+	if cap(*pooled) < chunkSize {
+		*pooled = make([]byte, chunkSize)
+	}
+	buf := (*pooled)[:chunkSize]
 	for {
 		n, err := reader.Read(buf)
 		if n > 0 {
@@ -99,47 +108,260 @@ func wrapReadError(err error, filePath string) error {
 	return wrappedErr
 }
 
-// EscapeForJSON escapes content for JSON output using the standard library.
-// This replaces the custom escapeJSONString function with a more robust implementation.
+// jsonSafe marks the ASCII bytes that appear as is in a JSON string. Like encoding/json,
+// the HTML-sensitive <, > and & are escaped so bundles can be embedded in HTML.
+var jsonSafe = func() [utf8.RuneSelf]bool {
+	var safe [utf8.RuneSelf]bool
+	for c := ' '; c < utf8.RuneSelf; c++ {
+		safe[c] = !strings.ContainsRune(`"\<>&`, c)
+	}
+
+	return safe
+}()
+
+// jsonEscapes holds the escaped form of every ASCII byte that jsonSafe does not mark.
+var jsonEscapes = func() [utf8.RuneSelf]string {
+	const hex = "0123456789abcdef"
+
+	var escapes [utf8.RuneSelf]string
+	for c := range byte(' ') {
+		escapes[c] = `\u00` + string(hex[c>>4]) + string(hex[c&0xF])
+	}
+	for c, escape := range map[byte]string{
+		'"': `\"`, '\\': `\\`, '\b': `\b`, '\f': `\f`, '\n': `\n`, '\r': `\r`, '\t': `\t`,
+		'<': `\u003c`, '>': `\u003e`, '&': `\u0026`,
+	} {
+		escapes[c] = escape
+	}
+
+	return escapes
+}()
+
+// EscapeForJSON escapes content for use inside a JSON string, producing the same output as
+// encoding/json. Content that needs no escaping is returned as is without allocating; otherwise
+// the runs between escaped characters are copied in one piece into a buffer from EscapeBuffers.
 func EscapeForJSON(content string) string {
-	// Use the standard library's JSON marshaling for proper escaping
-	jsonBytes, err := json.Marshal(content)
-	if err != nil {
-		// If marshaling fails (which is very unlikely for a string), return the original
+	if jsonEscapeIndex(content) == len(content) {
 		return content
 	}
-	// Remove the surrounding quotes that json.Marshal adds
-	jsonStr := string(jsonBytes)
-	if len(jsonStr) >= 2 && jsonStr[0] == '"' && jsonStr[len(jsonStr)-1] == '"' {
-		return jsonStr[1 : len(jsonStr)-1]
+
+	pooled := EscapeBuffers.Get()
+	defer EscapeBuffers.Put(pooled)
+	*pooled = appendJSONEscaped(slices.Grow(*pooled, len(content)+len(content)/4), content)
+
+	return string(*pooled)
+}
+
+// appendJSONEscaped appends s escaped for a JSON string to dst.
+func appendJSONEscaped[T string | []byte](dst []byte, s T) []byte {
+	start := 0
+	for i := jsonEscapeIndex(s); i < len(s); i = start + jsonEscapeIndex(s[start:]) {
+		dst = append(dst, s[start:i]...)
+		var n int
+		dst, n = appendJSONEscape(dst, s[i:])
+		start = i + n
+	}
+
+	return append(dst, s[start:]...)
+}
+
+// jsonEscapeIndex returns the index of the first character of s that must be escaped,
+// or len(s) when there is none.
+func jsonEscapeIndex[T string | []byte](s T) int {
+	for i := 0; i < len(s); {
+		if c := s[i]; c < utf8.RuneSelf {
+			if !jsonSafe[c] {
+				return i
+			}
+			i++
+
+			continue
+		}
+		r, size := decodeRune(s[i:])
+		if (r == utf8.RuneError && size == 1) || r == '\u2028' || r == '\u2029' {
+			return i
+		}
+		i += size
 	}
 
-	return jsonStr
+	return len(s)
+}
+
+// appendJSONEscape appends the escaped form of the character s starts with and returns its length in s.
+// Invalid UTF-8 becomes U+FFFD, and U+2028 and U+2029, which end lines in JavaScript, are escaped.
+func appendJSONEscape[T string | []byte](dst []byte, s T) ([]byte, int) {
+	if s[0] < utf8.RuneSelf {
+		return append(dst, jsonEscapes[s[0]]...), 1
+	}
+
+	r, size := decodeRune(s)
+	if r == utf8.RuneError {
+		return utf8.AppendRune(dst, utf8.RuneError), size
+	}
+
+	return append(dst, `\u202`+string("0123456789abcdef"[r&0xF])...), size
+}
+
+// decodeRune decodes the first UTF-8 character of s like utf8.DecodeRuneInString.
+// Converting at most utf8.UTFMax bytes keeps the conversion of a byte slice off the heap.
+func decodeRune[T string | []byte](s T) (rune, int) {
+	return utf8.DecodeRuneInString(string(s[:min(len(s), utf8.UTFMax)]))
+}
+
+// JSONStreamEscaper escapes content for a JSON string chunk by chunk, as the processChunk of
+// StreamContent, so a large file never needs an escaped copy of its whole content. A UTF-8
+// character split between two chunks is held back until it is complete, so the output is the
+// same as EscapeForJSON of the whole content.
+type JSONStreamEscaper struct {
+	buf     *[]byte
+	pending []byte
+}
+
+// NewJSONStreamEscaper creates an escaper with a buffer from EscapeBuffers; call Release when done.
+func NewJSONStreamEscaper() *JSONStreamEscaper {
+	return &JSONStreamEscaper{buf: EscapeBuffers.Get(), pending: make([]byte, 0, utf8.UTFMax)}
+}
+
+// Escape returns chunk escaped for a JSON string, less any incomplete character at its end.
+// The result is only valid until the next call.
+func (e *JSONStreamEscaper) Escape(chunk []byte) []byte {
+	dst := (*e.buf)[:0]
+	if len(e.pending) > 0 {
+		// Complete the character left over from the previous chunk with its continuation bytes
+		for len(chunk) > 0 && !utf8.FullRune(e.pending) && !utf8.RuneStart(chunk[0]) {
+			e.pending = append(e.pending, chunk[0])
+			chunk = chunk[1:]
+		}
+		if len(chunk) == 0 && !utf8.FullRune(e.pending) {
+			return dst
+		}
+		dst = appendJSONEscaped(dst, e.pending)
+		e.pending = e.pending[:0]
+	}
+
+	complete := len(chunk)
+	for i := len(chunk) - 1; i >= 0 && i > len(chunk)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(chunk[i]) {
+			if !utf8.FullRune(chunk[i:]) {
+				complete = i
+			}
+
+			break
+		}
+	}
+	dst = appendJSONEscaped(dst, chunk[:complete])
+	e.pending = append(e.pending, chunk[complete:]...)
+	*e.buf = dst
+
+	return dst
+}
+
+// Flush returns the escaped form of an incomplete character left at the end of the content.
+func (e *JSONStreamEscaper) Flush() []byte {
+	dst := appendJSONEscaped((*e.buf)[:0], e.pending)
+	e.pending = e.pending[:0]
+	*e.buf = dst
+
+	return dst
+}
+
+// Release returns the escaper's buffer to EscapeBuffers. The escaper must not be used afterwards.
+func (e *JSONStreamEscaper) Release() {
+	EscapeBuffers.Put(e.buf)
+	e.buf = nil
 }
 
 // EscapeForYAML quotes/escapes content for YAML output if needed.
-// This centralizes the YAML string quoting logic.
+// This centralizes the YAML string quoting logic. Quoted content decodes back to the original,
+// except that invalid UTF-8 bytes, which YAML cannot carry, become U+FFFD.
 func EscapeForYAML(content string) string {
 	// Quote if contains special characters, spaces, or starts with special chars
 	needsQuotes := strings.ContainsAny(content, " \t\n\r:{}[]|>-'\"\\") ||
 		strings.HasPrefix(content, "-") ||
 		strings.HasPrefix(content, "?") ||
 		strings.HasPrefix(content, ":") ||
+		strings.IndexAny(content, "@`!&*#%,") == 0 ||
 		content == "" ||
 		content == LiteralTrue || content == LiteralFalse ||
-		content == LiteralNull || content == "~"
+		content == LiteralNull || content == "~" ||
+		looksNumeric(content) ||
+		!YAMLLiteralSafe(content)
 
 	if needsQuotes {
-		// Use double quotes and escape internal quotes
-		escaped := strings.ReplaceAll(content, "\\", "\\\\")
-		escaped = strings.ReplaceAll(escaped, "\"", "\\\"")
-
-		return "\"" + escaped + "\""
+		return QuoteForYAML(content)
 	}
 
 	return content
 }
 
+// QuoteForYAML returns content as a double-quoted YAML scalar. Line breaks, control characters
+// and the other characters YAML does not allow unescaped are written as escape sequences, so
+// the scalar decodes back to content; invalid UTF-8 bytes become U+FFFD.
+func QuoteForYAML(content string) string {
+	var quoted strings.Builder
+	quoted.Grow(len(content) + 2)
+	quoted.WriteByte('"')
+	for _, r := range content {
+		switch {
+		case r == '"' || r == '\\':
+			quoted.WriteByte('\\')
+			quoted.WriteRune(r)
+		case r == '\n':
+			quoted.WriteString(`\n`)
+		case r == '\r':
+			quoted.WriteString(`\r`)
+		case r == '\t' || yamlPrintable(r):
+			quoted.WriteRune(r)
+		case r <= 0xFF:
+			fmt.Fprintf(&quoted, `\x%02X`, r)
+		default:
+			fmt.Fprintf(&quoted, `\u%04X`, r)
+		}
+	}
+	quoted.WriteByte('"')
+
+	return quoted.String()
+}
+
+// YAMLLiteralSafe reports whether content can be written as a YAML literal block scalar and
+// read back unchanged: it must be valid UTF-8 and contain no carriage returns, control
+// characters, byte order marks or line breaks other than "\n".
+func YAMLLiteralSafe(content string) bool {
+	for i, r := range content {
+		if r == utf8.RuneError && !strings.HasPrefix(content[i:], string(utf8.RuneError)) {
+			return false
+		}
+		if r != '\t' && r != '\n' && !yamlPrintable(r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// yamlPrintable reports whether r may appear unescaped in YAML content other than as a line
+// break or tab. NEL, LS and PS are excluded as YAML 1.1 parsers treat them as line breaks.
+func yamlPrintable(r rune) bool {
+	switch {
+	case r >= 0x20 && r <= 0x7E:
+		return true
+	case r == 0x85, r == 0x2028, r == 0x2029, r == 0xFEFF:
+		return false
+	case r >= 0xA0 && r <= 0xD7FF, r >= 0xE000 && r <= 0xFFFD:
+		return true
+	default:
+		return r >= 0x10000 && r <= utf8.MaxRune
+	}
+}
+
+// looksNumeric reports whether a plain YAML scalar would be read back as a number,
+// as hex-encoded file IDs made only of digits would be.
+func looksNumeric(content string) bool {
+	_, err := strconv.ParseFloat(content, 64)
+
+	return err == nil
+}
+
 // CheckContextCancellation is a helper function that checks if context is canceled and returns appropriate error.
 func CheckContextCancellation(ctx context.Context, operation string) error {
 	select {
@@ -160,34 +382,36 @@ func WithContextCheck(ctx context.Context, operation string, fn func() error) er
 }
 
 // StreamLines provides line-based streaming for YAML content.
-// This provides an alternative streaming approach for YAML writers.
+// Lines are read one at a time, so memory use is bounded by the longest line rather than the file.
 func StreamLines(reader io.Reader, writer io.Writer, filePath string, lineProcessor func(string) string) error {
-	// Read all content first (for small files this is fine)
-	content, err := io.ReadAll(reader)
-	if err != nil {
-		wrappedErr := WrapError(err, ErrorTypeIO, CodeIORead, "failed to read content for line processing")
-		if filePath != "" {
-			wrappedErr = wrappedErr.WithFilePath(filePath)
-		}
+	buffered := bufio.NewReaderSize(reader, FileProcessingStreamChunkSize)
+	scratch := ScratchBuffers.Get()
+	defer ScratchBuffers.Put(scratch)
 
-		return wrappedErr
-	}
+	for {
+		line, err := buffered.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			wrappedErr := WrapError(err, ErrorTypeIO, CodeIORead, "failed to read content for line processing")
+			if filePath != "" {
+				wrappedErr = wrappedErr.WithFilePath(filePath)
+			}
+
+			return wrappedErr
+		}
 
-	// Split into lines and process each
-	lines := strings.Split(string(content), "\n")
-	for i, line := range lines {
-		processedLine := line
+		text := strings.TrimSuffix(line, "\n")
 		if lineProcessor != nil {
-			processedLine = lineProcessor(line)
+			text = lineProcessor(text)
 		}
 
-		// Write line with proper line ending (except for last empty line)
-		lineToWrite := processedLine
-		if i < len(lines)-1 || line != "" {
-			lineToWrite += "\n"
+		// Every line ends with a newline; an empty remainder after the last one is not a line
+		if err != nil && line == "" {
+			return nil
 		}
+		*scratch = append((*scratch)[:0], text...)
+		*scratch = append(*scratch, '\n')
 
-		if _, writeErr := writer.Write([]byte(lineToWrite)); writeErr != nil {
+		if _, writeErr := writer.Write(*scratch); writeErr != nil {
 			wrappedErr := WrapError(writeErr, ErrorTypeIO, CodeIOWrite, "failed to write processed line")
 			if filePath != "" {
 				wrappedErr = wrappedErr.WithFilePath(filePath)
@@ -195,7 +419,8 @@ func StreamLines(reader io.Reader, writer io.Writer, filePath string, lineProces
 
 			return wrappedErr
 		}
+		if err != nil {
+			return nil
+		}
 	}
-
-	return nil
 }
@@ -3,9 +3,12 @@ package shared
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"math/rand/v2"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -378,6 +381,26 @@ func TestEscapeForJSON(t *testing.T) {
 			input:    "Line 1\n\t\"Quoted\"\r\nLine 2\\",
 			expected: "Line 1\\n\\t\\\"Quoted\\\"\\r\\nLine 2\\\\",
 		},
+		{
+			name:     "html characters",
+			input:    "<a href=\"x\">&</a>",
+			expected: `\u003ca href=\"x\"\u003e\u0026\u003c/a\u003e`,
+		},
+		{
+			name:     "backspace and form feed",
+			input:    "\b\f",
+			expected: `\b\f`,
+		},
+		{
+			name:     "invalid utf-8",
+			input:    "ok\xffok\xe4\xb8",
+			expected: "ok\uFFFDok\uFFFD\uFFFD",
+		},
+		{
+			name:     "line and paragraph separators",
+			input:    "a\u2028b\u2029c",
+			expected: `a\u2028b\u2029c`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -392,6 +415,79 @@ func TestEscapeForJSON(t *testing.T) {
 	}
 }
 
+// escapeForJSONMarshal is the encoding/json based escaper EscapeForJSON replaced,
+// kept as the reference output and the baseline of BenchmarkEscapeForJSON.
+func escapeForJSONMarshal(content string) string {
+	jsonBytes, err := json.Marshal(content)
+	if err != nil {
+		return content
+	}
+
+	return string(jsonBytes[1 : len(jsonBytes)-1])
+}
+
+func TestEscapeForJSONMatchesEncodingJSON(t *testing.T) {
+	inputs := make([]string, 0, 512)
+	for b := range 256 {
+		inputs = append(inputs, string([]byte{'x', byte(b), 'y'}))
+	}
+
+	alphabet := []string{
+		"a", " ", "\"", "\\", "<", "&", "\n", "\x00", "\x7f", "é", "世", "🌍", "\u2028", "\xff", "\xe4\xb8",
+	}
+	rng := rand.New(rand.NewPCG(1, 2))
+	for range 256 {
+		var b strings.Builder
+		for range rng.IntN(32) {
+			b.WriteString(alphabet[rng.IntN(len(alphabet))])
+		}
+		inputs = append(inputs, b.String())
+	}
+
+	for _, input := range inputs {
+		if got, want := EscapeForJSON(input), escapeForJSONMarshal(input); got != want {
+			t.Errorf("EscapeForJSON(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestJSONStreamEscaper(t *testing.T) {
+	inputs := []string{
+		"",
+		"plain ascii",
+		"é世🌍\u2028<&>\"\\\n",
+		"truncated \xe4\xb8 then more \xf0\x9f\x8c and \xff",
+		strings.Repeat("aé世🌍\xe4", 7),
+	}
+
+	for _, input := range inputs {
+		want := EscapeForJSON(input)
+		for chunkSize := 1; chunkSize <= 5; chunkSize++ {
+			escaper := NewJSONStreamEscaper()
+			var got []byte
+			for chunk := range slices.Chunk([]byte(input), chunkSize) {
+				got = append(got, escaper.Escape(chunk)...)
+			}
+			got = append(got, escaper.Flush()...)
+			escaper.Release()
+
+			if string(got) != want {
+				t.Errorf("escaping %q in chunks of %d = %q, want %q", input, chunkSize, got, want)
+			}
+		}
+	}
+}
+
+func TestEscapeForJSONNoEscapeAllocations(t *testing.T) {
+	content := strings.Repeat("func main() { fmt.Println(42) } // 世界\u00e9\u00e8\u00e0\u00fc", 100)
+	allocs := testing.AllocsPerRun(100, func() {
+		_ = EscapeForJSON(content)
+	})
+	if allocs != 0 {
+		t.Errorf("EscapeForJSON allocated %.0f times for content needing no escaping, want 0", allocs)
+	}
+}
+
 func TestEscapeForYAML(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -456,7 +552,42 @@ func TestEscapeForYAML(t *testing.T) {
 		{
 			name:     "string with newlines",
 			input:    "line1\nline2",
-			expected: "\"line1\nline2\"",
+			expected: `"line1\nline2"`,
+		},
+		{
+			name:     "string with carriage return",
+			input:    "line1\r\nline2",
+			expected: `"line1\r\nline2"`,
+		},
+		{
+			name:     "string with control character",
+			input:    "bell\a",
+			expected: `"bell\x07"`,
+		},
+		{
+			name:     "string with next line and byte order mark",
+			input:    "a\u0085b\ufeff",
+			expected: `"a\x85b\uFEFF"`,
+		},
+		{
+			name:     "invalid UTF-8",
+			input:    "bad\xff",
+			expected: "\"bad\ufffd\"",
+		},
+		{
+			name:     "path with emoji and spaces",
+			input:    "docs/🚀 launch plan.md",
+			expected: `"docs/🚀 launch plan.md"`,
+		},
+		{
+			name:     "path with emoji",
+			input:    "emoji🎉/party.json",
+			expected: "emoji🎉/party.json",
+		},
+		{
+			name:     "path in a right-to-left script",
+			input:    "src/مرحبا/main.go",
+			expected: "src/مرحبا/main.go",
 		},
 		{
 			name:     "string with tabs",
@@ -493,6 +624,21 @@ func TestEscapeForYAML(t *testing.T) {
 			input:    "normalValue123",
 			expected: "normalValue123",
 		},
+		{
+			name:     "string starting with at sign",
+			input:    "@org/team",
+			expected: `"@org/team"`,
+		},
+		{
+			name:     "numeric string",
+			input:    "0123456789",
+			expected: `"0123456789"`,
+		},
+		{
+			name:     "exponent-like string",
+			input:    "12e45",
+			expected: `"12e45"`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -724,12 +870,36 @@ func TestWrapReadError(t *testing.T) {
 }
 
 // Benchmark tests for performance-critical functions.
+// BenchmarkEscapeForJSON compares EscapeForJSON ("table") with the encoding/json escaper it
+// replaced ("marshal") on source-like, escape-free and escape-heavy content.
 func BenchmarkEscapeForJSON(b *testing.B) {
-	testString := `This is a "test string" with various characters: \n\t\r and some unicode: 世界`
+	inputs := []struct {
+		name    string
+		content string
+	}{
+		{"short", `This is a "test string" with various characters: \n\t\r and some unicode: 世界`},
+		{"source", strings.Repeat("\tif err != nil {\n\t\treturn fmt.Errorf(\"read %s: %w\", path, err)\n\t}\n", 500)},
+		{"plain", strings.Repeat("The quick brown fox jumps over the lazy dog 世界. ", 1000)},
+		{"escape-heavy", strings.Repeat("<a href=\"\\x\">&amp;</a>\n", 1000)},
+	}
+	escapers := []struct {
+		name   string
+		escape func(string) string
+	}{
+		{"marshal", escapeForJSONMarshal},
+		{"table", EscapeForJSON},
+	}
 
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		_ = EscapeForJSON(testString)
+	for _, input := range inputs {
+		for _, escaper := range escapers {
+			b.Run(input.name+"/"+escaper.name, func(b *testing.B) {
+				b.SetBytes(int64(len(input.content)))
+				b.ReportAllocs()
+				for b.Loop() {
+					_ = escaper.escape(input.content)
+				}
+			})
+		}
 	}
 }
 
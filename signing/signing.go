@@ -0,0 +1,330 @@
+// Package signing writes and verifies detached Ed25519 signatures of bundles, so a bundle
+// carried across a trust boundary can be checked against the key of the party that made it.
+package signing
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/ivuorinen/gibidify/shared"
+)
+
+// Version is the version of the signature file format.
+const Version = 1
+
+// Extension is appended to the name of a bundle to name its signature file by default.
+const Extension = ".sig"
+
+// Fields of a signature file, one "name: value" line each.
+const (
+	fieldVersion   = "gibidify-signature"
+	fieldKey       = "key"
+	fieldFile      = "file"
+	fieldSignature = "signature"
+)
+
+// Statuses of the files a signature covers, as reported by Verify.
+const (
+	StatusOK      = "ok"
+	StatusMissing = "missing"
+)
+
+// keyIDBytes is the number of bytes of the SHA-256 hash of a public key kept in its key ID.
+const keyIDBytes = 8
+
+// SignedFile is a file covered by a signature: its path relative to the signature file and
+// the SHA-256 hash of its content.
+type SignedFile struct {
+	Path   string
+	Digest [sha256.Size]byte
+}
+
+// Check is the result of verifying one signed file.
+type Check struct {
+	Path   string
+	Status string
+}
+
+// Signature is a parsed signature file: the ID of the signing key, the signed files, the
+// bundle first, and the Ed25519 signature of the lines listing them.
+type Signature struct {
+	KeyID     string
+	Files     []SignedFile
+	payload   []byte
+	signature []byte
+}
+
+// LoadPrivateKey reads an Ed25519 private key from a PKCS #8 PEM file, as written by
+// `openssl genpkey -algorithm ed25519`.
+func LoadPrivateKey(path string) (ed25519.PrivateKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, keyError(path, err)
+	}
+	key, ok := parsed.(ed25519.PrivateKey)
+	if !ok {
+		return nil, keyError(path, fmt.Errorf("%T is not an Ed25519 key", parsed))
+	}
+
+	return key, nil
+}
+
+// LoadPublicKey reads an Ed25519 public key from a PKIX PEM file, as written by
+// `openssl pkey -pubout`. The public key of a private key file is accepted too.
+func LoadPublicKey(path string) (ed25519.PublicKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	if block.Type == "PRIVATE KEY" {
+		key, err := LoadPrivateKey(path)
+		if err != nil {
+			return nil, err
+		}
+
+		return key.Public().(ed25519.PublicKey), nil
+	}
+
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, keyError(path, err)
+	}
+	key, ok := parsed.(ed25519.PublicKey)
+	if !ok {
+		return nil, keyError(path, fmt.Errorf("%T is not an Ed25519 key", parsed))
+	}
+
+	return key, nil
+}
+
+// readPEM reads the first PEM block of the file at path.
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- path is a key file named by the user
+	if err != nil {
+		return nil, shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIORead, "failed to read key").
+			WithFilePath(path)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, keyError(path, errors.New("no PEM data found"))
+	}
+
+	return block, nil
+}
+
+// keyError returns the error of a key file that cannot be used.
+func keyError(path string, err error) error {
+	return shared.WrapError(err, shared.ErrorTypeValidation, shared.CodeValidationSignature,
+		"not an Ed25519 key").WithFilePath(path)
+}
+
+// KeyID returns the ID of a public key: the hex-encoded start of its SHA-256 hash.
+func KeyID(key ed25519.PublicKey) string {
+	sum := sha256.Sum256(key)
+
+	return hex.EncodeToString(sum[:keyIDBytes])
+}
+
+// Sign writes the signature of files, the bundle first, to sigPath. Files are listed by their
+// path relative to the directory of sigPath, so the signature stays valid when the bundle
+// and the signature are moved together.
+func Sign(key ed25519.PrivateKey, sigPath string, files []string) error {
+	var payload bytes.Buffer
+	fmt.Fprintf(&payload, "%s: %d\n", fieldVersion, Version)
+	fmt.Fprintf(&payload, "%s: %s\n", fieldKey, KeyID(key.Public().(ed25519.PublicKey)))
+	for _, file := range files {
+		digest, err := hashFile(file)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&payload, "%s: %s %s\n", fieldFile, hex.EncodeToString(digest[:]), relativePath(sigPath, file))
+	}
+
+	signature := ed25519.Sign(key, payload.Bytes())
+	fmt.Fprintf(&payload, "%s: %s\n", fieldSignature, base64.StdEncoding.EncodeToString(signature))
+	if err := os.WriteFile(sigPath, payload.Bytes(), shared.OutputFilePermission); err != nil {
+		return shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIOFileWrite, "failed to write signature").
+			WithFilePath(sigPath)
+	}
+
+	return nil
+}
+
+// relativePath returns the slash-separated path of file relative to the directory of sigPath,
+// or its absolute path when it has none.
+func relativePath(sigPath, file string) string {
+	absSig, errSig := filepath.Abs(sigPath)
+	absFile, errFile := filepath.Abs(file)
+	if errSig != nil || errFile != nil {
+		return filepath.ToSlash(file)
+	}
+	rel, err := filepath.Rel(filepath.Dir(absSig), absFile)
+	if err != nil {
+		return filepath.ToSlash(absFile)
+	}
+
+	return filepath.ToSlash(rel)
+}
+
+// hashFile returns the SHA-256 hash of the content of the file at path.
+func hashFile(path string) ([sha256.Size]byte, error) {
+	var digest [sha256.Size]byte
+	file, err := os.Open(path) // #nosec G304 -- path is a bundle or one of its outputs
+	if err != nil {
+		return digest, shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIORead, "failed to read signed file").
+			WithFilePath(path)
+	}
+	defer shared.SafeCloseReader(file, path)
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return digest, shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIORead, "failed to read signed file").
+			WithFilePath(path)
+	}
+	copy(digest[:], hash.Sum(nil))
+
+	return digest, nil
+}
+
+// Parse parses a signature file. It does not check the signature; Verify does.
+func Parse(data []byte) (*Signature, error) {
+	sig := &Signature{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	offset := 0
+	for scanner.Scan() {
+		line := scanner.Text()
+		name, value, ok := strings.Cut(line, ": ")
+		if !ok {
+			return nil, signatureError("malformed line %q", line)
+		}
+		if name == fieldSignature {
+			signature, err := base64.StdEncoding.DecodeString(value)
+			if err != nil || len(signature) != ed25519.SignatureSize {
+				return nil, signatureError("malformed signature")
+			}
+			sig.payload, sig.signature = data[:offset], signature
+			if err := sig.check(); err != nil {
+				return nil, err
+			}
+
+			return sig, nil
+		}
+		if err := sig.parseField(name, value); err != nil {
+			return nil, err
+		}
+		offset += len(line) + 1
+	}
+
+	return nil, signatureError("no signature")
+}
+
+// parseField records the value of a signed field.
+func (s *Signature) parseField(name, value string) error {
+	switch name {
+	case fieldVersion:
+		if version, err := strconv.Atoi(value); err != nil || version != Version {
+			return signatureError("unsupported signature version %s", value)
+		}
+	case fieldKey:
+		s.KeyID = value
+	case fieldFile:
+		digest, path, ok := strings.Cut(value, " ")
+		decoded, err := hex.DecodeString(digest)
+		if !ok || err != nil || len(decoded) != sha256.Size || path == "" {
+			return signatureError("malformed file %q", value)
+		}
+		file := SignedFile{Path: path}
+		copy(file.Digest[:], decoded)
+		s.Files = append(s.Files, file)
+	default:
+		return signatureError("unknown field %q", name)
+	}
+
+	return nil
+}
+
+// check reports a signature missing its key ID or files.
+func (s *Signature) check() error {
+	if s.KeyID == "" || len(s.Files) == 0 {
+		return signatureError("incomplete signature")
+	}
+
+	return nil
+}
+
+// Verify checks the signature at sigPath with key and bundle against its first signed file.
+// The other signed files are looked up relative to the directory of sigPath; those not found
+// are reported missing rather than failing verification, so a bundle can travel without them.
+func Verify(key ed25519.PublicKey, sigPath, bundle string) ([]Check, error) {
+	data, err := os.ReadFile(sigPath) // #nosec G304 -- sigPath is a signature named by the user
+	if err != nil {
+		return nil, shared.WrapError(err, shared.ErrorTypeIO, shared.CodeIORead, "failed to read signature").
+			WithFilePath(sigPath)
+	}
+	sig, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	if keyID := KeyID(key); sig.KeyID != keyID {
+		return nil, signatureError("signed with key %s, not %s", sig.KeyID, keyID)
+	}
+	if !ed25519.Verify(key, sig.payload, sig.signature) {
+		return nil, signatureError("signature does not match")
+	}
+
+	checks := make([]Check, 0, len(sig.Files))
+	for i, file := range sig.Files {
+		path := bundle
+		if i > 0 {
+			path = filepath.Join(filepath.Dir(sigPath), filepath.FromSlash(file.Path))
+		}
+		status, err := verifyFile(path, file, i == 0)
+		if err != nil {
+			return nil, err
+		}
+		checks = append(checks, Check{Path: path, Status: status})
+	}
+
+	return checks, nil
+}
+
+// verifyFile checks the file at path against its signed hash. Missing files are an error only
+// when required.
+func verifyFile(path string, file SignedFile, required bool) (string, error) {
+	if _, err := os.Stat(path); !required && errors.Is(err, os.ErrNotExist) {
+		return StatusMissing, nil
+	}
+	digest, err := hashFile(path)
+	if err != nil {
+		return "", err
+	}
+	if digest != file.Digest {
+		return "", signatureError("%s does not match the signed %s", path, file.Path)
+	}
+
+	return StatusOK, nil
+}
+
+// signatureError returns a signature validation error.
+func signatureError(format string, args ...any) error {
+	return shared.NewStructuredError(
+		shared.ErrorTypeValidation, shared.CodeValidationSignature, fmt.Sprintf(format, args...), "", nil,
+	)
+}
@@ -0,0 +1,169 @@
+package signing_test
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ivuorinen/gibidify/shared"
+	"github.com/ivuorinen/gibidify/signing"
+	"github.com/ivuorinen/gibidify/testutil"
+)
+
+// writeKeys generates an Ed25519 key pair, writes it to dir as PEM files and returns their paths.
+func writeKeys(t *testing.T, dir, name string) (string, string) {
+	t.Helper()
+
+	public, private, err := ed25519.GenerateKey(nil)
+	testutil.MustSucceed(t, err, "generating key")
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	testutil.MustSucceed(t, err, "encoding private key")
+	publicDER, err := x509.MarshalPKIXPublicKey(public)
+	testutil.MustSucceed(t, err, "encoding public key")
+
+	privatePath := testutil.CreateTestFile(t, dir, name+".pem",
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}))
+	publicPath := testutil.CreateTestFile(t, dir, name+".pub",
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}))
+
+	return privatePath, publicPath
+}
+
+// TestSignVerify tests that a signed bundle and index verify, that a missing index is reported
+// rather than failing, and that tampering, a missing bundle and another key fail verification.
+func TestSignVerify(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(t *testing.T, dir string)
+		key    string
+		want   []string
+		errMsg string
+	}{
+		{name: "valid", want: []string{"ok", "ok"}},
+		{
+			name: "missing index",
+			modify: func(t *testing.T, dir string) {
+				testutil.MustSucceed(t, os.Remove(filepath.Join(dir, "index.json")), "")
+			},
+			want: []string{"ok", "missing"},
+		},
+		{
+			name: "tampered bundle",
+			modify: func(t *testing.T, dir string) {
+				testutil.CreateTestFile(t, dir, "bundle.md", []byte("# Changed\n"))
+			},
+			errMsg: "does not match the signed bundle.md",
+		},
+		{
+			name: "tampered signature",
+			modify: func(t *testing.T, dir string) {
+				path := filepath.Join(dir, "bundle.md.sig")
+				data, err := os.ReadFile(path)
+				testutil.MustSucceed(t, err, "reading signature")
+				data = []byte(strings.Replace(string(data), "bundle.md", "other.md", 1))
+				testutil.MustSucceed(t, os.WriteFile(path, data, 0o600), "writing signature")
+			},
+			errMsg: "signature does not match",
+		},
+		{
+			name: "missing bundle",
+			modify: func(t *testing.T, dir string) {
+				testutil.MustSucceed(t, os.Remove(filepath.Join(dir, "bundle.md")), "")
+			},
+			errMsg: "failed to read signed file",
+		},
+		{name: "other key", key: "other", errMsg: "signed with key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			private, public := writeKeys(t, dir, "key")
+			bundle := testutil.CreateTestFile(t, dir, "bundle.md", []byte("# Bundle\n"))
+			index := testutil.CreateTestFile(t, dir, "index.json", []byte(`{"files":[]}`))
+			sigPath := bundle + signing.Extension
+
+			key, err := signing.LoadPrivateKey(private)
+			testutil.MustSucceed(t, err, "LoadPrivateKey")
+			testutil.MustSucceed(t, signing.Sign(key, sigPath, []string{bundle, index}), "Sign")
+			if tt.modify != nil {
+				tt.modify(t, dir)
+			}
+			if tt.key != "" {
+				_, public = writeKeys(t, dir, tt.key)
+			}
+
+			publicKey, err := signing.LoadPublicKey(public)
+			testutil.MustSucceed(t, err, "LoadPublicKey")
+			checks, err := signing.Verify(publicKey, sigPath, bundle)
+			if tt.errMsg != "" {
+				var structErr *shared.StructuredError
+				if err == nil || !strings.Contains(err.Error(), tt.errMsg) || !errors.As(err, &structErr) {
+					t.Fatalf("Verify() error = %v, want %q", err, tt.errMsg)
+				}
+
+				return
+			}
+			testutil.MustSucceed(t, err, "Verify")
+			var statuses []string
+			for _, check := range checks {
+				statuses = append(statuses, check.Status)
+			}
+			if strings.Join(statuses, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("statuses = %v, want %v", statuses, tt.want)
+			}
+		})
+	}
+}
+
+// TestLoadPublicKeyFromPrivateKey tests that the public key of a private key file is accepted.
+func TestLoadPublicKeyFromPrivateKey(t *testing.T) {
+	dir := t.TempDir()
+	private, public := writeKeys(t, dir, "key")
+
+	fromPrivate, err := signing.LoadPublicKey(private)
+	testutil.MustSucceed(t, err, "LoadPublicKey(private)")
+	fromPublic, err := signing.LoadPublicKey(public)
+	testutil.MustSucceed(t, err, "LoadPublicKey(public)")
+	if signing.KeyID(fromPrivate) != signing.KeyID(fromPublic) {
+		t.Errorf("key IDs differ: %s, %s", signing.KeyID(fromPrivate), signing.KeyID(fromPublic))
+	}
+
+	notKey := testutil.CreateTestFile(t, dir, "notkey.pem", []byte("not a key"))
+	if _, err := signing.LoadPrivateKey(notKey); err == nil || !strings.Contains(err.Error(), "not an Ed25519 key") {
+		t.Errorf("LoadPrivateKey(notkey.pem) error = %v, want not an Ed25519 key", err)
+	}
+}
+
+// TestParseErrors tests that malformed signature files are rejected.
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{name: "empty", data: "", want: "no signature"},
+		{name: "malformed line", data: "garbage\n", want: "malformed line"},
+		{name: "version", data: "gibidify-signature: 2\n", want: "unsupported signature version"},
+		{name: "unknown field", data: "color: blue\n", want: "unknown field"},
+		{name: "malformed file", data: "file: abc bundle.md\n", want: "malformed file"},
+		{
+			name: "incomplete",
+			data: "gibidify-signature: 1\nsignature: " + strings.Repeat("A", 86) + "==\n",
+			want: "incomplete",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := signing.Parse([]byte(tt.data)); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Parse() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"strings"
 	"text/template"
 	"time"
+	"unicode"
 
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
@@ -207,13 +208,18 @@ func (e *Engine) RenderTableOfContents(files []FileContext) (string, error) {
 	buf := newBufferBuilder()
 	buf.writeString("## Table of Contents\n\n")
 
+	seen := make(map[string]int, len(files))
 	for _, file := range files {
-		// Create markdown anchor from file path
-		anchor := strings.ToLower(strings.ReplaceAll(file.RelativePath, "/", "-"))
-		anchor = strings.ReplaceAll(anchor, ".", "")
-		anchor = strings.ReplaceAll(anchor, " ", "-")
+		// Repeated anchors get a numeric suffix, as renderers number repeated headings
+		anchor := headingAnchor(file.RelativePath)
+		if count := seen[anchor]; count > 0 {
+			seen[anchor]++
+			anchor = fmt.Sprintf("%s-%d", anchor, count)
+		} else {
+			seen[anchor] = 1
+		}
 
-		buf.writeString(fmt.Sprintf("- [%s](#%s)\n", file.RelativePath, anchor))
+		buf.writeString(fmt.Sprintf("- [%s](#%s)\n", markdownLinkEscaper.Replace(file.RelativePath), anchor))
 	}
 
 	buf.writeString("\n")
@@ -221,6 +227,30 @@ func (e *Engine) RenderTableOfContents(files []FileContext) (string, error) {
 	return buf.String(), nil
 }
 
+// markdownLinkEscaper escapes the characters that would end a link text or turn a path such as
+// __init__.py into emphasis.
+var markdownLinkEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`, "<", `\<`, ">", `\>`,
+)
+
+// headingAnchor returns the anchor GitHub-flavored renderers give a heading with text: lowercased,
+// with spaces turned into hyphens and everything but letters, marks, digits, connector punctuation
+// and hyphens dropped. Slashes, dots and emoji disappear; accented, CJK and right-to-left
+// characters are kept.
+func headingAnchor(text string) string {
+	var anchor strings.Builder
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case r == ' ':
+			anchor.WriteByte('-')
+		case r == '-' || unicode.In(r, unicode.L, unicode.M, unicode.N, unicode.Pc):
+			anchor.WriteRune(r)
+		}
+	}
+
+	return anchor.String()
+}
+
 // Template returns the current template.
 func (e *Engine) Template() OutputTemplate {
 	return e.template
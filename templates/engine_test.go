@@ -326,6 +326,40 @@ func TestRenderTableOfContents(t *testing.T) {
 	}
 }
 
+func TestRenderTableOfContentsAnchors(t *testing.T) {
+	engine, err := NewEngine("detailed", TemplateContext{})
+	if err != nil {
+		t.Fatalf(shared.TestMsgNewEngineFailed, err)
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "docs/🚀 Launch Plan.md", want: "- [docs/🚀 Launch Plan.md](#docs-launch-planmd)"},
+		{path: "src/שלום עולם.py", want: "- [src/שלום עולם.py](#srcשלום-עולםpy)"},
+		{path: "données/Café.txt", want: "- [données/Café.txt](#donnéescafétxt)"},
+		{path: "pkg/__init__.py", want: `- [pkg/\_\_init\_\_.py](#pkg__init__py)`},
+		{path: "notes [draft].md", want: `- [notes \[draft\].md](#notes-draftmd)`},
+		{path: "a/b.go", want: "- [a/b.go](#abgo)"},
+		{path: "ab.go", want: "- [ab.go](#abgo-1)"},
+	}
+	files := make([]FileContext, 0, len(tests))
+	for _, tt := range tests {
+		files = append(files, FileContext{RelativePath: tt.path})
+	}
+
+	toc, err := engine.RenderTableOfContents(files)
+	if err != nil {
+		t.Fatalf("RenderTableOfContents failed: %v", err)
+	}
+	for _, tt := range tests {
+		if !strings.Contains(toc, tt.want+"\n") {
+			t.Errorf("TOC entry for %q: want %q in\n%s", tt.path, tt.want, toc)
+		}
+	}
+}
+
 func TestRenderTableOfContentsDisabled(t *testing.T) {
 	engine, err := NewEngine("default", TemplateContext{})
 	if err != nil {
@@ -0,0 +1,29 @@
+// Package templates provides templating engine functionality for output formatting.
+package templates
+
+import (
+	"fmt"
+	"text/template"
+)
+
+// PromptContext provides data available to prompt scaffold templates.
+// Bundle holds the complete generated bundle in the selected output format.
+type PromptContext struct {
+	TemplateContext
+
+	Bundle string `json:"bundle"`
+}
+
+// ParsePrompt checks that a prompt scaffold template parses with the engine's functions.
+func (e *Engine) ParsePrompt(templateStr string) error {
+	if _, err := template.New("prompt").Funcs(e.getTemplateFunctions()).Parse(templateStr); err != nil {
+		return fmt.Errorf("failed to parse prompt template: %w", err)
+	}
+
+	return nil
+}
+
+// RenderPrompt renders a prompt scaffold template around a generated bundle.
+func (e *Engine) RenderPrompt(templateStr, bundle string) (string, error) {
+	return e.renderTemplate(templateStr, PromptContext{TemplateContext: e.context, Bundle: bundle})
+}
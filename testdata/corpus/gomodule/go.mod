@@ -0,0 +1,3 @@
+module example.com/corpus/gomodule
+
+go 1.22
@@ -0,0 +1,9 @@
+package greet
+
+import "testing"
+
+func TestHello(t *testing.T) {
+	if got := Hello("go"); got != "Hello, go!" {
+		t.Errorf("Hello() = %q", got)
+	}
+}
@@ -0,0 +1,11 @@
+package main
+
+import (
+	"fmt"
+
+	"example.com/corpus/gomodule/internal/greet"
+)
+
+func main() {
+	fmt.Println(greet.Hello("corpus"))
+}
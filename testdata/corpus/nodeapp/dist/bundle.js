@@ -0,0 +1 @@
+console.log("build output is excluded");
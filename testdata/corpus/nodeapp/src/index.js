@@ -0,0 +1,3 @@
+const { pad } = require("./util");
+
+console.log(pad("corpus", 10));
@@ -0,0 +1,3 @@
+export function pad(value: string, width: number): string {
+  return value.padStart(width, " ");
+}
@@ -0,0 +1 @@
+print("שלום עולם")
@@ -0,0 +1,50 @@
+// Package testutil provides common testing utilities and helper functions.
+package testutil
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+)
+
+// RequireGit skips the test when no git executable is available.
+func RequireGit(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git executable not available")
+	}
+}
+
+// RunGit runs git inside dir with an isolated configuration and fails the test on error.
+func RunGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(
+		os.Environ(),
+		"GIT_CONFIG_GLOBAL=/dev/null",
+		"GIT_CONFIG_NOSYSTEM=1",
+		"GIT_COMMITTER_NAME=Test Committer",
+		"GIT_COMMITTER_EMAIL=committer@example.com",
+	)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v failed: %v\n%s", args, err, out)
+	}
+
+	return string(out)
+}
+
+// InitGitRepo initializes an empty git repository in dir.
+func InitGitRepo(t *testing.T, dir string) {
+	t.Helper()
+	RequireGit(t)
+	RunGit(t, dir, "init", "-q")
+}
+
+// GitCommitAs stages all changes in dir and commits them with the given author identity.
+func GitCommitAs(t *testing.T, dir, name, email, message string) {
+	t.Helper()
+	RunGit(t, dir, "add", "-A")
+	RunGit(t, dir, "commit", "-q", "--no-gpg-sign", "--author", name+" <"+email+">", "-m", message)
+}
@@ -0,0 +1,19 @@
+package testutil
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestGitHelpers tests the git repository helpers.
+func TestGitHelpers(t *testing.T) {
+	dir := t.TempDir()
+	InitGitRepo(t, dir)
+	CreateTestFile(t, dir, "a.txt", []byte("a\n"))
+	GitCommitAs(t, dir, "Alice", "alice@example.com", "initial")
+
+	log := RunGit(t, dir, "log", "--format=%an <%ae>")
+	if !strings.Contains(log, "Alice <alice@example.com>") {
+		t.Errorf("expected commit by Alice, got %q", log)
+	}
+}